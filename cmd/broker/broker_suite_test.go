@@ -27,10 +27,11 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	kcMock "github.com/kyma-project/kyma-environment-broker/internal/kubeconfig/automock"
 	"github.com/kyma-project/kyma-environment-broker/internal/metricsv2"
+	"github.com/kyma-project/kyma-environment-broker/internal/outbox"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
@@ -191,6 +192,9 @@ func NewBrokerSuiteTestWithConfig(t *testing.T, cfg *Config, version ...string)
 	gardenerClient := gardener.NewDynamicFakeClient(fixSecrets()...)
 
 	eventBroker := event.NewPubSub(log)
+	outboxDispatcher := outbox.NewDispatcher(db.Outbox(), []outbox.Sink{outbox.NewSubscribersSink(eventBroker)},
+		outbox.Config{PollInterval: 5 * time.Millisecond, BatchSize: 100, LeaseDuration: time.Minute, RetryInterval: 10 * time.Millisecond, MaxAttempts: 10}, log)
+	go outboxDispatcher.Run(ctx)
 
 	createSubscriptions(t, gardenerClient, cfg.SubscriptionGardenerResource)
 	require.NoError(t, err)
@@ -207,7 +211,7 @@ func NewBrokerSuiteTestWithConfig(t *testing.T, cfg *Config, version ...string)
 
 	fakeK8sSKRClient := fake.NewClientBuilder().WithScheme(sch).Build()
 	k8sClientProvider := kubeconfig.NewFakeK8sClientProvider(fakeK8sSKRClient)
-	provisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Provisioning, log.With("provisioning", "manager"))
+	provisionManager := process.NewStagedManager(db.Operations(), db.Outbox(), eventBroker, cfg.Broker.OperationTimeout, cfg.Provisioning, log.With("provisioning", "manager"))
 
 	rulesService, err := rules.NewRulesServiceFromFile("testdata/hap-rules.yaml", sets.New(maps.Keys(broker.PlanIDsMapping)...), sets.New([]string(cfg.Broker.EnablePlans)...).Delete("own_cluster"))
 	require.NoError(t, err)
@@ -215,22 +219,22 @@ func NewBrokerSuiteTestWithConfig(t *testing.T, cfg *Config, version ...string)
 		require.Empty(t, rulesService.ValidationInfo.PlanErrors)
 	}
 
-	awsClientFactory := fixture.NewFakeAWSClientFactory(fixDiscoveredZones(), nil)
+	zonesClientFactory := fixture.NewFakeHyperscalerClientFactory(fixDiscoveredZones(), nil)
 
 	provisioningQueue := NewProvisioningProcessingQueue(context.Background(), provisionManager, workersAmount, cfg, db, configProvider,
 		k8sClientProvider, cli, gardenerClientWithNamespace, defaultOIDCValues(), log, rulesService,
-		workersProvider(cfg.InfrastructureManager, providerSpec), providerSpec, awsClientFactory)
+		workersProvider(cfg.InfrastructureManager, providerSpec), providerSpec, zonesClientFactory)
 
 	provisioningQueue.SpeedUp(testSuiteSpeedUpFactor)
 	provisionManager.SpeedUp(testSuiteSpeedUpFactor)
 
-	updateManager := process.NewStagedManager(db.Operations(), eventBroker, time.Hour, cfg.Update, log.With("update", "manager"))
+	updateManager := process.NewStagedManager(db.Operations(), db.Outbox(), eventBroker, time.Hour, cfg.Update, log.With("update", "manager"))
 	updateQueue := NewUpdateProcessingQueue(context.Background(), updateManager, 1, db, *cfg, cli, log, workersProvider(cfg.InfrastructureManager, providerSpec),
-		schemaService, plansSpec, configProvider, providerSpec, gardenerClientWithNamespace, awsClientFactory)
+		schemaService, plansSpec, configProvider, providerSpec, gardenerClientWithNamespace, zonesClientFactory)
 	updateQueue.SpeedUp(testSuiteSpeedUpFactor)
 	updateManager.SpeedUp(testSuiteSpeedUpFactor)
 
-	deprovisionManager := process.NewStagedManager(db.Operations(), eventBroker, time.Hour, cfg.Deprovisioning, log.With("deprovisioning", "manager"))
+	deprovisionManager := process.NewStagedManager(db.Operations(), db.Outbox(), eventBroker, time.Hour, cfg.Deprovisioning, log.With("deprovisioning", "manager"))
 
 	deprovisioningQueue := NewDeprovisioningProcessingQueue(ctx, workersAmount, deprovisionManager, cfg, db,
 		k8sClientProvider, cli, configProvider, gardenerClient, "kyma", log)
@@ -252,9 +256,9 @@ func NewBrokerSuiteTestWithConfig(t *testing.T, cfg *Config, version ...string)
 	}
 	ts.poller = &broker.TimerPoller{PollInterval: 3 * time.Millisecond, PollTimeout: 800 * time.Millisecond, Log: ts.t.Log}
 
-	ts.CreateAPI(cfg, db, provisioningQueue, deprovisioningQueue, updateQueue, log, k8sClientProvider, eventBroker, configProvider, plansSpec, rulesService, gardenerClientWithNamespace, awsClientFactory)
+	ts.CreateAPI(cfg, db, provisioningQueue, deprovisioningQueue, updateQueue, log, k8sClientProvider, eventBroker, configProvider, plansSpec, rulesService, gardenerClientWithNamespace, zonesClientFactory)
 
-	expirationHandler := expiration.NewHandler(db.Instances(), db.Operations(), deprovisioningQueue, log)
+	expirationHandler := expiration.NewHandler(db.Instances(), db.Operations(), db.Outbox(), db.Actions(), deprovisioningQueue, log)
 	expirationHandler.AttachRoutes(ts.router)
 
 	runtimeHandler := kebRuntime.NewHandler(db, cfg.MaxPaginationPage, cfg.Broker.DefaultRequestRegion, cli, log)
@@ -401,10 +405,10 @@ func (s *BrokerSuiteTest) CallAPI(method string, path string, body string) *http
 	return resp
 }
 
-func (s *BrokerSuiteTest) CreateAPI(cfg *Config, db storage.BrokerStorage, provisioningQueue *process.Queue,
-	deprovisionQueue *process.Queue, updateQueue *process.Queue, log *slog.Logger,
+func (s *BrokerSuiteTest) CreateAPI(cfg *Config, db storage.BrokerStorage, provisioningQueue process.OperationQueue,
+	deprovisionQueue process.OperationQueue, updateQueue process.OperationQueue, log *slog.Logger,
 	skrK8sClientProvider *kubeconfig.FakeProvider, eventBroker *event.PubSub, configProvider kebConfig.Provider, planSpec *configuration.PlanSpecifications,
-	rulesService *rules.RulesService, gardenerClient *gardener.Client, awsClientFactory aws.ClientFactory) {
+	rulesService *rules.RulesService, gardenerClient *gardener.Client, zonesClientFactory hyperscalers.ClientFactory) {
 	servicesConfig := map[string]broker.Service{
 		broker.KymaServiceName: {
 			Description: "",
@@ -455,9 +459,9 @@ func (s *BrokerSuiteTest) CreateAPI(cfg *Config, db storage.BrokerStorage, provi
 	defaultOIDC := defaultOIDCValues()
 	schemaService := broker.NewSchemaService(providerSpec, planSpec, &defaultOIDC, cfg.Broker, cfg.InfrastructureManager.IngressFilteringPlans)
 
-	createAPI(s.router, schemaService, servicesConfig, cfg, db, provisioningQueue, deprovisionQueue, updateQueue,
+	createAPI(context.Background(), s.router, schemaService, servicesConfig, cfg, db, provisioningQueue, deprovisionQueue, updateQueue,
 		lager.NewLogger("api"), log, kcBuilder, skrK8sClientProvider, skrK8sClientProvider, fakeKcpK8sClient, eventBroker, defaultOIDCValues(),
-		providerSpec, configProvider, planSpec, rulesService, gardenerClient, awsClientFactory)
+		providerSpec, configProvider, planSpec, rulesService, gardenerClient, zonesClientFactory)
 
 	s.httpServer = httptest.NewServer(s.router)
 }
@@ -21,8 +21,10 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/additionalproperties"
 	"github.com/kyma-project/kyma-environment-broker/internal/appinfo"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	brokerBindings "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
+	"github.com/kyma-project/kyma-environment-broker/internal/campaign"
 	kebConfig "github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
@@ -30,18 +32,27 @@ import (
 	eventshandler "github.com/kyma-project/kyma-environment-broker/internal/events/handler"
 	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
 	"github.com/kyma-project/kyma-environment-broker/internal/health"
+	"github.com/kyma-project/kyma-environment-broker/internal/history"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	"github.com/kyma-project/kyma-environment-broker/internal/metricsv2"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+	"github.com/kyma-project/kyma-environment-broker/internal/operations"
+	"github.com/kyma-project/kyma-environment-broker/internal/outbox"
+	"github.com/kyma-project/kyma-environment-broker/internal/preview"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/provisioning"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/quota"
+	"github.com/kyma-project/kyma-environment-broker/internal/reencryption"
 	"github.com/kyma-project/kyma-environment-broker/internal/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/runtimedrift"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/suspension"
 	"github.com/kyma-project/kyma-environment-broker/internal/swagger"
+	"github.com/kyma-project/kyma-environment-broker/internal/webhook"
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 	"github.com/kyma-project/kyma-environment-broker/internal/workers"
 
@@ -93,6 +104,7 @@ type Config struct {
 	UpdateProcessingEnabled           bool `envconfig:"default=false"`
 	Broker                            broker.Config
 	CatalogFilePath                   string
+	BindingRolesFilePath              string
 
 	KymaDashboardConfig dashboard.Config
 
@@ -119,6 +131,27 @@ type Config struct {
 	Deprovisioning process.StagedManagerConfiguration
 	Update         process.StagedManagerConfiguration
 
+	// OperationQueue configures the database backed queue shared by all replicas, not used with DbInMemory
+	OperationQueue process.PersistentQueueConfig
+
+	// Outbox configures the delivery of events stored together with operations
+	Outbox outbox.Config
+
+	// Webhooks configures notifications sent to endpoints subscribed for events of accounts
+	Webhooks webhook.Config
+
+	// RuntimeDrift configures the detection of Runtime resources changed outside of KEB
+	RuntimeDrift runtimedrift.Config
+
+	// Campaigns configures updates of many instances in waves
+	Campaigns campaign.Config
+
+	// Reencryption configures encrypting stored secrets again with the active encryption key
+	Reencryption reencryption.Config
+
+	// Authorization configures the validation of tokens and scopes of the admin endpoints by KEB itself
+	Authorization middleware.AuthorizationConfig
+
 	RuntimeConfigurationConfigMapName string `envconfig:"default=keb-runtime-config"`
 
 	UpdateRuntimeResourceDelay time.Duration `envconfig:"default=4s"`
@@ -141,6 +174,9 @@ type Config struct {
 	HoldHapSteps bool
 
 	MachinesAvailabilityEndpoint bool
+	// MachinesAvailability configures caching of zones returned by the machines availability endpoint
+	MachinesAvailability machinesavailability.Config
+	PreviewEndpoint      bool
 }
 
 type ProfilerConfig struct {
@@ -273,7 +309,8 @@ func main() {
 	}
 
 	// create storage
-	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err, log)
 	var db storage.BrokerStorage
 	if cfg.DbInMemory {
 		db = storage.NewMemoryStorage()
@@ -294,6 +331,11 @@ func main() {
 	fatalOnError(err, log)
 	cfg.Gardener.DNSProviders, err = gardener.ReadDNSProvidersValuesFromYAML(cfg.SkrDnsProvidersValuesYAMLFilePath)
 	fatalOnError(err, log)
+	cfg.Broker.Binding.Roles, err = brokerBindings.NewRoleTemplatesFromFile(cfg.BindingRolesFilePath)
+	fatalOnError(err, log)
+	if _, found := cfg.Broker.Binding.Roles[cfg.Broker.Binding.DefaultRole]; !found {
+		fatalOnError(fmt.Errorf("default binding role %s is not configured", cfg.Broker.Binding.DefaultRole), log)
+	}
 	dynamicGardener, err := dynamic.NewForConfig(gardenerClusterConfig)
 	fatalOnError(err, log)
 
@@ -309,6 +351,28 @@ func main() {
 	// metrics collectors
 	_ = metricsv2.Register(ctx, eventBroker, db, cfg.MetricsV2, log)
 
+	// snapshots of parameters effective after succeeded operations
+	historyRecorder := history.NewRecorder(db.Operations(), db.Instances(), db.InstanceSnapshots(), log)
+	eventBroker.Subscribe(process.OperationSucceeded{}, historyRecorder.OnOperationSucceeded)
+
+	// events about finished operations are stored in the outbox and delivered to subscribers and external sinks
+	outboxSinks := []outbox.Sink{outbox.NewSubscribersSink(eventBroker)}
+	if cfg.Outbox.WebhookURL != "" {
+		outboxSinks = append(outboxSinks, outbox.NewWebhookSink(cfg.Outbox.WebhookURL, &http.Client{Timeout: 30 * time.Second}))
+	}
+	if cfg.Webhooks.Enabled {
+		outboxSinks = append(outboxSinks, webhook.NewSink(db.Webhooks()))
+		go webhook.NewDeliverer(db.Webhooks(), cfg.Webhooks, log).Run(ctx)
+	}
+	go outbox.NewDispatcher(db.Outbox(), outboxSinks, cfg.Outbox, log).Run(ctx)
+
+	plansSpec, err := configuration.NewPlanSpecificationsFromFile(cfg.PlansConfigurationFilePath)
+	fatalOnError(err, log)
+	fatalOnError(plansSpec.Validate(), log)
+	fatalOnError(broker.RegisterPlans(plansSpec.Definitions()), log)
+	fatalOnError(cfg.Broker.ValidatePlans(), log)
+	fatalOnError(cfg.InfrastructureManager.IngressFilteringPlans.Validate(), log)
+
 	rulesService, err := rules.NewRulesServiceFromFile(cfg.HapRuleFilePath, sets.New(maps.Keys(broker.PlanIDsMapping)...), sets.New([]string(cfg.Broker.EnablePlans)...).Delete("own_cluster"))
 	fatalOnError(err, log)
 
@@ -322,8 +386,6 @@ func main() {
 		fatalOnError(err, log)
 	}
 
-	plansSpec, err := configuration.NewPlanSpecificationsFromFile(cfg.PlansConfigurationFilePath)
-	fatalOnError(err, log)
 	providerSpec, err := configuration.NewProviderSpecFromFile(cfg.ProvidersConfigurationFilePath)
 	fatalOnError(err, log)
 	fatalOnError(providerSpec.ValidateZonesDiscovery(), log)
@@ -333,19 +395,19 @@ func main() {
 	log.Info("Plans and providers configuration is valid")
 	workersProvider := workers.NewProvider(cfg.InfrastructureManager, providerSpec)
 
-	awsClientFactory := aws.NewFactory()
+	zonesClientFactory := hyperscalers.NewFactory()
 
 	// run queues
-	provisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Provisioning, log.With("provisioning", "manager"))
+	provisionManager := process.NewStagedManager(db.Operations(), db.Outbox(), eventBroker, cfg.Broker.OperationTimeout, cfg.Provisioning, log.With("provisioning", "manager"))
 	provisionQueue := NewProvisioningProcessingQueue(ctx, provisionManager, cfg.Provisioning.WorkersAmount, &cfg, db, configProvider,
-		skrK8sClientProvider, kcpK8sClient, gardenerClient, oidcDefaultValues, log, rulesService, workersProvider, providerSpec, awsClientFactory)
+		skrK8sClientProvider, kcpK8sClient, gardenerClient, oidcDefaultValues, log, rulesService, workersProvider, providerSpec, zonesClientFactory)
 
-	deprovisionManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Deprovisioning, log.With("deprovisioning", "manager"))
+	deprovisionManager := process.NewStagedManager(db.Operations(), db.Outbox(), eventBroker, cfg.Broker.OperationTimeout, cfg.Deprovisioning, log.With("deprovisioning", "manager"))
 	deprovisionQueue := NewDeprovisioningProcessingQueue(ctx, cfg.Deprovisioning.WorkersAmount, deprovisionManager, &cfg, db,
 		skrK8sClientProvider, kcpK8sClient, configProvider, dynamicGardener, gardenerNamespace, log)
 
-	updateManager := process.NewStagedManager(db.Operations(), eventBroker, cfg.Broker.OperationTimeout, cfg.Update, log.With("update", "manager"))
-	updateQueue := NewUpdateProcessingQueue(ctx, updateManager, cfg.Update.WorkersAmount, db, cfg, kcpK8sClient, log, workersProvider, schemaService, plansSpec, configProvider, providerSpec, gardenerClient, awsClientFactory)
+	updateManager := process.NewStagedManager(db.Operations(), db.Outbox(), eventBroker, cfg.Broker.OperationTimeout, cfg.Update, log.With("update", "manager"))
+	updateQueue := NewUpdateProcessingQueue(ctx, updateManager, cfg.Update.WorkersAmount, db, cfg, kcpK8sClient, log, workersProvider, schemaService, plansSpec, configProvider, providerSpec, gardenerClient, zonesClientFactory)
 	/***/
 	servicesConfig, err := broker.NewServicesConfigFromFile(cfg.CatalogFilePath)
 	fatalOnError(err, log)
@@ -355,10 +417,16 @@ func main() {
 
 	// create server
 	router := httputil.NewRouter()
+	// with the authorization disabled, the keys still verify tokens which identify callers in recorded actions
+	if cfg.Authorization.Enabled || cfg.Authorization.KeysConfigured() {
+		authorization, err := middleware.NewAuthorization(cfg.Authorization, log)
+		fatalOnError(err, log)
+		router.Use(authorization.Middleware())
+	}
 
-	createAPI(router, schemaService, servicesConfig, &cfg, db, provisionQueue, deprovisionQueue, updateQueue, logger, log,
+	createAPI(ctx, router, schemaService, servicesConfig, &cfg, db, provisionQueue, deprovisionQueue, updateQueue, logger, log,
 		kcBuilder, skrK8sClientProvider, skrK8sClientProvider, kcpK8sClient, eventBroker, oidcDefaultValues,
-		providerSpec, configProvider, plansSpec, rulesService, gardenerClient, awsClientFactory)
+		providerSpec, configProvider, plansSpec, rulesService, gardenerClient, zonesClientFactory)
 
 	// create metrics endpoint
 	router.Handle("/metrics", promhttp.Handler())
@@ -397,9 +465,26 @@ func main() {
 	additionalPropertiesHandler.AttachRoutes(router)
 
 	// create expiration endpoint
-	expirationHandler := expiration.NewHandler(db.Instances(), db.Operations(), deprovisionQueue, log)
+	expirationHandler := expiration.NewHandler(db.Instances(), db.Operations(), db.Outbox(), db.Actions(), deprovisionQueue, log)
 	expirationHandler.AttachRoutes(router)
 
+	// create operations endpoint
+	operationsHandler := operations.NewHandler(db.Operations(), db.Actions(), provisionQueue, deprovisionQueue, updateQueue, cfg.Broker.OperationTimeout, log)
+	operationsHandler.AttachRoutes(router)
+
+	// create actions endpoint
+	actionsHandler := audit.NewHandler(db.Actions(), cfg.MaxPaginationPage, log)
+	actionsHandler.AttachRoutes(router)
+
+	// create instance history endpoint
+	historyHandler := history.NewHandler(db.Instances(), db.InstanceSnapshots(), log)
+	historyHandler.AttachRoutes(router)
+
+	if cfg.Webhooks.Enabled {
+		webhookHandler := webhook.NewHandler(db.Webhooks(), cfg.MaxPaginationPage, log)
+		webhookHandler.AttachRoutes(router)
+	}
+
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.StripPrefix("/", http.FileServer(http.Dir("/swagger"))).ServeHTTP(w, r)
 	})
@@ -414,6 +499,14 @@ func main() {
 
 func logConfiguration(logs *slog.Logger, cfg Config) {
 	logs.Info(fmt.Sprintf("Setting staged manager configuration: provisioning=%s, deprovisioning=%s, update=%s", cfg.Provisioning, cfg.Deprovisioning, cfg.Update))
+	logs.Info(fmt.Sprintf("Setting operation queue configuration: %s", cfg.OperationQueue))
+	logs.Info(fmt.Sprintf("Setting outbox configuration: %s", cfg.Outbox))
+	logs.Info(fmt.Sprintf("Setting webhooks configuration: %s", cfg.Webhooks))
+	logs.Info(fmt.Sprintf("Setting runtime drift configuration: %s", cfg.RuntimeDrift))
+	logs.Info(fmt.Sprintf("Setting campaigns configuration: %s", cfg.Campaigns))
+	logs.Info(fmt.Sprintf("Setting re-encryption configuration: %s", cfg.Reencryption))
+	logs.Info(fmt.Sprintf("Setting authorization configuration: %s", cfg.Authorization))
+	logs.Info(fmt.Sprintf("Setting machines availability configuration: %s", cfg.MachinesAvailability))
 	logs.Info(fmt.Sprintf("EnablePlans: %s", cfg.Broker.EnablePlans))
 	logs.Info(fmt.Sprintf("Is SubaccountMovementEnabled: %t", cfg.Broker.SubaccountMovementEnabled))
 	logs.Info(fmt.Sprintf("Is UpdateCustomResourcesLabelsOnAccountMove enabled: %t", cfg.Broker.UpdateCustomResourcesLabelsOnAccountMove))
@@ -433,20 +526,21 @@ func logConfiguration(logs *slog.Logger, cfg Config) {
 	logs.Info(fmt.Sprintf("Gardener resource used for subscriptions: %s", r.String()))
 }
 
-func createAPI(router *httputil.Router, schemaService *broker.SchemaService, servicesConfig broker.ServicesConfig, cfg *Config, db storage.BrokerStorage,
-	provisionQueue, deprovisionQueue, updateQueue *process.Queue, logger lager.Logger, logs *slog.Logger, kcBuilder kubeconfig.KcBuilder, clientProvider K8sClientProvider,
+func createAPI(ctx context.Context, router *httputil.Router, schemaService *broker.SchemaService, servicesConfig broker.ServicesConfig, cfg *Config, db storage.BrokerStorage,
+	provisionQueue, deprovisionQueue, updateQueue process.OperationQueue, logger lager.Logger, logs *slog.Logger, kcBuilder kubeconfig.KcBuilder, clientProvider K8sClientProvider,
 	kubeconfigProvider KubeconfigProvider, kcpK8sClient client.Client, publisher event.Publisher, oidcDefaultValues pkg.OIDCConfigDTO,
 	providerSpec *configuration.ProviderSpec, configProvider kebConfig.Provider, planSpec *configuration.PlanSpecifications, rulesService *rules.RulesService,
-	gardenerClient *gardener.Client, awsClientFactory aws.ClientFactory) {
+	gardenerClient *gardener.Client, zonesClientFactory hyperscalers.ClientFactory) {
 
 	if cfg.MachinesAvailabilityEndpoint {
+		var machinesAvailability *machinesavailability.Handler
 		if r, _ := cfg.GardenerSubscriptionResource(); r == gardener.SecretBindingResource {
-			machinesAvailability := machinesavailability.NewHandler(providerSpec, rulesService, gardenerClient, awsClientFactory, logs)
-			machinesAvailability.AttachRoutes(router)
+			machinesAvailability = machinesavailability.NewHandler(providerSpec, rulesService, gardenerClient, zonesClientFactory, cfg.MachinesAvailability, logs)
 		} else {
-			machinesAvailability := machinesavailability.NewHandlerCB(providerSpec, rulesService, gardenerClient, awsClientFactory, logs)
-			machinesAvailability.AttachRoutes(router)
+			machinesAvailability = machinesavailability.NewHandlerCB(providerSpec, rulesService, gardenerClient, zonesClientFactory, cfg.MachinesAvailability, logs)
 		}
+		machinesAvailability.AttachRoutes(router)
+		go machinesAvailability.Run(ctx)
 	}
 
 	regions, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
@@ -454,7 +548,7 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	logs.Info(fmt.Sprintf("Platform region mapping for trial: %v", regions))
 	valuesProvider := provider.NewPlanSpecificValuesProvider(cfg.InfrastructureManager, regions, schemaService, planSpec)
 
-	suspensionCtxHandler := suspension.NewContextUpdateHandler(db.Operations(), provisionQueue, deprovisionQueue, logs)
+	suspensionCtxHandler := suspension.NewContextUpdateHandler(db.Operations(), db.Outbox(), db.Actions(), provisionQueue, deprovisionQueue, logs)
 
 	defaultPlansConfig, err := servicesConfig.DefaultPlansConfig()
 	fatalOnError(err, logs)
@@ -475,6 +569,10 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	fatalOnError(err, logs)
 	logs.Info(fmt.Sprintf("Number of subaccountIds with unlimited quota: %d", len(quotaWhitelistedSubaccountIds)))
 
+	bindingsManager := brokerBindings.NewServiceAccountBindingsManager(clientProvider, kubeconfigProvider, cfg.Broker.Binding.Roles)
+	bindingQueue := newOperationQueue(*cfg, db, broker.NewBindingProcessor(cfg.Broker.Binding, db, bindingsManager, publisher, logs), logs, "binding")
+	bindingQueue.Run(ctx.Done(), cfg.Broker.Binding.WorkersAmount)
+
 	// create KymaEnvironmentBroker endpoints
 	kymaEnvBroker := &broker.KymaEnvironmentBroker{
 		ServicesEndpoint: broker.NewServices(cfg.Broker, schemaService, servicesConfig, logs, oidcDefaultValues, cfg.InfrastructureManager),
@@ -482,18 +580,18 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 			provisionQueue, defaultPlansConfig, logs, cfg.KymaDashboardConfig, kcBuilder, freemiumGlobalAccountIds,
 			schemaService, providerSpec, valuesProvider, cfg.InfrastructureManager.UseSmallerMachineTypes,
 			kebConfig.NewConfigMapConfigProvider(configProvider, cfg.Broker.GardenerSeedsCacheConfigMapName, kebConfig.ProviderConfigurationRequiredFields), quotaClient, quotaWhitelistedSubaccountIds,
-			rulesService, gardenerClient, awsClientFactory),
-		DeprovisionEndpoint: broker.NewDeprovision(db.Instances(), db.Operations(), deprovisionQueue, logs),
+			rulesService, gardenerClient, zonesClientFactory),
+		DeprovisionEndpoint: broker.NewDeprovision(db.Instances(), db.Operations(), db.Actions(), deprovisionQueue, logs),
 		UpdateEndpoint: broker.NewUpdate(cfg.Broker, db,
 			suspensionCtxHandler, cfg.UpdateProcessingEnabled, cfg.Broker.SubaccountMovementEnabled, cfg.Broker.UpdateCustomResourcesLabelsOnAccountMove, updateQueue, defaultPlansConfig,
 			valuesProvider, logs, cfg.KymaDashboardConfig, kcBuilder, kcpK8sClient, providerSpec, planSpec, cfg.InfrastructureManager, schemaService, quotaClient, quotaWhitelistedSubaccountIds,
-			rulesService, gardenerClient, awsClientFactory),
+			rulesService, gardenerClient, zonesClientFactory),
 		GetInstanceEndpoint:          broker.NewGetInstance(cfg.Broker, db.Instances(), db.Operations(), kcBuilder, logs),
 		LastOperationEndpoint:        broker.NewLastOperation(db.Operations(), db.InstancesArchived(), logs),
-		BindEndpoint:                 broker.NewBind(cfg.Broker.Binding, db, logs, clientProvider, kubeconfigProvider, publisher),
-		UnbindEndpoint:               broker.NewUnbind(logs, db, brokerBindings.NewServiceAccountBindingsManager(clientProvider, kubeconfigProvider), publisher),
+		BindEndpoint:                 broker.NewBind(cfg.Broker.Binding, db, logs, clientProvider, kubeconfigProvider, publisher, bindingQueue),
+		UnbindEndpoint:               broker.NewUnbind(logs, db, bindingsManager, publisher),
 		GetBindingEndpoint:           broker.NewGetBinding(logs, db),
-		LastBindingOperationEndpoint: broker.NewLastBindingOperation(logs),
+		LastBindingOperationEndpoint: broker.NewLastBindingOperation(logs, db),
 	}
 
 	if r, _ := cfg.GardenerSubscriptionResource(); r == gardener.CredentialsBindingResource {
@@ -510,10 +608,50 @@ func createAPI(router *httputil.Router, schemaService *broker.SchemaService, ser
 	runtimesInfoHandler := appinfo.NewRuntimeInfoHandler(db.Instances(), db.Operations(), defaultPlansConfig, cfg.Broker.DefaultRequestRegion, respWriter)
 	router.Handle("/info/runtimes", runtimesInfoHandler)
 	router.Handle("/events", eventshandler.NewHandler(db.Events(), db.Instances()))
+
+	if cfg.Broker.Binding.Enabled {
+		renewBindingHandler := broker.NewRenewBinding(cfg.Broker.Binding, db, bindingsManager, publisher, logs)
+		renewBindingHandler.AttachRoutes(router)
+	}
+
+	if cfg.PreviewEndpoint {
+		previewHandler := preview.NewHandler(kymaEnvBroker.ProvisionEndpoint, kymaEnvBroker.UpdateEndpoint, kcpK8sClient, rulesService,
+			cfg.InfrastructureManager, oidcDefaultValues, workers.NewProvider(cfg.InfrastructureManager, providerSpec), providerSpec,
+			kebConfig.NewConfigMapConfigProvider(configProvider, cfg.RuntimeConfigurationConfigMapName, kebConfig.RuntimeConfigurationRequiredFields),
+			valuesProvider, regions, cfg.Broker.DefaultRequestRegion, logs)
+		previewHandler.AttachRoutes(router)
+	}
+
+	if cfg.RuntimeDrift.Enabled {
+		runtimeResourceBuilder := provisioning.NewCreateRuntimeResourceStep(db, kcpK8sClient, cfg.InfrastructureManager, oidcDefaultValues,
+			workers.NewProvider(cfg.InfrastructureManager, providerSpec), providerSpec)
+		driftDetector := runtimedrift.NewDetector(db.Operations(), kcpK8sClient, runtimeResourceBuilder, valuesProvider)
+		runtimeDriftHandler := runtimedrift.NewHandler(db.Instances(), driftDetector, logs)
+		runtimeDriftHandler.AttachRoutes(router)
+		go runtimedrift.NewReconciler(db.Instances(), driftDetector, runtimedrift.NewMetrics(prometheus.DefaultRegisterer), cfg.RuntimeDrift, logs).Run(ctx)
+	}
+
+	if cfg.Campaigns.Enabled {
+		campaignHandler := campaign.NewHandler(db, cfg.MaxPaginationPage, logs)
+		campaignHandler.AttachRoutes(router)
+		go campaign.NewRunner(db, kymaEnvBroker.UpdateEndpoint, cfg.Campaigns, logs).Run(ctx)
+	}
+
+	if cfg.Reencryption.Enabled {
+		go reencryption.NewWorker(db.Reencryption(), cfg.Reencryption, logs).Run(ctx)
+	}
+}
+
+// newOperationQueue creates the queue shared by all broker replicas, the in-memory queue is used only with the memory storage
+func newOperationQueue(cfg Config, db storage.BrokerStorage, executor process.Executor, log *slog.Logger, name string) process.OperationQueue {
+	if cfg.DbInMemory {
+		return process.NewQueue(executor, log, name)
+	}
+	return process.NewPersistentQueue(executor, db.OperationQueue(), cfg.OperationQueue, log, name)
 }
 
-// queues all in progress operations by type
-func processOperationsInProgressByType(opType internal.OperationType, op storage.Operations, queue *process.Queue, log *slog.Logger) error {
+// queues all in progress operations by type, adding an operation which is already queued is a no-op
+func processOperationsInProgressByType(opType internal.OperationType, op storage.Operations, queue process.OperationQueue, log *slog.Logger) error {
 	operations, err := op.GetNotFinishedOperationsByType(opType)
 	if err != nil {
 		return fmt.Errorf("while getting in progress operations from storage: %w", err)
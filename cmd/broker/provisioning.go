@@ -11,8 +11,9 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/deprovisioning"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/provisioning"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
@@ -32,7 +33,7 @@ const (
 func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *process.StagedManager, workersAmount int, cfg *Config,
 	db storage.BrokerStorage, configProvider config.Provider,
 	k8sClientProvider provisioning.K8sClientProvider, k8sClient client.Client, gardenerClient *gardener.Client, defaultOIDC pkg.OIDCConfigDTO, logs *slog.Logger, rulesService *rules.RulesService,
-	workersProvider *workers.Provider, providerSpec *configuration.ProviderSpec, awsClientFactory aws.ClientFactory) *process.Queue {
+	workersProvider *workers.Provider, providerSpec *configuration.ProviderSpec, zonesClientFactory hyperscalers.ClientFactory) process.OperationQueue {
 
 	useCredentialsBinding := strings.ToLower(cfg.SubscriptionGardenerResource) == "credentialsbinding"
 
@@ -84,13 +85,13 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 		},
 		{
 			stage:     createRuntimeStageName,
-			step:      steps.NewDiscoverAvailableZonesStep(db, providerSpec, gardenerClient, awsClientFactory),
+			step:      steps.NewDiscoverAvailableZonesStep(db, providerSpec, gardenerClient, zonesClientFactory),
 			condition: provisioning.SkipForOwnClusterPlan,
 			disabled:  useCredentialsBinding,
 		},
 		{
 			stage:    createRuntimeStageName,
-			step:     steps.NewDiscoverAvailableZonesCBStep(db, providerSpec, gardenerClient, awsClientFactory),
+			step:     steps.NewDiscoverAvailableZonesCBStep(db, providerSpec, gardenerClient, zonesClientFactory),
 			disabled: !useCredentialsBinding,
 		},
 		{
@@ -135,7 +136,11 @@ func NewProvisioningProcessingQueue(ctx context.Context, provisionManager *proce
 		}
 	}
 
-	queue := process.NewQueue(provisionManager, logs, "provisioning")
+	// steps run when the provisioning is canceled, they remove resources created so far
+	provisionManager.AddCancelStep(deprovisioning.NewDeleteKymaResourceStep(db, k8sClient, config.NewConfigMapConfigProvider(configProvider, cfg.RuntimeConfigurationConfigMapName, config.RuntimeConfigurationRequiredFields)), nil)
+	provisionManager.AddCancelStep(deprovisioning.NewDeleteRuntimeResourceStep(db, k8sClient), provisioning.SkipForOwnClusterPlan)
+
+	queue := newOperationQueue(*cfg, db, provisionManager, logs, "provisioning")
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
@@ -8,7 +8,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/update"
@@ -22,7 +22,7 @@ import (
 
 func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManager, workersAmount int, db storage.BrokerStorage,
 	cfg Config, kcpClient client.Client, logs *slog.Logger, workersProvider *workers.Provider, schemaService *broker.SchemaService, planSpec *configuration.PlanSpecifications, configProvider config.Provider,
-	providerSpec *configuration.ProviderSpec, gardenerClient *gardener.Client, awsClientFactory aws.ClientFactory) *process.Queue {
+	providerSpec *configuration.ProviderSpec, gardenerClient *gardener.Client, zonesClientFactory hyperscalers.ClientFactory) process.OperationQueue {
 
 	trialRegionsMapping, err := provider.ReadPlatformRegionMappingFromFile(cfg.TrialRegionMappingFilePath)
 	if err != nil {
@@ -45,7 +45,7 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 		},
 		{
 			stage:     "runtime_resource",
-			step:      steps.NewDiscoverAvailableZonesStep(db, providerSpec, gardenerClient, awsClientFactory),
+			step:      steps.NewDiscoverAvailableZonesStep(db, providerSpec, gardenerClient, zonesClientFactory),
 			condition: update.SkipForOwnClusterPlan,
 		},
 		{
@@ -62,6 +62,10 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 			stage: "kyma_resource",
 			step:  update.NewUpdateKymaStep(db, kcpClient, config.NewConfigMapConfigProvider(configProvider, cfg.RuntimeConfigurationConfigMapName, config.RuntimeConfigurationRequiredFields)),
 		},
+		{
+			stage: "kyma_resource",
+			step:  update.NewUpdateLabelsStep(db, kcpClient),
+		},
 	}
 
 	for _, step := range updateSteps {
@@ -72,7 +76,7 @@ func NewUpdateProcessingQueue(ctx context.Context, manager *process.StagedManage
 			}
 		}
 	}
-	queue := process.NewQueue(manager, logs, "update-processing")
+	queue := newOperationQueue(cfg, db, manager, logs, "update-processing")
 	queue.Run(ctx.Done(), workersAmount)
 
 	return queue
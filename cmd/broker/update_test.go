@@ -11,6 +11,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 
 	gardener "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	"github.com/google/uuid"
@@ -236,7 +237,7 @@ func TestUpdatePlan(t *testing.T) {
 		customresources.PlanNameLabel: "build-runtime-aws",
 	})
 
-	actions, err := suite.db.Actions().ListActionsByInstanceID(iid)
+	actions, _, err := suite.db.Actions().ListActions(dbmodel.ActionFilter{InstanceIDs: []string{iid}, Types: []string{string(pkg.PlanUpdateActionType)}})
 	assert.NoError(t, err)
 	require.Len(t, actions, 1)
 	assert.Equal(t, actions[0].Type, pkg.PlanUpdateActionType)
@@ -3627,7 +3628,7 @@ func TestUpdateGlobalAccountID(t *testing.T) {
 
 	suite.WaitForOperationState(updateOperationID, domain.Succeeded)
 
-	actions, err := suite.db.Actions().ListActionsByInstanceID(iid)
+	actions, _, err := suite.db.Actions().ListActions(dbmodel.ActionFilter{InstanceIDs: []string{iid}, Types: []string{string(pkg.SubaccountMovementActionType)}})
 	assert.NoError(t, err)
 	require.Len(t, actions, 1)
 	assert.Equal(t, actions[0].Type, pkg.SubaccountMovementActionType)
@@ -53,9 +53,11 @@ func main() {
 
 	ctx := context.Background()
 	brokerClient := broker.NewClient(ctx, cfg.Broker)
+	brokerClient.UserAgent = broker.DeprovisionRetriggerJobName
 
 	// create storage connection
-	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
 	db, conn, err := storage.NewFromConfig(cfg.Database, events.Config{}, cipher)
 	fatalOnError(err)
 	svc := newDeprovisionRetriggerService(cfg, brokerClient, db.Instances())
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/operations"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+const (
+	urlEnv   = "KEB_URL"
+	tokenEnv = "KEB_TOKEN"
+
+	requestTimeout = 30 * time.Second
+)
+
+type RetryCommand struct {
+	cobraCmd *cobra.Command
+	url      string
+	token    string
+	stage    string
+}
+
+func NewRetryCmd() *cobra.Command {
+	cmd := RetryCommand{}
+	cobraCmd := &cobra.Command{
+		Use:     "retry OPERATION_ID",
+		Aliases: []string{"r"},
+		Short:   "Retries a failed operation.",
+		Long:    "Retries a failed provisioning, deprovisioning, or update operation. The operation is processed again starting from the first stage which is not finished.",
+		Example: `
+	# Retry the failed operation
+	keb-operations retry 8a7bd2b4-4d8e-4c5a-a5f8-2a7c8cf2d6c1 --url https://kyma-env-broker.kyma.local --token $TOKEN
+
+	# Retry the failed operation processing the finished "create_runtime" stage again
+	KEB_URL=https://kyma-env-broker.kyma.local KEB_TOKEN=$TOKEN keb-operations retry 8a7bd2b4-4d8e-4c5a-a5f8-2a7c8cf2d6c1 -s create_runtime
+		`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cmd.Run(args[0])
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	cmd.cobraCmd = cobraCmd
+
+	cobraCmd.Flags().StringVarP(&cmd.url, "url", "u", os.Getenv(urlEnv), "The Kyma Environment Broker URL. Defaults to the KEB_URL environment variable.")
+	cobraCmd.Flags().StringVarP(&cmd.token, "token", "t", os.Getenv(tokenEnv), "The OIDC ID token used to call Kyma Environment Broker. Defaults to the KEB_TOKEN environment variable.")
+	cobraCmd.Flags().StringVarP(&cmd.stage, "stage", "s", "", "The finished stage which must be processed again.")
+
+	return cobraCmd
+}
+
+func (cmd *RetryCommand) Run(operationID string) error {
+	if cmd.url == "" || cmd.token == "" {
+		return errors.New("the Kyma Environment Broker URL and token must be provided")
+	}
+
+	httpClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cmd.token}))
+	httpClient.Timeout = requestTimeout
+	client := operations.NewClient(cmd.url, httpClient)
+
+	response, err := client.RetryOperation(operationID, operations.RetryRequest{Stage: cmd.stage})
+	if err != nil {
+		return err
+	}
+	cmd.cobraCmd.Printf("Operation %s is %s\n", response.OperationID, response.State)
+	return nil
+}
@@ -43,7 +43,8 @@ func main() {
 	brokerClient := broker.NewClientWithRequestTimeoutAndRetries(ctx, cfg.Broker, cfg.Job.RequestTimeout, cfg.Job.RequestRetries)
 	brokerClient.UserAgent = broker.ServiceBindingCleanupJobName
 
-	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
 	db, conn, err := storage.NewFromConfig(cfg.Database, events.Config{}, cipher)
 	fatalOnError(err)
 
@@ -72,7 +72,8 @@ func main() {
 	kymaGVR := getResourceKindProvider(kebConfig.NewConfigMapConfigProvider(configProvider, cfg.RuntimeConfigurationConfigMapName, kebConfig.RuntimeConfigurationRequiredFields))
 
 	// create DB connection
-	cipher := storage.NewEncrypter(cfg.Database.SecretKey)
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	fatalOnError(err)
 	db, dbConn, err := storage.NewFromConfig(cfg.Database, events.Config{}, cipher)
 
 	// create and register metrics
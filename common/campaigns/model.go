@@ -0,0 +1,146 @@
+package campaigns
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type State string
+
+const (
+	StatePending    State = "pending"
+	StateInProgress State = "in progress"
+	// StatePaused is set by the operator or when the number of failed updates exceeds the failure threshold
+	StatePaused    State = "paused"
+	StateCanceled  State = "canceled"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+)
+
+// IsFinished returns true if the campaign does not update instances anymore and cannot be resumed
+func (s State) IsFinished() bool {
+	return s == StateCanceled || s == StateSucceeded || s == StateFailed
+}
+
+type InstanceState string
+
+const (
+	InstancePending    InstanceState = "pending"
+	InstanceInProgress InstanceState = "in progress"
+	InstanceSucceeded  InstanceState = "succeeded"
+	InstanceFailed     InstanceState = "failed"
+	// InstanceSkipped marks an instance for which KEB did not create an update operation, for example, because it is expired
+	InstanceSkipped  InstanceState = "skipped"
+	InstanceCanceled InstanceState = "canceled"
+)
+
+// IsFinished returns true if the instance is not updated by the campaign anymore
+func (s InstanceState) IsFinished() bool {
+	return s == InstanceSucceeded || s == InstanceFailed || s == InstanceSkipped || s == InstanceCanceled
+}
+
+// Target selects instances updated by the campaign, the fields have the same meaning as the filters of the GET /runtimes endpoint.
+// Only not deprovisioned instances are selected.
+type Target struct {
+	GlobalAccountIDs []string `json:"globalAccountIDs,omitempty"`
+	SubAccountIDs    []string `json:"subAccountIDs,omitempty"`
+	InstanceIDs      []string `json:"instanceIDs,omitempty"`
+	RuntimeIDs       []string `json:"runtimeIDs,omitempty"`
+	Regions          []string `json:"regions,omitempty"`
+	Plans            []string `json:"plans,omitempty"`
+	Shoots           []string `json:"shoots,omitempty"`
+}
+
+func (t Target) IsEmpty() bool {
+	return len(t.GlobalAccountIDs) == 0 && len(t.SubAccountIDs) == 0 && len(t.InstanceIDs) == 0 && len(t.RuntimeIDs) == 0 &&
+		len(t.Regions) == 0 && len(t.Plans) == 0 && len(t.Shoots) == 0
+}
+
+// Strategy defines how fast the campaign updates instances
+type Strategy struct {
+	// CanarySize is the number of instances updated in the first wave, there is no canary wave if 0
+	CanarySize int `json:"canarySize,omitempty"`
+	// WaveSize is the number of instances in every wave after the canary wave, all instances are updated in one wave if 0
+	WaveSize int `json:"waveSize,omitempty"`
+	// Parallelism is the maximum number of update operations of the campaign in progress at the same time
+	Parallelism int `json:"parallelism,omitempty"`
+	// WaveDelay is the time between the end of a wave and the start of the next one, in the Go duration format
+	WaveDelay string `json:"waveDelay,omitempty"`
+	// MaxFailures is the number of failed updates tolerated by the campaign, the campaign is paused when more updates fail
+	MaxFailures int `json:"maxFailures,omitempty"`
+}
+
+// CreateRequest is the body of the KEB POST /campaigns request
+type CreateRequest struct {
+	Description string `json:"description,omitempty"`
+	Target      Target `json:"target"`
+	// Parameters are sent as the parameters of the update request of every instance
+	Parameters json.RawMessage `json:"parameters"`
+	Strategy   Strategy        `json:"strategy,omitempty"`
+}
+
+type Progress struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	InProgress int `json:"inProgress"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+	Canceled   int `json:"canceled"`
+}
+
+// Add counts the instance in the given state
+func (p *Progress) Add(state InstanceState) {
+	p.Total++
+	switch state {
+	case InstancePending:
+		p.Pending++
+	case InstanceInProgress:
+		p.InProgress++
+	case InstanceSucceeded:
+		p.Succeeded++
+	case InstanceFailed:
+		p.Failed++
+	case InstanceSkipped:
+		p.Skipped++
+	case InstanceCanceled:
+		p.Canceled++
+	}
+}
+
+type CampaignDTO struct {
+	ID          string          `json:"id"`
+	Description string          `json:"description,omitempty"`
+	State       State           `json:"state"`
+	Message     string          `json:"message,omitempty"`
+	Target      Target          `json:"target"`
+	Parameters  json.RawMessage `json:"parameters"`
+	Strategy    Strategy        `json:"strategy"`
+	// CurrentWave is the zero-based index of the wave being updated
+	CurrentWave int       `json:"currentWave"`
+	Waves       int       `json:"waves"`
+	Progress    Progress  `json:"progress"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type CampaignsPage struct {
+	Data       []CampaignDTO `json:"data"`
+	Count      int           `json:"count"`
+	TotalCount int           `json:"totalCount"`
+}
+
+type InstanceDTO struct {
+	InstanceID  string        `json:"instanceID"`
+	Wave        int           `json:"wave"`
+	State       InstanceState `json:"state"`
+	OperationID string        `json:"operationID,omitempty"`
+	Message     string        `json:"message,omitempty"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+type InstancesPage struct {
+	Data       []InstanceDTO `json:"data"`
+	Count      int           `json:"count"`
+	TotalCount int           `json:"totalCount"`
+}
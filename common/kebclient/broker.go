@@ -0,0 +1,163 @@
+package kebclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+)
+
+// Catalog returns services and plans offered by KEB
+func (c *Client) Catalog(ctx context.Context) (apiresponses.CatalogResponse, error) {
+	var result apiresponses.CatalogResponse
+	_, err := c.call(ctx, request{method: http.MethodGet, path: c.brokerPath("/v2/catalog"), header: brokerHeader()}, &result)
+	return result, err
+}
+
+// Provision creates the instance asynchronously, the returned response contains the provisioning operation ID
+func (c *Client) Provision(ctx context.Context, instanceID string, details domain.ProvisionDetails) (apiresponses.ProvisioningResponse, error) {
+	var result apiresponses.ProvisioningResponse
+	_, err := c.call(ctx, request{
+		method:   http.MethodPut,
+		path:     c.brokerPath("/v2/service_instances/%s", url.PathEscape(instanceID)),
+		query:    url.Values{"accepts_incomplete": []string{"true"}},
+		body:     details,
+		header:   brokerHeader(),
+		expected: []int{http.StatusOK, http.StatusCreated, http.StatusAccepted},
+	}, &result)
+	return result, err
+}
+
+// GetInstance returns the instance with its parameters
+func (c *Client) GetInstance(ctx context.Context, instanceID string) (apiresponses.GetInstanceResponse, error) {
+	var result apiresponses.GetInstanceResponse
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   c.brokerPath("/v2/service_instances/%s", url.PathEscape(instanceID)),
+		header: brokerHeader(),
+	}, &result)
+	return result, err
+}
+
+// Update changes the plan, parameters or context of the instance, the returned response contains the update operation ID if the update is processed asynchronously
+func (c *Client) Update(ctx context.Context, instanceID string, details domain.UpdateDetails) (apiresponses.UpdateResponse, error) {
+	var result apiresponses.UpdateResponse
+	_, err := c.call(ctx, request{
+		method:   http.MethodPatch,
+		path:     c.brokerPath("/v2/service_instances/%s", url.PathEscape(instanceID)),
+		query:    url.Values{"accepts_incomplete": []string{"true"}},
+		body:     details,
+		header:   brokerHeader(),
+		expected: []int{http.StatusOK, http.StatusAccepted},
+	}, &result)
+	return result, err
+}
+
+// Deprovision deletes the instance asynchronously, the returned response contains the deprovisioning operation ID.
+// An empty response is returned if the instance does not exist.
+func (c *Client) Deprovision(ctx context.Context, instanceID, serviceID, planID string) (apiresponses.DeprovisionResponse, error) {
+	var result apiresponses.DeprovisionResponse
+	_, err := c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   c.brokerPath("/v2/service_instances/%s", url.PathEscape(instanceID)),
+		query: url.Values{
+			"accepts_incomplete": []string{"true"},
+			"service_id":         []string{serviceID},
+			"plan_id":            []string{planID},
+		},
+		header:   brokerHeader(),
+		expected: []int{http.StatusOK, http.StatusAccepted, http.StatusGone},
+	}, &result)
+	return result, err
+}
+
+// LastOperation returns the state of the instance operation, the last operation of the instance is returned if the operation ID is empty
+func (c *Client) LastOperation(ctx context.Context, instanceID, operationID string) (apiresponses.LastOperationResponse, error) {
+	query := url.Values{}
+	if operationID != "" {
+		query.Set("operation", operationID)
+	}
+	var result apiresponses.LastOperationResponse
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   c.brokerPath("/v2/service_instances/%s/last_operation", url.PathEscape(instanceID)),
+		query:  query,
+		header: brokerHeader(),
+	}, &result)
+	return result, err
+}
+
+// WaitForOperation polls the state of the instance operation until it succeeds, fails or the context is done.
+// An error is returned if the operation failed.
+func (c *Client) WaitForOperation(ctx context.Context, instanceID, operationID string, interval time.Duration) (apiresponses.LastOperationResponse, error) {
+	for {
+		result, err := c.LastOperation(ctx, instanceID, operationID)
+		if err != nil {
+			return result, err
+		}
+		switch result.State {
+		case domain.Succeeded:
+			return result, nil
+		case domain.Failed:
+			return result, fmt.Errorf("operation %s of the instance %s failed: %s", operationID, instanceID, result.Description)
+		}
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("while waiting for the operation %s of the instance %s: %w", operationID, instanceID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Bind creates the binding of the instance, the credentials contain the kubeconfig of the runtime
+func (c *Client) Bind(ctx context.Context, instanceID, bindingID string, details domain.BindDetails) (apiresponses.BindingResponse, error) {
+	var result apiresponses.BindingResponse
+	_, err := c.call(ctx, request{
+		method:   http.MethodPut,
+		path:     c.brokerPath("/v2/service_instances/%s/service_bindings/%s", url.PathEscape(instanceID), url.PathEscape(bindingID)),
+		body:     details,
+		header:   brokerHeader(),
+		expected: []int{http.StatusOK, http.StatusCreated},
+	}, &result)
+	return result, err
+}
+
+// GetBinding returns the binding of the instance
+func (c *Client) GetBinding(ctx context.Context, instanceID, bindingID string) (apiresponses.GetBindingResponse, error) {
+	var result apiresponses.GetBindingResponse
+	_, err := c.call(ctx, request{
+		method: http.MethodGet,
+		path:   c.brokerPath("/v2/service_instances/%s/service_bindings/%s", url.PathEscape(instanceID), url.PathEscape(bindingID)),
+		header: brokerHeader(),
+	}, &result)
+	return result, err
+}
+
+// Unbind deletes the binding of the instance, no error is returned if the binding does not exist
+func (c *Client) Unbind(ctx context.Context, instanceID, bindingID, serviceID, planID string) error {
+	_, err := c.call(ctx, request{
+		method: http.MethodDelete,
+		path:   c.brokerPath("/v2/service_instances/%s/service_bindings/%s", url.PathEscape(instanceID), url.PathEscape(bindingID)),
+		query: url.Values{
+			"service_id": []string{serviceID},
+			"plan_id":    []string{planID},
+		},
+		header:   brokerHeader(),
+		expected: []int{http.StatusOK, http.StatusGone},
+	}, nil)
+	return err
+}
+
+// RenewBinding issues new credentials for the existing binding, the response contains the kubeconfig and the new expiration time
+func (c *Client) RenewBinding(ctx context.Context, instanceID, bindingID string) (apiresponses.BindingResponse, error) {
+	var result apiresponses.BindingResponse
+	_, err := c.call(ctx, request{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/bindings/%s/%s/renew", url.PathEscape(instanceID), url.PathEscape(bindingID)),
+	}, &result)
+	return result, err
+}
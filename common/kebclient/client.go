@@ -0,0 +1,276 @@
+package kebclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	defaultRetries       = 3
+	defaultRetryInterval = time.Second
+	maxErrorBodySize     = 4096
+
+	brokerAPIVersionHeader = "X-Broker-API-Version"
+	brokerAPIVersion       = "2.14"
+)
+
+// Config contains the KEB location and the credentials used by the Client
+type Config struct {
+	// URL is the base URL of all KEB APIs, e.g. https://kyma-env-broker.kyma.local
+	URL string
+	// TokenURL, ClientID, ClientSecret and Scopes configure the OAuth2 client credentials flow, the flow is not used if TokenURL is empty
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Region is used in the path of the service broker API calls, e.g. /oauth/cf-eu10/v2/catalog, the default region of KEB is used if empty
+	Region string
+	// Retries is the number of retries of calls which failed because of network errors or 429, 502, 503 and 504 responses, defaults to 3.
+	// Use a negative value to disable retries.
+	Retries int
+	// RetryInterval is the time between retries if the response does not contain the Retry-After header, defaults to 1 second
+	RetryInterval time.Duration
+	// HTTPClient is the underlying HTTP client, it is wrapped by the OAuth2 transport if TokenURL is set, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Client is the HTTP client of the KEB APIs, all calls accept a context which cancels the call together with its retries
+type Client struct {
+	url           string
+	region        string
+	retries       int
+	retryInterval time.Duration
+	httpClient    *http.Client
+}
+
+// NewClient constructs and returns new Client for KEB APIs.
+// The context is used only to fetch OAuth2 tokens, it must not be canceled while the client is in use.
+func NewClient(ctx context.Context, cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if cfg.TokenURL != "" {
+		credentials := clientcredentials.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			TokenURL:     cfg.TokenURL,
+			Scopes:       cfg.Scopes,
+		}
+		httpClient = credentials.Client(context.WithValue(ctx, oauth2.HTTPClient, httpClient))
+	}
+
+	retries := cfg.Retries
+	if retries == 0 {
+		retries = defaultRetries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	retryInterval := cfg.RetryInterval
+	if retryInterval == 0 {
+		retryInterval = defaultRetryInterval
+	}
+
+	return &Client{
+		url:           strings.TrimSuffix(cfg.URL, "/"),
+		region:        cfg.Region,
+		retries:       retries,
+		retryInterval: retryInterval,
+		httpClient:    httpClient,
+	}
+}
+
+// APIError is returned when KEB responds with an unexpected status
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Message is the error returned by KEB in the response body
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("calling %s %s returned %d (%s) status", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("calling %s %s returned %d (%s) status: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound returns true if the error is an APIError with the 404 status
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if the error is an APIError with the 409 status
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	header http.Header
+	// expected lists accepted statuses, 200 is accepted if empty
+	expected []int
+}
+
+type errorBody struct {
+	Error       string `json:"error"`
+	Description string `json:"description"`
+}
+
+// call sends the request and decodes the JSON response into the result if it is not nil, the returned status is one of the expected ones
+func (c *Client) call(ctx context.Context, r request, result interface{}) (int, error) {
+	body, status, err := c.send(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	if result != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil {
+			return status, fmt.Errorf("while decoding response body: %w", err)
+		}
+	}
+	return status, nil
+}
+
+func (c *Client) send(ctx context.Context, r request) ([]byte, int, error) {
+	var payload []byte
+	if r.body != nil {
+		var err error
+		payload, err = json.Marshal(r.body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("while marshalling request body: %w", err)
+		}
+	}
+	target := c.url + r.path
+	if len(r.query) > 0 {
+		target = target + "?" + r.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, status, retryAfter, err := c.sendOnce(ctx, r, target, payload)
+		if retryAfter < 0 || attempt >= c.retries {
+			return body, status, err
+		}
+		if retryAfter == 0 {
+			retryAfter = c.retryInterval
+		}
+		select {
+		case <-ctx.Done():
+			return nil, 0, fmt.Errorf("while waiting for retry of %s %s: %w (last error: %s)", r.method, target, ctx.Err(), err)
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// sendOnce returns a negative retryAfter if the call must not be retried
+func (c *Client) sendOnce(ctx context.Context, r request, target string, payload []byte) ([]byte, int, time.Duration, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, target, reader)
+	if err != nil {
+		return nil, 0, -1, fmt.Errorf("while creating request: %w", err)
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("while calling %s %s: %w", r.method, target, err)
+		// POST calls are not idempotent, the request could be processed before the connection was lost
+		if ctx.Err() != nil || r.method == http.MethodPost {
+			return nil, 0, -1, err
+		}
+		return nil, 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("while reading response body of %s %s: %w", r.method, target, err)
+	}
+
+	expected := r.expected
+	if len(expected) == 0 {
+		expected = []int{http.StatusOK}
+	}
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return body, resp.StatusCode, -1, nil
+		}
+	}
+
+	apiErr := &APIError{Method: r.method, URL: target, StatusCode: resp.StatusCode, Message: errorMessage(body)}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return nil, resp.StatusCode, retryAfter(resp.Header), apiErr
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		if r.method == http.MethodPost {
+			return nil, resp.StatusCode, -1, apiErr
+		}
+		return nil, resp.StatusCode, 0, apiErr
+	default:
+		return nil, resp.StatusCode, -1, apiErr
+	}
+}
+
+func errorMessage(body []byte) string {
+	var e errorBody
+	if err := json.Unmarshal(body, &e); err == nil && (e.Error != "" || e.Description != "") {
+		if e.Error == "" || e.Description == "" {
+			return e.Error + e.Description
+		}
+		return fmt.Sprintf("%s: %s", e.Error, e.Description)
+	}
+	if len(body) > maxErrorBodySize {
+		body = body[:maxErrorBodySize]
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// retryAfter returns the delay from the Retry-After header given in seconds, 0 if it is not set
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *Client) brokerPath(format string, args ...interface{}) string {
+	prefix := "/oauth"
+	if c.region != "" {
+		prefix = prefix + "/" + url.PathEscape(c.region)
+	}
+	return prefix + fmt.Sprintf(format, args...)
+}
+
+func brokerHeader() http.Header {
+	return http.Header{brokerAPIVersionHeader: []string{brokerAPIVersion}}
+}
@@ -0,0 +1,331 @@
+package kebclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	instanceID = "instance-id"
+	serviceID  = "47c9dcbf-ff30-448e-ab36-d3bad66ba281"
+	awsPlanID  = "361c511f-f939-4621-b228-d0fb79a1fe15"
+)
+
+func TestClient_OAuth(t *testing.T) {
+	// given
+	var tokenRequests int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "client-id", user)
+		assert.Equal(t, "client-secret", password)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+	keb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data":[],"count":0,"totalCount":0}`))
+	}))
+	defer keb.Close()
+	client := NewClient(context.Background(), Config{
+		URL:          keb.URL,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	})
+
+	// when
+	_, err := client.ListRuntimes(context.Background(), runtime.ListParameters{})
+	require.NoError(t, err)
+	_, err = client.ListRuntimes(context.Background(), runtime.ListParameters{})
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokenRequests))
+}
+
+func TestClient_Retries(t *testing.T) {
+	t.Run("should retry unavailable server", func(t *testing.T) {
+		// given
+		keb := NewFakeServer()
+		defer keb.Close()
+		keb.FailNextRequests(http.StatusServiceUnavailable, 2)
+		keb.SetKubeconfig(instanceID, []byte("apiVersion: v1"))
+		client := NewClient(context.Background(), Config{URL: keb.URL, RetryInterval: time.Millisecond})
+
+		// when
+		kubeconfig, err := client.Kubeconfig(context.Background(), instanceID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "apiVersion: v1", string(kubeconfig))
+	})
+
+	t.Run("should return the last error when retries are exhausted", func(t *testing.T) {
+		// given
+		keb := NewFakeServer()
+		defer keb.Close()
+		keb.FailNextRequests(http.StatusTooManyRequests, 3)
+		client := NewClient(context.Background(), Config{URL: keb.URL, Retries: 2, RetryInterval: time.Millisecond})
+
+		// when
+		_, err := client.Catalog(context.Background())
+
+		// then
+		require.Error(t, err)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+		assert.Contains(t, err.Error(), "injected failure")
+	})
+
+	t.Run("should not retry client errors", func(t *testing.T) {
+		// given
+		var calls int32
+		keb := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"unsupported plan: aws"}`))
+		}))
+		defer keb.Close()
+		client := NewClient(context.Background(), Config{URL: keb.URL, RetryInterval: time.Millisecond})
+
+		// when
+		_, err := client.Expire(context.Background(), instanceID)
+
+		// then
+		assert.EqualError(t, err, fmt.Sprintf("calling PUT %s/expire/service_instance/%s returned 400 (Bad Request) status: unsupported plan: aws", keb.URL, instanceID))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should stop retrying when the context is canceled", func(t *testing.T) {
+		// given
+		keb := NewFakeServer()
+		defer keb.Close()
+		keb.FailNextRequests(http.StatusServiceUnavailable, 1)
+		client := NewClient(context.Background(), Config{URL: keb.URL, RetryInterval: time.Hour})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// when
+		_, err := client.RuntimesInfo(ctx)
+
+		// then
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestClient_Runtimes(t *testing.T) {
+	// given
+	keb := NewFakeServer()
+	defer keb.Close()
+	for i := 0; i < 5; i++ {
+		keb.AddRuntimes(runtime.RuntimeDTO{InstanceID: fmt.Sprintf("instance-%d", i), GlobalAccountID: "ga-1"})
+	}
+	keb.AddRuntimes(runtime.RuntimeDTO{InstanceID: "other", GlobalAccountID: "ga-2"})
+	client := NewClient(context.Background(), Config{URL: keb.URL})
+
+	t.Run("should iterate over all pages", func(t *testing.T) {
+		// when
+		var instanceIDs []string
+		for rt, err := range client.Runtimes(context.Background(), runtime.ListParameters{PageSize: 2, GlobalAccountIDs: []string{"ga-1"}}) {
+			require.NoError(t, err)
+			instanceIDs = append(instanceIDs, rt.InstanceID)
+		}
+
+		// then
+		assert.Equal(t, []string{"instance-0", "instance-1", "instance-2", "instance-3", "instance-4"}, instanceIDs)
+	})
+
+	t.Run("should return one page", func(t *testing.T) {
+		// when
+		page, err := client.ListRuntimes(context.Background(), runtime.ListParameters{Page: 2, PageSize: 4})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, page.Count)
+		assert.Equal(t, 6, page.TotalCount)
+		assert.Equal(t, "other", page.Data[1].InstanceID)
+	})
+
+	t.Run("should stop the iteration on error", func(t *testing.T) {
+		// given
+		keb.FailNextRequests(http.StatusInternalServerError, 1)
+
+		// when
+		var errs []error
+		for _, err := range client.Runtimes(context.Background(), runtime.ListParameters{}) {
+			errs = append(errs, err)
+		}
+
+		// then
+		require.Len(t, errs, 1)
+		assert.Error(t, errs[0])
+	})
+}
+
+func TestClient_Actions(t *testing.T) {
+	// given
+	keb := NewFakeServer()
+	defer keb.Close()
+	keb.AddActions(
+		runtime.ActionDTO{Action: runtime.Action{ID: "1", Type: runtime.PlanUpdateActionType, Actor: "admin"}, InstanceID: instanceID},
+		runtime.ActionDTO{Action: runtime.Action{ID: "2", Type: runtime.SubaccountMovementActionType}, InstanceID: instanceID},
+		runtime.ActionDTO{Action: runtime.Action{ID: "3", Type: runtime.PlanUpdateActionType, Actor: "admin"}, InstanceID: "other"},
+	)
+	client := NewClient(context.Background(), Config{URL: keb.URL})
+
+	// when
+	var ids []string
+	for action, err := range client.Actions(context.Background(), ActionsParameters{PageSize: 1, Types: []runtime.ActionType{runtime.PlanUpdateActionType}}) {
+		require.NoError(t, err)
+		ids = append(ids, action.ID)
+	}
+
+	// then
+	assert.Equal(t, []string{"1", "3"}, ids)
+}
+
+func TestClient_Events(t *testing.T) {
+	// given
+	keb := NewFakeServer()
+	defer keb.Close()
+	otherInstanceID := "other"
+	keb.AddEvents(
+		events.EventDTO{ID: "1", InstanceID: &otherInstanceID},
+		events.EventDTO{ID: "2", InstanceID: ptr(instanceID)},
+	)
+	keb.AddRuntimes(runtime.RuntimeDTO{InstanceID: instanceID, RuntimeID: "runtime-id"})
+	client := NewClient(context.Background(), Config{URL: keb.URL})
+
+	// when
+	result, err := client.ListEvents(context.Background(), EventsParameters{RuntimeIDs: []string{"runtime-id"}})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "2", result[0].ID)
+}
+
+func TestClient_ServiceBroker(t *testing.T) {
+	// given
+	keb := NewFakeServer()
+	defer keb.Close()
+	client := NewClient(context.Background(), Config{URL: keb.URL, Region: "cf-eu10"})
+	parameters := json.RawMessage(`{"name":"my-cluster","region":"eu-central-1"}`)
+
+	t.Run("should provision instance and wait for the operation", func(t *testing.T) {
+		// given
+		keb.SetNewOperationsState(domain.InProgress)
+
+		// when
+		response, err := client.Provision(context.Background(), instanceID, domain.ProvisionDetails{ServiceID: serviceID, PlanID: awsPlanID, RawParameters: parameters})
+		require.NoError(t, err)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			assert.NoError(t, keb.SetOperationState(instanceID, response.OperationData, domain.Succeeded, "Operation succeeded"))
+		}()
+		operation, err := client.WaitForOperation(context.Background(), instanceID, response.OperationData, 5*time.Millisecond)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "Operation succeeded", operation.Description)
+		_, planID, storedParameters, found := keb.Instance(instanceID)
+		require.True(t, found)
+		assert.Equal(t, awsPlanID, planID)
+		assert.JSONEq(t, string(parameters), string(storedParameters))
+	})
+
+	t.Run("should return failed operation", func(t *testing.T) {
+		// given
+		keb.SetNewOperationsState(domain.Failed)
+
+		// when
+		response, err := client.Update(context.Background(), instanceID, domain.UpdateDetails{ServiceID: serviceID, PlanID: awsPlanID})
+		require.NoError(t, err)
+		_, err = client.WaitForOperation(context.Background(), instanceID, response.OperationData, time.Millisecond)
+
+		// then
+		assert.Error(t, err)
+	})
+
+	t.Run("should bind and unbind instance", func(t *testing.T) {
+		// given
+		keb.SetKubeconfig(instanceID, []byte("apiVersion: v1"))
+
+		// when
+		binding, err := client.Bind(context.Background(), instanceID, "binding-id", domain.BindDetails{ServiceID: serviceID, PlanID: awsPlanID})
+		require.NoError(t, err)
+		fetched, err := client.GetBinding(context.Background(), instanceID, "binding-id")
+		require.NoError(t, err)
+		renewed, err := client.RenewBinding(context.Background(), instanceID, "binding-id")
+		require.NoError(t, err)
+		require.NoError(t, client.Unbind(context.Background(), instanceID, "binding-id", serviceID, awsPlanID))
+		_, err = client.GetBinding(context.Background(), instanceID, "binding-id")
+
+		// then
+		assert.Equal(t, map[string]interface{}{"kubeconfig": "apiVersion: v1"}, binding.Credentials)
+		assert.Equal(t, binding.Credentials, fetched.Credentials)
+		assert.Equal(t, binding.Credentials, renewed.Credentials)
+		assert.True(t, IsNotFound(err))
+	})
+
+	t.Run("should deprovision instance", func(t *testing.T) {
+		// given
+		keb.SetNewOperationsState(domain.Succeeded)
+
+		// when
+		response, err := client.Deprovision(context.Background(), instanceID, serviceID, awsPlanID)
+		require.NoError(t, err)
+		_, err = client.GetInstance(context.Background(), instanceID)
+
+		// then
+		assert.NotEmpty(t, response.OperationData)
+		assert.True(t, IsNotFound(err))
+		response, err = client.Deprovision(context.Background(), instanceID, serviceID, awsPlanID)
+		require.NoError(t, err)
+		assert.Empty(t, response.OperationData)
+	})
+}
+
+func TestClient_MachinesAvailability(t *testing.T) {
+	// given
+	keb := NewFakeServer()
+	defer keb.Close()
+	expected := MachinesAvailability{Providers: []MachinesAvailabilityProvider{{
+		Name: runtime.AWS,
+		MachineTypes: []MachineType{{
+			Name:    "g6.xlarge",
+			Regions: []MachineTypeRegion{{Name: "eu-central-1", HighAvailability: true, Zones: []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"}}},
+		}},
+	}}}
+	keb.SetMachinesAvailability(expected)
+	client := NewClient(context.Background(), Config{URL: keb.URL, Region: "cf-eu10"})
+
+	// when
+	result, err := client.MachinesAvailability(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,552 @@
+package kebclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+)
+
+// FakeServer is an in-memory KEB used in tests of the Client consumers. It serves data added by the Add and Set methods,
+// instances created by the service broker API are provisioned, updated and deprovisioned immediately unless
+// SetNewOperationsState is used.
+type FakeServer struct {
+	*httptest.Server
+
+	mu                 sync.Mutex
+	runtimes           []runtime.RuntimeDTO
+	actions            []runtime.ActionDTO
+	events             []events.EventDTO
+	history            map[string]History
+	runtimesInfo       []RuntimeInfo
+	kubeconfigs        map[string][]byte
+	machines           MachinesAvailability
+	catalog            apiresponses.CatalogResponse
+	instances          map[string]*fakeInstance
+	newOperationsState domain.LastOperationState
+	operationsCounter  int
+	failures           []int
+}
+
+type fakeInstance struct {
+	serviceID     string
+	planID        string
+	parameters    json.RawMessage
+	lastOperation string
+	operations    map[string]*apiresponses.LastOperationResponse
+	bindings      map[string]apiresponses.BindingResponse
+}
+
+// NewFakeServer starts the fake server, it must be closed by the caller
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		history:            make(map[string]History),
+		kubeconfigs:        make(map[string][]byte),
+		instances:          make(map[string]*fakeInstance),
+		newOperationsState: domain.Succeeded,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /runtimes", s.listRuntimes)
+	mux.HandleFunc("GET /runtimes/{id}/history", s.getHistory)
+	mux.HandleFunc("GET /actions", s.listActions)
+	mux.HandleFunc("GET /events", s.listEvents)
+	mux.HandleFunc("GET /info/runtimes", s.listRuntimesInfo)
+	mux.HandleFunc("PUT /expire/service_instance/{instance_id}", s.expire)
+	mux.HandleFunc("GET /kubeconfig/{instance_id}", s.getKubeconfig)
+	mux.HandleFunc("GET /oauth/v2/machines_availability", s.getMachinesAvailability)
+	mux.HandleFunc("POST /bindings/{instance_id}/{binding_id}/renew", s.renewBinding)
+	for _, prefix := range []string{"/oauth", "/oauth/{region}"} {
+		mux.HandleFunc("GET "+prefix+"/v2/catalog", s.getCatalog)
+		mux.HandleFunc("PUT "+prefix+"/v2/service_instances/{instance_id}", s.provision)
+		mux.HandleFunc("GET "+prefix+"/v2/service_instances/{instance_id}", s.getInstance)
+		mux.HandleFunc("PATCH "+prefix+"/v2/service_instances/{instance_id}", s.update)
+		mux.HandleFunc("DELETE "+prefix+"/v2/service_instances/{instance_id}", s.deprovision)
+		mux.HandleFunc("GET "+prefix+"/v2/service_instances/{instance_id}/last_operation", s.lastOperation)
+		mux.HandleFunc("PUT "+prefix+"/v2/service_instances/{instance_id}/service_bindings/{binding_id}", s.bind)
+		mux.HandleFunc("GET "+prefix+"/v2/service_instances/{instance_id}/service_bindings/{binding_id}", s.getBinding)
+		mux.HandleFunc("DELETE "+prefix+"/v2/service_instances/{instance_id}/service_bindings/{binding_id}", s.unbind)
+	}
+
+	s.Server = httptest.NewServer(s.withFailures(mux))
+	return s
+}
+
+// AddRuntimes adds runtimes returned by the GET /runtimes endpoint
+func (s *FakeServer) AddRuntimes(runtimes ...runtime.RuntimeDTO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runtimes = append(s.runtimes, runtimes...)
+}
+
+// AddActions adds actions returned by the GET /actions endpoint
+func (s *FakeServer) AddActions(actions ...runtime.ActionDTO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, actions...)
+}
+
+// AddEvents adds events returned by the GET /events endpoint
+func (s *FakeServer) AddEvents(events ...events.EventDTO) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+}
+
+// AddRuntimesInfo adds items returned by the GET /info/runtimes endpoint
+func (s *FakeServer) AddRuntimesInfo(items ...RuntimeInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runtimesInfo = append(s.runtimesInfo, items...)
+}
+
+// SetHistory sets the history returned for the runtime or instance ID
+func (s *FakeServer) SetHistory(id string, history History) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[id] = history
+}
+
+// SetKubeconfig sets the kubeconfig of the instance
+func (s *FakeServer) SetKubeconfig(instanceID string, kubeconfig []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kubeconfigs[instanceID] = kubeconfig
+}
+
+// SetMachinesAvailability sets the response of the GET /oauth/v2/machines_availability endpoint
+func (s *FakeServer) SetMachinesAvailability(machines MachinesAvailability) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.machines = machines
+}
+
+// SetCatalog sets the response of the GET /oauth/v2/catalog endpoint
+func (s *FakeServer) SetCatalog(catalog apiresponses.CatalogResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.catalog = catalog
+}
+
+// SetNewOperationsState sets the state of operations created by next provisioning, update and deprovisioning requests.
+// Use domain.InProgress to test polling and SetOperationState to finish the operation.
+func (s *FakeServer) SetNewOperationsState(state domain.LastOperationState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newOperationsState = state
+}
+
+// SetOperationState changes the state of the existing operation of the instance
+func (s *FakeServer) SetOperationState(instanceID, operationID string, state domain.LastOperationState, description string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[instanceID]
+	if !found {
+		return fmt.Errorf("instance %s not found", instanceID)
+	}
+	operation, found := instance.operations[operationID]
+	if !found {
+		return fmt.Errorf("operation %s of the instance %s not found", operationID, instanceID)
+	}
+	operation.State = state
+	operation.Description = description
+	return nil
+}
+
+// FailNextRequests makes the server respond to the next count requests with the given status, for example, to test retries
+func (s *FakeServer) FailNextRequests(status, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < count; i++ {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// Instance returns the service and plan IDs and parameters of the instance created by the service broker API
+func (s *FakeServer) Instance(instanceID string) (serviceID, planID string, parameters json.RawMessage, found bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[instanceID]
+	if !found {
+		return "", "", nil, false
+	}
+	return instance.serviceID, instance.planID, instance.parameters, true
+}
+
+func (s *FakeServer) withFailures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		if len(s.failures) > 0 {
+			status := s.failures[0]
+			s.failures = s.failures[1:]
+			s.mu.Unlock()
+			writeFakeError(w, status, "injected failure")
+			return
+		}
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *FakeServer) listRuntimes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	var matching []runtime.RuntimeDTO
+	for _, rt := range s.runtimes {
+		if matches(query[runtime.InstanceIDParam], rt.InstanceID) &&
+			matches(query[runtime.RuntimeIDParam], rt.RuntimeID) &&
+			matches(query[runtime.GlobalAccountIDParam], rt.GlobalAccountID) &&
+			matches(query[runtime.SubAccountIDParam], rt.SubAccountID) &&
+			matches(query[runtime.RegionParam], rt.ProviderRegion) &&
+			matches(query[runtime.ShootParam], rt.ShootName) &&
+			matches(query[runtime.PlanParam], rt.ServicePlanName) {
+			matching = append(matching, rt)
+		}
+	}
+	s.mu.Unlock()
+
+	items := matching
+	if cursor := query.Get(pagination.CursorParam); cursor != "" {
+		_, instanceID, err := pagination.DecodeCursor(cursor)
+		if err != nil {
+			writeFakeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		index := slices.IndexFunc(matching, func(rt runtime.RuntimeDTO) bool { return rt.InstanceID == instanceID })
+		items = matching[index+1:]
+		query.Del(pagination.PageParam)
+		r.URL.RawQuery = query.Encode()
+	}
+	data, ok := page(w, r, items)
+	if !ok {
+		return
+	}
+	result := runtime.RuntimesPage{Data: data, Count: len(data), TotalCount: len(matching)}
+	if len(data) > 0 && data[len(data)-1].InstanceID != items[len(items)-1].InstanceID {
+		last := data[len(data)-1]
+		result.NextCursor = pagination.EncodeCursor(last.Status.CreatedAt, last.InstanceID)
+	}
+	writeFakeResponse(w, http.StatusOK, result)
+}
+
+func (s *FakeServer) listActions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	var matching []runtime.ActionDTO
+	for _, action := range s.actions {
+		if matches(query[runtime.InstanceIDParam], action.InstanceID) &&
+			matches(query[runtime.ActionTypeParam], string(action.Type)) &&
+			matches(query[runtime.ActorParam], action.Actor) {
+			matching = append(matching, action)
+		}
+	}
+	s.mu.Unlock()
+
+	data, ok := page(w, r, matching)
+	if !ok {
+		return
+	}
+	writeFakeResponse(w, http.StatusOK, runtime.ActionsPage{Data: data, Count: len(data), TotalCount: len(matching)})
+}
+
+func (s *FakeServer) getHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	history, found := s.history[r.PathValue("id")]
+	s.mu.Unlock()
+	if !found {
+		writeFakeError(w, http.StatusNotFound, fmt.Sprintf("history of the runtime or instance %s not found", r.PathValue("id")))
+		return
+	}
+	writeFakeResponse(w, http.StatusOK, history)
+}
+
+func (s *FakeServer) listEvents(w http.ResponseWriter, r *http.Request) {
+	instanceIDs := splitParam(r.URL.Query().Get("instance_ids"))
+	operationIDs := splitParam(r.URL.Query().Get("operation_ids"))
+	s.mu.Lock()
+	runtimeIDs := splitParam(r.URL.Query().Get("runtime_ids"))
+	for _, rt := range s.runtimes {
+		if slices.Contains(runtimeIDs, rt.RuntimeID) {
+			instanceIDs = append(instanceIDs, rt.InstanceID)
+		}
+	}
+	result := make([]events.EventDTO, 0)
+	for _, event := range s.events {
+		if (len(instanceIDs) == 0 || event.InstanceID != nil && slices.Contains(instanceIDs, *event.InstanceID)) &&
+			(len(operationIDs) == 0 || event.OperationID != nil && slices.Contains(operationIDs, *event.OperationID)) {
+			result = append(result, event)
+		}
+	}
+	s.mu.Unlock()
+	writeFakeResponse(w, http.StatusOK, result)
+}
+
+func (s *FakeServer) listRuntimesInfo(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeFakeResponse(w, http.StatusOK, append(make([]RuntimeInfo, 0, len(s.runtimesInfo)), s.runtimesInfo...))
+}
+
+func (s *FakeServer) expire(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, fmt.Sprintf("instance %s not found", r.PathValue("instance_id")))
+		return
+	}
+	operationID := s.newOperation(instance)
+	writeFakeResponse(w, http.StatusAccepted, ExpirationResponse{OperationID: operationID})
+}
+
+func (s *FakeServer) getKubeconfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	kubeconfig, found := s.kubeconfigs[r.PathValue("instance_id")]
+	s.mu.Unlock()
+	if !found {
+		writeFakeError(w, http.StatusNotFound, fmt.Sprintf("kubeconfig for instance %s does not exist", r.PathValue("instance_id")))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-yaml")
+	_, _ = w.Write(kubeconfig)
+}
+
+func (s *FakeServer) getMachinesAvailability(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeFakeResponse(w, http.StatusOK, s.machines)
+}
+
+func (s *FakeServer) getCatalog(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeFakeResponse(w, http.StatusOK, s.catalog)
+}
+
+func (s *FakeServer) provision(w http.ResponseWriter, r *http.Request) {
+	var details domain.ProvisionDetails
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		writeFakeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if instance, found := s.instances[r.PathValue("instance_id")]; found {
+		if instance.planID != details.PlanID {
+			writeFakeError(w, http.StatusConflict, "instance already exists with different parameters")
+			return
+		}
+		writeFakeResponse(w, http.StatusOK, apiresponses.ProvisioningResponse{OperationData: instance.lastOperation})
+		return
+	}
+	instance := &fakeInstance{
+		serviceID:  details.ServiceID,
+		planID:     details.PlanID,
+		parameters: details.RawParameters,
+		operations: make(map[string]*apiresponses.LastOperationResponse),
+		bindings:   make(map[string]apiresponses.BindingResponse),
+	}
+	s.instances[r.PathValue("instance_id")] = instance
+	writeFakeResponse(w, http.StatusAccepted, apiresponses.ProvisioningResponse{OperationData: s.newOperation(instance)})
+}
+
+func (s *FakeServer) getInstance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "instance does not exist")
+		return
+	}
+	writeFakeResponse(w, http.StatusOK, apiresponses.GetInstanceResponse{
+		ServiceID:  instance.serviceID,
+		PlanID:     instance.planID,
+		Parameters: instance.parameters,
+	})
+}
+
+func (s *FakeServer) update(w http.ResponseWriter, r *http.Request) {
+	var details domain.UpdateDetails
+	if err := json.NewDecoder(r.Body).Decode(&details); err != nil {
+		writeFakeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "instance does not exist")
+		return
+	}
+	if details.PlanID != "" {
+		instance.planID = details.PlanID
+	}
+	if len(details.RawParameters) > 0 {
+		instance.parameters = details.RawParameters
+	}
+	writeFakeResponse(w, http.StatusAccepted, apiresponses.UpdateResponse{OperationData: s.newOperation(instance)})
+}
+
+func (s *FakeServer) deprovision(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeResponse(w, http.StatusGone, apiresponses.EmptyResponse{})
+		return
+	}
+	operationID := s.newOperation(instance)
+	if instance.operations[operationID].State == domain.Succeeded {
+		delete(s.instances, r.PathValue("instance_id"))
+		delete(s.kubeconfigs, r.PathValue("instance_id"))
+	}
+	writeFakeResponse(w, http.StatusAccepted, apiresponses.DeprovisionResponse{OperationData: operationID})
+}
+
+func (s *FakeServer) lastOperation(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusGone, "instance does not exist")
+		return
+	}
+	operationID := r.URL.Query().Get("operation")
+	if operationID == "" {
+		operationID = instance.lastOperation
+	}
+	operation, found := instance.operations[operationID]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, fmt.Sprintf("operation %s does not exist", operationID))
+		return
+	}
+	writeFakeResponse(w, http.StatusOK, operation)
+}
+
+func (s *FakeServer) bind(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "instance does not exist")
+		return
+	}
+	if binding, found := instance.bindings[r.PathValue("binding_id")]; found {
+		writeFakeResponse(w, http.StatusOK, binding)
+		return
+	}
+	kubeconfig, found := s.kubeconfigs[r.PathValue("instance_id")]
+	if !found {
+		kubeconfig = []byte(fmt.Sprintf("# kubeconfig of the binding %s", r.PathValue("binding_id")))
+	}
+	binding := apiresponses.BindingResponse{Credentials: map[string]string{"kubeconfig": string(kubeconfig)}}
+	instance.bindings[r.PathValue("binding_id")] = binding
+	writeFakeResponse(w, http.StatusCreated, binding)
+}
+
+func (s *FakeServer) getBinding(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "instance does not exist")
+		return
+	}
+	binding, found := instance.bindings[r.PathValue("binding_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "binding does not exist")
+		return
+	}
+	writeFakeResponse(w, http.StatusOK, apiresponses.GetBindingResponse{BindingResponse: binding})
+}
+
+func (s *FakeServer) unbind(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeResponse(w, http.StatusGone, apiresponses.EmptyResponse{})
+		return
+	}
+	if _, found := instance.bindings[r.PathValue("binding_id")]; !found {
+		writeFakeResponse(w, http.StatusGone, apiresponses.EmptyResponse{})
+		return
+	}
+	delete(instance.bindings, r.PathValue("binding_id"))
+	writeFakeResponse(w, http.StatusOK, apiresponses.EmptyResponse{})
+}
+
+func (s *FakeServer) renewBinding(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, found := s.instances[r.PathValue("instance_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "instance does not exist")
+		return
+	}
+	binding, found := instance.bindings[r.PathValue("binding_id")]
+	if !found {
+		writeFakeError(w, http.StatusNotFound, "binding not found")
+		return
+	}
+	writeFakeResponse(w, http.StatusOK, binding)
+}
+
+// newOperation must be called with the lock held
+func (s *FakeServer) newOperation(instance *fakeInstance) string {
+	s.operationsCounter++
+	operationID := fmt.Sprintf("operation-%d", s.operationsCounter)
+	instance.operations[operationID] = &apiresponses.LastOperationResponse{State: s.newOperationsState}
+	instance.lastOperation = operationID
+	return operationID
+}
+
+func page[T any](w http.ResponseWriter, r *http.Request, items []T) ([]T, bool) {
+	pageNumber, pageSize := 1, defaultPageSize
+	for param, target := range map[string]*int{pagination.PageParam: &pageNumber, pagination.PageSizeParam: &pageSize} {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeFakeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a positive number", param))
+			return nil, false
+		}
+		*target = parsed
+	}
+	start := min((pageNumber-1)*pageSize, len(items))
+	end := min(start+pageSize, len(items))
+	return append(make([]T, 0, end-start), items[start:end]...), true
+}
+
+func matches(filter []string, value string) bool {
+	return len(filter) == 0 || slices.Contains(filter, value)
+}
+
+func splitParam(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func writeFakeResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, message string) {
+	writeFakeResponse(w, status, errorBody{Error: message})
+}
@@ -0,0 +1,105 @@
+package kebclient
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+)
+
+// History is the response of the GET /runtimes/{id}/history endpoint
+type History struct {
+	InstanceID string           `json:"instanceID"`
+	Versions   []HistoryVersion `json:"versions"`
+}
+
+// HistoryVersion contains changes of instance parameters made by the operation
+type HistoryVersion struct {
+	Version       int       `json:"version"`
+	OperationID   string    `json:"operationID"`
+	OperationType string    `json:"operationType"`
+	RuntimeID     string    `json:"runtimeID"`
+	PlanID        string    `json:"planID"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Changes       []Change  `json:"changes"`
+}
+
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// RuntimeInfo is the item of the GET /info/runtimes response
+type RuntimeInfo struct {
+	RuntimeID         string            `json:"runtimeId"`
+	GlobalAccountID   string            `json:"globalAccountId"`
+	SubAccountID      string            `json:"subaccountId"`
+	SubAccountRegion  string            `json:"subaccountRegion"`
+	ServiceInstanceID string            `json:"serviceInstanceId"`
+	ServiceClassID    string            `json:"serviceClassId"`
+	ServiceClassName  string            `json:"serviceClassName"`
+	ServicePlanID     string            `json:"servicePlanId"`
+	ServicePlanName   string            `json:"servicePlanName"`
+	Status            RuntimeInfoStatus `json:"status"`
+}
+
+type RuntimeInfoStatus struct {
+	CreatedAt      *time.Time                  `json:"createdAt,omitempty"`
+	UpdatedAt      *time.Time                  `json:"updatedAt,omitempty"`
+	DeletedAt      *time.Time                  `json:"deletedAt,omitempty"`
+	Provisioning   *RuntimeInfoOperationStatus `json:"provisioning,omitempty"`
+	Deprovisioning *RuntimeInfoOperationStatus `json:"deprovisioning,omitempty"`
+}
+
+type RuntimeInfoOperationStatus struct {
+	State       string `json:"state"`
+	Description string `json:"description"`
+}
+
+// MachinesAvailability is the response of the GET /oauth/v2/machines_availability endpoint
+type MachinesAvailability struct {
+	Providers []MachinesAvailabilityProvider `json:"providers"`
+}
+
+type MachinesAvailabilityProvider struct {
+	Name         runtime.CloudProvider `json:"name"`
+	MachineTypes []MachineType         `json:"machine_types"`
+}
+
+type MachineType struct {
+	Name    string              `json:"name"`
+	Regions []MachineTypeRegion `json:"regions"`
+}
+
+type MachineTypeRegion struct {
+	Name             string `json:"name"`
+	HighAvailability bool   `json:"high_availability"`
+	// Zones lists zones in which the machine type is available, the list is empty if zones are not known
+	Zones     []string   `json:"zones"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ActionsParameters filters actions returned by the GET /actions endpoint
+type ActionsParameters struct {
+	// Page and PageSize select the page of actions, the first page of the default size is returned if not set
+	Page          int
+	PageSize      int
+	InstanceIDs   []string
+	Types         []runtime.ActionType
+	Actors        []string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// EventsParameters filters events returned by the GET /events endpoint
+type EventsParameters struct {
+	InstanceIDs  []string
+	RuntimeIDs   []string
+	OperationIDs []string
+}
+
+// ExpirationResponse is the response of the PUT /expire/service_instance/{instance_id} endpoint
+type ExpirationResponse struct {
+	// OperationID is the ID of the suspension operation
+	OperationID string `json:"operation"`
+}
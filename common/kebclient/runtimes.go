@@ -0,0 +1,192 @@
+package kebclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/events"
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+)
+
+const defaultPageSize = 100
+
+// ListRuntimes returns one page of runtimes matching the given parameters, the first page of 100 runtimes is returned if the page is not set
+func (c *Client) ListRuntimes(ctx context.Context, params runtime.ListParameters) (runtime.RuntimesPage, error) {
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = defaultPageSize
+	}
+	query := &url.URL{}
+	runtime.SetQuery(query, params)
+
+	var page runtime.RuntimesPage
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/runtimes", query: query.Query()}, &page)
+	return page, err
+}
+
+// Runtimes iterates over all runtimes matching the given parameters, pages are fetched when needed following the cursors returned by KEB,
+// so runtimes created during the iteration do not shift pages. Deprovisioned runtimes are paged by numbers starting from params.Page.
+// The iteration stops after the first error.
+func (c *Client) Runtimes(ctx context.Context, params runtime.ListParameters) iter.Seq2[runtime.RuntimeDTO, error] {
+	return func(yield func(runtime.RuntimeDTO, error) bool) {
+		if params.Page == 0 {
+			params.Page = 1
+		}
+		if params.PageSize == 0 {
+			params.PageSize = defaultPageSize
+		}
+		for {
+			page, err := c.ListRuntimes(ctx, params)
+			if err != nil {
+				yield(runtime.RuntimeDTO{}, err)
+				return
+			}
+			for _, rt := range page.Data {
+				if !yield(rt, nil) {
+					return
+				}
+			}
+			if page.NextCursor != "" {
+				params.Cursor = page.NextCursor
+				continue
+			}
+			if params.Cursor != "" || len(page.Data) == 0 || (params.Page-1)*params.PageSize+len(page.Data) >= page.TotalCount {
+				return
+			}
+			params.Page++
+		}
+	}
+}
+
+// RuntimeHistory returns versions of parameters of the instance, the ID is a runtime ID or an instance ID
+func (c *Client) RuntimeHistory(ctx context.Context, id string) (History, error) {
+	var history History
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/runtimes/" + url.PathEscape(id) + "/history"}, &history)
+	return history, err
+}
+
+// ListActions returns one page of actions matching the given parameters
+func (c *Client) ListActions(ctx context.Context, params ActionsParameters) (runtime.ActionsPage, error) {
+	query := url.Values{}
+	if params.Page != 0 {
+		query.Set(pagination.PageParam, strconv.Itoa(params.Page))
+	}
+	if params.PageSize != 0 {
+		query.Set(pagination.PageSizeParam, strconv.Itoa(params.PageSize))
+	}
+	query[runtime.InstanceIDParam] = params.InstanceIDs
+	for _, actionType := range params.Types {
+		query.Add(runtime.ActionTypeParam, string(actionType))
+	}
+	query[runtime.ActorParam] = params.Actors
+	if !params.CreatedAfter.IsZero() {
+		query.Set(runtime.CreatedAfterParam, params.CreatedAfter.Format(time.RFC3339))
+	}
+	if !params.CreatedBefore.IsZero() {
+		query.Set(runtime.CreatedBeforeParam, params.CreatedBefore.Format(time.RFC3339))
+	}
+
+	var page runtime.ActionsPage
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/actions", query: query}, &page)
+	return page, err
+}
+
+// Actions iterates over all actions matching the given parameters, pages are fetched when needed starting from params.Page.
+// The iteration stops after the first error.
+func (c *Client) Actions(ctx context.Context, params ActionsParameters) iter.Seq2[runtime.ActionDTO, error] {
+	if params.PageSize == 0 {
+		params.PageSize = defaultPageSize
+	}
+	return paginate(params.Page, func(page int) ([]runtime.ActionDTO, int, error) {
+		params.Page = page
+		result, err := c.ListActions(ctx, params)
+		return result.Data, result.TotalCount, err
+	})
+}
+
+// ListEvents returns tracing events of instances and operations
+func (c *Client) ListEvents(ctx context.Context, params EventsParameters) ([]events.EventDTO, error) {
+	query := url.Values{}
+	if len(params.InstanceIDs) > 0 {
+		query.Set("instance_ids", strings.Join(params.InstanceIDs, ","))
+	}
+	if len(params.RuntimeIDs) > 0 {
+		query.Set("runtime_ids", strings.Join(params.RuntimeIDs, ","))
+	}
+	if len(params.OperationIDs) > 0 {
+		query.Set("operation_ids", strings.Join(params.OperationIDs, ","))
+	}
+
+	var result []events.EventDTO
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/events", query: query}, &result)
+	return result, err
+}
+
+// RuntimesInfo returns all instances with the state of their provisioning and deprovisioning operations
+func (c *Client) RuntimesInfo(ctx context.Context) ([]RuntimeInfo, error) {
+	var result []RuntimeInfo
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/info/runtimes"}, &result)
+	return result, err
+}
+
+// Expire suspends the trial or free instance and marks it as expired, the returned response contains the suspension operation ID
+func (c *Client) Expire(ctx context.Context, instanceID string) (ExpirationResponse, error) {
+	var result ExpirationResponse
+	_, err := c.call(ctx, request{
+		method:   http.MethodPut,
+		path:     "/expire/service_instance/" + url.PathEscape(instanceID),
+		expected: []int{http.StatusAccepted},
+	}, &result)
+	return result, err
+}
+
+// Kubeconfig returns the kubeconfig of the runtime of the given instance
+func (c *Client) Kubeconfig(ctx context.Context, instanceID string) ([]byte, error) {
+	body, _, err := c.send(ctx, request{method: http.MethodGet, path: "/kubeconfig/" + url.PathEscape(instanceID)})
+	return body, err
+}
+
+// MachinesAvailability returns regions and zones in which machine types of all providers are available
+func (c *Client) MachinesAvailability(ctx context.Context) (MachinesAvailability, error) {
+	var result MachinesAvailability
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/oauth/v2/machines_availability"}, &result)
+	return result, err
+}
+
+// paginate iterates over items of pages, fetching the next page until all items counted by the total count are returned.
+// The size of the first page is used as the page size, because KEB can return fewer items than requested.
+func paginate[T any](first int, fetch func(page int) ([]T, int, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if first == 0 {
+			first = 1
+		}
+		pageSize := 0
+		for page := first; ; page++ {
+			items, totalCount, err := fetch(page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if pageSize == 0 {
+				pageSize = len(items)
+			}
+			if len(items) == 0 || (page-1)*pageSize+len(items) >= totalCount {
+				return
+			}
+		}
+	}
+}
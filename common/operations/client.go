@@ -0,0 +1,60 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Client is the interface to interact with the KEB /operations API as an HTTP client using OIDC ID token in JWT format.
+type Client interface {
+	RetryOperation(operationID string, request RetryRequest) (OperationResponse, error)
+}
+
+type client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewClient constructs and returns new Client for KEB /operations API
+// It takes the following arguments:
+//   - url        : base url of all KEB APIs, e.g. https://kyma-env-broker.kyma.local
+//   - httpClient : underlying HTTP client used for API call to KEB
+func NewClient(url string, httpClient *http.Client) Client {
+	return &client{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// RetryOperation puts the failed operation back to the KEB processing queue.
+func (c *client) RetryOperation(operationID string, request RetryRequest) (OperationResponse, error) {
+	response := OperationResponse{}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return response, fmt.Errorf("while marshalling request body: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/operations/%s/retry", c.url, operationID), bytes.NewReader(body))
+	if err != nil {
+		return response, fmt.Errorf("while creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return response, fmt.Errorf("while calling %s: %w", req.URL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		details, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return response, fmt.Errorf("calling %s returned %d (%s) status: %s", req.URL.String(), resp.StatusCode, resp.Status, string(details))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return response, fmt.Errorf("while decoding response body: %w", err)
+	}
+	return response, nil
+}
@@ -0,0 +1,54 @@
+package operations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RetryOperation(t *testing.T) {
+	t.Run("should send retry request", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/operations/op-id/retry", r.URL.Path)
+			var request RetryRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "create_runtime", request.Stage)
+
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(OperationResponse{OperationID: "op-id", State: "in progress"})
+		}))
+		defer ts.Close()
+		client := NewClient(ts.URL, ts.Client())
+
+		// when
+		response, err := client.RetryOperation("op-id", RetryRequest{Stage: "create_runtime"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, OperationResponse{OperationID: "op-id", State: "in progress"}, response)
+	})
+
+	t.Run("should return error with details when the operation cannot be retried", func(t *testing.T) {
+		// given
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"only failed operations can be retried"}`))
+		}))
+		defer ts.Close()
+		client := NewClient(ts.URL, ts.Client())
+
+		// when
+		_, err := client.RetryOperation("op-id", RetryRequest{})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "409")
+		assert.Contains(t, err.Error(), "only failed operations can be retried")
+	})
+}
@@ -0,0 +1,13 @@
+package operations
+
+// RetryRequest is the body of the KEB /operations/{operation_id}/retry request
+type RetryRequest struct {
+	// Stage is a finished stage which must be processed again, all other finished stages are skipped
+	Stage string `json:"stage,omitempty"`
+}
+
+// OperationResponse is returned by the KEB /operations API when the operation is accepted for processing
+type OperationResponse struct {
+	OperationID string `json:"operation"`
+	State       string `json:"state"`
+}
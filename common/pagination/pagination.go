@@ -1,9 +1,12 @@
 package pagination
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func ConvertPageSizeAndOrderedColumnToSQL(pageSize, page int, orderedColumn string) (string, error) {
@@ -37,6 +40,8 @@ func ConvertPageAndPageSizeToOffset(pageSize, page int) int {
 const (
 	PageSizeParam = "page_size"
 	PageParam     = "page"
+	// CursorParam selects the keyset pagination, the page starts after the item the cursor points to
+	CursorParam = "cursor"
 )
 
 func ExtractPaginationConfigFromRequest(req *http.Request, maxPage int) (int, int, error) {
@@ -84,3 +89,26 @@ func ExtractPaginationConfigFromRequest(req *http.Request, maxPage int) (int, in
 
 	return pageSize, page, nil
 }
+
+// EncodeCursor returns an opaque cursor pointing to the item with the given creation time and ID,
+// the next page contains items created after the item
+func EncodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+// DecodeCursor returns the creation time and ID of the item the cursor points to
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	createdAt, id, found := strings.Cut(string(decoded), "|")
+	if !found || id == "" {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	return t, id, nil
+}
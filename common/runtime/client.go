@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/pagination"
 )
@@ -39,7 +40,7 @@ func NewClient(url string, httpClient *http.Client) Client {
 }
 
 // ListRuntimes fetches the runtimes from KEB according to the given parameters.
-// If params.Page or params.PageSize is not set (zero), the client will fetch and return all runtimes.
+// If params.Page or params.PageSize is not set (zero), the client will fetch and return all runtimes following the cursors returned by KEB.
 func (c *client) ListRuntimes(params ListParameters) (RuntimesPage, error) {
 	runtimes := RuntimesPage{}
 	getAll := false
@@ -63,7 +64,7 @@ func (c *client) ListRuntimes(params ListParameters) (RuntimesPage, error) {
 		if err != nil {
 			return runtimes, fmt.Errorf("while creating request: %w", err)
 		}
-		setQuery(req.URL, params)
+		SetQuery(req.URL, params)
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
@@ -97,8 +98,11 @@ func (c *client) ListRuntimes(params ListParameters) (RuntimesPage, error) {
 		runtimes.Count += rp.Count
 		runtimes.Data = append(runtimes.Data, rp.Data...)
 		if getAll {
+			// the next page is fetched by the cursor if KEB returned it, so runtimes created in the meantime do not shift pages
+			usedCursor := params.Cursor != ""
 			params.Page++
-			fetchedAll = runtimes.Count >= runtimes.TotalCount
+			params.Cursor = rp.NextCursor
+			fetchedAll = runtimes.Count >= runtimes.TotalCount || len(rp.Data) == 0 || (usedCursor && rp.NextCursor == "")
 		} else {
 			fetchedAll = true
 		}
@@ -113,10 +117,14 @@ func (c *client) ListRuntimes(params ListParameters) (RuntimesPage, error) {
 	return runtimes, nil
 }
 
-func setQuery(url *url.URL, params ListParameters) {
+// SetQuery sets the query of the given /runtimes URL according to the given parameters
+func SetQuery(url *url.URL, params ListParameters) {
 	query := url.Query()
 	query.Add(pagination.PageParam, strconv.Itoa(params.Page))
 	query.Add(pagination.PageSizeParam, strconv.Itoa(params.PageSize))
+	if params.Cursor != "" {
+		query.Add(pagination.CursorParam, params.Cursor)
+	}
 	if params.OperationDetail != "" {
 		query.Add(OperationDetailParam, string(params.OperationDetail))
 	}
@@ -154,6 +162,24 @@ func setQuery(url *url.URL, params ListParameters) {
 	for _, s := range params.States {
 		query.Add(StateParam, string(s))
 	}
+	setParamList(query, ProviderParam, params.Providers)
+	setParamList(query, LicenseTypeParam, params.LicenseTypes)
+	setParamList(query, CommercialModelParam, params.CommercialModels)
+	setParamList(query, MachineTypeParam, params.MachineTypes)
+	setParamList(query, KubernetesVersionParam, params.KubernetesVersions)
+	if params.AdditionalWorkerNodePools != nil {
+		query.Add(AdditionalWorkerNodePoolsParam, strconv.FormatBool(*params.AdditionalWorkerNodePools))
+	}
+	setParamList(query, ErrorComponentParam, params.ErrorComponents)
+	setParamList(query, ErrorReasonParam, params.ErrorReasons)
+	setParamTime(query, CreatedAfterParam, params.CreatedAfter)
+	setParamTime(query, CreatedBeforeParam, params.CreatedBefore)
+	setParamTime(query, UpdatedAfterParam, params.UpdatedAfter)
+	setParamTime(query, UpdatedBeforeParam, params.UpdatedBefore)
+	if params.LabelSelector != "" {
+		query.Add(LabelSelectorParam, params.LabelSelector)
+	}
+	setParamList(query, SortParam, params.Sort)
 	url.RawQuery = query.Encode()
 }
 
@@ -163,6 +189,12 @@ func setParamList(query url.Values, key string, values []string) {
 	}
 }
 
+func setParamTime(query url.Values, key string, value time.Time) {
+	if !value.IsZero() {
+		query.Add(key, value.Format(time.RFC3339))
+	}
+}
+
 func drainResponseBody(body io.Reader) error {
 	if body == nil {
 		return nil
@@ -121,6 +121,33 @@ func TestClient_ListRuntimes(t *testing.T) {
 		assert.Len(t, rp.Data, 4)
 	})
 
+	t.Run("test pagination with cursor", func(t *testing.T) {
+		// given
+		var cursors []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cursor := r.URL.Query().Get(pagination.CursorParam)
+			cursors = append(cursors, cursor)
+			rp := RuntimesPage{Data: []RuntimeDTO{runtime1, runtime2}, Count: 2, TotalCount: 4, NextCursor: "next"}
+			if cursor == "next" {
+				rp.Data = []RuntimeDTO{fixRuntimeDTO("3")}
+				rp.Count = 1
+				rp.NextCursor = ""
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(rp))
+		}))
+		defer ts.Close()
+		client := NewClient(ts.URL, oauth2.NewClient(context.Background(), fixToken))
+
+		// when
+		rp, err := client.ListRuntimes(ListParameters{PageSize: 2})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "next"}, cursors)
+		assert.Equal(t, 3, rp.Count)
+		assert.Len(t, rp.Data, 3)
+	})
+
 	t.Run("Test deprovisioned runtimes limit", func(t *testing.T) {
 		//given
 		params := ListParameters{
@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const MaxLabels = 64
+
+// reservedLabelDomains are used by labels set by KEB and Kubernetes, user labels with these prefixes could override them
+var reservedLabelDomains = []string{"kyma-project.io", "kubernetes.io", "k8s.io", "gardener.cloud"}
+
+// ValidateLabels checks that labels follow the Kubernetes label syntax and do not use prefixes reserved for labels set by KEB
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("too many labels: %d, at most %d labels are allowed", len(labels), MaxLabels)
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		for _, msg := range validation.IsQualifiedName(key) {
+			problems = append(problems, fmt.Sprintf("label key %q: %s", key, msg))
+		}
+		if prefix, _, found := strings.Cut(key, "/"); found && isReservedLabelDomain(prefix) {
+			problems = append(problems, fmt.Sprintf("label key %q: the prefix %s is reserved", key, prefix))
+		}
+		for _, msg := range validation.IsValidLabelValue(labels[key]) {
+			problems = append(problems, fmt.Sprintf("label value of %q: %s", key, msg))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid labels: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isReservedLabelDomain(prefix string) bool {
+	for _, domain := range reservedLabelDomains {
+		if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
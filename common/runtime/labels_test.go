@@ -0,0 +1,60 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLabels(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxLabels; i++ {
+		tooMany[fmt.Sprintf("label-%d", i)] = "value"
+	}
+
+	for name, tc := range map[string]struct {
+		labels   map[string]string
+		expected string
+	}{
+		"no labels": {},
+		"valid labels": {
+			labels: map[string]string{"team": "x", "example.com/env": "prod", "empty": ""},
+		},
+		"invalid key": {
+			labels:   map[string]string{"team x": "x"},
+			expected: "label key \"team x\"",
+		},
+		"too long key name": {
+			labels:   map[string]string{strings.Repeat("a", 64): "x"},
+			expected: "must be no more than 63 characters",
+		},
+		"invalid value": {
+			labels:   map[string]string{"team": "-x"},
+			expected: "label value of \"team\"",
+		},
+		"reserved prefix": {
+			labels:   map[string]string{"kyma-project.io/global-account-id": "x"},
+			expected: "the prefix kyma-project.io is reserved",
+		},
+		"reserved subdomain prefix": {
+			labels:   map[string]string{"operator.kyma-project.io/managed-by": "x"},
+			expected: "the prefix operator.kyma-project.io is reserved",
+		},
+		"too many labels": {
+			labels:   tooMany,
+			expected: "too many labels",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateLabels(tc.labels)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expected)
+			}
+		})
+	}
+}
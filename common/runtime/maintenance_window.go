@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// time zones of maintenance windows must be loaded also when the image has no time zone database
+	_ "time/tzdata"
+)
+
+const (
+	MaintenanceWindowMinDuration = 30 * time.Minute
+	MaintenanceWindowMaxDuration = 6 * time.Hour
+
+	maintenanceWindowStartLayout = "15:04"
+)
+
+var weekdays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sunday":    time.Sunday,
+}
+
+// MaintenanceWindowDTO defines a weekly time window in which disruptive changes of the cluster are applied
+type MaintenanceWindowDTO struct {
+	// Weekday is the lowercase English name of the day, for example "saturday"
+	Weekday string `json:"weekday"`
+	// Start is the time of the day in the HH:MM format
+	Start string `json:"start"`
+	// Duration is given in the Go duration format, for example "2h" or "90m"
+	Duration string `json:"duration"`
+	// TimeZone is the IANA time zone name, UTC is used if empty
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+func MaintenanceWindowWeekdays() []string {
+	return []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+}
+
+func (w MaintenanceWindowDTO) Validate() error {
+	if _, found := weekdays[strings.ToLower(w.Weekday)]; !found {
+		return fmt.Errorf("maintenance window weekday %q is invalid, must be one of: %s", w.Weekday, strings.Join(MaintenanceWindowWeekdays(), ", "))
+	}
+	if _, err := time.Parse(maintenanceWindowStartLayout, w.Start); err != nil {
+		return fmt.Errorf("maintenance window start %q is invalid, must be in the HH:MM format", w.Start)
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return fmt.Errorf("maintenance window duration %q is invalid: %w", w.Duration, err)
+	}
+	if duration < MaintenanceWindowMinDuration || duration > MaintenanceWindowMaxDuration {
+		return fmt.Errorf("maintenance window duration %s must be between %s and %s", duration, MaintenanceWindowMinDuration, MaintenanceWindowMaxDuration)
+	}
+	if w.TimeZone == "Local" {
+		return fmt.Errorf("maintenance window time zone %q is invalid, must be an IANA time zone name", w.TimeZone)
+	}
+	if _, err := time.LoadLocation(w.TimeZone); err != nil {
+		return fmt.Errorf("maintenance window time zone %q is invalid: %w", w.TimeZone, err)
+	}
+	return nil
+}
+
+// Next returns the start and the end of the window which is open at the given time or, if none is open, of the next one.
+// The window must be valid.
+func (w MaintenanceWindowDTO) Next(now time.Time) (time.Time, time.Time) {
+	location, _ := time.LoadLocation(w.TimeZone)
+	start, _ := time.Parse(maintenanceWindowStartLayout, w.Start)
+	duration, _ := time.ParseDuration(w.Duration)
+
+	local := now.In(location)
+	daysToWeekday := int(weekdays[strings.ToLower(w.Weekday)] - local.Weekday())
+	// the window of the previous week can still be open
+	begin := time.Date(local.Year(), local.Month(), local.Day()+daysToWeekday-7, start.Hour(), start.Minute(), 0, 0, location)
+	for !begin.Add(duration).After(now) {
+		begin = begin.AddDate(0, 0, 7)
+	}
+	return begin, begin.Add(duration)
+}
+
+// IsOpen returns true if the given time is within the window
+func (w MaintenanceWindowDTO) IsOpen(now time.Time) bool {
+	begin, _ := w.Next(now)
+	return !begin.After(now)
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindowDTO_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		window   MaintenanceWindowDTO
+		expected string
+	}{
+		"valid window in UTC": {
+			window: MaintenanceWindowDTO{Weekday: "saturday", Start: "22:00", Duration: "2h"},
+		},
+		"valid window in a time zone": {
+			window: MaintenanceWindowDTO{Weekday: "Sunday", Start: "03:30", Duration: "90m", TimeZone: "Europe/Berlin"},
+		},
+		"invalid weekday": {
+			window:   MaintenanceWindowDTO{Weekday: "someday", Start: "22:00", Duration: "2h"},
+			expected: "maintenance window weekday \"someday\" is invalid",
+		},
+		"invalid start": {
+			window:   MaintenanceWindowDTO{Weekday: "saturday", Start: "25:00", Duration: "2h"},
+			expected: "maintenance window start \"25:00\" is invalid",
+		},
+		"too short duration": {
+			window:   MaintenanceWindowDTO{Weekday: "saturday", Start: "22:00", Duration: "10m"},
+			expected: "maintenance window duration 10m0s must be between 30m0s and 6h0m0s",
+		},
+		"too long duration": {
+			window:   MaintenanceWindowDTO{Weekday: "saturday", Start: "22:00", Duration: "8h"},
+			expected: "maintenance window duration 8h0m0s must be between 30m0s and 6h0m0s",
+		},
+		"invalid time zone": {
+			window:   MaintenanceWindowDTO{Weekday: "saturday", Start: "22:00", Duration: "2h", TimeZone: "Mars/Olympus"},
+			expected: "maintenance window time zone \"Mars/Olympus\" is invalid",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.window.Validate()
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowDTO_Next(t *testing.T) {
+	window := MaintenanceWindowDTO{Weekday: "saturday", Start: "22:00", Duration: "4h", TimeZone: "Europe/Berlin"}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		now           time.Time
+		expectedBegin time.Time
+	}{
+		"before the window in the same week": {
+			now:           time.Date(2026, 10, 14, 12, 0, 0, 0, berlin),
+			expectedBegin: time.Date(2026, 10, 17, 22, 0, 0, 0, berlin),
+		},
+		"during the window": {
+			now:           time.Date(2026, 10, 17, 23, 0, 0, 0, berlin),
+			expectedBegin: time.Date(2026, 10, 17, 22, 0, 0, 0, berlin),
+		},
+		"during the window on the next day": {
+			now:           time.Date(2026, 10, 18, 1, 0, 0, 0, berlin),
+			expectedBegin: time.Date(2026, 10, 17, 22, 0, 0, 0, berlin),
+		},
+		"after the window": {
+			now:           time.Date(2026, 10, 18, 2, 0, 0, 0, berlin),
+			expectedBegin: time.Date(2026, 10, 24, 22, 0, 0, 0, berlin),
+		},
+		"given in another time zone": {
+			now:           time.Date(2026, 10, 17, 19, 30, 0, 0, time.UTC),
+			expectedBegin: time.Date(2026, 10, 17, 22, 0, 0, 0, berlin),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			begin, end := window.Next(tc.now)
+
+			assert.True(t, tc.expectedBegin.Equal(begin), "expected %s, got %s", tc.expectedBegin, begin)
+			assert.Equal(t, 4*time.Hour, end.Sub(begin))
+			assert.Equal(t, !tc.now.Before(begin), window.IsOpen(tc.now))
+		})
+	}
+}
@@ -35,6 +35,10 @@ const (
 	StateUpdating State = "updating"
 	// StateSuspended means that the trial runtime is suspended (i.e. deprovisioned).
 	StateSuspended State = "suspended"
+	// StateCanceling means that the cancellation of the last provision, deprovision or update operation was requested and is in progress.
+	StateCanceling State = "canceling"
+	// StateCanceled means that the last provision, deprovision or update operation was canceled.
+	StateCanceled State = "canceled"
 	// AllState is a virtual state only used as query parameter in ListParameters to indicate "include all runtimes, which are excluded by default without state filters".
 	AllState State = "all"
 )
@@ -64,6 +68,8 @@ type RuntimeDTO struct {
 	LicenseType                 *string                   `json:"licenseType,omitempty"`
 	CommercialModel             *string                   `json:"commercialModel,omitempty"`
 	Actions                     []Action                  `json:"actions,omitempty"`
+	// ParametersVersion is the version of the parameters snapshot, set only when runtimes are requested at a point in time
+	ParametersVersion int `json:"parametersVersion,omitempty"`
 }
 
 type CloudProvider string
@@ -100,6 +106,9 @@ type ProvisioningParametersDTO struct {
 	ColocateControlPlane      *bool                      `json:"colocateControlPlane,omitempty"`
 	AdditionalWorkerNodePools []AdditionalWorkerNodePool `json:"additionalWorkerNodePools,omitempty"`
 	IngressFiltering          *bool                      `json:"ingressFiltering,omitempty"`
+	MaintenanceWindow         *MaintenanceWindowDTO      `json:"maintenanceWindow,omitempty"`
+	// Labels are key/value metadata of the instance set by the user, they are added to the Runtime, Kyma and GardenerCluster resources
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 const HAAutoscalerMinimumValue = 3
@@ -349,25 +358,53 @@ type BindingDTO struct {
 	ExpiresAt         time.Time `json:"expiresAt"`
 	KubeconfigExists  bool      `json:"kubeconfigExists"`
 	CreatedBy         string    `json:"createdBy"`
+	Role              string    `json:"role,omitempty"`
+	Namespaces        []string  `json:"namespaces,omitempty"`
 }
 
 type ActionType string
 
 const (
-	PlanUpdateActionType         ActionType = "plan_update"
-	SubaccountMovementActionType ActionType = "subaccount_movement"
+	PlanUpdateActionType           ActionType = "plan_update"
+	SubaccountMovementActionType   ActionType = "subaccount_movement"
+	OperationRetryActionType       ActionType = "operation_retry"
+	OperationCancelActionType      ActionType = "operation_cancel"
+	ProvisioningActionType         ActionType = "provisioning"
+	DeprovisioningActionType       ActionType = "deprovisioning"
+	ExpirationActionType           ActionType = "expiration"
+	SuspensionActionType           ActionType = "suspension"
+	UnsuspensionActionType         ActionType = "unsuspension"
+	OIDCUpdateActionType           ActionType = "oidc_update"
+	AdministratorsUpdateActionType ActionType = "administrators_update"
+	BindingCreationActionType      ActionType = "binding_creation"
+	BindingDeletionActionType      ActionType = "binding_deletion"
+	BindingRenewalActionType       ActionType = "binding_renewal"
+	LabelsUpdateActionType         ActionType = "labels_update"
 )
 
 type Action struct {
 	ID         string     `json:"ID,omitempty"`
 	Type       ActionType `json:"type,omitempty"`
 	InstanceID string     `json:"-"`
+	Actor      string     `json:"actor,omitempty"`
 	Message    string     `json:"message,omitempty"`
 	OldValue   string     `json:"oldValue,omitempty"`
 	NewValue   string     `json:"newValue,omitempty"`
 	CreatedAt  time.Time  `json:"createdAt,omitempty"`
 }
 
+// ActionDTO is the action returned by the actions endpoint, together with the instance it changed
+type ActionDTO struct {
+	Action
+	InstanceID string `json:"instanceID"`
+}
+
+type ActionsPage struct {
+	Data       []ActionDTO `json:"data"`
+	Count      int         `json:"count"`
+	TotalCount int         `json:"totalCount"`
+}
+
 type RuntimeStatus struct {
 	CreatedAt        time.Time       `json:"createdAt"`
 	ModifiedAt       time.Time       `json:"modifiedAt"`
@@ -417,6 +454,8 @@ type RuntimesPage struct {
 	Data       []RuntimeDTO `json:"data"`
 	Count      int          `json:"count"`
 	TotalCount int          `json:"totalCount"`
+	// NextCursor points to the last runtime of the page, it is empty if there are no more runtimes
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 const (
@@ -437,6 +476,40 @@ const (
 	BindingsParam        = "bindings"
 	WithBindingsParam    = "with_bindings"
 	ActionsParam         = "actions"
+	ActionTypeParam      = "action_type"
+	ActorParam           = "actor"
+	CreatedAfterParam    = "created_after"
+	CreatedBeforeParam   = "created_before"
+	AtParam              = "at"
+
+	SortParam                      = "sort"
+	UpdatedAfterParam              = "updated_after"
+	UpdatedBeforeParam             = "updated_before"
+	ProviderParam                  = "provider"
+	LicenseTypeParam               = "license_type"
+	CommercialModelParam           = "commercial_model"
+	MachineTypeParam               = "machine_type"
+	KubernetesVersionParam         = "kubernetes_version"
+	AdditionalWorkerNodePoolsParam = "additional_worker_node_pools"
+	ErrorComponentParam            = "error_component"
+	ErrorReasonParam               = "error_reason"
+	LabelSelectorParam             = "labelSelector"
+)
+
+// Fields accepted by the sort parameter in the field:asc or field:desc format
+const (
+	SortByCreatedAt       = "createdAt"
+	SortByUpdatedAt       = "updatedAt"
+	SortByInstanceID      = "instanceID"
+	SortByRuntimeID       = "runtimeID"
+	SortByGlobalAccountID = "globalAccountID"
+	SortBySubAccountID    = "subAccountID"
+	SortByRegion          = "region"
+	SortByPlan            = "plan"
+	SortByProvider        = "provider"
+
+	SortAscending  = "asc"
+	SortDescending = "desc"
 )
 
 type OperationDetail string
@@ -449,6 +522,8 @@ const (
 type ListParameters struct {
 	// Page specifies the offset for the runtime results in the total count of matching runtimes
 	Page int
+	// Cursor specifies the NextCursor of the previous page, the page starts after the last runtime of the previous page and Page is ignored
+	Cursor string
 	// PageSize specifies the count of matching runtimes returned in a response
 	PageSize int
 	// OperationDetail specifies whether the server should respond with all operations, or only the last operation. If not set, the server by default sends all operations
@@ -487,6 +562,31 @@ type ListParameters struct {
 	Events string
 	// Actions specifies whether audit logs should be included in the response for each runtime
 	Actions bool
+	// Providers parameter filters runtimes by specified cloud providers
+	Providers []string
+	// LicenseTypes parameter filters runtimes by specified license types from the ERS context
+	LicenseTypes []string
+	// CommercialModels parameter filters runtimes by specified commercial models from the ERS context
+	CommercialModels []string
+	// MachineTypes parameter filters runtimes by specified machine types of the Kyma worker node pool
+	MachineTypes []string
+	// KubernetesVersions parameter filters runtimes by specified Kubernetes versions
+	KubernetesVersions []string
+	// AdditionalWorkerNodePools parameter filters runtimes with (true) or without (false) additional worker node pools
+	AdditionalWorkerNodePools *bool
+	// ErrorComponents parameter filters runtimes by specified components of the last error
+	ErrorComponents []string
+	// ErrorReasons parameter filters runtimes by specified reasons of the last error
+	ErrorReasons []string
+	// CreatedAfter and CreatedBefore parameters filter runtimes by the creation time
+	CreatedAfter, CreatedBefore time.Time
+	// UpdatedAfter and UpdatedBefore parameters filter runtimes by the last update time
+	UpdatedAfter, UpdatedBefore time.Time
+	// LabelSelector parameter filters runtimes by instance labels with the Kubernetes label selector syntax, for example, team=x,env!=prod
+	LabelSelector string
+	// Sort specifies the order of runtimes in the field:asc or field:desc format, see SortBy constants for fields.
+	// Runtimes sorted by fields are paginated only by page numbers.
+	Sort []string
 }
 
 func (rt RuntimeDTO) LastOperation() Operation {
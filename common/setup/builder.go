@@ -96,8 +96,10 @@ func (b *AppBuilder) WithBrokerClient() {
 
 func (b *AppBuilder) WithStorage() {
 	// Init Storage
-	cipher := storage.NewEncrypter(b.cfg.Database.SecretKey)
-	var err error
+	cipher, err := storage.NewEncrypterFromConfig(b.cfg.Database)
+	if err != nil {
+		FatalOnError(err)
+	}
 	b.db, b.conn, err = storage.NewFromConfig(b.cfg.Database, events.Config{}, cipher)
 	if err != nil {
 		FatalOnError(err)
@@ -0,0 +1,60 @@
+package webhooks
+
+import "time"
+
+const (
+	// SignatureHeader contains the hex encoded HMAC-SHA256 of "<timestamp>.<body>" computed with the subscription secret
+	SignatureHeader = "X-Keb-Signature"
+	// TimestampHeader contains the Unix time of sending the notification, receivers should reject old notifications
+	TimestampHeader  = "X-Keb-Timestamp"
+	EventIDHeader    = "X-Keb-Event-Id"
+	EventTypeHeader  = "X-Keb-Event-Type"
+	DeliveryIDHeader = "X-Keb-Delivery-Id"
+
+	SignaturePrefix = "sha256="
+)
+
+// SubscriptionRequest is the body of the KEB POST /webhooks/subscriptions request, exactly one of the accounts must be set
+type SubscriptionRequest struct {
+	GlobalAccountID string `json:"globalAccountId,omitempty"`
+	SubAccountID    string `json:"subAccountId,omitempty"`
+	URL             string `json:"url"`
+	// Events lists subscribed event types, all events are sent if empty
+	Events []string `json:"events,omitempty"`
+	// Secret used to sign notifications, generated by KEB if empty
+	Secret string `json:"secret,omitempty"`
+}
+
+type SubscriptionDTO struct {
+	ID              string    `json:"id"`
+	GlobalAccountID string    `json:"globalAccountId,omitempty"`
+	SubAccountID    string    `json:"subAccountId,omitempty"`
+	URL             string    `json:"url"`
+	Events          []string  `json:"events,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	// Secret is returned only in the response to the subscription creation
+	Secret string `json:"secret,omitempty"`
+}
+
+type SubscriptionsPage struct {
+	Data       []SubscriptionDTO `json:"data"`
+	Count      int               `json:"count"`
+	TotalCount int               `json:"totalCount"`
+}
+
+type DeliveryDTO struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"eventId"`
+	EventType string    `json:"eventType"`
+	State     string    `json:"state"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type DeliveriesPage struct {
+	Data       []DeliveryDTO `json:"data"`
+	Count      int           `json:"count"`
+	TotalCount int           `json:"totalCount"`
+}
@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Sign returns the value of the signature header for the notification body sent at the given Unix time
+func Sign(secret string, timestamp int64, body []byte) string {
+	return SignaturePrefix + hex.EncodeToString(mac(secret, timestamp, body))
+}
+
+// Verify checks the signature header of the received notification
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(signature, SignaturePrefix))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(decoded, mac(secret, timestamp, body))
+}
+
+func mac(secret string, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(h, "%d.", timestamp)
+	_, _ = h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,23 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	// given
+	body := []byte(`{"id":1}`)
+
+	// when
+	signature := Sign("secret", 1760698800, body)
+
+	// then
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.True(t, Verify("secret", 1760698800, body, signature))
+	assert.False(t, Verify("other-secret", 1760698800, body, signature))
+	assert.False(t, Verify("secret", 1760698801, body, signature))
+	assert.False(t, Verify("secret", 1760698800, []byte(`{"id":2}`), signature))
+	assert.False(t, Verify("secret", 1760698800, body, "sha256=not-hex"))
+}
@@ -32,6 +32,7 @@ require (
 	github.com/vrischmann/envconfig v1.4.1
 	golang.org/x/exp v0.0.0-20251023183803-a4bb9ffd2546
 	golang.org/x/oauth2 v0.32.0
+	golang.org/x/sync v0.17.0
 	golang.org/x/time v0.14.0
 	gopkg.in/yaml.v2 v2.4.0
 	gopkg.in/yaml.v3 v3.0.1
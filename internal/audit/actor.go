@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+
+	"github.com/pivotal-cf/brokerapi/v12/middlewares"
+)
+
+const UnknownActor = "unknown"
+
+// ActorFromContext returns who requested the change: the caller authenticated by KEB or the user from the OSB API
+// originating identity header
+func ActorFromContext(ctx context.Context) string {
+	if caller, found := middleware.CallerFromContext(ctx); found && caller.Name() != "" {
+		return caller.Name()
+	}
+	if identity, ok := ctx.Value(middlewares.OriginatingIdentityKey).(string); ok {
+		if actor := originatingIdentityActor(identity); actor != "" {
+			return actor
+		}
+	}
+	return UnknownActor
+}
+
+// ActorFromRequest returns the caller of the admin endpoint authenticated by KEB, the request headers are not trusted
+func ActorFromRequest(req *http.Request) string {
+	if caller, found := middleware.CallerFromContext(req.Context()); found && caller.Name() != "" {
+		return caller.Name()
+	}
+	return UnknownActor
+}
+
+// originatingIdentityActor decodes the value of the X-Broker-API-Originating-Identity header, which contains
+// the platform name and the base64 encoded JSON with the user properties
+func originatingIdentityActor(identity string) string {
+	_, encoded, found := strings.Cut(identity, " ")
+	if !found {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(encoded)
+		if err != nil {
+			return ""
+		}
+	}
+	var properties map[string]interface{}
+	if err := json.Unmarshal(decoded, &properties); err != nil {
+		return ""
+	}
+	for _, key := range []string{"email", "user_name", "username", "user_id"} {
+		if value, ok := properties[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
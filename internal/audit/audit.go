@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are parts of JSON keys whose values are never stored in actions
+var sensitiveKeys = []string{"secret", "password", "token", "kubeconfig", "credentials", "privatekey"}
+
+// Record stores the action, the change is already done, so the failure is only logged
+func Record(actions storage.Actions, action runtime.Action, log *slog.Logger) {
+	if err := actions.InsertAction(action); err != nil {
+		log.Error(fmt.Sprintf("while inserting action %q with message %s for instance ID %s: %v", action.Type, action.Message, action.InstanceID, err))
+	}
+}
+
+// Redact returns the value as JSON with values of sensitive keys replaced, strings are returned unchanged
+func Redact(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return string(data)
+	}
+	redactedData, err := json.Marshal(redact(generic))
+	if err != nil {
+		return ""
+	}
+	return string(redactedData)
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redact(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitive(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
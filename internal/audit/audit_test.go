@@ -0,0 +1,100 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+
+	"github.com/pivotal-cf/brokerapi/v12/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActorFromContext(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ctx      context.Context
+		expected string
+	}{
+		"authenticated caller": {
+			ctx:      middleware.AddCallerToContext(context.Background(), middleware.Caller{Subject: "subject-1", Email: "admin@example.com"}),
+			expected: "admin@example.com",
+		},
+		"originating identity": {
+			ctx:      context.WithValue(context.Background(), middlewares.OriginatingIdentityKey, "cloudfoundry "+base64.StdEncoding.EncodeToString([]byte(`{"user_id":"user-1","email":"user@example.com"}`))),
+			expected: "user@example.com",
+		},
+		"originating identity without user": {
+			ctx:      context.WithValue(context.Background(), middlewares.OriginatingIdentityKey, "cloudfoundry "+base64.StdEncoding.EncodeToString([]byte(`{}`))),
+			expected: audit.UnknownActor,
+		},
+		"user agent of a job": {
+			ctx:      context.WithValue(context.Background(), "User-Agent", "accountcleanup-job"),
+			expected: audit.UnknownActor,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, audit.ActorFromContext(tc.ctx))
+		})
+	}
+}
+
+func TestActorFromRequest(t *testing.T) {
+	t.Run("should take the authenticated caller", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/expire/service_instance/instance-1", nil)
+		req = req.WithContext(middleware.AddCallerToContext(req.Context(), middleware.Caller{Subject: "caller-id", Email: "admin@example.com"}))
+
+		assert.Equal(t, "admin@example.com", audit.ActorFromRequest(req))
+	})
+
+	t.Run("should not trust the token payload header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/expire/service_instance/instance-1", nil)
+		req.Header.Set("X-Jwt-Payload", base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-id","email":"admin@example.com"}`)))
+
+		assert.Equal(t, audit.UnknownActor, audit.ActorFromRequest(req))
+	})
+
+	t.Run("should not trust the user agent", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/expire/service_instance/instance-1", nil)
+		req.Header.Set("User-Agent", "expirator-job")
+
+		assert.Equal(t, audit.UnknownActor, audit.ActorFromRequest(req))
+	})
+}
+
+func TestRedact(t *testing.T) {
+	type oidc struct {
+		ClientID     string            `json:"clientID"`
+		ClientSecret string            `json:"client_secret"`
+		Nested       map[string]string `json:"nested"`
+	}
+
+	for name, tc := range map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"string": {
+			value:    "azure",
+			expected: "azure",
+		},
+		"struct with secrets": {
+			value:    oidc{ClientID: "client-1", ClientSecret: "s3cr3t", Nested: map[string]string{"apiToken": "t0ken", "name": "n"}},
+			expected: `{"clientID":"client-1","client_secret":"[REDACTED]","nested":{"apiToken":"[REDACTED]","name":"n"}}`,
+		},
+		"list": {
+			value:    []map[string]string{{"kubeconfig": "apiVersion: v1"}, {"user": "admin"}},
+			expected: `[{"kubeconfig":"[REDACTED]"},{"user":"admin"}]`,
+		},
+		"nil": {
+			value:    nil,
+			expected: "null",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, audit.Redact(tc.value))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// Handler exposes the actions recorded for all instances
+type Handler struct {
+	actions        storage.Actions
+	defaultMaxPage int
+	log            *slog.Logger
+}
+
+func NewHandler(actions storage.Actions, defaultMaxPage int, log *slog.Logger) *Handler {
+	return &Handler{
+		actions:        actions,
+		defaultMaxPage: defaultMaxPage,
+		log:            log.With("service", "ActionsEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /actions", h.listActions)
+}
+
+func (h *Handler) listActions(w http.ResponseWriter, req *http.Request) {
+	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(req, h.defaultMaxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+	query := req.URL.Query()
+	filter := dbmodel.ActionFilter{
+		Page:        page,
+		PageSize:    pageSize,
+		InstanceIDs: query[pkg.InstanceIDParam],
+		Types:       query[pkg.ActionTypeParam],
+		Actors:      query[pkg.ActorParam],
+	}
+	if filter.CreatedAfter, err = timeParam(req, pkg.CreatedAfterParam); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.CreatedBefore, err = timeParam(req, pkg.CreatedBeforeParam); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	actions, totalCount, err := h.actions.ListActions(filter)
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to list actions: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching actions: %w", err))
+		return
+	}
+
+	result := pkg.ActionsPage{
+		Data:       make([]pkg.ActionDTO, 0, len(actions)),
+		Count:      len(actions),
+		TotalCount: totalCount,
+	}
+	for _, action := range actions {
+		result.Data = append(result.Data, pkg.ActionDTO{Action: action, InstanceID: action.InstanceID})
+	}
+	httputil.WriteResponse(w, http.StatusOK, result)
+}
+
+func timeParam(req *http.Request, name string) (time.Time, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a time in the RFC 3339 format: %w", name, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,85 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, db.Actions().InsertAction(pkg.Action{Type: pkg.PlanUpdateActionType, InstanceID: "i-1", Actor: "admin@example.com", CreatedAt: now.Add(-2 * time.Hour)}))
+	require.NoError(t, db.Actions().InsertAction(pkg.Action{Type: pkg.ExpirationActionType, InstanceID: "i-1", Actor: "expirator-job", CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, db.Actions().InsertAction(pkg.Action{Type: pkg.BindingCreationActionType, InstanceID: "i-2", Actor: "user@example.com", CreatedAt: now}))
+	router := httputil.NewRouter()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	audit.NewHandler(db.Actions(), 100, logger).AttachRoutes(router)
+
+	t.Run("should list actions of all instances", func(t *testing.T) {
+		// when
+		resp := call(router, "/actions")
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page pkg.ActionsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.Equal(t, 3, page.TotalCount)
+		require.Len(t, page.Data, 3)
+		assert.Equal(t, "i-2", page.Data[0].InstanceID)
+		assert.Equal(t, pkg.BindingCreationActionType, page.Data[0].Type)
+	})
+
+	t.Run("should filter and paginate actions", func(t *testing.T) {
+		// when
+		resp := call(router, "/actions?instance_id=i-1&action_type=plan_update&action_type=expiration&page=2&page_size=1")
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page pkg.ActionsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.Equal(t, 2, page.TotalCount)
+		assert.Equal(t, 1, page.Count)
+		assert.Equal(t, pkg.PlanUpdateActionType, page.Data[0].Type)
+		assert.Equal(t, "admin@example.com", page.Data[0].Actor)
+	})
+
+	t.Run("should filter actions by actor and creation time", func(t *testing.T) {
+		// when
+		resp := call(router, "/actions?actor=expirator-job&actor=admin@example.com&created_after="+now.Add(-90*time.Minute).Format(time.RFC3339))
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page pkg.ActionsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Equal(t, 1, page.TotalCount)
+		assert.Equal(t, pkg.ExpirationActionType, page.Data[0].Type)
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, call(router, "/actions?created_before=yesterday").Code)
+		assert.Equal(t, http.StatusBadRequest, call(router, "/actions?page=0").Code)
+	})
+}
+
+func call(router *httputil.Router, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	broker "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
@@ -26,13 +29,20 @@ const (
 )
 
 type BindingConfig struct {
-	Enabled              bool          `envconfig:"default=false"`
-	BindablePlans        EnablePlans   `envconfig:"default=aws"`
-	ExpirationSeconds    int           `envconfig:"default=600"`
-	MaxExpirationSeconds int           `envconfig:"default=7200"`
-	MinExpirationSeconds int           `envconfig:"default=600"`
-	MaxBindingsCount     int           `envconfig:"default=10"`
-	CreateBindingTimeout time.Duration `envconfig:"default=15s"`
+	Enabled              bool                 `envconfig:"default=false"`
+	BindablePlans        EnablePlans          `envconfig:"default=aws"`
+	ExpirationSeconds    int                  `envconfig:"default=600"`
+	MaxExpirationSeconds int                  `envconfig:"default=7200"`
+	MinExpirationSeconds int                  `envconfig:"default=600"`
+	MaxBindingsCount     int                  `envconfig:"default=10"`
+	CreateBindingTimeout time.Duration        `envconfig:"default=15s"`
+	DefaultRole          string               `envconfig:"default=admin"`
+	Roles                broker.RoleTemplates `envconfig:"-"`
+	// AsyncEnabled allows creating bindings asynchronously if the platform accepts incomplete bindings
+	AsyncEnabled       bool          `envconfig:"default=false"`
+	AsyncTimeout       time.Duration `envconfig:"default=5m"`
+	AsyncRetryInterval time.Duration `envconfig:"default=10s"`
+	WorkersAmount      int           `envconfig:"default=5"`
 }
 
 type BindEndpoint struct {
@@ -40,6 +50,9 @@ type BindEndpoint struct {
 	instancesStorage  storage.Instances
 	bindingsStorage   storage.Bindings
 	operationsStorage storage.Operations
+	outbox            storage.Outbox
+	actions           storage.Actions
+	queue             Queue
 
 	serviceAccountBindingManager broker.BindingsManager
 	publisher                    event.Publisher
@@ -62,7 +75,9 @@ func (b *BindingContext) CreatedBy() string {
 }
 
 type BindingParams struct {
-	ExpirationSeconds int `json:"expiration_seconds,omit"`
+	ExpirationSeconds int      `json:"expiration_seconds,omit"`
+	Role              string   `json:"role,omitempty"`
+	Namespaces        []string `json:"namespaces,omitempty"`
 }
 
 type Credentials struct {
@@ -70,14 +85,23 @@ type Credentials struct {
 }
 
 func NewBind(cfg BindingConfig, db storage.BrokerStorage, log *slog.Logger, clientProvider broker.ClientProvider, kubeconfigProvider broker.KubeconfigProvider,
-	publisher event.Publisher) *BindEndpoint {
+	publisher event.Publisher, queue Queue) *BindEndpoint {
+	if cfg.Roles == nil {
+		cfg.Roles = broker.DefaultRoleTemplates()
+	}
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = broker.AdminRole
+	}
 	return &BindEndpoint{config: cfg,
 		instancesStorage:             db.Instances(),
 		bindingsStorage:              db.Bindings(),
 		publisher:                    publisher,
 		operationsStorage:            db.Operations(),
+		outbox:                       db.Outbox(),
+		actions:                      db.Actions(),
+		queue:                        queue,
 		log:                          log.With("service", "BindEndpoint"),
-		serviceAccountBindingManager: broker.NewServiceAccountBindingsManager(clientProvider, kubeconfigProvider),
+		serviceAccountBindingManager: broker.NewServiceAccountBindingsManager(clientProvider, kubeconfigProvider, cfg.Roles),
 	}
 }
 
@@ -112,7 +136,8 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 		return domain.Binding{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to get instance %s", instanceID), http.StatusInternalServerError, fmt.Sprintf("failed to get instance %s", instanceID))
 	}
 
-	if !b.IsPlanBindable(instance.ServicePlanName) {
+	// plans are bindable by the configuration or by the plan traits, the same as in the catalog
+	if !b.IsPlanBindable(instance.ServicePlanName) && !IsBindablePlan(instance.ServicePlanID) {
 		return domain.Binding{}, apiresponses.NewFailureResponseBuilder(
 			errors.New("binding is not supported"), http.StatusUnprocessableEntity, "binding is not supported",
 		).WithErrorKey("BindingNotSupported").Build()
@@ -151,6 +176,15 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 		expirationSeconds = parameters.ExpirationSeconds
 	}
 
+	role := b.config.DefaultRole
+	if parameters.Role != "" {
+		role = parameters.Role
+	}
+	if err := b.config.Roles.Validate(role, parameters.Namespaces); err != nil {
+		message := err.Error()
+		return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusBadRequest, message)
+	}
+
 	lastOperation, err := b.operationsStorage.GetLastOperation(instance.InstanceID)
 	if err != nil {
 		return domain.Binding{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to get last operation for instance %s", instanceID), http.StatusInternalServerError, fmt.Sprintf("failed to get last operation for instance %s", instanceID))
@@ -171,12 +205,25 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 		return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusInternalServerError, message)
 	}
 	if bindingFromDB != nil {
-		if bindingFromDB.ExpirationSeconds != int64(expirationSeconds) {
+		if bindingFromDB.ExpirationSeconds != int64(expirationSeconds) || bindingFromDB.Role != role || !slices.Equal(bindingFromDB.Namespaces, parameters.Namespaces) {
 			message := fmt.Sprintf("binding already exists but with different parameters")
 			return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusConflict, message)
 		}
-		if bindingFromDB.ExpiresAt.After(time.Now()) {
+		switch {
+		case bindingFromDB.State == domain.Failed:
+			// resources of failed bindings are removed, so the binding is created again
+			if err := b.bindingsStorage.Delete(instanceID, bindingID); err != nil {
+				message := fmt.Sprintf("failed to delete failed Kyma binding from storage: %s", err)
+				return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusInternalServerError, message)
+			}
+		case bindingFromDB.Stage == BindingStageRemoval:
+			message := fmt.Sprintf("binding removal in progress")
+			return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusUnprocessableEntity, message)
+		case bindingFromDB.ExpiresAt.After(time.Now()):
 			if len(bindingFromDB.Kubeconfig) == 0 {
+				if asyncAllowed && bindingFromDB.State == domain.InProgress {
+					return domain.Binding{IsAsync: true, OperationData: bindingOperationData}, nil
+				}
 				message := fmt.Sprintf("binding creation already in progress")
 				return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusUnprocessableEntity, message)
 			}
@@ -233,6 +280,9 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 		ExpirationSeconds: int64(expirationSeconds),
 		ExpiresAt:         time.Now().Add(time.Duration(expirationSeconds) * time.Second),
 		CreatedBy:         bindingContext.CreatedBy(),
+		Role:              role,
+		Namespaces:        parameters.Namespaces,
+		State:             domain.InProgress,
 	}
 
 	err = b.bindingsStorage.Insert(binding)
@@ -245,17 +295,27 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 		return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusInternalServerError, message)
 	}
 
+	if asyncAllowed && b.config.AsyncEnabled {
+		b.queue.Add(BindingQueueKey(instanceID, bindingID))
+		b.log.Info(fmt.Sprintf("Binding %s for instance %s added to the binding queue", bindingID, instanceID))
+		return domain.Binding{IsAsync: true, OperationData: bindingOperationData}, nil
+	}
+
 	// create kubeconfig for the instance
 	var expiresAt time.Time
-	kubeconfig, expiresAt, err = b.serviceAccountBindingManager.Create(ctx, instance, bindingID, expirationSeconds)
+	kubeconfig, expiresAt, err = b.serviceAccountBindingManager.Create(ctx, instance, binding)
 	if err != nil {
 		message := fmt.Sprintf("failed to create a Kyma binding using service account's kubeconfig: %s", err)
 		b.log.Error(fmt.Sprintf("for instance %s %s", instanceID, message))
+		b.cleanUpFailedBinding(instance, binding, message)
 		return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusBadRequest, message)
 	}
 
 	binding.ExpiresAt = expiresAt
 	binding.Kubeconfig = kubeconfig
+	binding.State = domain.Succeeded
+	binding.Stage = BindingStageKubeconfig
+	binding.Description = "binding created"
 
 	err = b.bindingsStorage.Update(binding)
 	if err != nil {
@@ -263,7 +323,11 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 		return domain.Binding{}, apiresponses.NewFailureResponse(errors.New(message), http.StatusInternalServerError, message)
 	}
 	b.log.Info(fmt.Sprintf("Successfully created binding %s for instance %s", bindingID, instanceID))
-	b.publisher.Publish(context.Background(), BindingCreated{PlanID: instance.ServicePlanID})
+	actor := binding.CreatedBy
+	if actor == "" {
+		actor = audit.ActorFromContext(ctx)
+	}
+	bindingCreated(b.publisher, b.outbox, b.actions, *instance, *binding, actor, b.log)
 
 	return domain.Binding{
 		IsAsync: false,
@@ -276,6 +340,20 @@ func (b *BindEndpoint) bind(ctx context.Context, instanceID, bindingID string, d
 	}, nil
 }
 
+// cleanUpFailedBinding removes runtime resources created before the failure, the request context could be already canceled
+func (b *BindEndpoint) cleanUpFailedBinding(instance *internal.Instance, binding *internal.Binding, description string) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.CreateBindingTimeout)
+	defer cancel()
+	if err := b.serviceAccountBindingManager.Delete(ctx, instance, binding); err != nil {
+		b.log.Error(fmt.Sprintf("unable to remove resources of the failed binding %s: %s", binding.ID, err))
+	}
+	binding.State = domain.Failed
+	binding.Description = description
+	if err := b.bindingsStorage.Update(binding); err != nil {
+		b.log.Error(fmt.Sprintf("unable to store the failed binding %s: %s", binding.ID, err))
+	}
+}
+
 func (b *BindEndpoint) IsPlanBindable(planName string) bool {
 	planNameLowerCase := strings.ToLower(planName)
 	for _, p := range b.config.BindablePlans {
@@ -299,3 +377,25 @@ type UnbindRequestProcessed struct {
 type BindingCreated struct {
 	PlanID string
 }
+
+func bindingCreated(publisher event.Publisher, outbox storage.Outbox, actions storage.Actions, instance internal.Instance, binding internal.Binding, actor string, log *slog.Logger) {
+	publisher.Publish(context.Background(), BindingCreated{PlanID: instance.ServicePlanID})
+	storeBindingEvent(outbox, internal.BindingCreatedOutboxEvent, instance, binding.ID, log)
+	audit.Record(actions, pkg.Action{
+		Type:       pkg.BindingCreationActionType,
+		InstanceID: instance.InstanceID,
+		Actor:      actor,
+		Message:    fmt.Sprintf("Binding %s with role %s created, expires at %s.", binding.ID, binding.Role, binding.ExpiresAt.Format(expiresAtLayout)),
+	}, log)
+}
+
+// storeBindingEvent notifies webhook subscribers about the binding change, the change is already done, so the failure is only logged
+func storeBindingEvent(outbox storage.Outbox, eventType internal.OutboxEventType, instance internal.Instance, bindingID string, log *slog.Logger) {
+	event, err := internal.NewInstanceOutboxEvent(eventType, instance, "", bindingID)
+	if err == nil {
+		err = outbox.Insert(event)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to store %s event for binding %s: %s", eventType, bindingID, err))
+	}
+}
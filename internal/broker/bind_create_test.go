@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	brokerBindings "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -19,9 +20,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
@@ -93,7 +98,7 @@ func TestCreateBindingEndpoint_dbInsertionInCaseOfError(t *testing.T) {
 	publisher := event.NewPubSub(log)
 
 	//// api handler
-	bindEndpoint := NewBind(*bindingCfg, db, fixLogger(), &dummyProvider{}, &dummyProvider{}, publisher)
+	bindEndpoint := NewBind(*bindingCfg, db, fixLogger(), &dummyProvider{}, &dummyProvider{}, publisher, nil)
 
 	// test relies on checking if got nil on kubeconfig dummyProvider but the instance got inserted either way
 	t.Run("should INSERT binding despite error on k8s api call", func(t *testing.T) {
@@ -120,6 +125,56 @@ func TestCreateBindingEndpoint_dbInsertionInCaseOfError(t *testing.T) {
 	})
 }
 
+func TestCreateBindingForPlanBindableByTraits(t *testing.T) {
+	// given
+	restorePlans(t)
+	db := storage.NewMemoryStorage()
+	err := db.Instances().Insert(fixture.FixInstance(instanceID1))
+	require.NoError(t, err)
+	err = db.Operations().InsertOperation(fixture.FixOperation("operation-id", instanceID1, "provision"))
+	require.NoError(t, err)
+
+	bindingCfg := BindingConfig{
+		Enabled:              true,
+		BindablePlans:        EnablePlans{},
+		MaxBindingsCount:     maxBindingsCount,
+		MinExpirationSeconds: minExpirationSeconds,
+	}
+	bindEndpoint := NewBind(bindingCfg, db, fixLogger(), &dummyProvider{}, &dummyProvider{}, event.NewPubSub(fixLogger()), nil)
+	bind := func(bindingID string) error {
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, bindingID, domain.BindDetails{
+			ServiceID: "123",
+			PlanID:    fixture.PlanId,
+		}, false)
+		return err
+	}
+
+	t.Run("should reject binding of a plan which is not bindable", func(t *testing.T) {
+		// when
+		err := bind("binding-id-1")
+
+		// then
+		require.Error(t, err)
+		apiErr, ok := err.(*apiresponses.FailureResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, apiErr.ValidatedStatusCode(nil))
+		assert.EqualError(t, err, "binding is not supported")
+	})
+
+	t.Run("should accept binding of a plan bindable by the plan traits", func(t *testing.T) {
+		// given
+		require.NoError(t, RegisterPlans([]configuration.PlanDefinition{{Name: fixture.PlanName, Bindable: ptr.Bool(true)}}))
+
+		// when
+		_ = bind("binding-id-2")
+
+		// then
+		binding, err := db.Bindings().Get(instanceID1, "binding-id-2")
+		require.NoError(t, err)
+		assert.Equal(t, instanceID1, binding.InstanceID)
+	})
+}
+
 func TestCreateBindingExceedsAllowedNumberOfNonExpiredBindings(t *testing.T) {
 	// given
 	cfg := fixBindingConfig()
@@ -343,7 +398,7 @@ func TestCreateSecondBindingWithTheSameIdButDifferentParams(t *testing.T) {
 
 	publisher := event.NewPubSub(log)
 
-	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher)
+	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher, nil)
 	params := BindingParams{
 		ExpirationSeconds: 601,
 	}
@@ -394,7 +449,7 @@ func TestCreateSecondBindingWithTheSameIdAndParams(t *testing.T) {
 
 	publisher := event.NewPubSub(log)
 
-	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher)
+	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher, nil)
 	params := BindingParams{
 		ExpirationSeconds: 600,
 	}
@@ -446,7 +501,7 @@ func TestCreateSecondBindingWithTheSameIdAndParamsForExpired(t *testing.T) {
 	// event publisher
 	publisher := event.NewPubSub(log)
 
-	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher)
+	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher, nil)
 	params := BindingParams{
 		ExpirationSeconds: 600,
 	}
@@ -500,7 +555,7 @@ func TestCreateSecondBindingWithTheSameIdAndParamsForBindingInProgress(t *testin
 	// event publisher
 	publisher := event.NewPubSub(log)
 
-	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher)
+	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher, nil)
 	params := BindingParams{
 		ExpirationSeconds: 600,
 	}
@@ -551,7 +606,7 @@ func TestCreateSecondBindingWithTheSameIdAndParamsNotExplicitlyDefined(t *testin
 
 	publisher := event.NewPubSub(log)
 
-	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher)
+	svc := NewBind(*bindingCfg, brokerStorage, fixLogger(), nil, nil, publisher, nil)
 
 	// when
 	resp, err := svc.Bind(context.Background(), instanceID, bindingID, domain.BindDetails{}, false)
@@ -577,5 +632,135 @@ func prepareBindingEndpoint(t *testing.T, cfg BindingConfig) (*BindEndpoint, sto
 	err = db.Operations().InsertOperation(operation)
 	require.NoError(t, err)
 
-	return NewBind(cfg, db, log, k8sClientProvider, k8sClientProvider, event.NewPubSub(log)), db
+	return NewBind(cfg, db, log, k8sClientProvider, k8sClientProvider, event.NewPubSub(log), nil), db
+}
+
+func TestCreateBindingWithRole(t *testing.T) {
+	// given
+	sch := internal.NewSchemeForTests(t)
+	k8sClientProvider := kubeconfig.NewFakeK8sClientProvider(fake.NewClientBuilder().WithScheme(sch).Build())
+	clientset, err := k8sClientProvider.K8sClientSetForRuntimeID("")
+	require.NoError(t, err)
+	db := storage.NewMemoryStorage()
+	err = db.Instances().Insert(fixture.FixInstance(instanceID1))
+	require.NoError(t, err)
+	err = db.Operations().InsertOperation(fixture.FixOperation("operation-id", instanceID1, "provision"))
+	require.NoError(t, err)
+	bindEndpoint := NewBind(fixBindingConfig(), db, fixLogger(), k8sClientProvider, k8sClientProvider, event.NewPubSub(fixLogger()), nil)
+	unbindEndpoint := NewUnbind(fixLogger(), db, brokerBindings.NewServiceAccountBindingsManager(k8sClientProvider, k8sClientProvider, brokerBindings.DefaultRoleTemplates()), event.NewPubSub(fixLogger()))
+
+	t.Run("should create binding with the default role", func(t *testing.T) {
+		// when
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-admin", domain.BindDetails{
+			ServiceID: "123",
+			PlanID:    fixture.PlanId,
+		}, false)
+
+		// then
+		require.NoError(t, err)
+		binding, err := db.Bindings().Get(instanceID1, "binding-admin")
+		require.NoError(t, err)
+		assert.Equal(t, "admin", binding.Role)
+		clusterRole, err := clientset.RbacV1().ClusterRoles().Get(context.Background(), "kyma-binding-binding-admin", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"*"}, clusterRole.Rules[0].Verbs)
+		_, err = clientset.RbacV1().ClusterRoleBindings().Get(context.Background(), "kyma-binding-binding-admin", metav1.GetOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("should bind the view cluster role", func(t *testing.T) {
+		// when
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-view", domain.BindDetails{
+			ServiceID:     "123",
+			PlanID:        fixture.PlanId,
+			RawParameters: json.RawMessage(`{"role": "view"}`),
+		}, false)
+
+		// then
+		require.NoError(t, err)
+		crb, err := clientset.RbacV1().ClusterRoleBindings().Get(context.Background(), "kyma-binding-binding-view", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "view", crb.RoleRef.Name)
+		_, err = clientset.RbacV1().ClusterRoles().Get(context.Background(), "kyma-binding-binding-view", metav1.GetOptions{})
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+
+	t.Run("should create role bindings in requested namespaces and remove them on unbind", func(t *testing.T) {
+		// when
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-ci", domain.BindDetails{
+			ServiceID:     "123",
+			PlanID:        fixture.PlanId,
+			RawParameters: json.RawMessage(`{"role": "namespace-admin", "namespaces": ["ci", "staging"]}`),
+		}, false)
+
+		// then
+		require.NoError(t, err)
+		binding, err := db.Bindings().Get(instanceID1, "binding-ci")
+		require.NoError(t, err)
+		assert.Equal(t, "namespace-admin", binding.Role)
+		assert.Equal(t, []string{"ci", "staging"}, binding.Namespaces)
+		for _, namespace := range []string{"ci", "staging"} {
+			rb, err := clientset.RbacV1().RoleBindings(namespace).Get(context.Background(), "kyma-binding-binding-ci", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "admin", rb.RoleRef.Name)
+			assert.Equal(t, "kyma-binding-binding-ci", rb.Subjects[0].Name)
+		}
+		_, err = clientset.RbacV1().ClusterRoleBindings().Get(context.Background(), "kyma-binding-binding-ci", metav1.GetOptions{})
+		assert.True(t, k8serrors.IsNotFound(err))
+
+		// when
+		_, err = unbindEndpoint.Unbind(context.Background(), instanceID1, "binding-ci", domain.UnbindDetails{}, false)
+
+		// then
+		require.NoError(t, err)
+		for _, namespace := range []string{"ci", "staging"} {
+			_, err = clientset.RbacV1().RoleBindings(namespace).Get(context.Background(), "kyma-binding-binding-ci", metav1.GetOptions{})
+			assert.True(t, k8serrors.IsNotFound(err))
+		}
+	})
+
+	t.Run("should report a conflict if namespaces are different", func(t *testing.T) {
+		// given
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-dev", domain.BindDetails{
+			ServiceID:     "123",
+			PlanID:        fixture.PlanId,
+			RawParameters: json.RawMessage(`{"role": "namespace-admin", "namespaces": ["dev"]}`),
+		}, false)
+		require.NoError(t, err)
+
+		// when
+		_, err = bindEndpoint.Bind(context.Background(), instanceID1, "binding-dev", domain.BindDetails{
+			ServiceID:     "123",
+			PlanID:        fixture.PlanId,
+			RawParameters: json.RawMessage(`{"role": "namespace-admin", "namespaces": ["dev", "ci"]}`),
+		}, false)
+
+		// then
+		require.Error(t, err)
+		apierr := err.(*apiresponses.FailureResponse)
+		assert.Equal(t, http.StatusConflict, apierr.ValidatedStatusCode(nil))
+	})
+
+	for tn, params := range map[string]string{
+		"unknown role":                       `{"role": "owner"}`,
+		"namespaced role without namespaces": `{"role": "namespace-admin"}`,
+		"namespaces for cluster-wide role":   `{"role": "view", "namespaces": ["ci"]}`,
+		"invalid namespace":                  `{"role": "namespace-admin", "namespaces": ["Not_Valid"]}`,
+	} {
+		t.Run("should reject "+tn, func(t *testing.T) {
+			// when
+			_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-invalid", domain.BindDetails{
+				ServiceID:     "123",
+				PlanID:        fixture.PlanId,
+				RawParameters: json.RawMessage(params),
+			}, false)
+
+			// then
+			require.Error(t, err)
+			apierr := err.(*apiresponses.FailureResponse)
+			assert.Equal(t, http.StatusBadRequest, apierr.ValidatedStatusCode(nil))
+			_, err = db.Bindings().Get(instanceID1, "binding-invalid")
+			assert.True(t, dberr.IsNotFound(err))
+		})
+	}
 }
@@ -9,7 +9,9 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	broker "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
@@ -22,6 +24,8 @@ type UnbindEndpoint struct {
 	bindingsStorage   storage.Bindings
 	instancesStorage  storage.Instances
 	operationsStorage storage.Operations
+	outbox            storage.Outbox
+	actions           storage.Actions
 	bindingsManager   broker.BindingsManager
 	publisher         event.Publisher
 }
@@ -32,6 +36,8 @@ func NewUnbind(log *slog.Logger, db storage.BrokerStorage, bindingsManager broke
 		instancesStorage:  db.Instances(),
 		bindingsManager:   bindingsManager,
 		operationsStorage: db.Operations(),
+		outbox:            db.Outbox(),
+		actions:           db.Actions(),
 		publisher:         publisher,
 	}
 }
@@ -67,7 +73,7 @@ func (b *UnbindEndpoint) unbind(ctx context.Context, instanceID, bindingID strin
 		return domain.UnbindSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to get instance %s", instanceID), http.StatusInternalServerError, fmt.Sprintf("failed to get instance %s", instanceID))
 	}
 
-	_, err = b.bindingsStorage.Get(instanceID, bindingID)
+	binding, err := b.bindingsStorage.Get(instanceID, bindingID)
 	switch {
 	case dberr.IsNotFound(err):
 		return domain.UnbindSpec{}, apiresponses.ErrBindingDoesNotExist
@@ -81,7 +87,7 @@ func (b *UnbindEndpoint) unbind(ctx context.Context, instanceID, bindingID strin
 	}
 
 	if lastOperation.Type != internal.OperationTypeDeprovision {
-		err = b.bindingsManager.Delete(ctx, instance, bindingID)
+		err = b.bindingsManager.Delete(ctx, instance, binding)
 		if err != nil {
 			b.log.Error(fmt.Sprintf("Unbind error during removal of service account resources: %s", err))
 			return domain.UnbindSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to delete binding resources for binding %s and instance %s: %v", bindingID, instanceID, err), http.StatusInternalServerError, fmt.Sprintf("failed to delete resources for binding %s and instance %s: %v", bindingID, instanceID, err))
@@ -94,6 +100,13 @@ func (b *UnbindEndpoint) unbind(ctx context.Context, instanceID, bindingID strin
 		return domain.UnbindSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to delete binding resources for binding %s and instance %s: %v", bindingID, instanceID, err), http.StatusInternalServerError, fmt.Sprintf("failed to delete resources for binding %s and instance %s: %v", bindingID, instanceID, err))
 	}
 	b.log.Info(fmt.Sprintf("Successfully removed binding %s for instance %s", bindingID, instanceID))
+	storeBindingEvent(b.outbox, internal.BindingDeletedOutboxEvent, *instance, bindingID, b.log)
+	audit.Record(b.actions, pkg.Action{
+		Type:       pkg.BindingDeletionActionType,
+		InstanceID: instanceID,
+		Actor:      audit.ActorFromContext(ctx),
+		Message:    fmt.Sprintf("Binding %s deleted.", bindingID),
+	}, b.log)
 
 	return domain.UnbindSpec{
 		IsAsync: false,
@@ -4,16 +4,21 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
 )
 
 type LastBindingOperationEndpoint struct {
-	log *slog.Logger
+	log      *slog.Logger
+	bindings storage.Bindings
 }
 
-func NewLastBindingOperation(log *slog.Logger) *LastBindingOperationEndpoint {
-	return &LastBindingOperationEndpoint{log: log.With("service", "LastBindingOperationEndpoint")}
+func NewLastBindingOperation(log *slog.Logger, db storage.BrokerStorage) *LastBindingOperationEndpoint {
+	return &LastBindingOperationEndpoint{log: log.With("service", "LastBindingOperationEndpoint"), bindings: db.Bindings()}
 }
 
 // LastBindingOperation fetches last operation state for a service binding
@@ -24,5 +29,17 @@ func (b *LastBindingOperationEndpoint) LastBindingOperation(ctx context.Context,
 	b.log.Info(fmt.Sprintf("LastBindingOperation bindingID: %s", bindingID))
 	b.log.Info(fmt.Sprintf("LastBindingOperation details: %+v", details))
 
-	return domain.LastOperation{}, fmt.Errorf("not supported")
+	binding, err := b.bindings.Get(instanceID, bindingID)
+	switch {
+	case dberr.IsNotFound(err):
+		return domain.LastOperation{}, apiresponses.ErrBindingDoesNotExist
+	case err != nil:
+		message := fmt.Sprintf("failed to get binding %s: %s", bindingID, err)
+		return domain.LastOperation{}, apiresponses.NewFailureResponse(fmt.Errorf("failed to get binding %s", bindingID), http.StatusInternalServerError, message)
+	}
+
+	return domain.LastOperation{
+		State:       binding.State,
+		Description: binding.Description,
+	}, nil
 }
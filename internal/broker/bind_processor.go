@@ -0,0 +1,235 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	broker "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+const (
+	BindingStageServiceAccount = "service_account"
+	BindingStageRBAC           = "rbac"
+	BindingStageToken          = "token"
+	BindingStageKubeconfig     = "kubeconfig"
+	// BindingStageCleanup marks a failed binding whose runtime resources are not removed yet
+	BindingStageCleanup = "cleanup"
+	// BindingStageRemoval marks a binding removed during the creation whose runtime resources are not removed yet
+	BindingStageRemoval = "removal"
+
+	bindingOperationData = "binding_creation"
+)
+
+var bindingStages = []string{BindingStageServiceAccount, BindingStageRBAC, BindingStageToken, BindingStageKubeconfig}
+
+// BindingQueueKey identifies a binding in the binding queue, binding IDs are unique only within an instance
+func BindingQueueKey(instanceID, bindingID string) string {
+	return fmt.Sprintf("%s/%s", instanceID, bindingID)
+}
+
+// BindingProcessor creates bindings requested asynchronously. Finished stages are stored in the binding, so the processing
+// is resumed from the next stage. Bindings not created within the timeout fail and their runtime resources are removed,
+// the removal is retried until it succeeds.
+type BindingProcessor struct {
+	config           BindingConfig
+	instancesStorage storage.Instances
+	bindingsStorage  storage.Bindings
+	outbox           storage.Outbox
+	actions          storage.Actions
+	manager          broker.BindingsManager
+	publisher        event.Publisher
+	log              *slog.Logger
+}
+
+func NewBindingProcessor(cfg BindingConfig, db storage.BrokerStorage, manager broker.BindingsManager, publisher event.Publisher, log *slog.Logger) *BindingProcessor {
+	return &BindingProcessor{
+		config:           cfg,
+		instancesStorage: db.Instances(),
+		bindingsStorage:  db.Bindings(),
+		outbox:           db.Outbox(),
+		actions:          db.Actions(),
+		manager:          manager,
+		publisher:        publisher,
+		log:              log.With("service", "BindingProcessor"),
+	}
+}
+
+func (p *BindingProcessor) Execute(key string) (time.Duration, error) {
+	instanceID, bindingID, found := strings.Cut(key, "/")
+	if !found {
+		return 0, fmt.Errorf("invalid binding key %s", key)
+	}
+	log := p.log.With("instanceID", instanceID, "bindingID", bindingID)
+
+	binding, err := p.bindingsStorage.Get(instanceID, bindingID)
+	switch {
+	case dberr.IsNotFound(err):
+		log.Info("binding does not exist, stopping the processing")
+		return 0, nil
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the binding: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	if binding.State != domain.InProgress {
+		return 0, nil
+	}
+
+	instance, err := p.instancesStorage.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		// runtime resources of not existing instances do not need to be removed
+		instance = nil
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the instance: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+
+	switch binding.Stage {
+	case BindingStageCleanup:
+		return p.cleanUp(instance, binding, log)
+	case BindingStageRemoval:
+		return p.remove(instance, binding, log)
+	}
+	if instance == nil {
+		return p.fail(nil, binding, "instance does not exist", log)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.CreateBindingTimeout)
+	defer cancel()
+
+	var token string
+	for _, stage := range remainingBindingStages(binding.Stage) {
+		switch stage {
+		case BindingStageServiceAccount:
+			err = p.manager.CreateServiceAccount(ctx, instance, binding)
+		case BindingStageRBAC:
+			err = p.manager.CreateRBAC(ctx, instance, binding)
+		case BindingStageToken:
+			token, binding.ExpiresAt, err = p.manager.CreateToken(ctx, instance, binding)
+		case BindingStageKubeconfig:
+			binding.Kubeconfig, err = p.manager.BuildKubeconfig(instance, token)
+		}
+		if err != nil {
+			return p.retryOrFail(instance, binding, fmt.Sprintf("stage %s failed: %s", stage, err), log)
+		}
+		// the token is not stored, so the token stage is repeated if the kubeconfig stage fails
+		if stage == BindingStageToken {
+			continue
+		}
+		binding.Stage = stage
+		if stage == BindingStageKubeconfig {
+			continue
+		}
+		if err := p.bindingsStorage.Update(binding); err != nil {
+			log.Error(fmt.Sprintf("unable to store the stage %s: %s", stage, err))
+			return p.config.AsyncRetryInterval, nil
+		}
+	}
+
+	// the binding could be removed while the resources were created
+	if _, err := p.bindingsStorage.Get(instanceID, bindingID); dberr.IsNotFound(err) {
+		log.Info("binding was removed during the creation, removing its resources")
+		err := p.manager.Delete(ctx, instance, binding)
+		if err == nil {
+			return 0, nil
+		}
+		log.Error(fmt.Sprintf("unable to remove resources of the removed binding, retrying: %s", err))
+		// the binding is stored again, so the removal is retried until the resources are removed
+		binding.Stage = BindingStageRemoval
+		binding.Description = "binding removed during the creation, removing its resources"
+		binding.Kubeconfig = ""
+		switch err := p.bindingsStorage.Insert(binding); {
+		case dberr.IsAlreadyExists(err):
+			log.Info("binding with the same ID was requested again, its creation takes over the resources")
+			return 0, nil
+		case err != nil:
+			log.Error(fmt.Sprintf("unable to store the removed binding: %s", err))
+		}
+		return p.config.AsyncRetryInterval, nil
+	}
+
+	binding.State = domain.Succeeded
+	binding.Description = "binding created"
+	if err := p.bindingsStorage.Update(binding); err != nil {
+		log.Error(fmt.Sprintf("unable to store the created binding: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	log.Info(fmt.Sprintf("Successfully created binding %s for instance %s", bindingID, instanceID))
+	bindingCreated(p.publisher, p.outbox, p.actions, *instance, *binding, binding.CreatedBy, log)
+
+	return 0, nil
+}
+
+func (p *BindingProcessor) retryOrFail(instance *internal.Instance, binding *internal.Binding, description string, log *slog.Logger) (time.Duration, error) {
+	if time.Since(binding.CreatedAt) >= p.config.AsyncTimeout {
+		return p.fail(instance, binding, fmt.Sprintf("binding not created within %s, %s", p.config.AsyncTimeout, description), log)
+	}
+	log.Warn(fmt.Sprintf("%s, retrying", description))
+	binding.Description = description
+	if err := p.bindingsStorage.Update(binding); err != nil {
+		log.Error(fmt.Sprintf("unable to update the binding: %s", err))
+	}
+	return p.config.AsyncRetryInterval, nil
+}
+
+// fail removes runtime resources created for the binding, so a new binding with the same ID can be requested.
+// The binding stays in the cleanup stage until the resources are removed and only then is marked as failed.
+func (p *BindingProcessor) fail(instance *internal.Instance, binding *internal.Binding, description string, log *slog.Logger) (time.Duration, error) {
+	log.Error(fmt.Sprintf("binding creation failed: %s", description))
+	binding.Stage = BindingStageCleanup
+	binding.Description = description
+	binding.Kubeconfig = ""
+	if err := p.bindingsStorage.Update(binding); err != nil {
+		log.Error(fmt.Sprintf("unable to store the failed binding: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	return p.cleanUp(instance, binding, log)
+}
+
+func (p *BindingProcessor) cleanUp(instance *internal.Instance, binding *internal.Binding, log *slog.Logger) (time.Duration, error) {
+	if err := p.deleteResources(instance, binding); err != nil {
+		log.Error(fmt.Sprintf("unable to remove resources of the failed binding, retrying: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	binding.State = domain.Failed
+	if err := p.bindingsStorage.Update(binding); err != nil {
+		log.Error(fmt.Sprintf("unable to store the failed binding: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	return 0, nil
+}
+
+// remove removes runtime resources of the binding removed during the creation and then the binding itself
+func (p *BindingProcessor) remove(instance *internal.Instance, binding *internal.Binding, log *slog.Logger) (time.Duration, error) {
+	if err := p.deleteResources(instance, binding); err != nil {
+		log.Error(fmt.Sprintf("unable to remove resources of the removed binding, retrying: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	if err := p.bindingsStorage.Delete(binding.InstanceID, binding.ID); err != nil && !dberr.IsNotFound(err) {
+		log.Error(fmt.Sprintf("unable to remove the binding: %s", err))
+		return p.config.AsyncRetryInterval, nil
+	}
+	return 0, nil
+}
+
+func (p *BindingProcessor) deleteResources(instance *internal.Instance, binding *internal.Binding) error {
+	if instance == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.CreateBindingTimeout)
+	defer cancel()
+	return p.manager.Delete(ctx, instance, binding)
+}
+
+func remainingBindingStages(lastStage string) []string {
+	return bindingStages[slices.Index(bindingStages, lastStage)+1:]
+}
@@ -0,0 +1,326 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncBinding(t *testing.T) {
+	t.Run("should create binding in stages and report the last operation", func(t *testing.T) {
+		// given
+		db, bindEndpoint, processor, manager := prepareAsyncBinding(t, "binding-id")
+		lastOperationEndpoint := NewLastBindingOperation(fixLogger(), db)
+
+		// when
+		response, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{
+			ServiceID:     "123",
+			PlanID:        fixture.PlanId,
+			RawParameters: json.RawMessage(`{"role": "view"}`),
+		}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+		assert.Equal(t, bindingOperationData, response.OperationData)
+		lastOperation, err := lastOperationEndpoint.LastBindingOperation(context.Background(), instanceID1, "binding-id", domain.PollDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, lastOperation.State)
+
+		// when
+		when, err := processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.Equal(t, bindingStages, manager.calls)
+		binding, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Succeeded, binding.State)
+		assert.Equal(t, BindingStageKubeconfig, binding.Stage)
+		assert.Equal(t, "kubeconfig-token", binding.Kubeconfig)
+		lastOperation, err = lastOperationEndpoint.LastBindingOperation(context.Background(), instanceID1, "binding-id", domain.PollDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, domain.Succeeded, lastOperation.State)
+		actions, err := db.Actions().ListActionsByInstanceID(instanceID1)
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Contains(t, actions[0].Message, "Binding binding-id with role view created")
+	})
+
+	t.Run("should return the in progress binding for the same request", func(t *testing.T) {
+		// given
+		_, bindEndpoint, _, _ := prepareAsyncBinding(t, "binding-id")
+		details := domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", details, true)
+		require.NoError(t, err)
+
+		// when
+		response, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", details, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+	})
+
+	t.Run("should resume from the next stage after the last finished stage", func(t *testing.T) {
+		// given
+		db, bindEndpoint, processor, manager := prepareAsyncBinding(t, "binding-id")
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+		require.NoError(t, err)
+		manager.failingStage = BindingStageToken
+
+		// when
+		when, err := processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, when)
+		binding, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, binding.State)
+		assert.Equal(t, BindingStageRBAC, binding.Stage)
+		assert.Contains(t, binding.Description, "stage token failed")
+
+		// when
+		manager.failingStage = ""
+		manager.calls = nil
+		_, err = processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{BindingStageToken, BindingStageKubeconfig}, manager.calls)
+		binding, err = db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Succeeded, binding.State)
+	})
+
+	t.Run("should fail after the timeout and remove created resources", func(t *testing.T) {
+		// given
+		db, bindEndpoint, processor, manager := prepareAsyncBinding(t, "binding-id")
+		getBindingEndpoint := NewGetBinding(fixLogger(), db)
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+		require.NoError(t, err)
+		binding, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		binding.CreatedAt = time.Now().Add(-time.Hour)
+		require.NoError(t, db.Bindings().Update(binding))
+		manager.failingStage = BindingStageRBAC
+
+		// when
+		when, err := processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.True(t, manager.deleted)
+		binding, err = db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, binding.State)
+		assert.Contains(t, binding.Description, "binding not created within 1m0s")
+		_, err = getBindingEndpoint.GetBinding(context.Background(), instanceID1, "binding-id", domain.FetchBindingDetails{})
+		assert.ErrorContains(t, err, "Binding creation failed")
+
+		// when the platform requests the binding again
+		response, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, response.IsAsync)
+		binding, err = db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, binding.State)
+		assert.Empty(t, binding.Stage)
+	})
+
+	t.Run("should keep the failed binding in cleanup until its resources are removed", func(t *testing.T) {
+		// given
+		db, bindEndpoint, processor, manager := prepareAsyncBinding(t, "binding-id")
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+		require.NoError(t, err)
+		binding, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		binding.CreatedAt = time.Now().Add(-time.Hour)
+		require.NoError(t, db.Bindings().Update(binding))
+		manager.failingStage = BindingStageRBAC
+		manager.failDelete = true
+
+		// when
+		when, err := processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, when)
+		assert.False(t, manager.deleted)
+		binding, err = db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, binding.State)
+		assert.Equal(t, BindingStageCleanup, binding.Stage)
+		assert.Contains(t, binding.Description, "binding not created within 1m0s")
+
+		// when
+		manager.failDelete = false
+		manager.calls = nil
+		when, err = processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.True(t, manager.deleted)
+		assert.Empty(t, manager.calls)
+		binding, err = db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, binding.State)
+		assert.Contains(t, binding.Description, "binding not created within 1m0s")
+	})
+
+	t.Run("should retry the removal of resources of the binding removed during the creation", func(t *testing.T) {
+		// given
+		db, bindEndpoint, processor, manager := prepareAsyncBinding(t, "binding-id")
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+		require.NoError(t, err)
+		manager.afterStage = func(name string) {
+			if name == BindingStageKubeconfig {
+				require.NoError(t, db.Bindings().Delete(instanceID1, "binding-id"))
+			}
+		}
+		manager.failDelete = true
+
+		// when
+		when, err := processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, when)
+		binding, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, binding.State)
+		assert.Equal(t, BindingStageRemoval, binding.Stage)
+		assert.Empty(t, binding.Kubeconfig)
+		_, err = bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+		assert.ErrorContains(t, err, "binding removal in progress")
+
+		// when
+		manager.failDelete = false
+		manager.afterStage = nil
+		when, err = processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.True(t, manager.deleted)
+		_, err = db.Bindings().Get(instanceID1, "binding-id")
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should stop processing of removed binding", func(t *testing.T) {
+		// given
+		db, bindEndpoint, processor, manager := prepareAsyncBinding(t, "binding-id")
+		_, err := bindEndpoint.Bind(context.Background(), instanceID1, "binding-id", domain.BindDetails{ServiceID: "123", PlanID: fixture.PlanId}, true)
+		require.NoError(t, err)
+		require.NoError(t, db.Bindings().Delete(instanceID1, "binding-id"))
+
+		// when
+		when, err := processor.Execute(BindingQueueKey(instanceID1, "binding-id"))
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, when)
+		assert.Empty(t, manager.calls)
+	})
+
+	t.Run("should report not existing binding", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+
+		// when
+		_, err := NewLastBindingOperation(fixLogger(), db).LastBindingOperation(context.Background(), instanceID1, "binding-id", domain.PollDetails{})
+
+		// then
+		require.Error(t, err)
+		apierr := err.(*apiresponses.FailureResponse)
+		assert.Equal(t, http.StatusGone, apierr.ValidatedStatusCode(nil))
+	})
+}
+
+func prepareAsyncBinding(t *testing.T, bindingID string) (storage.BrokerStorage, *BindEndpoint, *BindingProcessor, *stagesBindingsManager) {
+	db := storage.NewMemoryStorage()
+	require.NoError(t, db.Instances().Insert(fixture.FixInstance(instanceID1)))
+	require.NoError(t, db.Operations().InsertOperation(fixture.FixOperation("operation-id", instanceID1, "provision")))
+
+	cfg := fixBindingConfig()
+	cfg.AsyncEnabled = true
+	cfg.AsyncTimeout = time.Minute
+	cfg.AsyncRetryInterval = time.Second
+	cfg.CreateBindingTimeout = time.Second
+
+	queue := automock.NewQueue(t)
+	queue.On("Add", BindingQueueKey(instanceID1, bindingID)).Return()
+	manager := &stagesBindingsManager{}
+	publisher := event.NewPubSub(fixLogger())
+	bindEndpoint := NewBind(cfg, db, fixLogger(), nil, nil, publisher, queue)
+
+	return db, bindEndpoint, NewBindingProcessor(cfg, db, manager, publisher, fixLogger()), manager
+}
+
+// stagesBindingsManager records executed stages and fails the configured stage
+type stagesBindingsManager struct {
+	calls        []string
+	failingStage string
+	failDelete   bool
+	deleted      bool
+	afterStage   func(name string)
+}
+
+func (m *stagesBindingsManager) stage(name string) error {
+	if name == m.failingStage {
+		return fmt.Errorf("API server timeout")
+	}
+	m.calls = append(m.calls, name)
+	if m.afterStage != nil {
+		m.afterStage(name)
+	}
+	return nil
+}
+
+func (m *stagesBindingsManager) Create(_ context.Context, _ *internal.Instance, _ *internal.Binding) (string, time.Time, error) {
+	return "", time.Time{}, fmt.Errorf("not supported")
+}
+
+func (m *stagesBindingsManager) Delete(_ context.Context, _ *internal.Instance, _ *internal.Binding) error {
+	if m.failDelete {
+		return fmt.Errorf("API server timeout")
+	}
+	m.deleted = true
+	return nil
+}
+
+func (m *stagesBindingsManager) CreateServiceAccount(_ context.Context, _ *internal.Instance, _ *internal.Binding) error {
+	return m.stage(BindingStageServiceAccount)
+}
+
+func (m *stagesBindingsManager) CreateRBAC(_ context.Context, _ *internal.Instance, _ *internal.Binding) error {
+	return m.stage(BindingStageRBAC)
+}
+
+func (m *stagesBindingsManager) CreateToken(_ context.Context, _ *internal.Instance, binding *internal.Binding) (string, time.Time, error) {
+	return "token", time.Now().Add(time.Duration(binding.ExpirationSeconds) * time.Second), m.stage(BindingStageToken)
+}
+
+func (m *stagesBindingsManager) BuildKubeconfig(_ *internal.Instance, token string) (string, error) {
+	return "kubeconfig-" + token, m.stage(BindingStageKubeconfig)
+}
@@ -0,0 +1,150 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
+	broker "github.com/kyma-project/kyma-environment-broker/internal/broker/bindings"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// RenewBindingEndpoint issues a new token for the ServiceAccount of an existing binding, so the binding credentials are
+// extended without recreating the binding and its RBAC resources
+type RenewBindingEndpoint struct {
+	config     BindingConfig
+	instances  storage.Instances
+	bindings   storage.Bindings
+	operations storage.Operations
+	actions    storage.Actions
+	manager    broker.BindingsManager
+	publisher  event.Publisher
+	log        *slog.Logger
+}
+
+func NewRenewBinding(cfg BindingConfig, db storage.BrokerStorage, manager broker.BindingsManager, publisher event.Publisher, log *slog.Logger) *RenewBindingEndpoint {
+	return &RenewBindingEndpoint{
+		config:     cfg,
+		instances:  db.Instances(),
+		bindings:   db.Bindings(),
+		operations: db.Operations(),
+		actions:    db.Actions(),
+		manager:    manager,
+		publisher:  publisher,
+		log:        log.With("service", "RenewBindingEndpoint"),
+	}
+}
+
+func (e *RenewBindingEndpoint) AttachRoutes(r router) {
+	r.HandleFunc("POST /bindings/{instance_id}/{binding_id}/renew", e.renew)
+}
+
+func (e *RenewBindingEndpoint) renew(w http.ResponseWriter, req *http.Request) {
+	instanceID := req.PathValue("instance_id")
+	bindingID := req.PathValue("binding_id")
+	log := e.log.With("instanceID", instanceID, "bindingID", bindingID)
+
+	instance, err := e.instances.GetByID(instanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, fmt.Errorf("instance %s does not exist", instanceID))
+		return
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the instance: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get instance %s", instanceID))
+		return
+	}
+
+	lastOperation, err := e.operations.GetLastOperation(instanceID)
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to get the last operation: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get last operation for instance %s", instanceID))
+		return
+	}
+	if lastOperation.Type == internal.OperationTypeDeprovision {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.New("binding not found"))
+		return
+	}
+
+	binding, err := e.bindings.Get(instanceID, bindingID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.New("binding not found"))
+		return
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the binding: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get binding %s", bindingID))
+		return
+	}
+	// the cleanup job removes expired bindings, so they cannot be renewed
+	if binding.ExpiresAt.Before(time.Now()) {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, errors.New("binding expired"))
+		return
+	}
+	if binding.State != domain.Succeeded || len(binding.Kubeconfig) == 0 {
+		httputil.WriteErrorResponse(w, http.StatusConflict, errors.New("binding creation is not finished"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), e.config.CreateBindingTimeout)
+	defer cancel()
+	token, expiresAt, err := e.manager.CreateToken(ctx, instance, binding)
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to create a token: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to renew binding %s: %w", bindingID, err))
+		return
+	}
+	kubeconfig, err := e.manager.BuildKubeconfig(instance, token)
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to build a kubeconfig: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to renew binding %s: %w", bindingID, err))
+		return
+	}
+
+	previousExpiresAt := binding.ExpiresAt
+	binding.ExpiresAt = expiresAt
+	binding.Kubeconfig = kubeconfig
+	if err := e.bindings.Update(binding); err != nil {
+		log.Error(fmt.Sprintf("unable to store the renewed binding: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to update binding %s", bindingID))
+		return
+	}
+	log.Info(fmt.Sprintf("Binding renewed, expires at %s", expiresAt))
+
+	e.publisher.Publish(req.Context(), BindingRenewed{PlanID: instance.ServicePlanID})
+	audit.Record(e.actions, pkg.Action{
+		Type:       pkg.BindingRenewalActionType,
+		InstanceID: instanceID,
+		Actor:      audit.ActorFromRequest(req),
+		Message: fmt.Sprintf("Binding %s renewed, expires at %s instead of %s.", bindingID,
+			binding.ExpiresAt.Format(expiresAtLayout), previousExpiresAt.Format(expiresAtLayout)),
+	}, log)
+
+	httputil.WriteResponse(w, http.StatusOK, apiresponses.BindingResponse{
+		Credentials: Credentials{
+			Kubeconfig: kubeconfig,
+		},
+		Metadata: domain.BindingMetadata{
+			ExpiresAt: binding.ExpiresAt.Format(expiresAtLayout),
+		},
+	})
+}
+
+type BindingRenewed struct {
+	PlanID string
+}
@@ -0,0 +1,137 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const renewBindingPathFormat = "/bindings/%s/%s/renew"
+
+func TestRenewBinding(t *testing.T) {
+	t.Run("should issue a new token and extend the binding", func(t *testing.T) {
+		// given
+		db, router, manager := prepareRenewBinding(t)
+		binding := fixture.FixBindingWithInstanceID("binding-id", instanceID1)
+		binding.ExpiresAt = time.Now().Add(2 * time.Minute)
+		require.NoError(t, db.Bindings().Insert(&binding))
+
+		// when
+		resp := renewBinding(router, instanceID1, "binding-id")
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var body struct {
+			Credentials Credentials            `json:"credentials"`
+			Metadata    domain.BindingMetadata `json:"metadata"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, "kubeconfig-token", body.Credentials.Kubeconfig)
+		assert.NotEmpty(t, body.Metadata.ExpiresAt)
+		assert.Equal(t, []string{BindingStageToken, BindingStageKubeconfig}, manager.calls)
+
+		renewed, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, "kubeconfig-token", renewed.Kubeconfig)
+		assert.True(t, renewed.ExpiresAt.After(time.Now().Add(9*time.Minute)))
+		actions, err := db.Actions().ListActionsByInstanceID(instanceID1)
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.BindingRenewalActionType, actions[0].Type)
+	})
+
+	t.Run("should not renew not existing binding", func(t *testing.T) {
+		// given
+		_, router, _ := prepareRenewBinding(t)
+
+		// when
+		resp := renewBinding(router, instanceID1, "binding-id")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("should not renew expired binding", func(t *testing.T) {
+		// given
+		db, router, manager := prepareRenewBinding(t)
+		binding := fixture.FixExpiredBindingWithInstanceID("binding-id", instanceID1, time.Hour)
+		require.NoError(t, db.Bindings().Insert(&binding))
+
+		// when
+		resp := renewBinding(router, instanceID1, "binding-id")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Empty(t, manager.calls)
+	})
+
+	t.Run("should not renew binding in progress", func(t *testing.T) {
+		// given
+		db, router, manager := prepareRenewBinding(t)
+		binding := fixture.FixBindingWithInstanceID("binding-id", instanceID1)
+		binding.State = domain.InProgress
+		binding.Kubeconfig = ""
+		require.NoError(t, db.Bindings().Insert(&binding))
+
+		// when
+		resp := renewBinding(router, instanceID1, "binding-id")
+
+		// then
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Empty(t, manager.calls)
+	})
+
+	t.Run("should keep the binding when the token cannot be created", func(t *testing.T) {
+		// given
+		db, router, manager := prepareRenewBinding(t)
+		binding := fixture.FixBindingWithInstanceID("binding-id", instanceID1)
+		require.NoError(t, db.Bindings().Insert(&binding))
+		manager.failingStage = BindingStageToken
+
+		// when
+		resp := renewBinding(router, instanceID1, "binding-id")
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		stored, err := db.Bindings().Get(instanceID1, "binding-id")
+		require.NoError(t, err)
+		assert.Equal(t, "kubeconfig", stored.Kubeconfig)
+		actions, err := db.Actions().ListActionsByInstanceID(instanceID1)
+		require.NoError(t, err)
+		assert.Empty(t, actions)
+	})
+}
+
+func prepareRenewBinding(t *testing.T) (storage.BrokerStorage, *httputil.Router, *stagesBindingsManager) {
+	db := storage.NewMemoryStorage()
+	require.NoError(t, db.Instances().Insert(fixture.FixInstance(instanceID1)))
+	require.NoError(t, db.Operations().InsertOperation(fixture.FixOperation("operation-id", instanceID1, internal.OperationTypeProvision)))
+
+	cfg := fixBindingConfig()
+	cfg.CreateBindingTimeout = time.Second
+	manager := &stagesBindingsManager{}
+	router := httputil.NewRouter()
+	NewRenewBinding(cfg, db, manager, event.NewPubSub(fixLogger()), fixLogger()).AttachRoutes(router)
+
+	return db, router, manager
+}
+
+func renewBinding(router *httputil.Router, instanceID, bindingID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(renewBindingPathFormat, instanceID, bindingID), nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
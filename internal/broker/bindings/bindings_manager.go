@@ -27,8 +27,14 @@ type Credentials struct {
 }
 
 type BindingsManager interface {
-	Create(ctx context.Context, instance *internal.Instance, bindingID string, expirationSeconds int) (string, time.Time, error)
-	Delete(ctx context.Context, instance *internal.Instance, bindingID string) error
+	Create(ctx context.Context, instance *internal.Instance, binding *internal.Binding) (string, time.Time, error)
+	Delete(ctx context.Context, instance *internal.Instance, binding *internal.Binding) error
+
+	// stages of Create used by the asynchronous binding creation
+	CreateServiceAccount(ctx context.Context, instance *internal.Instance, binding *internal.Binding) error
+	CreateRBAC(ctx context.Context, instance *internal.Instance, binding *internal.Binding) error
+	CreateToken(ctx context.Context, instance *internal.Instance, binding *internal.Binding) (string, time.Time, error)
+	BuildKubeconfig(instance *internal.Instance, token string) (string, error)
 }
 
 type ClientProvider interface {
@@ -42,23 +48,44 @@ type KubeconfigProvider interface {
 type ServiceAccountBindingsManager struct {
 	clientProvider    ClientProvider
 	kubeconfigBuilder *kubeconfig.Builder
+	roles             RoleTemplates
 }
 
-func NewServiceAccountBindingsManager(clientProvider ClientProvider, kubeconfigProvider KubeconfigProvider) *ServiceAccountBindingsManager {
+func NewServiceAccountBindingsManager(clientProvider ClientProvider, kubeconfigProvider KubeconfigProvider, roles RoleTemplates) *ServiceAccountBindingsManager {
 	return &ServiceAccountBindingsManager{
 		clientProvider:    clientProvider,
 		kubeconfigBuilder: kubeconfig.NewBuilder(nil, kubeconfigProvider),
+		roles:             roles,
 	}
 }
 
-func (c *ServiceAccountBindingsManager) Create(ctx context.Context, instance *internal.Instance, bindingID string, expirationSeconds int) (string, time.Time, error) {
+func (c *ServiceAccountBindingsManager) Create(ctx context.Context, instance *internal.Instance, binding *internal.Binding) (string, time.Time, error) {
+	if err := c.CreateServiceAccount(ctx, instance, binding); err != nil {
+		return "", time.Time{}, err
+	}
+	if err := c.CreateRBAC(ctx, instance, binding); err != nil {
+		return "", time.Time{}, err
+	}
+	token, expiresAt, err := c.CreateToken(ctx, instance, binding)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	kubeconfigContent, err := c.BuildKubeconfig(instance, token)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return kubeconfigContent, expiresAt, nil
+}
+
+func (c *ServiceAccountBindingsManager) CreateServiceAccount(ctx context.Context, instance *internal.Instance, binding *internal.Binding) error {
 	clientset, err := c.clientProvider.K8sClientSetForRuntimeID(instance.RuntimeID)
 
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("while creating a runtime client for binding creation: %v", err)
+		return fmt.Errorf("while creating a runtime client for binding creation: %v", err)
 	}
 
-	serviceBindingName := BindingName(bindingID)
+	serviceBindingName := BindingName(binding.ID)
 	fmt.Printf("Creating a service account binding for runtime %s with name %s", instance.RuntimeID, serviceBindingName)
 
 	_, err = clientset.CoreV1().ServiceAccounts(BindingNamespace).Create(ctx,
@@ -71,26 +98,74 @@ func (c *ServiceAccountBindingsManager) Create(ctx context.Context, instance *in
 		}, mv1.CreateOptions{})
 
 	if err != nil && !apierrors.IsAlreadyExists(err) {
-		return "", time.Time{}, fmt.Errorf("while creating a service account: %v", err)
+		return fmt.Errorf("while creating a service account: %v", err)
 	}
 
-	_, err = clientset.RbacV1().ClusterRoles().Create(ctx,
-		&rbacv1.ClusterRole{
-			TypeMeta: mv1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
-			ObjectMeta: mv1.ObjectMeta{
-				Name:   serviceBindingName,
-				Labels: map[string]string{"app.kubernetes.io/managed-by": "kcp-kyma-environment-broker"},
-			},
-			Rules: []rbacv1.PolicyRule{
-				{
-					Verbs:     []string{"*"},
-					APIGroups: []string{"*"},
-					Resources: []string{"*"},
+	return nil
+}
+
+func (c *ServiceAccountBindingsManager) CreateRBAC(ctx context.Context, instance *internal.Instance, binding *internal.Binding) error {
+	template, found := c.roles[binding.Role]
+	if !found {
+		return fmt.Errorf("binding role %s is not configured", binding.Role)
+	}
+
+	clientset, err := c.clientProvider.K8sClientSetForRuntimeID(instance.RuntimeID)
+
+	if err != nil {
+		return fmt.Errorf("while creating a runtime client for binding creation: %v", err)
+	}
+
+	serviceBindingName := BindingName(binding.ID)
+
+	clusterRoleName := template.ClusterRole
+	if clusterRoleName == "" {
+		clusterRoleName = serviceBindingName
+		_, err = clientset.RbacV1().ClusterRoles().Create(ctx,
+			&rbacv1.ClusterRole{
+				TypeMeta: mv1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+				ObjectMeta: mv1.ObjectMeta{
+					Name:   serviceBindingName,
+					Labels: map[string]string{"app.kubernetes.io/managed-by": "kcp-kyma-environment-broker"},
 				},
-			},
-		}, mv1.CreateOptions{})
-	if err != nil && !apierrors.IsAlreadyExists(err) {
-		return "", time.Time{}, fmt.Errorf("while creating a cluster role: %v", err)
+				Rules: template.policyRules(),
+			}, mv1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("while creating a cluster role: %v", err)
+		}
+	}
+
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     clusterRoleName,
+	}
+	subjects := []rbacv1.Subject{
+		{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: BindingNamespace,
+			Name:      serviceBindingName,
+		},
+	}
+
+	if template.Namespaced {
+		for _, namespace := range binding.Namespaces {
+			_, err = clientset.RbacV1().RoleBindings(namespace).Create(ctx, &rbacv1.RoleBinding{
+				TypeMeta: mv1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: mv1.ObjectMeta{
+					Name:      serviceBindingName,
+					Namespace: namespace,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "kcp-kyma-environment-broker"},
+				},
+				RoleRef:  roleRef,
+				Subjects: subjects,
+			}, mv1.CreateOptions{})
+
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("while creating a role binding in namespace %s: %v", namespace, err)
+			}
+		}
+		return nil
 	}
 
 	_, err = clientset.RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
@@ -99,68 +174,82 @@ func (c *ServiceAccountBindingsManager) Create(ctx context.Context, instance *in
 			Name:   serviceBindingName,
 			Labels: map[string]string{"app.kubernetes.io/managed-by": "kcp-kyma-environment-broker"},
 		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: rbacv1.GroupName,
-			Kind:     "ClusterRole",
-			Name:     serviceBindingName,
-		},
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      rbacv1.ServiceAccountKind,
-				Namespace: "kyma-system",
-				Name:      serviceBindingName,
-			},
-		},
+		RoleRef:  roleRef,
+		Subjects: subjects,
 	}, mv1.CreateOptions{})
 
 	if err != nil && !apierrors.IsAlreadyExists(err) {
-		return "", time.Time{}, fmt.Errorf("while creating a cluster role binding: %v", err)
+		return fmt.Errorf("while creating a cluster role binding: %v", err)
 	}
 
+	return nil
+}
+
+// CreateToken issues a token of the binding service account, the token is valid for the expiration seconds of the binding
+func (c *ServiceAccountBindingsManager) CreateToken(ctx context.Context, instance *internal.Instance, binding *internal.Binding) (string, time.Time, error) {
+	clientset, err := c.clientProvider.K8sClientSetForRuntimeID(instance.RuntimeID)
+
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("while creating a runtime client for binding creation: %v", err)
+	}
+
+	serviceBindingName := BindingName(binding.ID)
+
 	tokenRequest := &authv1.TokenRequest{
 		ObjectMeta: mv1.ObjectMeta{
 			Name:      serviceBindingName,
-			Namespace: "kyma-system",
+			Namespace: BindingNamespace,
 			Labels:    map[string]string{"app.kubernetes.io/managed-by": "kcp-kyma-environment-broker"},
 		},
 		Spec: authv1.TokenRequestSpec{
-			ExpirationSeconds: ptr.Integer64(int64(expirationSeconds)),
+			ExpirationSeconds: ptr.Integer64(binding.ExpirationSeconds),
 		},
 	}
 
-	tkn, err := clientset.CoreV1().ServiceAccounts("kyma-system").CreateToken(ctx, serviceBindingName, tokenRequest, mv1.CreateOptions{})
+	tkn, err := clientset.CoreV1().ServiceAccounts(BindingNamespace).CreateToken(ctx, serviceBindingName, tokenRequest, mv1.CreateOptions{})
 
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("while creating a service account kubeconfig: %v", err)
 	}
 
-	expiresAt := tkn.Status.ExpirationTimestamp.Time
-	kubeconfigContent, err := c.kubeconfigBuilder.BuildFromAdminKubeconfigForBinding(instance.RuntimeID, tkn.Status.Token)
+	return tkn.Status.Token, tkn.Status.ExpirationTimestamp.Time, nil
+}
+
+func (c *ServiceAccountBindingsManager) BuildKubeconfig(instance *internal.Instance, token string) (string, error) {
+	kubeconfigContent, err := c.kubeconfigBuilder.BuildFromAdminKubeconfigForBinding(instance.RuntimeID, token)
 
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("while creating a kubeconfig: %v", err)
+		return "", fmt.Errorf("while creating a kubeconfig: %v", err)
 	}
 
-	return string(kubeconfigContent), expiresAt, nil
+	return string(kubeconfigContent), nil
 }
 
-func (c *ServiceAccountBindingsManager) Delete(ctx context.Context, instance *internal.Instance, bindingID string) error {
+func (c *ServiceAccountBindingsManager) Delete(ctx context.Context, instance *internal.Instance, binding *internal.Binding) error {
 	clientset, err := c.clientProvider.K8sClientSetForRuntimeID(instance.RuntimeID)
 
 	if err != nil {
 		return fmt.Errorf("while creating a runtime client for binding creation: %v", err)
 	}
 
-	serviceBindingName := BindingName(bindingID)
+	serviceBindingName := BindingName(binding.ID)
+
+	// remove bindings, the role template could be changed after the binding was created, so all binding kinds are removed
+	for _, namespace := range binding.Namespaces {
+		err = clientset.RbacV1().RoleBindings(namespace).Delete(ctx, serviceBindingName, mv1.DeleteOptions{})
+
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("while removing a role binding in namespace %s: %v", namespace, err)
+		}
+	}
 
-	// remove a binding
 	err = clientset.RbacV1().ClusterRoleBindings().Delete(ctx, serviceBindingName, mv1.DeleteOptions{})
 
 	if err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("while removing a cluster role binding: %v", err)
 	}
 
-	// remove a role
+	// remove a role, cluster roles referenced by role templates are named differently and stay untouched
 	err = clientset.RbacV1().ClusterRoles().Delete(ctx, serviceBindingName, mv1.DeleteOptions{})
 
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -168,7 +257,7 @@ func (c *ServiceAccountBindingsManager) Delete(ctx context.Context, instance *in
 	}
 
 	// remove an account
-	err = clientset.CoreV1().ServiceAccounts("kyma-system").Delete(ctx, serviceBindingName, mv1.DeleteOptions{})
+	err = clientset.CoreV1().ServiceAccounts(BindingNamespace).Delete(ctx, serviceBindingName, mv1.DeleteOptions{})
 
 	if err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("while creating a service account: %v", err)
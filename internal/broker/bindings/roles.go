@@ -0,0 +1,121 @@
+package broker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const AdminRole = "admin"
+
+// RoleTemplate defines permissions granted by a binding. The binding uses the existing ClusterRole set in ClusterRole,
+// or KEB creates a ClusterRole with the Rules. Namespaced templates are bound with RoleBindings in the namespaces
+// requested for the binding, other templates with a ClusterRoleBinding.
+type RoleTemplate struct {
+	ClusterRole string       `yaml:"clusterRole"`
+	Rules       []PolicyRule `yaml:"rules"`
+	Namespaced  bool         `yaml:"namespaced"`
+}
+
+type PolicyRule struct {
+	APIGroups []string `yaml:"apiGroups"`
+	Resources []string `yaml:"resources"`
+	Verbs     []string `yaml:"verbs"`
+}
+
+type RoleTemplates map[string]RoleTemplate
+
+// DefaultRoleTemplates returns templates used when no templates are configured, the admin role keeps the permissions
+// granted by bindings created before roles were introduced
+func DefaultRoleTemplates() RoleTemplates {
+	return RoleTemplates{
+		AdminRole: {
+			Rules: []PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+		},
+		"edit":            {ClusterRole: "edit"},
+		"view":            {ClusterRole: "view"},
+		"namespace-admin": {ClusterRole: "admin", Namespaced: true},
+	}
+}
+
+func NewRoleTemplatesFromFile(filePath string) (RoleTemplates, error) {
+	if filePath == "" {
+		return DefaultRoleTemplates(), nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("while opening binding roles file: %w", err)
+	}
+	defer file.Close()
+
+	return NewRoleTemplates(file)
+}
+
+func NewRoleTemplates(r io.Reader) (RoleTemplates, error) {
+	templates := RoleTemplates{}
+	if err := yaml.NewDecoder(r).Decode(&templates); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("while decoding binding roles: %w", err)
+	}
+	if len(templates) == 0 {
+		return DefaultRoleTemplates(), nil
+	}
+	for name, template := range templates {
+		if (template.ClusterRole == "") == (len(template.Rules) == 0) {
+			return nil, fmt.Errorf("binding role %s must define either clusterRole or rules", name)
+		}
+	}
+	return templates, nil
+}
+
+// Validate checks that the role is configured and the namespaces match the scope of the role
+func (t RoleTemplates) Validate(role string, namespaces []string) error {
+	template, found := t[role]
+	if !found {
+		return fmt.Errorf("role %s is not supported, supported roles: %s", role, strings.Join(t.Names(), ", "))
+	}
+	if !template.Namespaced {
+		if len(namespaces) > 0 {
+			return fmt.Errorf("namespaces cannot be set for the cluster-wide role %s", role)
+		}
+		return nil
+	}
+	if len(namespaces) == 0 {
+		return fmt.Errorf("role %s requires namespaces", role)
+	}
+	for i, namespace := range namespaces {
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(msgs, ", "))
+		}
+		if slices.Contains(namespaces[:i], namespace) {
+			return fmt.Errorf("duplicated namespace %s", namespace)
+		}
+	}
+	return nil
+}
+
+func (t RoleTemplates) Names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (t RoleTemplate) policyRules() []rbacv1.PolicyRule {
+	rules := make([]rbacv1.PolicyRule, 0, len(t.Rules))
+	for _, rule := range t.Rules {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: rule.APIGroups,
+			Resources: rule.Resources,
+			Verbs:     rule.Verbs,
+		})
+	}
+	return rules
+}
@@ -0,0 +1,108 @@
+package broker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRoleTemplates(t *testing.T) {
+	t.Run("should read role templates", func(t *testing.T) {
+		// when
+		templates, err := NewRoleTemplates(strings.NewReader(`
+deployer:
+  clusterRole: edit
+  namespaced: true
+reader:
+  rules:
+    - apiGroups: [""]
+      resources: ["pods", "services"]
+      verbs: ["get", "list", "watch"]
+`))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"deployer", "reader"}, templates.Names())
+		assert.Equal(t, RoleTemplate{ClusterRole: "edit", Namespaced: true}, templates["deployer"])
+		assert.Equal(t, []string{"pods", "services"}, templates["reader"].policyRules()[0].Resources)
+	})
+
+	t.Run("should use default templates for empty file", func(t *testing.T) {
+		// when
+		templates, err := NewRoleTemplates(strings.NewReader(""))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, DefaultRoleTemplates(), templates)
+	})
+
+	t.Run("should reject template with cluster role and rules", func(t *testing.T) {
+		// when
+		_, err := NewRoleTemplates(strings.NewReader(`
+reader:
+  clusterRole: view
+  rules:
+    - apiGroups: [""]
+      resources: ["pods"]
+      verbs: ["get"]
+`))
+
+		// then
+		assert.EqualError(t, err, "binding role reader must define either clusterRole or rules")
+	})
+}
+
+func TestRoleTemplates_Validate(t *testing.T) {
+	templates := DefaultRoleTemplates()
+
+	for tn, tc := range map[string]struct {
+		role          string
+		namespaces    []string
+		expectedError string
+	}{
+		"cluster-wide role": {
+			role: "view",
+		},
+		"namespaced role": {
+			role:       "namespace-admin",
+			namespaces: []string{"ci", "staging"},
+		},
+		"unknown role": {
+			role:          "owner",
+			expectedError: "role owner is not supported, supported roles: admin, edit, namespace-admin, view",
+		},
+		"namespaces for cluster-wide role": {
+			role:          "admin",
+			namespaces:    []string{"ci"},
+			expectedError: "namespaces cannot be set for the cluster-wide role admin",
+		},
+		"namespaced role without namespaces": {
+			role:          "namespace-admin",
+			expectedError: "role namespace-admin requires namespaces",
+		},
+		"invalid namespace": {
+			role:          "namespace-admin",
+			namespaces:    []string{"CI"},
+			expectedError: `invalid namespace "CI"`,
+		},
+		"duplicated namespace": {
+			role:          "namespace-admin",
+			namespaces:    []string{"ci", "ci"},
+			expectedError: "duplicated namespace ci",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			err := templates.Validate(tc.role, tc.namespaces)
+
+			// then
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -58,6 +58,32 @@ type Config struct {
 	RejectUnsupportedParameters bool `envconfig:"default=false"`
 	EnablePlanUpgrades          bool `envconfig:"default=false"`
 	CheckQuotaLimit             bool `envconfig:"default=false"`
+
+	// MaintenanceWindowPlans are plans for which the maintenance window can be set and updates can be deferred to it
+	MaintenanceWindowPlans EnablePlans `envconfig:"default=no-plan"`
+	// InstanceLabelsPlans are plans for which users can set labels of the instance
+	InstanceLabelsPlans EnablePlans `envconfig:"default=no-plan"`
+	// ModulesUpdatePlans are plans for which users can change modules of the Kyma resource in an update
+	ModulesUpdatePlans EnablePlans `envconfig:"default=no-plan"`
+	// NetworkingUpdatePlans are plans for which users can extend the nodes CIDR in an update
+	NetworkingUpdatePlans EnablePlans `envconfig:"default=no-plan"`
+}
+
+// ValidatePlans checks if all plan lists contain only known plans, it must be called after plans from the plans configuration file are registered
+func (c *Config) ValidatePlans() error {
+	for name, plans := range map[string]EnablePlans{
+		"EnablePlans":            c.EnablePlans,
+		"Binding.BindablePlans":  c.Binding.BindablePlans,
+		"MaintenanceWindowPlans": c.MaintenanceWindowPlans,
+		"InstanceLabelsPlans":    c.InstanceLabelsPlans,
+		"ModulesUpdatePlans":     c.ModulesUpdatePlans,
+		"NetworkingUpdatePlans":  c.NetworkingUpdatePlans,
+	} {
+		if err := plans.Validate(); err != nil {
+			return fmt.Errorf("while validating %s: %w", name, err)
+		}
+	}
+	return nil
 }
 
 type ServicesConfig map[string]Service
@@ -131,15 +157,25 @@ type EnablePlans []string
 
 // Unmarshal provides custom parsing of enabled plans.
 // Implements envconfig.Unmarshal interface.
+// Unmarshal does not verify plan names, because plans defined in the plans configuration file are not registered yet, use Validate instead
 func (m *EnablePlans) Unmarshal(in string) error {
-	plans := strings.Split(in, ",")
-	for _, name := range plans {
+	*m = strings.Split(in, ",")
+	return nil
+}
+
+// noPlan is the default of plan lists which should not contain any plan
+const noPlan = "no-plan"
+
+// Validate checks if all plans are known, it must be called after plans from the plans configuration file are registered
+func (m *EnablePlans) Validate() error {
+	for _, name := range *m {
+		if name == "" || name == noPlan {
+			continue
+		}
 		if _, exists := PlanIDsMapping[name]; !exists {
 			return fmt.Errorf("unrecognized %v plan name", name)
 		}
 	}
-
-	*m = plans
 	return nil
 }
 
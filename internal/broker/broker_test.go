@@ -21,7 +21,35 @@ func TestEnablePlans_Unmarshal(t *testing.T) {
 			t.Errorf("Expected plan %s not found in the list", plan)
 		}
 	}
-	assert.Error(t, enablePlans.Unmarshal("invalid,plan"))
+	assert.NoError(t, enablePlans.Validate())
+
+	assert.NoError(t, enablePlans.Unmarshal("invalid,plan"))
+	assert.Error(t, enablePlans.Validate())
+}
+
+func TestConfig_ValidatePlans(t *testing.T) {
+	// given
+	cfg := Config{
+		EnablePlans:            EnablePlans{"aws", "azure"},
+		Binding:                BindingConfig{BindablePlans: EnablePlans{"aws"}},
+		MaintenanceWindowPlans: EnablePlans{"no-plan"},
+		InstanceLabelsPlans:    EnablePlans{""},
+		ModulesUpdatePlans:     EnablePlans{"gcp"},
+		NetworkingUpdatePlans:  EnablePlans{"aws"},
+	}
+
+	// when
+	err := cfg.ValidatePlans()
+
+	// then
+	assert.NoError(t, err)
+
+	// when
+	cfg.NetworkingUpdatePlans = EnablePlans{"invalid"}
+	err = cfg.ValidatePlans()
+
+	// then
+	assert.EqualError(t, err, "while validating NetworkingUpdatePlans: unrecognized invalid plan name")
 }
 
 func TestEnablePlans_Contains(t *testing.T) {
@@ -19,6 +19,9 @@ const (
 	kymaClassID                  = "47c9dcbf-ff30-448e-ab36-d3bad66ba281"
 	AccountCleanupJob            = "accountcleanup-job"
 	ServiceBindingCleanupJobName = "service-binding-cleanup-job"
+	ExpiratorJobName             = "expirator-job"
+	TrialCleanupJobName          = "trial-cleanup-job"
+	DeprovisionRetriggerJobName  = "deprovision-retrigger-job"
 
 	instancesURL       = "/oauth/v2/service_instances"
 	expireInstanceURL  = "/expire/service_instance"
@@ -20,10 +20,11 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/additionalproperties"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
 	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
 	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/networking"
@@ -76,6 +77,8 @@ type ProvisionEndpoint struct {
 	operationsStorage       storage.Operations
 	instanceStorage         storage.Instances
 	instanceArchivedStorage storage.InstancesArchived
+	actionStorage           storage.Actions
+	instanceLabelsStorage   storage.InstanceLabels
 	queue                   Queue
 	enabledPlanIDs          map[string]struct{}
 	plansConfig             PlansConfig
@@ -99,7 +102,7 @@ type ProvisionEndpoint struct {
 	quotaWhitelist         whitelist.Set
 	rulesService           *rules.RulesService
 	gardenerClient         *gardener.Client
-	awsClientFactory       aws.ClientFactory
+	zonesClientFactory     hyperscalers.ClientFactory
 	useCredentialsBindings bool
 }
 
@@ -108,6 +111,10 @@ const (
 	IngressFilteringNotSupportedForPlanMsg             = "ingress filtering is not available for %s plan"
 	IngressFilteringNotSupportedForExternalCustomerMsg = "ingress filtering is not available for your type of license"
 	IngressFilteringOptionIsNotSupported               = "ingress filtering option is not available"
+	MaintenanceWindowNotSupportedForPlanMsg            = "maintenance window is not available for %s plan"
+	LabelsNotSupportedForPlanMsg                       = "labels are not available for %s plan"
+	ModulesUpdateNotSupportedForPlanMsg                = "modules cannot be updated for %s plan"
+	NetworkingUpdateNotSupportedForPlanMsg             = "networking cannot be updated for %s plan"
 	FailedToValidateZonesMsg                           = "Failed to validate the number of available zones. Please try again later."
 )
 
@@ -130,7 +137,7 @@ func NewProvision(brokerConfig Config,
 	quotaWhitelist whitelist.Set,
 	rulesService *rules.RulesService,
 	gardenerClient *gardener.Client,
-	awsClientFactory aws.ClientFactory,
+	zonesClientFactory hyperscalers.ClientFactory,
 ) *ProvisionEndpoint {
 	enabledPlanIDs := map[string]struct{}{}
 	for _, planName := range brokerConfig.EnablePlans {
@@ -144,6 +151,8 @@ func NewProvision(brokerConfig Config,
 		operationsStorage:       db.Operations(),
 		instanceStorage:         db.Instances(),
 		instanceArchivedStorage: db.InstancesArchived(),
+		actionStorage:           db.Actions(),
+		instanceLabelsStorage:   db.InstanceLabels(),
 		queue:                   queue,
 		log:                     log.With("service", "ProvisionEndpoint"),
 		enabledPlanIDs:          enabledPlanIDs,
@@ -163,7 +172,7 @@ func NewProvision(brokerConfig Config,
 		quotaWhitelist:          quotaWhitelist,
 		rulesService:            rulesService,
 		gardenerClient:          gardenerClient,
-		awsClientFactory:        awsClientFactory,
+		zonesClientFactory:      zonesClientFactory,
 	}
 }
 
@@ -175,55 +184,20 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	logger := b.log.With("instanceID", instanceID, "operationID", operationID, "planID", details.PlanID)
 	logger.Info(fmt.Sprintf("Provision called with context: %s", marshallRawContext(hideSensitiveDataFromRawContext(details.RawContext))))
 
-	region, found := middleware.RegionFromContext(ctx)
-	if !found {
-		err := fmt.Errorf("%s", "No region specified in request.")
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusInternalServerError, "provisioning")
-	}
-	platformProvider, found := middleware.ProviderFromContext(ctx)
-	if !found {
-		err := fmt.Errorf("%s", "No provider specified in request.")
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusInternalServerError, "provisioning")
-	}
-
-	// EXTRACT INPUT PARAMETERS / PROVISIONING PARAMETERS
-	parameters, err := b.extractInputParameters(details)
+	provisioningParameters, err := b.provisioningParameters(ctx, details)
 	if err != nil {
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "while extracting input parameters")
+		return domain.ProvisionedServiceSpec{}, err
 	}
-	ersContext, err := b.extractERSContext(details)
+	region := provisioningParameters.PlatformRegion
+	parameters := provisioningParameters.Parameters
+	ersContext := provisioningParameters.ErsContext
 	logger = logger.With("globalAccountID", ersContext.GlobalAccountID)
-	if err != nil {
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "while extracting context")
-	}
 	if b.config.MonitorAdditionalProperties {
 		b.monitorAdditionalProperties(instanceID, ersContext, details.RawParameters)
 	}
-	provisioningParameters := internal.ProvisioningParameters{
-		PlanID:           details.PlanID,
-		ServiceID:        details.ServiceID,
-		ErsContext:       ersContext,
-		Parameters:       parameters,
-		PlatformRegion:   region,
-		PlatformProvider: platformProvider,
-	}
-	// TODO: remove once we implemented proper filtering of parameters - removing parameters that are not supported by the plan
-	if details.PlanID == TrialPlanID {
-		provisioningParameters.Parameters.MachineType = nil
-		provisioningParameters.Parameters.AutoScalerMin = nil
-		provisioningParameters.Parameters.AutoScalerMax = nil
-	}
-	providerValues, err := b.valuesProvider.ValuesForPlanAndParameters(provisioningParameters)
+	providerValues, err := b.validatedProviderValues(ctx, instanceID, details, provisioningParameters, logger)
 	if err != nil {
-		errMsg := fmt.Sprintf("unable to provide default values for instance %s: %s", instanceID, err)
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
-	}
-
-	// validation of incoming input
-	err = b.validate(ctx, details, provisioningParameters, logger)
-	if err != nil {
-		errMsg := fmt.Sprintf("[instanceID: %s] %s", instanceID, err)
-		return domain.ProvisionedServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+		return domain.ProvisionedServiceSpec{}, err
 	}
 
 	logger.Info(fmt.Sprintf("Starting provisioning runtime: Name=%s, GlobalAccountID=%s, SubAccountID=%s, PlatformRegion=%s, ProvisioningParameters.Region=%s, ProvisioningParameters.ColocateControlPlane=%t, ProvisioningParameters.MachineType=%s",
@@ -241,28 +215,13 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		return b.handleExistingOperation(existingOperation, provisioningParameters)
 	}
 
-	shootName := gardener.CreateShootName()
-	shootDomainSuffix := strings.Trim(b.shootDomain, ".")
-
-	dashboardURL := b.createDashboardURL(details.PlanID, instanceID)
-
 	// create and save new operation
-	operation, err := internal.NewProvisioningOperationWithID(operationID, instanceID, provisioningParameters)
+	operation, err := b.newProvisioningOperation(operationID, instanceID, provisioningParameters, providerValues)
 	if err != nil {
 		logger.Error(fmt.Sprintf("cannot create new operation: %s", err))
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot create new operation")
 	}
-
-	operation.ProviderValues = &providerValues
-	operation.ShootName = shootName
-	operation.ShootDomain = fmt.Sprintf("%s.%s", shootName, shootDomainSuffix)
-	operation.ShootDNSProviders = b.shootDnsProviders
-	operation.DashboardURL = dashboardURL
-	// for own cluster plan - KEB uses provided shoot name and shoot domain
-	if IsOwnClusterPlan(provisioningParameters.PlanID) {
-		operation.ShootName = provisioningParameters.Parameters.ShootName
-		operation.ShootDomain = provisioningParameters.Parameters.ShootDomain
-	}
+	dashboardURL := operation.DashboardURL
 	logger.Info(fmt.Sprintf("Runtime ShootDomain: %s", operation.ShootDomain))
 
 	err = b.operationsStorage.InsertOperation(operation.Operation)
@@ -295,6 +254,21 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot save instance")
 	}
 
+	if len(provisioningParameters.Parameters.Labels) > 0 {
+		err = b.instanceLabelsStorage.Set(instanceID, provisioningParameters.Parameters.Labels)
+		if err != nil {
+			logger.Error(fmt.Sprintf("cannot save labels of the instance in storage: %s", err))
+			return domain.ProvisionedServiceSpec{}, fmt.Errorf("cannot save instance")
+		}
+	}
+
+	audit.Record(b.actionStorage, pkg.Action{
+		Type:       pkg.ProvisioningActionType,
+		InstanceID: instanceID,
+		Actor:      audit.ActorFromContext(ctx),
+		Message:    fmt.Sprintf("Provisioning of %s plan started with operation %s.", instance.ServicePlanName, operationID),
+	}, logger)
+
 	logger.Info("Adding operation to provisioning queue")
 	b.queue.Add(operation.ID)
 
@@ -308,6 +282,98 @@ func (b *ProvisionEndpoint) Provision(ctx context.Context, instanceID string, de
 	}, nil
 }
 
+// Preview validates the provisioning request in the same way as Provision and returns the operation which would be created.
+// Nothing is stored and no operation is queued.
+func (b *ProvisionEndpoint) Preview(ctx context.Context, instanceID string, details domain.ProvisionDetails) (internal.ProvisioningOperation, error) {
+	logger := b.log.With("instanceID", instanceID, "planID", details.PlanID, "preview", true)
+
+	provisioningParameters, err := b.provisioningParameters(ctx, details)
+	if err != nil {
+		return internal.ProvisioningOperation{}, err
+	}
+	providerValues, err := b.validatedProviderValues(ctx, instanceID, details, provisioningParameters, logger)
+	if err != nil {
+		return internal.ProvisioningOperation{}, err
+	}
+	return b.newProvisioningOperation(uuid.New().String(), instanceID, provisioningParameters, providerValues)
+}
+
+func (b *ProvisionEndpoint) provisioningParameters(ctx context.Context, details domain.ProvisionDetails) (internal.ProvisioningParameters, error) {
+	region, found := middleware.RegionFromContext(ctx)
+	if !found {
+		err := fmt.Errorf("%s", "No region specified in request.")
+		return internal.ProvisioningParameters{}, apiresponses.NewFailureResponse(err, http.StatusInternalServerError, "provisioning")
+	}
+	platformProvider, found := middleware.ProviderFromContext(ctx)
+	if !found {
+		err := fmt.Errorf("%s", "No provider specified in request.")
+		return internal.ProvisioningParameters{}, apiresponses.NewFailureResponse(err, http.StatusInternalServerError, "provisioning")
+	}
+
+	// EXTRACT INPUT PARAMETERS / PROVISIONING PARAMETERS
+	parameters, err := b.extractInputParameters(details)
+	if err != nil {
+		return internal.ProvisioningParameters{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "while extracting input parameters")
+	}
+	ersContext, err := b.extractERSContext(details)
+	if err != nil {
+		return internal.ProvisioningParameters{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "while extracting context")
+	}
+	provisioningParameters := internal.ProvisioningParameters{
+		PlanID:           details.PlanID,
+		ServiceID:        details.ServiceID,
+		ErsContext:       ersContext,
+		Parameters:       parameters,
+		PlatformRegion:   region,
+		PlatformProvider: platformProvider,
+	}
+	// TODO: remove once we implemented proper filtering of parameters - removing parameters that are not supported by the plan
+	if details.PlanID == TrialPlanID {
+		provisioningParameters.Parameters.MachineType = nil
+		provisioningParameters.Parameters.AutoScalerMin = nil
+		provisioningParameters.Parameters.AutoScalerMax = nil
+	}
+	return provisioningParameters, nil
+}
+
+func (b *ProvisionEndpoint) validatedProviderValues(ctx context.Context, instanceID string, details domain.ProvisionDetails, provisioningParameters internal.ProvisioningParameters, logger *slog.Logger) (internal.ProviderValues, error) {
+	providerValues, err := b.valuesProvider.ValuesForPlanAndParameters(provisioningParameters)
+	if err != nil {
+		errMsg := fmt.Sprintf("unable to provide default values for instance %s: %s", instanceID, err)
+		return internal.ProviderValues{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+	}
+
+	// validation of incoming input
+	err = b.validate(ctx, details, provisioningParameters, logger)
+	if err != nil {
+		errMsg := fmt.Sprintf("[instanceID: %s] %s", instanceID, err)
+		return internal.ProviderValues{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, errMsg)
+	}
+	return providerValues, nil
+}
+
+func (b *ProvisionEndpoint) newProvisioningOperation(operationID, instanceID string, provisioningParameters internal.ProvisioningParameters, providerValues internal.ProviderValues) (internal.ProvisioningOperation, error) {
+	operation, err := internal.NewProvisioningOperationWithID(operationID, instanceID, provisioningParameters)
+	if err != nil {
+		return internal.ProvisioningOperation{}, err
+	}
+
+	shootName := gardener.CreateShootName()
+	shootDomainSuffix := strings.Trim(b.shootDomain, ".")
+
+	operation.ProviderValues = &providerValues
+	operation.ShootName = shootName
+	operation.ShootDomain = fmt.Sprintf("%s.%s", shootName, shootDomainSuffix)
+	operation.ShootDNSProviders = b.shootDnsProviders
+	operation.DashboardURL = b.createDashboardURL(provisioningParameters.PlanID, instanceID)
+	// for own cluster plan - KEB uses provided shoot name and shoot domain
+	if IsOwnClusterPlan(provisioningParameters.PlanID) {
+		operation.ShootName = provisioningParameters.Parameters.ShootName
+		operation.ShootDomain = provisioningParameters.Parameters.ShootDomain
+	}
+	return operation, nil
+}
+
 // UseCredentialsBindings indicates whether to use credentials bindings when creating AWS clients, it is a deprecated func and will be removed in future releases
 // when all KCP instances are migrated to use credentials bindings
 func (b *ProvisionEndpoint) UseCredentialsBindings() {
@@ -388,19 +454,19 @@ func (b *ProvisionEndpoint) validate(ctx context.Context, details domain.Provisi
 		}
 
 		// todo: simplify it, remove "if" when all KCP insdtances are migrated to use credentials bindings
-		var awsClient aws.Client
+		var hyperscalerClient hyperscalers.Client
 		if b.useCredentialsBindings {
-			awsClient, err = newAWSClientUsingCredentialsBinding(ctx, l, b.rulesService, b.gardenerClient, b.awsClientFactory, provisioningParameters, values)
+			hyperscalerClient, err = newHyperscalerClientUsingCredentialsBinding(ctx, l, b.rulesService, b.gardenerClient, b.zonesClientFactory, provisioningParameters, values)
 		} else {
-			awsClient, err = newAWSClient(ctx, l, b.rulesService, b.gardenerClient, b.awsClientFactory, provisioningParameters, values)
+			hyperscalerClient, err = newHyperscalerClient(ctx, l, b.rulesService, b.gardenerClient, b.zonesClientFactory, provisioningParameters, values)
 		}
 		if err != nil {
-			l.Error(fmt.Sprintf("unable to create AWS client: %s", err))
+			l.Error(fmt.Sprintf("unable to create hyperscaler client: %s", err))
 			return apiresponses.NewFailureResponse(fmt.Errorf(FailedToValidateZonesMsg), http.StatusUnprocessableEntity, FailedToValidateZonesMsg)
 		}
 
 		for machineType := range discoveredZones {
-			zonesCount, err := awsClient.AvailableZonesCount(ctx, machineType)
+			zonesCount, err := hyperscalerClient.AvailableZonesCount(ctx, machineType)
 			if err != nil {
 				l.Error(fmt.Sprintf("unable to get available zones: %s", err))
 				return apiresponses.NewFailureResponse(fmt.Errorf(FailedToValidateZonesMsg), http.StatusUnprocessableEntity, FailedToValidateZonesMsg)
@@ -470,6 +536,16 @@ func (b *ProvisionEndpoint) validate(ctx context.Context, details domain.Provisi
 		return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 	}
 
+	err = validateMaintenanceWindow(provisioningParameters.PlanID, parameters.MaintenanceWindow, b.config.MaintenanceWindowPlans)
+	if err != nil {
+		return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
+	err = validateLabels(provisioningParameters.PlanID, parameters.Labels, b.config.InstanceLabelsPlans)
+	if err != nil {
+		return apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
 	planValidator, err := b.validator(&details, provisioningParameters.PlatformProvider, ctx)
 	if err != nil {
 		return fmt.Errorf("while creating plan validator: %w", err)
@@ -562,22 +638,34 @@ func validateIngressFiltering(provisioningParameters internal.ProvisioningParame
 	return nil
 }
 
+func validateMaintenanceWindow(planID string, maintenanceWindow *pkg.MaintenanceWindowDTO, plans EnablePlans) error {
+	if maintenanceWindow == nil {
+		return nil
+	}
+	if !plans.Contains(PlanNamesMapping[planID]) {
+		return fmt.Errorf(MaintenanceWindowNotSupportedForPlanMsg, PlanNamesMapping[planID])
+	}
+	return maintenanceWindow.Validate()
+}
+
+func validateLabels(planID string, labels map[string]string, plans EnablePlans) error {
+	if labels == nil {
+		return nil
+	}
+	if !plans.Contains(PlanNamesMapping[planID]) {
+		return fmt.Errorf(LabelsNotSupportedForPlanMsg, PlanNamesMapping[planID])
+	}
+	return pkg.ValidateLabels(labels)
+}
+
 func isEuRestrictedAccess(ctx context.Context) bool {
 	platformRegion, _ := middleware.RegionFromContext(ctx)
 	return euaccess.IsEURestrictedAccess(platformRegion)
 }
 
 func supportsAdditionalWorkerNodePools(planID string) bool {
-	var unsupportedPlans = []string{
-		FreemiumPlanID,
-		TrialPlanID,
-	}
-	for _, unsupportedPlan := range unsupportedPlans {
-		if planID == unsupportedPlan {
-			return false
-		}
-	}
-	return true
+	traits, found := Traits(planID)
+	return !found || traits.AdditionalWorkerNodePools
 }
 
 func AreNamesUnique(pools []pkg.AdditionalWorkerNodePool) bool {
@@ -824,6 +912,8 @@ func (b *ProvisionEndpoint) createDashboardURL(planID, instanceID string) string
 	}
 }
 
+const maxNodesCIDRSuffix = 23
+
 func validateCidr(cidr string) (*net.IPNet, error) {
 	ip, ipNet, err := net.ParseCIDR(cidr)
 	if err != nil {
@@ -854,9 +944,8 @@ func (b *ProvisionEndpoint) validateNetworking(parameters pkg.ProvisioningParame
 	}
 	// error is handled before, in the validate CIDR
 	cidr, _ := netip.ParsePrefix(parameters.Networking.NodesCidr)
-	const maxSuffix = 23
-	if cidr.Bits() > maxSuffix {
-		err = multierror.Append(err, fmt.Errorf("the suffix of the node CIDR must not be greater than %d", maxSuffix))
+	if cidr.Bits() > maxNodesCIDRSuffix {
+		err = multierror.Append(err, fmt.Errorf("the suffix of the node CIDR must not be greater than %d", maxNodesCIDRSuffix))
 	}
 
 	if parameters.Networking.PodsCidr != nil {
@@ -1012,15 +1101,15 @@ func validateQuotaLimit(instanceStorage storage.Instances, quotaClient QuotaClie
 	return nil
 }
 
-func newAWSClient(
+func newHyperscalerClient(
 	ctx context.Context,
 	log *slog.Logger,
 	rulesService *rules.RulesService,
 	gardenerClient *gardener.Client,
-	awsClientFactory aws.ClientFactory,
+	zonesClientFactory hyperscalers.ClientFactory,
 	provisioningParameters internal.ProvisioningParameters,
 	values internal.ProviderValues,
-) (aws.Client, error) {
+) (hyperscalers.Client, error) {
 	log.Info("Zones discovery enabled, validating zone count using subscription secret")
 	attr := &rules.ProvisioningAttributes{
 		Plan:              PlanNamesMapping[provisioningParameters.PlanID],
@@ -1055,27 +1144,23 @@ func newAWSClient(
 		return nil, fmt.Errorf("unable to get secret %s/%s", secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName())
 	}
 
-	accessKeyID, secretAccessKey, err := aws.ExtractCredentials(secret)
+	client, err := zonesClientFactory.New(ctx, pkg.CloudProviderFromString(values.ProviderType), secret, values.Region)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract AWS credentials")
-	}
-	client, err := awsClientFactory.New(ctx, accessKeyID, secretAccessKey, values.Region)
-	if err != nil {
-		return nil, fmt.Errorf("unable to create AWS client")
+		return nil, fmt.Errorf("unable to create hyperscaler client: %w", err)
 	}
 
 	return client, nil
 }
 
-func newAWSClientUsingCredentialsBinding(
+func newHyperscalerClientUsingCredentialsBinding(
 	ctx context.Context,
 	log *slog.Logger,
 	rulesService *rules.RulesService,
 	gardenerClient *gardener.Client,
-	awsClientFactory aws.ClientFactory,
+	zonesClientFactory hyperscalers.ClientFactory,
 	provisioningParameters internal.ProvisioningParameters,
 	values internal.ProviderValues,
-) (aws.Client, error) {
+) (hyperscalers.Client, error) {
 	log.Info("Zones discovery enabled, validating zone count using subscription secret")
 	attr := &rules.ProvisioningAttributes{
 		Plan:              PlanNamesMapping[provisioningParameters.PlanID],
@@ -1110,13 +1195,9 @@ func newAWSClientUsingCredentialsBinding(
 		return nil, fmt.Errorf("unable to get secret %s/%s", credentialsBinding.GetSecretRefNamespace(), credentialsBinding.GetSecretRefName())
 	}
 
-	accessKeyID, secretAccessKey, err := aws.ExtractCredentials(secret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract AWS credentials")
-	}
-	client, err := awsClientFactory.New(ctx, accessKeyID, secretAccessKey, values.Region)
+	client, err := zonesClientFactory.New(ctx, pkg.CloudProviderFromString(values.ProviderType), secret, values.Region)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create AWS client")
+		return nil, fmt.Errorf("unable to create hyperscaler client: %w", err)
 	}
 
 	return client, nil
@@ -26,6 +26,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 
 	"github.com/pivotal-cf/brokerapi/v12/domain"
@@ -2364,6 +2365,84 @@ func TestUnsupportedMachineType(t *testing.T) {
 	assert.EqualError(t, err, "In the region europe-west3, the machine type c2d-highmem-32 is not available, it is supported in the southamerica-east1, us-central1")
 }
 
+func TestProvisionWithLabels(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		labelsPlans   broker.EnablePlans
+		labels        string
+		expectedError string
+	}{
+		"should store labels": {
+			labelsPlans: broker.EnablePlans{broker.GCPPlanName},
+			labels:      `{"team": "x", "example.com/env": "prod"}`,
+		},
+		"should reject labels for a plan without labels": {
+			labelsPlans:   broker.EnablePlans{broker.AWSPlanName},
+			labels:        `{"team": "x"}`,
+			expectedError: "labels are not available for gcp plan",
+		},
+		"should reject an invalid label value": {
+			labelsPlans:   broker.EnablePlans{broker.GCPPlanName},
+			labels:        `{"team": "x y"}`,
+			expectedError: "invalid labels: label value of \"team\"",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			memoryStorage := storage.NewMemoryStorage()
+
+			queue := &automock.Queue{}
+			queue.On("Add", mock.AnythingOfType("string"))
+
+			kcBuilder := &kcMock.KcBuilder{}
+			kcBuilder.On("GetServerURL", "").Return("", fmt.Errorf("error"))
+			provisionEndpoint := broker.NewProvision(
+				broker.Config{
+					EnablePlans:         []string{"gcp"},
+					URL:                 brokerURL,
+					InstanceLabelsPlans: tc.labelsPlans,
+				},
+				gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+				imConfigFixture,
+				memoryStorage,
+				queue,
+				broker.PlansConfig{},
+				fixLogger(),
+				dashboardConfig,
+				kcBuilder,
+				whitelist.Set{},
+				newSchemaService(t),
+				newProviderSpec(t),
+				fixValueProvider(t),
+				false,
+				config.FakeProviderConfigProvider{},
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+			)
+
+			// when
+			_, err := provisionEndpoint.Provision(fixRequestContext(t, "cf-eu10"), instanceID, domain.ProvisionDetails{
+				ServiceID:     serviceID,
+				PlanID:        broker.GCPPlanID,
+				RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "region": "%s", "labels": %s}`, clusterName, "europe-west3", tc.labels)),
+				RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, "Test@Test.pl")),
+			}, true)
+
+			// then
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			labels, err := memoryStorage.InstanceLabels().Get(instanceID)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"team": "x", "example.com/env": "prod"}, labels)
+		})
+	}
+}
+
 func TestUnsupportedMachineTypeInAdditionalWorkerNodePools(t *testing.T) {
 	// given
 	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
@@ -3573,7 +3652,7 @@ func TestDiscoveryZones(t *testing.T) {
 				nil,
 				rulesService,
 				fixture.CreateGardenerClient(),
-				fixture.NewFakeAWSClientFactory(tc.zones, tc.awsError),
+				fixture.NewFakeHyperscalerClientFactory(tc.zones, tc.awsError),
 			)
 
 			// when
@@ -3694,3 +3773,74 @@ func newEmptyProviderSpec() *configuration.ProviderSpec {
 	spec, _ := configuration.NewProviderSpec(strings.NewReader(""))
 	return spec
 }
+
+func TestProvision_Preview(t *testing.T) {
+	// given
+	memoryStorage := storage.NewMemoryStorage()
+	queue := &automock.Queue{}
+	kcBuilder := &kcMock.KcBuilder{}
+	provisionEndpoint := broker.NewProvision(
+		broker.Config{
+			EnablePlans: []string{"gcp", "azure"},
+			URL:         brokerURL,
+		},
+		gardener.Config{Project: "test", ShootDomain: "example.com", DNSProviders: fixDNSProviders()},
+		imConfigFixture,
+		memoryStorage,
+		queue,
+		broker.PlansConfig{},
+		fixLogger(),
+		dashboardConfig,
+		kcBuilder,
+		whitelist.Set{},
+		newSchemaService(t),
+		newProviderSpec(t),
+		fixValueProvider(t),
+		false,
+		config.FakeProviderConfigProvider{},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	t.Run("should return the operation without storing it", func(t *testing.T) {
+		// when
+		operation, err := provisionEndpoint.Preview(fixRequestContext(t, "req-region"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(fmt.Sprintf(`{"name": "%s", "region": "%s"}`, clusterName, clusterRegion)),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, "Test@Test.pl")),
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, instanceID, operation.InstanceID)
+		assert.Equal(t, "req-region", operation.ProvisioningParameters.PlatformRegion)
+		assert.Equal(t, clusterRegion, operation.ProviderValues.Region)
+		assert.Equal(t, fixDNSProviders(), operation.ShootDNSProviders)
+
+		_, err = memoryStorage.Operations().GetProvisioningOperationByID(operation.ID)
+		assert.True(t, dberr.IsNotFound(err))
+		_, err = memoryStorage.Instances().GetByID(instanceID)
+		assert.True(t, dberr.IsNotFound(err))
+		queue.AssertNotCalled(t, "Add", mock.Anything)
+	})
+
+	t.Run("should return the validation error", func(t *testing.T) {
+		// when
+		_, err := provisionEndpoint.Preview(fixRequestContext(t, "req-region"), instanceID, domain.ProvisionDetails{
+			ServiceID:     serviceID,
+			PlanID:        planID,
+			RawParameters: json.RawMessage(`{"name": "", "region": "not-existing"}`),
+			RawContext:    json.RawMessage(fmt.Sprintf(`{"globalaccount_id": "%s", "subaccount_id": "%s", "user_id": "%s"}`, globalAccountID, subAccountID, "Test@Test.pl")),
+		})
+
+		// then
+		require.Error(t, err)
+		apiErr, ok := err.(*apiresponses.FailureResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, apiErr.ValidatedStatusCode(nil))
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"net/http"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 
 	"github.com/google/uuid"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -21,15 +23,17 @@ type DeprovisionEndpoint struct {
 
 	instancesStorage  storage.Instances
 	operationsStorage storage.Deprovisioning
+	actionStorage     storage.Actions
 
 	queue Queue
 }
 
-func NewDeprovision(instancesStorage storage.Instances, operationsStorage storage.Operations, q Queue, log *slog.Logger) *DeprovisionEndpoint {
+func NewDeprovision(instancesStorage storage.Instances, operationsStorage storage.Operations, actionStorage storage.Actions, q Queue, log *slog.Logger) *DeprovisionEndpoint {
 	return &DeprovisionEndpoint{
 		log:               log.With("service", "DeprovisionEndpoint"),
 		instancesStorage:  instancesStorage,
 		operationsStorage: operationsStorage,
+		actionStorage:     actionStorage,
 
 		queue: q,
 	}
@@ -101,6 +105,13 @@ func (b *DeprovisionEndpoint) Deprovision(ctx context.Context, instanceID string
 		return domain.DeprovisionServiceSpec{}, fmt.Errorf("cannot save operation")
 	}
 
+	audit.Record(b.actionStorage, pkg.Action{
+		Type:       pkg.DeprovisioningActionType,
+		InstanceID: instanceID,
+		Actor:      audit.ActorFromContext(ctx),
+		Message:    fmt.Sprintf("Deprovisioning started with operation %s.", operationID),
+	}, logger)
+
 	logger.Info("Adding operation to deprovisioning queue")
 	b.queue.Add(operationID)
 
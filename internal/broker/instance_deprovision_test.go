@@ -2,15 +2,18 @@ package broker
 
 import (
 	"context"
+	"encoding/base64"
 	"log/slog"
 	"os"
 	"testing"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker/automock"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/middlewares"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -28,7 +31,7 @@ func TestDeprovisionEndpoint_DeprovisionNotExistingInstance(t *testing.T) {
 	queue := &automock.Queue{}
 	queue.On("Add", mock.AnythingOfType("string"))
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, fixLogger())
+	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), memoryStorage.Actions(), queue, fixLogger())
 
 	// when
 	_, err := svc.Deprovision(context.TODO(), "inst-0001", domain.DeprovisionDetails{}, true)
@@ -46,16 +49,22 @@ func TestDeprovisionEndpoint_DeprovisionExistingInstance(t *testing.T) {
 	queue := &automock.Queue{}
 	queue.On("Add", mock.AnythingOfType("string"))
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, fixLogger())
+	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), memoryStorage.Actions(), queue, fixLogger())
 
 	// when
-	_, err = svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
+	identity := "cloudfoundry " + base64.StdEncoding.EncodeToString([]byte(`{"email":"user@example.com"}`))
+	_, err = svc.Deprovision(context.WithValue(context.TODO(), middlewares.OriginatingIdentityKey, identity), instanceID, domain.DeprovisionDetails{}, true)
 
 	// then
 	require.NoError(t, err)
 	operation, err := memoryStorage.Operations().GetDeprovisioningOperationByInstanceID(instanceID)
 	require.NoError(t, err)
 	assert.Equal(t, domain.LastOperationState("pending"), operation.State)
+	actions, err := memoryStorage.Actions().ListActionsByInstanceID(instanceID)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, pkg.DeprovisioningActionType, actions[0].Type)
+	assert.Equal(t, "user@example.com", actions[0].Actor)
 }
 
 func TestDeprovisionEndpoint_DeprovisionExistingOperationInProgress(t *testing.T) {
@@ -70,7 +79,7 @@ func TestDeprovisionEndpoint_DeprovisionExistingOperationInProgress(t *testing.T
 	queue := &automock.Queue{}
 	queue.On("Add", mock.AnythingOfType("string"))
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, fixLogger())
+	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), memoryStorage.Actions(), queue, fixLogger())
 
 	// when
 	res, err := svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
@@ -83,6 +92,9 @@ func TestDeprovisionEndpoint_DeprovisionExistingOperationInProgress(t *testing.T
 	require.NoError(t, err)
 	assert.Equal(t, domain.InProgress, operation.State)
 	assert.Equal(t, "", operation.ProvisionerOperationID)
+	actions, err := memoryStorage.Actions().ListActionsByInstanceID(instanceID)
+	require.NoError(t, err)
+	assert.Empty(t, actions)
 }
 
 func TestDeprovisionEndpoint_DeprovisionExistingOperationFailed(t *testing.T) {
@@ -97,7 +109,7 @@ func TestDeprovisionEndpoint_DeprovisionExistingOperationFailed(t *testing.T) {
 	queue := &automock.Queue{}
 	queue.On("Add", mock.Anything)
 
-	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), queue, fixLogger())
+	svc := NewDeprovision(memoryStorage.Instances(), memoryStorage.Operations(), memoryStorage.Actions(), queue, fixLogger())
 
 	// when
 	res, err := svc.Deprovision(context.TODO(), instanceID, domain.DeprovisionDetails{}, true)
@@ -53,7 +53,7 @@ func (b *LastOperationEndpoint) LastOperation(ctx context.Context, instanceID st
 				fmt.Sprintf("while getting last operation from storage"))
 		}
 		return domain.LastOperation{
-			State:       mapStateToOSBCompliantState(lastOp.State),
+			State:       mapStateToOSBCompliantState(lastOp.Type, lastOp.State),
 			Description: lastOp.Description,
 		}, nil
 	}
@@ -76,7 +76,7 @@ func (b *LastOperationEndpoint) LastOperation(ctx context.Context, instanceID st
 	}
 
 	return domain.LastOperation{
-		State:       mapStateToOSBCompliantState(operation.State),
+		State:       mapStateToOSBCompliantState(operation.Type, operation.State),
 		Description: operation.Description,
 	}, nil
 }
@@ -98,10 +98,16 @@ func (b *LastOperationEndpoint) responseFromInstanceArchived(instanceID string,
 	}
 }
 
-func mapStateToOSBCompliantState(opState domain.LastOperationState) domain.LastOperationState {
+func mapStateToOSBCompliantState(opType internal.OperationType, opState domain.LastOperationState) domain.LastOperationState {
+	canceledInstanceLifecycle := opType == internal.OperationTypeProvision || opType == internal.OperationTypeDeprovision
 	switch {
 	case opState == internal.OperationStatePending || opState == internal.OperationStateRetrying:
 		return domain.InProgress
+	// the instance is not provisioned (or deprovisioned) when the operation is canceled
+	case opState == internal.OperationStateCanceling && canceledInstanceLifecycle:
+		return domain.InProgress
+	case opState == internal.OperationStateCanceled && canceledInstanceLifecycle:
+		return domain.Failed
 	case opState == internal.OperationStateCanceled || opState == internal.OperationStateCanceling:
 		return domain.Succeeded
 	default:
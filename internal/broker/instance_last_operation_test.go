@@ -176,6 +176,41 @@ func TestLastOperation_LastOperation(t *testing.T) {
 			Description: updateOp.Description,
 		}, response)
 	})
+	t.Run("Should convert canceling and canceled provisioning states", func(t *testing.T) {
+		// given
+		memoryStorage := storage.NewMemoryStorage()
+		provisioning := fixOperation()
+		provisioning.State = internal.OperationStateCanceling
+		err := memoryStorage.Operations().InsertOperation(provisioning)
+		assert.NoError(t, err)
+
+		lastOperationEndpoint := broker.NewLastOperation(memoryStorage.Operations(), memoryStorage.InstancesArchived(), fixLogger())
+
+		// when
+		response, err := lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{OperationData: operationID})
+		assert.NoError(t, err)
+
+		// then
+		assert.Equal(t, domain.InProgress, response.State)
+
+		// given
+		canceled, err := memoryStorage.Operations().GetOperationByID(operationID)
+		assert.NoError(t, err)
+		canceled.State = internal.OperationStateCanceled
+		canceled.Description = "Operation canceled"
+		_, err = memoryStorage.Operations().UpdateOperation(*canceled)
+		assert.NoError(t, err)
+
+		// when
+		response, err = lastOperationEndpoint.LastOperation(context.TODO(), instID, domain.PollDetails{OperationData: operationID})
+		assert.NoError(t, err)
+
+		// then
+		assert.Equal(t, domain.LastOperation{
+			State:       domain.Failed,
+			Description: "Operation canceled",
+		}, response)
+	})
 	t.Run("Should return provisioning operation", func(t *testing.T) {
 		// given
 		memoryStorage := storage.NewMemoryStorage()
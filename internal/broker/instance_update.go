@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -16,9 +17,11 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/additionalproperties"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	"github.com/kyma-project/kyma-environment-broker/internal/dashboard"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/kubeconfig"
+	"github.com/kyma-project/kyma-environment-broker/internal/networking"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -27,18 +30,25 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
 	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
 	"github.com/santhosh-tekuri/jsonschema/v6"
 	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const planChangeMessage = "Plan change"
+const (
+	planChangeMessage           = "Plan change"
+	oidcChangeMessage           = "OIDC"
+	administratorsChangeMessage = "Runtime Administrators"
+	labelsChangeMessage         = "Labels"
+)
 
 type ContextUpdateHandler interface {
-	Handle(instance *internal.Instance, newCtx internal.ERSContext) (bool, error)
+	Handle(ctx context.Context, instance *internal.Instance, newCtx internal.ERSContext) (bool, error)
 }
 
 type UpdateEndpoint struct {
@@ -52,8 +62,9 @@ type UpdateEndpoint struct {
 	subaccountMovementEnabled                bool
 	updateCustomResourcesLabelsOnAccountMove bool
 
-	operationStorage storage.Operations
-	actionStorage    storage.Actions
+	operationStorage      storage.Operations
+	actionStorage         storage.Actions
+	instanceLabelsStorage storage.InstanceLabels
 
 	updatingQueue Queue
 
@@ -67,14 +78,14 @@ type UpdateEndpoint struct {
 	useSmallerMachineTypes      bool
 	infrastructureManagerConfig InfrastructureManager
 
-	schemaService    *SchemaService
-	providerSpec     *configuration.ProviderSpec
-	planSpec         *configuration.PlanSpecifications
-	quotaClient      QuotaClient
-	quotaWhitelist   whitelist.Set
-	rulesService     *rules.RulesService
-	gardenerClient   *gardener.Client
-	awsClientFactory aws.ClientFactory
+	schemaService      *SchemaService
+	providerSpec       *configuration.ProviderSpec
+	planSpec           *configuration.PlanSpecifications
+	quotaClient        QuotaClient
+	quotaWhitelist     whitelist.Set
+	rulesService       *rules.RulesService
+	gardenerClient     *gardener.Client
+	zonesClientFactory hyperscalers.ClientFactory
 }
 
 func NewUpdate(cfg Config,
@@ -98,7 +109,7 @@ func NewUpdate(cfg Config,
 	quotaWhitelist whitelist.Set,
 	rulesService *rules.RulesService,
 	gardenerClient *gardener.Client,
-	awsClientFactory aws.ClientFactory,
+	zonesClientFactory hyperscalers.ClientFactory,
 ) *UpdateEndpoint {
 	return &UpdateEndpoint{
 		config:                                   cfg,
@@ -106,6 +117,7 @@ func NewUpdate(cfg Config,
 		instanceStorage:                          db.Instances(),
 		operationStorage:                         db.Operations(),
 		actionStorage:                            db.Actions(),
+		instanceLabelsStorage:                    db.InstanceLabels(),
 		contextUpdateHandler:                     ctxUpdateHandler,
 		processingEnabled:                        processingEnabled,
 		subaccountMovementEnabled:                subaccountMovementEnabled,
@@ -124,7 +136,7 @@ func NewUpdate(cfg Config,
 		quotaWhitelist:                           quotaWhitelist,
 		rulesService:                             rulesService,
 		gardenerClient:                           gardenerClient,
-		awsClientFactory:                         awsClientFactory,
+		zonesClientFactory:                       zonesClientFactory,
 	}
 }
 
@@ -197,7 +209,7 @@ func (b *UpdateEndpoint) Update(ctx context.Context, instanceID string, details
 	}
 
 	if b.processingEnabled {
-		instance, suspendStatusChange, err := b.processContext(instance, details, lastProvisioningOperation, logger)
+		instance, suspendStatusChange, err := b.processContext(ctx, instance, details, lastProvisioningOperation, logger)
 		if err != nil {
 			return domain.UpdateServiceSpec{}, err
 		}
@@ -261,12 +273,201 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 	if !asyncAllowed {
 		return domain.UpdateServiceSpec{}, apiresponses.ErrAsyncRequired
 	}
+	oldPlanID := instance.ServicePlanID
+	oldOIDC := instance.Parameters.Parameters.OIDC
+	oldAdministrators := instance.Parameters.Parameters.RuntimeAdministrators
+	oldLabels := instance.Parameters.Parameters.Labels
+	actor := audit.ActorFromContext(ctx)
+	params, operation, err := b.newUpdateOperation(ctx, instance, details, ersContext, false, logger)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+	logger = logger.With("operationID", operation.ID)
+
+	var updateStorage []string
+	if operation.UpdatedPlanID != "" {
+		updateStorage = append(updateStorage, planChangeMessage)
+	}
+	if params.Labels != nil {
+		// labels are stored before the operation is created, the update step only propagates them to the runtime resources
+		if err := b.instanceLabelsStorage.Set(instance.InstanceID, params.Labels); err != nil {
+			logger.Error(fmt.Sprintf("unable to store labels of the instance: %s", err))
+			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("Update operation failed"), http.StatusInternalServerError, "unable to store labels of the instance")
+		}
+	}
+	err = b.operationStorage.InsertOperation(operation)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
+	if params.OIDC.IsProvided() {
+		if params.OIDC.List != nil || (params.OIDC.OIDCConfigDTO != nil && !params.OIDC.OIDCConfigDTO.IsEmpty()) {
+			instance.Parameters.Parameters.OIDC = params.OIDC
+			updateStorage = append(updateStorage, oidcChangeMessage)
+		}
+	}
+
+	if params.IngressFiltering != nil {
+		instance.Parameters.Parameters.IngressFiltering = params.IngressFiltering
+		updateStorage = append(updateStorage, "Ingress Filtering")
+	}
+
+	if params.MaintenanceWindow != nil {
+		instance.Parameters.Parameters.MaintenanceWindow = params.MaintenanceWindow
+		updateStorage = append(updateStorage, "Maintenance Window")
+	}
+
+	if params.Labels != nil {
+		instance.Parameters.Parameters.Labels = params.Labels
+		updateStorage = append(updateStorage, labelsChangeMessage)
+	}
+
+	if params.UpdateNetworking(&instance.Parameters.Parameters) {
+		updateStorage = append(updateStorage, "Networking")
+	}
+
+	if len(params.RuntimeAdministrators) != 0 {
+		newAdministrators := make([]string, 0, len(params.RuntimeAdministrators))
+		newAdministrators = append(newAdministrators, params.RuntimeAdministrators...)
+		instance.Parameters.Parameters.RuntimeAdministrators = newAdministrators
+		updateStorage = append(updateStorage, administratorsChangeMessage)
+	}
+
+	if params.UpdateAutoScaler(&instance.Parameters.Parameters) {
+		updateStorage = append(updateStorage, "Auto Scaler parameters")
+	}
+	if params.MachineType != nil && *params.MachineType != "" {
+		instance.Parameters.Parameters.MachineType = params.MachineType
+	}
+
+	if supportsAdditionalWorkerNodePools(details.PlanID) && params.AdditionalWorkerNodePools != nil {
+		newAdditionalWorkerNodePools := make([]pkg.AdditionalWorkerNodePool, 0, len(params.AdditionalWorkerNodePools))
+		newAdditionalWorkerNodePools = append(newAdditionalWorkerNodePools, params.AdditionalWorkerNodePools...)
+		instance.Parameters.Parameters.AdditionalWorkerNodePools = newAdditionalWorkerNodePools
+		updateStorage = append(updateStorage, "Additional Worker Node Pools")
+	}
+
+	if len(updateStorage) > 0 {
+		if err := wait.PollUntilContextTimeout(context.Background(), 500*time.Millisecond, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+			instance, err = b.instanceStorage.Update(*instance)
+			if err != nil {
+				params := strings.Join(updateStorage, ", ")
+				logger.Warn(fmt.Sprintf("unable to update instance with new %v (%s), retrying", params, err.Error()))
+				return false, nil
+			}
+			return true, nil
+		}); err != nil {
+			response := apiresponses.NewFailureResponse(fmt.Errorf("Update operation failed"), http.StatusInternalServerError, err.Error())
+			return domain.UpdateServiceSpec{}, response
+		}
+
+		if slices.Contains(updateStorage, planChangeMessage) {
+			oldPlan := PlanNamesMapping[oldPlanID]
+			newPlan := PlanNamesMapping[details.PlanID]
+			message := fmt.Sprintf("Plan updated from %s (PlanID: %s) to %s (PlanID: %s).", oldPlan, oldPlanID, newPlan, details.PlanID)
+			audit.Record(b.actionStorage, pkg.Action{
+				Type:       pkg.PlanUpdateActionType,
+				InstanceID: instance.InstanceID,
+				Actor:      actor,
+				Message:    message,
+				OldValue:   oldPlanID,
+				NewValue:   details.PlanID,
+			}, logger)
+		}
+		if slices.Contains(updateStorage, oidcChangeMessage) {
+			audit.Record(b.actionStorage, pkg.Action{
+				Type:       pkg.OIDCUpdateActionType,
+				InstanceID: instance.InstanceID,
+				Actor:      actor,
+				Message:    "OIDC configuration updated.",
+				OldValue:   audit.Redact(oldOIDC),
+				NewValue:   audit.Redact(instance.Parameters.Parameters.OIDC),
+			}, logger)
+		}
+		if slices.Contains(updateStorage, administratorsChangeMessage) {
+			audit.Record(b.actionStorage, pkg.Action{
+				Type:       pkg.AdministratorsUpdateActionType,
+				InstanceID: instance.InstanceID,
+				Actor:      actor,
+				Message:    "Runtime administrators updated.",
+				OldValue:   strings.Join(oldAdministrators, ","),
+				NewValue:   strings.Join(instance.Parameters.Parameters.RuntimeAdministrators, ","),
+			}, logger)
+		}
+		if slices.Contains(updateStorage, labelsChangeMessage) {
+			audit.Record(b.actionStorage, pkg.Action{
+				Type:       pkg.LabelsUpdateActionType,
+				InstanceID: instance.InstanceID,
+				Actor:      actor,
+				Message:    "Labels updated.",
+				OldValue:   labels.Set(oldLabels).String(),
+				NewValue:   labels.Set(instance.Parameters.Parameters.Labels).String(),
+			}, logger)
+		}
+	}
+	logger.Debug("Adding update operation to the processing queue")
+	b.updatingQueue.Add(operation.ID)
+
+	return domain.UpdateServiceSpec{
+		IsAsync:       true,
+		DashboardURL:  instance.DashboardURL,
+		OperationData: operation.ID,
+		Metadata: domain.InstanceMetadata{
+			Labels: ResponseLabels(*lastProvisioningOperation, *instance, b.config.URL, b.kcBuilder),
+		},
+	}, nil
+}
+
+// PreviewUpdate validates the update request in the same way as Update and returns the operation which would be created.
+// Nothing is stored, the context (suspension, subaccount movement) is not processed and no operation is queued.
+func (b *UpdateEndpoint) PreviewUpdate(ctx context.Context, instanceID string, details domain.UpdateDetails) (internal.Operation, *internal.Instance, error) {
+	logger := b.log.With("instanceID", instanceID, "preview", true)
+	instance, err := b.instanceStorage.GetByID(instanceID)
+	if err != nil && dberr.IsNotFound(err) {
+		return internal.Operation{}, nil, apiresponses.NewFailureResponse(err, http.StatusNotFound, fmt.Sprintf("could not execute update for instanceID %s", instanceID))
+	} else if err != nil {
+		logger.Error(fmt.Sprintf("unable to get instance: %s", err.Error()))
+		return internal.Operation{}, nil, fmt.Errorf("unable to get instance")
+	}
+	// the context of the instance is used when the request does not contain any
+	ersContext := instance.Parameters.ErsContext
+	if len(details.RawContext) > 0 {
+		ersContext = internal.ERSContext{}
+		if err := json.Unmarshal(details.RawContext, &ersContext); err != nil {
+			return internal.Operation{}, nil, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "unable to unmarshal context")
+		}
+	}
+	if err := b.validateWithJsonSchemaValidator(details, instance); err != nil {
+		return internal.Operation{}, nil, apiresponses.NewFailureResponse(err, http.StatusBadRequest, "validation failed")
+	}
+	if instance.IsExpired() {
+		return internal.Operation{}, nil, apiresponses.NewFailureResponse(fmt.Errorf("cannot update an expired instance"), http.StatusBadRequest, "")
+	}
+	lastProvisioningOperation, err := b.operationStorage.GetProvisioningOperationByInstanceID(instance.InstanceID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot fetch provisioning operation for instance with ID: %s : %s", instance.InstanceID, err.Error()))
+		return internal.Operation{}, nil, fmt.Errorf("unable to process the update")
+	}
+	if lastProvisioningOperation.State == domain.Failed {
+		return internal.Operation{}, nil, apiresponses.NewFailureResponse(fmt.Errorf("Unable to process an update of a failed instance"), http.StatusUnprocessableEntity, "")
+	}
+
+	_, operation, err := b.newUpdateOperation(ctx, instance, details, ersContext, true, logger)
+	if err != nil {
+		return internal.Operation{}, nil, err
+	}
+	return operation, instance, nil
+}
+
+// newUpdateOperation validates the update parameters and creates the update operation, an accepted plan change is applied to the instance.
+// In the preview, credentials are not read from Gardener and the hyperscaler API is not called, zones from the providers configuration are used instead.
+func (b *UpdateEndpoint) newUpdateOperation(ctx context.Context, instance *internal.Instance, details domain.UpdateDetails, ersContext internal.ERSContext, preview bool, logger *slog.Logger) (internal.UpdatingParametersDTO, internal.Operation, error) {
 	var params internal.UpdatingParametersDTO
 	if len(details.RawParameters) != 0 {
 		err := json.Unmarshal(details.RawParameters, &params)
 		if err != nil {
 			logger.Error(fmt.Sprintf("unable to unmarshal parameters: %s", err.Error()))
-			return domain.UpdateServiceSpec{}, fmt.Errorf("unable to unmarshal parameters")
+			return params, internal.Operation{}, fmt.Errorf("unable to unmarshal parameters")
 		}
 		logger.Debug(fmt.Sprintf("Updating with params: %+v", params))
 	}
@@ -280,12 +481,12 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 	providerValues, err := b.valuesProvider.ValuesForPlanAndParameters(instance.Parameters)
 	if err != nil {
 		logger.Error(fmt.Sprintf("unable to obtain dummyProvider values: %s", err.Error()))
-		return domain.UpdateServiceSpec{}, fmt.Errorf("unable to process the request")
+		return params, internal.Operation{}, fmt.Errorf("unable to process the request")
 	}
 
 	regionsSupportingMachine, err := b.providerSpec.RegionSupportingMachine(providerValues.ProviderType)
 	if err != nil {
-		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusUnprocessableEntity, err.Error())
 	}
 	if !regionsSupportingMachine.IsSupported(valueOfPtr(instance.Parameters.Parameters.Region), valueOfPtr(params.MachineType)) {
 		message := fmt.Sprintf(
@@ -294,7 +495,7 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 			valueOfPtr(params.MachineType),
 			strings.Join(regionsSupportingMachine.SupportedRegions(valueOfPtr(params.MachineType)), ", "),
 		)
-		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusBadRequest, message)
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusBadRequest, message)
 	}
 
 	discoveredZones := make(map[string]int)
@@ -307,25 +508,31 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 			discoveredZones[additionalWorkerNodePool.MachineType] = 0
 		}
 
-		awsClient, err := newAWSClient(ctx, logger, b.rulesService, b.gardenerClient, b.awsClientFactory, instance.Parameters, providerValues)
-		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create AWS client: %s", err))
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf(FailedToValidateZonesMsg), http.StatusBadRequest, FailedToValidateZonesMsg)
-		}
-
-		for machineType := range discoveredZones {
-			zonesCount, err := awsClient.AvailableZonesCount(ctx, machineType)
+		if preview {
+			for machineType := range discoveredZones {
+				discoveredZones[machineType] = len(providerValues.Zones)
+			}
+		} else {
+			hyperscalerClient, err := newHyperscalerClient(ctx, logger, b.rulesService, b.gardenerClient, b.zonesClientFactory, instance.Parameters, providerValues)
 			if err != nil {
-				logger.Error(fmt.Sprintf("unable to get available zones: %s", err))
-				return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf(FailedToValidateZonesMsg), http.StatusBadRequest, FailedToValidateZonesMsg)
+				logger.Error(fmt.Sprintf("unable to create hyperscaler client: %s", err))
+				return params, internal.Operation{}, apiresponses.NewFailureResponse(fmt.Errorf(FailedToValidateZonesMsg), http.StatusBadRequest, FailedToValidateZonesMsg)
+			}
+
+			for machineType := range discoveredZones {
+				zonesCount, err := hyperscalerClient.AvailableZonesCount(ctx, machineType)
+				if err != nil {
+					logger.Error(fmt.Sprintf("unable to get available zones: %s", err))
+					return params, internal.Operation{}, apiresponses.NewFailureResponse(fmt.Errorf(FailedToValidateZonesMsg), http.StatusBadRequest, FailedToValidateZonesMsg)
+				}
+				discoveredZones[machineType] = zonesCount
 			}
-			discoveredZones[machineType] = zonesCount
 		}
 
 		if params.MachineType != nil {
 			if discoveredZones[*params.MachineType] < providerValues.ZonesCount {
 				message := fmt.Sprintf("In the %s, the %s machine type is not available in %v zones.", providerValues.Region, *params.MachineType, providerValues.ZonesCount)
-				return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusUnprocessableEntity, message)
+				return params, internal.Operation{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusUnprocessableEntity, message)
 			}
 		}
 	}
@@ -333,7 +540,7 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 	if params.OIDC.IsProvided() {
 		if err := params.OIDC.Validate(instance.Parameters.Parameters.OIDC); err != nil {
 			logger.Error(fmt.Sprintf("invalid OIDC parameters: %s", err.Error()))
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 		}
 	}
 
@@ -345,36 +552,36 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 
 	if err := operation.ProvisioningParameters.Parameters.AutoScalerParameters.Validate(providerValues.DefaultAutoScalerMin, providerValues.DefaultAutoScalerMax); err != nil {
 		logger.Error(fmt.Sprintf("invalid autoscaler parameters: %s", err.Error()))
-		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 	}
 
 	if params.AdditionalWorkerNodePools != nil {
 		if !supportsAdditionalWorkerNodePools(details.PlanID) {
 			message := fmt.Sprintf("additional worker node pools are not supported for plan ID: %s", details.PlanID)
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusBadRequest, message)
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusBadRequest, message)
 		}
 
 		if !AreNamesUnique(params.AdditionalWorkerNodePools) {
 			message := "names of additional worker node pools must be unique"
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusBadRequest, message)
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(fmt.Errorf("%s", message), http.StatusBadRequest, message)
 		}
 
 		if IsExternalLicenseType(ersContext) {
 			if err := checkGPUMachinesUsage(params.AdditionalWorkerNodePools); err != nil {
-				return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+				return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 			}
 		}
 
 		if err := checkUnsupportedMachines(regionsSupportingMachine, valueOfPtr(instance.Parameters.Parameters.Region), params.AdditionalWorkerNodePools); err != nil {
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 		}
 
 		if err := checkAutoScalerConfiguration(params.AdditionalWorkerNodePools); err != nil {
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 		}
 
 		if err := checkHAZonesUnchanged(instance.Parameters.Parameters.AdditionalWorkerNodePools, params.AdditionalWorkerNodePools); err != nil {
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 		}
 
 		if err := checkAvailableZones(
@@ -386,7 +593,7 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 			b.providerSpec.ZonesDiscovery(pkg.CloudProviderFromString(providerValues.ProviderType)),
 			discoveredZones,
 		); err != nil {
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 		}
 
 		multiError := pkg.MachineTypeMultiError{}
@@ -396,23 +603,37 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 			}
 		}
 		if multiError.IsError() {
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(&multiError, http.StatusBadRequest, multiError.Error())
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(&multiError, http.StatusBadRequest, multiError.Error())
 		}
 	}
 
 	err = validateIngressFiltering(operation.ProvisioningParameters, params.IngressFiltering, b.infrastructureManagerConfig.IngressFilteringPlans, logger)
 	if err != nil {
-		return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
+	if err := b.deferToMaintenanceWindow(instance, params, &operation, logger); err != nil {
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
+	if err := validateLabels(instance.ServicePlanID, params.Labels, b.config.InstanceLabelsPlans); err != nil {
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
+	if err := validateModulesUpdate(instance.ServicePlanID, params.Modules, b.config.ModulesUpdatePlans); err != nil {
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+	}
+
+	if err := validateNetworkingUpdate(instance.ServicePlanID, instance.Provider, instance.Parameters.Parameters.Networking, params.Networking, b.config.NetworkingUpdatePlans); err != nil {
+		return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 	}
 
-	var updateStorage []string
-	oldPlanID := instance.ServicePlanID
 	if details.PlanID != "" && details.PlanID != instance.ServicePlanID {
 		logger.Info(fmt.Sprintf("Plan change requested: %s -> %s", instance.ServicePlanID, details.PlanID))
 		if b.config.EnablePlanUpgrades && b.planSpec.IsUpgradableBetween(PlanNamesMapping[instance.ServicePlanID], PlanNamesMapping[details.PlanID]) {
 			if b.config.CheckQuotaLimit && whitelist.IsNotWhitelisted(ersContext.SubAccountID, b.quotaWhitelist) {
 				if err := validateQuotaLimit(b.instanceStorage, b.quotaClient, ersContext.SubAccountID, details.PlanID, true); err != nil {
-					return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
+					return params, internal.Operation{}, apiresponses.NewFailureResponse(err, http.StatusBadRequest, err.Error())
 				}
 			}
 			logger.Info(fmt.Sprintf("Plan change accepted."))
@@ -421,10 +642,9 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 			instance.Parameters.PlanID = details.PlanID
 			instance.ServicePlanID = details.PlanID
 			instance.ServicePlanName = PlanNamesMapping[details.PlanID]
-			updateStorage = append(updateStorage, planChangeMessage)
 		} else {
 			logger.Info(fmt.Sprintf("Plan change not allowed."))
-			return domain.UpdateServiceSpec{}, apiresponses.NewFailureResponse(
+			return params, internal.Operation{}, apiresponses.NewFailureResponse(
 				fmt.Errorf("plan upgrade from %s (planID: %s) to %s (planID: %s) is not allowed", PlanNamesMapping[instance.ServicePlanID], instance.ServicePlanID, PlanNamesMapping[details.PlanID], details.PlanID),
 				http.StatusBadRequest,
 				fmt.Sprintf("plan upgrade from %s (planID: %s) to %s (planID: %s) is not allowed", PlanNamesMapping[instance.ServicePlanID], instance.ServicePlanID, PlanNamesMapping[details.PlanID], details.PlanID),
@@ -432,87 +652,43 @@ func (b *UpdateEndpoint) processUpdateParameters(ctx context.Context, instance *
 		}
 	}
 	operation.ProviderValues = &providerValues
-	err = b.operationStorage.InsertOperation(operation)
-	if err != nil {
-		return domain.UpdateServiceSpec{}, err
-	}
-
-	if params.OIDC.IsProvided() {
-		if params.OIDC.List != nil || (params.OIDC.OIDCConfigDTO != nil && !params.OIDC.OIDCConfigDTO.IsEmpty()) {
-			instance.Parameters.Parameters.OIDC = params.OIDC
-			updateStorage = append(updateStorage, "OIDC")
-		}
-	}
+	return params, operation, nil
+}
 
-	if params.IngressFiltering != nil {
-		instance.Parameters.Parameters.IngressFiltering = params.IngressFiltering
-		updateStorage = append(updateStorage, "Ingress Filtering")
+// deferToMaintenanceWindow validates the maintenance window and, if requested, defers the update with disruptive changes to the next maintenance window.
+// The update is not deferred when the maintenance window is open.
+func (b *UpdateEndpoint) deferToMaintenanceWindow(instance *internal.Instance, params internal.UpdatingParametersDTO, operation *internal.Operation, logger *slog.Logger) error {
+	if err := validateMaintenanceWindow(instance.ServicePlanID, params.MaintenanceWindow, b.config.MaintenanceWindowPlans); err != nil {
+		return err
 	}
-
-	if len(params.RuntimeAdministrators) != 0 {
-		newAdministrators := make([]string, 0, len(params.RuntimeAdministrators))
-		newAdministrators = append(newAdministrators, params.RuntimeAdministrators...)
-		instance.Parameters.Parameters.RuntimeAdministrators = newAdministrators
-		updateStorage = append(updateStorage, "Runtime Administrators")
+	if params.DeferToMaintenanceWindow == nil || !*params.DeferToMaintenanceWindow {
+		return nil
 	}
-
-	if params.UpdateAutoScaler(&instance.Parameters.Parameters) {
-		updateStorage = append(updateStorage, "Auto Scaler parameters")
+	if !b.config.MaintenanceWindowPlans.Contains(PlanNamesMapping[instance.ServicePlanID]) {
+		return fmt.Errorf(MaintenanceWindowNotSupportedForPlanMsg, PlanNamesMapping[instance.ServicePlanID])
 	}
-	if params.MachineType != nil && *params.MachineType != "" {
-		instance.Parameters.Parameters.MachineType = params.MachineType
+	maintenanceWindow := operation.ProvisioningParameters.Parameters.MaintenanceWindow
+	if maintenanceWindow == nil {
+		return fmt.Errorf("the update cannot be deferred, the instance has no maintenance window")
 	}
-
-	if supportsAdditionalWorkerNodePools(details.PlanID) && params.AdditionalWorkerNodePools != nil {
-		newAdditionalWorkerNodePools := make([]pkg.AdditionalWorkerNodePool, 0, len(params.AdditionalWorkerNodePools))
-		newAdditionalWorkerNodePools = append(newAdditionalWorkerNodePools, params.AdditionalWorkerNodePools...)
-		instance.Parameters.Parameters.AdditionalWorkerNodePools = newAdditionalWorkerNodePools
-		updateStorage = append(updateStorage, "Additional Worker Node Pools")
+	if !params.HasDisruptiveChanges(instance.Parameters.Parameters) {
+		logger.Info("the update has no disruptive changes, it is not deferred")
+		return nil
 	}
 
-	if len(updateStorage) > 0 {
-		if err := wait.PollUntilContextTimeout(context.Background(), 500*time.Millisecond, 2*time.Second, true, func(ctx context.Context) (bool, error) {
-			instance, err = b.instanceStorage.Update(*instance)
-			if err != nil {
-				params := strings.Join(updateStorage, ", ")
-				logger.Warn(fmt.Sprintf("unable to update instance with new %v (%s), retrying", params, err.Error()))
-				return false, nil
-			}
-			return true, nil
-		}); err != nil {
-			response := apiresponses.NewFailureResponse(fmt.Errorf("Update operation failed"), http.StatusInternalServerError, err.Error())
-			return domain.UpdateServiceSpec{}, response
-		}
-
-		if slices.Contains(updateStorage, planChangeMessage) {
-			oldPlan := PlanNamesMapping[oldPlanID]
-			newPlan := PlanNamesMapping[details.PlanID]
-			message := fmt.Sprintf("Plan updated from %s (PlanID: %s) to %s (PlanID: %s).", oldPlan, oldPlanID, newPlan, details.PlanID)
-			if err := b.actionStorage.InsertAction(
-				pkg.PlanUpdateActionType,
-				instance.InstanceID,
-				message,
-				oldPlanID,
-				details.PlanID,
-			); err != nil {
-				logger.Error(fmt.Sprintf("while inserting action %q with message %s for instance ID %s: %v", pkg.PlanUpdateActionType, message, instance.InstanceID, err))
-			}
-		}
+	now := time.Now()
+	if maintenanceWindow.IsOpen(now) {
+		logger.Info("the maintenance window is open, the update is not deferred")
+		return nil
 	}
-	logger.Debug("Adding update operation to the processing queue")
-	b.updatingQueue.Add(operationID)
-
-	return domain.UpdateServiceSpec{
-		IsAsync:       true,
-		DashboardURL:  instance.DashboardURL,
-		OperationData: operation.ID,
-		Metadata: domain.InstanceMetadata{
-			Labels: ResponseLabels(*lastProvisioningOperation, *instance, b.config.URL, b.kcBuilder),
-		},
-	}, nil
+	begin, _ := maintenanceWindow.Next(now)
+	logger.Info(fmt.Sprintf("the update is deferred to the maintenance window starting at %s", begin.Format(time.RFC3339)))
+	operation.DeferredUntil = &begin
+	operation.Description = fmt.Sprintf("Operation deferred to the maintenance window starting at %s", begin.UTC().Format(time.RFC3339))
+	return nil
 }
 
-func (b *UpdateEndpoint) processContext(instance *internal.Instance, details domain.UpdateDetails, lastProvisioningOperation *internal.ProvisioningOperation, logger *slog.Logger) (*internal.Instance, bool, error) {
+func (b *UpdateEndpoint) processContext(ctx context.Context, instance *internal.Instance, details domain.UpdateDetails, lastProvisioningOperation *internal.ProvisioningOperation, logger *slog.Logger) (*internal.Instance, bool, error) {
 	var ersContext internal.ERSContext
 	err := json.Unmarshal(details.RawContext, &ersContext)
 	if err != nil {
@@ -540,7 +716,7 @@ func (b *UpdateEndpoint) processContext(instance *internal.Instance, details dom
 	instance.Parameters.ErsContext = internal.InheritMissingERSContext(instance.Parameters.ErsContext, lastOp.ProvisioningParameters.ErsContext)
 	instance.Parameters.ErsContext = internal.UpdateInstanceERSContext(instance.Parameters.ErsContext, ersContext)
 
-	changed, err := b.contextUpdateHandler.Handle(instance, ersContext)
+	changed, err := b.contextUpdateHandler.Handle(ctx, instance, ersContext)
 	if err != nil {
 		logger.Error(fmt.Sprintf("processing context updated failed: %s", err.Error()))
 		return nil, changed, fmt.Errorf("unable to process the update")
@@ -554,15 +730,14 @@ func (b *UpdateEndpoint) processContext(instance *internal.Instance, details dom
 	needUpdateCustomResources := false
 	if b.subaccountMovementEnabled && (instance.GlobalAccountID != ersContext.GlobalAccountID && ersContext.GlobalAccountID != "") {
 		message := fmt.Sprintf("Subaccount %s moved from Global Account %s to %s.", ersContext.SubAccountID, instance.GlobalAccountID, ersContext.GlobalAccountID)
-		if err := b.actionStorage.InsertAction(
-			pkg.SubaccountMovementActionType,
-			instance.InstanceID,
-			message,
-			instance.GlobalAccountID,
-			ersContext.GlobalAccountID,
-		); err != nil {
-			logger.Error(fmt.Sprintf("while inserting action %q with message %s for instance ID %s: %v", pkg.SubaccountMovementActionType, message, instance.InstanceID, err))
-		}
+		audit.Record(b.actionStorage, pkg.Action{
+			Type:       pkg.SubaccountMovementActionType,
+			InstanceID: instance.InstanceID,
+			Actor:      audit.ActorFromContext(ctx),
+			Message:    message,
+			OldValue:   instance.GlobalAccountID,
+			NewValue:   ersContext.GlobalAccountID,
+		}, logger)
 		if instance.SubscriptionGlobalAccountID == "" {
 			instance.SubscriptionGlobalAccountID = instance.GlobalAccountID
 		}
@@ -642,3 +817,102 @@ func checkHAZonesUnchanged(currentAdditionalWorkerNodePools, newAdditionalWorker
 	message := fmt.Sprintf("HA zones setting is permanent and cannot be changed for additional worker node pools: %s.", strings.Join(poolsWithChangedHAZones, ", "))
 	return fmt.Errorf("%s", message)
 }
+
+func validateModulesUpdate(planID string, modules *pkg.ModulesDTO, plans EnablePlans) error {
+	if modules == nil {
+		return nil
+	}
+	if !plans.Contains(PlanNamesMapping[planID]) {
+		return fmt.Errorf(ModulesUpdateNotSupportedForPlanMsg, PlanNamesMapping[planID])
+	}
+	names := make(map[string]struct{}, len(modules.List))
+	for _, module := range modules.List {
+		if _, found := names[module.Name]; found {
+			return fmt.Errorf("module %s is specified more than once", module.Name)
+		}
+		names[module.Name] = struct{}{}
+	}
+	return nil
+}
+
+// nodesCIDRNotExtendableReasons explains why the nodes CIDR cannot be extended on the provider. The network infrastructure of
+// the cluster is derived from the nodes CIDR and Gardener does not allow changing it. Only on GCP the nodes CIDR is the single
+// workers subnet, which can be extended.
+var nodesCIDRNotExtendableReasons = map[pkg.CloudProvider]string{
+	pkg.AWS:               "on AWS the nodes CIDR determines the VPC CIDR and the subnets of zones, which cannot be changed",
+	pkg.Azure:             "on Azure the nodes CIDR determines the VNet CIDR and the subnets of zones, which cannot be changed",
+	pkg.Alicloud:          "on Alicloud the nodes CIDR determines the VPC CIDR and the subnets of zones, which cannot be changed",
+	pkg.SapConvergedCloud: "on SAP Cloud Infrastructure the nodes CIDR determines the workers subnet, which cannot be changed",
+}
+
+// validateNetworkingUpdate rejects changes which Gardener does not accept: the nodes CIDR can only be extended on GCP to a range
+// containing the current one, which must not overlap the pods, services and seed ranges. The pods and services CIDRs cannot be changed.
+func validateNetworkingUpdate(planID string, provider pkg.CloudProvider, current, requested *pkg.NetworkingDTO, plans EnablePlans) error {
+	if requested == nil {
+		return nil
+	}
+	if !plans.Contains(PlanNamesMapping[planID]) {
+		return fmt.Errorf(NetworkingUpdateNotSupportedForPlanMsg, PlanNamesMapping[planID])
+	}
+	if current == nil {
+		current = &pkg.NetworkingDTO{}
+	}
+	currentNodes, currentPods, currentServices := networking.DefaultNodesCIDR, networking.DefaultPodsCIDR, networking.DefaultServicesCIDR
+	if current.NodesCidr != "" {
+		currentNodes = current.NodesCidr
+	}
+	if current.PodsCidr != nil {
+		currentPods = *current.PodsCidr
+	}
+	if current.ServicesCidr != nil {
+		currentServices = *current.ServicesCidr
+	}
+
+	if requested.PodsCidr != nil && *requested.PodsCidr != currentPods {
+		return fmt.Errorf("pods CIDR cannot be changed, the current pods CIDR is %s", currentPods)
+	}
+	if requested.ServicesCidr != nil && *requested.ServicesCidr != currentServices {
+		return fmt.Errorf("services CIDR cannot be changed, the current services CIDR is %s", currentServices)
+	}
+	if requested.NodesCidr == "" || requested.NodesCidr == currentNodes {
+		return nil
+	}
+	if provider != pkg.GCP {
+		if reason, found := nodesCIDRNotExtendableReasons[provider]; found {
+			return fmt.Errorf("nodes CIDR cannot be extended, %s", reason)
+		}
+		return fmt.Errorf("nodes CIDR cannot be extended for %s provider", provider)
+	}
+
+	nodes, err := validateCidr(requested.NodesCidr)
+	if err != nil {
+		return fmt.Errorf("while parsing nodes CIDR: %w", err)
+	}
+	if ones, _ := nodes.Mask.Size(); ones > maxNodesCIDRSuffix {
+		return fmt.Errorf("the suffix of the node CIDR must not be greater than %d", maxNodesCIDRSuffix)
+	}
+	_, oldNodes, err := net.ParseCIDR(currentNodes)
+	if err != nil {
+		return fmt.Errorf("while parsing current nodes CIDR: %w", err)
+	}
+	oldOnes, _ := oldNodes.Mask.Size()
+	newOnes, _ := nodes.Mask.Size()
+	if newOnes > oldOnes || !nodes.Contains(oldNodes.IP) {
+		return fmt.Errorf("nodes CIDR can only be extended, %s does not contain the current nodes CIDR %s", requested.NodesCidr, currentNodes)
+	}
+
+	var errs error
+	for _, seed := range networking.GardenerSeedCIDRs {
+		_, seedCidr, _ := net.ParseCIDR(seed)
+		if e := validateOverlapping(*nodes, *seedCidr); e != nil {
+			errs = multierror.Append(errs, fmt.Errorf("nodes CIDR must not overlap %s", seed))
+		}
+	}
+	if _, pods, e := net.ParseCIDR(currentPods); e == nil && validateOverlapping(*nodes, *pods) != nil {
+		errs = multierror.Append(errs, fmt.Errorf("nodes CIDR must not overlap pods CIDR %s", currentPods))
+	}
+	if _, services, e := net.ParseCIDR(currentServices); e == nil && validateOverlapping(*nodes, *services) != nil {
+		errs = multierror.Append(errs, fmt.Errorf("nodes CIDR must not overlap services CIDR %s", currentServices))
+	}
+	return errs
+}
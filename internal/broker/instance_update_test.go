@@ -26,6 +26,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/whitelist"
 
 	"github.com/google/uuid"
@@ -35,6 +36,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -54,7 +56,7 @@ type handler struct {
 	ersContext internal.ERSContext
 }
 
-func (h *handler) Handle(inst *internal.Instance, ers internal.ERSContext) (bool, error) {
+func (h *handler) Handle(_ context.Context, inst *internal.Instance, ers internal.ERSContext) (bool, error) {
 	h.Instance = *inst
 	h.ersContext = ers
 	return false, nil
@@ -1369,6 +1371,343 @@ func TestLabelChangeWhenMovingSubaccount(t *testing.T) {
 	})
 }
 
+func TestUpdateInstanceLabels(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		labelsPlans    broker.EnablePlans
+		rawParameters  string
+		expectedError  string
+		expectedLabels map[string]string
+	}{
+		"should replace labels": {
+			labelsPlans:    broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:  `{"labels": {"team": "y", "cost-center": "42"}}`,
+			expectedLabels: map[string]string{"team": "y", "cost-center": "42"},
+		},
+		"should remove all labels": {
+			labelsPlans:    broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:  `{"labels": {}}`,
+			expectedLabels: map[string]string{},
+		},
+		"should not change labels when not provided": {
+			labelsPlans:    broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:  `{"autoScalerMax": 30}`,
+			expectedLabels: map[string]string{"team": "x", "env": "dev"},
+		},
+		"should reject labels for a plan without labels": {
+			labelsPlans:   broker.EnablePlans{broker.AzurePlanName},
+			rawParameters: `{"labels": {"team": "y"}}`,
+			expectedError: "labels are not available for aws plan",
+		},
+		"should reject labels with a reserved prefix": {
+			labelsPlans:   broker.EnablePlans{broker.AWSPlanName},
+			rawParameters: `{"labels": {"kyma-project.io/global-account-id": "other"}}`,
+			expectedError: "invalid labels: label key \"kyma-project.io/global-account-id\": the prefix kyma-project.io is reserved",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			oldLabels := map[string]string{"team": "x", "env": "dev"}
+			instance := fixture.FixInstance(instanceID)
+			instance.ServicePlanID = broker.AWSPlanID
+			instance.Parameters.Parameters.Labels = oldLabels
+			st := storage.NewMemoryStorage()
+			require.NoError(t, st.Instances().Insert(instance))
+			require.NoError(t, st.InstanceLabels().Set(instanceID, oldLabels))
+			require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning01")))
+
+			q := &automock.Queue{}
+			q.On("Add", mock.AnythingOfType("string"))
+
+			kcBuilder := &kcMock.KcBuilder{}
+			kcBuilder.On("GetServerURL", mock.Anything).Return("https://kcp.example.com", nil)
+
+			svc := broker.NewUpdate(broker.Config{InstanceLabelsPlans: tc.labelsPlans}, st, &handler{}, true, true, false, q, broker.PlansConfig{},
+				fixValueProvider(t), fixLogger(), dashboardConfig, kcBuilder, fakeKcpK8sClient, newProviderSpec(t), newPlanSpec(t), imConfigFixture, newSchemaService(t), nil, nil, nil, nil, nil)
+
+			// when
+			_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+				PlanID:        broker.AWSPlanID,
+				RawParameters: json.RawMessage(tc.rawParameters),
+				RawContext:    json.RawMessage("{\"globalaccount_id\":\"globalaccount_id_1\", \"active\":true}"),
+			}, true)
+
+			// then
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			stored, err := st.InstanceLabels().Get(instanceID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedLabels, stored)
+		})
+	}
+}
+
+func TestUpdateInstanceLabels_StoreFailure(t *testing.T) {
+	// given
+	instance := fixture.FixInstance(instanceID)
+	instance.ServicePlanID = broker.AWSPlanID
+	memoryStorage := storage.NewMemoryStorage()
+	require.NoError(t, memoryStorage.Instances().Insert(instance))
+	require.NoError(t, memoryStorage.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning01")))
+	st := failingLabelsStorage{BrokerStorage: memoryStorage}
+
+	q := &automock.Queue{}
+
+	kcBuilder := &kcMock.KcBuilder{}
+	kcBuilder.On("GetServerURL", mock.Anything).Return("https://kcp.example.com", nil)
+
+	svc := broker.NewUpdate(broker.Config{InstanceLabelsPlans: broker.EnablePlans{broker.AWSPlanName}}, st, &handler{}, true, true, false, q, broker.PlansConfig{},
+		fixValueProvider(t), fixLogger(), dashboardConfig, kcBuilder, fakeKcpK8sClient, newProviderSpec(t), newPlanSpec(t), imConfigFixture, newSchemaService(t), nil, nil, nil, nil, nil)
+
+	// when
+	_, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+		PlanID:        broker.AWSPlanID,
+		RawParameters: json.RawMessage(`{"labels": {"team": "y"}}`),
+		RawContext:    json.RawMessage("{\"globalaccount_id\":\"globalaccount_id_1\", \"active\":true}"),
+	}, true)
+
+	// then
+	assert.IsType(t, err, &apiresponses.FailureResponse{}, "Update returned error of unexpected type")
+	apierr := err.(*apiresponses.FailureResponse)
+	assert.Equal(t, http.StatusInternalServerError, apierr.ValidatedStatusCode(nil))
+	operations, err := memoryStorage.Operations().ListOperationsByInstanceID(instanceID)
+	require.NoError(t, err)
+	assert.Len(t, operations, 1)
+	q.AssertNotCalled(t, "Add", mock.Anything)
+}
+
+type failingLabelsStorage struct {
+	storage.BrokerStorage
+}
+
+func (s failingLabelsStorage) InstanceLabels() storage.InstanceLabels {
+	return failingLabels{}
+}
+
+type failingLabels struct {
+	storage.InstanceLabels
+}
+
+func (failingLabels) Set(instanceID string, labels map[string]string) error {
+	return fmt.Errorf("connection refused")
+}
+
+func TestUpdateModules(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		modulesPlans    broker.EnablePlans
+		rawParameters   string
+		expectedError   string
+		expectedModules *pkg.ModulesDTO
+	}{
+		"should replace modules with the custom list": {
+			modulesPlans:  broker.EnablePlans{broker.AWSPlanName},
+			rawParameters: `{"modules": {"list": [{"name": "btp-operator", "channel": "fast"}, {"name": "keda", "customResourcePolicy": "Ignore"}]}}`,
+			expectedModules: &pkg.ModulesDTO{List: []pkg.ModuleDTO{
+				{Name: "btp-operator", Channel: ptr.String("fast")},
+				{Name: "keda", CustomResourcePolicy: ptr.String("Ignore")},
+			}},
+		},
+		"should reset modules to default": {
+			modulesPlans:    broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:   `{"modules": {"default": true}}`,
+			expectedModules: &pkg.ModulesDTO{Default: ptr.Bool(true)},
+		},
+		"should not change modules when not provided": {
+			modulesPlans:    broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:   `{"autoScalerMax": 30}`,
+			expectedModules: &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "istio"}}},
+		},
+		"should reject modules for a plan without modules update": {
+			modulesPlans:  broker.EnablePlans{broker.AzurePlanName},
+			rawParameters: `{"modules": {"default": true}}`,
+			expectedError: "modules cannot be updated for aws plan",
+		},
+		"should reject a module specified more than once": {
+			modulesPlans:  broker.EnablePlans{broker.AWSPlanName},
+			rawParameters: `{"modules": {"list": [{"name": "keda"}, {"name": "keda", "channel": "fast"}]}}`,
+			expectedError: "module keda is specified more than once",
+		},
+		"should reject a not supported channel": {
+			modulesPlans:  broker.EnablePlans{broker.AWSPlanName},
+			rawParameters: `{"modules": {"list": [{"name": "keda", "channel": "experimental"}]}}`,
+			expectedError: "while validating update parameters",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			instance := fixture.FixInstance(instanceID)
+			instance.ServicePlanID = broker.AWSPlanID
+			instance.Parameters.Parameters.Modules = &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "istio"}}}
+			st := storage.NewMemoryStorage()
+			require.NoError(t, st.Instances().Insert(instance))
+			require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning01")))
+
+			q := &automock.Queue{}
+			q.On("Add", mock.AnythingOfType("string"))
+
+			kcBuilder := &kcMock.KcBuilder{}
+			kcBuilder.On("GetServerURL", mock.Anything).Return("https://kcp.example.com", nil)
+
+			cfg := broker.Config{ModulesUpdatePlans: tc.modulesPlans}
+			schemaService := broker.NewSchemaService(newProviderSpec(t), newPlanSpec(t), nil, cfg, broker.EnablePlans{broker.AWSPlanName})
+			svc := broker.NewUpdate(cfg, st, &handler{}, true, true, false, q, broker.PlansConfig{},
+				fixValueProvider(t), fixLogger(), dashboardConfig, kcBuilder, fakeKcpK8sClient, newProviderSpec(t), newPlanSpec(t), imConfigFixture, schemaService, nil, nil, nil, nil, nil)
+
+			// when
+			response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+				PlanID:        broker.AWSPlanID,
+				RawParameters: json.RawMessage(tc.rawParameters),
+				RawContext:    json.RawMessage("{\"globalaccount_id\":\"globalaccount_id_1\", \"active\":true}"),
+			}, true)
+
+			// then
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			// the instance keeps applied modules, the update step stores the new ones after they are set in the Kyma resource
+			stored, err := st.Instances().GetByID(instanceID)
+			require.NoError(t, err)
+			assert.Equal(t, &pkg.ModulesDTO{List: []pkg.ModuleDTO{{Name: "istio"}}}, stored.Parameters.Parameters.Modules)
+
+			operation, err := st.Operations().GetOperationByID(response.OperationData)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedModules, operation.ProvisioningParameters.Parameters.Modules)
+		})
+	}
+}
+
+func TestUpdateNetworking(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		networkingPlans    broker.EnablePlans
+		provider           pkg.CloudProvider
+		rawParameters      string
+		expectedError      string
+		expectedNetworking *pkg.NetworkingDTO
+	}{
+		"should extend nodes CIDR": {
+			networkingPlans:    broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:      `{"networking": {"nodes": "10.250.0.0/15"}}`,
+			expectedNetworking: &pkg.NetworkingDTO{NodesCidr: "10.250.0.0/15", PodsCidr: ptr.String("10.96.0.0/13")},
+		},
+		"should accept the current nodes CIDR": {
+			networkingPlans:    broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:      `{"networking": {"nodes": "10.250.0.0/16", "pods": "10.96.0.0/13", "services": "10.104.0.0/13"}}`,
+			expectedNetworking: &pkg.NetworkingDTO{NodesCidr: "10.250.0.0/16", PodsCidr: ptr.String("10.96.0.0/13")},
+		},
+		"should reject networking for a plan without networking update": {
+			networkingPlans: broker.EnablePlans{broker.AzurePlanName},
+			rawParameters:   `{"networking": {"nodes": "10.250.0.0/15"}}`,
+			expectedError:   "networking cannot be updated for gcp plan",
+		},
+		"should reject extending nodes CIDR on AWS": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			provider:        pkg.AWS,
+			rawParameters:   `{"networking": {"nodes": "10.250.0.0/15"}}`,
+			expectedError:   "nodes CIDR cannot be extended, on AWS the nodes CIDR determines the VPC CIDR and the subnets of zones, which cannot be changed",
+		},
+		"should reject extending nodes CIDR on unknown provider": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			provider:        pkg.UnknownProvider,
+			rawParameters:   `{"networking": {"nodes": "10.250.0.0/15"}}`,
+			expectedError:   "nodes CIDR cannot be extended for unknown provider",
+		},
+		"should accept the current nodes CIDR on AWS": {
+			networkingPlans:    broker.EnablePlans{broker.GCPPlanName},
+			provider:           pkg.AWS,
+			rawParameters:      `{"networking": {"nodes": "10.250.0.0/16"}}`,
+			expectedNetworking: &pkg.NetworkingDTO{NodesCidr: "10.250.0.0/16", PodsCidr: ptr.String("10.96.0.0/13")},
+		},
+		"should reject shrinking nodes CIDR": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.250.0.0/17"}}`,
+			expectedError:   "nodes CIDR can only be extended, 10.250.0.0/17 does not contain the current nodes CIDR 10.250.0.0/16",
+		},
+		"should reject nodes CIDR not containing the current range": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.252.0.0/15"}}`,
+			expectedError:   "nodes CIDR can only be extended, 10.252.0.0/15 does not contain the current nodes CIDR 10.250.0.0/16",
+		},
+		"should reject nodes CIDR overlapping seed CIDRs": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.240.0.0/12"}}`,
+			expectedError:   "nodes CIDR must not overlap 10.243.128.0/17",
+		},
+		"should reject nodes CIDR overlapping pods CIDR": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.0.0.0/8"}}`,
+			expectedError:   "nodes CIDR must not overlap pods CIDR 10.96.0.0/13",
+		},
+		"should reject not canonical nodes CIDR": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.250.0.1/15"}}`,
+			expectedError:   "while parsing nodes CIDR: 10.250.0.1 must be valid canonical CIDR",
+		},
+		"should reject changing pods CIDR": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.250.0.0/16", "pods": "10.64.0.0/13"}}`,
+			expectedError:   "pods CIDR cannot be changed, the current pods CIDR is 10.96.0.0/13",
+		},
+		"should reject changing services CIDR": {
+			networkingPlans: broker.EnablePlans{broker.GCPPlanName},
+			rawParameters:   `{"networking": {"nodes": "10.250.0.0/16", "services": "10.112.0.0/13"}}`,
+			expectedError:   "services CIDR cannot be changed, the current services CIDR is 10.104.0.0/13",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			instance := fixture.FixInstance(instanceID)
+			instance.ServicePlanID = broker.GCPPlanID
+			instance.Provider = pkg.GCP
+			if tc.provider != "" {
+				instance.Provider = tc.provider
+			}
+			instance.Parameters.Parameters.Networking = &pkg.NetworkingDTO{NodesCidr: "10.250.0.0/16", PodsCidr: ptr.String("10.96.0.0/13")}
+			st := storage.NewMemoryStorage()
+			require.NoError(t, st.Instances().Insert(instance))
+			require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning01")))
+
+			q := &automock.Queue{}
+			q.On("Add", mock.AnythingOfType("string"))
+
+			kcBuilder := &kcMock.KcBuilder{}
+			kcBuilder.On("GetServerURL", mock.Anything).Return("https://kcp.example.com", nil)
+
+			cfg := broker.Config{NetworkingUpdatePlans: tc.networkingPlans}
+			schemaService := broker.NewSchemaService(newProviderSpec(t), newPlanSpec(t), nil, cfg, broker.EnablePlans{broker.GCPPlanName})
+			svc := broker.NewUpdate(cfg, st, &handler{}, true, true, false, q, broker.PlansConfig{},
+				fixValueProvider(t), fixLogger(), dashboardConfig, kcBuilder, fakeKcpK8sClient, newProviderSpec(t), newPlanSpec(t), imConfigFixture, schemaService, nil, nil, nil, nil, nil)
+
+			// when
+			response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+				PlanID:        broker.GCPPlanID,
+				RawParameters: json.RawMessage(tc.rawParameters),
+				RawContext:    json.RawMessage("{\"globalaccount_id\":\"globalaccount_id_1\", \"active\":true}"),
+			}, true)
+
+			// then
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			stored, err := st.Instances().GetByID(instanceID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNetworking, stored.Parameters.Parameters.Networking)
+
+			operation, err := st.Operations().GetOperationByID(response.OperationData)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNetworking, operation.ProvisioningParameters.Parameters.Networking)
+		})
+	}
+}
+
 func TestUpdateUnsupportedMachine(t *testing.T) {
 	// given
 	instance := fixture.FixInstance(instanceID)
@@ -2323,7 +2662,7 @@ func TestZonesDiscoveryDuringUpdate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			svc := broker.NewUpdate(broker.Config{}, st, handler, true, true, false, q, broker.PlansConfig{},
 				fixValueProvider(t), fixLogger(), dashboardConfig, kcBuilder, fakeKcpK8sClient, fixture.NewProviderSpecWithZonesDiscovery(t, true), newPlanSpec(t), imConfigFixture, newSchemaService(t), nil, nil,
-				rulesService, fixture.CreateGardenerClient(), fixture.NewFakeAWSClientFactory(tc.zones, tc.awsError))
+				rulesService, fixture.CreateGardenerClient(), fixture.NewFakeHyperscalerClientFactory(tc.zones, tc.awsError))
 
 			// when
 			_, err = svc.Update(context.Background(), instanceID, domain.UpdateDetails{
@@ -2372,6 +2711,9 @@ func createCRDs(t *testing.T) {
 		crdName := fmt.Sprintf("%ss.%s", strings.ToLower(gvk.Kind), gvk.Group)
 		customResourceDefinition.SetName(crdName)
 		err = fakeKcpK8sClient.Create(context.Background(), &customResourceDefinition)
+		if k8serrors.IsAlreadyExists(err) {
+			return
+		}
 		require.NoError(t, err)
 	}
 	createCustomResource(customresources.KymaCr)
@@ -2417,3 +2759,208 @@ func cleanFakeCRs(t *testing.T, runtimeID string) {
 	createCustomResource(t, runtimeID, customresources.GardenerClusterCr)
 	createCustomResource(t, runtimeID, customresources.RuntimeCr)
 }
+
+func TestUpdateEndpoint_PreviewUpdate(t *testing.T) {
+	// given
+	instance := internal.Instance{
+		InstanceID:    instanceID,
+		ServicePlanID: broker.AWSPlanID,
+		Parameters: internal.ProvisioningParameters{
+			PlanID: broker.AWSPlanID,
+			ErsContext: internal.ERSContext{
+				Active: ptr.Bool(true),
+			},
+		},
+	}
+	st := storage.NewMemoryStorage()
+	err := st.Instances().Insert(instance)
+	require.NoError(t, err)
+	provisioning := fixProvisioningOperation("01")
+	provisioning.ProviderValues = &internal.ProviderValues{
+		ProviderType: "aws",
+	}
+	err = st.Operations().InsertProvisioningOperation(provisioning)
+	require.NoError(t, err)
+
+	q := &automock.Queue{}
+	svc := broker.NewUpdate(broker.Config{}, st, &handler{}, true, false, true, q, broker.PlansConfig{},
+		fixValueProvider(t), fixLogger(), dashboardConfig, &kcMock.KcBuilder{},
+		fakeKcpK8sClient, newProviderSpec(t), newPlanSpec(t), imConfigFixture, newSchemaService(t), nil, nil, nil, nil, nil)
+
+	t.Run("should return the operation without storing it", func(t *testing.T) {
+		// when
+		operation, gotInstance, err := svc.PreviewUpdate(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:        broker.AWSPlanID,
+			RawParameters: json.RawMessage(`{"autoScalerMin": 4, "autoScalerMax": 5}`),
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, instanceID, gotInstance.InstanceID)
+		assert.Equal(t, 4, *operation.UpdatingParameters.AutoScalerMin)
+		assert.Equal(t, 5, *operation.UpdatingParameters.AutoScalerMax)
+		require.NotNil(t, operation.ProviderValues)
+
+		_, err = st.Operations().GetOperationByID(operation.ID)
+		assert.True(t, dberr.IsNotFound(err))
+		storedInstance, err := st.Instances().GetByID(instanceID)
+		require.NoError(t, err)
+		assert.Nil(t, storedInstance.Parameters.Parameters.AutoScalerMin)
+		q.AssertNotCalled(t, "Add", mock.Anything)
+	})
+
+	t.Run("should return the validation error", func(t *testing.T) {
+		// when
+		_, _, err := svc.PreviewUpdate(context.Background(), instanceID, domain.UpdateDetails{
+			PlanID:        broker.AWSPlanID,
+			RawParameters: json.RawMessage(`{"autoScalerMin": 4, "autoScalerMax": 3}`),
+		})
+
+		// then
+		assert.ErrorContains(t, err, "AutoScalerMax 3 should be larger than AutoScalerMin 4")
+		apierr, ok := err.(*apiresponses.FailureResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, apierr.ValidatedStatusCode(nil))
+	})
+
+	t.Run("should return not found for a missing instance", func(t *testing.T) {
+		// when
+		_, _, err := svc.PreviewUpdate(context.Background(), "not-existing", domain.UpdateDetails{PlanID: broker.AWSPlanID})
+
+		// then
+		apierr, ok := err.(*apiresponses.FailureResponse)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, apierr.ValidatedStatusCode(nil))
+	})
+}
+
+func TestUpdateEndpoint_PreviewUpdateWithZonesDiscovery(t *testing.T) {
+	// given
+	instance := fixture.FixInstance(instanceID)
+	region := "eu-west-2"
+	instance.Parameters.Parameters.Region = &region
+	instance.Provider = pkg.AWS
+	instance.ServicePlanID = broker.AWSPlanID
+	instance.Parameters.PlanID = broker.AWSPlanID
+	st := storage.NewMemoryStorage()
+	require.NoError(t, st.Instances().Insert(instance))
+	require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning01")))
+
+	q := &automock.Queue{}
+	// the hyperscaler client fails every call, the preview must not use it
+	svc := broker.NewUpdate(broker.Config{}, st, &handler{}, true, false, false, q, broker.PlansConfig{},
+		fixValueProvider(t), fixLogger(), dashboardConfig, &kcMock.KcBuilder{}, fakeKcpK8sClient, fixture.NewProviderSpecWithZonesDiscovery(t, true), newPlanSpec(t), imConfigFixture, newSchemaService(t), nil, nil,
+		nil, nil, fixture.NewFakeHyperscalerClientFactory(nil, fmt.Errorf("the hyperscaler API must not be called")))
+
+	// when
+	operation, _, err := svc.PreviewUpdate(context.Background(), instanceID, domain.UpdateDetails{
+		PlanID:        broker.AWSPlanID,
+		RawParameters: json.RawMessage(`{"machineType": "m6i.large", "additionalWorkerNodePools": [{"name": "name-1", "machineType": "g6.xlarge", "haZones": true, "autoScalerMin": 3, "autoScalerMax": 20}]}`),
+		RawContext:    json.RawMessage(`{"globalaccount_id":"globalaccount_id_1", "active":true}`),
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "m6i.large", *operation.UpdatingParameters.MachineType)
+	q.AssertNotCalled(t, "Add", mock.Anything)
+}
+
+func TestUpdateDeferredToMaintenanceWindow(t *testing.T) {
+	// the window starts in three days, so it is never open during the test
+	inThreeDays := time.Now().UTC().AddDate(0, 0, 3)
+	maintenanceWindow := &pkg.MaintenanceWindowDTO{Weekday: strings.ToLower(inThreeDays.Weekday().String()), Start: "00:00", Duration: "2h"}
+	expectedBegin := time.Date(inThreeDays.Year(), inThreeDays.Month(), inThreeDays.Day(), 0, 0, 0, 0, time.UTC)
+	additionalWorkerNodePools := `[{"name": "name-1", "machineType": "m6i.large", "haZones": true, "autoScalerMin": 3, "autoScalerMax": 20}]`
+
+	for tn, tc := range map[string]struct {
+		maintenanceWindowPlans broker.EnablePlans
+		storedWindow           *pkg.MaintenanceWindowDTO
+		rawParameters          string
+		expectedError          string
+		expectedDeferredUntil  *time.Time
+	}{
+		"should defer the update of additional worker node pools": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AWSPlanName},
+			storedWindow:           maintenanceWindow,
+			rawParameters:          `{"deferToMaintenanceWindow": true, "additionalWorkerNodePools": ` + additionalWorkerNodePools + `}`,
+			expectedDeferredUntil:  &expectedBegin,
+		},
+		"should defer the update to the window given in the request": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AWSPlanName},
+			rawParameters: fmt.Sprintf(`{"deferToMaintenanceWindow": true, "maintenanceWindow": {"weekday": %q, "start": "00:00", "duration": "2h"}, "additionalWorkerNodePools": %s}`,
+				maintenanceWindow.Weekday, additionalWorkerNodePools),
+			expectedDeferredUntil: &expectedBegin,
+		},
+		"should not defer the update without disruptive changes": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AWSPlanName},
+			storedWindow:           maintenanceWindow,
+			rawParameters:          `{"deferToMaintenanceWindow": true, "autoScalerMax": 30}`,
+		},
+		"should not defer the update when not requested": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AWSPlanName},
+			storedWindow:           maintenanceWindow,
+			rawParameters:          `{"additionalWorkerNodePools": ` + additionalWorkerNodePools + `}`,
+		},
+		"should reject the deferral when the instance has no maintenance window": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:          `{"deferToMaintenanceWindow": true, "additionalWorkerNodePools": ` + additionalWorkerNodePools + `}`,
+			expectedError:          "the update cannot be deferred, the instance has no maintenance window",
+		},
+		"should reject the maintenance window for a plan without maintenance windows": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AzurePlanName},
+			rawParameters:          `{"maintenanceWindow": {"weekday": "saturday", "start": "22:00", "duration": "2h"}}`,
+			expectedError:          "maintenance window is not available for aws plan",
+		},
+		"should reject an invalid maintenance window": {
+			maintenanceWindowPlans: broker.EnablePlans{broker.AWSPlanName},
+			rawParameters:          `{"maintenanceWindow": {"weekday": "saturday", "start": "22:00", "duration": "12h"}}`,
+			expectedError:          "maintenance window duration 12h0m0s must be between 30m0s and 6h0m0s",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			instance := fixture.FixInstance(instanceID)
+			instance.ServicePlanID = broker.AWSPlanID
+			instance.Parameters.Parameters.MaintenanceWindow = tc.storedWindow
+			st := storage.NewMemoryStorage()
+			require.NoError(t, st.Instances().Insert(instance))
+			require.NoError(t, st.Operations().InsertProvisioningOperation(fixProvisioningOperation("provisioning01")))
+
+			q := &automock.Queue{}
+			q.On("Add", mock.AnythingOfType("string"))
+
+			kcBuilder := &kcMock.KcBuilder{}
+			kcBuilder.On("GetServerURL", mock.Anything).Return("https://kcp.example.com", nil)
+
+			svc := broker.NewUpdate(broker.Config{MaintenanceWindowPlans: tc.maintenanceWindowPlans}, st, &handler{}, true, true, false, q, broker.PlansConfig{},
+				fixValueProvider(t), fixLogger(), dashboardConfig, kcBuilder, fakeKcpK8sClient, newProviderSpec(t), newPlanSpec(t), imConfigFixture, newSchemaService(t), nil, nil, nil, nil, nil)
+
+			// when
+			response, err := svc.Update(context.Background(), instanceID, domain.UpdateDetails{
+				PlanID:        broker.AWSPlanID,
+				RawParameters: json.RawMessage(tc.rawParameters),
+				RawContext:    json.RawMessage("{\"globalaccount_id\":\"globalaccount_id_1\", \"active\":true}"),
+			}, true)
+
+			// then
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			operation, err := st.Operations().GetOperationByID(response.OperationData)
+			require.NoError(t, err)
+			assert.Equal(t, domain.LastOperationState(internal.OperationStatePending), operation.State)
+			if tc.expectedDeferredUntil == nil {
+				assert.Nil(t, operation.DeferredUntil)
+				return
+			}
+			require.NotNil(t, operation.DeferredUntil)
+			assert.True(t, tc.expectedDeferredUntil.Equal(*operation.DeferredUntil), "expected %s, got %s", tc.expectedDeferredUntil, operation.DeferredUntil)
+
+			updatedInstance, err := st.Instances().GetByID(instanceID)
+			require.NoError(t, err)
+			assert.Equal(t, maintenanceWindow.Weekday, updatedInstance.Parameters.Parameters.MaintenanceWindow.Weekday)
+		})
+	}
+}
@@ -34,15 +34,24 @@ func NewLabeler(kcpClient client.Client) *Labeler {
 }
 
 func (l *Labeler) UpdateLabels(id, newGlobalAccountId string) error {
-	kymaErr := l.updateCrLabel(id, customresources.KymaCr, newGlobalAccountId)
-	gardenerClusterErr := l.updateCrLabel(id, customresources.GardenerClusterCr, newGlobalAccountId)
-	runtimeErr := l.updateCrLabel(id, customresources.RuntimeCr, newGlobalAccountId)
+	return l.updateCrsLabels(id, map[string]string{customresources.GlobalAccountIdLabel: newGlobalAccountId}, nil)
+}
+
+// UpdateInstanceLabels sets labels of the instance and removes labels which are no longer set by the user
+func (l *Labeler) UpdateInstanceLabels(id string, labels map[string]string, removed []string) error {
+	return l.updateCrsLabels(id, labels, removed)
+}
+
+func (l *Labeler) updateCrsLabels(id string, labels map[string]string, removed []string) error {
+	kymaErr := l.updateCrLabels(id, customresources.KymaCr, labels, removed)
+	gardenerClusterErr := l.updateCrLabels(id, customresources.GardenerClusterCr, labels, removed)
+	runtimeErr := l.updateCrLabels(id, customresources.RuntimeCr, labels, removed)
 	err := errors.Join(kymaErr, gardenerClusterErr, runtimeErr)
 	return err
 }
 
-func (l *Labeler) updateCrLabel(id, crName, newGlobalAccountId string) error {
-	l.log.Info(fmt.Sprintf("update label starting for runtime %s for %s cr with new value %s", id, crName, newGlobalAccountId))
+func (l *Labeler) updateCrLabels(id, crName string, labels map[string]string, removed []string) error {
+	l.log.Info(fmt.Sprintf("update labels starting for runtime %s for %s cr with new values %v and removed keys %v", id, crName, labels, removed))
 	gvk, err := customresources.GvkByName(crName)
 	if err != nil {
 		return fmt.Errorf("while getting gvk for name: %s: %s", crName, err.Error())
@@ -64,10 +73,17 @@ func (l *Labeler) updateCrLabel(id, crName, newGlobalAccountId string) error {
 		return fmt.Errorf("while getting k8s object of type %s from kcp cluster for instance %s, due to: %s", crName, id, err.Error())
 	}
 
-	err = addOrOverrideLabel(&k8sObject, customresources.GlobalAccountIdLabel, newGlobalAccountId)
-	if err != nil {
-		return fmt.Errorf("while adding or overriding label (newGlobalAccountId=%s) for k8s object %s %s, because: %s", newGlobalAccountId, id, crName, err.Error())
+	for key, value := range labels {
+		err = addOrOverrideLabel(&k8sObject, key, value)
+		if err != nil {
+			return fmt.Errorf("while adding or overriding label (%s=%s) for k8s object %s %s, because: %s", key, value, id, crName, err.Error())
+		}
+	}
+	objectLabels := k8sObject.GetLabels()
+	for _, key := range removed {
+		delete(objectLabels, key)
 	}
+	k8sObject.SetLabels(objectLabels)
 
 	err = l.kcpClient.Update(context.Background(), &k8sObject)
 	if err != nil {
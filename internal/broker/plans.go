@@ -1,11 +1,14 @@
 package broker
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pivotal-cf/brokerapi/v12/domain"
 
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 )
 
 type PlanID string
@@ -72,15 +75,129 @@ var PlanIDsMapping = map[string]string{
 	AlicloudPlanName:          AlicloudPlanID,
 }
 
+// PlanTraits describes the behaviour of a plan, built-in plans are defined in planTraits,
+// other plans and overrides are taken from the plans configuration file (see RegisterPlans)
+type PlanTraits struct {
+	Provider                  pkg.CloudProvider
+	Trial                     bool
+	Free                      bool
+	OwnCluster                bool
+	Bindable                  bool
+	AdditionalWorkerNodePools bool
+	// Custom is true for plans defined only in the plans configuration file
+	Custom bool
+}
+
+// planTraits is indexed by plan ID
+var planTraits = map[string]PlanTraits{
+	GCPPlanID:               {Provider: pkg.GCP, AdditionalWorkerNodePools: true},
+	AWSPlanID:               {Provider: pkg.AWS, AdditionalWorkerNodePools: true},
+	AzurePlanID:             {Provider: pkg.Azure, AdditionalWorkerNodePools: true},
+	AzureLitePlanID:         {Provider: pkg.Azure, AdditionalWorkerNodePools: true},
+	TrialPlanID:             {Trial: true},
+	SapConvergedCloudPlanID: {Provider: pkg.SapConvergedCloud, AdditionalWorkerNodePools: true},
+	FreemiumPlanID:          {Free: true},
+	OwnClusterPlanID:        {OwnCluster: true, AdditionalWorkerNodePools: true},
+	PreviewPlanID:           {Provider: pkg.AWS, AdditionalWorkerNodePools: true},
+	BuildRuntimeAWSPlanID:   {Provider: pkg.AWS, AdditionalWorkerNodePools: true},
+	BuildRuntimeGCPPlanID:   {Provider: pkg.GCP, AdditionalWorkerNodePools: true},
+	BuildRuntimeAzurePlanID: {Provider: pkg.Azure, AdditionalWorkerNodePools: true},
+	AlicloudPlanID:          {Provider: pkg.Alicloud, AdditionalWorkerNodePools: true},
+}
+
+func overrideTrait(trait *bool, value *bool) {
+	if value != nil {
+		*trait = *value
+	}
+}
+
+func valueOrFalse(value *bool) bool {
+	return value != nil && *value
+}
+
+// RegisterPlans adds plans defined in the plans configuration file to the plan mappings and overrides traits of built-in plans.
+// The function must be called at startup, before the configuration of enabled plans and HAP rules is validated.
+func RegisterPlans(definitions []configuration.PlanDefinition) error {
+	for _, definition := range definitions {
+		id, builtIn := PlanIDsMapping[definition.Name]
+		traits := planTraits[id]
+		switch {
+		case builtIn && definition.ID != "" && definition.ID != id:
+			return fmt.Errorf("plan %s: id %s does not match the built-in plan id %s", definition.Name, definition.ID, id)
+		case builtIn && definition.Provider != "" && definition.Provider != traits.Provider:
+			return fmt.Errorf("plan %s: provider %s cannot be changed for the built-in plan", definition.Name, definition.Provider)
+		case !builtIn && definition.ID == "":
+			return fmt.Errorf("plan %s: id is required for a plan which is not built-in", definition.Name)
+		case !builtIn && definition.Provider == "" && !valueOrFalse(definition.OwnCluster):
+			return fmt.Errorf("plan %s: provider is required for a plan which is not built-in", definition.Name)
+		}
+		if name, found := PlanNamesMapping[definition.ID]; !builtIn && found {
+			return fmt.Errorf("plan %s: id %s is already used by the plan %s", definition.Name, definition.ID, name)
+		}
+
+		if !builtIn {
+			id = definition.ID
+			traits = PlanTraits{Provider: definition.Provider, AdditionalWorkerNodePools: !valueOrFalse(definition.Trial) && !valueOrFalse(definition.Free), Custom: true}
+		}
+		// flags not set in the configuration keep the built-in value
+		overrideTrait(&traits.Trial, definition.Trial)
+		overrideTrait(&traits.Free, definition.Free)
+		overrideTrait(&traits.OwnCluster, definition.OwnCluster)
+		overrideTrait(&traits.Bindable, definition.Bindable)
+		overrideTrait(&traits.AdditionalWorkerNodePools, definition.AdditionalWorkerNodePools)
+
+		PlanIDsMapping[definition.Name] = id
+		PlanNamesMapping[id] = definition.Name
+		planTraits[id] = traits
+	}
+
+	for _, definition := range definitions {
+		for _, target := range definition.UpgradableToPlans {
+			if _, found := PlanIDsMapping[target]; !found {
+				return fmt.Errorf("plan %s: unknown plan %s in upgradableToPlans", definition.Name, target)
+			}
+		}
+	}
+	return nil
+}
+
+// Traits returns traits of the plan, the second value is false for an unknown plan
+func Traits(planID string) (PlanTraits, bool) {
+	traits, found := planTraits[planID]
+	return traits, found
+}
+
+// CustomPlanIDs returns IDs of plans defined only in the plans configuration file, sorted by plan name
+func CustomPlanIDs() []string {
+	var ids []string
+	for id, traits := range planTraits {
+		if traits.Custom {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return PlanNamesMapping[ids[i]] < PlanNamesMapping[ids[j]]
+	})
+	return ids
+}
+
 type ControlFlagsObject struct {
 	ingressFilteringEnabled     bool
 	rejectUnsupportedParameters bool
+	maintenanceWindowEnabled    bool
+	labelsEnabled               bool
+	modulesUpdateEnabled        bool
+	networkingUpdateEnabled     bool
 }
 
-func NewControlFlagsObject(ingressFilteringEnabled, rejectUnsupportedParameters bool) ControlFlagsObject {
+func NewControlFlagsObject(ingressFilteringEnabled, rejectUnsupportedParameters, maintenanceWindowEnabled, labelsEnabled, modulesUpdateEnabled, networkingUpdateEnabled bool) ControlFlagsObject {
 	return ControlFlagsObject{
 		ingressFilteringEnabled:     ingressFilteringEnabled,
 		rejectUnsupportedParameters: rejectUnsupportedParameters,
+		maintenanceWindowEnabled:    maintenanceWindowEnabled,
+		labelsEnabled:               labelsEnabled,
+		modulesUpdateEnabled:        modulesUpdateEnabled,
+		networkingUpdateEnabled:     networkingUpdateEnabled,
 	}
 }
 
@@ -146,6 +263,25 @@ func createSchemaWithProperties(properties ProvisioningProperties,
 	if flags.ingressFilteringEnabled {
 		properties.IngressFiltering = IngressFilteringProperty()
 	}
+	if flags.maintenanceWindowEnabled {
+		properties.MaintenanceWindow = NewMaintenanceWindowSchema(flags.rejectUnsupportedParameters)
+		if update {
+			properties.DeferToMaintenanceWindow = DeferToMaintenanceWindowProperty()
+		}
+	}
+	if flags.labelsEnabled {
+		properties.Labels = LabelsProperty()
+	}
+	if update {
+		properties.Modules = nil
+		if flags.modulesUpdateEnabled {
+			properties.Modules = NewModulesSchema(flags.rejectUnsupportedParameters)
+		}
+		properties.Networking = nil
+		if flags.networkingUpdateEnabled {
+			properties.Networking = NewNetworkingUpdateSchema(flags.rejectUnsupportedParameters)
+		}
+	}
 
 	if update {
 		return createSchemaWith(properties.UpdateProperties, []string{}, flags.rejectUnsupportedParameters)
@@ -194,34 +330,23 @@ func defaultMetadata(planName string, plans PlansConfig) *domain.ServicePlanMeta
 }
 
 func IsTrialPlan(planID string) bool {
-	switch planID {
-	case TrialPlanID:
-		return true
-	default:
-		return false
-	}
+	return planTraits[planID].Trial
 }
 
 func IsSapConvergedCloudPlan(planID string) bool {
-	switch planID {
-	case SapConvergedCloudPlanID:
-		return true
-	default:
-		return false
-	}
+	return planTraits[planID].Provider == pkg.SapConvergedCloud
 }
 
 func IsFreemiumPlan(planID string) bool {
-	switch planID {
-	case FreemiumPlanID:
-		return true
-	default:
-		return false
-	}
+	return planTraits[planID].Free
 }
 
 func IsOwnClusterPlan(planID string) bool {
-	return planID == OwnClusterPlanID
+	return planTraits[planID].OwnCluster
+}
+
+func IsBindablePlan(planID string) bool {
+	return planTraits[planID].Bindable
 }
 
 func filter(items *[]interface{}, included map[string]interface{}) interface{} {
@@ -33,13 +33,11 @@ type RootSchema struct {
 type ProvisioningProperties struct {
 	UpdateProperties
 
-	Name                 NameType        `json:"name"`
-	ShootName            *Type           `json:"shootName,omitempty"`
-	ShootDomain          *Type           `json:"shootDomain,omitempty"`
-	Region               *Type           `json:"region,omitempty"`
-	Networking           *NetworkingType `json:"networking,omitempty"`
-	Modules              *Modules        `json:"modules,omitempty"`
-	ColocateControlPlane *Type           `json:"colocateControlPlane,omitempty"`
+	Name                 NameType `json:"name"`
+	ShootName            *Type    `json:"shootName,omitempty"`
+	ShootDomain          *Type    `json:"shootDomain,omitempty"`
+	Region               *Type    `json:"region,omitempty"`
+	ColocateControlPlane *Type    `json:"colocateControlPlane,omitempty"`
 }
 
 type UpdateProperties struct {
@@ -51,12 +49,31 @@ type UpdateProperties struct {
 	MachineType               *Type                          `json:"machineType,omitempty"`
 	AdditionalWorkerNodePools *AdditionalWorkerNodePoolsType `json:"additionalWorkerNodePools,omitempty"`
 	IngressFiltering          *Type                          `json:"ingressFiltering,omitempty"`
+	MaintenanceWindow         *MaintenanceWindowType         `json:"maintenanceWindow,omitempty"`
+	DeferToMaintenanceWindow  *Type                          `json:"deferToMaintenanceWindow,omitempty"`
+	Labels                    *Type                          `json:"labels,omitempty"`
+	Modules                   *Modules                       `json:"modules,omitempty"`
+	Networking                *NetworkingType                `json:"networking,omitempty"`
+}
+
+type MaintenanceWindowType struct {
+	Type
+	ControlsOrder []string                    `json:"_controlsOrder,omitempty"`
+	Required      []string                    `json:"required,omitempty"`
+	Properties    MaintenanceWindowProperties `json:"properties"`
+}
+
+type MaintenanceWindowProperties struct {
+	Weekday  Type `json:"weekday"`
+	Start    Type `json:"start"`
+	Duration Type `json:"duration"`
+	TimeZone Type `json:"timeZone"`
 }
 
 type NetworkingProperties struct {
-	Nodes    Type `json:"nodes"`
-	Services Type `json:"services"`
-	Pods     Type `json:"pods"`
+	Nodes    Type  `json:"nodes"`
+	Services *Type `json:"services,omitempty"`
+	Pods     *Type `json:"pods,omitempty"`
 }
 
 type NetworkingType struct {
@@ -583,6 +600,68 @@ func IngressFilteringProperty() *Type {
 	}
 }
 
+func NewMaintenanceWindowSchema(rejectUnsupportedParameters bool) *MaintenanceWindowType {
+	maintenanceWindow := &MaintenanceWindowType{
+		Type: Type{
+			Type:        "object",
+			Title:       "Maintenance window",
+			Description: "Specifies the weekly time window in which the cluster maintenance and deferred updates are performed.",
+		},
+		ControlsOrder: []string{"weekday", "start", "duration", "timeZone"},
+		Required:      []string{"weekday", "start", "duration"},
+		Properties: MaintenanceWindowProperties{
+			Weekday: Type{
+				Type:  "string",
+				Title: "Weekday",
+				Enum:  ToInterfaceSlice(pkg.MaintenanceWindowWeekdays()),
+			},
+			Start: Type{
+				Type:        "string",
+				Title:       "Start",
+				Description: "Specifies the start time of the maintenance window in the HH:MM format.",
+				Pattern:     "^([01][0-9]|2[0-3]):[0-5][0-9]$",
+			},
+			Duration: Type{
+				Type:        "string",
+				Title:       "Duration",
+				Description: "Specifies the duration of the maintenance window, for example, 2h or 90m. The duration must be between 30m and 6h.",
+				Pattern:     "^([0-9]+h)?([0-9]+m)?$",
+				MinLength:   2,
+			},
+			TimeZone: Type{
+				Type:        "string",
+				Title:       "Time zone",
+				Description: "Specifies the IANA time zone name of the maintenance window, for example, Europe/Berlin. If not set, UTC is used.",
+			},
+		},
+	}
+	if rejectUnsupportedParameters {
+		maintenanceWindow.Type.AdditionalProperties = false
+	}
+	return maintenanceWindow
+}
+
+func DeferToMaintenanceWindowProperty() *Type {
+	return &Type{
+		Type:        "boolean",
+		Title:       "Defer to maintenance window",
+		Default:     false,
+		Description: "If set to true, an update that changes the machine type or additional worker node pools is applied in the next maintenance window.",
+	}
+}
+
+func LabelsProperty() *Type {
+	return &Type{
+		Type:        "object",
+		Title:       "Labels",
+		Description: "Specifies labels of the instance, which are added to the Kyma runtime resources. An update replaces all labels.",
+		AdditionalProperties: &Type{
+			Type:      "string",
+			MaxLength: 63,
+		},
+	}
+}
+
 // NewProvisioningProperties creates a new properties for different plans
 // Note that the order of properties will be the same in the form on the website
 func NewProvisioningProperties(machineTypesDisplay, additionalMachineTypesDisplay, regionsDisplay map[string]string, machineTypes, additionalMachineTypes, regions []string, update, rejectUnsupportedParameters bool) ProvisioningProperties {
@@ -610,6 +689,8 @@ func NewProvisioningProperties(machineTypesDisplay, additionalMachineTypesDispla
 				Description:     "Specifies the type of the virtual machine.",
 			},
 			AdditionalWorkerNodePools: NewAdditionalWorkerNodePoolsSchema(additionalMachineTypesDisplay, additionalMachineTypes, rejectUnsupportedParameters),
+			Modules:                   NewModulesSchema(rejectUnsupportedParameters),
+			Networking:                NewNetworkingSchema(rejectUnsupportedParameters),
 		},
 		Name: NameProperty(),
 		Region: &Type{
@@ -618,8 +699,6 @@ func NewProvisioningProperties(machineTypesDisplay, additionalMachineTypesDispla
 			EnumDisplayName: regionsDisplay,
 			MinLength:       1,
 		},
-		Networking:           NewNetworkingSchema(rejectUnsupportedParameters),
-		Modules:              NewModulesSchema(rejectUnsupportedParameters),
 		ColocateControlPlane: ColocateControlPlaneProperty(),
 	}
 
@@ -636,9 +715,9 @@ func NewNetworkingSchema(rejectUnsupportedParameters bool) *NetworkingType {
 	networkingType := &NetworkingType{
 		Type: Type{Type: "object", Description: "Networking configuration. These values are immutable and cannot be updated later. All provided CIDR ranges must not overlap one another."},
 		Properties: NetworkingProperties{
-			Services: Type{Type: "string", Title: "CIDR range for Services", Description: fmt.Sprintf("CIDR range for Services, must not overlap with the following CIDRs: %s", seedCIDRs),
+			Services: &Type{Type: "string", Title: "CIDR range for Services", Description: fmt.Sprintf("CIDR range for Services, must not overlap with the following CIDRs: %s", seedCIDRs),
 				Default: networking.DefaultServicesCIDR},
-			Pods: Type{Type: "string", Title: "CIDR range for Pods", Description: fmt.Sprintf("CIDR range for Pods, must not overlap with the following CIDRs: %s", seedCIDRs),
+			Pods: &Type{Type: "string", Title: "CIDR range for Pods", Description: fmt.Sprintf("CIDR range for Pods, must not overlap with the following CIDRs: %s", seedCIDRs),
 				Default: networking.DefaultPodsCIDR},
 			Nodes: Type{Type: "string", Title: "CIDR range for Nodes", Description: fmt.Sprintf("CIDR range for Nodes, must not overlap with the following CIDRs: %s", seedCIDRs),
 				Default: networking.DefaultNodesCIDR},
@@ -651,6 +730,22 @@ func NewNetworkingSchema(rejectUnsupportedParameters bool) *NetworkingType {
 	return networkingType
 }
 
+// NewNetworkingUpdateSchema allows only extending the nodes CIDR range, the pods and services CIDR ranges cannot be changed
+func NewNetworkingUpdateSchema(rejectUnsupportedParameters bool) *NetworkingType {
+	seedCIDRs := strings.Join(networking.GardenerSeedCIDRs, ", ")
+	networkingType := &NetworkingType{
+		Type: Type{Type: "object", Description: "Networking configuration. Only the CIDR range for Nodes can be extended, the CIDR ranges for Pods and Services cannot be changed."},
+		Properties: NetworkingProperties{
+			Nodes: Type{Type: "string", Title: "CIDR range for Nodes", Description: fmt.Sprintf("CIDR range for Nodes, must contain the current range and must not overlap with the CIDR ranges for Pods, Services and the following CIDRs: %s", seedCIDRs)},
+		},
+		Required: []string{"nodes"},
+	}
+	if rejectUnsupportedParameters {
+		networkingType.Type.AdditionalProperties = false
+	}
+	return networkingType
+}
+
 func NewSchema(properties interface{}, required []string, rejectUnsupportedParameters bool) *RootSchema {
 	rootSchema := &RootSchema{
 		Schema: "http://json-schema.org/draft-04/schema#",
@@ -680,7 +775,7 @@ func unmarshalOrPanic(from, to interface{}) interface{} {
 }
 
 func DefaultControlsOrder() []string {
-	return []string{"name", "kubeconfig", "shootName", "shootDomain", "region", "colocateControlPlane", "machineType", "autoScalerMin", "autoScalerMax", "zonesCount", "additionalWorkerNodePools", "modules", "networking", "oidc", "administrators", "ingressFiltering"}
+	return []string{"name", "kubeconfig", "shootName", "shootDomain", "region", "colocateControlPlane", "machineType", "autoScalerMin", "autoScalerMax", "zonesCount", "additionalWorkerNodePools", "modules", "networking", "oidc", "administrators", "ingressFiltering", "maintenanceWindow", "deferToMaintenanceWindow", "labels"}
 }
 
 func ToInterfaceSlice(input []string) []interface{} {
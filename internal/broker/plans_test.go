@@ -3,11 +3,13 @@ package broker
 import (
 	"bytes"
 	"encoding/json"
+	"maps"
 	"os"
 	"path"
 	"testing"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/stretchr/testify/assert"
@@ -147,6 +149,100 @@ func TestRemoveString(t *testing.T) {
 	}
 }
 
+func TestRegisterPlans(t *testing.T) {
+	t.Run("should register a new plan and override traits of a built-in plan", func(t *testing.T) {
+		// given
+		restorePlans(t)
+		definitions := []configuration.PlanDefinition{
+			{Name: AWSPlanName, Bindable: ptr.Bool(true)},
+			{Name: OwnClusterPlanName, AdditionalWorkerNodePools: ptr.Bool(false)},
+			{ID: "6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5", Name: "gcp-small", Provider: pkg.GCP, AdditionalWorkerNodePools: ptr.Bool(false), UpgradableToPlans: []string{GCPPlanName}},
+			{ID: "0f3a4c1e-6c5c-4a0e-9c1b-2f9c5f3f7e21", Name: "free-gcp", Provider: pkg.GCP, Free: ptr.Bool(true)},
+		}
+
+		// when
+		err := RegisterPlans(definitions)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5", PlanIDsMapping["gcp-small"])
+		assert.Equal(t, "gcp-small", PlanNamesMapping["6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5"])
+		assert.True(t, IsBindablePlan(AWSPlanID))
+		assert.False(t, supportsAdditionalWorkerNodePools(OwnClusterPlanID))
+		assert.False(t, supportsAdditionalWorkerNodePools("6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5"))
+		assert.True(t, IsFreemiumPlan("0f3a4c1e-6c5c-4a0e-9c1b-2f9c5f3f7e21"))
+		assert.False(t, supportsAdditionalWorkerNodePools("0f3a4c1e-6c5c-4a0e-9c1b-2f9c5f3f7e21"))
+		assert.Equal(t, []string{"0f3a4c1e-6c5c-4a0e-9c1b-2f9c5f3f7e21", "6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5"}, CustomPlanIDs())
+	})
+
+	t.Run("should turn off a trait of a plan", func(t *testing.T) {
+		// given
+		restorePlans(t)
+		require.NoError(t, RegisterPlans([]configuration.PlanDefinition{{Name: AWSPlanName, Bindable: ptr.Bool(true)}}))
+
+		// when
+		err := RegisterPlans([]configuration.PlanDefinition{{Name: AWSPlanName, Bindable: ptr.Bool(false)}})
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, IsBindablePlan(AWSPlanID))
+		assert.True(t, supportsAdditionalWorkerNodePools(AWSPlanID))
+	})
+
+	for tn, tc := range map[string]struct {
+		definition    configuration.PlanDefinition
+		expectedError string
+	}{
+		"built-in plan with a different id": {
+			definition:    configuration.PlanDefinition{ID: "6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5", Name: AWSPlanName},
+			expectedError: "does not match the built-in plan id",
+		},
+		"built-in plan with a different provider": {
+			definition:    configuration.PlanDefinition{Name: AWSPlanName, Provider: pkg.GCP},
+			expectedError: "cannot be changed for the built-in plan",
+		},
+		"new plan without id": {
+			definition:    configuration.PlanDefinition{Name: "new-plan", Provider: pkg.GCP},
+			expectedError: "id is required",
+		},
+		"new plan without provider": {
+			definition:    configuration.PlanDefinition{ID: "6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5", Name: "new-plan"},
+			expectedError: "provider is required",
+		},
+		"new plan with id of a built-in plan": {
+			definition:    configuration.PlanDefinition{ID: AWSPlanID, Name: "new-plan", Provider: pkg.AWS},
+			expectedError: "is already used by the plan aws",
+		},
+		"unknown upgrade target": {
+			definition:    configuration.PlanDefinition{Name: AWSPlanName, Bindable: ptr.Bool(true), UpgradableToPlans: []string{"not-existing"}},
+			expectedError: "unknown plan not-existing in upgradableToPlans",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			restorePlans(t)
+
+			// when
+			err := RegisterPlans([]configuration.PlanDefinition{tc.definition})
+
+			// then
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+// restorePlans restores global plan mappings modified by RegisterPlans when the test finishes
+func restorePlans(t *testing.T) {
+	names := maps.Clone(PlanNamesMapping)
+	ids := maps.Clone(PlanIDsMapping)
+	traits := maps.Clone(planTraits)
+	t.Cleanup(func() {
+		PlanNamesMapping = names
+		PlanIDsMapping = ids
+		planTraits = traits
+	})
+}
+
 func createSchemaService(t *testing.T) *SchemaService {
 	plans, err := configuration.NewPlanSpecificationsFromFile("testdata/plans.yaml")
 	require.NoError(t, err)
@@ -41,7 +41,11 @@ func (s *SchemaService) Validate() error {
 		case AlicloudPlanName:
 			provider = pkg.Alicloud
 		default:
-			continue
+			traits, found := Traits(PlanIDsMapping[planName])
+			if !found || !traits.Custom || traits.Provider == "" {
+				continue
+			}
+			provider = traits.Provider
 		}
 		for _, region := range regions {
 			err := s.providerSpec.Validate(provider, region)
@@ -99,6 +103,18 @@ func (s *SchemaService) Plans(plans PlansConfig, platformRegion string, cp pkg.C
 	ownClusterUpdateSchema := s.OwnClusterSchema(true)
 	outputPlans[OwnClusterPlanID] = s.defaultServicePlan(OwnClusterPlanID, OwnClusterPlanName, plans, ownClusterCreateSchema, ownClusterUpdateSchema)
 
+	for _, planID := range CustomPlanIDs() {
+		planName := PlanNamesMapping[planID]
+		traits, _ := Traits(planID)
+		if traits.OwnCluster {
+			outputPlans[planID] = s.defaultServicePlan(planID, planName, plans, s.OwnClusterSchema(false), s.OwnClusterSchema(true))
+			continue
+		}
+		if createSchema, updateSchema, available := s.planSchemas(traits.Provider, planName, platformRegion); available {
+			outputPlans[planID] = s.defaultServicePlan(planID, planName, plans, createSchema, updateSchema)
+		}
+	}
+
 	return outputPlans
 }
 
@@ -313,6 +329,9 @@ func (s *SchemaService) OwnClusterSchema(update bool) *map[string]interface{} {
 	}
 
 	if update {
+		if s.cfg.ModulesUpdatePlans.Contains(OwnClusterPlanName) {
+			properties.Modules = NewModulesSchema(s.cfg.RejectUnsupportedParameters)
+		}
 		return createSchemaWith(properties.UpdateProperties, []string{}, s.cfg.RejectUnsupportedParameters)
 	} else {
 		properties.Modules = NewModulesSchema(s.cfg.RejectUnsupportedParameters)
@@ -324,6 +343,10 @@ func (s *SchemaService) createFlags(planName string) ControlFlagsObject {
 	return NewControlFlagsObject(
 		s.ingressFilteringPlans.Contains(planName),
 		s.cfg.RejectUnsupportedParameters,
+		s.cfg.MaintenanceWindowPlans.Contains(planName),
+		s.cfg.InstanceLabelsPlans.Contains(planName),
+		s.cfg.ModulesUpdatePlans.Contains(planName),
+		s.cfg.NetworkingUpdatePlans.Contains(planName),
 	)
 }
 
@@ -70,7 +70,7 @@ func (se *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, err
 			continue
 		}
 
-		if se.cfg.Binding.Enabled && se.cfg.Binding.BindablePlans.Contains(plan.Name) {
+		if se.cfg.Binding.Enabled && (se.cfg.Binding.BindablePlans.Contains(plan.Name) || IsBindablePlan(plan.ID)) {
 			plan.Bindable = &bindable
 		}
 		availableServicePlans = append(availableServicePlans, plan)
@@ -87,6 +87,7 @@ func (se *ServicesEndpoint) Services(ctx context.Context) ([]domain.Service, err
 			Description:          class.Description,
 			Bindable:             false,
 			InstancesRetrievable: true,
+			BindingsRetrievable:  se.cfg.Binding.Enabled && se.cfg.Binding.AsyncEnabled,
 			Tags: []string{
 				"SAP",
 				"Kyma",
@@ -0,0 +1,369 @@
+package campaign
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/google/uuid"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler struct {
+	campaigns      storage.Campaigns
+	instances      storage.Instances
+	defaultMaxPage int
+	log            *slog.Logger
+}
+
+func NewHandler(db storage.BrokerStorage, defaultMaxPage int, log *slog.Logger) *Handler {
+	return &Handler{
+		campaigns:      db.Campaigns(),
+		instances:      db.Instances(),
+		defaultMaxPage: defaultMaxPage,
+		log:            log.With("service", "CampaignsEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	r.HandleFunc("POST /campaigns", h.createCampaign)
+	r.HandleFunc("GET /campaigns", h.listCampaigns)
+	r.HandleFunc("GET /campaigns/{campaign_id}", h.getCampaign)
+	r.HandleFunc("GET /campaigns/{campaign_id}/instances", h.listInstances)
+	r.HandleFunc("POST /campaigns/{campaign_id}/pause", h.pauseCampaign)
+	r.HandleFunc("POST /campaigns/{campaign_id}/resume", h.resumeCampaign)
+	r.HandleFunc("POST /campaigns/{campaign_id}/retry", h.retryCampaign)
+	r.HandleFunc("POST /campaigns/{campaign_id}/cancel", h.cancelCampaign)
+}
+
+func (h *Handler) createCampaign(w http.ResponseWriter, req *http.Request) {
+	var request campaigns.CreateRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log.Warn(fmt.Sprintf("unable to decode request body: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	if err := validateCampaign(request); err != nil {
+		h.log.Warn(fmt.Sprintf("invalid campaign: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	instances, _, _, err := h.instances.List(dbmodel.InstanceFilter{
+		GlobalAccountIDs: request.Target.GlobalAccountIDs,
+		SubAccountIDs:    request.Target.SubAccountIDs,
+		InstanceIDs:      request.Target.InstanceIDs,
+		RuntimeIDs:       request.Target.RuntimeIDs,
+		Regions:          request.Target.Regions,
+		Plans:            request.Target.Plans,
+		Shoots:           request.Target.Shoots,
+		States:           []dbmodel.InstanceState{dbmodel.InstanceNotDeprovisioned},
+	})
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to list instances: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(instances) == 0 {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.New("no instances match the target"))
+		return
+	}
+
+	now := time.Now().UTC()
+	campaign := internal.Campaign{
+		ID:          uuid.NewString(),
+		Description: request.Description,
+		State:       campaigns.StatePending,
+		Target:      request.Target,
+		Parameters:  request.Parameters,
+		Strategy:    request.Strategy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	campaignInstances := make([]internal.CampaignInstance, 0, len(instances))
+	for i, instance := range instances {
+		wave := waveOf(i, request.Strategy)
+		campaign.Waves = max(campaign.Waves, wave+1)
+		campaignInstances = append(campaignInstances, internal.CampaignInstance{
+			CampaignID: campaign.ID,
+			InstanceID: instance.InstanceID,
+			Wave:       wave,
+			State:      campaigns.InstancePending,
+			UpdatedAt:  now,
+		})
+		campaign.Progress.Add(campaigns.InstancePending)
+	}
+	if err := h.campaigns.Insert(campaign, campaignInstances); err != nil {
+		h.log.Error(fmt.Sprintf("unable to store campaign: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info(fmt.Sprintf("campaign %s created for %d instances in %d waves", campaign.ID, len(campaignInstances), campaign.Waves))
+	httputil.WriteResponse(w, http.StatusCreated, toCampaignDTO(campaign))
+}
+
+func (h *Handler) listCampaigns(w http.ResponseWriter, req *http.Request) {
+	pageSize, pageNumber, err := pagination.ExtractPaginationConfigFromRequest(req, h.defaultMaxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+	list, totalCount, err := h.campaigns.List(dbmodel.CampaignFilter{
+		Page:     pageNumber,
+		PageSize: pageSize,
+		State:    req.URL.Query().Get(pkg.StateParam),
+	})
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to list campaigns: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := campaigns.CampaignsPage{
+		Data:       make([]campaigns.CampaignDTO, 0, len(list)),
+		Count:      len(list),
+		TotalCount: totalCount,
+	}
+	for _, campaign := range list {
+		page.Data = append(page.Data, toCampaignDTO(campaign))
+	}
+	httputil.WriteResponse(w, http.StatusOK, page)
+}
+
+func (h *Handler) getCampaign(w http.ResponseWriter, req *http.Request) {
+	campaign, ok := h.campaign(w, req)
+	if !ok {
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, toCampaignDTO(*campaign))
+}
+
+func (h *Handler) listInstances(w http.ResponseWriter, req *http.Request) {
+	campaign, ok := h.campaign(w, req)
+	if !ok {
+		return
+	}
+	instances, err := h.campaigns.ListInstances(campaign.ID, campaigns.InstanceState(req.URL.Query().Get(pkg.StateParam)))
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to list instances of campaign %s: %s", campaign.ID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := campaigns.InstancesPage{Data: make([]campaigns.InstanceDTO, 0, len(instances))}
+	for _, instance := range instances {
+		page.Data = append(page.Data, campaigns.InstanceDTO{
+			InstanceID:  instance.InstanceID,
+			Wave:        instance.Wave,
+			State:       instance.State,
+			OperationID: instance.OperationID,
+			Message:     instance.Message,
+			UpdatedAt:   instance.UpdatedAt,
+		})
+	}
+	page.Count = len(page.Data)
+	page.TotalCount = len(page.Data)
+	httputil.WriteResponse(w, http.StatusOK, page)
+}
+
+// pauseCampaign stops starting new updates, update operations already started are not stopped
+func (h *Handler) pauseCampaign(w http.ResponseWriter, req *http.Request) {
+	h.changeState(w, req, func(campaign *internal.Campaign) error {
+		if campaign.State != campaigns.StatePending && campaign.State != campaigns.StateInProgress {
+			return fmt.Errorf("%w: campaign in the %s state cannot be paused", errInvalidState, campaign.State)
+		}
+		campaign.State = campaigns.StatePaused
+		campaign.Message = "Paused by the operator"
+		return nil
+	})
+}
+
+// resumeCampaign continues the paused campaign, failed updates are accepted and do not count to the failure threshold anymore
+func (h *Handler) resumeCampaign(w http.ResponseWriter, req *http.Request) {
+	h.changeState(w, req, func(campaign *internal.Campaign) error {
+		if campaign.State != campaigns.StatePaused {
+			return fmt.Errorf("%w: campaign in the %s state cannot be resumed", errInvalidState, campaign.State)
+		}
+		campaign.State = campaigns.StateInProgress
+		campaign.Message = ""
+		campaign.AcceptedFailures = campaign.Progress.Failed
+		return nil
+	})
+}
+
+// retryCampaign sends the update requests of failed instances again
+func (h *Handler) retryCampaign(w http.ResponseWriter, req *http.Request) {
+	h.changeState(w, req, func(campaign *internal.Campaign) error {
+		if campaign.State != campaigns.StatePaused && campaign.State != campaigns.StateFailed {
+			return fmt.Errorf("%w: campaign in the %s state cannot be retried", errInvalidState, campaign.State)
+		}
+		failed, err := h.campaigns.ListInstances(campaign.ID, campaigns.InstanceFailed)
+		if err != nil {
+			return err
+		}
+		for _, instance := range failed {
+			instance.State = campaigns.InstancePending
+			instance.OperationID = ""
+			instance.Message = "Retried by the operator"
+			instance.UpdatedAt = time.Now()
+			if err := h.campaigns.UpdateInstance(instance); err != nil {
+				return err
+			}
+			// the instance is updated in the current wave
+			campaign.CurrentWave = min(campaign.CurrentWave, instance.Wave)
+		}
+		campaign.State = campaigns.StateInProgress
+		campaign.Message = fmt.Sprintf("Retrying %d failed updates", len(failed))
+		campaign.AcceptedFailures = 0
+		campaign.Progress.Pending += len(failed)
+		campaign.Progress.Failed -= len(failed)
+		return nil
+	})
+}
+
+// cancelCampaign stops the campaign, instances which were not updated yet are not updated anymore
+func (h *Handler) cancelCampaign(w http.ResponseWriter, req *http.Request) {
+	h.changeState(w, req, func(campaign *internal.Campaign) error {
+		if campaign.State.IsFinished() {
+			return fmt.Errorf("%w: campaign in the %s state cannot be canceled", errInvalidState, campaign.State)
+		}
+		pending, err := h.campaigns.ListInstances(campaign.ID, campaigns.InstancePending)
+		if err != nil {
+			return err
+		}
+		for _, instance := range pending {
+			instance.State = campaigns.InstanceCanceled
+			instance.UpdatedAt = time.Now()
+			if err := h.campaigns.UpdateInstance(instance); err != nil {
+				return err
+			}
+		}
+		campaign.State = campaigns.StateCanceled
+		campaign.Message = "Canceled by the operator"
+		campaign.Progress.Canceled += len(pending)
+		campaign.Progress.Pending -= len(pending)
+		return nil
+	})
+}
+
+var errInvalidState = errors.New("invalid campaign state")
+
+func (h *Handler) changeState(w http.ResponseWriter, req *http.Request, change func(campaign *internal.Campaign) error) {
+	campaign, ok := h.campaign(w, req)
+	if !ok {
+		return
+	}
+	if err := change(campaign); err != nil {
+		if errors.Is(err, errInvalidState) {
+			httputil.WriteErrorResponse(w, http.StatusConflict, err)
+			return
+		}
+		h.log.Error(fmt.Sprintf("unable to change the state of campaign %s: %s", campaign.ID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	updated, err := h.campaigns.Update(*campaign)
+	switch {
+	case dberr.IsConflict(err):
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("campaign %s was changed by another request, try again", campaign.ID))
+		return
+	case err != nil:
+		h.log.Error(fmt.Sprintf("unable to update campaign %s: %s", campaign.ID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.log.Info(fmt.Sprintf("campaign %s is %s", updated.ID, updated.State))
+	httputil.WriteResponse(w, http.StatusOK, toCampaignDTO(*updated))
+}
+
+func (h *Handler) campaign(w http.ResponseWriter, req *http.Request) (*internal.Campaign, bool) {
+	campaignID := req.PathValue("campaign_id")
+	campaign, err := h.campaigns.Get(campaignID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		return nil, false
+	case err != nil:
+		h.log.Error(fmt.Sprintf("unable to get campaign %s: %s", campaignID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return campaign, true
+}
+
+func validateCampaign(request campaigns.CreateRequest) error {
+	if request.Target.IsEmpty() {
+		return errors.New("the target must contain at least one filter")
+	}
+	if len(request.Parameters) == 0 {
+		return errors.New("parameters must be set")
+	}
+	// the parameters are validated against the plan schema of every instance when the update request is sent,
+	// here only the unknown parameters are rejected
+	decoder := json.NewDecoder(bytes.NewReader(request.Parameters))
+	decoder.DisallowUnknownFields()
+	var params internal.UpdatingParametersDTO
+	if err := decoder.Decode(&params); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	strategy := request.Strategy
+	if strategy.CanarySize < 0 || strategy.WaveSize < 0 || strategy.Parallelism < 0 || strategy.MaxFailures < 0 {
+		return errors.New("canarySize, waveSize, parallelism and maxFailures must not be negative")
+	}
+	if strategy.WaveDelay != "" {
+		if delay, err := time.ParseDuration(strategy.WaveDelay); err != nil || delay < 0 {
+			return fmt.Errorf("waveDelay %q must be a non-negative duration", strategy.WaveDelay)
+		}
+	}
+	return nil
+}
+
+// waveOf returns the wave of the instance with the given index, the canary wave is followed by waves of the wave size
+func waveOf(index int, strategy campaigns.Strategy) int {
+	wave := 0
+	if strategy.CanarySize > 0 {
+		if index < strategy.CanarySize {
+			return 0
+		}
+		index -= strategy.CanarySize
+		wave = 1
+	}
+	if strategy.WaveSize > 0 {
+		wave += index / strategy.WaveSize
+	}
+	return wave
+}
+
+func toCampaignDTO(campaign internal.Campaign) campaigns.CampaignDTO {
+	return campaigns.CampaignDTO{
+		ID:          campaign.ID,
+		Description: campaign.Description,
+		State:       campaign.State,
+		Message:     campaign.Message,
+		Target:      campaign.Target,
+		Parameters:  campaign.Parameters,
+		Strategy:    campaign.Strategy,
+		CurrentWave: campaign.CurrentWave,
+		Waves:       campaign.Waves,
+		Progress:    campaign.Progress,
+		CreatedAt:   campaign.CreatedAt,
+		UpdatedAt:   campaign.UpdatedAt,
+	}
+}
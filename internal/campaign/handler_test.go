@@ -0,0 +1,175 @@
+package campaign_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal/campaign"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaigns(t *testing.T) {
+	router := httputil.NewRouter()
+	db := storage.NewMemoryStorage()
+	for _, id := range []string{"i-1", "i-2", "i-3"} {
+		instance := fixture.FixInstance(id)
+		instance.GlobalAccountID = "ga-1"
+		require.NoError(t, db.Instances().Insert(instance))
+	}
+	other := fixture.FixInstance("i-4")
+	other.GlobalAccountID = "ga-2"
+	require.NoError(t, db.Instances().Insert(other))
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	campaign.NewHandler(db, 100, logger).AttachRoutes(router)
+
+	var created campaigns.CampaignDTO
+
+	t.Run("should create campaign with waves", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodPost, "/campaigns", campaigns.CreateRequest{
+			Description: "raise the autoscaler maximum",
+			Target:      campaigns.Target{GlobalAccountIDs: []string{"ga-1"}},
+			Parameters:  json.RawMessage(`{"autoScalerMax": 30}`),
+			Strategy:    campaigns.Strategy{CanarySize: 1, WaveSize: 1},
+		})
+
+		// then
+		require.Equal(t, http.StatusCreated, resp.Code)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, campaigns.StatePending, created.State)
+		assert.Equal(t, 3, created.Waves)
+		assert.Equal(t, campaigns.Progress{Total: 3, Pending: 3}, created.Progress)
+
+		resp = call(router, http.MethodGet, "/campaigns/"+created.ID+"/instances", nil)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page campaigns.InstancesPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Equal(t, 3, page.Count)
+		for i, instance := range page.Data {
+			assert.Equal(t, i, instance.Wave)
+			assert.Equal(t, campaigns.InstancePending, instance.State)
+		}
+	})
+
+	t.Run("should reject invalid campaigns", func(t *testing.T) {
+		for name, request := range map[string]campaigns.CreateRequest{
+			"empty target": {
+				Parameters: json.RawMessage(`{"autoScalerMax": 30}`),
+			},
+			"unknown parameter": {
+				Target:     campaigns.Target{GlobalAccountIDs: []string{"ga-1"}},
+				Parameters: json.RawMessage(`{"autoScalerMaximum": 30}`),
+			},
+			"invalid wave delay": {
+				Target:     campaigns.Target{GlobalAccountIDs: []string{"ga-1"}},
+				Parameters: json.RawMessage(`{"autoScalerMax": 30}`),
+				Strategy:   campaigns.Strategy{WaveDelay: "tomorrow"},
+			},
+			"no matching instances": {
+				Target:     campaigns.Target{GlobalAccountIDs: []string{"ga-3"}},
+				Parameters: json.RawMessage(`{"autoScalerMax": 30}`),
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				resp := call(router, http.MethodPost, "/campaigns", request)
+
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			})
+		}
+	})
+
+	t.Run("should pause and resume the campaign", func(t *testing.T) {
+		// when
+		paused := call(router, http.MethodPost, "/campaigns/"+created.ID+"/pause", nil)
+		pausedAgain := call(router, http.MethodPost, "/campaigns/"+created.ID+"/pause", nil)
+		resumed := call(router, http.MethodPost, "/campaigns/"+created.ID+"/resume", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, paused.Code)
+		assert.Equal(t, http.StatusConflict, pausedAgain.Code)
+		require.Equal(t, http.StatusOK, resumed.Code)
+		var dto campaigns.CampaignDTO
+		require.NoError(t, json.NewDecoder(resumed.Body).Decode(&dto))
+		assert.Equal(t, campaigns.StateInProgress, dto.State)
+	})
+
+	t.Run("should cancel pending updates", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodPost, "/campaigns/"+created.ID+"/cancel", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var dto campaigns.CampaignDTO
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&dto))
+		assert.Equal(t, campaigns.StateCanceled, dto.State)
+		assert.Equal(t, campaigns.Progress{Total: 3, Canceled: 3}, dto.Progress)
+
+		canceled, err := db.Campaigns().ListInstances(created.ID, campaigns.InstanceCanceled)
+		require.NoError(t, err)
+		assert.Len(t, canceled, 3)
+		assert.Equal(t, http.StatusConflict, call(router, http.MethodPost, "/campaigns/"+created.ID+"/retry", nil).Code)
+	})
+
+	t.Run("should list campaigns by state", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodGet, "/campaigns?state=canceled", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page campaigns.CampaignsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Equal(t, 1, page.Count)
+		assert.Equal(t, 1, page.TotalCount)
+		assert.Equal(t, created.ID, page.Data[0].ID)
+	})
+
+	t.Run("should paginate campaigns and return the total count", func(t *testing.T) {
+		// given
+		resp := call(router, http.MethodPost, "/campaigns", campaigns.CreateRequest{
+			Description: "second campaign",
+			Target:      campaigns.Target{GlobalAccountIDs: []string{"ga-2"}},
+			Parameters:  created.Parameters,
+		})
+		require.Equal(t, http.StatusCreated, resp.Code)
+
+		// when
+		resp = call(router, http.MethodGet, "/campaigns?page=2&page_size=1", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page campaigns.CampaignsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.Equal(t, 1, page.Count)
+		assert.Equal(t, 2, page.TotalCount)
+		assert.Equal(t, "second campaign", page.Data[0].Description)
+	})
+
+	t.Run("should return not found for unknown campaign", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, call(router, http.MethodGet, "/campaigns/unknown", nil).Code)
+		assert.Equal(t, http.StatusNotFound, call(router, http.MethodPost, "/campaigns/unknown/pause", nil).Code)
+	})
+}
+
+func call(router *httputil.Router, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
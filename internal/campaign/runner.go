@@ -0,0 +1,285 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/google/uuid"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+type Config struct {
+	// Enables campaigns and their API
+	Enabled bool `envconfig:"default=false"`
+	// How often the runner checks campaigns and the update operations started by them
+	PollInterval time.Duration `envconfig:"default=30s"`
+	// Time after which a campaign processed by a KEB replica can be processed by another replica
+	LeaseDuration time.Duration `envconfig:"default=5m"`
+	// Parallelism of campaigns which do not define it in the strategy
+	DefaultParallelism int `envconfig:"default=10"`
+	// Maximum number of update requests sent by the runner in one poll interval, for all campaigns together
+	MaxUpdatesPerInterval int `envconfig:"default=50"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("(Enabled=%t; PollInterval=%s; LeaseDuration=%s; DefaultParallelism=%d; MaxUpdatesPerInterval=%d)",
+		c.Enabled, c.PollInterval, c.LeaseDuration, c.DefaultParallelism, c.MaxUpdatesPerInterval)
+}
+
+// Updater sends the update request of the instance, it is implemented by the OSB API update endpoint
+type Updater interface {
+	Update(ctx context.Context, instanceID string, details domain.UpdateDetails, asyncAllowed bool) (domain.UpdateServiceSpec, error)
+}
+
+// Runner updates instances of campaigns in waves. Update requests are sent in the same way as OSB API update requests,
+// so every update is validated against the plan schema and processed by the update operation.
+type Runner struct {
+	campaigns  storage.Campaigns
+	instances  storage.Instances
+	operations storage.Operations
+	updater    Updater
+	cfg        Config
+	owner      string
+	log        *slog.Logger
+}
+
+func NewRunner(db storage.BrokerStorage, updater Updater, cfg Config, log *slog.Logger) *Runner {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "keb"
+	}
+	return &Runner{
+		campaigns:  db.Campaigns(),
+		instances:  db.Instances(),
+		operations: db.Operations(),
+		updater:    updater,
+		cfg:        cfg,
+		owner:      fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+		log:        log.With("component", "CampaignRunner"),
+	}
+}
+
+// Run processes campaigns until the context is done
+func (r *Runner) Run(ctx context.Context) {
+	r.log.Info("starting the campaign runner")
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.Process(ctx)
+		select {
+		case <-ctx.Done():
+			r.log.Info("campaign runner stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process checks all pending and in progress campaigns once and starts the next updates
+func (r *Runner) Process(ctx context.Context) {
+	claimed, err := r.campaigns.Claim(r.owner, r.cfg.LeaseDuration)
+	if err != nil {
+		r.log.Error(fmt.Sprintf("unable to claim campaigns: %s", err))
+		return
+	}
+
+	budget := r.cfg.MaxUpdatesPerInterval
+	for _, campaign := range claimed {
+		if ctx.Err() != nil {
+			return
+		}
+		budget -= r.process(ctx, campaign, budget)
+	}
+}
+
+// process returns the number of sent update requests
+func (r *Runner) process(ctx context.Context, campaign internal.Campaign, budget int) int {
+	log := r.log.With("campaignID", campaign.ID)
+	instances, err := r.campaigns.ListInstances(campaign.ID, "")
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to list instances of the campaign: %s", err))
+		return 0
+	}
+	for i := range instances {
+		if instances[i].State == campaigns.InstanceInProgress {
+			r.refresh(&instances[i], log)
+		}
+	}
+	if campaign.State == campaigns.StatePending {
+		log.Info("starting the campaign")
+		campaign.State = campaigns.StateInProgress
+	}
+
+	started := 0
+	progress := countProgress(instances)
+	switch {
+	case progress.Failed-campaign.AcceptedFailures > campaign.Strategy.MaxFailures:
+		log.Warn(fmt.Sprintf("pausing the campaign, %d updates failed", progress.Failed))
+		campaign.State = campaigns.StatePaused
+		campaign.Message = fmt.Sprintf("Paused because %d updates failed, the campaign tolerates %d failed updates", progress.Failed-campaign.AcceptedFailures, campaign.Strategy.MaxFailures)
+	case progress.Pending == 0 && progress.InProgress == 0:
+		campaign.State, campaign.Message = finalState(progress)
+		log.Info(fmt.Sprintf("campaign finished: %s", campaign.Message))
+	default:
+		if r.nextWave(&campaign, instances) {
+			started = r.startUpdates(ctx, campaign, instances, &progress, budget, log)
+		}
+	}
+
+	campaign.Progress = progress
+	if _, err := r.campaigns.Update(campaign); err != nil {
+		if dberr.IsConflict(err) {
+			log.Info("the campaign was changed during processing, it is processed again in the next pass")
+		} else {
+			log.Error(fmt.Sprintf("unable to store the campaign: %s", err))
+		}
+	}
+	return started
+}
+
+// refresh sets the state of the instance from the state of its update operation
+func (r *Runner) refresh(instance *internal.CampaignInstance, log *slog.Logger) {
+	operation, err := r.operations.GetOperationByID(instance.OperationID)
+	switch {
+	case dberr.IsNotFound(err):
+		r.finishInstance(instance, campaigns.InstanceFailed, fmt.Sprintf("the update operation %s does not exist", instance.OperationID), log)
+		return
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the operation %s: %s", instance.OperationID, err))
+		return
+	}
+
+	switch operation.State {
+	case domain.Succeeded:
+		r.finishInstance(instance, campaigns.InstanceSucceeded, "", log)
+	case domain.Failed:
+		r.finishInstance(instance, campaigns.InstanceFailed, operation.Description, log)
+	case internal.OperationStateCanceled:
+		r.finishInstance(instance, campaigns.InstanceFailed, "the update operation was canceled", log)
+	}
+}
+
+// nextWave moves the campaign to the next wave when the current one is finished and the wave delay has passed,
+// it returns false if the campaign has to wait
+func (r *Runner) nextWave(campaign *internal.Campaign, instances []internal.CampaignInstance) bool {
+	for _, instance := range instances {
+		if instance.Wave == campaign.CurrentWave && !instance.State.IsFinished() {
+			return true
+		}
+	}
+	if campaign.CurrentWave >= campaign.Waves-1 {
+		return true
+	}
+
+	now := time.Now()
+	if campaign.NextWaveAt.IsZero() {
+		delay, _ := time.ParseDuration(campaign.Strategy.WaveDelay)
+		campaign.NextWaveAt = now.Add(delay)
+	}
+	if now.Before(campaign.NextWaveAt) {
+		return false
+	}
+	campaign.CurrentWave++
+	campaign.NextWaveAt = time.Time{}
+	return true
+}
+
+func (r *Runner) startUpdates(ctx context.Context, campaign internal.Campaign, instances []internal.CampaignInstance, progress *campaigns.Progress, budget int, log *slog.Logger) int {
+	parallelism := campaign.Strategy.Parallelism
+	if parallelism <= 0 {
+		parallelism = r.cfg.DefaultParallelism
+	}
+
+	started := 0
+	for i := range instances {
+		if progress.InProgress >= parallelism || started >= budget || ctx.Err() != nil {
+			break
+		}
+		if instances[i].Wave != campaign.CurrentWave || instances[i].State != campaigns.InstancePending {
+			continue
+		}
+		started++
+		r.start(ctx, campaign, &instances[i], log)
+		*progress = countProgress(instances)
+	}
+	return started
+}
+
+func (r *Runner) start(ctx context.Context, campaign internal.Campaign, campaignInstance *internal.CampaignInstance, log *slog.Logger) {
+	log = log.With("instanceID", campaignInstance.InstanceID)
+	instance, err := r.instances.GetByID(campaignInstance.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		r.finishInstance(campaignInstance, campaigns.InstanceSkipped, "the instance does not exist", log)
+		return
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the instance: %s", err))
+		return
+	case instance.IsExpired():
+		r.finishInstance(campaignInstance, campaigns.InstanceSkipped, "the instance is expired", log)
+		return
+	case instance.Parameters.ErsContext.Active != nil && !*instance.Parameters.ErsContext.Active:
+		r.finishInstance(campaignInstance, campaigns.InstanceSkipped, "the instance is suspended", log)
+		return
+	}
+
+	response, err := r.updater.Update(ctx, instance.InstanceID, domain.UpdateDetails{
+		ServiceID:     instance.ServiceID,
+		PlanID:        instance.ServicePlanID,
+		RawParameters: campaign.Parameters,
+		// the context of the instance is not changed by campaigns
+		RawContext: json.RawMessage("{}"),
+	}, true)
+	switch {
+	case err != nil:
+		r.finishInstance(campaignInstance, campaigns.InstanceFailed, fmt.Sprintf("the update request was rejected: %s", err), log)
+	case response.OperationData == "":
+		r.finishInstance(campaignInstance, campaigns.InstanceSkipped, "no update operation was created", log)
+	default:
+		log.Info(fmt.Sprintf("update operation %s created", response.OperationData))
+		campaignInstance.State = campaigns.InstanceInProgress
+		campaignInstance.OperationID = response.OperationData
+		campaignInstance.UpdatedAt = time.Now()
+		if err := r.campaigns.UpdateInstance(*campaignInstance); err != nil {
+			log.Error(fmt.Sprintf("unable to store the update operation ID: %s", err))
+		}
+	}
+}
+
+func (r *Runner) finishInstance(instance *internal.CampaignInstance, state campaigns.InstanceState, message string, log *slog.Logger) {
+	if state == campaigns.InstanceFailed {
+		log.Warn(fmt.Sprintf("update of instance %s failed: %s", instance.InstanceID, message))
+	}
+	instance.State = state
+	instance.Message = message
+	instance.UpdatedAt = time.Now()
+	if err := r.campaigns.UpdateInstance(*instance); err != nil {
+		log.Error(fmt.Sprintf("unable to store the state of instance %s: %s", instance.InstanceID, err))
+	}
+}
+
+func countProgress(instances []internal.CampaignInstance) campaigns.Progress {
+	var progress campaigns.Progress
+	for _, instance := range instances {
+		progress.Add(instance.State)
+	}
+	return progress
+}
+
+func finalState(progress campaigns.Progress) (campaigns.State, string) {
+	message := fmt.Sprintf("%d instances updated, %d failed, %d skipped, %d canceled", progress.Succeeded, progress.Failed, progress.Skipped, progress.Canceled)
+	if progress.Failed > 0 {
+		return campaigns.StateFailed, message
+	}
+	return campaigns.StateSucceeded, message
+}
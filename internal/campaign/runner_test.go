@@ -0,0 +1,227 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner(t *testing.T) {
+	cfg := Config{PollInterval: time.Second, LeaseDuration: time.Minute, DefaultParallelism: 10, MaxUpdatesPerInterval: 100}
+
+	t.Run("should update instances in the canary wave and the following waves", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		updater := newFakeUpdater(db)
+		fixCampaign(t, db, "campaign-1", campaigns.Strategy{CanarySize: 1, WaveSize: 2}, "i-1", "i-2", "i-3", "i-4", "i-5")
+		runner := NewRunner(db, updater, cfg, fixLogger())
+
+		// when
+		runner.Process(context.Background())
+		runner.Process(context.Background())
+
+		// then
+		assert.Equal(t, []string{"i-1"}, updater.calls)
+		assertCampaign(t, db, "campaign-1", campaigns.StateInProgress, 0)
+
+		// when
+		updater.finishAll(t, domain.Succeeded)
+		runner.Process(context.Background())
+
+		// then
+		assert.Equal(t, []string{"i-1", "i-2", "i-3"}, updater.calls)
+		assertCampaign(t, db, "campaign-1", campaigns.StateInProgress, 1)
+
+		// when
+		updater.finishAll(t, domain.Succeeded)
+		runner.Process(context.Background())
+		updater.finishAll(t, domain.Succeeded)
+		runner.Process(context.Background())
+
+		// then
+		assert.Equal(t, []string{"i-1", "i-2", "i-3", "i-4", "i-5"}, updater.calls)
+		campaign := assertCampaign(t, db, "campaign-1", campaigns.StateSucceeded, 2)
+		assert.Equal(t, campaigns.Progress{Total: 5, Succeeded: 5}, campaign.Progress)
+	})
+
+	t.Run("should not start more updates than the parallelism", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		updater := newFakeUpdater(db)
+		fixCampaign(t, db, "campaign-1", campaigns.Strategy{Parallelism: 2}, "i-1", "i-2", "i-3")
+		runner := NewRunner(db, updater, cfg, fixLogger())
+
+		// when
+		runner.Process(context.Background())
+		runner.Process(context.Background())
+
+		// then
+		assert.Equal(t, []string{"i-1", "i-2"}, updater.calls)
+
+		// when
+		updater.finish(t, "i-1", domain.Succeeded)
+		runner.Process(context.Background())
+
+		// then
+		assert.Equal(t, []string{"i-1", "i-2", "i-3"}, updater.calls)
+	})
+
+	t.Run("should limit the number of update requests of all campaigns", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		updater := newFakeUpdater(db)
+		fixCampaign(t, db, "campaign-1", campaigns.Strategy{}, "i-1", "i-2")
+		fixCampaign(t, db, "campaign-2", campaigns.Strategy{}, "i-3")
+		limited := cfg
+		limited.MaxUpdatesPerInterval = 2
+		runner := NewRunner(db, updater, limited, fixLogger())
+
+		// when
+		runner.Process(context.Background())
+
+		// then
+		assert.Len(t, updater.calls, 2)
+	})
+
+	t.Run("should pause the campaign when too many updates failed", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		updater := newFakeUpdater(db)
+		updater.errors["i-2"] = errors.New("validation failed")
+		fixCampaign(t, db, "campaign-1", campaigns.Strategy{MaxFailures: 1}, "i-1", "i-2", "i-3")
+		runner := NewRunner(db, updater, cfg, fixLogger())
+
+		// when
+		runner.Process(context.Background())
+		updater.finish(t, "i-1", domain.Failed)
+		runner.Process(context.Background())
+
+		// then
+		campaign := assertCampaign(t, db, "campaign-1", campaigns.StatePaused, 0)
+		assert.Equal(t, 2, campaign.Progress.Failed)
+		failed, err := db.Campaigns().ListInstances("campaign-1", campaigns.InstanceFailed)
+		require.NoError(t, err)
+		require.Len(t, failed, 2)
+		assert.Equal(t, "the update request was rejected: validation failed", failed[1].Message)
+
+		// when
+		runner.Process(context.Background())
+
+		// then
+		assert.Equal(t, []string{"i-1", "i-2", "i-3"}, updater.calls)
+		assertCampaign(t, db, "campaign-1", campaigns.StatePaused, 0)
+	})
+
+	t.Run("should skip expired instances", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		updater := newFakeUpdater(db)
+		fixCampaign(t, db, "campaign-1", campaigns.Strategy{}, "i-1")
+		instance, err := db.Instances().GetByID("i-1")
+		require.NoError(t, err)
+		instance.ExpiredAt = ptr.Time(time.Now())
+		_, err = db.Instances().Update(*instance)
+		require.NoError(t, err)
+		runner := NewRunner(db, updater, cfg, fixLogger())
+
+		// when
+		runner.Process(context.Background())
+		runner.Process(context.Background())
+
+		// then
+		assert.Empty(t, updater.calls)
+		campaign := assertCampaign(t, db, "campaign-1", campaigns.StateSucceeded, 0)
+		assert.Equal(t, 1, campaign.Progress.Skipped)
+	})
+}
+
+type fakeUpdater struct {
+	db     storage.BrokerStorage
+	calls  []string
+	errors map[string]error
+}
+
+func newFakeUpdater(db storage.BrokerStorage) *fakeUpdater {
+	return &fakeUpdater{db: db, errors: make(map[string]error)}
+}
+
+func (f *fakeUpdater) Update(_ context.Context, instanceID string, details domain.UpdateDetails, _ bool) (domain.UpdateServiceSpec, error) {
+	f.calls = append(f.calls, instanceID)
+	if err := f.errors[instanceID]; err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+	operation := fixture.FixOperation(operationID(instanceID), instanceID, internal.OperationTypeUpdate)
+	operation.State = domain.InProgress
+	if err := f.db.Operations().InsertOperation(operation); err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+	return domain.UpdateServiceSpec{IsAsync: true, OperationData: operation.ID}, nil
+}
+
+func (f *fakeUpdater) finish(t *testing.T, instanceID string, state domain.LastOperationState) {
+	operation, err := f.db.Operations().GetOperationByID(operationID(instanceID))
+	require.NoError(t, err)
+	operation.State = state
+	_, err = f.db.Operations().UpdateOperation(*operation)
+	require.NoError(t, err)
+}
+
+func (f *fakeUpdater) finishAll(t *testing.T, state domain.LastOperationState) {
+	for _, instanceID := range f.calls {
+		if _, found := f.errors[instanceID]; !found {
+			f.finish(t, instanceID, state)
+		}
+	}
+}
+
+func operationID(instanceID string) string {
+	return fmt.Sprintf("op-%s", instanceID)
+}
+
+func fixCampaign(t *testing.T, db storage.BrokerStorage, id string, strategy campaigns.Strategy, instanceIDs ...string) {
+	campaign := internal.Campaign{
+		ID:         id,
+		State:      campaigns.StatePending,
+		Parameters: []byte(`{"autoScalerMax": 30}`),
+		Strategy:   strategy,
+		CreatedAt:  time.Now(),
+	}
+	instances := make([]internal.CampaignInstance, 0, len(instanceIDs))
+	for i, instanceID := range instanceIDs {
+		if _, err := db.Instances().GetByID(instanceID); err != nil {
+			require.NoError(t, db.Instances().Insert(fixture.FixInstance(instanceID)))
+		}
+		wave := waveOf(i, strategy)
+		campaign.Waves = max(campaign.Waves, wave+1)
+		instances = append(instances, internal.CampaignInstance{CampaignID: id, InstanceID: instanceID, Wave: wave, State: campaigns.InstancePending})
+	}
+	require.NoError(t, db.Campaigns().Insert(campaign, instances))
+}
+
+func assertCampaign(t *testing.T, db storage.BrokerStorage, id string, state campaigns.State, wave int) *internal.Campaign {
+	campaign, err := db.Campaigns().Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, state, campaign.State)
+	assert.Equal(t, wave, campaign.CurrentWave)
+	return campaign
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+}
@@ -51,6 +51,33 @@ type UpdatingParametersDTO struct {
 	MachineType               *string                        `json:"machineType,omitempty"`
 	AdditionalWorkerNodePools []pkg.AdditionalWorkerNodePool `json:"additionalWorkerNodePools"`
 	IngressFiltering          *bool                          `json:"ingressFiltering,omitempty"`
+	MaintenanceWindow         *pkg.MaintenanceWindowDTO      `json:"maintenanceWindow,omitempty"`
+	// DeferToMaintenanceWindow postpones the update with disruptive changes to the next maintenance window
+	DeferToMaintenanceWindow *bool `json:"deferToMaintenanceWindow,omitempty"`
+	// Labels replace all labels of the instance, labels are not changed if nil
+	Labels map[string]string `json:"labels,omitempty"`
+	// Modules replace modules of the Kyma resource, modules are not changed if nil
+	Modules *pkg.ModulesDTO `json:"modules,omitempty"`
+	// Networking extends the nodes CIDR, pods and services CIDRs cannot be changed
+	Networking *pkg.NetworkingDTO `json:"networking,omitempty"`
+}
+
+// HasDisruptiveChanges returns true if the update rolls nodes of the cluster, which happens when a machine type or additional worker node pools are changed
+func (u UpdatingParametersDTO) HasDisruptiveChanges(current pkg.ProvisioningParametersDTO) bool {
+	if u.MachineType != nil && *u.MachineType != "" && (current.MachineType == nil || *current.MachineType != *u.MachineType) {
+		return true
+	}
+	if u.AdditionalWorkerNodePools != nil {
+		if len(u.AdditionalWorkerNodePools) != len(current.AdditionalWorkerNodePools) {
+			return true
+		}
+		for i := range u.AdditionalWorkerNodePools {
+			if u.AdditionalWorkerNodePools[i] != current.AdditionalWorkerNodePools[i] {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (u UpdatingParametersDTO) UpdateAutoScaler(p *pkg.ProvisioningParametersDTO) bool {
@@ -74,6 +101,23 @@ func (u UpdatingParametersDTO) UpdateAutoScaler(p *pkg.ProvisioningParametersDTO
 	return updated
 }
 
+// UpdateNetworking sets the new nodes CIDR and keeps the pods and services CIDRs, returns true if the nodes CIDR is changed
+func (u UpdatingParametersDTO) UpdateNetworking(p *pkg.ProvisioningParametersDTO) bool {
+	if u.Networking == nil || u.Networking.NodesCidr == "" {
+		return false
+	}
+	networking := pkg.NetworkingDTO{}
+	if p.Networking != nil {
+		if p.Networking.NodesCidr == u.Networking.NodesCidr {
+			return false
+		}
+		networking = *p.Networking
+	}
+	networking.NodesCidr = u.Networking.NodesCidr
+	p.Networking = &networking
+	return true
+}
+
 type ERSContext struct {
 	TenantID              string                             `json:"tenant_id,omitempty"`
 	SubAccountID          string                             `json:"subaccount_id"`
@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
@@ -14,6 +15,11 @@ type Publisher interface {
 	Publish(ctx context.Context, event interface{})
 }
 
+// SyncPublisher calls event handlers in the caller goroutine and returns their errors, which allows to retry the delivery
+type SyncPublisher interface {
+	PublishSync(ctx context.Context, event interface{}) error
+}
+
 type Subscriber interface {
 	Subscribe(evType interface{}, evHandler Handler)
 }
@@ -48,6 +54,20 @@ func (b *PubSub) Publish(ctx context.Context, ev interface{}) {
 	}
 }
 
+func (b *PubSub) PublishSync(ctx context.Context, ev interface{}) error {
+	b.mu.Lock()
+	hList := append([]Handler{}, b.handlers[reflect.TypeOf(ev)]...)
+	b.mu.Unlock()
+
+	var errs []error
+	for _, handler := range hList {
+		if err := handler(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (b *PubSub) Subscribe(evType interface{}, evHandler Handler) {
 	tt := reflect.TypeOf(evType)
 	b.mu.Lock()
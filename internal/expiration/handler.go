@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
@@ -33,14 +35,18 @@ type Handler interface {
 type handler struct {
 	instances           storage.Instances
 	operations          storage.Operations
+	outbox              storage.Outbox
+	actions             storage.Actions
 	deprovisioningQueue suspension.Adder
 	log                 *slog.Logger
 }
 
-func NewHandler(instancesStorage storage.Instances, operationsStorage storage.Operations, deprovisioningQueue suspension.Adder, log *slog.Logger) Handler {
+func NewHandler(instancesStorage storage.Instances, operationsStorage storage.Operations, outbox storage.Outbox, actions storage.Actions, deprovisioningQueue suspension.Adder, log *slog.Logger) Handler {
 	return &handler{
 		instances:           instancesStorage,
 		operations:          operationsStorage,
+		outbox:              outbox,
+		actions:             actions,
 		deprovisioningQueue: deprovisioningQueue,
 		log:                 log.With("service", "ExpirationEndpoint"),
 	}
@@ -97,6 +103,21 @@ func (h *handler) expireInstance(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// the instance is expired, the event is only logged when it cannot be stored
+	event, err := internal.NewInstanceOutboxEvent(internal.InstanceExpiredOutboxEvent, *instance, suspensionOpID, "")
+	if err == nil {
+		err = h.outbox.Insert(event)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to store %s event: %s", internal.InstanceExpiredOutboxEvent, err.Error()))
+	}
+	audit.Record(h.actions, pkg.Action{
+		Type:       pkg.ExpirationActionType,
+		InstanceID: instanceID,
+		Actor:      audit.ActorFromRequest(req),
+		Message:    fmt.Sprintf("Instance expired, suspension operation %s.", suspensionOpID),
+	}, logger)
+
 	res := expirationResponse{suspensionOpID}
 	httputil.WriteResponse(w, http.StatusAccepted, res)
 
@@ -11,10 +11,12 @@ import (
 	"testing"
 	"time"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/expiration"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/pivotal-cf/brokerapi/v12/domain"
@@ -31,7 +33,7 @@ func TestExpiration(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
-	handler := expiration.NewHandler(storage.Instances(), storage.Operations(), deprovisioningQueue, logger)
+	handler := expiration.NewHandler(storage.Instances(), storage.Operations(), storage.Outbox(), storage.Actions(), deprovisioningQueue, logger)
 	handler.AttachRoutes(router)
 
 	t.Run("should receive 404 Not Found response", func(t *testing.T) {
@@ -87,6 +89,7 @@ func TestExpiration(t *testing.T) {
 
 		reqPath := fmt.Sprintf(requestPathFormat, instanceID)
 		req := httptest.NewRequest("PUT", reqPath, nil)
+		req = req.WithContext(middleware.AddCallerToContext(req.Context(), middleware.Caller{Email: "admin@example.com"}))
 		w := httptest.NewRecorder()
 
 		// when
@@ -99,10 +102,15 @@ func TestExpiration(t *testing.T) {
 		// when
 		actualInstance, err := storage.Instances().GetByID(instanceID)
 		require.NoError(t, err)
+		actions, err := storage.Actions().ListActionsByInstanceID(instanceID)
+		require.NoError(t, err)
 
 		// then
 		assert.False(t, *actualInstance.Parameters.ErsContext.Active)
 		assert.NotNil(t, actualInstance.ExpiredAt)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.ExpirationActionType, actions[0].Type)
+		assert.Equal(t, "admin@example.com", actions[0].Actor)
 	})
 
 	t.Run("should repeat suspension on previously expired instance", func(t *testing.T) {
@@ -10,7 +10,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 
@@ -324,6 +324,8 @@ func FixBindingWithInstanceID(bindingID string, instanceID string) internal.Bind
 		Kubeconfig:        "kubeconfig",
 		ExpirationSeconds: 600,
 		CreatedBy:         "john.smith@email.com",
+		Role:              "admin",
+		State:             domain.Succeeded,
 	}
 }
 
@@ -339,6 +341,8 @@ func FixExpiredBindingWithInstanceID(bindingID string, instanceID string, offset
 		Kubeconfig:        "kubeconfig",
 		ExpirationSeconds: 600,
 		CreatedBy:         "john.smith@email.com",
+		Role:              "admin",
+		State:             domain.Succeeded,
 	}
 }
 
@@ -378,35 +382,35 @@ func FixKymaResourceWithGivenRuntimeID(kcpClient client.Client, kymaResourceName
 	}})
 }
 
-func NewFakeAWSClientFactory(zones map[string][]string, error error) *FakeAWSClientFactory {
-	fakeClient := &fakeAWSClient{
+func NewFakeHyperscalerClientFactory(zones map[string][]string, error error) *FakeHyperscalerClientFactory {
+	fakeClient := &fakeHyperscalerClient{
 		zones: zones,
 		err:   error,
 	}
-	return &FakeAWSClientFactory{client: fakeClient}
+	return &FakeHyperscalerClientFactory{client: fakeClient}
 }
 
-type FakeAWSClientFactory struct {
-	client aws.Client
+type FakeHyperscalerClientFactory struct {
+	client hyperscalers.Client
 }
 
-func (f *FakeAWSClientFactory) New(ctx context.Context, accessKeyID, secretAccessKey, region string) (aws.Client, error) {
+func (f *FakeHyperscalerClientFactory) New(ctx context.Context, provider pkg.CloudProvider, secret *unstructured.Unstructured, region string) (hyperscalers.Client, error) {
 	return f.client, nil
 }
 
-type fakeAWSClient struct {
+type fakeHyperscalerClient struct {
 	zones map[string][]string
 	err   error
 }
 
-func (f *fakeAWSClient) AvailableZones(ctx context.Context, machineType string) ([]string, error) {
+func (f *fakeHyperscalerClient) AvailableZones(ctx context.Context, machineType string) ([]string, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
 	return f.zones[machineType], nil
 }
 
-func (f *fakeAWSClient) AvailableZonesCount(ctx context.Context, machineType string) (int, error) {
+func (f *fakeHyperscalerClient) AvailableZonesCount(ctx context.Context, machineType string) (int, error) {
 	zones, err := f.AvailableZones(ctx, machineType)
 	if err != nil {
 		return 0, err
@@ -75,10 +75,15 @@ func initAll(ctx context.Context, cfg Config) (*http.Client, storage.BrokerStora
 		TokenURL:     cfg.AuthURL,
 	}
 
+	cipher, err := storage.NewEncrypterFromConfig(cfg.Database)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, nil, nil, nil, err
+	}
 	db, connection, err := storage.NewFromConfig(
 		cfg.Database,
 		events.Config{},
-		storage.NewEncrypter(cfg.Database.SecretKey),
+		cipher,
 	)
 
 	if err != nil {
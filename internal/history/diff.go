@@ -0,0 +1,72 @@
+package history
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
+)
+
+// Change is a parameter which differs between two versions, the field is the JSON path of the parameter,
+// lists are compared as a whole and values of sensitive parameters are redacted
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// Diff returns changes between parameters sorted by the field, a nil previous version means all parameters were added
+func Diff(previous, current *internal.ProvisioningParameters) []Change {
+	oldFields := flatten(previous)
+	newFields := flatten(current)
+
+	changes := make([]Change, 0)
+	for field, newValue := range newFields {
+		oldValue, found := oldFields[field]
+		if !found || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, Change{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	for field, oldValue := range oldFields {
+		if _, found := newFields[field]; !found {
+			changes = append(changes, Change{Field: field, Old: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+func flatten(params *internal.ProvisioningParameters) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if params == nil {
+		return fields
+	}
+	var generic interface{}
+	if err := json.Unmarshal([]byte(audit.Redact(*params)), &generic); err != nil {
+		return fields
+	}
+	flattenInto(fields, "", generic)
+	return fields
+}
+
+func flattenInto(fields map[string]interface{}, prefix string, value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		// empty values are not distinguished from missing ones, the parameters use omitempty
+		if value != nil && value != "" {
+			fields[prefix] = value
+		}
+		return
+	}
+	for key, item := range object {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		flattenInto(fields, field, item)
+	}
+}
@@ -0,0 +1,58 @@
+package history_test
+
+import (
+	"testing"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/history"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	previous := internal.ProvisioningParameters{
+		PlanID: "plan-1",
+		Parameters: pkg.ProvisioningParametersDTO{
+			MachineType:           ptr.String("m5.xlarge"),
+			RuntimeAdministrators: []string{"admin@example.com"},
+			OIDC:                  &pkg.OIDCConnectDTO{OIDCConfigDTO: &pkg.OIDCConfigDTO{ClientID: "client-1", IssuerURL: "https://issuer.example.com"}},
+		},
+		ErsContext: internal.ERSContext{SMOperatorCredentials: &internal.ServiceManagerOperatorCredentials{ClientSecret: "s3cr3t"}},
+	}
+
+	t.Run("should list all parameters of the first version", func(t *testing.T) {
+		// when
+		changes := history.Diff(nil, &previous)
+
+		// then
+		assert.Contains(t, changes, history.Change{Field: "plan_id", New: "plan-1"})
+		assert.Contains(t, changes, history.Change{Field: "parameters.machineType", New: "m5.xlarge"})
+		assert.Contains(t, changes, history.Change{Field: "parameters.oidc.clientID", New: "client-1"})
+		assert.Contains(t, changes, history.Change{Field: "ers_context.sm_operator_credentials", New: "[REDACTED]"})
+	})
+
+	t.Run("should return only changed parameters", func(t *testing.T) {
+		// given
+		current := previous
+		current.Parameters.MachineType = ptr.String("m5.2xlarge")
+		current.Parameters.RuntimeAdministrators = []string{"admin@example.com", "other@example.com"}
+		current.Parameters.OIDC = &pkg.OIDCConnectDTO{OIDCConfigDTO: &pkg.OIDCConfigDTO{ClientID: "client-1"}}
+		current.ErsContext = internal.ERSContext{SMOperatorCredentials: &internal.ServiceManagerOperatorCredentials{ClientSecret: "n3w"}}
+
+		// when
+		changes := history.Diff(&previous, &current)
+
+		// then
+		assert.Equal(t, []history.Change{
+			{Field: "parameters.administrators", Old: []interface{}{"admin@example.com"}, New: []interface{}{"admin@example.com", "other@example.com"}},
+			{Field: "parameters.machineType", Old: "m5.xlarge", New: "m5.2xlarge"},
+			{Field: "parameters.oidc.issuerURL", Old: "https://issuer.example.com"},
+		}, changes)
+	})
+
+	t.Run("should return no changes for equal parameters", func(t *testing.T) {
+		assert.Empty(t, history.Diff(&previous, &previous))
+	})
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type HistoryResponse struct {
+	InstanceID string    `json:"instanceID"`
+	Versions   []Version `json:"versions"`
+}
+
+// Version describes the snapshot and changes compared to the previous version
+type Version struct {
+	Version       int                    `json:"version"`
+	OperationID   string                 `json:"operationID"`
+	OperationType internal.OperationType `json:"operationType"`
+	RuntimeID     string                 `json:"runtimeID"`
+	PlanID        string                 `json:"planID"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	Changes       []Change               `json:"changes"`
+}
+
+// Handler returns versions of instance parameters, the ID is a runtime ID or, for example for deprovisioned instances, an instance ID
+type Handler struct {
+	instances storage.Instances
+	snapshots storage.InstanceSnapshots
+	log       *slog.Logger
+}
+
+func NewHandler(instances storage.Instances, snapshots storage.InstanceSnapshots, log *slog.Logger) *Handler {
+	return &Handler{
+		instances: instances,
+		snapshots: snapshots,
+		log:       log.With("service", "InstanceHistoryEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /runtimes/{id}/history", h.getHistory)
+}
+
+func (h *Handler) getHistory(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	log := h.log.With("id", id)
+
+	// a trial runtime gets a new runtime ID after unsuspension, snapshots are kept by the instance ID
+	instanceID := id
+	instances, err := h.instances.FindAllInstancesForRuntimes([]string{id})
+	if err == nil && len(instances) > 0 {
+		instanceID = instances[0].InstanceID
+	}
+
+	snapshots, err := h.snapshots.ListByInstanceID(instanceID)
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to list snapshots: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(snapshots) == 0 {
+		httputil.WriteErrorResponse(w, http.StatusNotFound, fmt.Errorf("history of the runtime or instance %s not found", id))
+		return
+	}
+
+	response := HistoryResponse{
+		InstanceID: instanceID,
+		Versions:   make([]Version, 0, len(snapshots)),
+	}
+	var previous *internal.ProvisioningParameters
+	for _, snapshot := range snapshots {
+		response.Versions = append(response.Versions, Version{
+			Version:       snapshot.Version,
+			OperationID:   snapshot.OperationID,
+			OperationType: snapshot.OperationType,
+			RuntimeID:     snapshot.RuntimeID,
+			PlanID:        snapshot.Parameters.PlanID,
+			CreatedAt:     snapshot.CreatedAt,
+			Changes:       Diff(previous, &snapshot.Parameters),
+		})
+		previous = &snapshot.Parameters
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
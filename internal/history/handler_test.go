@@ -0,0 +1,111 @@
+package history_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/history"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	instanceID = "instance-1"
+	runtimeID  = "runtime-1"
+)
+
+func TestHistory(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	now := time.Now().UTC().Truncate(time.Second)
+
+	instance := fixture.FixInstance(instanceID)
+	instance.RuntimeID = runtimeID
+	require.NoError(t, db.Instances().Insert(instance))
+
+	provisioning := fixture.FixProvisioningOperation("op-1", instanceID)
+	provisioning.RuntimeID = runtimeID
+	provisioning.UpdatedAt = now.Add(-time.Hour)
+	require.NoError(t, db.Operations().InsertOperation(provisioning))
+
+	update := fixture.FixOperation("op-2", instanceID, internal.OperationTypeUpdate)
+	update.RuntimeID = runtimeID
+	update.ProvisioningParameters = provisioning.ProvisioningParameters
+	update.ProvisioningParameters.Parameters.MachineType = ptr.String("Standard_D16_v3")
+	update.ProvisioningParameters.Parameters.Region = ptr.String("requested-only")
+	update.UpdatedAt = now
+	require.NoError(t, db.Operations().InsertOperation(update))
+
+	recorder := history.NewRecorder(db.Operations(), db.Instances(), db.InstanceSnapshots(), logger)
+	router := httputil.NewRouter()
+	history.NewHandler(db.Instances(), db.InstanceSnapshots(), logger).AttachRoutes(router)
+
+	t.Run("should store snapshots of succeeded operations once", func(t *testing.T) {
+		// when
+		require.NoError(t, recorder.OnOperationSucceeded(context.Background(), process.OperationSucceeded{Operation: provisioning}))
+		// the update stores the applied parameters in the instance, the operation keeps the requested ones
+		instance.Parameters.Parameters.MachineType = ptr.String("Standard_D16_v3")
+		_, err := db.Instances().Update(instance)
+		require.NoError(t, err)
+		require.NoError(t, recorder.OnOperationSucceeded(context.Background(), process.OperationSucceeded{Operation: update}))
+		require.NoError(t, recorder.OnOperationSucceeded(context.Background(), process.OperationSucceeded{Operation: update}))
+		require.NoError(t, recorder.OnOperationSucceeded(context.Background(), process.OperationSucceeded{Operation: fixture.FixOperation("deleted", instanceID, internal.OperationTypeUpdate)}))
+		require.NoError(t, recorder.OnOperationSucceeded(context.Background(), process.OperationSucceeded{Operation: fixture.FixDeprovisioningOperationAsOperation("op-3", instanceID)}))
+
+		// then
+		snapshots, err := db.InstanceSnapshots().ListByInstanceID(instanceID)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		assert.Equal(t, "op-1", snapshots[0].OperationID)
+		assert.Equal(t, now.Add(-time.Hour), snapshots[0].CreatedAt)
+		assert.Equal(t, "Standard_D16_v3", *snapshots[1].Parameters.Parameters.MachineType)
+		assert.Equal(t, instance.Parameters.Parameters.Region, snapshots[1].Parameters.Parameters.Region)
+	})
+
+	t.Run("should return versions with changes", func(t *testing.T) {
+		for _, id := range []string{runtimeID, instanceID} {
+			// when
+			resp := call(router, "/runtimes/"+id+"/history")
+
+			// then
+			require.Equal(t, http.StatusOK, resp.Code)
+			var response history.HistoryResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			assert.Equal(t, instanceID, response.InstanceID)
+			require.Len(t, response.Versions, 2)
+			assert.Equal(t, 1, response.Versions[0].Version)
+			assert.Equal(t, internal.OperationTypeProvision, response.Versions[0].OperationType)
+			assert.NotEmpty(t, response.Versions[0].Changes)
+			assert.Equal(t, 2, response.Versions[1].Version)
+			assert.Equal(t, "op-2", response.Versions[1].OperationID)
+			assert.Equal(t, []history.Change{{Field: "parameters.machineType", Old: "Standard_D8_v3", New: "Standard_D16_v3"}}, response.Versions[1].Changes)
+		}
+	})
+
+	t.Run("should return not found for unknown runtime", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, call(router, "/runtimes/unknown/history").Code)
+	})
+}
+
+func call(router *httputil.Router, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	insertRetryInterval = 100 * time.Millisecond
+	insertRetryTimeout  = 10 * time.Second
+)
+
+// Recorder stores a snapshot of the parameters effective after every succeeded operation. The parameters are taken from
+// the instance, which keeps the applied values, parameters of the operation keep the requested values only.
+// Events are delivered at least once, the snapshot of an operation which is already stored is skipped.
+// Snapshots of one instance stored at the same time get the same version, the one stored later is inserted again.
+type Recorder struct {
+	operations storage.Operations
+	instances  storage.Instances
+	snapshots  storage.InstanceSnapshots
+	log        *slog.Logger
+}
+
+func NewRecorder(operations storage.Operations, instances storage.Instances, snapshots storage.InstanceSnapshots, log *slog.Logger) *Recorder {
+	return &Recorder{
+		operations: operations,
+		instances:  instances,
+		snapshots:  snapshots,
+		log:        log.With("service", "InstanceHistoryRecorder"),
+	}
+}
+
+func (r *Recorder) OnOperationSucceeded(_ context.Context, ev interface{}) error {
+	succeeded, ok := ev.(process.OperationSucceeded)
+	if !ok {
+		return fmt.Errorf("expected process.OperationSucceeded but got %T", ev)
+	}
+	// deprovisioning does not change parameters, the instance is removed or suspended
+	if succeeded.Operation.Type == internal.OperationTypeDeprovision {
+		return nil
+	}
+	log := r.log.With("instanceID", succeeded.Operation.InstanceID, "operationID", succeeded.Operation.ID)
+
+	// the event does not contain parameters, the operation is read from the storage
+	operation, err := r.operations.GetOperationByID(succeeded.Operation.ID)
+	switch {
+	case dberr.IsNotFound(err):
+		log.Info("operation not found, skipping the snapshot")
+		return nil
+	case err != nil:
+		return fmt.Errorf("while getting the operation %s: %w", succeeded.Operation.ID, err)
+	}
+	instance, err := r.instances.GetByID(operation.InstanceID)
+	switch {
+	case dberr.IsNotFound(err):
+		log.Info("instance not found, skipping the snapshot")
+		return nil
+	case err != nil:
+		return fmt.Errorf("while getting the instance %s: %w", operation.InstanceID, err)
+	}
+
+	var snapshot *internal.InstanceSnapshot
+	err = wait.PollUntilContextTimeout(context.Background(), insertRetryInterval, insertRetryTimeout, true, func(ctx context.Context) (bool, error) {
+		snapshot, err = r.snapshots.Insert(internal.InstanceSnapshot{
+			InstanceID:    operation.InstanceID,
+			OperationID:   operation.ID,
+			OperationType: operation.Type,
+			RuntimeID:     instance.RuntimeID,
+			Parameters:    instance.Parameters,
+			CreatedAt:     operation.UpdatedAt,
+		})
+		if dberr.IsConflict(err) {
+			log.Info(fmt.Sprintf("the snapshot version is taken by another snapshot, retrying: %s", err))
+			return false, nil
+		}
+		return true, err
+	})
+	switch {
+	case dberr.IsAlreadyExists(err):
+		log.Info("the snapshot of the operation already exists, skipping")
+		return nil
+	case err != nil:
+		return fmt.Errorf("while storing the snapshot of the operation %s: %w", operation.ID, err)
+	}
+	log.Info(fmt.Sprintf("stored the snapshot version %d", snapshot.Version))
+	return nil
+}
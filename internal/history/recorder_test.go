@@ -0,0 +1,57 @@
+package history_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/history"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_RetriesVersionConflict(t *testing.T) {
+	// given
+	db := storage.NewMemoryStorage()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	operation := fixture.FixProvisioningOperation("op-1", instanceID)
+	require.NoError(t, db.Operations().InsertOperation(operation))
+	require.NoError(t, db.Instances().Insert(fixture.FixInstance(instanceID)))
+	snapshots := &conflictingSnapshots{InstanceSnapshots: db.InstanceSnapshots(), conflicts: 1}
+	recorder := history.NewRecorder(db.Operations(), db.Instances(), snapshots, logger)
+
+	// when
+	err := recorder.OnOperationSucceeded(context.Background(), process.OperationSucceeded{Operation: operation})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, snapshots.inserts)
+	stored, err := db.InstanceSnapshots().ListByInstanceID(instanceID)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "op-1", stored[0].OperationID)
+}
+
+// conflictingSnapshots fails the given number of inserts as if a concurrent insert took the version
+type conflictingSnapshots struct {
+	storage.InstanceSnapshots
+	conflicts int
+	inserts   int
+}
+
+func (s *conflictingSnapshots) Insert(snapshot internal.InstanceSnapshot) (*internal.InstanceSnapshot, error) {
+	s.inserts++
+	if s.inserts <= s.conflicts {
+		return nil, dberr.Conflict("snapshot version of the instance %s is already taken", snapshot.InstanceID)
+	}
+	return s.InstanceSnapshots.Insert(snapshot)
+}
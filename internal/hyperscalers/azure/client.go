@@ -0,0 +1,232 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	interval = time.Second
+	retries  = 5
+
+	managementURL   = "https://management.azure.com"
+	managementScope = "https://management.azure.com/.default"
+	tokenURLFormat  = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+	skusAPIVersion  = "2021-07-01"
+
+	virtualMachinesResourceType = "virtualMachines"
+	zoneRestriction             = "Zone"
+	locationRestriction         = "Location"
+)
+
+type Credentials struct {
+	ClientID       string
+	ClientSecret   string
+	SubscriptionID string
+	TenantID       string
+}
+
+type ClientFactory interface {
+	New(ctx context.Context, credentials Credentials, region string) (Client, error)
+}
+
+type Client interface {
+	AvailableZones(ctx context.Context, machineType string) ([]string, error)
+	AvailableZonesCount(ctx context.Context, machineType string) (int, error)
+}
+
+func NewFactory() ClientFactory {
+	return AzureClientFactory{}
+}
+
+type AzureClientFactory struct{}
+
+func (AzureClientFactory) New(ctx context.Context, credentials Credentials, region string) (Client, error) {
+	return NewClient(ctx, credentials, region)
+}
+
+type AzureClient struct {
+	httpClient     *http.Client
+	baseURL        string
+	subscriptionID string
+	region         string
+}
+
+func NewClient(ctx context.Context, credentials Credentials, region string) (*AzureClient, error) {
+	cfg := &clientcredentials.Config{
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+		TokenURL:     fmt.Sprintf(tokenURLFormat, credentials.TenantID),
+		Scopes:       []string{managementScope},
+	}
+	return newClient(cfg.Client(ctx), managementURL, credentials.SubscriptionID, region), nil
+}
+
+func newClient(httpClient *http.Client, baseURL, subscriptionID, region string) *AzureClient {
+	return &AzureClient{
+		httpClient:     httpClient,
+		baseURL:        baseURL,
+		subscriptionID: subscriptionID,
+		region:         region,
+	}
+}
+
+type resourceSKUsResponse struct {
+	Value    []resourceSKU `json:"value"`
+	NextLink string        `json:"nextLink"`
+}
+
+type resourceSKU struct {
+	ResourceType string `json:"resourceType"`
+	Name         string `json:"name"`
+	LocationInfo []struct {
+		Location string   `json:"location"`
+		Zones    []string `json:"zones"`
+	} `json:"locationInfo"`
+	Restrictions []struct {
+		Type            string `json:"type"`
+		RestrictionInfo struct {
+			Locations []string `json:"locations"`
+			Zones     []string `json:"zones"`
+		} `json:"restrictionInfo"`
+	} `json:"restrictions"`
+}
+
+// AvailableZones returns the zones of the region in which the VM size is offered and not restricted for the subscription
+func (c *AzureClient) AvailableZones(ctx context.Context, machineType string) ([]string, error) {
+	query := url.Values{
+		"api-version": []string{skusAPIVersion},
+		"$filter":     []string{fmt.Sprintf("location eq '%s'", c.region)},
+	}
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Compute/skus?%s", c.baseURL, c.subscriptionID, query.Encode())
+
+	zones := make([]string, 0)
+	for next != "" {
+		var page resourceSKUsResponse
+		if err := c.get(ctx, next, &page); err != nil {
+			return nil, fmt.Errorf("failed to list resource SKUs: %w", err)
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType != virtualMachinesResourceType || !strings.EqualFold(sku.Name, machineType) {
+				continue
+			}
+			zones = append(zones, c.unrestrictedZones(sku)...)
+		}
+		next = page.NextLink
+	}
+	sort.Strings(zones)
+
+	return slices.Compact(zones), nil
+}
+
+func (c *AzureClient) unrestrictedZones(sku resourceSKU) []string {
+	var zones []string
+	for _, info := range sku.LocationInfo {
+		if strings.EqualFold(info.Location, c.region) {
+			zones = append(zones, info.Zones...)
+		}
+	}
+	for _, restriction := range sku.Restrictions {
+		switch restriction.Type {
+		case locationRestriction:
+			if slices.ContainsFunc(restriction.RestrictionInfo.Locations, func(location string) bool {
+				return strings.EqualFold(location, c.region)
+			}) {
+				return nil
+			}
+		case zoneRestriction:
+			zones = slices.DeleteFunc(zones, func(zone string) bool {
+				return slices.Contains(restriction.RestrictionInfo.Zones, zone)
+			})
+		}
+	}
+	return zones
+}
+
+func (c *AzureClient) AvailableZonesCount(ctx context.Context, machineType string) (int, error) {
+	zones, err := c.AvailableZones(ctx, machineType)
+	if err != nil {
+		return 0, err
+	}
+	return len(zones), nil
+}
+
+func (c *AzureClient) get(ctx context.Context, u string, out any) error {
+	var err error
+	for i := 0; i < retries; i++ {
+		var retry bool
+		retry, err = c.doGet(ctx, u, out)
+		if err == nil || !retry {
+			break
+		}
+		time.Sleep(interval)
+	}
+	return err
+}
+
+func (c *AzureClient) doGet(ctx context.Context, u string, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retry, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return false, fmt.Errorf("while unmarshalling response: %w", err)
+	}
+	return false, nil
+}
+
+func ExtractCredentials(secret *unstructured.Unstructured) (Credentials, error) {
+	data, found, err := unstructured.NestedStringMap(secret.Object, "data")
+	if err != nil {
+		return Credentials{}, fmt.Errorf("unable to extract data from secret: %w", err)
+	}
+	if !found {
+		return Credentials{}, fmt.Errorf("secret does not contain data")
+	}
+
+	decoded := make(map[string]string)
+	for _, key := range []string{"clientID", "clientSecret", "subscriptionID", "tenantID"} {
+		value, ok := data[key]
+		if !ok {
+			return Credentials{}, fmt.Errorf("secret does not contain %s", key)
+		}
+		valueBytes, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		decoded[key] = string(valueBytes)
+	}
+
+	return Credentials{
+		ClientID:       decoded["clientID"],
+		ClientSecret:   decoded["clientSecret"],
+		SubscriptionID: decoded["subscriptionID"],
+		TenantID:       decoded["tenantID"],
+	}, nil
+}
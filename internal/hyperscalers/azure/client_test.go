@@ -0,0 +1,146 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	firstPage = `{
+  "value": [
+    {"resourceType": "disks", "name": "Standard_D4s_v5", "locationInfo": [{"location": "westeurope", "zones": ["1", "2", "3"]}]},
+    {"resourceType": "virtualMachines", "name": "Standard_D4s_v5", "locationInfo": [{"location": "westeurope", "zones": ["3", "1", "2"]}]},
+    {"resourceType": "virtualMachines", "name": "Standard_NC4as_T4_v3", "locationInfo": [{"location": "westeurope", "zones": ["1", "2", "3"]}],
+     "restrictions": [{"type": "Zone", "reasonCode": "NotAvailableForSubscription", "restrictionInfo": {"locations": ["westeurope"], "zones": ["2"]}}]}
+  ],
+  "nextLink": "%s/subscriptions/test-subscription/providers/Microsoft.Compute/skus?page=2"
+}`
+	secondPage = `{
+  "value": [
+    {"resourceType": "virtualMachines", "name": "Standard_E4s_v5", "locationInfo": [{"location": "westeurope", "zones": ["1", "2", "3"]}],
+     "restrictions": [{"type": "Location", "reasonCode": "NotAvailableForSubscription", "restrictionInfo": {"locations": ["westeurope"]}}]}
+  ]
+}`
+)
+
+func newFakeManagementServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subscriptions/test-subscription/providers/Microsoft.Compute/skus" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"error": {"code": "SubscriptionNotFound"}}`)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = fmt.Fprint(w, secondPage)
+			return
+		}
+		assert.Equal(t, "location eq 'westeurope'", r.URL.Query().Get("$filter"))
+		_, _ = fmt.Fprintf(w, firstPage, server.URL)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAvailableZones(t *testing.T) {
+	server := newFakeManagementServer(t)
+	client := newClient(server.Client(), server.URL, "test-subscription", "westeurope")
+
+	for tn, tc := range map[string]struct {
+		machineType string
+		expected    []string
+	}{
+		"offered in all zones": {
+			machineType: "Standard_D4s_v5",
+			expected:    []string{"1", "2", "3"},
+		},
+		"zone restricted for the subscription": {
+			machineType: "Standard_NC4as_T4_v3",
+			expected:    []string{"1", "3"},
+		},
+		"location restricted for the subscription": {
+			machineType: "Standard_E4s_v5",
+			expected:    []string{},
+		},
+		"not offered": {
+			machineType: "Standard_M8ms",
+			expected:    []string{},
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			zones, err := client.AvailableZones(context.Background(), tc.machineType)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, zones)
+		})
+	}
+}
+
+func TestAvailableZones_Error(t *testing.T) {
+	// given
+	server := newFakeManagementServer(t)
+	client := newClient(server.Client(), server.URL, "other-subscription", "westeurope")
+
+	// when
+	count, err := client.AvailableZonesCount(context.Background(), "Standard_D4s_v5")
+
+	// then
+	assert.ErrorContains(t, err, "failed to list resource SKUs: unexpected status code 404")
+	assert.Zero(t, count)
+}
+
+func TestExtractCredentials(t *testing.T) {
+	encode := func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	t.Run("should extract credentials", func(t *testing.T) {
+		// given
+		secret := &unstructured.Unstructured{Object: map[string]interface{}{
+			"data": map[string]interface{}{
+				"clientID":       encode("client-id"),
+				"clientSecret":   encode("client-secret"),
+				"subscriptionID": encode("subscription-id"),
+				"tenantID":       encode("tenant-id"),
+			},
+		}}
+
+		// when
+		credentials, err := ExtractCredentials(secret)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Credentials{
+			ClientID:       "client-id",
+			ClientSecret:   "client-secret",
+			SubscriptionID: "subscription-id",
+			TenantID:       "tenant-id",
+		}, credentials)
+	})
+
+	t.Run("should fail when a key is missing", func(t *testing.T) {
+		// given
+		secret := &unstructured.Unstructured{Object: map[string]interface{}{
+			"data": map[string]interface{}{
+				"clientID":     encode("client-id"),
+				"clientSecret": encode("client-secret"),
+			},
+		}}
+
+		// when
+		_, err := ExtractCredentials(secret)
+
+		// then
+		assert.EqualError(t, err, "secret does not contain subscriptionID")
+	})
+}
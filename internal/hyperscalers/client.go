@@ -0,0 +1,78 @@
+package hyperscalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/azure"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/gcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ErrInvalidCredentials is returned when the subscription secret does not contain credentials of the provider,
+// creating the client again with the same secret does not help
+var ErrInvalidCredentials = errors.New("invalid hyperscaler credentials")
+
+type ClientFactory interface {
+	New(ctx context.Context, provider runtime.CloudProvider, secret *unstructured.Unstructured, region string) (Client, error)
+}
+
+type Client interface {
+	AvailableZones(ctx context.Context, machineType string) ([]string, error)
+	AvailableZonesCount(ctx context.Context, machineType string) (int, error)
+}
+
+// SupportsZonesDiscovery returns true for the providers which zones can be discovered
+func SupportsZonesDiscovery(provider runtime.CloudProvider) bool {
+	switch provider {
+	case runtime.AWS, runtime.GCP, runtime.Azure:
+		return true
+	}
+	return false
+}
+
+func NewFactory() ClientFactory {
+	return NewFactoryWithProviders(aws.NewFactory(), gcp.NewFactory(), azure.NewFactory())
+}
+
+func NewFactoryWithProviders(awsFactory aws.ClientFactory, gcpFactory gcp.ClientFactory, azureFactory azure.ClientFactory) ClientFactory {
+	return &clientFactory{
+		aws:   awsFactory,
+		gcp:   gcpFactory,
+		azure: azureFactory,
+	}
+}
+
+// clientFactory creates the client of the provider with credentials extracted from the subscription secret
+type clientFactory struct {
+	aws   aws.ClientFactory
+	gcp   gcp.ClientFactory
+	azure azure.ClientFactory
+}
+
+func (f *clientFactory) New(ctx context.Context, provider runtime.CloudProvider, secret *unstructured.Unstructured, region string) (Client, error) {
+	switch provider {
+	case runtime.AWS:
+		accessKeyID, secretAccessKey, err := aws.ExtractCredentials(secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to extract AWS credentials: %w", ErrInvalidCredentials, err)
+		}
+		return f.aws.New(ctx, accessKeyID, secretAccessKey, region)
+	case runtime.GCP:
+		serviceAccountJSON, err := gcp.ExtractCredentials(secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to extract GCP credentials: %w", ErrInvalidCredentials, err)
+		}
+		return f.gcp.New(ctx, serviceAccountJSON, region)
+	case runtime.Azure:
+		credentials, err := azure.ExtractCredentials(secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to extract Azure credentials: %w", ErrInvalidCredentials, err)
+		}
+		return f.azure.New(ctx, credentials, region)
+	}
+	return nil, fmt.Errorf("zones discovery is not supported for the %s provider", provider)
+}
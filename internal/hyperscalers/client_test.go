@@ -0,0 +1,99 @@
+package hyperscalers
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/azure"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/gcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type fakeClient struct {
+	zones []string
+}
+
+func (f fakeClient) AvailableZones(ctx context.Context, machineType string) ([]string, error) {
+	return f.zones, nil
+}
+
+func (f fakeClient) AvailableZonesCount(ctx context.Context, machineType string) (int, error) {
+	return len(f.zones), nil
+}
+
+type fakeAWSFactory struct{}
+
+func (fakeAWSFactory) New(ctx context.Context, accessKeyID, secretAccessKey, region string) (aws.Client, error) {
+	return fakeClient{zones: []string{region + "a"}}, nil
+}
+
+type fakeGCPFactory struct{}
+
+func (fakeGCPFactory) New(ctx context.Context, serviceAccountJSON []byte, region string) (gcp.Client, error) {
+	return fakeClient{zones: []string{region + "-a"}}, nil
+}
+
+type fakeAzureFactory struct{}
+
+func (fakeAzureFactory) New(ctx context.Context, credentials azure.Credentials, region string) (azure.Client, error) {
+	return fakeClient{zones: []string{"1"}}, nil
+}
+
+func secretWithData(data map[string]string) *unstructured.Unstructured {
+	encoded := make(map[string]interface{})
+	for key, value := range data {
+		encoded[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{"data": encoded}}
+}
+
+func TestClientFactory_New(t *testing.T) {
+	factory := NewFactoryWithProviders(fakeAWSFactory{}, fakeGCPFactory{}, fakeAzureFactory{})
+	awsSecret := secretWithData(map[string]string{"accessKeyID": "key", "secretAccessKey": "secret"})
+	gcpSecret := secretWithData(map[string]string{"serviceaccount.json": `{"project_id": "test"}`})
+	azureSecret := secretWithData(map[string]string{"clientID": "id", "clientSecret": "secret", "subscriptionID": "sub", "tenantID": "tenant"})
+
+	for tn, tc := range map[string]struct {
+		provider runtime.CloudProvider
+		secret   *unstructured.Unstructured
+		region   string
+		expected []string
+	}{
+		"AWS":   {provider: runtime.AWS, secret: awsSecret, region: "eu-central-1", expected: []string{"eu-central-1a"}},
+		"GCP":   {provider: runtime.GCP, secret: gcpSecret, region: "europe-west3", expected: []string{"europe-west3-a"}},
+		"Azure": {provider: runtime.Azure, secret: azureSecret, region: "westeurope", expected: []string{"1"}},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// when
+			client, err := factory.New(context.Background(), tc.provider, tc.secret, tc.region)
+
+			// then
+			require.NoError(t, err)
+			zones, err := client.AvailableZones(context.Background(), "any")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, zones)
+		})
+	}
+
+	t.Run("should return invalid credentials error for a secret of another provider", func(t *testing.T) {
+		// when
+		_, err := factory.New(context.Background(), runtime.GCP, awsSecret, "europe-west3")
+
+		// then
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("should fail for an unsupported provider", func(t *testing.T) {
+		// when
+		_, err := factory.New(context.Background(), runtime.SapConvergedCloud, awsSecret, "eu-de-1")
+
+		// then
+		assert.EqualError(t, err, "zones discovery is not supported for the SapConvergedCloud provider")
+		assert.NotErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
@@ -0,0 +1,205 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"golang.org/x/oauth2/jwt"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	interval = time.Second
+	retries  = 5
+
+	computeURL      = "https://compute.googleapis.com/compute/v1"
+	computeScope    = "https://www.googleapis.com/auth/compute.readonly"
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+
+	serviceAccountKey = "serviceaccount.json"
+)
+
+type ClientFactory interface {
+	New(ctx context.Context, serviceAccountJSON []byte, region string) (Client, error)
+}
+
+type Client interface {
+	AvailableZones(ctx context.Context, machineType string) ([]string, error)
+	AvailableZonesCount(ctx context.Context, machineType string) (int, error)
+}
+
+func NewFactory() ClientFactory {
+	return GCPClientFactory{}
+}
+
+type GCPClientFactory struct{}
+
+func (GCPClientFactory) New(ctx context.Context, serviceAccountJSON []byte, region string) (Client, error) {
+	return NewClient(ctx, serviceAccountJSON, region)
+}
+
+type GCPClient struct {
+	httpClient *http.Client
+	baseURL    string
+	project    string
+	region     string
+}
+
+type serviceAccount struct {
+	ProjectID    string `json:"project_id"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+func NewClient(ctx context.Context, serviceAccountJSON []byte, region string) (*GCPClient, error) {
+	var sa serviceAccount
+	if err := json.Unmarshal(serviceAccountJSON, &sa); err != nil {
+		return nil, fmt.Errorf("while unmarshalling service account: %w", err)
+	}
+	if sa.ProjectID == "" || sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("service account must contain project_id, client_email and private_key")
+	}
+	tokenURL := sa.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	cfg := &jwt.Config{
+		Email:        sa.ClientEmail,
+		PrivateKey:   []byte(sa.PrivateKey),
+		PrivateKeyID: sa.PrivateKeyID,
+		Scopes:       []string{computeScope},
+		TokenURL:     tokenURL,
+	}
+	return newClient(cfg.Client(ctx), computeURL, sa.ProjectID, region), nil
+}
+
+func newClient(httpClient *http.Client, baseURL, project, region string) *GCPClient {
+	return &GCPClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		project:    project,
+		region:     region,
+	}
+}
+
+type regionResponse struct {
+	Zones []string `json:"zones"`
+}
+
+type machineTypesResponse struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+// AvailableZones lists the machine types with the given name in every zone of the region, the machine type
+// is available in the zones which return it
+func (c *GCPClient) AvailableZones(ctx context.Context, machineType string) ([]string, error) {
+	var region regionResponse
+	if err := c.get(ctx, fmt.Sprintf("/projects/%s/regions/%s", c.project, c.region), nil, &region); err != nil {
+		return nil, fmt.Errorf("failed to get region %s: %w", c.region, err)
+	}
+
+	zones := make([]string, 0, len(region.Zones))
+	for _, zoneURL := range region.Zones {
+		// zones are returned as URLs, the zone name is the last segment
+		zone := path.Base(zoneURL)
+		var machineTypes machineTypesResponse
+		query := url.Values{"filter": []string{fmt.Sprintf("name = %q", machineType)}}
+		if err := c.get(ctx, fmt.Sprintf("/projects/%s/zones/%s/machineTypes", c.project, zone), query, &machineTypes); err != nil {
+			return nil, fmt.Errorf("failed to list machine types in zone %s: %w", zone, err)
+		}
+		for _, item := range machineTypes.Items {
+			if item.Name == machineType {
+				zones = append(zones, zone)
+				break
+			}
+		}
+	}
+	sort.Strings(zones)
+
+	return zones, nil
+}
+
+func (c *GCPClient) AvailableZonesCount(ctx context.Context, machineType string) (int, error) {
+	zones, err := c.AvailableZones(ctx, machineType)
+	if err != nil {
+		return 0, err
+	}
+	return len(zones), nil
+}
+
+func (c *GCPClient) get(ctx context.Context, resource string, query url.Values, out any) error {
+	u := c.baseURL + resource
+	if len(query) > 0 {
+		u = u + "?" + query.Encode()
+	}
+
+	var err error
+	for i := 0; i < retries; i++ {
+		var retry bool
+		retry, err = c.doGet(ctx, u, out)
+		if err == nil || !retry {
+			break
+		}
+		time.Sleep(interval)
+	}
+	return err
+}
+
+func (c *GCPClient) doGet(ctx context.Context, u string, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retry, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return false, fmt.Errorf("while unmarshalling response: %w", err)
+	}
+	return false, nil
+}
+
+func ExtractCredentials(secret *unstructured.Unstructured) ([]byte, error) {
+	data, found, err := unstructured.NestedStringMap(secret.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("unable to extract data from secret: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("secret does not contain data")
+	}
+
+	serviceAccountJSON, ok := data[serviceAccountKey]
+	if !ok {
+		return nil, fmt.Errorf("secret does not contain %s", serviceAccountKey)
+	}
+
+	serviceAccountJSONBytes, err := base64.StdEncoding.DecodeString(serviceAccountJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", serviceAccountKey, err)
+	}
+
+	return serviceAccountJSONBytes, nil
+}
@@ -0,0 +1,124 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFakeComputeServer(t *testing.T, machineTypesByZone map[string][]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/test-project/regions/europe-west3", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"zones": [
+			"https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west3-c",
+			"https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west3-a",
+			"https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west3-b"
+		]}`)
+	})
+	mux.HandleFunc("GET /projects/test-project/zones/{zone}/machineTypes", func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("filter")
+		items := ""
+		for _, machineType := range machineTypesByZone[r.PathValue("zone")] {
+			if filter == fmt.Sprintf("name = %q", machineType) {
+				items = fmt.Sprintf(`{"name": %q}`, machineType)
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"items": [%s]}`, items)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAvailableZones_Success(t *testing.T) {
+	// given
+	server := newFakeComputeServer(t, map[string][]string{
+		"europe-west3-a": {"n2-standard-4", "g2-standard-8"},
+		"europe-west3-b": {"n2-standard-4"},
+		"europe-west3-c": {"n2-standard-4", "g2-standard-8"},
+	})
+	client := newClient(server.Client(), server.URL, "test-project", "europe-west3")
+
+	// when
+	zones, err := client.AvailableZones(context.Background(), "g2-standard-8")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"europe-west3-a", "europe-west3-c"}, zones)
+
+	count, err := client.AvailableZonesCount(context.Background(), "n2-standard-4")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestAvailableZones_NotOffered(t *testing.T) {
+	// given
+	server := newFakeComputeServer(t, map[string][]string{})
+	client := newClient(server.Client(), server.URL, "test-project", "europe-west3")
+
+	// when
+	zones, err := client.AvailableZones(context.Background(), "g2-standard-8")
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, zones)
+}
+
+func TestAvailableZones_Error(t *testing.T) {
+	// given
+	server := newFakeComputeServer(t, map[string][]string{})
+	client := newClient(server.Client(), server.URL, "test-project", "us-east1")
+
+	// when
+	zones, err := client.AvailableZones(context.Background(), "g2-standard-8")
+
+	// then
+	assert.ErrorContains(t, err, "failed to get region us-east1: unexpected status code 404")
+	assert.Nil(t, zones)
+}
+
+func TestExtractCredentials(t *testing.T) {
+	t.Run("should extract the service account", func(t *testing.T) {
+		// given
+		secret := &unstructured.Unstructured{Object: map[string]interface{}{
+			"data": map[string]interface{}{
+				"serviceaccount.json": base64.StdEncoding.EncodeToString([]byte(`{"project_id": "test-project"}`)),
+			},
+		}}
+
+		// when
+		serviceAccountJSON, err := ExtractCredentials(secret)
+
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"project_id": "test-project"}`, string(serviceAccountJSON))
+	})
+
+	t.Run("should fail without the service account", func(t *testing.T) {
+		// given
+		secret := &unstructured.Unstructured{Object: map[string]interface{}{
+			"data": map[string]interface{}{
+				"accessKeyID": base64.StdEncoding.EncodeToString([]byte("key")),
+			},
+		}}
+
+		// when
+		_, err := ExtractCredentials(secret)
+
+		// then
+		assert.EqualError(t, err, "secret does not contain serviceaccount.json")
+	})
+}
+
+func TestNewClient_InvalidServiceAccount(t *testing.T) {
+	_, err := NewClient(context.Background(), []byte(`{"project_id": "test-project"}`), "europe-west3")
+
+	assert.EqualError(t, err, "service account must contain project_id, client_email and private_key")
+}
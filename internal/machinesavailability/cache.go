@@ -0,0 +1,125 @@
+package machinesavailability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type Config struct {
+	// CacheTTL is the time for which discovered zones are returned without asking the hyperscaler
+	CacheTTL time.Duration `envconfig:"default=1h"`
+	// StaleTTL is the time after CacheTTL for which discovered zones are still returned while they are refreshed in the background
+	StaleTTL time.Duration `envconfig:"default=24h"`
+	// RefreshInterval is the interval of refreshing zones of all configured machine types, 0 disables the refresher
+	RefreshInterval time.Duration `envconfig:"default=30m"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("(CacheTTL=%s; StaleTTL=%s; RefreshInterval=%s)", c.CacheTTL, c.StaleTTL, c.RefreshInterval)
+}
+
+type cacheKey struct {
+	provider    runtime.CloudProvider
+	region      string
+	machineType string
+}
+
+func (k cacheKey) String() string {
+	return fmt.Sprintf("%s/%s/%s", k.provider, k.region, k.machineType)
+}
+
+type cacheEntry struct {
+	zones      []string
+	updatedAt  time.Time
+	refreshing bool
+}
+
+type fetchFunc func(ctx context.Context, key cacheKey) ([]string, error)
+
+// zonesCache keeps zones discovered by hyperscaler APIs, stale zones are returned while they are refreshed
+// in the background, so callers wait for the hyperscaler only when zones are missing or expired,
+// concurrent loads of the same key share one hyperscaler call
+type zonesCache struct {
+	mu       sync.Mutex
+	loads    singleflight.Group
+	entries  map[cacheKey]*cacheEntry
+	ttl      time.Duration
+	staleTTL time.Duration
+	fetch    fetchFunc
+	now      func() time.Time
+	log      *slog.Logger
+}
+
+func newZonesCache(cfg Config, fetch fetchFunc, log *slog.Logger) *zonesCache {
+	return &zonesCache{
+		entries:  make(map[cacheKey]*cacheEntry),
+		ttl:      cfg.CacheTTL,
+		staleTTL: cfg.StaleTTL,
+		fetch:    fetch,
+		now:      time.Now,
+		log:      log,
+	}
+}
+
+func (c *zonesCache) Get(ctx context.Context, key cacheKey) ([]string, time.Time, error) {
+	c.mu.Lock()
+	if entry, found := c.entries[key]; found {
+		age := c.now().Sub(entry.updatedAt)
+		if age < c.ttl {
+			c.mu.Unlock()
+			return entry.zones, entry.updatedAt, nil
+		}
+		if age < c.ttl+c.staleTTL {
+			if !entry.refreshing {
+				entry.refreshing = true
+				go c.revalidate(key)
+			}
+			c.mu.Unlock()
+			return entry.zones, entry.updatedAt, nil
+		}
+	}
+	c.mu.Unlock()
+
+	return c.Load(ctx, key)
+}
+
+// Load fetches zones from the hyperscaler and stores them in the cache
+func (c *zonesCache) Load(ctx context.Context, key cacheKey) ([]string, time.Time, error) {
+	result, err, _ := c.loads.Do(key.String(), func() (interface{}, error) {
+		return c.load(ctx, key)
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	entry := result.(*cacheEntry)
+	return entry.zones, entry.updatedAt, nil
+}
+
+func (c *zonesCache) load(ctx context.Context, key cacheKey) (*cacheEntry, error) {
+	zones, err := c.fetch(ctx, key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if entry, found := c.entries[key]; found {
+			entry.refreshing = false
+		}
+		return nil, err
+	}
+	entry := &cacheEntry{zones: zones, updatedAt: c.now()}
+	c.entries[key] = entry
+	return entry, nil
+}
+
+func (c *zonesCache) revalidate(key cacheKey) {
+	if _, _, err := c.Load(context.Background(), key); err != nil {
+		c.log.Warn(fmt.Sprintf("unable to refresh zones of the machine type %s in the %s region of %s: %s", key.machineType, key.region, key.provider, err))
+	}
+}
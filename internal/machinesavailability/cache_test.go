@@ -0,0 +1,186 @@
+package machinesavailability
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedTime = time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+
+func fixedNow() time.Time {
+	return fixedTime
+}
+
+type fakeFetcher struct {
+	mu    sync.Mutex
+	calls int
+	zones []string
+	err   error
+	done  chan struct{}
+	// release, when set, blocks fetches until it is closed
+	release chan struct{}
+}
+
+func (f *fakeFetcher) fetch(ctx context.Context, key cacheKey) ([]string, error) {
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.done != nil {
+		defer func() { f.done <- struct{}{} }()
+	}
+	return f.zones, f.err
+}
+
+func (f *fakeFetcher) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestZonesCache(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	key := cacheKey{provider: runtime.AWS, region: "eu-central-1", machineType: "g6.xlarge"}
+	cfg := Config{CacheTTL: time.Hour, StaleTTL: 24 * time.Hour}
+
+	t.Run("should fetch missing zones once and return them while fresh", func(t *testing.T) {
+		// given
+		fetcher := &fakeFetcher{zones: []string{"eu-central-1a"}}
+		cache := newZonesCache(cfg, fetcher.fetch, log)
+		cache.now = fixedNow
+
+		// when
+		_, _, err := cache.Get(context.Background(), key)
+		require.NoError(t, err)
+		cache.now = func() time.Time { return fixedTime.Add(59 * time.Minute) }
+		zones, updatedAt, err := cache.Get(context.Background(), key)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eu-central-1a"}, zones)
+		assert.Equal(t, fixedTime, updatedAt)
+		assert.Equal(t, 1, fetcher.callCount())
+	})
+
+	t.Run("should return stale zones and refresh them in the background", func(t *testing.T) {
+		// given
+		fetcher := &fakeFetcher{zones: []string{"eu-central-1a"}}
+		cache := newZonesCache(cfg, fetcher.fetch, log)
+		cache.now = fixedNow
+		_, _, err := cache.Get(context.Background(), key)
+		require.NoError(t, err)
+		fetcher.zones = []string{"eu-central-1a", "eu-central-1b"}
+		fetcher.done = make(chan struct{}, 1)
+		cache.now = func() time.Time { return fixedTime.Add(2 * time.Hour) }
+
+		// when
+		zones, updatedAt, err := cache.Get(context.Background(), key)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eu-central-1a"}, zones)
+		assert.Equal(t, fixedTime, updatedAt)
+
+		select {
+		case <-fetcher.done:
+		case <-time.After(time.Second):
+			t.Fatal("zones were not refreshed")
+		}
+		require.Eventually(t, func() bool {
+			zones, _, _ := cache.Get(context.Background(), key)
+			return len(zones) == 2
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, 2, fetcher.callCount())
+	})
+
+	t.Run("should fetch expired zones", func(t *testing.T) {
+		// given
+		fetcher := &fakeFetcher{zones: []string{"eu-central-1a"}}
+		cache := newZonesCache(cfg, fetcher.fetch, log)
+		cache.now = fixedNow
+		_, _, err := cache.Get(context.Background(), key)
+		require.NoError(t, err)
+		fetcher.zones = []string{"eu-central-1b"}
+		expired := fixedTime.Add(26 * time.Hour)
+		cache.now = func() time.Time { return expired }
+
+		// when
+		zones, updatedAt, err := cache.Get(context.Background(), key)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"eu-central-1b"}, zones)
+		assert.Equal(t, expired, updatedAt)
+		assert.Equal(t, 2, fetcher.callCount())
+	})
+
+	t.Run("should fetch missing zones once for concurrent callers", func(t *testing.T) {
+		// given
+		fetcher := &fakeFetcher{zones: []string{"eu-central-1a"}, release: make(chan struct{})}
+		cache := newZonesCache(cfg, fetcher.fetch, log)
+		cache.now = fixedNow
+
+		// when
+		var wg sync.WaitGroup
+		results := make([][]string, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _, _ = cache.Get(context.Background(), key)
+			}(i)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(fetcher.release)
+		wg.Wait()
+
+		// then
+		for _, zones := range results {
+			assert.Equal(t, []string{"eu-central-1a"}, zones)
+		}
+		assert.Equal(t, 1, fetcher.callCount())
+	})
+
+	t.Run("should return the error of missing zones", func(t *testing.T) {
+		// given
+		fetcher := &fakeFetcher{err: fmt.Errorf("rate limit exceeded")}
+		cache := newZonesCache(cfg, fetcher.fetch, log)
+
+		// when
+		_, _, err := cache.Get(context.Background(), key)
+
+		// then
+		assert.EqualError(t, err, "rate limit exceeded")
+	})
+}
+
+func TestMachineFamily(t *testing.T) {
+	for _, tc := range []struct {
+		provider    runtime.CloudProvider
+		machineType string
+		expected    string
+	}{
+		{provider: runtime.AWS, machineType: "g6.2xlarge", expected: "g6"},
+		{provider: runtime.GCP, machineType: "n2-standard-16", expected: "n2-standard"},
+		{provider: runtime.Azure, machineType: "Standard_D16s_v5", expected: "Standard_Ds_v5"},
+		{provider: runtime.Azure, machineType: "Standard_NC4as_T4_v3", expected: "Standard_NCas_T4_v3"},
+		{provider: runtime.SapConvergedCloud, machineType: "g_c4_m16", expected: "g"},
+		{provider: runtime.Alicloud, machineType: "ecs.g8i.large", expected: "ecs.g8i"},
+	} {
+		t.Run(tc.machineType, func(t *testing.T) {
+			assert.Equal(t, tc.expected, machineFamily(tc.provider, tc.machineType))
+		})
+	}
+}
@@ -5,16 +5,20 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/subscriptions"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 const (
@@ -22,6 +26,13 @@ const (
 	highAvailabilityThreshold = 3
 )
 
+var (
+	supportedProviders = []runtime.CloudProvider{runtime.AWS, runtime.GCP, runtime.Azure, runtime.SapConvergedCloud, runtime.Alicloud}
+
+	// azureMachineSize matches the number of vCPUs in Azure VM sizes, for example, Standard_D4s_v5
+	azureMachineSize = regexp.MustCompile(`^(Standard_[A-Za-z]+)\d+(.*)$`)
+)
+
 type ProvidersData struct {
 	Providers []Provider `json:"providers"`
 }
@@ -39,13 +50,25 @@ type MachineType struct {
 type Region struct {
 	Name             string `json:"name"`
 	HighAvailability bool   `json:"high_availability"`
+	// Zones are the zones in which the machine type is available
+	Zones []string `json:"zones"`
+	// UpdatedAt is the time zones were discovered, it is empty for zones from the providers configuration
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// secretResolver returns the subscription secret used to query the hyperscaler of the provider
+type secretResolver interface {
+	subscriptionSecret(provider string) (*unstructured.Unstructured, error)
 }
 
 type Handler struct {
 	providerSpec   *configuration.ProviderSpec
 	rulesService   *rules.RulesService
 	gardenerClient *gardener.Client
-	clientFactory  aws.ClientFactory
+	clientFactory  hyperscalers.ClientFactory
+	secrets        secretResolver
+	cache          *zonesCache
+	cfg            Config
 	logger         *slog.Logger
 }
 
@@ -53,125 +76,253 @@ func NewHandler(
 	providerSpec *configuration.ProviderSpec,
 	rulesService *rules.RulesService,
 	gardenerClient *gardener.Client,
-	clientFactory aws.ClientFactory,
+	clientFactory hyperscalers.ClientFactory,
+	cfg Config,
+	logger *slog.Logger,
+) *Handler {
+	h := newHandler(providerSpec, rulesService, gardenerClient, clientFactory, cfg, logger)
+	h.secrets = &secretBindingResolver{h}
+	return h
+}
+
+func newHandler(
+	providerSpec *configuration.ProviderSpec,
+	rulesService *rules.RulesService,
+	gardenerClient *gardener.Client,
+	clientFactory hyperscalers.ClientFactory,
+	cfg Config,
 	logger *slog.Logger,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		providerSpec:   providerSpec,
 		rulesService:   rulesService,
 		gardenerClient: gardenerClient,
 		clientFactory:  clientFactory,
+		cfg:            cfg,
 		logger:         logger.With("service", "MachinesAvailabilityHandler"),
 	}
+	h.cache = newZonesCache(cfg, h.discoverZones, h.logger)
+	return h
 }
 
 func (h *Handler) AttachRoutes(router *httputil.Router) {
 	router.HandleFunc(machinesAvailabilityPath, h.getMachinesAvailability)
 }
 
-func (h *Handler) getMachinesAvailability(w http.ResponseWriter, req *http.Request) {
-	supportedProviders := []runtime.CloudProvider{runtime.AWS}
-	var providersData ProvidersData
-
-	for _, provider := range supportedProviders {
-		providerEntry := Provider{
-			Name:         provider,
-			MachineTypes: []MachineType{},
-		}
-
-		regionSupportingMachine, err := h.providerSpec.RegionSupportingMachine(string(provider))
-		if err != nil {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+// Run refreshes discovered zones of all configured machine types periodically, so requests are served from the cache
+func (h *Handler) Run(ctx context.Context) {
+	if h.cfg.RefreshInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(h.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		h.refresh(ctx)
+		select {
+		case <-ctx.Done():
 			return
+		case <-ticker.C:
 		}
+	}
+}
 
-		accessKeyID, secretAccessKey, err := h.clientCredentials(strings.ToLower(string(provider)))
-		if err != nil {
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-			return
+func (h *Handler) refresh(ctx context.Context) {
+	refreshed := 0
+	for _, cp := range supportedProviders {
+		if !h.discovered(cp) {
+			continue
 		}
-
-		machineTypes := h.providerSpec.MachineTypes(provider)
-		machineFamilies := make(map[string]string)
-		for _, machineType := range machineTypes {
-			var family string
-			if provider == runtime.AWS {
-				// For AWS, machine types follow the pattern "<family>.<size>".
-				parts := strings.SplitN(machineType, ".", 2)
-				family = parts[0]
-			} else {
-				httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("%s provider not supported", provider))
-				return
+		for _, machineType := range h.machineFamilies(cp) {
+			for _, region := range h.regions(cp, machineType) {
+				if ctx.Err() != nil {
+					return
+				}
+				key := cacheKey{provider: cp, region: region, machineType: machineType}
+				if _, _, err := h.cache.Load(ctx, key); err != nil {
+					h.logger.Warn(fmt.Sprintf("unable to refresh zones of the machine type %s in the %s region of %s: %s", machineType, region, cp, err))
+					continue
+				}
+				refreshed++
 			}
-			machineFamilies[family] = machineType
+		}
+	}
+	h.logger.Info(fmt.Sprintf("refreshed zones of %d machine types and regions", refreshed))
+}
+
+func (h *Handler) getMachinesAvailability(w http.ResponseWriter, req *http.Request) {
+	providersData := ProvidersData{Providers: []Provider{}}
+
+	for _, cp := range supportedProviders {
+		families := h.machineFamilies(cp)
+		if len(families) == 0 {
+			continue
+		}
+		providerEntry := Provider{
+			Name:         cp,
+			MachineTypes: []MachineType{},
 		}
 
-		for machineFamily, machineType := range machineFamilies {
+		for _, family := range sortedKeys(families) {
+			machineType := families[family]
 			machineTypeEntry := MachineType{
-				Name:    machineFamily,
+				Name:    family,
 				Regions: []Region{},
 			}
 
-			regions := regionSupportingMachine.SupportedRegions(machineType)
-			if len(regions) == 0 {
-				regions = h.providerSpec.Regions(provider)
-			}
-
-			for _, region := range regions {
-				client, err := h.clientFactory.New(context.Background(), accessKeyID, secretAccessKey, region)
+			for _, region := range h.regions(cp, machineType) {
+				regionEntry, err := h.region(req.Context(), cp, region, machineType)
 				if err != nil {
 					httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
 					return
 				}
-
-				count, err := client.AvailableZonesCount(context.Background(), machineType)
-				if err != nil {
-					httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-					return
-				}
-
-				highAvailability := count >= highAvailabilityThreshold
-				machineTypeEntry.Regions = append(machineTypeEntry.Regions, Region{
-					Name:             region,
-					HighAvailability: highAvailability,
-				})
+				machineTypeEntry.Regions = append(machineTypeEntry.Regions, regionEntry)
 			}
 
 			providerEntry.MachineTypes = append(providerEntry.MachineTypes, machineTypeEntry)
 		}
 
-		sort.Slice(providerEntry.MachineTypes, func(i, j int) bool {
-			return providerEntry.MachineTypes[i].Name < providerEntry.MachineTypes[j].Name
-		})
-
 		providersData.Providers = append(providersData.Providers, providerEntry)
 	}
 
 	httputil.WriteResponse(w, http.StatusOK, providersData)
 }
 
-func (h *Handler) clientCredentials(provider string) (string, string, error) {
-	matchedRule, err := h.matchRule(provider)
-	if err != nil {
-		return "", "", err
+func (h *Handler) region(ctx context.Context, cp runtime.CloudProvider, region, machineType string) (Region, error) {
+	if !h.discovered(cp) {
+		zones := h.configuredZones(cp, region, machineType)
+		return Region{
+			Name:             region,
+			HighAvailability: len(zones) >= highAvailabilityThreshold,
+			Zones:            zones,
+		}, nil
 	}
 
-	secretBinding, err := h.getSecretBindingForRule(matchedRule)
+	zones, updatedAt, err := h.cache.Get(ctx, cacheKey{provider: cp, region: region, machineType: machineType})
 	if err != nil {
-		return "", "", err
+		return Region{}, err
 	}
+	return Region{
+		Name:             region,
+		HighAvailability: len(zones) >= highAvailabilityThreshold,
+		Zones:            zones,
+		UpdatedAt:        &updatedAt,
+	}, nil
+}
+
+// discovered returns true when zones of the provider are queried from the hyperscaler instead of read from the providers configuration
+func (h *Handler) discovered(cp runtime.CloudProvider) bool {
+	return h.providerSpec.ZonesDiscovery(cp) && hyperscalers.SupportsZonesDiscovery(cp)
+}
 
-	h.logger.Info(fmt.Sprintf("getting subscription secret with name %s/%s", secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName()))
-	secret, err := h.gardenerClient.GetSecret(secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName())
+func (h *Handler) discoverZones(ctx context.Context, key cacheKey) ([]string, error) {
+	secret, err := h.secrets.subscriptionSecret(strings.ToLower(string(key.provider)))
 	if err != nil {
-		return "", "", fmt.Errorf("unable to get secret %s/%s", secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName())
+		return nil, err
 	}
-
-	accessKeyID, secretAccessKey, err := aws.ExtractCredentials(secret)
+	client, err := h.clientFactory.New(ctx, key.provider, secret, key.region)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to extract AWS credentials")
+		return nil, err
+	}
+	zones, err := client.AvailableZones(ctx, key.machineType)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// configuredZones returns zones of the machine type from the regions supporting machine or zones of the region
+func (h *Handler) configuredZones(cp runtime.CloudProvider, region, machineType string) []string {
+	pt := providerType(cp)
+	zones, err := h.providerSpec.AvailableZonesForAdditionalWorkers(machineType, region, pt)
+	if err != nil || len(zones) == 0 {
+		zones = h.providerSpec.Zones(cp, region)
+	}
+	fullZones := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		fullZones = append(fullZones, provider.FullZoneName(pt, region, zone))
+	}
+	sort.Strings(fullZones)
+	return fullZones
+}
+
+func (h *Handler) regions(cp runtime.CloudProvider, machineType string) []string {
+	regionsSupportingMachine, err := h.providerSpec.RegionSupportingMachine(string(cp))
+	if err == nil {
+		if regions := regionsSupportingMachine.SupportedRegions(machineType); len(regions) > 0 {
+			return regions
+		}
+	}
+	return h.providerSpec.Regions(cp)
+}
+
+// machineFamilies maps machine families of the provider to the machine type representing them
+func (h *Handler) machineFamilies(cp runtime.CloudProvider) map[string]string {
+	machineTypes := h.providerSpec.MachineTypes(cp)
+	sort.Strings(machineTypes)
+
+	families := make(map[string]string)
+	for _, machineType := range machineTypes {
+		family := machineFamily(cp, machineType)
+		if _, found := families[family]; !found {
+			families[family] = machineType
+		}
+	}
+	return families
+}
+
+func machineFamily(cp runtime.CloudProvider, machineType string) string {
+	switch cp {
+	case runtime.AWS:
+		// <family>.<size>, for example, m6i.large
+		family, _, _ := strings.Cut(machineType, ".")
+		return family
+	case runtime.Alicloud:
+		// ecs.<family>.<size>, for example, ecs.g8i.large
+		if i := strings.LastIndex(machineType, "."); i > 0 {
+			return machineType[:i]
+		}
+	case runtime.GCP:
+		// <series>-<type>-<vCPUs>, for example, n2-standard-4
+		if i := strings.LastIndex(machineType, "-"); i > 0 {
+			return machineType[:i]
+		}
+	case runtime.Azure:
+		// Standard_<family><vCPUs><features>_<version>, for example, Standard_D4s_v5
+		if matches := azureMachineSize.FindStringSubmatch(machineType); matches != nil {
+			return matches[1] + matches[2]
+		}
+	case runtime.SapConvergedCloud:
+		// <family>_c<vCPUs>_m<memory>, for example, g_c4_m16
+		family, _, _ := strings.Cut(machineType, "_")
+		return family
 	}
-	return accessKeyID, secretAccessKey, nil
+	return machineType
+}
+
+func providerType(cp runtime.CloudProvider) string {
+	switch cp {
+	case runtime.AWS:
+		return provider.AWSProviderType
+	case runtime.GCP:
+		return provider.GCPProviderType
+	case runtime.Azure:
+		return provider.AzureProviderType
+	case runtime.SapConvergedCloud:
+		return provider.OpenstackProviderType
+	case runtime.Alicloud:
+		return provider.AlicloudProviderType
+	}
+	return strings.ToLower(string(cp))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (h *Handler) matchRule(provider string) (rules.Result, error) {
@@ -189,12 +340,35 @@ func (h *Handler) matchRule(provider string) (rules.Result, error) {
 	return matchedRule, nil
 }
 
-func (h *Handler) getSecretBindingForRule(matchedRule rules.Result) (*gardener.SecretBinding, error) {
+type secretBindingResolver struct {
+	*Handler
+}
+
+func (r *secretBindingResolver) subscriptionSecret(provider string) (*unstructured.Unstructured, error) {
+	matchedRule, err := r.matchRule(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	secretBinding, err := r.getSecretBindingForRule(matchedRule)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info(fmt.Sprintf("getting subscription secret with name %s/%s", secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName()))
+	secret, err := r.gardenerClient.GetSecret(secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get secret %s/%s", secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName())
+	}
+	return secret, nil
+}
+
+func (r *secretBindingResolver) getSecretBindingForRule(matchedRule rules.Result) (*gardener.SecretBinding, error) {
 	labelSelectorBuilder := subscriptions.NewLabelSelectorFromRuleset(matchedRule)
 	labelSelector := labelSelectorBuilder.BuildAnySubscription()
 
-	h.logger.Info(fmt.Sprintf("getting secret binding with selector %q", labelSelector))
-	secretBindings, err := h.gardenerClient.GetSecretBindings(labelSelector)
+	r.logger.Info(fmt.Sprintf("getting secret binding with selector %q", labelSelector))
+	secretBindings, err := r.gardenerClient.GetSecretBindings(labelSelector)
 	if err != nil {
 		return nil, fmt.Errorf("while getting secret bindings with selector %q: %w", labelSelector, err)
 	}
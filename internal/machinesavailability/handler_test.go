@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
@@ -25,7 +26,7 @@ func TestMachinesAvailabilityHandler(t *testing.T) {
 	rulesService, err := rules.NewRulesServiceFromSlice([]string{"aws"}, sets.New("aws"), sets.New("aws"))
 	require.NoError(t, err)
 
-	fakeAWSClientFactory := fixture.NewFakeAWSClientFactory(map[string][]string{
+	fakeAWSClientFactory := fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 		"m6i.large":    {"a", "b", "c", "d"},
 		"m6i.xlarge":   {"a", "b", "c", "d"},
 		"c7i.large":    {"a", "b"},
@@ -38,7 +39,8 @@ func TestMachinesAvailabilityHandler(t *testing.T) {
 
 	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 
-	handler := NewHandler(providerSpec, rulesService, fixture.CreateGardenerClient(), fakeAWSClientFactory, log)
+	handler := NewHandler(providerSpec, rulesService, fixture.CreateGardenerClient(), fakeAWSClientFactory, Config{CacheTTL: time.Hour}, log)
+	handler.cache.now = fixedNow
 
 	router := httputil.NewRouter()
 	handler.AttachRoutes(router)
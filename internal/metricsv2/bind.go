@@ -94,6 +94,37 @@ func (c *BindingCreationCollector) OnBindingCreated(ctx context.Context, ev inte
 	return nil
 }
 
+type BindingRenewalCollector struct {
+	bindingRenewed *prometheus.CounterVec
+}
+
+// BindingRenewalCollector provides a counter which shows the total number of renewed bindings:
+// - kcp_keb_v2_binding_renewed_total{plan_id}
+func NewBindingRenewalCollector() *BindingRenewalCollector {
+	return &BindingRenewalCollector{
+		bindingRenewed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: prometheusNamespacev2,
+			Subsystem: prometheusSubsystemv2,
+			Name:      "binding_renewed_total",
+			Help:      "The total number of renewed bindings",
+		}, []string{"plan_id"}),
+	}
+}
+
+func (c *BindingRenewalCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.bindingRenewed.Describe(ch)
+}
+
+func (c *BindingRenewalCollector) Collect(ch chan<- prometheus.Metric) {
+	c.bindingRenewed.Collect(ch)
+}
+
+func (c *BindingRenewalCollector) OnBindingRenewed(ctx context.Context, ev interface{}) error {
+	obj := ev.(broker.BindingRenewed)
+	c.bindingRenewed.WithLabelValues(obj.PlanID).Inc()
+	return nil
+}
+
 type BindingStatitics struct {
 	db     storage.Bindings
 	logger *slog.Logger
@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type AuthorizationConfig struct {
+	// Enables the validation of bearer tokens and scopes of the admin endpoints. Without it, the endpoints are protected only by the Istio authorization policies.
+	Enabled bool `envconfig:"default=false"`
+	// Accepted token issuers
+	Issuers []string `envconfig:"optional"`
+	// Required token audience, not checked if empty
+	Audience string `envconfig:"optional"`
+	// URLs of the JSON Web Key Sets used to verify token signatures, one for every issuer in the same order as the issuers
+	KeysURLs []string `envconfig:"optional"`
+	// How often the JSON Web Key Set is fetched again
+	KeysRefreshInterval time.Duration `envconfig:"default=15m"`
+	// Path to a PEM encoded public key used instead of the JSON Web Key Set, for local development and tests
+	StaticKeyFile string `envconfig:"optional"`
+	// Token claim with scopes, either a space separated string or a list
+	ScopesClaim string `envconfig:"default=scp"`
+	// Token claim with groups of the caller
+	GroupsClaim string `envconfig:"default=groups"`
+	// Groups granted all scopes
+	AdminGroups []string `envconfig:"optional"`
+	// Groups of operators, granted all scopes as admins
+	OperatorGroups []string `envconfig:"optional"`
+	// Groups granted read scopes
+	ViewerGroups []string `envconfig:"optional"`
+	// Names of OAuth clients in the clientID=name format, for example of KEB jobs, used as the caller name in recorded actions
+	ClientNames []string `envconfig:"optional"`
+}
+
+func (c AuthorizationConfig) String() string {
+	return fmt.Sprintf("(Enabled=%t; Issuers=%s; Audience=%s; KeysURLs=%s; KeysRefreshInterval=%s; StaticKeyFile=%s; ScopesClaim=%s; GroupsClaim=%s; AdminGroups=%s; OperatorGroups=%s; ViewerGroups=%s; ClientNames=%s)",
+		c.Enabled, c.Issuers, c.Audience, c.KeysURLs, c.KeysRefreshInterval, c.StaticKeyFile, c.ScopesClaim, c.GroupsClaim, c.AdminGroups, c.OperatorGroups, c.ViewerGroups, c.ClientNames)
+}
+
+// KeysConfigured returns true if tokens can be verified, also when the authorization is not enabled
+func (c AuthorizationConfig) KeysConfigured() bool {
+	return c.StaticKeyFile != "" || len(c.KeysURLs) > 0
+}
+
+// Caller is the identity of the caller taken from the validated token
+type Caller struct {
+	Subject  string
+	Issuer   string
+	Email    string
+	ClientID string
+	// ClientName is the name configured for the client ID, for example the name of a KEB job
+	ClientName string
+	Groups     []string
+	Scopes     []string
+}
+
+// Name returns the most readable identifier of the caller
+func (c Caller) Name() string {
+	switch {
+	case c.ClientName != "":
+		return c.ClientName
+	case c.Email != "":
+		return c.Email
+	case c.Subject != "":
+		return c.Subject
+	default:
+		return c.ClientID
+	}
+}
+
+func (c Caller) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// The callerKey type is not exported to prevent collisions with context keys
+// defined in other packages.
+type callerKey int
+
+const (
+	// requestCallerKey is the context key for the caller authenticated by the token.
+	requestCallerKey callerKey = iota + 1
+)
+
+// CallerFromContext returns the caller authenticated by the Authorization middleware if possible.
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(requestCallerKey).(Caller)
+	return caller, ok
+}
+
+func AddCallerToContext(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, requestCallerKey, caller)
+}
+
+var errMissingToken = errors.New("missing bearer token")
+
+// Authorization validates bearer tokens of requests to the admin endpoints and checks the scopes required by the endpoint
+type Authorization struct {
+	cfg         AuthorizationConfig
+	keys        KeySet
+	clientNames map[string]string
+	parser      *jwt.Parser
+	rules       *http.ServeMux
+	log         *slog.Logger
+}
+
+// NewAuthorization creates the authorization with the static key if configured, otherwise with the JSON Web Key Sets of all issuers
+func NewAuthorization(cfg AuthorizationConfig, log *slog.Logger) (*Authorization, error) {
+	if _, err := parseClientNames(cfg.ClientNames); err != nil {
+		return nil, err
+	}
+	var keys KeySet
+	switch {
+	case cfg.StaticKeyFile != "":
+		staticKeys, err := NewStaticKeySetFromFile(cfg.StaticKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("while reading the static key: %w", err)
+		}
+		keys = staticKeys
+	case len(cfg.KeysURLs) > 0:
+		if len(cfg.KeysURLs) != len(cfg.Issuers) {
+			return nil, fmt.Errorf("every issuer must have one keys URL, got %d issuers and %d keys URLs", len(cfg.Issuers), len(cfg.KeysURLs))
+		}
+		client := &http.Client{Timeout: 10 * time.Second}
+		keySets := make(map[string]KeySet, len(cfg.KeysURLs))
+		for i, url := range cfg.KeysURLs {
+			keySets[cfg.Issuers[i]] = NewJWKSKeySet(url, cfg.KeysRefreshInterval, client)
+		}
+		keys = NewMultiKeySet(keySets)
+	default:
+		return nil, fmt.Errorf("either the keys URLs or the static key file must be configured")
+	}
+	return NewAuthorizationWithKeys(cfg, keys, log), nil
+}
+
+func NewAuthorizationWithKeys(cfg AuthorizationConfig, keys KeySet, log *slog.Logger) *Authorization {
+	rules := http.NewServeMux()
+	for _, rule := range endpointScopes {
+		rules.Handle(rule.pattern, http.NotFoundHandler())
+	}
+	// client names are validated by NewAuthorization, invalid entries are skipped
+	clientNames, _ := parseClientNames(cfg.ClientNames)
+	return &Authorization{
+		cfg:         cfg,
+		keys:        keys,
+		clientNames: clientNames,
+		parser:      jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"})),
+		rules:       rules,
+		log:         log.With("component", "Authorization"),
+	}
+}
+
+// parseClientNames reads entries in the clientID=name format
+func parseClientNames(entries []string) (map[string]string, error) {
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		clientID, name, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || clientID == "" || name == "" {
+			return names, fmt.Errorf("client name %q must be in the clientID=name format", entry)
+		}
+		names[clientID] = name
+	}
+	return names, nil
+}
+
+// Middleware rejects requests to protected endpoints without a valid token or the required scope if the authorization
+// is enabled. Other requests are passed without any check, a valid token only identifies the caller for recorded actions.
+func (a *Authorization) Middleware() MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			scope, protected := a.requiredScope(req)
+			if !protected || !a.cfg.Enabled {
+				if caller, err := a.Authenticate(req); err == nil {
+					req = req.WithContext(AddCallerToContext(req.Context(), caller))
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			caller, err := a.Authenticate(req)
+			if err != nil {
+				a.log.Info(fmt.Sprintf("rejecting %s %s: %s", req.Method, req.URL.Path, err))
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+				return
+			}
+			if !caller.HasScope(scope) {
+				a.log.Info(fmt.Sprintf("rejecting %s %s of %s: missing scope %s", req.Method, req.URL.Path, caller.Name(), scope))
+				writeError(w, http.StatusForbidden, fmt.Sprintf("the %s scope is required", scope))
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(AddCallerToContext(req.Context(), caller)))
+		})
+	}
+}
+
+func (a *Authorization) requiredScope(req *http.Request) (string, bool) {
+	_, pattern := a.rules.Handler(req)
+	for _, rule := range endpointScopes {
+		if rule.pattern == pattern {
+			return rule.scope, true
+		}
+	}
+	return "", false
+}
+
+// Authenticate validates the bearer token of the request and returns the caller with the scopes granted by the token and its groups
+func (a *Authorization) Authenticate(req *http.Request) (Caller, error) {
+	header := req.Header.Get("Authorization")
+	raw, found := strings.CutPrefix(header, "Bearer ")
+	if !found || raw == "" {
+		return Caller{}, errMissingToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		// the issuer is not verified yet, it only selects the keys, a token signed by another issuer is rejected
+		return a.keys.Key(req.Context(), stringClaim(claims, "iss"), kid)
+	})
+	if err != nil {
+		return Caller{}, fmt.Errorf("invalid token: %w", err)
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return Caller{}, fmt.Errorf("the token has no expiration time")
+	}
+	issuer, _ := claims["iss"].(string)
+	if len(a.cfg.Issuers) > 0 && !slices.Contains(a.cfg.Issuers, issuer) {
+		return Caller{}, fmt.Errorf("the issuer %s is not accepted", issuer)
+	}
+	if a.cfg.Audience != "" && !claims.VerifyAudience(a.cfg.Audience, true) {
+		return Caller{}, fmt.Errorf("the token is not issued for the %s audience", a.cfg.Audience)
+	}
+
+	caller := Caller{
+		Subject:  stringClaim(claims, "sub"),
+		Issuer:   issuer,
+		Email:    stringClaim(claims, "email"),
+		ClientID: stringClaim(claims, "client_id"),
+		Groups:   listClaim(claims, a.cfg.GroupsClaim),
+	}
+	if caller.ClientID == "" {
+		caller.ClientID = stringClaim(claims, "azp")
+	}
+	caller.ClientName = a.clientNames[caller.ClientID]
+	caller.Scopes = a.scopes(listClaim(claims, a.cfg.ScopesClaim), caller.Groups)
+	return caller, nil
+}
+
+// scopes returns the scopes from the token together with the scopes granted to the groups of the caller
+func (a *Authorization) scopes(tokenScopes, groups []string) []string {
+	scopes := slices.Clone(tokenScopes)
+	for _, group := range groups {
+		switch {
+		case slices.Contains(a.cfg.AdminGroups, group), slices.Contains(a.cfg.OperatorGroups, group):
+			scopes = append(scopes, AllScopes()...)
+		case slices.Contains(a.cfg.ViewerGroups, group):
+			scopes = append(scopes, ReadScopes()...)
+		}
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes)
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// listClaim reads the claim as a list of strings, a string is split by spaces as the OAuth 2.0 scope claim
+func listClaim(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
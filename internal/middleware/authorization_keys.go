@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// KeySet provides public keys verifying signatures of tokens issued by the issuer
+type KeySet interface {
+	Key(ctx context.Context, issuer, kid string) (crypto.PublicKey, error)
+}
+
+// StaticKeySet contains keys known upfront for all issuers, a key with an empty ID verifies tokens with any key ID
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+func NewStaticKeySet(keys map[string]crypto.PublicKey) *StaticKeySet {
+	return &StaticKeySet{keys: keys}
+}
+
+// NewStaticKeySetFromFile reads a PEM encoded public key used for tokens with any key ID
+func NewStaticKeySetFromFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("while reading the file %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("the file %s does not contain a PEM encoded key", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("while parsing the public key: %w", err)
+	}
+	return NewStaticKeySet(map[string]crypto.PublicKey{"": key}), nil
+}
+
+func (s *StaticKeySet) Key(_ context.Context, _, kid string) (crypto.PublicKey, error) {
+	if key, found := s.keys[kid]; found {
+		return key, nil
+	}
+	if key, found := s.keys[""]; found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// MultiKeySet contains key sets of accepted issuers, a token is verified only with keys of the issuer from its claims,
+// so a key of one issuer never verifies a token which claims to be issued by another one
+type MultiKeySet struct {
+	keySets map[string]KeySet
+}
+
+func NewMultiKeySet(keySets map[string]KeySet) *MultiKeySet {
+	return &MultiKeySet{keySets: keySets}
+}
+
+func (s *MultiKeySet) Key(ctx context.Context, issuer, kid string) (crypto.PublicKey, error) {
+	keySet, found := s.keySets[issuer]
+	if !found {
+		return nil, fmt.Errorf("the issuer %q is not accepted", issuer)
+	}
+	return keySet.Key(ctx, issuer, kid)
+}
+
+// minJWKSRefreshInterval limits fetching the key set when tokens with unknown key IDs are received
+const minJWKSRefreshInterval = time.Minute
+
+// JWKSKeySet fetches keys from the JSON Web Key Set URL of the token issuer. The keys are fetched again after the refresh interval
+// or when a token is signed with an unknown key, which happens after the issuer rotates its keys. Concurrent requests share
+// one fetch, and the cached keys stay available to other requests while the keys are fetched.
+type JWKSKeySet struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+	fetches         singleflight.Group
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func NewJWKSKeySet(url string, refreshInterval time.Duration, client *http.Client) *JWKSKeySet {
+	return &JWKSKeySet{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          client,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+
+func (s *JWKSKeySet) Key(ctx context.Context, _, kid string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	key, found := s.keys[kid]
+	sinceFetch := time.Since(s.fetchedAt)
+	s.mu.RUnlock()
+	if (found && sinceFetch < s.refreshInterval) || (!found && sinceFetch < minJWKSRefreshInterval) {
+		if !found {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		return key, nil
+	}
+
+	// the fetch is shared by concurrent requests, a canceled request must not fail the others
+	fetched, err, _ := s.fetches.Do(s.url, func() (interface{}, error) {
+		keys, err := s.fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.keys = keys
+		s.fetchedAt = time.Now()
+		s.mu.Unlock()
+		return keys, nil
+	})
+	if err != nil {
+		if found {
+			// the issuer is not available, the cached key is still used
+			return key, nil
+		}
+		return nil, err
+	}
+
+	key, found = fetched.(map[string]crypto.PublicKey)[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (s *JWKSKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("while creating the request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching the key set: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the key set returned status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("while decoding the key set: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("while parsing the key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("while decoding the key parameter: %w", err)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import "strings"
+
+const (
+	ScopeRuntimesRead             = "runtimes:read"
+	ScopeRuntimesExpire           = "runtimes:expire"
+	ScopeEventsRead               = "events:read"
+	ScopeKubeconfigRead           = "kubeconfig:read"
+	ScopeAdditionalPropertiesRead = "additional-properties:read"
+	ScopeOperationsWrite          = "operations:write"
+	ScopePreviewRead              = "preview:read"
+	ScopeWebhooksRead             = "webhooks:read"
+	ScopeWebhooksWrite            = "webhooks:write"
+	ScopeCampaignsRead            = "campaigns:read"
+	ScopeCampaignsWrite           = "campaigns:write"
+	ScopeActionsRead              = "actions:read"
+	ScopeBindingsWrite            = "bindings:write"
+)
+
+type endpointScope struct {
+	// pattern is the http.ServeMux pattern of the protected endpoints
+	pattern string
+	scope   string
+}
+
+// endpointScopes defines the scope required by every protected endpoint, OSB API, health, and metrics endpoints are not protected
+var endpointScopes = []endpointScope{
+	{pattern: "/runtimes", scope: ScopeRuntimesRead},
+	{pattern: "GET /runtimes/", scope: ScopeRuntimesRead},
+	{pattern: "/info/runtimes", scope: ScopeRuntimesRead},
+	{pattern: "PUT /expire/", scope: ScopeRuntimesExpire},
+	{pattern: "/events", scope: ScopeEventsRead},
+	{pattern: "GET /kubeconfig/", scope: ScopeKubeconfigRead},
+	{pattern: "/additional_properties", scope: ScopeAdditionalPropertiesRead},
+	{pattern: "POST /operations/", scope: ScopeOperationsWrite},
+	{pattern: "POST /preview/", scope: ScopePreviewRead},
+	{pattern: "GET /webhooks/", scope: ScopeWebhooksRead},
+	{pattern: "POST /webhooks/", scope: ScopeWebhooksWrite},
+	{pattern: "DELETE /webhooks/", scope: ScopeWebhooksWrite},
+	{pattern: "GET /campaigns", scope: ScopeCampaignsRead},
+	{pattern: "GET /campaigns/", scope: ScopeCampaignsRead},
+	{pattern: "POST /campaigns", scope: ScopeCampaignsWrite},
+	{pattern: "POST /campaigns/", scope: ScopeCampaignsWrite},
+	{pattern: "GET /actions", scope: ScopeActionsRead},
+	{pattern: "POST /bindings/", scope: ScopeBindingsWrite},
+}
+
+// AllScopes returns scopes of all protected endpoints
+func AllScopes() []string {
+	scopes := make([]string, 0, len(endpointScopes))
+	for _, rule := range endpointScopes {
+		scopes = append(scopes, rule.scope)
+	}
+	return scopes
+}
+
+// ReadScopes returns scopes of all protected endpoints which do not change any data
+func ReadScopes() []string {
+	scopes := make([]string, 0, len(endpointScopes))
+	for _, rule := range endpointScopes {
+		if strings.HasSuffix(rule.scope, ":read") {
+			scopes = append(scopes, rule.scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,407 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://issuer.local"
+
+func TestAuthorization(t *testing.T) {
+	// given
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := middleware.AuthorizationConfig{
+		Enabled:      true,
+		Issuers:      []string{testIssuer},
+		ScopesClaim:  "scp",
+		GroupsClaim:  "groups",
+		AdminGroups:  []string{"runtimeAdmin"},
+		ViewerGroups: []string{"runtimeViewer"},
+	}
+	authorization := middleware.NewAuthorizationWithKeys(cfg, middleware.NewStaticKeySet(map[string]crypto.PublicKey{"key-1": &privateKey.PublicKey}), fixLogger())
+	router, caller := fixRouter(authorization)
+
+	for name, tc := range map[string]struct {
+		method       string
+		path         string
+		claims       jwt.MapClaims
+		expectedCode int
+	}{
+		"unprotected endpoint without token": {
+			method:       http.MethodGet,
+			path:         "/oauth/v2/catalog",
+			expectedCode: http.StatusOK,
+		},
+		"protected endpoint without token": {
+			method:       http.MethodGet,
+			path:         "/runtimes",
+			expectedCode: http.StatusUnauthorized,
+		},
+		"token with the required scope": {
+			method:       http.MethodGet,
+			path:         "/runtimes",
+			claims:       fixClaims(jwt.MapClaims{"scp": "runtimes:read events:read"}),
+			expectedCode: http.StatusOK,
+		},
+		"token without the required scope": {
+			method:       http.MethodPut,
+			path:         "/expire/service_instance/instance-1",
+			claims:       fixClaims(jwt.MapClaims{"scp": []string{"runtimes:read"}}),
+			expectedCode: http.StatusForbidden,
+		},
+		"viewer group reading campaigns": {
+			method:       http.MethodGet,
+			path:         "/campaigns/campaign-1",
+			claims:       fixClaims(jwt.MapClaims{"groups": []string{"runtimeViewer"}}),
+			expectedCode: http.StatusOK,
+		},
+		"viewer group creating campaign": {
+			method:       http.MethodPost,
+			path:         "/campaigns",
+			claims:       fixClaims(jwt.MapClaims{"groups": []string{"runtimeViewer"}}),
+			expectedCode: http.StatusForbidden,
+		},
+		"admin group expiring instance": {
+			method:       http.MethodPut,
+			path:         "/expire/service_instance/instance-1",
+			claims:       fixClaims(jwt.MapClaims{"groups": []string{"runtimeAdmin"}}),
+			expectedCode: http.StatusOK,
+		},
+		"token of unknown issuer": {
+			method:       http.MethodGet,
+			path:         "/runtimes",
+			claims:       fixClaims(jwt.MapClaims{"iss": "https://other.local", "scp": "runtimes:read"}),
+			expectedCode: http.StatusUnauthorized,
+		},
+		"expired token": {
+			method:       http.MethodGet,
+			path:         "/runtimes",
+			claims:       fixClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix(), "scp": "runtimes:read"}),
+			expectedCode: http.StatusUnauthorized,
+		},
+		"token without expiration time": {
+			method:       http.MethodGet,
+			path:         "/runtimes",
+			claims:       fixClaims(jwt.MapClaims{"exp": nil, "scp": "runtimes:read"}),
+			expectedCode: http.StatusUnauthorized,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.claims != nil {
+				req.Header.Set("Authorization", "Bearer "+sign(t, privateKey, "key-1", tc.claims))
+			}
+			resp := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(resp, req)
+
+			// then
+			assert.Equal(t, tc.expectedCode, resp.Code)
+		})
+	}
+
+	t.Run("should pass the caller to the handler", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/events", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(t, privateKey, "key-1", fixClaims(jwt.MapClaims{"email": "operator@local", "scp": "events:read"})))
+		resp := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(resp, req)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "operator@local", caller.Name())
+		assert.Equal(t, testIssuer, caller.Issuer)
+		assert.Equal(t, []string{middleware.ScopeEventsRead}, caller.Scopes)
+	})
+
+	t.Run("should reject token signed with another key", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/runtimes", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(t, otherKey, "key-1", fixClaims(jwt.MapClaims{"scp": "runtimes:read"})))
+		resp := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(resp, req)
+
+		// then
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
+
+func TestAuthorization_IdentifiesCallersWithoutEnforcement(t *testing.T) {
+	// given
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := middleware.AuthorizationConfig{
+		Issuers:     []string{testIssuer},
+		ScopesClaim: "scp",
+		ClientNames: []string{"cleanup-client=accountcleanup-job"},
+	}
+	authorization := middleware.NewAuthorizationWithKeys(cfg, middleware.NewStaticKeySet(map[string]crypto.PublicKey{"key-1": &privateKey.PublicKey}), fixLogger())
+
+	for name, tc := range map[string]struct {
+		path           string
+		token          string
+		expectedCaller string
+	}{
+		"job calling the OSB API": {
+			path:           "/oauth/v2/service_instances/instance-1",
+			token:          sign(t, privateKey, "key-1", fixClaims(jwt.MapClaims{"client_id": "cleanup-client"})),
+			expectedCaller: "accountcleanup-job",
+		},
+		"admin endpoint with the authorization disabled": {
+			path:           "/runtimes",
+			token:          sign(t, privateKey, "key-1", fixClaims(jwt.MapClaims{"email": "operator@local"})),
+			expectedCaller: "operator@local",
+		},
+		"token signed with another key": {
+			path:  "/oauth/v2/service_instances/instance-1",
+			token: sign(t, otherKey, "key-1", fixClaims(jwt.MapClaims{"client_id": "cleanup-client"})),
+		},
+		"admin endpoint without token": {
+			path: "/runtimes",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			router, caller := fixRouter(authorization)
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			resp := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(resp, req)
+
+			// then
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.expectedCaller, caller.Name())
+		})
+	}
+
+	t.Run("should reject client names in a wrong format", func(t *testing.T) {
+		// when
+		_, err := middleware.NewAuthorization(middleware.AuthorizationConfig{StaticKeyFile: "unused", ClientNames: []string{"accountcleanup-job"}}, fixLogger())
+
+		// then
+		assert.EqualError(t, err, `client name "accountcleanup-job" must be in the clientID=name format`)
+	})
+}
+
+func TestJWKSKeySet(t *testing.T) {
+	// given
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "key-1",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+	keys := middleware.NewJWKSKeySet(server.URL, time.Hour, server.Client())
+	authorization := middleware.NewAuthorizationWithKeys(middleware.AuthorizationConfig{Enabled: true, ScopesClaim: "scp"}, keys, fixLogger())
+	router, _ := fixRouter(authorization)
+
+	// when
+	first := call(router, sign(t, privateKey, "key-1", fixClaims(jwt.MapClaims{"scp": "runtimes:read"})))
+	second := call(router, sign(t, privateKey, "key-1", fixClaims(jwt.MapClaims{"scp": "runtimes:read"})))
+	unknownKey := call(router, sign(t, privateKey, "key-2", fixClaims(jwt.MapClaims{"scp": "runtimes:read"})))
+
+	// then
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, http.StatusUnauthorized, unknownKey.Code)
+	assert.Equal(t, 1, requests)
+}
+
+func TestJWKSKeySet_SharesFetchOfConcurrentRequests(t *testing.T) {
+	// given
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var requests atomic.Int32
+	release := make(chan struct{})
+	jwks := fixJWKSServer("key-1", privateKey)
+	defer jwks.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		jwks.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	keys := middleware.NewJWKSKeySet(server.URL, time.Hour, server.Client())
+
+	// when
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = keys.Key(context.Background(), testIssuer, "key-1")
+		}()
+	}
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, 10*time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// then
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestAuthorization_KeysOfManyIssuers(t *testing.T) {
+	// given
+	firstKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	secondKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	firstServer := fixJWKSServer("key-1", firstKey)
+	defer firstServer.Close()
+	secondServer := fixJWKSServer("key-2", secondKey)
+	defer secondServer.Close()
+
+	authorization, err := middleware.NewAuthorization(middleware.AuthorizationConfig{
+		Enabled:             true,
+		Issuers:             []string{testIssuer, "https://second-issuer.local"},
+		KeysURLs:            []string{firstServer.URL, secondServer.URL},
+		KeysRefreshInterval: time.Hour,
+		ScopesClaim:         "scp",
+	}, fixLogger())
+	require.NoError(t, err)
+	router, _ := fixRouter(authorization)
+
+	// when
+	first := call(router, sign(t, firstKey, "key-1", fixClaims(jwt.MapClaims{"scp": "runtimes:read"})))
+	second := call(router, sign(t, secondKey, "key-2", fixClaims(jwt.MapClaims{"scp": "runtimes:read", "iss": "https://second-issuer.local"})))
+	other := call(router, sign(t, otherKey, "key-2", fixClaims(jwt.MapClaims{"scp": "runtimes:read", "iss": "https://second-issuer.local"})))
+	// a token claiming to be issued by the first issuer but signed with a key of the second one
+	crossIssuer := call(router, sign(t, secondKey, "key-2", fixClaims(jwt.MapClaims{"scp": "runtimes:read"})))
+	unknownIssuer := call(router, sign(t, firstKey, "key-1", fixClaims(jwt.MapClaims{"scp": "runtimes:read", "iss": "https://unknown.local"})))
+
+	// then
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, http.StatusUnauthorized, other.Code)
+	assert.Equal(t, http.StatusUnauthorized, crossIssuer.Code)
+	assert.Equal(t, http.StatusUnauthorized, unknownIssuer.Code)
+}
+
+func TestAuthorization_RequiresKeysURLOfEveryIssuer(t *testing.T) {
+	// when
+	_, err := middleware.NewAuthorization(middleware.AuthorizationConfig{
+		Issuers:  []string{testIssuer, "https://second-issuer.local"},
+		KeysURLs: []string{"https://issuer.local/oauth2/certs"},
+	}, fixLogger())
+
+	// then
+	assert.EqualError(t, err, "every issuer must have one keys URL, got 2 issuers and 1 keys URLs")
+}
+
+func fixJWKSServer(kid string, key *rsa.PrivateKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": kid,
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+}
+
+func fixRouter(authorization *middleware.Authorization) (*httputil.Router, *middleware.Caller) {
+	router := httputil.NewRouter()
+	router.Use(authorization.Middleware())
+	caller := &middleware.Caller{}
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		if c, found := middleware.CallerFromContext(req.Context()); found {
+			*caller = c
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	router.HandleFunc("/runtimes", handler)
+	router.HandleFunc("/events", handler)
+	router.HandleFunc("PUT /expire/service_instance/{instance_id}", handler)
+	router.HandleFunc("GET /campaigns/{campaign_id}", handler)
+	router.HandleFunc("POST /campaigns", handler)
+	router.HandleFunc("/oauth/", handler)
+	return router, caller
+}
+
+func fixClaims(claims jwt.MapClaims) jwt.MapClaims {
+	result := jwt.MapClaims{
+		"iss": testIssuer,
+		"sub": "subject-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = value
+	}
+	return result
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func call(router *httputil.Router, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/runtimes", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/internal/euaccess"
 
 	"github.com/google/uuid"
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
 	"github.com/kyma-project/kyma-environment-broker/common/gardener"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
@@ -144,6 +146,9 @@ type Operation struct {
 	// UpdatedPlanID is used to store the plan ID if the plan has been changed, "" if not changed
 	UpdatedPlanID string `json:"updated_plan_id,omitempty"`
 
+	// DeferredUntil is the start of the maintenance window to which the update is deferred, the operation stays pending until then
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+
 	// UPGRADE KYMA
 	RuntimeOperation            `json:"runtime_operation"`
 	ClusterConfigurationApplied bool `json:"cluster_configuration_applied"`
@@ -183,6 +188,14 @@ func (o *Operation) IsFinished() bool {
 	return o.State != OperationStateInProgress && o.State != OperationStatePending && o.State != OperationStateCanceling && o.State != OperationStateRetrying
 }
 
+// TimeLimitStart returns the time from which the operation time limit is measured, a deferred operation is measured from the end of the deferral
+func (o *Operation) TimeLimitStart() time.Time {
+	if o.DeferredUntil != nil && o.DeferredUntil.After(o.CreatedAt) {
+		return *o.DeferredUntil
+	}
+	return o.CreatedAt
+}
+
 func (o *Operation) EventInfof(fmt string, args ...any) {
 	events.Infof(o.InstanceID, o.ID, fmt, args...)
 }
@@ -230,6 +243,9 @@ type InstanceDetails struct {
 
 	CloudProvider string `json:"cloud_provider"`
 
+	// KubernetesVersion is the version of the Runtime resource, it is read when the resource is created and on every update
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+
 	ProviderValues *ProviderValues `json:"providerValues"`
 }
 
@@ -437,6 +453,16 @@ func NewUpdateOperation(operationID string, instance *Instance, updatingParams U
 		op.ProvisioningParameters.Parameters.AdditionalWorkerNodePools = updatingParams.AdditionalWorkerNodePools
 	}
 
+	if updatingParams.MaintenanceWindow != nil {
+		op.ProvisioningParameters.Parameters.MaintenanceWindow = updatingParams.MaintenanceWindow
+	}
+
+	if updatingParams.Modules != nil {
+		op.ProvisioningParameters.Parameters.Modules = updatingParams.Modules
+	}
+
+	updatingParams.UpdateNetworking(&op.ProvisioningParameters.Parameters)
+
 	return op
 }
 
@@ -526,6 +552,13 @@ type Binding struct {
 	Kubeconfig        string
 	ExpirationSeconds int64
 	CreatedBy         string
+	Role              string
+	Namespaces        []string
+
+	// State of the binding creation, Stage is the last finished stage of the asynchronous creation
+	State       domain.LastOperationState
+	Stage       string
+	Description string
 }
 
 type RetryTuple struct {
@@ -542,3 +575,237 @@ type RegionsSupporter interface {
 	SupportedRegions(machineType string) []string
 	AvailableZonesForAdditionalWorkers(machineType, region, planID string) ([]string, error)
 }
+
+type OutboxEventType string
+
+const (
+	// OperationFinishedOutboxEvent is stored when an operation reaches the succeeded, failed or canceled state
+	OperationFinishedOutboxEvent OutboxEventType = "operation_finished"
+)
+
+// OutboxEvent is stored together with the change which caused it and is delivered to subscribers at least once.
+// Events of one instance are delivered in the order they were stored.
+type OutboxEvent struct {
+	ID          int64
+	InstanceID  string
+	OperationID string
+	Type        OutboxEventType
+	Payload     []byte
+	Attempts    int
+	// DeliveredSinks are names of sinks which accepted the event, they are skipped when the event is delivered again
+	DeliveredSinks []string
+	CreatedAt      time.Time
+}
+
+// OperationFinishedEvent is the payload of the OperationFinishedOutboxEvent
+type OperationFinishedEvent struct {
+	OperationID     string                    `json:"operationId"`
+	InstanceID      string                    `json:"instanceId"`
+	RuntimeID       string                    `json:"runtimeId,omitempty"`
+	GlobalAccountID string                    `json:"globalAccountId,omitempty"`
+	SubAccountID    string                    `json:"subAccountId,omitempty"`
+	PlanID          string                    `json:"planId"`
+	Type            OperationType             `json:"type"`
+	State           domain.LastOperationState `json:"state"`
+	Description     string                    `json:"description"`
+	CreatedAt       time.Time                 `json:"createdAt"`
+	UpdatedAt       time.Time                 `json:"updatedAt"`
+	LastError       kebError.LastError        `json:"lastError"`
+}
+
+// IsOperationFinished returns true for states which end the operation processing
+func IsOperationFinished(state domain.LastOperationState) bool {
+	return state == domain.Succeeded || state == domain.Failed || state == OperationStateCanceled
+}
+
+// NewOperationFinishedOutboxEvent creates the outbox event for the finished operation
+func NewOperationFinishedOutboxEvent(op Operation) (OutboxEvent, error) {
+	payload, err := json.Marshal(OperationFinishedEvent{
+		OperationID:     op.ID,
+		InstanceID:      op.InstanceID,
+		RuntimeID:       op.RuntimeID,
+		GlobalAccountID: op.GlobalAccountID,
+		SubAccountID:    op.ProvisioningParameters.ErsContext.SubAccountID,
+		PlanID:          op.ProvisioningParameters.PlanID,
+		Type:            op.Type,
+		State:           op.State,
+		Description:     op.Description,
+		CreatedAt:       op.CreatedAt,
+		UpdatedAt:       op.UpdatedAt,
+		LastError:       op.LastError,
+	})
+	if err != nil {
+		return OutboxEvent{}, fmt.Errorf("while marshalling the operation %s finished event: %w", op.ID, err)
+	}
+	return OutboxEvent{
+		InstanceID:  op.InstanceID,
+		OperationID: op.ID,
+		Type:        OperationFinishedOutboxEvent,
+		Payload:     payload,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Operation returns the finished operation with the fields carried by the event
+func (e OperationFinishedEvent) Operation() Operation {
+	op := Operation{
+		ID:          e.OperationID,
+		InstanceID:  e.InstanceID,
+		Type:        e.Type,
+		State:       e.State,
+		Description: e.Description,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+		LastError:   e.LastError,
+	}
+	op.RuntimeID = e.RuntimeID
+	op.GlobalAccountID = e.GlobalAccountID
+	op.ProvisioningParameters.PlanID = e.PlanID
+	op.ProvisioningParameters.ErsContext.SubAccountID = e.SubAccountID
+	return op
+}
+
+const (
+	InstanceSuspensionStartedOutboxEvent   OutboxEventType = "instance_suspension_started"
+	InstanceUnsuspensionStartedOutboxEvent OutboxEventType = "instance_unsuspension_started"
+	InstanceExpiredOutboxEvent             OutboxEventType = "instance_expired"
+	BindingCreatedOutboxEvent              OutboxEventType = "binding_created"
+	BindingDeletedOutboxEvent              OutboxEventType = "binding_deleted"
+)
+
+// InstanceEvent is the payload of outbox events about instance lifecycle changes and bindings
+type InstanceEvent struct {
+	InstanceID      string    `json:"instanceId"`
+	RuntimeID       string    `json:"runtimeId,omitempty"`
+	GlobalAccountID string    `json:"globalAccountId"`
+	SubAccountID    string    `json:"subAccountId"`
+	PlanID          string    `json:"planId"`
+	OperationID     string    `json:"operationId,omitempty"`
+	BindingID       string    `json:"bindingId,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// NewInstanceOutboxEvent creates the outbox event about the instance, the operation and binding IDs are optional
+func NewInstanceOutboxEvent(eventType OutboxEventType, instance Instance, operationID, bindingID string) (OutboxEvent, error) {
+	now := time.Now()
+	payload, err := json.Marshal(InstanceEvent{
+		InstanceID:      instance.InstanceID,
+		RuntimeID:       instance.RuntimeID,
+		GlobalAccountID: instance.GlobalAccountID,
+		SubAccountID:    instance.SubAccountID,
+		PlanID:          instance.ServicePlanID,
+		OperationID:     operationID,
+		BindingID:       bindingID,
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return OutboxEvent{}, fmt.Errorf("while marshalling the %s event of instance %s: %w", eventType, instance.InstanceID, err)
+	}
+	return OutboxEvent{
+		InstanceID:  instance.InstanceID,
+		OperationID: operationID,
+		Type:        eventType,
+		Payload:     payload,
+		CreatedAt:   now,
+	}, nil
+}
+
+// WebhookSubscription registers the endpoint which receives signed notifications about events of instances
+// in the global account or in the subaccount
+type WebhookSubscription struct {
+	ID              string
+	GlobalAccountID string
+	SubAccountID    string
+	URL             string
+	Secret          string
+	EventTypes      []OutboxEventType
+	CreatedAt       time.Time
+}
+
+// Matches returns true if the subscription scope contains the given account and the event type is subscribed,
+// an empty list of event types means all events
+func (s WebhookSubscription) Matches(eventType OutboxEventType, globalAccountID, subAccountID string) bool {
+	inScope := (s.GlobalAccountID != "" && s.GlobalAccountID == globalAccountID) || (s.SubAccountID != "" && s.SubAccountID == subAccountID)
+	if !inScope {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryState string
+
+const (
+	WebhookDeliveryPending WebhookDeliveryState = "pending"
+	// WebhookDeliveryDead marks a notification which was not delivered after all attempts
+	WebhookDeliveryDead WebhookDeliveryState = "dead"
+)
+
+// WebhookDelivery is a notification about the outbox event sent to one subscription
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID string
+	EventID        int64
+	EventType      OutboxEventType
+	Payload        []byte
+	State          WebhookDeliveryState
+	Attempts       int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Campaign updates many instances with the same parameters in waves
+type Campaign struct {
+	ID          string
+	Description string
+	State       campaigns.State
+	// Message explains the state, for example, why the campaign was paused
+	Message    string
+	Target     campaigns.Target
+	Parameters json.RawMessage
+	Strategy   campaigns.Strategy
+
+	CurrentWave int
+	Waves       int
+	// NextWaveAt is the earliest time the current wave can be started, it is set when the previous wave is finished
+	NextWaveAt time.Time
+	// AcceptedFailures is the number of failed updates accepted by the operator when the campaign was resumed
+	AcceptedFailures int
+	Progress         campaigns.Progress
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+}
+
+// CampaignInstance is the update of one instance done by the campaign
+type CampaignInstance struct {
+	CampaignID  string
+	InstanceID  string
+	Wave        int
+	State       campaigns.InstanceState
+	OperationID string
+	Message     string
+	UpdatedAt   time.Time
+}
+
+// InstanceSnapshot is an immutable copy of the parameters which became effective when the operation succeeded
+type InstanceSnapshot struct {
+	InstanceID string
+	// Version starts with 1 and is incremented for every snapshot of the instance
+	Version       int
+	OperationID   string
+	OperationType OperationType
+	RuntimeID     string
+	Parameters    ProvisioningParameters
+	// CreatedAt is the time the operation finished, the parameters were effective since then
+	CreatedAt time.Time
+}
@@ -0,0 +1,238 @@
+package operations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	operationsapi "github.com/kyma-project/kyma-environment-broker/common/operations"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+const cancelConflictRetries = 3
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Adder interface {
+	Add(processId string)
+}
+
+type Handler struct {
+	operations       storage.Operations
+	actions          storage.Actions
+	queues           map[internal.OperationType]Adder
+	operationTimeout time.Duration
+	log              *slog.Logger
+}
+
+func NewHandler(operations storage.Operations, actions storage.Actions, provisioningQueue, deprovisioningQueue, updateQueue Adder, operationTimeout time.Duration, log *slog.Logger) *Handler {
+	return &Handler{
+		operations: operations,
+		actions:    actions,
+		queues: map[internal.OperationType]Adder{
+			internal.OperationTypeProvision:   provisioningQueue,
+			internal.OperationTypeDeprovision: deprovisioningQueue,
+			internal.OperationTypeUpdate:      updateQueue,
+		},
+		operationTimeout: operationTimeout,
+		log:              log.With("service", "OperationsEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	r.HandleFunc("POST /operations/{operation_id}/cancel", h.cancelOperation)
+	r.HandleFunc("POST /operations/{operation_id}/retry", h.retryOperation)
+}
+
+// cancelOperation marks the operation as canceling and puts it to the queue, the processing is stopped before the next step
+// and the operation is finished as canceled.
+func (h *Handler) cancelOperation(w http.ResponseWriter, req *http.Request) {
+	operationID := req.PathValue("operation_id")
+	requester := audit.ActorFromRequest(req)
+	logger := h.log.With("operationID", operationID, "requester", requester)
+	logger.Info("Cancellation requested")
+
+	for attempt := 0; ; attempt++ {
+		operation, err := h.operations.GetOperationByID(operationID)
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to get operation: %s", err.Error()))
+			switch {
+			case dberr.IsNotFound(err):
+				httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+			default:
+				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		logger = logger.With("instanceID", operation.InstanceID)
+
+		queue, supported := h.queues[operation.Type]
+		if !supported {
+			err := fmt.Errorf("cancellation of %s operation is not supported", operation.Type)
+			logger.Warn(err.Error())
+			httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+
+		switch operation.State {
+		case internal.OperationStateCanceling:
+			logger.Info("operation is already canceling")
+			queue.Add(operation.ID)
+			httputil.WriteResponse(w, http.StatusAccepted, operationsapi.OperationResponse{OperationID: operation.ID, State: string(operation.State)})
+			return
+		case domain.InProgress, internal.OperationStatePending, internal.OperationStateRetrying:
+		default:
+			err := fmt.Errorf("operation %s is already finished with state %s", operation.ID, operation.State)
+			logger.Warn(err.Error())
+			httputil.WriteErrorResponse(w, http.StatusConflict, err)
+			return
+		}
+
+		operation.State = internal.OperationStateCanceling
+		operation.Description = "Operation cancellation requested"
+		_, err = h.operations.UpdateOperation(*operation)
+		switch {
+		case dberr.IsConflict(err) && attempt < cancelConflictRetries:
+			logger.Info("operation was modified in the meantime, retrying")
+			continue
+		case err != nil:
+			logger.Error(fmt.Sprintf("unable to update operation: %s", err.Error()))
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		audit.Record(h.actions, pkg.Action{
+			Type:       pkg.OperationCancelActionType,
+			InstanceID: operation.InstanceID,
+			Actor:      requester,
+			Message:    fmt.Sprintf("Operation %s (%s) canceled by %s.", operation.ID, operation.Type, requester),
+		}, logger)
+
+		// the operation can wait for a retry, add it to the queue to stop the processing as soon as possible
+		queue.Add(operation.ID)
+		logger.Info("operation marked as canceling")
+		httputil.WriteResponse(w, http.StatusAccepted, operationsapi.OperationResponse{OperationID: operation.ID, State: string(operation.State)})
+		return
+	}
+}
+
+// retryOperation puts the failed operation back to the queue. Finished stages are not processed again,
+// except the stage given in the request.
+func (h *Handler) retryOperation(w http.ResponseWriter, req *http.Request) {
+	operationID := req.PathValue("operation_id")
+	requester := audit.ActorFromRequest(req)
+	logger := h.log.With("operationID", operationID, "requester", requester)
+
+	var retryRequest operationsapi.RetryRequest
+	if err := json.NewDecoder(req.Body).Decode(&retryRequest); err != nil && !errors.Is(err, io.EOF) {
+		logger.Warn(fmt.Sprintf("unable to decode request body: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	logger.Info(fmt.Sprintf("Retry requested, stage to process again: %q", retryRequest.Stage))
+
+	operation, err := h.operations.GetOperationByID(operationID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to get operation: %s", err.Error()))
+		switch {
+		case dberr.IsNotFound(err):
+			httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		default:
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	logger = logger.With("instanceID", operation.InstanceID)
+
+	queue, supported := h.queues[operation.Type]
+	if !supported {
+		err := fmt.Errorf("retry of %s operation is not supported", operation.Type)
+		logger.Warn(err.Error())
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if retryRequest.Stage != "" && !operation.IsStageFinished(retryRequest.Stage) {
+		err := fmt.Errorf("stage %s is not finished, only finished stages can be forced to run again", retryRequest.Stage)
+		logger.Warn(err.Error())
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.checkRetryable(*operation); err != nil {
+		logger.Warn(err.Error())
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+		return
+	}
+
+	operation.State = domain.InProgress
+	operation.Description = "Operation retried"
+	operation.LastError = kebError.LastError{}
+	if retryRequest.Stage != "" {
+		operation.FinishedStages = slices.DeleteFunc(operation.FinishedStages, func(stage string) bool {
+			return stage == retryRequest.Stage
+		})
+	}
+	_, err = h.operations.UpdateOperation(*operation)
+	switch {
+	case dberr.IsConflict(err):
+		logger.Warn("operation was modified in the meantime")
+		httputil.WriteErrorResponse(w, http.StatusConflict, fmt.Errorf("operation %s was modified in the meantime, try again", operation.ID))
+		return
+	case err != nil:
+		logger.Error(fmt.Sprintf("unable to update operation: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	message := fmt.Sprintf("Operation %s (%s) retried by %s.", operation.ID, operation.Type, requester)
+	if retryRequest.Stage != "" {
+		message = fmt.Sprintf("Operation %s (%s) retried by %s, stage %s processed again.", operation.ID, operation.Type, requester, retryRequest.Stage)
+	}
+	audit.Record(h.actions, pkg.Action{
+		Type:       pkg.OperationRetryActionType,
+		InstanceID: operation.InstanceID,
+		Actor:      requester,
+		Message:    message,
+		OldValue:   string(domain.Failed),
+		NewValue:   string(domain.InProgress),
+	}, logger)
+
+	queue.Add(operation.ID)
+	logger.Info("operation retried")
+	httputil.WriteResponse(w, http.StatusAccepted, operationsapi.OperationResponse{OperationID: operation.ID, State: string(operation.State)})
+}
+
+func (h *Handler) checkRetryable(operation internal.Operation) error {
+	if operation.State != domain.Failed {
+		return fmt.Errorf("operation %s is in state %s, only failed operations can be retried", operation.ID, operation.State)
+	}
+	// the staged manager fails the operation immediately when the time limit is reached
+	if time.Since(operation.TimeLimitStart()) > h.operationTimeout {
+		return fmt.Errorf("operation %s has reached the time limit, it cannot be retried", operation.ID)
+	}
+	lastOperation, err := h.operations.GetLastOperationByTypes(operation.InstanceID, []internal.OperationType{
+		internal.OperationTypeProvision,
+		internal.OperationTypeDeprovision,
+		internal.OperationTypeUpdate,
+	})
+	if err != nil {
+		return fmt.Errorf("while getting the last operation for instance %s: %w", operation.InstanceID, err)
+	}
+	if lastOperation.ID != operation.ID {
+		return fmt.Errorf("operation %s is not the last operation of instance %s, the last one is %s", operation.ID, operation.InstanceID, lastOperation.ID)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package operations_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+	"github.com/kyma-project/kyma-environment-broker/internal/operations"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cancelPathFormat = "/operations/%s/cancel"
+	retryPathFormat  = "/operations/%s/retry"
+)
+
+func TestCancelOperation(t *testing.T) {
+	router := httputil.NewRouter()
+	db := storage.NewMemoryStorage()
+	provisioningQueue := &queue{}
+	deprovisioningQueue := &queue{}
+	updateQueue := &queue{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	handler := operations.NewHandler(db.Operations(), db.Actions(), provisioningQueue, deprovisioningQueue, updateQueue, time.Hour, logger)
+	handler.AttachRoutes(router)
+
+	t.Run("should return 404 for not existing operation", func(t *testing.T) {
+		// when
+		resp := cancel(router, "not-existing")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should mark in progress provisioning as canceling", func(t *testing.T) {
+		// given
+		operation := fixture.FixProvisioningOperation("prov-op-1", "inst-1")
+		operation.State = domain.InProgress
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		resp := cancel(router, operation.ID)
+
+		// then
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"operation": operation.ID, "state": internal.OperationStateCanceling}, body)
+
+		stored, err := db.Operations().GetOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.LastOperationState(internal.OperationStateCanceling), stored.State)
+		assert.Equal(t, []string{operation.ID}, provisioningQueue.ids)
+		assert.Empty(t, deprovisioningQueue.ids)
+	})
+
+	t.Run("should accept cancellation of already canceling operation", func(t *testing.T) {
+		// given
+		operation := fixture.FixUpdatingOperation("upd-op-1", "inst-2")
+		operation.State = internal.OperationStateCanceling
+		require.NoError(t, db.Operations().InsertUpdatingOperation(operation))
+
+		// when
+		resp := cancel(router, operation.ID)
+
+		// then
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, []string{operation.ID}, updateQueue.ids)
+	})
+
+	t.Run("should return 409 for finished operation", func(t *testing.T) {
+		// given
+		operation := fixture.FixDeprovisioningOperation("deprov-op-1", "inst-3")
+		operation.State = domain.Succeeded
+		require.NoError(t, db.Operations().InsertDeprovisioningOperation(operation))
+
+		// when
+		resp := cancel(router, operation.ID)
+
+		// then
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		stored, err := db.Operations().GetOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Succeeded, stored.State)
+		assert.Empty(t, deprovisioningQueue.ids)
+	})
+
+	t.Run("should return 400 for not supported operation type", func(t *testing.T) {
+		// given
+		operation := fixture.FixOperation("upgrade-op-1", "inst-4", internal.OperationTypeUpgradeCluster)
+		operation.State = domain.InProgress
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		resp := cancel(router, operation.ID)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestRetryOperation(t *testing.T) {
+	router := httputil.NewRouter()
+	db := storage.NewMemoryStorage()
+	provisioningQueue := &queue{}
+	deprovisioningQueue := &queue{}
+	updateQueue := &queue{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	handler := operations.NewHandler(db.Operations(), db.Actions(), provisioningQueue, deprovisioningQueue, updateQueue, time.Hour, logger)
+	handler.AttachRoutes(router)
+
+	t.Run("should return 404 for not existing operation", func(t *testing.T) {
+		// when
+		resp := retry(router, "not-existing", "")
+
+		// then
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should retry failed provisioning keeping finished stages", func(t *testing.T) {
+		// given
+		operation := fixture.FixProvisioningOperation("prov-op-1", "inst-1")
+		operation.State = domain.Failed
+		operation.LastError = kebError.LastError{}.SetMessage("runtime resource failed")
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		resp := retry(router, operation.ID, "")
+
+		// then
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		stored, err := db.Operations().GetOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.InProgress, stored.State)
+		assert.Empty(t, stored.LastError.Error())
+		assert.Equal(t, []string{"prepare", "check_provisioning"}, stored.FinishedStages)
+		assert.Equal(t, []string{operation.ID}, provisioningQueue.ids)
+
+		actions, err := db.Actions().ListActionsByInstanceID(operation.InstanceID)
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, pkg.OperationRetryActionType, actions[0].Type)
+		assert.Equal(t, "Operation prov-op-1 (provision) retried by admin@example.com.", actions[0].Message)
+	})
+
+	t.Run("should not trust the token payload header", func(t *testing.T) {
+		// given
+		operation := fixture.FixProvisioningOperation("prov-op-5", "inst-7")
+		operation.State = domain.Failed
+		require.NoError(t, db.Operations().InsertOperation(operation))
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(retryPathFormat, operation.ID), nil)
+		req.Header.Set("X-Jwt-Payload", base64.RawURLEncoding.EncodeToString([]byte(`{"email":"admin@example.com"}`)))
+		w := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(w, req)
+
+		// then
+		require.Equal(t, http.StatusAccepted, w.Result().StatusCode)
+		actions, err := db.Actions().ListActionsByInstanceID(operation.InstanceID)
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, "Operation prov-op-5 (provision) retried by unknown.", actions[0].Message)
+	})
+
+	t.Run("should retry failed update forcing the given stage", func(t *testing.T) {
+		// given
+		operation := fixture.FixOperation("upd-op-1", "inst-2", internal.OperationTypeUpdate)
+		operation.State = domain.Failed
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		resp := retry(router, operation.ID, `{"stage": "prepare"}`)
+
+		// then
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		stored, err := db.Operations().GetOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"check_provisioning"}, stored.FinishedStages)
+		assert.Equal(t, []string{operation.ID}, updateQueue.ids)
+	})
+
+	t.Run("should return 400 for not finished stage", func(t *testing.T) {
+		// given
+		operation := fixture.FixDeprovisioningOperation("deprov-op-1", "inst-3")
+		operation.State = domain.Failed
+		require.NoError(t, db.Operations().InsertDeprovisioningOperation(operation))
+
+		// when
+		resp := retry(router, operation.ID, `{"stage": "not-finished"}`)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, deprovisioningQueue.ids)
+	})
+
+	t.Run("should return 409 for not failed operation", func(t *testing.T) {
+		// given
+		operation := fixture.FixProvisioningOperation("prov-op-2", "inst-4")
+		operation.State = domain.InProgress
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		resp := retry(router, operation.ID, "")
+
+		// then
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("should return 409 when the operation is not the last one", func(t *testing.T) {
+		// given
+		operation := fixture.FixProvisioningOperation("prov-op-3", "inst-5")
+		operation.State = domain.Failed
+		operation.CreatedAt = time.Now().Add(-time.Minute)
+		require.NoError(t, db.Operations().InsertOperation(operation))
+		deprovisioning := fixture.FixDeprovisioningOperation("deprov-op-2", "inst-5")
+		deprovisioning.State = domain.InProgress
+		require.NoError(t, db.Operations().InsertDeprovisioningOperation(deprovisioning))
+
+		// when
+		resp := retry(router, operation.ID, "")
+
+		// then
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		stored, err := db.Operations().GetOperationByID(operation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Failed, stored.State)
+	})
+
+	t.Run("should return 409 when the operation reached the time limit", func(t *testing.T) {
+		// given
+		operation := fixture.FixProvisioningOperation("prov-op-4", "inst-6")
+		operation.State = domain.Failed
+		operation.CreatedAt = time.Now().Add(-2 * time.Hour)
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		// when
+		resp := retry(router, operation.ID, "")
+
+		// then
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+}
+
+func retry(router *httputil.Router, operationID, body string) *http.Response {
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(retryPathFormat, operationID), bytes.NewBufferString(body))
+	req = req.WithContext(middleware.AddCallerToContext(req.Context(), middleware.Caller{Subject: "user-id", Email: "admin@example.com"}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Result()
+}
+
+func cancel(router *httputil.Router, operationID string) *http.Response {
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(cancelPathFormat, operationID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Result()
+}
+
+type queue struct {
+	ids []string
+}
+
+func (q *queue) Add(id string) {
+	q.ids = append(q.ids, id)
+}
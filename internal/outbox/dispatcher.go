@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+type Config struct {
+	// How often the dispatcher checks the outbox for events to deliver
+	PollInterval time.Duration `envconfig:"default=1s"`
+	// Maximum number of events claimed at once, only the oldest event of every instance is claimed
+	BatchSize int `envconfig:"default=100"`
+	// Time after which an event claimed by a dispatcher can be claimed by another replica
+	LeaseDuration time.Duration `envconfig:"default=1m"`
+	// Time after which an event is delivered again to the sinks which failed
+	RetryInterval time.Duration `envconfig:"default=10s"`
+	// Number of delivery attempts after which the event is dropped
+	MaxAttempts int `envconfig:"default=10"`
+	// URL of the endpoint which receives all events, the webhook sink is disabled if empty
+	WebhookURL string `envconfig:"optional"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("(PollInterval=%s; BatchSize=%d; LeaseDuration=%s; RetryInterval=%s; MaxAttempts=%d; WebhookURL=%s)",
+		c.PollInterval, c.BatchSize, c.LeaseDuration, c.RetryInterval, c.MaxAttempts, c.WebhookURL)
+}
+
+// Sink delivers outbox events to one destination. Events are delivered at least once, a sink must tolerate duplicates.
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, event internal.OutboxEvent) error
+}
+
+// Dispatcher delivers events stored in the outbox to all sinks. An event is removed from the outbox only
+// when all sinks accepted it, the next event of the same instance is not delivered before. A failed delivery
+// is retried only for the sinks which did not accept the event.
+type Dispatcher struct {
+	outbox storage.Outbox
+	sinks  []Sink
+	cfg    Config
+	owner  string
+	log    *slog.Logger
+}
+
+func NewDispatcher(outbox storage.Outbox, sinks []Sink, cfg Config, log *slog.Logger) *Dispatcher {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "keb"
+	}
+	return &Dispatcher{
+		outbox: outbox,
+		sinks:  sinks,
+		cfg:    cfg,
+		owner:  fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+		log:    log.With("component", "OutboxDispatcher"),
+	}
+}
+
+// Run delivers events until the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.Info(fmt.Sprintf("starting the outbox dispatcher with sinks %v", d.sinkNames()))
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// do not wait when the batch was full, there could be more events ready
+		if d.Dispatch(ctx) >= d.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			d.log.Info("outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch delivers one batch of events and returns the number of claimed events
+func (d *Dispatcher) Dispatch(ctx context.Context) int {
+	events, err := d.outbox.Claim(d.owner, d.cfg.LeaseDuration, d.cfg.BatchSize)
+	if err != nil {
+		d.log.Error(fmt.Sprintf("unable to claim outbox events: %s", err))
+		return 0
+	}
+
+	for _, event := range events {
+		d.deliver(ctx, event)
+	}
+	return len(events)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event internal.OutboxEvent) {
+	log := d.log.With("eventID", event.ID, "instanceID", event.InstanceID, "operationID", event.OperationID, "eventType", event.Type)
+
+	// sinks which accepted the event in a previous attempt are skipped, so a failing sink does not cause duplicates in the others
+	delivered := slices.Clone(event.DeliveredSinks)
+	var errs []error
+	for _, sink := range d.sinks {
+		if slices.Contains(delivered, sink.Name()) {
+			continue
+		}
+		if err := sink.Deliver(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+			continue
+		}
+		delivered = append(delivered, sink.Name())
+		if err := d.outbox.MarkDelivered(event.ID, d.owner, delivered); err != nil {
+			log.Error(fmt.Sprintf("unable to mark the event as delivered to sink %s: %s", sink.Name(), err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	switch {
+	case err == nil:
+		log.Debug("event delivered")
+		if err := d.outbox.Remove(event.ID, d.owner); err != nil {
+			log.Error(fmt.Sprintf("unable to remove delivered event: %s", err))
+		}
+	case event.Attempts+1 >= d.cfg.MaxAttempts:
+		log.Error(fmt.Sprintf("event dropped after %d delivery attempts: %s", event.Attempts+1, err))
+		if err := d.outbox.Remove(event.ID, d.owner); err != nil {
+			log.Error(fmt.Sprintf("unable to remove dropped event: %s", err))
+		}
+	default:
+		log.Warn(fmt.Sprintf("unable to deliver event, retrying in %s: %s", d.cfg.RetryInterval, err))
+		if err := d.outbox.Release(event.ID, d.owner, d.cfg.RetryInterval); err != nil {
+			log.Error(fmt.Sprintf("unable to release event: %s", err))
+		}
+	}
+}
+
+func (d *Dispatcher) sinkNames() []string {
+	names := make([]string, 0, len(d.sinks))
+	for _, sink := range d.sinks {
+		names = append(names, sink.Name())
+	}
+	return names
+}
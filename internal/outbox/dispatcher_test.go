@@ -0,0 +1,199 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/outbox"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := outbox.Config{BatchSize: 10, LeaseDuration: time.Minute, RetryInterval: 0, MaxAttempts: 3}
+
+	t.Run("should deliver events of an instance in order", func(t *testing.T) {
+		// given
+		store := memory.NewOutbox()
+		publisher := outbox.NewFakeMessagePublisher()
+		dispatcher := outbox.NewDispatcher(store, []outbox.Sink{outbox.NewMessageSink(publisher, "operations")}, cfg, log)
+		require.NoError(t, store.Insert(fixEvent(t, "op-1", "inst-1", domain.Succeeded)))
+		require.NoError(t, store.Insert(fixEvent(t, "op-2", "inst-1", domain.Failed)))
+		require.NoError(t, store.Insert(fixEvent(t, "op-3", "inst-2", domain.Succeeded)))
+
+		// when
+		first := dispatcher.Dispatch(context.Background())
+		second := dispatcher.Dispatch(context.Background())
+		third := dispatcher.Dispatch(context.Background())
+
+		// then
+		assert.Equal(t, 2, first)
+		assert.Equal(t, 1, second)
+		assert.Zero(t, third)
+		messages := publisher.Messages()
+		require.Len(t, messages, 3)
+		assert.Equal(t, []string{"op-1", "op-3", "op-2"}, operationIDs(t, messages))
+		assert.Equal(t, "inst-1", messages[0].Key)
+		assert.Equal(t, "operations", messages[0].Topic)
+	})
+
+	t.Run("should retry the failed delivery and keep next events of the instance", func(t *testing.T) {
+		// given
+		store := memory.NewOutbox()
+		publisher := outbox.NewFakeMessagePublisher()
+		dispatcher := outbox.NewDispatcher(store, []outbox.Sink{outbox.NewMessageSink(publisher, "operations")}, cfg, log)
+		require.NoError(t, store.Insert(fixEvent(t, "op-1", "inst-1", domain.Succeeded)))
+		require.NoError(t, store.Insert(fixEvent(t, "op-2", "inst-1", domain.Failed)))
+
+		// when
+		publisher.FailWith(fmt.Errorf("broker unavailable"))
+		dispatcher.Dispatch(context.Background())
+		publisher.FailWith(nil)
+		dispatcher.Dispatch(context.Background())
+		dispatcher.Dispatch(context.Background())
+
+		// then
+		assert.Equal(t, []string{"op-1", "op-2"}, operationIDs(t, publisher.Messages()))
+	})
+
+	t.Run("should retry only sinks which failed", func(t *testing.T) {
+		// given
+		store := memory.NewOutbox()
+		working := outbox.NewFakeMessagePublisher()
+		failing := outbox.NewFakeMessagePublisher()
+		dispatcher := outbox.NewDispatcher(store, []outbox.Sink{
+			outbox.NewMessageSink(working, "operations"),
+			outbox.NewMessageSink(failing, "audit"),
+		}, cfg, log)
+		require.NoError(t, store.Insert(fixEvent(t, "op-1", "inst-1", domain.Succeeded)))
+
+		// when
+		failing.FailWith(fmt.Errorf("broker unavailable"))
+		dispatcher.Dispatch(context.Background())
+		dispatcher.Dispatch(context.Background())
+		failing.FailWith(nil)
+		dispatcher.Dispatch(context.Background())
+
+		// then
+		assert.Equal(t, []string{"op-1"}, operationIDs(t, working.Messages()))
+		assert.Equal(t, []string{"op-1"}, operationIDs(t, failing.Messages()))
+		assert.Zero(t, dispatcher.Dispatch(context.Background()))
+	})
+
+	t.Run("should drop the event after the maximum number of attempts", func(t *testing.T) {
+		// given
+		store := memory.NewOutbox()
+		publisher := outbox.NewFakeMessagePublisher()
+		dispatcher := outbox.NewDispatcher(store, []outbox.Sink{outbox.NewMessageSink(publisher, "operations")}, cfg, log)
+		require.NoError(t, store.Insert(fixEvent(t, "op-1", "inst-1", domain.Succeeded)))
+		require.NoError(t, store.Insert(fixEvent(t, "op-2", "inst-1", domain.Failed)))
+		publisher.FailWith(fmt.Errorf("broker unavailable"))
+
+		// when
+		for i := 0; i < cfg.MaxAttempts; i++ {
+			dispatcher.Dispatch(context.Background())
+		}
+		publisher.FailWith(nil)
+		dispatcher.Dispatch(context.Background())
+
+		// then
+		assert.Equal(t, []string{"op-2"}, operationIDs(t, publisher.Messages()))
+	})
+}
+
+func TestSubscribersSink(t *testing.T) {
+	// given
+	pubSub := event.NewPubSub(nil)
+	var received []interface{}
+	collect := func(ctx context.Context, ev interface{}) error {
+		received = append(received, ev)
+		return nil
+	}
+	pubSub.Subscribe(process.OperationSucceeded{}, collect)
+	pubSub.Subscribe(process.OperationFinished{}, collect)
+	pubSub.Subscribe(process.DeprovisioningSucceeded{}, collect)
+	sink := outbox.NewSubscribersSink(pubSub)
+
+	// when
+	require.NoError(t, sink.Deliver(context.Background(), fixEvent(t, "op-1", "inst-1", domain.Succeeded)))
+	require.NoError(t, sink.Deliver(context.Background(), fixEvent(t, "op-2", "inst-1", internal.OperationStateCanceled)))
+
+	// then
+	require.Len(t, received, 3)
+	succeeded, ok := received[0].(process.OperationSucceeded)
+	require.True(t, ok)
+	assert.Equal(t, "op-1", succeeded.Operation.ID)
+	finished, ok := received[1].(process.OperationFinished)
+	require.True(t, ok)
+	assert.Equal(t, "op-1", finished.Operation.ID)
+	assert.Equal(t, fixture.PlanId, string(finished.PlanID))
+	deprovisioned, ok := received[2].(process.DeprovisioningSucceeded)
+	require.True(t, ok)
+	assert.Equal(t, "op-1", deprovisioned.Operation.ID)
+}
+
+func TestWebhookSink(t *testing.T) {
+	// given
+	var received outbox.Envelope
+	var eventID string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventID = r.Header.Get("X-Keb-Event-Id")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	sink := outbox.NewWebhookSink(server.URL, server.Client())
+	ev := fixEvent(t, "op-1", "inst-1", domain.Succeeded)
+	ev.ID = 7
+
+	// when
+	err := sink.Deliver(context.Background(), ev)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "7", eventID)
+	assert.Equal(t, "op-1", received.OperationID)
+	assert.Equal(t, internal.OperationFinishedOutboxEvent, received.Type)
+	assert.JSONEq(t, string(ev.Payload), string(received.Data))
+
+	// when
+	status = http.StatusServiceUnavailable
+	err = sink.Deliver(context.Background(), ev)
+
+	// then
+	assert.Error(t, err)
+}
+
+func fixEvent(t *testing.T, operationID, instanceID string, state domain.LastOperationState) internal.OutboxEvent {
+	operation := fixture.FixOperation(operationID, instanceID, internal.OperationTypeDeprovision)
+	operation.State = state
+	ev, err := internal.NewOperationFinishedOutboxEvent(operation)
+	require.NoError(t, err)
+	return ev
+}
+
+func operationIDs(t *testing.T, messages []outbox.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		var envelope outbox.Envelope
+		require.NoError(t, json.Unmarshal(message.Data, &envelope))
+		ids = append(ids, envelope.OperationID)
+	}
+	return ids
+}
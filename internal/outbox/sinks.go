@@ -0,0 +1,204 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+)
+
+const eventIDHeader = "X-Keb-Event-Id"
+
+// SubscribersSink translates outbox events to the process events and calls in-process subscribers, for example metrics.
+type SubscribersSink struct {
+	publisher event.SyncPublisher
+}
+
+func NewSubscribersSink(publisher event.SyncPublisher) *SubscribersSink {
+	return &SubscribersSink{publisher: publisher}
+}
+
+func (s *SubscribersSink) Name() string {
+	return "subscribers"
+}
+
+func (s *SubscribersSink) Deliver(ctx context.Context, ev internal.OutboxEvent) error {
+	if ev.Type != internal.OperationFinishedOutboxEvent {
+		return nil
+	}
+	var payload internal.OperationFinishedEvent
+	if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+		return fmt.Errorf("while unmarshalling the payload: %w", err)
+	}
+	operation := payload.Operation()
+
+	// subscribers of process events expect only succeeded or failed operations
+	var events []interface{}
+	switch operation.State {
+	case domain.Succeeded:
+		events = append(events, process.OperationSucceeded{Operation: operation})
+		events = append(events, process.OperationFinished{Operation: operation, PlanID: broker.PlanID(operation.ProvisioningParameters.PlanID)})
+		if operation.Type == internal.OperationTypeDeprovision {
+			events = append(events, process.DeprovisioningSucceeded{Operation: internal.DeprovisioningOperation{Operation: operation}})
+		}
+	case domain.Failed:
+		events = append(events, process.OperationFinished{Operation: operation, PlanID: broker.PlanID(operation.ProvisioningParameters.PlanID)})
+	}
+
+	for _, e := range events {
+		if err := s.publisher.PublishSync(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Envelope is the body sent by the WebhookSink and the MessageSink, receivers can use the ID to skip duplicates
+type Envelope struct {
+	ID          int64                    `json:"id"`
+	Type        internal.OutboxEventType `json:"type"`
+	InstanceID  string                   `json:"instanceId"`
+	OperationID string                   `json:"operationId"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	Data        json.RawMessage          `json:"data"`
+}
+
+// WebhookSink posts every event to the configured URL, any response other than 2xx is retried
+type WebhookSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewWebhookSink(url string, httpClient *http.Client) *WebhookSink {
+	return &WebhookSink{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, ev internal.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(ev))
+	if err != nil {
+		return fmt.Errorf("while marshalling the event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("while creating the request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(eventIDHeader, fmt.Sprintf("%d", ev.ID))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("while calling %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// MessagePublisher is implemented by clients of message brokers like NATS or Kafka. The key is used
+// for partitioning, messages with the same key must be kept in order.
+type MessagePublisher interface {
+	Publish(ctx context.Context, topic, key string, data []byte) error
+}
+
+// MessageSink publishes every event to the message broker, the instance ID is the message key
+type MessageSink struct {
+	publisher MessagePublisher
+	topic     string
+}
+
+func NewMessageSink(publisher MessagePublisher, topic string) *MessageSink {
+	return &MessageSink{
+		publisher: publisher,
+		topic:     topic,
+	}
+}
+
+func (s *MessageSink) Name() string {
+	return fmt.Sprintf("message:%s", s.topic)
+}
+
+func (s *MessageSink) Deliver(ctx context.Context, ev internal.OutboxEvent) error {
+	data, err := json.Marshal(NewEnvelope(ev))
+	if err != nil {
+		return fmt.Errorf("while marshalling the event: %w", err)
+	}
+	return s.publisher.Publish(ctx, s.topic, ev.InstanceID, data)
+}
+
+// Message is a message stored by the FakeMessagePublisher
+type Message struct {
+	Topic string
+	Key   string
+	Data  []byte
+}
+
+// FakeMessagePublisher keeps published messages in memory, it is used in tests instead of a message broker
+type FakeMessagePublisher struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+func NewFakeMessagePublisher() *FakeMessagePublisher {
+	return &FakeMessagePublisher{}
+}
+
+func (p *FakeMessagePublisher) Publish(_ context.Context, topic, key string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, Message{Topic: topic, Key: key, Data: data})
+	return nil
+}
+
+// FailWith makes all following Publish calls return the given error, nil restores publishing
+func (p *FakeMessagePublisher) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+func (p *FakeMessagePublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Message{}, p.messages...)
+}
+
+// NewEnvelope wraps the event payload with the event metadata
+func NewEnvelope(ev internal.OutboxEvent) Envelope {
+	return Envelope{
+		ID:          ev.ID,
+		Type:        ev.Type,
+		InstanceID:  ev.InstanceID,
+		OperationID: ev.OperationID,
+		CreatedAt:   ev.CreatedAt,
+		Data:        ev.Payload,
+	}
+}
@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"context"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dryRunClient keeps created and updated objects in memory instead of sending them to the cluster.
+// Objects are read from the reader, all objects are treated as not existing if the reader is not set.
+type dryRunClient struct {
+	// calling any other method of the embedded client panics, steps run in the preview must not use them
+	client.Client
+
+	reader client.Reader
+
+	mu      sync.Mutex
+	written []client.Object
+}
+
+func newDryRunClient(reader client.Reader) *dryRunClient {
+	return &dryRunClient{reader: reader}
+}
+
+func (c *dryRunClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if c.reader == nil {
+		return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	return c.reader.Get(ctx, key, obj, opts...)
+}
+
+func (c *dryRunClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.store(obj)
+	return nil
+}
+
+func (c *dryRunClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	c.store(obj)
+	return nil
+}
+
+func (c *dryRunClient) store(obj client.Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.written = append(c.written, obj.DeepCopyObject().(client.Object))
+}
+
+// objects returns all created and updated objects in the order of writes
+func (c *dryRunClient) objects() []client.Object {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]client.Object{}, c.written...)
+}
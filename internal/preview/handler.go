@@ -0,0 +1,349 @@
+package preview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/config"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/provisioning"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/update"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/subscriptions"
+	"github.com/kyma-project/kyma-environment-broker/internal/workers"
+
+	"github.com/google/uuid"
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Provisioner validates the provisioning request and returns the operation which would be created
+type Provisioner interface {
+	Preview(ctx context.Context, instanceID string, details domain.ProvisionDetails) (internal.ProvisioningOperation, error)
+}
+
+// Updater validates the update request and returns the operation which would be created together with the instance
+type Updater interface {
+	PreviewUpdate(ctx context.Context, instanceID string, details domain.UpdateDetails) (internal.Operation, *internal.Instance, error)
+}
+
+type router interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Handler returns resources which would be created or changed by the provisioning or the update without storing
+// any operation and without changing any resource. Validation of the request and steps creating the Runtime and
+// the Kyma resources are the same as used by the OSB API and the processing queues.
+type Handler struct {
+	provisioner                 Provisioner
+	updater                     Updater
+	kcpClient                   client.Reader
+	rulesService                *rules.RulesService
+	infrastructureManagerConfig broker.InfrastructureManager
+	oidcDefaultValues           pkg.OIDCConfigDTO
+	workersProvider             *workers.Provider
+	providerSpec                *configuration.ProviderSpec
+	configProvider              config.ConfigMapConfigProvider
+	valuesProvider              broker.ValuesProvider
+	trialPlatformRegionMapping  map[string]string
+	defaultRegion               string
+	log                         *slog.Logger
+}
+
+func NewHandler(provisioner Provisioner, updater Updater, kcpClient client.Reader, rulesService *rules.RulesService,
+	infrastructureManagerConfig broker.InfrastructureManager, oidcDefaultValues pkg.OIDCConfigDTO, workersProvider *workers.Provider,
+	providerSpec *configuration.ProviderSpec, configProvider config.ConfigMapConfigProvider, valuesProvider broker.ValuesProvider,
+	trialPlatformRegionMapping map[string]string, defaultRegion string, log *slog.Logger) *Handler {
+	return &Handler{
+		provisioner:                 provisioner,
+		updater:                     updater,
+		kcpClient:                   kcpClient,
+		rulesService:                rulesService,
+		infrastructureManagerConfig: infrastructureManagerConfig,
+		oidcDefaultValues:           oidcDefaultValues,
+		workersProvider:             workersProvider,
+		providerSpec:                providerSpec,
+		configProvider:              configProvider,
+		valuesProvider:              valuesProvider,
+		trialPlatformRegionMapping:  trialPlatformRegionMapping,
+		defaultRegion:               defaultRegion,
+		log:                         log.With("service", "PreviewEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	// the platform region is read from the path in the same way as for the OSB API
+	provision := middleware.AddRegionToContext(h.defaultRegion)(middleware.AddProviderToContext()(http.HandlerFunc(h.provision)))
+	r.Handle("POST /preview/provision", provision)
+	r.Handle("POST /preview/{region}/provision", provision)
+	r.Handle("POST /preview/update", http.HandlerFunc(h.update))
+}
+
+type conditionalStep struct {
+	step      process.Step
+	condition process.StepCondition
+}
+
+func (h *Handler) provision(w http.ResponseWriter, req *http.Request) {
+	var request ProvisionRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log.Warn(fmt.Sprintf("unable to decode request body: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	instanceID := request.InstanceID
+	if instanceID == "" {
+		instanceID = uuid.NewString()
+	}
+
+	provisioningOperation, err := h.provisioner.Preview(req.Context(), instanceID, request.ProvisionDetails)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response, err := h.previewProvisioning(provisioningOperation.Operation)
+	if err != nil {
+		h.log.Warn(fmt.Sprintf("unable to preview provisioning of instance %s: %s", instanceID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) update(w http.ResponseWriter, req *http.Request) {
+	var request UpdateRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log.Warn(fmt.Sprintf("unable to decode request body: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	if request.InstanceID == "" {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, errors.New("instance_id must be set"))
+		return
+	}
+
+	operation, instance, err := h.updater.PreviewUpdate(req.Context(), request.InstanceID, request.UpdateDetails)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response, err := h.previewUpdate(operation, *instance)
+	if err != nil {
+		h.log.Warn(fmt.Sprintf("unable to preview update of instance %s: %s", request.InstanceID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) previewProvisioning(operation internal.Operation) (Response, error) {
+	credentials, err := h.credentials(operation)
+	if err != nil {
+		return Response{}, err
+	}
+
+	// the credentials binding is resolved when the provisioning starts, the Runtime resource is created without it
+	if operation.ProvisioningParameters.Parameters.TargetSecret == nil {
+		operation.ProvisioningParameters.Parameters.TargetSecret = ptr.String("")
+	}
+	if h.providerSpec.ZonesDiscovery(pkg.CloudProviderFromString(operation.ProviderValues.ProviderType)) {
+		machineTypes := []string{provisioning.DefaultIfParamNotSet(operation.ProviderValues.DefaultMachineType, operation.ProvisioningParameters.Parameters.MachineType)}
+		for _, pool := range operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools {
+			machineTypes = append(machineTypes, pool.MachineType)
+		}
+		if err := h.useConfiguredZones(&operation, machineTypes); err != nil {
+			return Response{}, err
+		}
+	}
+
+	db := storage.NewMemoryStorage()
+	instance := internal.Instance{
+		InstanceID:      operation.InstanceID,
+		GlobalAccountID: operation.GlobalAccountID,
+		SubAccountID:    operation.SubAccountID,
+		ServiceID:       operation.ProvisioningParameters.ServiceID,
+		ServicePlanID:   operation.ProvisioningParameters.PlanID,
+		DashboardURL:    operation.DashboardURL,
+		Parameters:      operation.ProvisioningParameters,
+		InstanceDetails: operation.InstanceDetails,
+	}
+	cli := newDryRunClient(nil)
+	operation, err = h.run(db, instance, operation, []conditionalStep{
+		{step: steps.NewInitKymaTemplate(db.Operations(), h.configProvider)},
+		{step: provisioning.NewOverrideKymaModules(db.Operations())},
+		{step: provisioning.NewGenerateRuntimeIDStep(db.Operations(), db.Instances())},
+		{step: provisioning.NewCreateResourceNamesStep(db.Operations())},
+		{
+			step:      provisioning.NewCreateRuntimeResourceStep(db, cli, h.infrastructureManagerConfig, h.oidcDefaultValues, h.workersProvider, h.providerSpec),
+			condition: provisioning.SkipForOwnClusterPlan,
+		},
+		{step: provisioning.NewApplyKymaStep(db.Operations(), cli)},
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	response := newResponse(operation, cli)
+	response.Credentials = credentials
+	return response, nil
+}
+
+func (h *Handler) previewUpdate(operation internal.Operation, instance internal.Instance) (Response, error) {
+	// the same as done by the initialisation step of the update
+	providerValues := operation.ProviderValues
+	operation.InstanceDetails = instance.InstanceDetails
+	if operation.ProviderValues == nil {
+		operation.ProviderValues = providerValues
+	}
+	if operation.RuntimeID == "" {
+		operation.RuntimeID = instance.RuntimeID
+	}
+	if h.providerSpec.ZonesDiscovery(pkg.CloudProviderFromString(operation.ProviderValues.ProviderType)) {
+		var machineTypes []string
+		for _, pool := range operation.UpdatingParameters.AdditionalWorkerNodePools {
+			machineTypes = append(machineTypes, pool.MachineType)
+		}
+		if err := h.useConfiguredZones(&operation, machineTypes); err != nil {
+			return Response{}, err
+		}
+	}
+
+	db := storage.NewMemoryStorage()
+	// the current resources are read from the KCP cluster, the changed ones are not written
+	cli := newDryRunClient(h.kcpClient)
+	operation, err := h.run(db, instance, operation, []conditionalStep{
+		{
+			step:      update.NewUpdateRuntimeStep(db, cli, 0, h.infrastructureManagerConfig, h.trialPlatformRegionMapping, h.workersProvider, h.valuesProvider),
+			condition: update.SkipForOwnClusterPlan,
+		},
+		{step: update.NewUpdateKymaStep(db, cli, h.configProvider)},
+	})
+	if err != nil {
+		return Response{}, err
+	}
+	return newResponse(operation, cli), nil
+}
+
+// credentials returns the rule used to choose the credentials binding, the binding itself is not read from Gardener
+func (h *Handler) credentials(operation internal.Operation) (*CredentialsDTO, error) {
+	if broker.IsOwnClusterPlan(operation.ProvisioningParameters.PlanID) {
+		return nil, nil
+	}
+	if targetSecret := operation.ProvisioningParameters.Parameters.TargetSecret; targetSecret != nil && *targetSecret != "" {
+		return &CredentialsDTO{TargetSecret: *targetSecret}, nil
+	}
+
+	attributes := &rules.ProvisioningAttributes{
+		Plan:              broker.PlanNamesMapping[operation.ProvisioningParameters.PlanID],
+		PlatformRegion:    operation.ProvisioningParameters.PlatformRegion,
+		HyperscalerRegion: operation.ProviderValues.Region,
+		Hyperscaler:       operation.ProviderValues.ProviderType,
+	}
+	result, found := h.rulesService.MatchProvisioningAttributesWithValidRuleset(attributes)
+	if !found {
+		return nil, fmt.Errorf("no matching rule for provisioning attributes %q", attributes)
+	}
+
+	selector := subscriptions.NewLabelSelectorFromRuleset(result)
+	credentials := &CredentialsDTO{
+		MatchedRule:      result.NumberedRule(),
+		Hyperscaler:      result.Hyperscaler(),
+		Shared:           result.IsShared(),
+		EUAccess:         result.IsEUAccess(),
+		AssignedSelector: selector.BuildForTenantMatching(operation.ProvisioningParameters.ErsContext.GlobalAccountID),
+	}
+	// shared bindings are never claimed
+	if !result.IsShared() {
+		credentials.UnassignedSelector = selector.BuildForSecretBindingClaim()
+	}
+	return credentials, nil
+}
+
+// useConfiguredZones sets zones from the providers configuration as discovered ones, the hyperscaler API is not called in the preview
+func (h *Handler) useConfiguredZones(operation *internal.Operation, machineTypes []string) error {
+	if len(operation.ProviderValues.Zones) < operation.ProviderValues.ZonesCount {
+		return fmt.Errorf("%d zones are configured for region %s, %d are required", len(operation.ProviderValues.Zones), operation.ProviderValues.Region, operation.ProviderValues.ZonesCount)
+	}
+	operation.DiscoveredZones = make(map[string][]string)
+	for _, machineType := range machineTypes {
+		operation.DiscoveredZones[machineType] = operation.ProviderValues.Zones
+	}
+	return nil
+}
+
+// run executes steps one by one, a step which needs to be retried ends the preview
+func (h *Handler) run(db storage.BrokerStorage, instance internal.Instance, operation internal.Operation, stepsToRun []conditionalStep) (internal.Operation, error) {
+	if err := db.Instances().Insert(instance); err != nil {
+		return operation, fmt.Errorf("while inserting the instance: %w", err)
+	}
+	if err := db.Operations().InsertOperation(operation); err != nil {
+		return operation, fmt.Errorf("while inserting the operation: %w", err)
+	}
+
+	log := h.log.With("instanceID", operation.InstanceID, "operationID", operation.ID, "preview", true)
+	for _, s := range stepsToRun {
+		if s.condition != nil && !s.condition(operation) {
+			continue
+		}
+		processedOperation, backoff, err := s.step.Run(operation, log.With("step", s.step.Name()))
+		switch {
+		case err != nil:
+			return processedOperation, fmt.Errorf("step %s failed: %w", s.step.Name(), err)
+		case backoff > 0:
+			return processedOperation, fmt.Errorf("step %s cannot be completed: %s", s.step.Name(), processedOperation.Description)
+		}
+		operation = processedOperation
+	}
+	return operation, nil
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	var failureResponse *apiresponses.FailureResponse
+	if errors.As(err, &failureResponse) {
+		httputil.WriteErrorResponse(w, failureResponse.ValidatedStatusCode(h.log), err)
+		return
+	}
+	h.log.Error(fmt.Sprintf("unable to validate the request: %s", err.Error()))
+	httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+}
+
+func newResponse(operation internal.Operation, cli *dryRunClient) Response {
+	response := Response{
+		InstanceID: operation.InstanceID,
+		RuntimeID:  operation.RuntimeID,
+		PlanID:     operation.ProvisioningParameters.PlanID,
+		PlanName:   broker.PlanNamesMapping[operation.ProvisioningParameters.PlanID],
+	}
+	if operation.UpdatedPlanID != "" {
+		response.PlanID = operation.UpdatedPlanID
+		response.PlanName = broker.PlanNamesMapping[operation.UpdatedPlanID]
+	}
+	if operation.ProviderValues != nil {
+		response.ProviderValues = toProviderValuesDTO(*operation.ProviderValues)
+	}
+	for _, obj := range cli.objects() {
+		switch obj := obj.(type) {
+		case *imv1.Runtime:
+			response.Runtime = obj
+		case *unstructured.Unstructured:
+			response.Kyma = obj
+		}
+	}
+	return response
+}
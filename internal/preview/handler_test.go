@@ -0,0 +1,329 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/common/hyperscaler/rules"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/middleware"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/workers"
+
+	gardener "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/pivotal-cf/brokerapi/v12/domain/apiresponses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	instanceID      = "instance-id"
+	runtimeID       = "runtime-id"
+	globalAccountID = "global-account-id"
+)
+
+func TestHandler_Provision(t *testing.T) {
+	t.Run("should return resources which would be created", func(t *testing.T) {
+		// given
+		provisioner := &fakeProvisioner{operation: fixProvisioningOperation()}
+		router := fixRouter(t, provisioner, &fakeUpdater{}, fake.NewClientBuilder().Build())
+
+		// when
+		resp := call(router, "/preview/cf-eu10/provision", ProvisionRequest{
+			InstanceID:       instanceID,
+			ProvisionDetails: domain.ProvisionDetails{ServiceID: broker.KymaServiceID, PlanID: broker.AWSPlanID},
+		})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "cf-eu10", provisioner.region)
+		assert.Equal(t, instanceID, provisioner.instanceID)
+
+		var response Response
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+		assert.Equal(t, instanceID, response.InstanceID)
+		assert.NotEmpty(t, response.RuntimeID)
+		assert.Equal(t, broker.AWSPlanName, response.PlanName)
+		assert.Equal(t, "eu-central-1", response.ProviderValues.Region)
+
+		require.NotNil(t, response.Credentials)
+		assert.Equal(t, "aws", response.Credentials.Hyperscaler)
+		assert.Equal(t, "hyperscalerType=aws,!euAccess,shared!=true,!dirty,tenantName=global-account-id", response.Credentials.AssignedSelector)
+		assert.Equal(t, "hyperscalerType=aws,!euAccess,shared!=true,!dirty,!tenantName", response.Credentials.UnassignedSelector)
+
+		require.NotNil(t, response.Runtime)
+		assert.Equal(t, response.RuntimeID, response.Runtime.Name)
+		assert.Equal(t, "eu-central-1", response.Runtime.Spec.Shoot.Region)
+		assert.Equal(t, "m6i.large", response.Runtime.Spec.Shoot.Provider.Workers[0].Machine.Type)
+		assert.Equal(t, []string{"eu-central-1a"}, response.Runtime.Spec.Shoot.Provider.Workers[0].Zones)
+		assert.Empty(t, response.Runtime.Spec.Shoot.SecretBindingName)
+
+		require.NotNil(t, response.Kyma)
+		assert.Equal(t, response.RuntimeID, response.Kyma.GetName())
+		assert.Equal(t, broker.AWSPlanName, response.Kyma.GetLabels()[customresources.PlanNameLabel])
+	})
+
+	t.Run("should use the default region", func(t *testing.T) {
+		// given
+		provisioner := &fakeProvisioner{operation: fixProvisioningOperation()}
+		router := fixRouter(t, provisioner, &fakeUpdater{}, fake.NewClientBuilder().Build())
+
+		// when
+		resp := call(router, "/preview/provision", ProvisionRequest{
+			ProvisionDetails: domain.ProvisionDetails{ServiceID: broker.KymaServiceID, PlanID: broker.AWSPlanID},
+		})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "cf-eu11", provisioner.region)
+		assert.NotEmpty(t, provisioner.instanceID)
+	})
+
+	t.Run("should return the validation error", func(t *testing.T) {
+		// given
+		provisioner := &fakeProvisioner{err: apiresponses.NewFailureResponse(fmt.Errorf("machine type is not supported"), http.StatusBadRequest, "")}
+		router := fixRouter(t, provisioner, &fakeUpdater{}, fake.NewClientBuilder().Build())
+
+		// when
+		resp := call(router, "/preview/provision", ProvisionRequest{
+			ProvisionDetails: domain.ProvisionDetails{ServiceID: broker.KymaServiceID, PlanID: broker.AWSPlanID},
+		})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "machine type is not supported")
+	})
+
+	t.Run("should return an error when no rule matches", func(t *testing.T) {
+		// given
+		operation := fixProvisioningOperation()
+		operation.ProvisioningParameters.PlanID = broker.GCPPlanID
+		router := fixRouter(t, &fakeProvisioner{operation: operation}, &fakeUpdater{}, fake.NewClientBuilder().Build())
+
+		// when
+		resp := call(router, "/preview/provision", ProvisionRequest{
+			ProvisionDetails: domain.ProvisionDetails{ServiceID: broker.KymaServiceID, PlanID: broker.AWSPlanID},
+		})
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Contains(t, resp.Body.String(), "no matching rule")
+	})
+}
+
+func TestHandler_Update(t *testing.T) {
+	t.Run("should return changed resources without updating them", func(t *testing.T) {
+		// given
+		kcpClient := fake.NewClientBuilder().WithRuntimeObjects(fixRuntimeResource()).Build()
+		require.NoError(t, fixture.FixKymaResourceWithGivenRuntimeID(kcpClient, "kcp-system", runtimeID))
+		operation, instance := fixUpdatingOperation()
+		operation.UpdatingParameters.MachineType = ptr.String("m6i.xlarge")
+		operation.UpdatedPlanID = broker.BuildRuntimeAWSPlanID
+		router := fixRouter(t, &fakeProvisioner{}, &fakeUpdater{operation: operation, instance: instance}, kcpClient)
+
+		// when
+		resp := call(router, "/preview/update", UpdateRequest{
+			InstanceID:    instanceID,
+			UpdateDetails: domain.UpdateDetails{ServiceID: broker.KymaServiceID, PlanID: broker.BuildRuntimeAWSPlanID},
+		})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var response Response
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+		assert.Equal(t, runtimeID, response.RuntimeID)
+		assert.Equal(t, broker.BuildRuntimeAWSPlanName, response.PlanName)
+		assert.Nil(t, response.Credentials)
+		require.NotNil(t, response.Runtime)
+		assert.Equal(t, "m6i.xlarge", response.Runtime.Spec.Shoot.Provider.Workers[0].Machine.Type)
+		require.NotNil(t, response.Kyma)
+		assert.Equal(t, broker.BuildRuntimeAWSPlanName, response.Kyma.GetLabels()[customresources.PlanNameLabel])
+
+		var runtime imv1.Runtime
+		require.NoError(t, kcpClient.Get(context.Background(), client.ObjectKey{Name: runtimeID, Namespace: "kcp-system"}, &runtime))
+		assert.Equal(t, "m6i.large", runtime.Spec.Shoot.Provider.Workers[0].Machine.Type)
+	})
+
+	t.Run("should not return the Kyma resource when the plan does not change", func(t *testing.T) {
+		// given
+		kcpClient := fake.NewClientBuilder().WithRuntimeObjects(fixRuntimeResource()).Build()
+		operation, instance := fixUpdatingOperation()
+		operation.UpdatingParameters.AutoScalerMax = ptr.Integer(10)
+		router := fixRouter(t, &fakeProvisioner{}, &fakeUpdater{operation: operation, instance: instance}, kcpClient)
+
+		// when
+		resp := call(router, "/preview/update", UpdateRequest{
+			InstanceID:    instanceID,
+			UpdateDetails: domain.UpdateDetails{ServiceID: broker.KymaServiceID, PlanID: broker.AWSPlanID},
+		})
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var response Response
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+		require.NotNil(t, response.Runtime)
+		assert.Equal(t, int32(10), response.Runtime.Spec.Shoot.Provider.Workers[0].Maximum)
+		assert.Nil(t, response.Kyma)
+	})
+
+	t.Run("should return an error when the Runtime resource does not exist", func(t *testing.T) {
+		// given
+		operation, instance := fixUpdatingOperation()
+		router := fixRouter(t, &fakeProvisioner{}, &fakeUpdater{operation: operation, instance: instance}, fake.NewClientBuilder().Build())
+
+		// when
+		resp := call(router, "/preview/update", UpdateRequest{InstanceID: instanceID})
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Update_Runtime_Resource")
+	})
+
+	t.Run("should require the instance ID", func(t *testing.T) {
+		// given
+		router := fixRouter(t, &fakeProvisioner{}, &fakeUpdater{}, fake.NewClientBuilder().Build())
+
+		// when
+		resp := call(router, "/preview/update", UpdateRequest{})
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+type fakeProvisioner struct {
+	operation internal.Operation
+	err       error
+
+	instanceID string
+	region     string
+}
+
+func (f *fakeProvisioner) Preview(ctx context.Context, instanceID string, details domain.ProvisionDetails) (internal.ProvisioningOperation, error) {
+	f.instanceID = instanceID
+	f.region, _ = middleware.RegionFromContext(ctx)
+	if f.err != nil {
+		return internal.ProvisioningOperation{}, f.err
+	}
+	operation := f.operation
+	operation.InstanceID = instanceID
+	operation.ProvisioningParameters.PlatformRegion = f.region
+	return internal.ProvisioningOperation{Operation: operation}, nil
+}
+
+type fakeUpdater struct {
+	operation internal.Operation
+	instance  internal.Instance
+}
+
+func (f *fakeUpdater) PreviewUpdate(_ context.Context, _ string, _ domain.UpdateDetails) (internal.Operation, *internal.Instance, error) {
+	instance := f.instance
+	return f.operation, &instance, nil
+}
+
+func fixRouter(t *testing.T, provisioner Provisioner, updater Updater, kcpClient client.Reader) *httputil.Router {
+	require.NoError(t, imv1.AddToScheme(scheme.Scheme))
+	rulesService, err := rules.NewRulesServiceFromSlice([]string{"aws"}, sets.New("aws"), sets.New("aws"))
+	require.NoError(t, err)
+	providerSpec := fixture.NewProviderSpecWithZonesDiscovery(t, false)
+	imConfig := broker.InfrastructureManager{DefaultGardenerShootPurpose: "development"}
+
+	handler := NewHandler(provisioner, updater, kcpClient, rulesService, imConfig, pkg.OIDCConfigDTO{}, workers.NewProvider(imConfig, providerSpec),
+		providerSpec, fixture.FakeKymaConfigProvider{}, nil, nil, "cf-eu11", slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	router := httputil.NewRouter()
+	handler.AttachRoutes(router)
+	return router
+}
+
+func call(router *httputil.Router, path string, body any) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func fixProvisioningOperation() internal.Operation {
+	operation := fixture.FixProvisioningOperationWithProvisioningParameters("operation-id", instanceID, internal.ProvisioningParameters{
+		PlanID:     broker.AWSPlanID,
+		ServiceID:  broker.KymaServiceID,
+		ErsContext: internal.ERSContext{GlobalAccountID: globalAccountID, SubAccountID: "sub-account-id", UserID: "user@example.com"},
+		Parameters: pkg.ProvisioningParametersDTO{Name: "my-cluster"},
+	})
+	operation.RuntimeID = ""
+	operation.KymaTemplate = ""
+	operation.ProviderValues = &internal.ProviderValues{
+		DefaultAutoScalerMax: 20,
+		DefaultAutoScalerMin: 3,
+		ZonesCount:           1,
+		Zones:                []string{"eu-central-1a"},
+		ProviderType:         "aws",
+		DefaultMachineType:   "m6i.large",
+		Region:               "eu-central-1",
+		Purpose:              "development",
+		VolumeSizeGb:         80,
+		DiskType:             "gp3",
+	}
+	return operation
+}
+
+func fixUpdatingOperation() (internal.Operation, internal.Instance) {
+	instance := fixture.FixInstance(instanceID)
+	instance.RuntimeID = runtimeID
+	instance.InstanceDetails.RuntimeID = runtimeID
+	instance.InstanceDetails.KymaResourceNamespace = "kcp-system"
+	instance.InstanceDetails.KymaResourceName = runtimeID
+	instance.InstanceDetails.RuntimeResourceName = runtimeID
+
+	operation := fixture.FixUpdatingOperation("operation-id", instanceID).Operation
+	operation.UpdatingParameters = internal.UpdatingParametersDTO{}
+	operation.KymaTemplate = ""
+	operation.ProviderValues = &internal.ProviderValues{ProviderType: "aws", Region: "eu-central-1", ZonesCount: 1, Zones: []string{"eu-central-1a"}}
+	return operation, instance
+}
+
+func fixRuntimeResource() *imv1.Runtime {
+	maxSurge := intstr.FromInt32(1)
+	maxUnavailable := intstr.FromInt32(0)
+	return &imv1.Runtime{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      runtimeID,
+			Namespace: "kcp-system",
+		},
+		Spec: imv1.RuntimeSpec{
+			Shoot: imv1.RuntimeShoot{
+				Provider: imv1.Provider{
+					Workers: []gardener.Worker{
+						{
+							Machine:        gardener.Machine{Type: "m6i.large"},
+							Minimum:        3,
+							Maximum:        20,
+							MaxSurge:       &maxSurge,
+							MaxUnavailable: &maxUnavailable,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package preview
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/internal"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ProvisionRequest is the body of the OSB provisioning request extended with the optional instance ID
+type ProvisionRequest struct {
+	InstanceID string `json:"instance_id"`
+	domain.ProvisionDetails
+}
+
+// UpdateRequest is the body of the OSB update request extended with the ID of the updated instance
+type UpdateRequest struct {
+	InstanceID string `json:"instance_id"`
+	domain.UpdateDetails
+}
+
+type Response struct {
+	InstanceID     string            `json:"instanceID"`
+	RuntimeID      string            `json:"runtimeID,omitempty"`
+	PlanID         string            `json:"planID"`
+	PlanName       string            `json:"planName"`
+	ProviderValues ProviderValuesDTO `json:"providerValues"`
+	// Credentials is not set for the update and for plans which do not use hyperscaler accounts
+	Credentials *CredentialsDTO `json:"credentials,omitempty"`
+	// Runtime is the Runtime resource which would be created or the existing one with the update applied
+	Runtime *imv1.Runtime `json:"runtime,omitempty"`
+	// Kyma is the Kyma resource which would be created or updated, it is not set when the Kyma resource does not change
+	Kyma *unstructured.Unstructured `json:"kyma,omitempty"`
+}
+
+type ProviderValuesDTO struct {
+	ProviderType         string   `json:"providerType"`
+	Region               string   `json:"region"`
+	Zones                []string `json:"zones"`
+	ZonesCount           int      `json:"zonesCount"`
+	DefaultMachineType   string   `json:"defaultMachineType"`
+	DefaultAutoScalerMin int      `json:"defaultAutoScalerMin"`
+	DefaultAutoScalerMax int      `json:"defaultAutoScalerMax"`
+	VolumeSizeGb         int      `json:"volumeSizeGb,omitempty"`
+	DiskType             string   `json:"diskType,omitempty"`
+	Purpose              string   `json:"purpose,omitempty"`
+	FailureTolerance     *string  `json:"failureTolerance,omitempty"`
+}
+
+// CredentialsDTO describes how the credentials binding of the hyperscaler account is chosen. The binding is resolved
+// during the provisioning: the binding already assigned to the global account is used, otherwise a free binding is claimed.
+type CredentialsDTO struct {
+	MatchedRule        string `json:"matchedRule"`
+	Hyperscaler        string `json:"hyperscaler"`
+	Shared             bool   `json:"shared"`
+	EUAccess           bool   `json:"euAccess"`
+	AssignedSelector   string `json:"assignedSelector"`
+	UnassignedSelector string `json:"unassignedSelector,omitempty"`
+	// TargetSecret is set when the request provides the binding, the rules are not used then
+	TargetSecret string `json:"targetSecret,omitempty"`
+}
+
+func toProviderValuesDTO(values internal.ProviderValues) ProviderValuesDTO {
+	return ProviderValuesDTO{
+		ProviderType:         values.ProviderType,
+		Region:               values.Region,
+		Zones:                values.Zones,
+		ZonesCount:           values.ZonesCount,
+		DefaultMachineType:   values.DefaultMachineType,
+		DefaultAutoScalerMin: values.DefaultAutoScalerMin,
+		DefaultAutoScalerMax: values.DefaultAutoScalerMax,
+		VolumeSizeGb:         values.VolumeSizeGb,
+		DiskType:             values.DiskType,
+		Purpose:              values.Purpose,
+		FailureTolerance:     values.FailureTolerance,
+	}
+}
@@ -185,7 +185,12 @@ func (om *OperationManager) UpdateOperation(operation internal.Operation, update
 			}
 			// do not optimize the flow by skipping the update call - it's required to update the `UpdatedAt` field
 			op.Merge(&operation)
+			canceling := op.State == internal.OperationStateCanceling
 			update(op)
+			// the cancellation requested in the meantime must not be overridden by a step
+			if canceling && op.State == domain.InProgress {
+				op.State = internal.OperationStateCanceling
+			}
 			op, err = om.storage.UpdateOperation(*op)
 			if err != nil {
 				log.Error(fmt.Sprintf("while updating operation after conflict: %v", err))
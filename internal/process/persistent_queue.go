@@ -0,0 +1,256 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// OperationQueue is implemented by the in-memory Queue and by the storage backed PersistentQueue
+type OperationQueue interface {
+	Add(processId string)
+	AddAfter(processId string, duration time.Duration)
+	Run(stop <-chan struct{}, workersAmount int)
+	ShutDown()
+	SpeedUp(speedFactor int64)
+}
+
+type PersistentQueueConfig struct {
+	// Time after which an operation claimed by a worker becomes available for other workers (also in other replicas)
+	// if the lease is not extended
+	LeaseDuration time.Duration `envconfig:"default=5m"`
+	// How often a worker extends the lease of the processed operation
+	HeartbeatInterval time.Duration `envconfig:"default=1m"`
+	// How often an idle worker checks the storage for operations ready to be processed
+	PollInterval time.Duration `envconfig:"default=2s"`
+}
+
+func (c PersistentQueueConfig) String() string {
+	return fmt.Sprintf("(LeaseDuration=%s; HeartbeatInterval=%s; PollInterval=%s)", c.LeaseDuration, c.HeartbeatInterval, c.PollInterval)
+}
+
+// PersistentQueue keeps queued operations in the storage, so they survive restarts and can be shared
+// between many broker replicas. Every operation is claimed exclusively by one worker, which prevents
+// concurrent processing of the same operation.
+type PersistentQueue struct {
+	storage   storage.OperationQueue
+	executor  Executor
+	cfg       PersistentQueueConfig
+	waitGroup sync.WaitGroup
+	log       *slog.Logger
+	name      string
+	owner     string
+
+	notify   chan struct{}
+	shutdown chan struct{}
+	once     sync.Once
+
+	speedFactor int64
+}
+
+func NewPersistentQueue(executor Executor, queueStorage storage.OperationQueue, cfg PersistentQueueConfig, log *slog.Logger, name string) *PersistentQueue {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "keb"
+	}
+	return &PersistentQueue{
+		storage:     queueStorage,
+		executor:    executor,
+		cfg:         cfg,
+		waitGroup:   sync.WaitGroup{},
+		log:         log.With("queueName", name),
+		name:        name,
+		owner:       fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+		notify:      make(chan struct{}, 1),
+		shutdown:    make(chan struct{}),
+		speedFactor: 1,
+	}
+}
+
+func (q *PersistentQueue) Add(processId string) {
+	q.AddAfter(processId, 0)
+}
+
+func (q *PersistentQueue) AddAfter(processId string, duration time.Duration) {
+	err := q.storage.Add(q.name, processId, duration)
+	if err != nil {
+		q.log.Error(fmt.Sprintf("unable to add item %s to the queue %s: %s", processId, q.name, err))
+		return
+	}
+	q.log.Info(fmt.Sprintf("item %s will be added to the queue %s after duration of %d", processId, q.name, duration))
+	if duration == 0 {
+		q.wakeUp()
+		return
+	}
+	time.AfterFunc(duration, q.wakeUp)
+}
+
+func (q *PersistentQueue) ShutDown() {
+	q.log.Info("shutting down the queue")
+	q.once.Do(func() {
+		close(q.shutdown)
+	})
+}
+
+func (q *PersistentQueue) Run(stop <-chan struct{}, workersAmount int) {
+	for i := 0; i < workersAmount; i++ {
+		q.waitGroup.Add(1)
+
+		workerLogger := q.log.With("workerId", i)
+
+		go func() {
+			wait.Until(q.worker(stop, workerLogger), time.Second, q.shutdown)
+			q.waitGroup.Done()
+		}()
+	}
+}
+
+// SpeedUp changes speedFactor parameter to reduce time between processing operations.
+// This method should only be used for testing purposes
+func (q *PersistentQueue) SpeedUp(speedFactor int64) {
+	q.speedFactor = speedFactor
+	q.log.Info(fmt.Sprintf("queue speed factor set to %d", speedFactor))
+}
+
+func (q *PersistentQueue) wakeUp() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *PersistentQueue) worker(stop <-chan struct{}, log *slog.Logger) func() {
+	return func() {
+		for {
+			select {
+			case <-q.shutdown:
+				log.Info("shutting down")
+				return
+			default:
+			}
+
+			id, err := q.storage.Claim(q.name, q.owner, q.cfg.LeaseDuration)
+			switch {
+			case err == nil:
+				q.process(id, log.With("operationID", id))
+				continue
+			case !dberr.IsNotFound(err):
+				log.Error(fmt.Sprintf("unable to claim an item from the queue: %s", err))
+			}
+
+			select {
+			case <-stop:
+				q.ShutDown()
+				log.Info("shutting down")
+				return
+			case <-q.shutdown:
+				log.Info("shutting down")
+				return
+			case <-q.notify:
+			case <-time.After(q.cfg.PollInterval):
+			}
+		}
+	}
+}
+
+func (q *PersistentQueue) process(id string, log *slog.Logger) {
+	log.Info(fmt.Sprintf("about to process item %s", id))
+
+	ctx, stopHeartbeat := q.heartbeat(id, log)
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("panic error from process: %v. Stacktrace: %s", err, debug.Stack()))
+			if ctx.Err() == nil {
+				q.remove(id, log)
+			}
+		}
+		stopHeartbeat()
+		log.Info("queue done processing")
+	}()
+
+	when, err := q.execute(ctx, id)
+	if ctx.Err() != nil {
+		// another worker can process the operation, the item is no longer owned by this worker
+		log.Warn(fmt.Sprintf("stopped processing item %s: %s", id, context.Cause(ctx)))
+		return
+	}
+	if err == nil && when != 0 {
+		afterDuration := time.Duration(int64(when) / q.speedFactor)
+		log.Info(fmt.Sprintf("Adding %q item after %s", id, afterDuration))
+		if err := q.storage.Release(id, q.owner, afterDuration); err != nil {
+			log.Error(fmt.Sprintf("unable to release item %s: %s", id, err))
+			return
+		}
+		time.AfterFunc(afterDuration, q.wakeUp)
+		return
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("Error from process: %v", err))
+	}
+
+	q.remove(id, log)
+}
+
+func (q *PersistentQueue) remove(id string, log *slog.Logger) {
+	if err := q.storage.Remove(id, q.owner); err != nil {
+		log.Error(fmt.Sprintf("unable to remove item %s from the queue: %s", id, err))
+		return
+	}
+	log.Info(fmt.Sprintf("item for %s has been processed, no retry, element removed", id))
+}
+
+func (q *PersistentQueue) execute(ctx context.Context, id string) (time.Duration, error) {
+	if executor, ok := q.executor.(ContextExecutor); ok {
+		return executor.ExecuteWithContext(ctx, id)
+	}
+	return q.executor.Execute(id)
+}
+
+// heartbeat extends the lease of the processed operation until the returned function is called.
+// The returned context is canceled when the lease is lost: the item is leased by another worker or the lease
+// could expire before the next heartbeat, because it could not be extended.
+func (q *PersistentQueue) heartbeat(id string, log *slog.Logger) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(q.cfg.HeartbeatInterval)
+		defer ticker.Stop()
+		leaseExpiresAt := time.Now().Add(q.cfg.LeaseDuration)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := q.storage.ExtendLease(id, q.owner, q.cfg.LeaseDuration)
+				switch {
+				case err == nil:
+					leaseExpiresAt = time.Now().Add(q.cfg.LeaseDuration)
+				case dberr.IsNotFound(err):
+					log.Error(fmt.Sprintf("lease of item %s is lost: %s", id, err))
+					cancel(fmt.Errorf("lease of item %s is lost", id))
+					return
+				case time.Until(leaseExpiresAt) <= q.cfg.HeartbeatInterval:
+					log.Error(fmt.Sprintf("unable to extend the lease of item %s before it expires: %s", id, err))
+					cancel(fmt.Errorf("lease of item %s expires", id))
+					return
+				default:
+					log.Warn(fmt.Sprintf("unable to extend the lease of item %s: %s", id, err))
+				}
+			}
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		cancel(nil)
+	}
+}
@@ -0,0 +1,212 @@
+package process
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/driver/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingExecutor struct {
+	mu         sync.Mutex
+	executions map[string]int
+	running    map[string]bool
+	concurrent atomic.Bool
+	retryOnce  bool
+	done       sync.WaitGroup
+}
+
+func (e *countingExecutor) Execute(operationID string) (time.Duration, error) {
+	e.mu.Lock()
+	if e.running[operationID] {
+		e.concurrent.Store(true)
+	}
+	e.running[operationID] = true
+	e.executions[operationID]++
+	executions := e.executions[operationID]
+	e.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	e.mu.Lock()
+	e.running[operationID] = false
+	e.mu.Unlock()
+
+	if e.retryOnce && executions == 1 {
+		return time.Millisecond, nil
+	}
+	e.done.Done()
+	return 0, nil
+}
+
+// leaseAwareExecutor waits until the context is done, as a manager running long steps
+type leaseAwareExecutor struct {
+	interrupted chan error
+}
+
+func (e *leaseAwareExecutor) Execute(operationID string) (time.Duration, error) {
+	return 0, nil
+}
+
+func (e *leaseAwareExecutor) ExecuteWithContext(ctx context.Context, operationID string) (time.Duration, error) {
+	select {
+	case <-ctx.Done():
+		e.interrupted <- context.Cause(ctx)
+		return 0, context.Cause(ctx)
+	case <-time.After(time.Second):
+		e.interrupted <- nil
+		return 0, nil
+	}
+}
+
+// stolenLeaseQueue behaves as if another worker took over the lease of every claimed item
+type stolenLeaseQueue struct {
+	storage.OperationQueue
+	removed atomic.Bool
+}
+
+func (q *stolenLeaseQueue) ExtendLease(operationID, owner string, leaseDuration time.Duration) error {
+	return dberr.NotFound("operation %s is not leased by %s", operationID, owner)
+}
+
+func (q *stolenLeaseQueue) Remove(operationID, owner string) error {
+	q.removed.Store(true)
+	return q.OperationQueue.Remove(operationID, owner)
+}
+
+func TestPersistentQueue(t *testing.T) {
+	cfg := PersistentQueueConfig{
+		LeaseDuration:     time.Minute,
+		HeartbeatInterval: 10 * time.Second,
+		PollInterval:      10 * time.Millisecond,
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	t.Run("should process operations once across many queue instances sharing the storage", func(t *testing.T) {
+		// given
+		queueStorage := memory.NewOperationQueue()
+		executor := &countingExecutor{executions: map[string]int{}, running: map[string]bool{}}
+		executor.done.Add(3)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		replica1 := NewPersistentQueue(executor, queueStorage, cfg, logger, "test")
+		replica2 := NewPersistentQueue(executor, queueStorage, cfg, logger, "test")
+
+		// when
+		replica1.Add("op-1")
+		replica1.Add("op-2")
+		replica2.Add("op-3")
+		replica2.Add("op-1")
+		replica1.Run(ctx.Done(), 2)
+		replica2.Run(ctx.Done(), 2)
+		executor.done.Wait()
+
+		// then
+		assert.Eventually(t, func() bool {
+			count, err := queueStorage.Count("test")
+			require.NoError(t, err)
+			return count == 0
+		}, time.Second, 10*time.Millisecond)
+		assert.False(t, executor.concurrent.Load())
+		executor.mu.Lock()
+		assert.Equal(t, map[string]int{"op-1": 1, "op-2": 1, "op-3": 1}, executor.executions)
+		executor.mu.Unlock()
+
+		replica1.ShutDown()
+		replica2.ShutDown()
+		replica1.waitGroup.Wait()
+		replica2.waitGroup.Wait()
+	})
+
+	t.Run("should process an operation again when the executor requests a retry", func(t *testing.T) {
+		// given
+		queueStorage := memory.NewOperationQueue()
+		executor := &countingExecutor{executions: map[string]int{}, running: map[string]bool{}, retryOnce: true}
+		executor.done.Add(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		queue := NewPersistentQueue(executor, queueStorage, cfg, logger, "test")
+
+		// when
+		queue.Add("op-1")
+		queue.Run(ctx.Done(), 1)
+		executor.done.Wait()
+
+		// then
+		executor.mu.Lock()
+		assert.Equal(t, 2, executor.executions["op-1"])
+		executor.mu.Unlock()
+
+		cancel()
+		queue.waitGroup.Wait()
+	})
+
+	t.Run("should stop processing and keep the item when the lease is lost", func(t *testing.T) {
+		// given
+		queueStorage := &stolenLeaseQueue{OperationQueue: memory.NewOperationQueue()}
+		executor := &leaseAwareExecutor{interrupted: make(chan error, 1)}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		queue := NewPersistentQueue(executor, queueStorage, PersistentQueueConfig{
+			LeaseDuration:     time.Minute,
+			HeartbeatInterval: 10 * time.Millisecond,
+			PollInterval:      10 * time.Millisecond,
+		}, logger, "test")
+
+		// when
+		queue.Add("op-1")
+		queue.Run(ctx.Done(), 1)
+
+		// then
+		select {
+		case err := <-executor.interrupted:
+			assert.EqualError(t, err, "lease of item op-1 is lost")
+		case <-time.After(2 * time.Second):
+			t.Fatal("processing was not interrupted")
+		}
+		cancel()
+		queue.ShutDown()
+		queue.waitGroup.Wait()
+		assert.False(t, queueStorage.removed.Load())
+		count, err := queueStorage.Count("test")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("should take over an operation with the expired lease", func(t *testing.T) {
+		// given
+		queueStorage := memory.NewOperationQueue()
+		require.NoError(t, queueStorage.Add("test", "op-1", 0))
+		_, err := queueStorage.Claim("test", "crashed-replica", time.Millisecond)
+		require.NoError(t, err)
+
+		executor := &countingExecutor{executions: map[string]int{}, running: map[string]bool{}}
+		executor.done.Add(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		queue := NewPersistentQueue(executor, queueStorage, cfg, logger, "test")
+
+		// when
+		queue.Run(ctx.Done(), 1)
+		executor.done.Wait()
+
+		// then
+		executor.mu.Lock()
+		assert.Equal(t, 1, executor.executions["op-1"])
+		executor.mu.Unlock()
+
+		cancel()
+		queue.waitGroup.Wait()
+	})
+}
@@ -85,7 +85,7 @@ func (s *CreateRuntimeResourceStep) Run(operation internal.Operation, log *slog.
 		return operation, 0, nil
 	} else {
 		var backoff time.Duration
-		err = s.updateRuntimeResourceObject(log, *operation.ProviderValues, runtimeCR, operation, runtimeResourceName, operation.CloudProvider)
+		err = s.updateRuntimeResourceObject(log, *operation.ProviderValues, runtimeCR, operation, runtimeResourceName, operation.CloudProvider, nil)
 		if err != nil {
 			return s.operationManager.OperationFailed(operation, fmt.Sprintf("while creating Runtime CR object: %s", err), err, log)
 		}
@@ -99,6 +99,7 @@ func (s *CreateRuntimeResourceStep) Run(operation internal.Operation, log *slog.
 		operation, backoff, _ = s.operationManager.UpdateOperation(operation, func(op *internal.Operation) {
 			op.Region = runtimeCR.Spec.Shoot.Region
 			op.CloudProvider = operation.CloudProvider
+			op.KubernetesVersion = ptr.ToString(runtimeCR.Spec.Shoot.Kubernetes.Version)
 		}, log)
 		if backoff > 0 {
 			return s.operationManager.RetryOperation(operation, "cannot update operation", err, dbRetryInterval, dbRetryTimeout, log)
@@ -122,14 +123,27 @@ func (s *CreateRuntimeResourceStep) Run(operation internal.Operation, log *slog.
 	}
 }
 
-func (s *CreateRuntimeResourceStep) updateRuntimeResourceObject(log *slog.Logger, values internal.ProviderValues, runtime *imv1.Runtime, operation internal.Operation, runtimeName, cloudProvider string) error {
+// RuntimeResource returns the Runtime resource built from the operation the same way as when the step creates it.
+// Zones are chosen only once, so the zones of the Kyma worker node pool and of the existing additional worker node pools are taken from the current resource.
+func (s *CreateRuntimeResourceStep) RuntimeResource(operation internal.Operation, current *imv1.Runtime, log *slog.Logger) (*imv1.Runtime, error) {
+	operation.CloudProvider = string(provider.ProviderToCloudProvider(operation.ProviderValues.ProviderType))
+
+	runtimeCR := &imv1.Runtime{}
+	err := s.updateRuntimeResourceObject(log, *operation.ProviderValues, runtimeCR, operation, steps.KymaRuntimeResourceName(operation), operation.CloudProvider, current)
+	if err != nil {
+		return nil, err
+	}
+	return runtimeCR, nil
+}
+
+func (s *CreateRuntimeResourceStep) updateRuntimeResourceObject(log *slog.Logger, values internal.ProviderValues, runtime *imv1.Runtime, operation internal.Operation, runtimeName, cloudProvider string, current *imv1.Runtime) error {
 
 	runtime.ObjectMeta.Name = runtimeName
 	runtime.ObjectMeta.Namespace = operation.KymaResourceNamespace
 
 	runtime.ObjectMeta.Labels = s.createLabelsForRuntime(operation, values.Region, cloudProvider)
 
-	providerObj, err := s.createShootProvider(log, &operation, values)
+	providerObj, err := s.createShootProvider(log, &operation, values, current)
 	if err != nil {
 		return err
 	}
@@ -184,7 +198,7 @@ func (s *CreateRuntimeResourceStep) createSecurityConfiguration(operation intern
 	return security
 }
 
-func (s *CreateRuntimeResourceStep) createShootProvider(log *slog.Logger, operation *internal.Operation, values internal.ProviderValues) (imv1.Provider, error) {
+func (s *CreateRuntimeResourceStep) createShootProvider(log *slog.Logger, operation *internal.Operation, values internal.ProviderValues, current *imv1.Runtime) (imv1.Provider, error) {
 
 	maxSurge := intstr.FromInt32(int32(DefaultIfParamNotSet(values.ZonesCount, operation.ProvisioningParameters.Parameters.MaxSurge)))
 	maxUnavailable := intstr.FromInt32(int32(DefaultIfParamNotSet(0, operation.ProvisioningParameters.Parameters.MaxUnavailable)))
@@ -193,7 +207,17 @@ func (s *CreateRuntimeResourceStep) createShootProvider(log *slog.Logger, operat
 	scalerMin := int32(DefaultIfParamNotSet(values.DefaultAutoScalerMin, operation.ProvisioningParameters.Parameters.AutoScalerMin))
 
 	zones := values.Zones
-	if s.providerSpec.ZonesDiscovery(pkg.CloudProviderFromString(operation.ProviderValues.ProviderType)) {
+	var currentAdditionalWorkers map[string]gardener.Worker
+	switch {
+	case current != nil && len(current.Spec.Shoot.Provider.Workers) > 0:
+		zones = current.Spec.Shoot.Provider.Workers[0].Zones
+		currentAdditionalWorkers = make(map[string]gardener.Worker)
+		if current.Spec.Shoot.Provider.AdditionalWorkers != nil {
+			for _, worker := range *current.Spec.Shoot.Provider.AdditionalWorkers {
+				currentAdditionalWorkers[worker.Name] = worker
+			}
+		}
+	case s.providerSpec.ZonesDiscovery(pkg.CloudProviderFromString(operation.ProviderValues.ProviderType)):
 		zones = operation.DiscoveredZones[DefaultIfParamNotSet(values.DefaultMachineType, operation.ProvisioningParameters.Parameters.MachineType)]
 		zones = zones[:values.ZonesCount]
 	}
@@ -227,7 +251,7 @@ func (s *CreateRuntimeResourceStep) createShootProvider(log *slog.Logger, operat
 		}
 	}
 
-	additionalWorkers, err := s.workersProvider.CreateAdditionalWorkers(values, nil, operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools,
+	additionalWorkers, err := s.workersProvider.CreateAdditionalWorkers(values, currentAdditionalWorkers, operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools,
 		values.Zones, operation.ProvisioningParameters.PlanID, operation.DiscoveredZones, log)
 	if err != nil {
 		return imv1.Provider{}, fmt.Errorf("while creating additional workers: %w", err)
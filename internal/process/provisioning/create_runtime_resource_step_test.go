@@ -1246,6 +1246,39 @@ func TestCreateRuntimeResourceStep_AWS_ZonesDiscovery(t *testing.T) {
 	assert.Subset(t, []string{"zone-i", "zone-j", "zone-k", "zone-l"}, (*runtime.Spec.Shoot.Provider.AdditionalWorkers)[1].Zones)
 }
 
+func TestCreateRuntimeResourceStep_RuntimeResourceKeepsCurrentZones(t *testing.T) {
+	// given
+	inputConfig := broker.InfrastructureManager{MultiZoneCluster: true, DefaultGardenerShootPurpose: provider.PurposeProduction}
+	providerSpec := fixture.NewProviderSpecWithZonesDiscovery(t, true)
+
+	_, operation := fixInstanceAndOperation(broker.AWSPlanID, "eu-west-2", "platform-region", inputConfig, pkg.AWS)
+	operation.ProvisioningParameters.Parameters.AdditionalWorkerNodePools = []pkg.AdditionalWorkerNodePool{
+		{Name: "existing", MachineType: "m6i.large", HAZones: true, AutoScalerMin: 3, AutoScalerMax: 20},
+		{Name: "new", MachineType: "m5.large", HAZones: false, AutoScalerMin: 1, AutoScalerMax: 1},
+	}
+	// zones are discovered only for the new additional worker node pool
+	operation.DiscoveredZones = map[string][]string{
+		"m5.large": {"zone-x", "zone-y", "zone-z"},
+	}
+
+	current := &imv1.Runtime{}
+	current.Spec.Shoot.Provider.Workers = []gardener.Worker{{Name: "cpu-worker-0", Zones: []string{"zone-a", "zone-b", "zone-c"}}}
+	current.Spec.Shoot.Provider.AdditionalWorkers = &[]gardener.Worker{{Name: "existing", Zones: []string{"zone-d", "zone-e", "zone-f"}}}
+
+	step := NewCreateRuntimeResourceStep(storage.NewMemoryStorage(), nil, inputConfig, defaultOIDSConfig, workers.NewProvider(inputConfig, providerSpec), providerSpec)
+
+	// when
+	runtime, err := step.RuntimeResource(operation, current, fixLogger())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, operation.RuntimeID, runtime.Name)
+	assert.Equal(t, []string{"zone-a", "zone-b", "zone-c"}, runtime.Spec.Shoot.Provider.Workers[0].Zones)
+	require.Len(t, *runtime.Spec.Shoot.Provider.AdditionalWorkers, 2)
+	assert.Equal(t, []string{"zone-d", "zone-e", "zone-f"}, (*runtime.Spec.Shoot.Provider.AdditionalWorkers)[0].Zones)
+	assert.Equal(t, []string{"zone-x"}, (*runtime.Spec.Shoot.Provider.AdditionalWorkers)[1].Zones)
+}
+
 func TestCreateRuntimeResourceStep_Free_ZonesDiscovery(t *testing.T) {
 	// given
 	memoryStorage := storage.NewMemoryStorage()
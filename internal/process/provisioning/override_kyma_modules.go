@@ -1,7 +1,6 @@
 package provisioning
 
 import (
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -48,10 +47,7 @@ func (k *OverrideKymaModules) Run(operation internal.Operation, logger *slog.Log
 
 	modulesParams := operation.ProvisioningParameters.Parameters.Modules
 	if modulesParams != nil {
-		defaultModulesSetToFalse := modulesParams.Default != nil && !*modulesParams.Default  // 1 case
-		customModulesListPassed := modulesParams.Default == nil && modulesParams.List != nil // 2 & 3 case
-		overrideModules := defaultModulesSetToFalse || customModulesListPassed
-		if overrideModules {
+		if steps.OverridesDefaultModules(*modulesParams) {
 			k.logger.Info("custom modules parameters are set, the content of list will replace current modules section. Default settings will be overriden.")
 			return k.handleModulesOverride(operation, *modulesParams)
 		}
@@ -91,45 +87,15 @@ func (k *OverrideKymaModules) handleModulesOverride(operation internal.Operation
 }
 
 func (k *OverrideKymaModules) replaceModulesSpec(kymaTemplate *unstructured.Unstructured, customModuleList []pkg.ModuleDTO) error {
-	toInsert := k.prepareModulesSection(customModuleList)
-	toInsertMarshaled, err := json.Marshal(toInsert)
-	if err != nil {
-		return err
-	}
-	var toInsertUnmarshaled interface{}
-	err = json.Unmarshal(toInsertMarshaled, &toInsertUnmarshaled)
-	if err != nil {
-		return err
+	toInsert := steps.CustomModules(customModuleList)
+	if len(toInsert) == 0 {
+		k.logger.Info("empty (0 items) list with custom modules passed to KEB, 0 modules will be installed - default config will be ignored")
+	} else {
+		k.logger.Info(fmt.Sprintf("not empty list with custom modules passed to KEB. Number of modules: %d", len(toInsert)))
 	}
-	err = unstructured.SetNestedField(kymaTemplate.Object, toInsertUnmarshaled, "spec", "modules")
-	if err != nil {
+	if err := steps.SetKymaModules(kymaTemplate, toInsert); err != nil {
 		return err
 	}
 	k.logger.Info("custom modules replaced in Kyma template successfully.")
 	return nil
 }
-func (k *OverrideKymaModules) prepareModulesSection(customModuleList []pkg.ModuleDTO) []pkg.ModuleDTO {
-	// if field is "" convert it to nil to field will be not present in yaml
-	mapIfNeeded := func(field *string) *string {
-		if field != nil && *field == "" {
-			return nil
-		}
-		return field
-	}
-
-	overridedModules := make([]pkg.ModuleDTO, 0)
-	if customModuleList == nil || len(customModuleList) == 0 {
-		k.logger.Info("empty (0 items) list with custom modules passed to KEB, 0 modules will be installed - default config will be ignored")
-		return overridedModules
-	}
-
-	for _, customModule := range customModuleList {
-		module := pkg.ModuleDTO{Name: customModule.Name}
-		module.CustomResourcePolicy = mapIfNeeded(customModule.CustomResourcePolicy)
-		module.Channel = mapIfNeeded(customModule.Channel)
-		overridedModules = append(overridedModules, module)
-	}
-
-	k.logger.Info(fmt.Sprintf("not empty list with custom modules passed to KEB. Number of modules: %d", len(overridedModules)))
-	return overridedModules
-}
@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"runtime/debug"
@@ -15,6 +16,12 @@ type Executor interface {
 	Execute(operationID string) (time.Duration, error)
 }
 
+// ContextExecutor stops processing when the context is done, the PersistentQueue cancels the context
+// when the lease of the operation is lost and another worker can process it
+type ContextExecutor interface {
+	ExecuteWithContext(ctx context.Context, operationID string) (time.Duration, error)
+}
+
 type Queue struct {
 	queue     workqueue.RateLimitingInterface
 	executor  Executor
@@ -7,8 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/kyma-project/kyma-environment-broker/internal/broker"
-
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 
 	"github.com/pkg/errors"
@@ -24,9 +22,11 @@ import (
 type StagedManager struct {
 	log              *slog.Logger
 	operationStorage storage.Operations
+	outbox           storage.Outbox
 	publisher        event.Publisher
 
 	stages           []*stage
+	cancelSteps      []StepWithCondition
 	operationTimeout time.Duration
 
 	mu sync.RWMutex
@@ -69,10 +69,13 @@ func (s *stage) AddStep(step Step, cnd StepCondition) {
 	})
 }
 
-func NewStagedManager(storage storage.Operations, pub event.Publisher, operationTimeout time.Duration, cfg StagedManagerConfiguration, logger *slog.Logger) *StagedManager {
+// NewStagedManager creates the manager which processes operations step by step. Events about finished operations
+// are stored in the outbox, in-process events about processed steps are published using the publisher.
+func NewStagedManager(storage storage.Operations, outbox storage.Outbox, pub event.Publisher, operationTimeout time.Duration, cfg StagedManagerConfiguration, logger *slog.Logger) *StagedManager {
 	return &StagedManager{
 		log:              logger,
 		operationStorage: storage,
+		outbox:           outbox,
 		publisher:        pub,
 		operationTimeout: operationTimeout,
 		speedFactor:      1,
@@ -103,6 +106,15 @@ func (m *StagedManager) AddStep(stageName string, step Step, cnd StepCondition)
 	return fmt.Errorf("stage %s not defined", stageName)
 }
 
+// AddCancelStep adds a step which is run when the operation is canceled. Cancel steps undo the work done by the operation,
+// they must be idempotent, because they are run from the beginning when any of them needs a retry.
+func (m *StagedManager) AddCancelStep(step Step, cnd StepCondition) {
+	m.cancelSteps = append(m.cancelSteps, StepWithCondition{
+		Step:      step,
+		condition: cnd,
+	})
+}
+
 func (m *StagedManager) GetAllStages() []string {
 	var all []string
 	for _, s := range m.stages {
@@ -112,7 +124,12 @@ func (m *StagedManager) GetAllStages() []string {
 }
 
 func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
+	return m.ExecuteWithContext(context.Background(), operationID)
+}
 
+// ExecuteWithContext processes the operation until the context is done. After that, no step is run and the operation
+// is not saved, because the operation can be processed by another worker.
+func (m *StagedManager) ExecuteWithContext(ctx context.Context, operationID string) (time.Duration, error) {
 	operation, err := m.operationStorage.GetOperationByID(operationID)
 	if err != nil {
 		m.log.Error(fmt.Sprintf("Cannot fetch operation from storage: %s", err))
@@ -121,7 +138,19 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 
 	logOperation := m.log.With("operationID", operationID, "instanceID", operation.InstanceID, "planID", operation.ProvisioningParameters.PlanID)
 	logOperation.Info(fmt.Sprintf("Start process operation steps for GlobalAccount=%s, ", operation.ProvisioningParameters.ErsContext.GlobalAccountID))
-	if time.Since(operation.CreatedAt) > m.operationTimeout {
+	switch operation.State {
+	case internal.OperationStateCanceled:
+		logOperation.Info("operation is already canceled")
+		return 0, nil
+	case internal.OperationStateCanceling:
+		return m.cancel(ctx, *operation, logOperation)
+	}
+	// the operation deferred to the maintenance window stays pending and no step is run until the window starts
+	if operation.State == internal.OperationStatePending && operation.DeferredUntil != nil && time.Now().Before(*operation.DeferredUntil) {
+		logOperation.Info(fmt.Sprintf("operation is deferred to the maintenance window starting at %s", operation.DeferredUntil.Format(time.RFC3339)))
+		return time.Until(*operation.DeferredUntil), nil
+	}
+	if time.Since(operation.TimeLimitStart()) > m.operationTimeout {
 		timeoutErr := kebError.TimeoutError("operation has reached the time limit", string(kebError.KEBDependency))
 		operation.LastError = timeoutErr
 		defer m.publishEventOnFail(operation, err)
@@ -153,9 +182,18 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 				logStep.Debug("Skipping")
 				continue
 			}
+			if ctx.Err() != nil {
+				return m.interrupted(ctx, logStep)
+			}
+			if canceling, requested := m.cancellationRequested(processedOperation.ID); requested {
+				return m.cancel(ctx, *canceling, logOperation)
+			}
 			operation.EventInfof("processing step: %v", step.Name())
 
-			processedOperation, when, err = m.runStep(step, processedOperation, logStep)
+			processedOperation, when, err = m.runStep(ctx, step, processedOperation, logStep)
+			if ctx.Err() != nil {
+				return m.interrupted(ctx, logStep)
+			}
 			if err != nil {
 				logStep.Error(fmt.Sprintf("Process operation failed: %s", err))
 				operation.EventErrorf(err, "step %v processing returned error", step.Name())
@@ -164,19 +202,23 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 			if processedOperation.State == domain.Failed || processedOperation.State == domain.Succeeded {
 				logStep.Info(fmt.Sprintf("Operation %q got status %s. Process finished.", operation.ID, processedOperation.State))
 				operation.EventInfof("operation processing %v", processedOperation.State)
-				m.publishOperationFinishedEvent(processedOperation)
-				m.publishDeprovisioningSucceeded(&processedOperation)
 				return 0, nil
 			}
 
 			// the step needs a retry
 			if when > 0 {
+				if canceling, requested := m.cancellationRequested(processedOperation.ID); requested {
+					return m.cancel(ctx, *canceling, logOperation)
+				}
 				logStep.Warn(fmt.Sprintf("retrying step %s by restarting the operation in %d s", step.Name(), int64(when.Seconds())))
 				return when, nil
 			}
 			logStep.Info(fmt.Sprintf("Step %q processed successfully", step.Name()))
 		}
 
+		if ctx.Err() != nil {
+			return m.interrupted(ctx, logOperation)
+		}
 		processedOperation, err = m.saveFinishedStage(processedOperation, stage, logOperation)
 
 		// it is ok, when operation does not exist in the DB - it can happen at the end of a deprovisioning process
@@ -185,16 +227,20 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 		}
 	}
 
+	if ctx.Err() != nil {
+		return m.interrupted(ctx, logOperation)
+	}
 	logOperation.Info("Operation succeeded")
 
 	processedOperation.State = domain.Succeeded
 	processedOperation.Description = "Processing finished"
 
-	m.publishEventOnSuccess(&processedOperation)
-
 	_, err = m.operationStorage.UpdateOperation(processedOperation)
+	switch {
 	// it is ok, when operation does not exist in the DB - it can happen at the end of a deprovisioning process
-	if err != nil && !dberr.IsNotFound(err) {
+	case dberr.IsNotFound(err):
+		return m.storeFinishedEvent(processedOperation, logOperation)
+	case err != nil:
 		logOperation.Info("Unable to save operation with finished the provisioning process")
 		return time.Second, err
 	}
@@ -202,6 +248,77 @@ func (m *StagedManager) Execute(operationID string) (time.Duration, error) {
 	return 0, nil
 }
 
+// cancellationRequested checks in the storage if the operation cancellation was requested in the meantime
+func (m *StagedManager) cancellationRequested(operationID string) (*internal.Operation, bool) {
+	operation, err := m.operationStorage.GetOperationByID(operationID)
+	if err != nil {
+		// it is ok, when operation does not exist in the DB - it can happen at the end of a deprovisioning process
+		if !dberr.IsNotFound(err) {
+			m.log.Warn(fmt.Sprintf("unable to check if the operation %s is canceled: %s", operationID, err))
+		}
+		return nil, false
+	}
+	return operation, operation.State == internal.OperationStateCanceling
+}
+
+// interrupted stops processing of the operation which can be processed by another worker, the cause of the done context is returned
+func (m *StagedManager) interrupted(ctx context.Context, log *slog.Logger) (time.Duration, error) {
+	err := context.Cause(ctx)
+	log.Warn(fmt.Sprintf("processing of the operation interrupted: %s", err))
+	return 0, err
+}
+
+// cancel runs cancel steps and marks the operation as canceled
+func (m *StagedManager) cancel(ctx context.Context, operation internal.Operation, log *slog.Logger) (time.Duration, error) {
+	log.Info("Operation cancellation requested, running cancel steps")
+	operation.EventInfof("operation canceling")
+
+	for _, step := range m.cancelSteps {
+		logStep := log.With("step", step.Name()).With("stage", "cancel")
+		if step.condition != nil && !step.condition(operation) {
+			logStep.Debug("Skipping")
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return m.interrupted(ctx, logStep)
+		}
+		processedOperation, when, err := m.runStep(ctx, step, operation, logStep)
+		if ctx.Err() != nil {
+			return m.interrupted(ctx, logStep)
+		}
+		if err != nil {
+			logStep.Error(fmt.Sprintf("Cancel step failed: %s", err))
+			operation.EventErrorf(err, "cancel step %v processing returned error", step.Name())
+			return 0, err
+		}
+		if processedOperation.State == domain.Failed {
+			logStep.Info(fmt.Sprintf("Operation %q got status %s during cancellation. Process finished.", operation.ID, processedOperation.State))
+			return 0, nil
+		}
+		if when > 0 {
+			logStep.Warn(fmt.Sprintf("retrying cancel step %s by restarting the operation in %d s", step.Name(), int64(when.Seconds())))
+			return when, nil
+		}
+		operation = processedOperation
+	}
+
+	if ctx.Err() != nil {
+		return m.interrupted(ctx, log)
+	}
+	om := NewOperationManager(m.operationStorage, "Cancel_Operation", kebError.KEBDependency)
+	_, repeat, err := om.OperationCanceled(operation, "Operation canceled", log)
+	if repeat != 0 {
+		return time.Second, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	log.Info("Operation canceled")
+	operation.EventInfof("operation canceled")
+	return 0, nil
+}
+
 func (m *StagedManager) saveFinishedStage(operation internal.Operation, s *stage, log *slog.Logger) (internal.Operation, error) {
 	operation.FinishStage(s.name)
 	op, err := m.operationStorage.UpdateOperation(operation)
@@ -214,7 +331,7 @@ func (m *StagedManager) saveFinishedStage(operation internal.Operation, s *stage
 	return *op, nil
 }
 
-func (m *StagedManager) runStep(step Step, operation internal.Operation, logger *slog.Logger) (processedOperation internal.Operation, backoff time.Duration, err error) {
+func (m *StagedManager) runStep(ctx context.Context, step Step, operation internal.Operation, logger *slog.Logger) (processedOperation internal.Operation, backoff time.Duration, err error) {
 	var start time.Time
 	defer func() {
 		if pErr := recover(); pErr != nil {
@@ -232,6 +349,10 @@ func (m *StagedManager) runStep(step Step, operation internal.Operation, logger
 		logger.Info("Start step")
 		stepLogger := logger.With("step", step.Name(), "operationID", processedOperation.ID)
 		processedOperation, backoff, err = step.Run(processedOperation, stepLogger)
+		// the operation must not be saved by a worker which lost it
+		if ctx.Err() != nil {
+			return processedOperation, 0, context.Cause(ctx)
+		}
 		if err != nil {
 			logOperation := stepLogger.With("error_component", processedOperation.LastError.GetComponent(), "error_reason", processedOperation.LastError.GetReason())
 			logOperation.Warn(fmt.Sprintf("Last error from step: %s", processedOperation.LastError.Error()))
@@ -264,8 +385,18 @@ func (m *StagedManager) runStep(step Step, operation internal.Operation, logger
 			}
 			return processedOperation, backoff, err
 		}
+		// cancel steps are run for an operation which is already canceling
+		if operation.State != internal.OperationStateCanceling {
+			if _, requested := m.cancellationRequested(processedOperation.ID); requested {
+				return processedOperation, backoff, nil
+			}
+		}
 		operation.EventInfof("step %v sleeping for %v", step.Name(), backoff)
-		time.Sleep(backoff / time.Duration(m.speedFactor))
+		select {
+		case <-ctx.Done():
+			return processedOperation, 0, context.Cause(ctx)
+		case <-time.After(backoff / time.Duration(m.speedFactor)):
+		}
 	}
 }
 
@@ -273,8 +404,6 @@ func (m *StagedManager) publishEventOnFail(operation *internal.Operation, err er
 	logOperation := m.log.With("operationID", operation.ID, "error_component", operation.LastError.GetComponent(), "error_reason", operation.LastError.GetReason())
 	logOperation.Error(fmt.Sprintf("Last error: %s", operation.LastError.Error()))
 
-	m.publishOperationFinishedEvent(*operation)
-
 	m.publisher.Publish(context.TODO(), OperationStepProcessed{
 		StepProcessed: StepProcessed{
 			Duration: time.Since(operation.CreatedAt),
@@ -285,29 +414,17 @@ func (m *StagedManager) publishEventOnFail(operation *internal.Operation, err er
 	})
 }
 
-func (m *StagedManager) publishEventOnSuccess(operation *internal.Operation) {
-	m.publisher.Publish(context.TODO(), OperationSucceeded{
-		Operation: *operation,
-	})
-
-	m.publishOperationFinishedEvent(*operation)
-
-	m.publishDeprovisioningSucceeded(operation)
-}
-
-func (m *StagedManager) publishOperationFinishedEvent(operation internal.Operation) {
-	m.publisher.Publish(context.TODO(), OperationFinished{
-		Operation: operation,
-		PlanID:    broker.PlanID(operation.ProvisioningParameters.PlanID),
-	})
-}
-
-func (m *StagedManager) publishDeprovisioningSucceeded(operation *internal.Operation) {
-	if operation.State == domain.Succeeded && operation.Type == internal.OperationTypeDeprovision {
-		m.publisher.Publish(
-			context.TODO(), DeprovisioningSucceeded{
-				Operation: internal.DeprovisioningOperation{Operation: *operation},
-			},
-		)
+// storeFinishedEvent stores the event of the succeeded operation which was removed from the storage by the last step,
+// otherwise the event is stored by the operations storage together with the operation
+func (m *StagedManager) storeFinishedEvent(operation internal.Operation, log *slog.Logger) (time.Duration, error) {
+	event, err := internal.NewOperationFinishedOutboxEvent(operation)
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to create operation finished event: %s", err))
+		return 0, nil
+	}
+	if err := m.outbox.Insert(event); err != nil {
+		log.Error(fmt.Sprintf("unable to store operation finished event: %s", err))
+		return time.Second, nil
 	}
+	return 0, nil
 }
@@ -21,6 +21,7 @@ import (
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/event"
+	"github.com/kyma-project/kyma-environment-broker/internal/outbox"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -164,6 +165,150 @@ func TestSkipFinishedStage(t *testing.T) {
 	assert.True(t, op.IsStageFinished("stage-2"))
 }
 
+func TestCancelBetweenSteps(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	mgr, operationStorage, eventCollector := SetupStagedManager(t, operation)
+	err := mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	err = mgr.AddStep("stage-1", &cancelRequestingStep{name: "second", operations: operationStorage, eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	err = mgr.AddStep("stage-1", &testingStep{name: "third", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	err = mgr.AddStep("stage-2", &testingStep{name: "first-2", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	mgr.AddCancelStep(&testingStep{name: "compensation", eventPublisher: eventCollector}, nil)
+	mgr.AddCancelStep(&testingStep{name: "skipped-compensation", eventPublisher: eventCollector}, func(_ internal.Operation) bool {
+		return false
+	})
+
+	// when
+	retry, err := mgr.Execute(operation.ID)
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, retry)
+	eventCollector.AssertProcessedSteps(t, []string{"first", "second", "compensation"})
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.Equal(t, domain.LastOperationState(internal.OperationStateCanceled), op.State)
+	assert.Equal(t, "Operation canceled", op.Description)
+	assert.False(t, op.IsStageFinished("stage-1"))
+}
+
+func TestDeferredToMaintenanceWindow(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		deferredUntil  time.Time
+		expectedSteps  []string
+		expectedFinish bool
+	}{
+		"maintenance window not started": {
+			deferredUntil: time.Now().Add(48 * time.Hour),
+		},
+		"maintenance window started": {
+			deferredUntil:  time.Now().Add(-time.Minute),
+			expectedSteps:  []string{"first"},
+			expectedFinish: true,
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			operation := FixOperation("op-0001234")
+			operation.State = internal.OperationStatePending
+			operation.DeferredUntil = &tc.deferredUntil
+			mgr, operationStorage, eventCollector := SetupStagedManager(t, operation)
+			err := mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+			assert.NoError(t, err)
+
+			// when
+			retry, err := mgr.Execute(operation.ID)
+
+			// then
+			assert.NoError(t, err)
+			eventCollector.AssertProcessedSteps(t, tc.expectedSteps)
+			op, _ := operationStorage.GetOperationByID(operation.ID)
+			assert.Equal(t, tc.expectedFinish, op.IsStageFinished("stage-1"))
+			if tc.expectedFinish {
+				assert.Zero(t, retry)
+			} else {
+				assert.Equal(t, domain.LastOperationState(internal.OperationStatePending), op.State)
+				assert.InDelta(t, (48 * time.Hour).Seconds(), retry.Seconds(), 60)
+			}
+		})
+	}
+}
+
+func TestStopWhenContextIsDone(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	mgr, operationStorage, eventCollector := SetupStagedManager(t, operation)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	err := mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	err = mgr.AddStep("stage-1", &leaseLosingStep{name: "second", cancel: cancel, eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	err = mgr.AddStep("stage-1", &testingStep{name: "third", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+
+	// when
+	retry, err := mgr.ExecuteWithContext(ctx, operation.ID)
+
+	// then
+	assert.EqualError(t, err, "lease lost")
+	assert.Zero(t, retry)
+	// the result of the interrupted step is dropped
+	eventCollector.AssertProcessedSteps(t, []string{"first"})
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.False(t, op.IsStageFinished("stage-1"))
+	assert.Equal(t, operation.State, op.State)
+}
+
+func TestCancelRetryingStep(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	mgr, operationStorage, eventCollector := SetupStagedManager(t, operation)
+	err := mgr.AddStep("stage-1", &cancelRequestingStep{name: "retrying", retry: time.Second, operations: operationStorage, eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	mgr.AddCancelStep(&testingStep{name: "compensation", eventPublisher: eventCollector}, nil)
+
+	// when
+	retry, err := mgr.Execute(operation.ID)
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, retry)
+	eventCollector.AssertProcessedSteps(t, []string{"retrying", "compensation"})
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.Equal(t, domain.LastOperationState(internal.OperationStateCanceled), op.State)
+}
+
+func TestCancelWithRetryingCancelStep(t *testing.T) {
+	// given
+	operation := FixOperation("op-0001234")
+	operation.State = internal.OperationStateCanceling
+	mgr, operationStorage, eventCollector := SetupStagedManager(t, operation)
+	err := mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: eventCollector}, nil)
+	assert.NoError(t, err)
+	mgr.AddCancelStep(&onceRetryingStep{name: "compensation", eventPublisher: eventCollector}, nil)
+
+	// when
+	retry, err := mgr.Execute(operation.ID)
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, retry)
+	eventCollector.AssertProcessedSteps(t, []string{"compensation", "compensation"})
+	op, _ := operationStorage.GetOperationByID(operation.ID)
+	assert.Equal(t, domain.LastOperationState(internal.OperationStateCanceled), op.State)
+
+	// when
+	retry, err = mgr.Execute(operation.ID)
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, retry)
+	eventCollector.AssertProcessedSteps(t, []string{"compensation", "compensation"})
+}
+
 func SetupStagedManager(t *testing.T, op internal.Operation) (*process.StagedManager, storage.Operations, *CollectingEventHandler) {
 	memoryStorage := storage.NewMemoryStorage()
 	err := memoryStorage.Operations().InsertOperation(op)
@@ -173,7 +318,7 @@ func SetupStagedManager(t *testing.T, op internal.Operation) (*process.StagedMan
 	l := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
 	}))
-	mgr := process.NewStagedManager(memoryStorage.Operations(), eventCollector, 3*time.Second, process.StagedManagerConfiguration{MaxStepProcessingTime: time.Second}, l)
+	mgr := process.NewStagedManager(memoryStorage.Operations(), memoryStorage.Outbox(), eventCollector, 3*time.Second, process.StagedManagerConfiguration{MaxStepProcessingTime: time.Second}, l)
 	mgr.SpeedUp(100000)
 	mgr.DefineStages([]string{"stage-1", "stage-2"})
 
@@ -213,6 +358,46 @@ func (s *onceRetryingStep) Run(operation internal.Operation, logger *slog.Logger
 	return operation, 0, nil
 }
 
+// cancelRequestingStep simulates the operation cancellation requested while the step is processed
+type cancelRequestingStep struct {
+	name           string
+	retry          time.Duration
+	operations     storage.Operations
+	eventPublisher event.Publisher
+}
+
+func (s *cancelRequestingStep) Name() string {
+	return s.name
+}
+
+func (s *cancelRequestingStep) Run(operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error) {
+	s.eventPublisher.Publish(context.Background(), s.name)
+	stored, err := s.operations.GetOperationByID(operation.ID)
+	if err != nil {
+		return operation, 0, err
+	}
+	stored.State = internal.OperationStateCanceling
+	_, err = s.operations.UpdateOperation(*stored)
+	return operation, s.retry, err
+}
+
+// leaseLosingStep cancels the context of the execution, as the queue does when the lease of the operation is lost
+type leaseLosingStep struct {
+	name           string
+	cancel         context.CancelCauseFunc
+	eventPublisher event.Publisher
+}
+
+func (s *leaseLosingStep) Name() string {
+	return s.name
+}
+
+func (s *leaseLosingStep) Run(operation internal.Operation, logger *slog.Logger) (internal.Operation, time.Duration, error) {
+	s.eventPublisher.Publish(context.Background(), s.name)
+	s.cancel(fmt.Errorf("lease lost"))
+	return operation, 0, nil
+}
+
 type panicStep struct {
 	name           string
 	processed      bool
@@ -333,7 +518,7 @@ func (rc *resultCollector) AssertDurationGreaterThanZero(t *testing.T) {
 	assert.Greater(t, rc.duration, 0.0)
 }
 
-func SetupStagedManager2(t *testing.T, op internal.Operation) (*process.StagedManager, storage.Operations, *event.PubSub) {
+func SetupStagedManager2(t *testing.T, op internal.Operation) (*process.StagedManager, *outbox.Dispatcher, *event.PubSub) {
 	memoryStorage := storage.NewMemoryStorage()
 	err := memoryStorage.Operations().InsertOperation(op)
 	assert.NoError(t, err)
@@ -342,18 +527,19 @@ func SetupStagedManager2(t *testing.T, op internal.Operation) (*process.StagedMa
 		Level: slog.LevelDebug,
 	}))
 	pubSub := event.NewPubSub(nil)
-	mgr := process.NewStagedManager(memoryStorage.Operations(), pubSub, 3*time.Second, process.StagedManagerConfiguration{MaxStepProcessingTime: time.Second}, l)
+	mgr := process.NewStagedManager(memoryStorage.Operations(), memoryStorage.Outbox(), pubSub, 3*time.Second, process.StagedManagerConfiguration{MaxStepProcessingTime: time.Second}, l)
 	mgr.SpeedUp(100000)
 	mgr.DefineStages([]string{"stage-1", "stage-2"})
+	dispatcher := outbox.NewDispatcher(memoryStorage.Outbox(), []outbox.Sink{outbox.NewSubscribersSink(pubSub)}, outbox.Config{BatchSize: 10, LeaseDuration: time.Minute, MaxAttempts: 1}, l)
 
-	return mgr, memoryStorage.Operations(), pubSub
+	return mgr, dispatcher, pubSub
 }
 
 func TestOperationSucceededEvent(t *testing.T) {
 	// given
 	const opID = "op-0001234"
 	operation := FixOperation("op-0001234")
-	mgr, _, pubSub := SetupStagedManager2(t, operation)
+	mgr, dispatcher, pubSub := SetupStagedManager2(t, operation)
 	err := mgr.AddStep("stage-1", &testingStep{name: "first", eventPublisher: pubSub}, nil)
 	assert.NoError(t, err)
 
@@ -365,6 +551,7 @@ func TestOperationSucceededEvent(t *testing.T) {
 	// when
 	_, err = mgr.Execute(operation.ID)
 	assert.NoError(t, err)
+	dispatcher.Dispatch(context.Background())
 
 	// then
 	rc.WaitForState(t, domain.Succeeded)
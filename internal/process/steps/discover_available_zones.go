@@ -2,6 +2,7 @@ package steps
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/rand"
@@ -11,7 +12,7 @@ import (
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
-	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers/aws"
+	"github.com/kyma-project/kyma-environment-broker/internal/hyperscalers"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -24,16 +25,16 @@ type DiscoverAvailableZonesStep struct {
 	instanceStorage  storage.Instances
 	providerSpec     *configuration.ProviderSpec
 	gardenerClient   *gardener.Client
-	awsClientFactory aws.ClientFactory
+	clientFactory    hyperscalers.ClientFactory
 }
 
-func NewDiscoverAvailableZonesStep(db storage.BrokerStorage, providerSpec *configuration.ProviderSpec, gardenerClient *gardener.Client, awsClientFactory aws.ClientFactory) *DiscoverAvailableZonesStep {
+func NewDiscoverAvailableZonesStep(db storage.BrokerStorage, providerSpec *configuration.ProviderSpec, gardenerClient *gardener.Client, clientFactory hyperscalers.ClientFactory) *DiscoverAvailableZonesStep {
 	step := &DiscoverAvailableZonesStep{
 		operationStorage: db.Operations(),
 		instanceStorage:  db.Instances(),
 		providerSpec:     providerSpec,
 		gardenerClient:   gardenerClient,
-		awsClientFactory: awsClientFactory,
+		clientFactory:    clientFactory,
 	}
 	step.operationManager = process.NewOperationManager(db.Operations(), step.Name(), kebError.KEBDependency)
 	return step
@@ -74,14 +75,12 @@ func (s *DiscoverAvailableZonesStep) Run(operation internal.Operation, log *slog
 	if err != nil {
 		return s.operationManager.RetryOperation(operation, fmt.Sprintf("unable to get secret %s/%s", secretBinding.GetSecretRefNamespace(), secretBinding.GetSecretRefName()), err, 10*time.Second, time.Minute, log)
 	}
-	accessKeyID, secretAccessKey, err := aws.ExtractCredentials(secret)
+	client, err := s.clientFactory.New(context.Background(), runtime.CloudProviderFromString(operation.ProviderValues.ProviderType), secret, operation.ProviderValues.Region)
 	if err != nil {
-		return s.operationManager.OperationFailed(operation, "failed to extract AWS credentials", err, log)
-	}
-
-	client, err := s.awsClientFactory.New(context.Background(), accessKeyID, secretAccessKey, operation.ProviderValues.Region)
-	if err != nil {
-		return s.operationManager.RetryOperation(operation, "unable to create AWS client", err, 10*time.Second, time.Minute, log)
+		if errors.Is(err, hyperscalers.ErrInvalidCredentials) {
+			return s.operationManager.OperationFailed(operation, "failed to extract hyperscaler credentials", err, log)
+		}
+		return s.operationManager.RetryOperation(operation, "unable to create hyperscaler client", err, 10*time.Second, time.Minute, log)
 	}
 
 	operation.DiscoveredZones = make(map[string][]string)
@@ -48,7 +48,7 @@ func TestDiscoverAvailableZonesCBStep_ZonesDiscoveryDisabled(t *testing.T) {
 		memoryStorage,
 		fixture.NewProviderSpecWithZonesDiscovery(t, false),
 		fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 			"m6i.large":   {"ap-southeast-2a", "ap-southeast-2b", "ap-southeast-2c"},
 			"g6.xlarge":   {"ap-southeast-2a", "ap-southeast-2c"},
 			"g4dn.xlarge": {"ap-southeast-2b"},
@@ -86,7 +86,7 @@ func TestDiscoverAvailableZonesCBStep_FailWhenNoSubscriptionSecretName(t *testin
 	assert.NoError(t, err)
 
 	step := NewDiscoverAvailableZonesCBStep(memoryStorage, fixture.NewProviderSpecWithZonesDiscovery(t, true), fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{}, nil))
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{}, nil))
 
 	// when
 	operation, repeat, err := step.Run(operation, fixLogger())
@@ -135,7 +135,7 @@ func TestDiscoverAvailableZonesCBStep_SubscriptionSecretNameFromOperation(t *tes
 		memoryStorage,
 		fixture.NewProviderSpecWithZonesDiscovery(t, true),
 		fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 			"m6i.large":   {"ap-southeast-2a", "ap-southeast-2b", "ap-southeast-2c"},
 			"g6.xlarge":   {"ap-southeast-2a", "ap-southeast-2c"},
 			"g4dn.xlarge": {"ap-southeast-2b"},
@@ -192,7 +192,7 @@ func TestDiscoverAvailableZonesCBStep_RegionFromProviderValues(t *testing.T) {
 		memoryStorage,
 		fixture.NewProviderSpecWithZonesDiscovery(t, true),
 		fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 			"m6i.large":   {"ap-southeast-2a", "ap-southeast-2b", "ap-southeast-2c"},
 			"g6.xlarge":   {"ap-southeast-2a", "ap-southeast-2c"},
 			"g4dn.xlarge": {"ap-southeast-2b"},
@@ -231,7 +231,7 @@ func TestDiscoverAvailableZonesCBStep_MachineTypeFromProviderValues(t *testing.T
 		memoryStorage,
 		fixture.NewProviderSpecWithZonesDiscovery(t, true),
 		fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 			"m5.large": {"ap-southeast-2a", "ap-southeast-2b", "ap-southeast-2c"},
 		}, nil),
 	)
@@ -269,7 +269,7 @@ func TestDiscoverAvailableZonesCBStep_RepeatWhenAWSError(t *testing.T) {
 	assert.NoError(t, err)
 
 	step := NewDiscoverAvailableZonesCBStep(memoryStorage, fixture.NewProviderSpecWithZonesDiscovery(t, true),
-		fixture.CreateGardenerClientWithCredentialsBindings(), fixture.NewFakeAWSClientFactory(map[string][]string{}, fmt.Errorf("AWS error")))
+		fixture.CreateGardenerClientWithCredentialsBindings(), fixture.NewFakeHyperscalerClientFactory(map[string][]string{}, fmt.Errorf("AWS error")))
 
 	// when
 	operation, repeat, err := step.Run(operation, fixLogger())
@@ -316,7 +316,7 @@ func TestDiscoverAvailableZonesCBStep_ProvisioningHappyPath(t *testing.T) {
 		memoryStorage,
 		fixture.NewProviderSpecWithZonesDiscovery(t, true),
 		fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 			"m6i.large":   {"ap-southeast-2a", "ap-southeast-2b", "ap-southeast-2c"},
 			"g6.xlarge":   {"ap-southeast-2a", "ap-southeast-2c"},
 			"g4dn.xlarge": {"ap-southeast-2b"},
@@ -370,7 +370,7 @@ func TestDiscoverAvailableZonesCBStep_UpdateHappyPath(t *testing.T) {
 		memoryStorage,
 		fixture.NewProviderSpecWithZonesDiscovery(t, true),
 		fixture.CreateGardenerClientWithCredentialsBindings(),
-		fixture.NewFakeAWSClientFactory(map[string][]string{
+		fixture.NewFakeHyperscalerClientFactory(map[string][]string{
 			"g6.xlarge":   {"ap-southeast-2a", "ap-southeast-2c"},
 			"g4dn.xlarge": {"ap-southeast-2b"},
 		}, nil),
@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"encoding/json"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// OverridesDefaultModules returns true if the modules parameters replace the default modules from the Kyma template:
+// 'default' is set to false (no modules) or a custom list is given without 'default' (an empty list means no modules).
+// In all other cases the default modules are used.
+func OverridesDefaultModules(modules pkg.ModulesDTO) bool {
+	defaultModulesSetToFalse := modules.Default != nil && !*modules.Default
+	customModulesListPassed := modules.Default == nil && modules.List != nil
+	return defaultModulesSetToFalse || customModulesListPassed
+}
+
+// CustomModules converts the custom module list to the modules section of the Kyma resource.
+// Empty channel and customResourcePolicy are removed, so the Kyma resource defaults are used.
+func CustomModules(customModuleList []pkg.ModuleDTO) []pkg.ModuleDTO {
+	// if field is "" convert it to nil to field will be not present in yaml
+	mapIfNeeded := func(field *string) *string {
+		if field != nil && *field == "" {
+			return nil
+		}
+		return field
+	}
+
+	modules := make([]pkg.ModuleDTO, 0, len(customModuleList))
+	for _, customModule := range customModuleList {
+		module := pkg.ModuleDTO{Name: customModule.Name}
+		module.CustomResourcePolicy = mapIfNeeded(customModule.CustomResourcePolicy)
+		module.Channel = mapIfNeeded(customModule.Channel)
+		modules = append(modules, module)
+	}
+	return modules
+}
+
+// SetKymaModules replaces the spec.modules section of the Kyma resource or the Kyma template
+func SetKymaModules(kyma *unstructured.Unstructured, modules []pkg.ModuleDTO) error {
+	marshaled, err := json.Marshal(modules)
+	if err != nil {
+		return err
+	}
+	var unmarshaled interface{}
+	if err := json.Unmarshal(marshaled, &unmarshaled); err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(kyma.Object, unmarshaled, "spec", "modules")
+}
+
+// DefaultKymaModules returns the spec.modules section of the Kyma template, nil is returned if the section is not present
+func DefaultKymaModules(kymaTemplate *unstructured.Unstructured) ([]interface{}, error) {
+	modules, _, err := unstructured.NestedSlice(kymaTemplate.Object, "spec", "modules")
+	return modules, err
+}
+
+// KymaModules returns the spec.modules section of the Kyma resource, an empty list is returned if the section is not present
+func KymaModules(kyma *unstructured.Unstructured) ([]pkg.ModuleDTO, error) {
+	modules := []pkg.ModuleDTO{}
+	section, found, err := unstructured.NestedSlice(kyma.Object, "spec", "modules")
+	if err != nil || !found {
+		return modules, err
+	}
+	marshaled, err := json.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(marshaled, &modules); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
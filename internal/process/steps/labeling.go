@@ -10,6 +10,10 @@ func SetCommonLabels(labels map[string]string, operation internal.Operation) map
 	if labels == nil {
 		labels = make(map[string]string)
 	}
+	// labels set by the user cannot use reserved prefixes, so they never override labels set by KEB
+	for key, value := range operation.ProvisioningParameters.Parameters.Labels {
+		labels[key] = value
+	}
 	labels[customresources.InstanceIdLabel] = operation.InstanceID
 	labels[customresources.RuntimeIdLabel] = operation.RuntimeID
 	labels[customresources.PlanIdLabel] = operation.ProvisioningParameters.PlanID
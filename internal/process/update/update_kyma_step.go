@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"time"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/config"
@@ -40,8 +41,9 @@ func (s *UpdateKymaStep) Name() string {
 }
 
 func (s *UpdateKymaStep) Run(operation internal.Operation, log *slog.Logger) (internal.Operation, time.Duration, error) {
-	if operation.UpdatedPlanID == "" {
-		log.Info("Plan did not change, skipping update Kyma resource step")
+	modules := operation.UpdatingParameters.Modules
+	if operation.UpdatedPlanID == "" && modules == nil {
+		log.Info("Plan and modules did not change, skipping update Kyma resource step")
 		return operation, 0, nil
 	}
 
@@ -107,11 +109,60 @@ func (s *UpdateKymaStep) Run(operation internal.Operation, log *slog.Logger) (in
 
 	log.Info(fmt.Sprintf("Updating Kyma resource: %s in namespace:%s", kymaResourceName, operation.KymaResourceNamespace))
 
-	kymaUnstructured.SetLabels(steps.UpdatePlanLabels(kymaUnstructured.GetLabels(), operation.UpdatedPlanID))
+	if operation.UpdatedPlanID != "" {
+		kymaUnstructured.SetLabels(steps.UpdatePlanLabels(kymaUnstructured.GetLabels(), operation.UpdatedPlanID))
+	}
+	if modules != nil {
+		if err := s.updateModules(kymaUnstructured, obj, *modules, log); err != nil {
+			return s.operationManager.OperationFailed(operation, "unable to set modules in Kyma resource", err, log)
+		}
+	}
 	err = s.kcpClient.Update(context.Background(), kymaUnstructured)
 	if err != nil {
 		return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), fmt.Sprintf("unable to update Kyma Resource %s", kymaResourceName), 10*time.Second, 1*time.Minute, log, err)
 	}
 
+	if modules != nil {
+		if err := s.storeModules(operation.InstanceID, kymaUnstructured); err != nil {
+			return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), "unable to store modules of the instance", 10*time.Second, 1*time.Minute, log, err)
+		}
+	}
+
 	return operation, 0, nil
 }
+
+// storeModules saves modules applied to the Kyma resource in the instance parameters, so the instance shows the resulting list instead of the requested one
+func (s *UpdateKymaStep) storeModules(instanceID string, kyma *unstructured.Unstructured) error {
+	applied, err := steps.KymaModules(kyma)
+	if err != nil {
+		return fmt.Errorf("while reading modules of the Kyma resource: %w", err)
+	}
+	instance, err := s.instances.GetByID(instanceID)
+	if err != nil {
+		return fmt.Errorf("while getting the instance: %w", err)
+	}
+	instance.Parameters.Parameters.Modules = &pkg.ModulesDTO{List: applied}
+	if _, err := s.instances.Update(*instance); err != nil {
+		return fmt.Errorf("while updating the instance: %w", err)
+	}
+	return nil
+}
+
+// updateModules replaces modules of the Kyma resource with the custom list or resets them to the default modules from the Kyma template
+func (s *UpdateKymaStep) updateModules(kyma, kymaTemplate *unstructured.Unstructured, modules pkg.ModulesDTO, log *slog.Logger) error {
+	if steps.OverridesDefaultModules(modules) {
+		customModules := steps.CustomModules(modules.List)
+		log.Info(fmt.Sprintf("Replacing modules of the Kyma resource with %d custom modules", len(customModules)))
+		return steps.SetKymaModules(kyma, customModules)
+	}
+
+	defaultModules, err := steps.DefaultKymaModules(kymaTemplate)
+	if err != nil {
+		return fmt.Errorf("while reading default modules from the Kyma template: %w", err)
+	}
+	if defaultModules == nil {
+		defaultModules = []interface{}{}
+	}
+	log.Info(fmt.Sprintf("Resetting modules of the Kyma resource to %d default modules", len(defaultModules)))
+	return unstructured.SetNestedSlice(kyma.Object, defaultModules, "spec", "modules")
+}
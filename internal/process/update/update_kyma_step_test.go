@@ -1,18 +1,24 @@
 package update
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 
 	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -201,3 +207,138 @@ func TestUpdateKymaStep_NoKymaResource(t *testing.T) {
 	assert.Equal(t, 10*time.Second, backoff)
 	assert.NoError(t, err)
 }
+
+func TestUpdateKymaStep_CustomModules(t *testing.T) {
+	// given
+	err := imv1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	kcpClient := fake.NewClientBuilder().Build()
+	err = fixture.FixKymaResourceWithGivenRuntimeID(kcpClient, "kyma-system", "runtime-inst-id")
+	require.NoError(t, err)
+	db := storage.NewMemoryStorage()
+	operations := db.Operations()
+	err = db.Instances().Insert(fixture.FixInstance("inst-id"))
+	require.NoError(t, err)
+
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.KymaTemplate = kymaTemplateWithModules
+	operation.UpdatingParameters.Modules = &pkg.ModulesDTO{List: []pkg.ModuleDTO{
+		{Name: "btp-operator", Channel: ptr.String("fast"), CustomResourcePolicy: ptr.String("")},
+		{Name: "keda", CustomResourcePolicy: ptr.String("Ignore")},
+	}}
+	err = operations.InsertOperation(operation)
+	require.NoError(t, err)
+
+	step := NewUpdateKymaStep(db, kcpClient, nil)
+
+	// when
+	_, backoff, err := step.Run(operation, fixLogger())
+
+	// then
+	assert.Zero(t, backoff)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "btp-operator", "channel": "fast"},
+		map[string]interface{}{"name": "keda", "customResourcePolicy": "Ignore"},
+	}, kymaModules(t, kcpClient))
+	assert.Equal(t, &pkg.ModulesDTO{List: []pkg.ModuleDTO{
+		{Name: "btp-operator", Channel: ptr.String("fast")},
+		{Name: "keda", CustomResourcePolicy: ptr.String("Ignore")},
+	}}, instanceModules(t, db))
+}
+
+func TestUpdateKymaStep_DefaultModules(t *testing.T) {
+	// given
+	err := imv1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	kcpClient := fake.NewClientBuilder().Build()
+	err = fixture.FixKymaResourceWithGivenRuntimeID(kcpClient, "kyma-system", "runtime-inst-id")
+	require.NoError(t, err)
+	db := storage.NewMemoryStorage()
+	operations := db.Operations()
+	err = db.Instances().Insert(fixture.FixInstance("inst-id"))
+	require.NoError(t, err)
+
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.KymaTemplate = kymaTemplateWithModules
+	operation.UpdatingParameters.Modules = &pkg.ModulesDTO{Default: ptr.Bool(true)}
+	err = operations.InsertOperation(operation)
+	require.NoError(t, err)
+
+	step := NewUpdateKymaStep(db, kcpClient, nil)
+
+	// when
+	_, backoff, err := step.Run(operation, fixLogger())
+
+	// then
+	assert.Zero(t, backoff)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "istio"},
+		map[string]interface{}{"name": "api-gateway", "channel": "regular"},
+	}, kymaModules(t, kcpClient))
+	assert.Equal(t, &pkg.ModulesDTO{List: []pkg.ModuleDTO{
+		{Name: "istio"},
+		{Name: "api-gateway", Channel: ptr.String("regular")},
+	}}, instanceModules(t, db))
+}
+
+func TestUpdateKymaStep_NoModules(t *testing.T) {
+	// given
+	err := imv1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	kcpClient := fake.NewClientBuilder().Build()
+	err = fixture.FixKymaResourceWithGivenRuntimeID(kcpClient, "kyma-system", "runtime-inst-id")
+	require.NoError(t, err)
+	db := storage.NewMemoryStorage()
+	operations := db.Operations()
+	err = db.Instances().Insert(fixture.FixInstance("inst-id"))
+	require.NoError(t, err)
+
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.KymaTemplate = kymaTemplateWithModules
+	operation.UpdatingParameters.Modules = &pkg.ModulesDTO{Default: ptr.Bool(false)}
+	err = operations.InsertOperation(operation)
+	require.NoError(t, err)
+
+	step := NewUpdateKymaStep(db, kcpClient, nil)
+
+	// when
+	_, backoff, err := step.Run(operation, fixLogger())
+
+	// then
+	assert.Zero(t, backoff)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, kymaModules(t, kcpClient))
+	assert.Equal(t, &pkg.ModulesDTO{List: []pkg.ModuleDTO{}}, instanceModules(t, db))
+}
+
+const kymaTemplateWithModules = `
+apiVersion: operator.kyma-project.io/v1beta2
+kind: Kyma
+metadata:
+  name: my-kyma
+  namespace: kyma-system
+spec:
+  channel: stable
+  modules:
+  - name: istio
+  - name: api-gateway
+    channel: regular
+`
+
+func kymaModules(t *testing.T, kcpClient client.Client) []interface{} {
+	kyma := &unstructured.Unstructured{}
+	kyma.SetGroupVersionKind(schema.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "Kyma"})
+	err := kcpClient.Get(context.Background(), client.ObjectKey{Namespace: "kyma-system", Name: "runtime-inst-id"}, kyma)
+	require.NoError(t, err)
+	modules, _, err := unstructured.NestedSlice(kyma.Object, "spec", "modules")
+	require.NoError(t, err)
+	return modules
+}
+
+func instanceModules(t *testing.T, db storage.BrokerStorage) *pkg.ModulesDTO {
+	instance, err := db.Instances().GetByID("inst-id")
+	require.NoError(t, err)
+	return instance.Parameters.Parameters.Modules
+}
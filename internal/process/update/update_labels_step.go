@@ -0,0 +1,59 @@
+package update
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
+	"github.com/kyma-project/kyma-environment-broker/internal/process"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpdateLabelsStep propagates labels of the instance to the Kyma, GardenerCluster and Runtime resources.
+// Labels are stored by the update endpoint, the step only sets them on the resources.
+type UpdateLabelsStep struct {
+	operationManager *process.OperationManager
+	kcpClient        client.Client
+}
+
+func NewUpdateLabelsStep(db storage.BrokerStorage, kcpClient client.Client) *UpdateLabelsStep {
+	step := &UpdateLabelsStep{
+		kcpClient: kcpClient,
+	}
+	step.operationManager = process.NewOperationManager(db.Operations(), step.Name(), kebError.K8sDependency)
+	return step
+}
+
+func (s *UpdateLabelsStep) Name() string {
+	return "Update_Labels"
+}
+
+func (s *UpdateLabelsStep) Run(operation internal.Operation, log *slog.Logger) (internal.Operation, time.Duration, error) {
+	newLabels := operation.UpdatingParameters.Labels
+	if newLabels == nil {
+		log.Info("Labels did not change, skipping update labels step")
+		return operation, 0, nil
+	}
+	if operation.RuntimeID == "" {
+		log.Info("Runtime is not created, provisioning sets labels on created resources, skipping update labels step")
+		return operation, 0, nil
+	}
+
+	// provisioning parameters of the update operation keep labels from before the update
+	var removed []string
+	for key := range operation.ProvisioningParameters.Parameters.Labels {
+		if _, found := newLabels[key]; !found {
+			removed = append(removed, key)
+		}
+	}
+	if err := broker.NewLabeler(s.kcpClient).UpdateInstanceLabels(operation.RuntimeID, newLabels, removed); err != nil {
+		// labels are stored, the next update of labels propagates them again
+		return s.operationManager.RetryOperationWithoutFail(operation, s.Name(), "unable to update labels on related CRs", 10*time.Second, 1*time.Minute, log, err)
+	}
+
+	return operation, 0, nil
+}
@@ -0,0 +1,116 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var labeledCrs = []string{customresources.KymaCr, customresources.GardenerClusterCr, customresources.RuntimeCr}
+
+func TestUpdateLabelsStep(t *testing.T) {
+	t.Run("should set new labels and remove labels which are no longer set", func(t *testing.T) {
+		// given
+		kcpClient := fixKcpClientWithLabeledCrs(t, "runtime-id", map[string]string{"team": "x", "env": "dev", "other": "kept"})
+		db := storage.NewMemoryStorage()
+		operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+		operation.RuntimeID = "runtime-id"
+		operation.ProvisioningParameters.Parameters.Labels = map[string]string{"team": "x", "env": "dev"}
+		operation.UpdatingParameters.Labels = map[string]string{"team": "y", "cost-center": "42"}
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		step := NewUpdateLabelsStep(db, kcpClient)
+
+		// when
+		_, backoff, err := step.Run(operation, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, backoff)
+		for _, crName := range labeledCrs {
+			assert.Equal(t, map[string]string{"team": "y", "cost-center": "42", "other": "kept"}, labelsOfCr(t, kcpClient, crName, "runtime-id"), crName)
+		}
+	})
+
+	t.Run("should skip when labels did not change", func(t *testing.T) {
+		// given
+		kcpClient := fixKcpClientWithLabeledCrs(t, "runtime-id", map[string]string{"team": "x"})
+		db := storage.NewMemoryStorage()
+		operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+		operation.RuntimeID = "runtime-id"
+		operation.ProvisioningParameters.Parameters.Labels = map[string]string{"team": "x"}
+
+		step := NewUpdateLabelsStep(db, kcpClient)
+
+		// when
+		_, backoff, err := step.Run(operation, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.Zero(t, backoff)
+		for _, crName := range labeledCrs {
+			assert.Equal(t, map[string]string{"team": "x"}, labelsOfCr(t, kcpClient, crName, "runtime-id"), crName)
+		}
+	})
+
+	t.Run("should retry when resources cannot be updated", func(t *testing.T) {
+		// given
+		kcpClient := fixKcpClientWithLabeledCrs(t, "other-runtime-id", nil)
+		db := storage.NewMemoryStorage()
+		operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+		operation.RuntimeID = "runtime-id"
+		operation.UpdatingParameters.Labels = map[string]string{"team": "y"}
+		require.NoError(t, db.Operations().InsertOperation(operation))
+
+		step := NewUpdateLabelsStep(db, kcpClient)
+
+		// when
+		_, backoff, err := step.Run(operation, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.NotZero(t, backoff)
+	})
+}
+
+func fixKcpClientWithLabeledCrs(t *testing.T, runtimeID string, labels map[string]string) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	var objects []client.Object
+	for _, crName := range labeledCrs {
+		gvk, err := customresources.GvkByName(crName)
+		require.NoError(t, err)
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		crd.SetName(fmt.Sprintf("%ss.%s", strings.ToLower(gvk.Kind), gvk.Group))
+		cr := &unstructured.Unstructured{}
+		cr.SetGroupVersionKind(gvk)
+		cr.SetName(runtimeID)
+		cr.SetNamespace(broker.KcpNamespace)
+		cr.SetLabels(labels)
+		objects = append(objects, crd, cr)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func labelsOfCr(t *testing.T, kcpClient client.Client, crName, runtimeID string) map[string]string {
+	gvk, err := customresources.GvkByName(crName)
+	require.NoError(t, err)
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	require.NoError(t, kcpClient.Get(context.Background(), client.ObjectKey{Name: runtimeID, Namespace: broker.KcpNamespace}, cr))
+	return cr.GetLabels()
+}
@@ -14,6 +14,7 @@ import (
 	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/kyma-environment-broker/internal/process"
 	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/process/provisioning"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
@@ -203,11 +204,27 @@ func (s *UpdateRuntimeStep) Run(operation internal.Operation, log *slog.Logger)
 		runtime.SetLabels(steps.UpdatePlanLabels(runtime.GetLabels(), operation.UpdatedPlanID))
 	}
 
+	// the nodes CIDR is validated by the update endpoint, Gardener accepts only extending the range
+	if networking := operation.UpdatingParameters.Networking; networking != nil && networking.NodesCidr != "" {
+		runtime.Spec.Shoot.Networking.Nodes = networking.NodesCidr
+	}
+
 	err = s.k8sClient.Update(context.Background(), &runtime)
 	if err != nil {
 		return s.operationManager.RetryOperation(operation, fmt.Sprintf("unable to update Runtime Resource %s", operation.GetRuntimeResourceName()), err, 10*time.Second, 1*time.Minute, log)
 	}
 
+	// the version can be changed in the Runtime resource after the provisioning, the stored one is used to filter runtimes
+	if version := ptr.ToString(runtime.Spec.Shoot.Kubernetes.Version); version != operation.KubernetesVersion {
+		var backoff time.Duration
+		operation, backoff, _ = s.operationManager.UpdateOperation(operation, func(op *internal.Operation) {
+			op.KubernetesVersion = version
+		}, log)
+		if backoff > 0 {
+			return operation, backoff, nil
+		}
+	}
+
 	// this sleep is needed to wait for the runtime to be updated by the infrastructure manager with state PENDING,
 	// then we can wait for the state READY in the next step
 	time.Sleep(s.delay)
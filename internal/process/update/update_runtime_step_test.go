@@ -85,6 +85,60 @@ func TestUpdateRuntimeStep_RunUpdateMachineType(t *testing.T) {
 
 }
 
+func TestUpdateRuntimeStep_RunUpdateNodesCIDR(t *testing.T) {
+	// given
+	err := imv1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	runtimeResource := fixRuntimeResource("runtime-name")
+	runtimeResource.(*imv1.Runtime).Spec.Shoot.Networking = imv1.Networking{Nodes: "10.250.0.0/16", Pods: "10.96.0.0/13", Services: "10.104.0.0/13"}
+	kcpClient := fake.NewClientBuilder().WithRuntimeObjects(runtimeResource).Build()
+	step := NewUpdateRuntimeStep(memoryStorage, kcpClient, 0, broker.InfrastructureManager{}, nil, &workers.Provider{}, fixValuesProvider())
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.RuntimeResourceName = "runtime-name"
+	operation.KymaResourceNamespace = "kcp-system"
+	operation.UpdatingParameters = internal.UpdatingParametersDTO{
+		Networking: &pkg.NetworkingDTO{NodesCidr: "10.250.0.0/15"},
+	}
+
+	// when
+	_, backoff, err := step.Run(operation, fixLogger())
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, backoff)
+
+	var gotRuntime imv1.Runtime
+	err = kcpClient.Get(context.Background(), client.ObjectKey{Name: operation.RuntimeResourceName, Namespace: "kcp-system"}, &gotRuntime)
+	require.NoError(t, err)
+	assert.Equal(t, imv1.Networking{Nodes: "10.250.0.0/15", Pods: "10.96.0.0/13", Services: "10.104.0.0/13"}, gotRuntime.Spec.Shoot.Networking)
+}
+
+func TestUpdateRuntimeStep_StoresKubernetesVersion(t *testing.T) {
+	// given
+	err := imv1.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	runtimeResource := fixRuntimeResource("runtime-name")
+	runtimeResource.(*imv1.Runtime).Spec.Shoot.Kubernetes.Version = ptr.String("1.33")
+	kcpClient := fake.NewClientBuilder().WithRuntimeObjects(runtimeResource).Build()
+	db := storage.NewMemoryStorage()
+	step := NewUpdateRuntimeStep(db, kcpClient, 0, broker.InfrastructureManager{}, nil, &workers.Provider{}, fixValuesProvider())
+	operation := fixture.FixUpdatingOperation("op-id", "inst-id").Operation
+	operation.RuntimeResourceName = "runtime-name"
+	operation.KymaResourceNamespace = "kcp-system"
+	operation.KubernetesVersion = "1.32"
+	require.NoError(t, db.Operations().InsertOperation(operation))
+
+	// when
+	_, backoff, err := step.Run(operation, fixLogger())
+
+	// then
+	assert.NoError(t, err)
+	assert.Zero(t, backoff)
+	stored, err := db.Operations().GetOperationByID("op-id")
+	require.NoError(t, err)
+	assert.Equal(t, "1.33", stored.KubernetesVersion)
+}
+
 func TestUpdateRuntimeStep_RunUpdateEmptyOIDCConfigWithOIDCObject(t *testing.T) {
 	// given
 	err := imv1.AddToScheme(scheme.Scheme)
@@ -1,10 +1,15 @@
 package configuration
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+
+	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
 )
 
@@ -53,6 +58,110 @@ type planSpecificationDTO struct {
 	AdditionalMachines []string `yaml:"additionalMachines"`
 	VolumeSizeGb       int      `yaml:"volumeSizeGb"`
 	UpgradableToPlans  []string `yaml:"upgradableToPlans,omitempty"`
+
+	// catalog fields, required only for plans which are not built into KEB
+	ID                        string `yaml:"id,omitempty"`
+	Provider                  string `yaml:"provider,omitempty"`
+	Trial                     *bool  `yaml:"trial,omitempty"`
+	Free                      *bool  `yaml:"free,omitempty"`
+	OwnCluster                *bool  `yaml:"ownCluster,omitempty"`
+	Bindable                  *bool  `yaml:"bindable,omitempty"`
+	AdditionalWorkerNodePools *bool  `yaml:"additionalWorkerNodePools,omitempty"`
+}
+
+// PlanDefinition describes a plan defined in the plans configuration file by the catalog fields
+type PlanDefinition struct {
+	ID       string
+	Name     string
+	Provider runtime.CloudProvider
+
+	// nil means the default for the plan is used
+	Trial                     *bool
+	Free                      *bool
+	OwnCluster                *bool
+	Bindable                  *bool
+	AdditionalWorkerNodePools *bool
+
+	UpgradableToPlans []string
+}
+
+// Definitions returns plans which define at least one catalog field, sorted by name
+func (p *PlanSpecifications) Definitions() []PlanDefinition {
+	var definitions []PlanDefinition
+	for name, plan := range p.plans {
+		if !plan.hasCatalogFields() {
+			continue
+		}
+		definitions = append(definitions, PlanDefinition{
+			ID:                        plan.ID,
+			Name:                      name,
+			Provider:                  cloudProvider(plan.Provider),
+			Trial:                     plan.Trial,
+			Free:                      plan.Free,
+			OwnCluster:                plan.OwnCluster,
+			Bindable:                  plan.Bindable,
+			AdditionalWorkerNodePools: plan.AdditionalWorkerNodePools,
+			UpgradableToPlans:         plan.UpgradableToPlans,
+		})
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
+
+// Validate checks the catalog fields, the validation against built-in plans is done when plans are registered in the broker
+func (p *PlanSpecifications) Validate() error {
+	names := map[string]string{}
+	for name, plan := range p.plans {
+		if plan.ID == "" {
+			continue
+		}
+		if _, err := uuid.Parse(plan.ID); err != nil {
+			return fmt.Errorf("plan %s: id %q is not a valid UUID", name, plan.ID)
+		}
+		if other, found := names[plan.ID]; found {
+			return fmt.Errorf("plans %s and %s have the same id %s", other, name, plan.ID)
+		}
+		names[plan.ID] = name
+	}
+
+	for name, plan := range p.plans {
+		if plan.Provider != "" && cloudProvider(plan.Provider) == runtime.UnknownProvider {
+			return fmt.Errorf("plan %s: unknown provider %q", name, plan.Provider)
+		}
+		if isSet(plan.Trial) && isSet(plan.Free) {
+			return fmt.Errorf("plan %s: a plan cannot be trial and free at the same time", name)
+		}
+		if isSet(plan.OwnCluster) && plan.Provider != "" {
+			return fmt.Errorf("plan %s: own cluster plan cannot define a provider", name)
+		}
+	}
+	return nil
+}
+
+func (p planSpecificationDTO) hasCatalogFields() bool {
+	return p.ID != "" || p.Provider != "" || p.Trial != nil || p.Free != nil || p.OwnCluster != nil || p.Bindable != nil || p.AdditionalWorkerNodePools != nil
+}
+
+func isSet(flag *bool) bool {
+	return flag != nil && *flag
+}
+
+func cloudProvider(name string) runtime.CloudProvider {
+	if name == "" {
+		return ""
+	}
+	for _, cp := range []runtime.CloudProvider{runtime.AWS, runtime.GCP, runtime.Azure, runtime.SapConvergedCloud, runtime.Alicloud} {
+		// remove '-' to support "sap-converged-cloud" for CloudProvider SapConvergedCloud
+		if strings.ToLower(strings.ReplaceAll(name, "-", "")) == strings.ToLower(string(cp)) {
+			return cp
+		}
+	}
+	if strings.ToLower(name) == "openstack" {
+		return runtime.SapConvergedCloud
+	}
+	return runtime.UnknownProvider
 }
 
 func (p *PlanSpecifications) Regions(planName string, platformRegion string) []string {
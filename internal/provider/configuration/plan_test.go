@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -52,3 +55,101 @@ sap-converged-cloud:
 	assert.False(t, spec.IsUpgradableBetween("plan1", "plan3-bis"))
 	assert.False(t, spec.IsUpgradableBetween("plan1-not-existing", "plan2"))
 }
+
+func TestPlanConfiguration_Definitions(t *testing.T) {
+	// given
+	spec, err := NewPlanSpecifications(strings.NewReader(`
+aws:
+        bindable: true
+        regions:
+            default:
+                - eu-central-1
+aws-large:
+        id: 6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5
+        provider: aws
+        additionalWorkerNodePools: false
+        upgradableToPlans: [aws]
+        regions:
+            default:
+                - eu-central-1
+converged-small:
+        id: 0f3a4c1e-6c5c-4a0e-9c1b-2f9c5f3f7e21
+        provider: sap-converged-cloud
+gcp:
+        regions:
+            default:
+                - europe-west3
+`))
+	require.NoError(t, err)
+
+	// when
+	err = spec.Validate()
+	definitions := spec.Definitions()
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, definitions, 3)
+	assert.Equal(t, PlanDefinition{Name: "aws", Bindable: ptr.Bool(true)}, definitions[0])
+	assert.Equal(t, "aws-large", definitions[1].Name)
+	assert.Equal(t, "6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5", definitions[1].ID)
+	assert.Equal(t, runtime.AWS, definitions[1].Provider)
+	require.NotNil(t, definitions[1].AdditionalWorkerNodePools)
+	assert.False(t, *definitions[1].AdditionalWorkerNodePools)
+	assert.Equal(t, []string{"aws"}, definitions[1].UpgradableToPlans)
+	assert.Equal(t, runtime.SapConvergedCloud, definitions[2].Provider)
+}
+
+func TestPlanConfiguration_Validate(t *testing.T) {
+	for tn, tc := range map[string]struct {
+		config        string
+		expectedError string
+	}{
+		"invalid id": {
+			config: `
+plan1:
+        id: not-a-uuid
+        provider: aws`,
+			expectedError: `plan plan1: id "not-a-uuid" is not a valid UUID`,
+		},
+		"duplicated id": {
+			config: `
+plan1,plan2:
+        id: 6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5
+        provider: aws`,
+			expectedError: "have the same id 6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5",
+		},
+		"unknown provider": {
+			config: `
+plan1:
+        id: 6c5bd7f4-2b7b-4a32-a2d9-0de0c7d3a4b5
+        provider: ibm`,
+			expectedError: `plan plan1: unknown provider "ibm"`,
+		},
+		"trial and free": {
+			config: `
+plan1:
+        trial: true
+        free: true`,
+			expectedError: "plan plan1: a plan cannot be trial and free at the same time",
+		},
+		"own cluster with provider": {
+			config: `
+plan1:
+        ownCluster: true
+        provider: gcp`,
+			expectedError: "plan plan1: own cluster plan cannot define a provider",
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			// given
+			spec, err := NewPlanSpecifications(strings.NewReader(tc.config))
+			require.NoError(t, err)
+
+			// when
+			err = spec.Validate()
+
+			// then
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -157,7 +157,14 @@ func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningPara
 	case broker.OwnClusterPlanID:
 		p = &OwnClusterinputProvider{}
 	default:
-		return internal.ProviderValues{}, fmt.Errorf("plan %s not supported", provisioningParameters.PlanID)
+		traits, found := broker.Traits(provisioningParameters.PlanID)
+		if !found || !traits.Custom {
+			return internal.ProviderValues{}, fmt.Errorf("plan %s not supported", provisioningParameters.PlanID)
+		}
+		p = s.customPlanProvider(traits, provisioningParameters)
+		if p == nil {
+			return internal.ProviderValues{}, fmt.Errorf("provider %s for plan %s not supported", traits.Provider, provisioningParameters.PlanID)
+		}
 	}
 
 	values := p.Provide()
@@ -168,6 +175,109 @@ func (s *PlanSpecificValuesProvider) ValuesForPlanAndParameters(provisioningPara
 	return values, nil
 }
 
+// customPlanProvider returns the input provider for the cloud provider of a plan defined in the plans configuration file,
+// trial and free plans use the trial and freemium input providers, other plans the commercial ones
+func (s *PlanSpecificValuesProvider) customPlanProvider(traits broker.PlanTraits, provisioningParameters internal.ProvisioningParameters) Provider {
+	switch {
+	case traits.OwnCluster:
+		return &OwnClusterinputProvider{}
+	case traits.Trial:
+		return s.customTrialPlanProvider(traits.Provider, provisioningParameters)
+	case traits.Free:
+		return s.customFreePlanProvider(traits.Provider, provisioningParameters)
+	}
+	switch traits.Provider {
+	case pkg.AWS:
+		return &AWSInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.Azure:
+		return &AzureInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.GCP:
+		return &GCPInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.SapConvergedCloud:
+		return &SapConvergedCloudInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.Alicloud:
+		return &AlicloudInputProvider{
+			Purpose:                s.defaultPurpose,
+			MultiZone:              s.multiZoneCluster,
+			ProvisioningParameters: provisioningParameters,
+			FailureTolerance:       s.commercialFailureTolerance,
+			ZonesProvider:          s.zonesProvider,
+		}
+	default:
+		return nil
+	}
+}
+
+func (s *PlanSpecificValuesProvider) customTrialPlanProvider(provider pkg.CloudProvider, provisioningParameters internal.ProvisioningParameters) Provider {
+	switch provider {
+	case pkg.AWS:
+		return &AWSTrialInputProvider{
+			PlatformRegionMapping:  s.trialPlatformRegionMapping,
+			UseSmallerMachineTypes: s.useSmallerMachineTypes,
+			ProvisioningParameters: provisioningParameters,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.GCP:
+		return &GCPTrialInputProvider{
+			PlatformRegionMapping:  s.trialPlatformRegionMapping,
+			ProvisioningParameters: provisioningParameters,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.Azure:
+		return &AzureTrialInputProvider{
+			PlatformRegionMapping:  s.trialPlatformRegionMapping,
+			UseSmallerMachineTypes: s.useSmallerMachineTypes,
+			ProvisioningParameters: provisioningParameters,
+			ZonesProvider:          s.zonesProvider,
+		}
+	default:
+		return nil
+	}
+}
+
+func (s *PlanSpecificValuesProvider) customFreePlanProvider(provider pkg.CloudProvider, provisioningParameters internal.ProvisioningParameters) Provider {
+	switch provider {
+	case pkg.AWS:
+		return &AWSFreemiumInputProvider{
+			UseSmallerMachineTypes: s.useSmallerMachineTypes,
+			ProvisioningParameters: provisioningParameters,
+			ZonesProvider:          s.zonesProvider,
+		}
+	case pkg.Azure:
+		return &AzureFreemiumInputProvider{
+			UseSmallerMachineTypes: s.useSmallerMachineTypes,
+			ProvisioningParameters: provisioningParameters,
+			ZonesProvider:          s.zonesProvider,
+		}
+	default:
+		return nil
+	}
+}
+
 func ProviderToCloudProvider(providerType string) pkg.CloudProvider {
 	switch providerType {
 	case "azure":
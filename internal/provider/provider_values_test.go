@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/provider/configuration"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuesForPlanAndParameters_CustomPlans(t *testing.T) {
+	// given
+	const (
+		trialPlanID = "2b8a1f3c-5d7e-4f90-8a6b-1c2d3e4f5a6b"
+		freePlanID  = "7e6d5c4b-3a29-4180-9f8e-7d6c5b4a3f2e"
+	)
+	require.NoError(t, broker.RegisterPlans([]configuration.PlanDefinition{
+		{ID: trialPlanID, Name: "custom-trial", Provider: pkg.AWS, Trial: ptr.Bool(true)},
+		{ID: freePlanID, Name: "custom-free", Provider: pkg.Azure, Free: ptr.Bool(true)},
+	}))
+	planSpec, err := configuration.NewPlanSpecifications(strings.NewReader("aws:\n  volumeSizeGb: 80\n"))
+	require.NoError(t, err)
+	valuesProvider := NewPlanSpecificValuesProvider(broker.InfrastructureManager{DefaultTrialProvider: pkg.AWS},
+		TestTrialPlatformRegionMapping, FakeZonesProvider([]string{"a", "b", "c"}), planSpec)
+
+	for tn, tc := range map[string]struct {
+		customPlanID  string
+		builtInParams internal.ProvisioningParameters
+	}{
+		"trial plan": {
+			customPlanID:  trialPlanID,
+			builtInParams: internal.ProvisioningParameters{PlanID: broker.TrialPlanID, PlatformRegion: "cf-eu10"},
+		},
+		"free plan": {
+			customPlanID:  freePlanID,
+			builtInParams: internal.ProvisioningParameters{PlanID: broker.FreemiumPlanID, PlatformRegion: "cf-eu10", PlatformProvider: pkg.Azure},
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			customParams := tc.builtInParams
+			customParams.PlanID = tc.customPlanID
+
+			// when
+			values, err := valuesProvider.ValuesForPlanAndParameters(customParams)
+
+			// then
+			require.NoError(t, err)
+			expected, err := valuesProvider.ValuesForPlanAndParameters(tc.builtInParams)
+			require.NoError(t, err)
+			assert.Equal(t, expected, values)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package reencryption
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+type Config struct {
+	// Enables encrypting stored secrets again with the active key after the broker starts
+	Enabled bool `envconfig:"default=false"`
+	// Number of rows read and written in one batch
+	BatchSize int `envconfig:"default=100"`
+	// Pause between batches, limits the load of the database
+	BatchInterval time.Duration `envconfig:"default=1s"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("(Enabled=%t; BatchSize=%d; BatchInterval=%s)", c.Enabled, c.BatchSize, c.BatchInterval)
+}
+
+// Stats counts rows processed by a pass over all tables
+type Stats struct {
+	Scanned     int
+	Reencrypted int
+	Conflicts   int
+	Failed      int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("scanned=%d, reencrypted=%d, conflicts=%d, failed=%d", s.Scanned, s.Reencrypted, s.Conflicts, s.Failed)
+}
+
+// Worker encrypts secrets written with other keys again with the active key. All replicas can run it at the same time,
+// a row is written only if it was not changed since it was read.
+type Worker struct {
+	storage storage.Reencryption
+	cfg     Config
+	log     *slog.Logger
+}
+
+func NewWorker(storage storage.Reencryption, cfg Config, log *slog.Logger) *Worker {
+	return &Worker{
+		storage: storage,
+		cfg:     cfg,
+		log:     log.With("service", "Reencryption"),
+	}
+}
+
+// Run repeats passes until a pass finds no row to encrypt again. Rows which cannot be decrypted are only logged,
+// they are retried after the broker restarts, for example, with the missing key configured.
+func (w *Worker) Run(ctx context.Context) {
+	w.log.Info("starting the re-encryption")
+	for {
+		stats, err := w.Pass(ctx)
+		if err != nil {
+			w.log.Error(fmt.Sprintf("re-encryption interrupted: %s", err))
+			return
+		}
+		w.log.Info(fmt.Sprintf("re-encryption pass finished: %s", stats))
+		if stats.Reencrypted == 0 && stats.Conflicts == 0 {
+			w.log.Info("re-encryption finished")
+			return
+		}
+	}
+}
+
+// Pass processes all tables once
+func (w *Worker) Pass(ctx context.Context) (Stats, error) {
+	var stats Stats
+	for _, table := range w.storage.Tables() {
+		var after []string
+		for {
+			batch, err := w.storage.ReencryptBatch(table, after, w.cfg.BatchSize)
+			if err != nil {
+				return stats, fmt.Errorf("while re-encrypting %s: %w", table, err)
+			}
+			for _, rowErr := range batch.Errors {
+				w.log.Error(fmt.Sprintf("unable to re-encrypt: %s", rowErr))
+			}
+			stats.Scanned += batch.Scanned
+			stats.Reencrypted += batch.Reencrypted
+			stats.Conflicts += batch.Conflicts
+			stats.Failed += len(batch.Errors)
+			if batch.Scanned < w.cfg.BatchSize {
+				break
+			}
+			after = batch.Last
+
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-time.After(w.cfg.BatchInterval):
+			}
+		}
+	}
+	return stats, nil
+}
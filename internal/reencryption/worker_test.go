@@ -0,0 +1,83 @@
+package reencryption_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/reencryption"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	cfg := reencryption.Config{Enabled: true, BatchSize: 2}
+
+	t.Run("should process all tables in batches", func(t *testing.T) {
+		// given
+		db := &fakeStorage{rows: map[string]int{"instances": 5, "bindings": 2}, stale: map[string]int{"instances": 3, "bindings": 1}}
+
+		// when
+		stats, err := reencryption.NewWorker(db, cfg, logger).Pass(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, reencryption.Stats{Scanned: 7, Reencrypted: 4}, stats)
+		assert.Equal(t, []string{"instances:", "instances:2", "instances:4", "bindings:", "bindings:2"}, db.calls)
+	})
+
+	t.Run("should repeat passes until nothing is left", func(t *testing.T) {
+		// given
+		db := &fakeStorage{rows: map[string]int{"instances": 3}, stale: map[string]int{"instances": 3}, failing: 1}
+
+		// when
+		reencryption.NewWorker(db, cfg, logger).Run(context.Background())
+
+		// then
+		assert.Equal(t, 0, db.stale["instances"])
+		// the last pass only confirms there is nothing left
+		assert.Equal(t, []string{"instances:", "instances:2", "bindings:"}, db.calls[6:])
+		assert.Len(t, db.calls, 9)
+	})
+}
+
+// fakeStorage re-encrypts stale rows in the order of batches, the first failing rows are reported as errors
+type fakeStorage struct {
+	rows    map[string]int
+	stale   map[string]int
+	failing int
+	calls   []string
+}
+
+func (f *fakeStorage) Tables() []string {
+	return []string{"instances", "bindings"}
+}
+
+func (f *fakeStorage) ReencryptBatch(table string, after []string, limit int) (dbmodel.ReencryptionBatch, error) {
+	start := 0
+	if len(after) > 0 {
+		_, _ = fmt.Sscanf(after[0], "%d", &start)
+	}
+	f.calls = append(f.calls, fmt.Sprintf("%s:%s", table, strings.Join(after, ",")))
+
+	batch := dbmodel.ReencryptionBatch{Scanned: min(limit, f.rows[table]-start)}
+	batch.Last = []string{fmt.Sprint(start + batch.Scanned)}
+	for i := 0; i < batch.Scanned && f.stale[table] > 0; i++ {
+		if f.failing > 0 {
+			f.failing--
+			batch.Errors = append(batch.Errors, fmt.Errorf("unknown encryption key"))
+			continue
+		}
+		f.stale[table]--
+		batch.Reencrypted++
+	}
+	return batch, nil
+}
@@ -230,6 +230,15 @@ func (c *converter) adjustRuntimeState(dto *pkg.RuntimeDTO) {
 		case pkg.Update:
 			dto.Status.State = pkg.StateUpdating
 		}
+	case internal.OperationStateCanceling, internal.OperationStateCanceled:
+		dto.Status.State = pkg.StateSucceeded
+		switch lastOp.Type {
+		case pkg.Provision, pkg.Deprovision, pkg.Update:
+			dto.Status.State = pkg.StateCanceled
+			if lastOp.State == internal.OperationStateCanceling {
+				dto.Status.State = pkg.StateCanceling
+			}
+		}
 	default:
 		dto.Status.State = pkg.StateSucceeded
 	}
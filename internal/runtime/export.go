@@ -0,0 +1,154 @@
+package runtime
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+const (
+	exportFormatParam = "format"
+	exportFormatJSON  = "ndjson"
+	exportFormatCSV   = "csv"
+)
+
+var exportCSVHeader = []string{
+	"instanceID", "runtimeID", "globalAccountID", "subAccountID", "subAccountRegion", "region", "provider",
+	"servicePlanName", "shootName", "state", "createdAt", "modifiedAt", "expiredAt",
+}
+
+type exportWriter interface {
+	Write(dto pkg.RuntimeDTO) error
+	Flush() error
+}
+
+// exportRuntimes streams all runtimes matching the filters of the /runtimes endpoint, runtimes are read from the database
+// in pages using the cursor, so the whole result is never kept in memory
+func (h *Handler) exportRuntimes(w http.ResponseWriter, req *http.Request) {
+	filter, err := h.getFilters(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("export is not supported for the %s state", pkg.StateDeprovisioned))
+		return
+	}
+	if len(filter.Sort) > 0 {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("export is ordered by the creation time, the %s parameter is not supported", pkg.SortParam))
+		return
+	}
+	options, err := getDetailsOptions(req, pkg.LastOperation)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var writer exportWriter
+	switch format := req.URL.Query().Get(exportFormatParam); format {
+	case "", exportFormatJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		writer = &jsonExportWriter{encoder: json.NewEncoder(w), w: w}
+	case exportFormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="runtimes.csv"`)
+		csvWriter := &csvExportWriter{writer: csv.NewWriter(w), w: w}
+		if err := csvWriter.writer.Write(exportCSVHeader); err != nil {
+			return
+		}
+		writer = csvWriter
+	default:
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("unsupported format %s, use %s or %s", format, exportFormatJSON, exportFormatCSV))
+		return
+	}
+
+	filter.PageSize = h.defaultMaxPage
+	filter.After = &dbmodel.InstanceCursor{}
+	exported := 0
+	for {
+		instances, _, _, err := h.listInstances(filter)
+		if err != nil {
+			h.abortExport(exported, fmt.Errorf("while fetching instances: %w", err))
+		}
+		for _, dto := range instances {
+			if err := h.setDetails(&dto, options); err != nil {
+				h.abortExport(exported, err)
+			}
+			if err := writer.Write(dto); err != nil {
+				h.abortExport(exported, err)
+			}
+			exported++
+		}
+		if err := writer.Flush(); err != nil {
+			h.abortExport(exported, err)
+		}
+
+		filter.After = lastInstance(filter, instances)
+		if filter.After == nil {
+			break
+		}
+	}
+	h.logger.Info(fmt.Sprintf("exported %d runtimes", exported))
+}
+
+// abortExport closes the connection without finishing the response, so clients do not take a partial export as a complete one
+func (h *Handler) abortExport(exported int, err error) {
+	h.logger.Warn(fmt.Sprintf("export aborted after %d runtimes: %s", exported, err))
+	panic(http.ErrAbortHandler)
+}
+
+type jsonExportWriter struct {
+	encoder *json.Encoder
+	w       http.ResponseWriter
+}
+
+func (j *jsonExportWriter) Write(dto pkg.RuntimeDTO) error {
+	return j.encoder.Encode(dto)
+}
+
+func (j *jsonExportWriter) Flush() error {
+	return flush(j.w)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+	w      http.ResponseWriter
+}
+
+func (c *csvExportWriter) Write(dto pkg.RuntimeDTO) error {
+	return c.writer.Write([]string{
+		dto.InstanceID, dto.RuntimeID, dto.GlobalAccountID, dto.SubAccountID, dto.SubAccountRegion, dto.ProviderRegion, dto.Provider,
+		dto.ServicePlanName, dto.ShootName, string(dto.Status.State), formatTime(&dto.Status.CreatedAt), formatTime(&dto.Status.ModifiedAt), formatTime(dto.Status.ExpiredAt),
+	})
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+	return flush(c.w)
+}
+
+// flush sends buffered data to the client, writers wrapped by middlewares which cannot flush are buffered by the server
+func flush(w http.ResponseWriter) error {
+	if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kyma-project/kyma-environment-broker/internal/process/steps"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,6 +42,7 @@ type Handler struct {
 	instancesArchivedDb storage.InstancesArchived
 	subaccountStatesDb  storage.SubaccountStates
 	actionsDb           storage.Actions
+	snapshotsDb         storage.InstanceSnapshots
 	converter           Converter
 	defaultMaxPage      int
 	k8sClient           client.Client
@@ -52,6 +58,7 @@ func NewHandler(storage storage.BrokerStorage, defaultMaxPage int, defaultReques
 		instancesArchivedDb: storage.InstancesArchived(),
 		subaccountStatesDb:  storage.SubaccountStates(),
 		actionsDb:           storage.Actions(),
+		snapshotsDb:         storage.InstanceSnapshots(),
 		converter:           NewConverter(defaultRequestRegion),
 		defaultMaxPage:      defaultMaxPage,
 		k8sClient:           k8sClient,
@@ -61,6 +68,7 @@ func NewHandler(storage storage.BrokerStorage, defaultMaxPage int, defaultReques
 
 func (h *Handler) AttachRoutes(router *httputil.Router) {
 	router.HandleFunc("/runtimes", h.getRuntimes)
+	router.HandleFunc("GET /runtimes/export", h.exportRuntimes)
 }
 
 func unionInstances(sets ...[]pkg.RuntimeDTO) (union []pkg.RuntimeDTO) {
@@ -172,6 +180,29 @@ func (h *Handler) InstanceFromInstanceArchived(archived internal.InstanceArchive
 	}
 }
 
+// detailsOptions selects details set for each runtime returned by the /runtimes and /runtimes/export endpoints
+type detailsOptions struct {
+	opDetail              pkg.OperationDetail
+	runtimeResourceConfig bool
+	bindings              bool
+	actions               bool
+	at                    time.Time
+}
+
+func getDetailsOptions(req *http.Request, defaultOpDetail pkg.OperationDetail) (detailsOptions, error) {
+	at, err := getTimeParam(pkg.AtParam, req)
+	if err != nil {
+		return detailsOptions{}, err
+	}
+	return detailsOptions{
+		opDetail:              getOpDetail(req, defaultOpDetail),
+		runtimeResourceConfig: getBoolParam(pkg.RuntimeConfigParam, req),
+		bindings:              getBoolParam(pkg.BindingsParam, req),
+		actions:               getBoolParam(pkg.ActionsParam, req),
+		at:                    at,
+	}, nil
+}
+
 func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 	toReturn := make([]pkg.RuntimeDTO, 0)
 
@@ -181,13 +212,22 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
 		return
 	}
-	filter := h.getFilters(req)
+	filter, err := h.getFilters(req)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
 	filter.PageSize = pageSize
 	filter.Page = page
-	opDetail := getOpDetail(req)
-	runtimeResourceConfig := getBoolParam(pkg.RuntimeConfigParam, req)
-	bindings := getBoolParam(pkg.BindingsParam, req)
-	actions := getBoolParam(pkg.ActionsParam, req)
+	if err := setCursor(&filter, req.URL.Query().Get(pagination.CursorParam)); err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	options, err := getDetailsOptions(req, pkg.AllOperation)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
 
 	instances, count, totalCount, err := h.listInstances(filter)
 	if err != nil {
@@ -195,76 +235,13 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 		httputil.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("while fetching instances: %s", err.Error()))
 		return
 	}
+	nextCursor := nextCursor(filter, instances)
 
 	for _, dto := range instances {
-
-		switch opDetail {
-		case pkg.AllOperation:
-			err = h.setRuntimeAllOperations(&dto)
-		case pkg.LastOperation:
-			err = h.setRuntimeLastOperation(&dto)
-		}
-		if err != nil {
-			h.logger.Warn(fmt.Sprintf("unable to set operations: %s", err.Error()))
-			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-			return
-		}
-
-		err = h.determineStatusModifiedAt(&dto)
-		if err != nil {
-			h.logger.Warn(fmt.Sprintf("unable to determine status: %s", err.Error()))
+		if err := h.setDetails(&dto, options); err != nil {
 			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
 			return
 		}
-
-		if runtimeResourceConfig && dto.RuntimeID != "" {
-			runtimeResourceName, runtimeNamespaceName := h.getRuntimeNamesFromLastOperation(dto)
-
-			runtimeResourceObject := &unstructured.Unstructured{}
-			runtimeResourceObject.SetGroupVersionKind(RuntimeResourceGVK())
-			err = h.k8sClient.Get(context.Background(), client.ObjectKey{
-				Namespace: runtimeNamespaceName,
-				Name:      runtimeResourceName,
-			}, runtimeResourceObject)
-			switch {
-			case errors.IsNotFound(err):
-				h.logger.Info(fmt.Sprintf("Runtime resource (instanceID=%s, runtimeID=%s): is not found: %s", dto.InstanceID, dto.RuntimeID, err.Error()))
-				dto.RuntimeConfig = nil
-			case err != nil:
-				h.logger.Warn(fmt.Sprintf("unable to get Runtime resource (instanceID=%s, runtimeID=%s): %s", dto.InstanceID, dto.RuntimeID, err.Error()))
-				dto.RuntimeConfig = nil
-			default:
-				// remove managedFields from the object to reduce the size of the response
-				_, ok := runtimeResourceObject.Object["metadata"].(map[string]interface{})
-				if !ok {
-					h.logger.Warn(fmt.Sprintf("unable to get Runtime resource metadata (instanceID=%s, runtimeID=%s): %s", dto.InstanceID, dto.RuntimeID, err.Error()))
-					dto.RuntimeConfig = nil
-
-				} else {
-					delete(runtimeResourceObject.Object["metadata"].(map[string]interface{}), "managedFields")
-					dto.RuntimeConfig = &runtimeResourceObject.Object
-				}
-			}
-
-		}
-		if bindings {
-			err := h.addBindings(&dto)
-			if err != nil {
-				h.logger.Warn(fmt.Sprintf("unable to apply bindings: %s", err.Error()))
-				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-				return
-			}
-		}
-		if actions {
-			actions, err := h.actionsDb.ListActionsByInstanceID(dto.InstanceID)
-			if err != nil {
-				h.logger.Warn(fmt.Sprintf("unable to list actions: %s", err.Error()))
-				httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
-				return
-			}
-			dto.Actions = actions
-		}
-
 		toReturn = append(toReturn, dto)
 	}
 
@@ -272,10 +249,125 @@ func (h *Handler) getRuntimes(w http.ResponseWriter, req *http.Request) {
 		Data:       toReturn,
 		Count:      count,
 		TotalCount: totalCount,
+		NextCursor: nextCursor,
 	}
 	httputil.WriteResponse(w, http.StatusOK, runtimePage)
 }
 
+// setCursor selects the keyset pagination, it is not supported for deprovisioned runtimes which are merged from two tables
+// and for runtimes sorted by other fields than the creation time
+func setCursor(filter *dbmodel.InstanceFilter, cursor string) error {
+	if cursor == "" {
+		return nil
+	}
+	if slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) {
+		return fmt.Errorf("the %s parameter is not supported for the %s state", pagination.CursorParam, pkg.StateDeprovisioned)
+	}
+	if len(filter.Sort) > 0 {
+		return fmt.Errorf("the %s parameter cannot be used together with the %s parameter", pagination.CursorParam, pkg.SortParam)
+	}
+	createdAt, instanceID, err := pagination.DecodeCursor(cursor)
+	if err != nil {
+		return err
+	}
+	filter.After = &dbmodel.InstanceCursor{CreatedAt: createdAt, InstanceID: instanceID}
+	return nil
+}
+
+// nextCursor returns the cursor pointing to the last runtime of a full page, the next page can be empty
+func nextCursor(filter dbmodel.InstanceFilter, instances []pkg.RuntimeDTO) string {
+	last := lastInstance(filter, instances)
+	if last == nil {
+		return ""
+	}
+	return pagination.EncodeCursor(last.CreatedAt, last.InstanceID)
+}
+
+func lastInstance(filter dbmodel.InstanceFilter, instances []pkg.RuntimeDTO) *dbmodel.InstanceCursor {
+	if len(instances) == 0 || len(instances) < filter.PageSize || len(filter.Sort) > 0 || slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) {
+		return nil
+	}
+	last := instances[len(instances)-1]
+	return &dbmodel.InstanceCursor{CreatedAt: last.Status.CreatedAt, InstanceID: last.InstanceID}
+}
+
+func (h *Handler) setDetails(dto *pkg.RuntimeDTO, options detailsOptions) error {
+	var err error
+	switch options.opDetail {
+	case pkg.AllOperation:
+		err = h.setRuntimeAllOperations(dto)
+	case pkg.LastOperation:
+		err = h.setRuntimeLastOperation(dto)
+	}
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("unable to set operations: %s", err.Error()))
+		return err
+	}
+
+	err = h.determineStatusModifiedAt(dto)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("unable to determine status: %s", err.Error()))
+		return err
+	}
+
+	if options.runtimeResourceConfig && dto.RuntimeID != "" {
+		h.setRuntimeResourceConfig(dto)
+	}
+	if options.bindings {
+		err := h.addBindings(dto)
+		if err != nil {
+			h.logger.Warn(fmt.Sprintf("unable to apply bindings: %s", err.Error()))
+			return err
+		}
+	}
+	if options.actions {
+		actions, err := h.actionsDb.ListActionsByInstanceID(dto.InstanceID)
+		if err != nil {
+			h.logger.Warn(fmt.Sprintf("unable to list actions: %s", err.Error()))
+			return err
+		}
+		dto.Actions = actions
+	}
+	if !options.at.IsZero() {
+		err := h.setParametersAt(dto, options.at)
+		if err != nil {
+			h.logger.Warn(fmt.Sprintf("unable to set parameters at %s: %s", options.at.Format(time.RFC3339), err.Error()))
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) setRuntimeResourceConfig(dto *pkg.RuntimeDTO) {
+	runtimeResourceName, runtimeNamespaceName := h.getRuntimeNamesFromLastOperation(*dto)
+
+	runtimeResourceObject := &unstructured.Unstructured{}
+	runtimeResourceObject.SetGroupVersionKind(RuntimeResourceGVK())
+	err := h.k8sClient.Get(context.Background(), client.ObjectKey{
+		Namespace: runtimeNamespaceName,
+		Name:      runtimeResourceName,
+	}, runtimeResourceObject)
+	switch {
+	case errors.IsNotFound(err):
+		h.logger.Info(fmt.Sprintf("Runtime resource (instanceID=%s, runtimeID=%s): is not found: %s", dto.InstanceID, dto.RuntimeID, err.Error()))
+		dto.RuntimeConfig = nil
+	case err != nil:
+		h.logger.Warn(fmt.Sprintf("unable to get Runtime resource (instanceID=%s, runtimeID=%s): %s", dto.InstanceID, dto.RuntimeID, err.Error()))
+		dto.RuntimeConfig = nil
+	default:
+		// remove managedFields from the object to reduce the size of the response
+		_, ok := runtimeResourceObject.Object["metadata"].(map[string]interface{})
+		if !ok {
+			h.logger.Warn(fmt.Sprintf("unable to get Runtime resource metadata (instanceID=%s, runtimeID=%s)", dto.InstanceID, dto.RuntimeID))
+			dto.RuntimeConfig = nil
+
+		} else {
+			delete(runtimeResourceObject.Object["metadata"].(map[string]interface{}), "managedFields")
+			dto.RuntimeConfig = &runtimeResourceObject.Object
+		}
+	}
+}
+
 func (h *Handler) getRuntimeNamesFromLastOperation(dto pkg.RuntimeDTO) (string, string) {
 	// TODO get rid of additional DB query - we have this info fetched from DB but it is tedious to pass it through
 	op, err := h.operationsDb.GetLastOperation(dto.InstanceID)
@@ -411,8 +503,9 @@ func (h *Handler) setRuntimeLastOperation(dto *pkg.RuntimeDTO) error {
 	return nil
 }
 
-func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
+func (h *Handler) getFilters(req *http.Request) (dbmodel.InstanceFilter, error) {
 	var filter dbmodel.InstanceFilter
+	var err error
 	query := req.URL.Query()
 	// For optional filter, zero value (nil) is fine if not supplied
 	filter.GlobalAccountIDs = query[pkg.GlobalAccountIDParam]
@@ -422,6 +515,38 @@ func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
 	filter.Regions = query[pkg.RegionParam]
 	filter.Shoots = query[pkg.ShootParam]
 	filter.Plans = query[pkg.PlanParam]
+	filter.Providers = query[pkg.ProviderParam]
+	filter.LicenseTypes = query[pkg.LicenseTypeParam]
+	filter.CommercialModels = query[pkg.CommercialModelParam]
+	filter.MachineTypes = query[pkg.MachineTypeParam]
+	filter.KubernetesVersions = query[pkg.KubernetesVersionParam]
+	filter.LastErrorComponents = query[pkg.ErrorComponentParam]
+	filter.LastErrorReasons = query[pkg.ErrorReasonParam]
+	if v := query.Get(pkg.AdditionalWorkerNodePoolsParam); v != "" {
+		pools, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s parameter, true or false expected", pkg.AdditionalWorkerNodePoolsParam)
+		}
+		filter.AdditionalWorkerNodePools = &pools
+	}
+	if filter.CreatedAfter, err = getTimeParam(pkg.CreatedAfterParam, req); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = getTimeParam(pkg.CreatedBeforeParam, req); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedAfter, err = getTimeParam(pkg.UpdatedAfterParam, req); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedBefore, err = getTimeParam(pkg.UpdatedBeforeParam, req); err != nil {
+		return filter, err
+	}
+	if filter.LabelSelector, err = getLabelSelector(query.Get(pkg.LabelSelectorParam)); err != nil {
+		return filter, err
+	}
+	if filter.Sort, err = getSort(query[pkg.SortParam]); err != nil {
+		return filter, err
+	}
 	if v, exists := query[pkg.WithBindingsParam]; exists && v[0] == "true" {
 		filter.BindingExists = ptr.Bool(true)
 	}
@@ -455,6 +580,10 @@ func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
 				filter.Suspended = &suspended
 			case pkg.StateDeprovisioned:
 				filter.States = append(filter.States, dbmodel.InstanceDeprovisioned)
+			case pkg.StateCanceling:
+				filter.States = append(filter.States, dbmodel.InstanceCanceling)
+			case pkg.StateCanceled:
+				filter.States = append(filter.States, dbmodel.InstanceCanceled)
 			case pkg.StateDeprovisionIncomplete:
 				deletionAttempted := true
 				filter.DeletionAttempted = &deletionAttempted
@@ -467,7 +596,107 @@ func (h *Handler) getFilters(req *http.Request) dbmodel.InstanceFilter {
 		}
 	}
 
-	return filter
+	if slices.Contains(filter.States, dbmodel.InstanceDeprovisioned) {
+		return filter, validateDeprovisionedFilter(filter)
+	}
+	return filter, nil
+}
+
+var sortFields = map[string]dbmodel.InstanceSortField{
+	pkg.SortByCreatedAt:       dbmodel.InstanceSortCreatedAt,
+	pkg.SortByUpdatedAt:       dbmodel.InstanceSortUpdatedAt,
+	pkg.SortByInstanceID:      dbmodel.InstanceSortInstanceID,
+	pkg.SortByRuntimeID:       dbmodel.InstanceSortRuntimeID,
+	pkg.SortByGlobalAccountID: dbmodel.InstanceSortGlobalAccountID,
+	pkg.SortBySubAccountID:    dbmodel.InstanceSortSubAccountID,
+	pkg.SortByRegion:          dbmodel.InstanceSortRegion,
+	pkg.SortByPlan:            dbmodel.InstanceSortPlan,
+	pkg.SortByProvider:        dbmodel.InstanceSortProvider,
+}
+
+// getSort parses values of the sort parameter in the field:asc or field:desc format, the order is ascending if it is omitted
+func getSort(values []string) ([]dbmodel.InstanceSort, error) {
+	var result []dbmodel.InstanceSort
+	for _, value := range values {
+		name, order, _ := strings.Cut(value, ":")
+		field, ok := sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid %s parameter, unknown field %q", pkg.SortParam, name)
+		}
+		switch order {
+		case "", pkg.SortAscending:
+			result = append(result, dbmodel.InstanceSort{Field: field})
+		case pkg.SortDescending:
+			result = append(result, dbmodel.InstanceSort{Field: field, Descending: true})
+		default:
+			return nil, fmt.Errorf("invalid %s parameter, the order must be %s or %s", pkg.SortParam, pkg.SortAscending, pkg.SortDescending)
+		}
+	}
+	return result, nil
+}
+
+// getLabelSelector parses the label selector with the Kubernetes syntax, the gt and lt operators are not supported
+func getLabelSelector(value string) ([]dbmodel.LabelRequirement, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", pkg.LabelSelectorParam, err)
+	}
+	requirements, _ := parsed.Requirements()
+	result := make([]dbmodel.LabelRequirement, 0, len(requirements))
+	for _, requirement := range requirements {
+		var operator dbmodel.LabelOperator
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			operator = dbmodel.LabelOperatorIn
+		case selection.NotEquals, selection.NotIn:
+			operator = dbmodel.LabelOperatorNotIn
+		case selection.Exists:
+			operator = dbmodel.LabelOperatorExists
+		case selection.DoesNotExist:
+			operator = dbmodel.LabelOperatorDoesNotExist
+		default:
+			return nil, fmt.Errorf("invalid %s parameter, the %s operator is not supported", pkg.LabelSelectorParam, requirement.Operator())
+		}
+		result = append(result, dbmodel.LabelRequirement{
+			Key:      requirement.Key(),
+			Operator: operator,
+			Values:   requirement.ValuesUnsorted(),
+		})
+	}
+	return result, nil
+}
+
+// validateDeprovisionedFilter rejects filters and sorting which cannot be applied to deprovisioned runtimes,
+// because they are merged with archived instances which do not keep parameters and operations
+func validateDeprovisionedFilter(filter dbmodel.InstanceFilter) error {
+	unsupported := []struct {
+		param string
+		set   bool
+	}{
+		{pkg.SortParam, len(filter.Sort) > 0},
+		{pkg.ProviderParam, len(filter.Providers) > 0},
+		{pkg.LicenseTypeParam, len(filter.LicenseTypes) > 0},
+		{pkg.CommercialModelParam, len(filter.CommercialModels) > 0},
+		{pkg.MachineTypeParam, len(filter.MachineTypes) > 0},
+		{pkg.KubernetesVersionParam, len(filter.KubernetesVersions) > 0},
+		{pkg.AdditionalWorkerNodePoolsParam, filter.AdditionalWorkerNodePools != nil},
+		{pkg.ErrorComponentParam, len(filter.LastErrorComponents) > 0},
+		{pkg.ErrorReasonParam, len(filter.LastErrorReasons) > 0},
+		{pkg.CreatedAfterParam, !filter.CreatedAfter.IsZero()},
+		{pkg.CreatedBeforeParam, !filter.CreatedBefore.IsZero()},
+		{pkg.UpdatedAfterParam, !filter.UpdatedAfter.IsZero()},
+		{pkg.UpdatedBeforeParam, !filter.UpdatedBefore.IsZero()},
+		{pkg.LabelSelectorParam, len(filter.LabelSelector) > 0},
+	}
+	for _, u := range unsupported {
+		if u.set {
+			return fmt.Errorf("the %s parameter is not supported for the %s state", u.param, pkg.StateDeprovisioned)
+		}
+	}
+	return nil
 }
 
 func (h *Handler) addBindings(p *pkg.RuntimeDTO) error {
@@ -484,14 +713,33 @@ func (h *Handler) addBindings(p *pkg.RuntimeDTO) error {
 			ExpiresAt:         b.ExpiresAt,
 			CreatedBy:         b.CreatedBy,
 			KubeconfigExists:  len(b.Kubeconfig) > 0,
+			Role:              b.Role,
+			Namespaces:        b.Namespaces,
 		})
 	}
 
 	return nil
 }
 
-func getOpDetail(req *http.Request) pkg.OperationDetail {
-	opDetail := pkg.AllOperation
+// setParametersAt replaces parameters with the snapshot effective at the given time,
+// parameters are not changed when there is no snapshot, for example the runtime was provisioned before snapshots were stored
+func (h *Handler) setParametersAt(dto *pkg.RuntimeDTO, at time.Time) error {
+	snapshot, err := h.snapshotsDb.GetAt(dto.InstanceID, at)
+	switch {
+	case dberr.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	dto.Parameters = snapshot.Parameters.Parameters
+	dto.ServicePlanID = snapshot.Parameters.PlanID
+	dto.ServicePlanName = broker.PlanNamesMapping[snapshot.Parameters.PlanID]
+	dto.ParametersVersion = snapshot.Version
+	return nil
+}
+
+func getOpDetail(req *http.Request, defaultOpDetail pkg.OperationDetail) pkg.OperationDetail {
+	opDetail := defaultOpDetail
 	opDetailParams := req.URL.Query()[pkg.OperationDetailParam]
 	for _, p := range opDetailParams {
 		opDetailParam := pkg.OperationDetail(p)
@@ -504,6 +752,18 @@ func getOpDetail(req *http.Request) pkg.OperationDetail {
 	return opDetail
 }
 
+func getTimeParam(param string, req *http.Request) (time.Time, error) {
+	value := req.URL.Query().Get(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s parameter, RFC 3339 time expected: %w", param, err)
+	}
+	return t, nil
+}
+
 func getBoolParam(param string, req *http.Request) bool {
 	requested := false
 	params := req.URL.Query()[param]
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,8 +22,10 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
 	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/runtime"
@@ -106,6 +110,259 @@ func TestRuntimeHandler(t *testing.T) {
 
 	})
 
+	t.Run("test cursor pagination should work", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Now()
+		for _, instance := range []internal.Instance{fixInstance("c", createdAt.Add(time.Minute)), fixInstance("b", createdAt), fixInstance("a", createdAt)} {
+			require.NoError(t, db.Instances().Insert(instance))
+			require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fixRandomID(), instance.InstanceID)))
+		}
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?page_size=2", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		var out pkg.RuntimesPage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		require.Len(t, out.Data, 2)
+		assert.Equal(t, "a", out.Data[0].InstanceID)
+		assert.Equal(t, "b", out.Data[1].InstanceID)
+		require.NotEmpty(t, out.NextCursor)
+		cursor := out.NextCursor
+
+		// when
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?page_size=2&cursor="+cursor, nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		out = pkg.RuntimesPage{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		require.Len(t, out.Data, 1)
+		assert.Equal(t, "c", out.Data[0].InstanceID)
+		assert.Equal(t, 3, out.TotalCount)
+		assert.Empty(t, out.NextCursor)
+
+		// when
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?cursor=invalid", nil))
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		// when
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?state=deprovisioned&cursor="+cursor, nil))
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("test filters and sort should work", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+
+		aws := fixInstance("aws", createdAt)
+		aws.Provider = pkg.AWS
+		aws.UpdatedAt = createdAt.Add(2 * time.Hour)
+		aws.Parameters.ErsContext.LicenseType = ptr.String("SAPDEV")
+		aws.Parameters.Parameters.AdditionalWorkerNodePools = []pkg.AdditionalWorkerNodePool{{Name: "worker", MachineType: "m6i.large"}}
+		aws.InstanceDetails.ProviderValues = &internal.ProviderValues{DefaultMachineType: "m6i.large"}
+		aws.InstanceDetails.KubernetesVersion = "1.33"
+		awsOperation := fixture.FixProvisioningOperation(fixRandomID(), aws.InstanceID)
+		awsOperation.State = domain.Failed
+		awsOperation.LastError = kebError.LastError{Component: kebError.InfrastructureManagerDependency, Reason: kebError.KEBTimeOutCode}
+
+		azure := fixInstance("azure", createdAt.Add(time.Hour))
+		azure.Provider = pkg.Azure
+		azure.UpdatedAt = createdAt.Add(time.Hour)
+		azure.Parameters.ErsContext.CommercialModel = ptr.String("CONSUMPTION")
+		azure.Parameters.Parameters.MachineType = ptr.String("Standard_D4s_v5")
+		azure.InstanceDetails.KubernetesVersion = "1.32"
+		azureOperation := fixture.FixProvisioningOperation(fixRandomID(), azure.InstanceID)
+
+		require.NoError(t, db.Instances().Insert(aws))
+		require.NoError(t, db.Operations().InsertOperation(awsOperation))
+		require.NoError(t, db.Instances().Insert(azure))
+		require.NoError(t, db.Operations().InsertOperation(azureOperation))
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		for query, expected := range map[string][]string{
+			"provider=AWS":                           {"aws"},
+			"license_type=SAPDEV":                    {"aws"},
+			"commercial_model=CONSUMPTION":           {"azure"},
+			"machine_type=m6i.large":                 {"aws"},
+			"machine_type=Standard_D4s_v5":           {"azure"},
+			"kubernetes_version=1.32":                {"azure"},
+			"additional_worker_node_pools=true":      {"aws"},
+			"additional_worker_node_pools=false":     {"azure"},
+			"error_component=infrastructure-manager": {"aws"},
+			"error_reason=err_keb_timeout&state=all": {"aws"},
+			"created_after=2025-10-20T12:30:00Z":     {"azure"},
+			"created_before=2025-10-20T12:30:00Z":    {"aws"},
+			"updated_after=2025-10-20T13:30:00Z":     {"aws"},
+			"updated_before=2025-10-20T13:30:00Z":    {"azure"},
+			"sort=createdAt:desc":                    {"azure", "aws"},
+			"sort=updatedAt":                         {"azure", "aws"},
+			"sort=provider:asc&sort=createdAt:desc":  {"aws", "azure"},
+		} {
+			// when
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?"+query, nil))
+
+			// then
+			require.Equal(t, http.StatusOK, rr.Code, query)
+			var out pkg.RuntimesPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+			var ids []string
+			for _, dto := range out.Data {
+				ids = append(ids, dto.InstanceID)
+			}
+			assert.Equal(t, expected, ids, query)
+		}
+
+		for _, query := range []string{
+			"sort=shoot",
+			"sort=createdAt:up",
+			"created_after=yesterday",
+			"additional_worker_node_pools=maybe",
+			"sort=createdAt&cursor=" + pagination.EncodeCursor(createdAt, "aws"),
+			"state=deprovisioned&provider=AWS",
+		} {
+			// when
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?"+query, nil))
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+		}
+
+		// when
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?page_size=1&sort=createdAt:desc", nil))
+
+		// then
+		var out pkg.RuntimesPage
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		assert.Empty(t, out.NextCursor)
+	})
+
+	t.Run("test label selector should work", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+		for id, labels := range map[string]map[string]string{
+			"prod": {"team": "x", "env": "prod"},
+			"dev":  {"team": "x", "env": "dev"},
+			"none": nil,
+		} {
+			instance := fixInstance(id, createdAt)
+			require.NoError(t, db.Instances().Insert(instance))
+			require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fixRandomID(), instance.InstanceID)))
+			require.NoError(t, db.InstanceLabels().Set(instance.InstanceID, labels))
+		}
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		for selector, expected := range map[string][]string{
+			"team=x":            {"dev", "prod"},
+			"team=x,env!=prod":  {"dev"},
+			"env in (dev,test)": {"dev"},
+			"env notin (dev)":   {"none", "prod"},
+			"!team":             {"none"},
+		} {
+			// when
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?labelSelector="+url.QueryEscape(selector), nil))
+
+			// then
+			require.Equal(t, http.StatusOK, rr.Code, selector)
+			var out pkg.RuntimesPage
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+			var ids []string
+			for _, dto := range out.Data {
+				ids = append(ids, dto.InstanceID)
+			}
+			assert.ElementsMatch(t, expected, ids, selector)
+		}
+
+		for _, query := range []string{
+			"labelSelector=" + url.QueryEscape("team in (x"),
+			"labelSelector=" + url.QueryEscape("size>2"),
+			"state=deprovisioned&labelSelector=team",
+		} {
+			// when
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes?"+query, nil))
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+		}
+	})
+
+	t.Run("test export should stream all runtimes", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		createdAt := time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+		for i, id := range []string{"a", "b", "c"} {
+			instance := fixInstance(id, createdAt.Add(time.Duration(i)*time.Minute))
+			require.NoError(t, db.Instances().Insert(instance))
+			operation := fixture.FixProvisioningOperation(fixRandomID(), instance.InstanceID)
+			operation.State = domain.Succeeded
+			require.NoError(t, db.Operations().InsertOperation(operation))
+		}
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		// when
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes/export", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+		decoder := json.NewDecoder(rr.Body)
+		var ids []string
+		for decoder.More() {
+			var dto pkg.RuntimeDTO
+			require.NoError(t, decoder.Decode(&dto))
+			ids = append(ids, dto.InstanceID)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, ids)
+
+		// when
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes/export?format=csv&instance_id=b", nil))
+
+		// then
+		require.Equal(t, http.StatusOK, rr.Code)
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "instanceID,runtimeID,"))
+		assert.True(t, strings.HasPrefix(lines[1], "b,b,b,b,"))
+		assert.Contains(t, lines[1], ",succeeded,2025-10-20T12:01:00Z,")
+
+		// when
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/runtimes/export?format=xml", nil))
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("test validation should work", func(t *testing.T) {
 		// given
 
@@ -965,9 +1222,9 @@ func TestRuntimeHandler_WithKimOnlyDrivenInstances(t *testing.T) {
 		err = operations.InsertOperation(provOp)
 		require.NoError(t, err)
 
-		err = actions.InsertAction(pkg.PlanUpdateActionType, testID, "test-message-1", "old-value-1", "new-value-1")
+		err = actions.InsertAction(pkg.Action{Type: pkg.PlanUpdateActionType, InstanceID: testID, Message: "test-message-1", OldValue: "old-value-1", NewValue: "new-value-1"})
 		assert.NoError(t, err)
-		err = actions.InsertAction(pkg.SubaccountMovementActionType, testID, "test-message-2", "old-value-2", "new-value-2")
+		err = actions.InsertAction(pkg.Action{Type: pkg.SubaccountMovementActionType, InstanceID: testID, Message: "test-message-2", OldValue: "old-value-2", NewValue: "new-value-2"})
 		assert.NoError(t, err)
 
 		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
@@ -992,6 +1249,67 @@ func TestRuntimeHandler_WithKimOnlyDrivenInstances(t *testing.T) {
 		assert.Equal(t, out.Data[0].Actions[0].Type, pkg.SubaccountMovementActionType)
 		assert.Equal(t, out.Data[0].Actions[1].Type, pkg.PlanUpdateActionType)
 	})
+
+	t.Run("test parameters at a point in time", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		testID := "Test1"
+		testTime := time.Now().UTC().Truncate(time.Second)
+		testInstance := fixInstanceForPreview(testID, testTime)
+		require.NoError(t, db.Instances().Insert(testInstance))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fixRandomID(), testID)))
+
+		_, err := db.InstanceSnapshots().Insert(internal.InstanceSnapshot{InstanceID: testID, OperationID: "op-1", OperationType: internal.OperationTypeProvision,
+			Parameters: internal.ProvisioningParameters{PlanID: broker.AWSPlanID, Parameters: pkg.ProvisioningParametersDTO{MachineType: ptr.String("m5.xlarge")}},
+			CreatedAt:  testTime.Add(-2 * time.Hour)})
+		require.NoError(t, err)
+		updatedParameters := testInstance.Parameters
+		updatedParameters.PlanID = broker.PreviewPlanID
+		_, err = db.InstanceSnapshots().Insert(internal.InstanceSnapshot{InstanceID: testID, OperationID: "op-2", OperationType: internal.OperationTypeUpdate,
+			Parameters: updatedParameters, CreatedAt: testTime.Add(-time.Hour)})
+		require.NoError(t, err)
+
+		runtimeHandler := runtime.NewHandler(db, 2, "", k8sClient, log)
+		router := httputil.NewRouter()
+		runtimeHandler.AttachRoutes(router)
+
+		for name, tc := range map[string]struct {
+			at                  time.Time
+			expectedMachineType string
+			expectedVersion     int
+			expectedPlanName    string
+		}{
+			"after provisioning": {at: testTime.Add(-90 * time.Minute), expectedMachineType: "m5.xlarge", expectedVersion: 1, expectedPlanName: broker.AWSPlanName},
+			"after update":       {at: testTime, expectedMachineType: "fake-machine-type", expectedVersion: 2, expectedPlanName: broker.PreviewPlanName},
+			"before snapshots":   {at: testTime.Add(-3 * time.Hour), expectedMachineType: "fake-machine-type", expectedVersion: 0, expectedPlanName: broker.PreviewPlanName},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// when
+				rr := httptest.NewRecorder()
+				req, err := http.NewRequest("GET", "/runtimes?at="+tc.at.Format(time.RFC3339), nil)
+				require.NoError(t, err)
+				router.ServeHTTP(rr, req)
+
+				// then
+				require.Equal(t, http.StatusOK, rr.Code)
+				var out pkg.RuntimesPage
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+				require.Len(t, out.Data, 1)
+				assert.Equal(t, tc.expectedMachineType, *out.Data[0].Parameters.MachineType)
+				assert.Equal(t, tc.expectedVersion, out.Data[0].ParametersVersion)
+				assert.Equal(t, tc.expectedPlanName, out.Data[0].ServicePlanName)
+			})
+		}
+
+		t.Run("invalid time", func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/runtimes?at=yesterday", nil)
+			require.NoError(t, err)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	})
 }
 
 func fixInstance(id string, t time.Time) internal.Instance {
@@ -0,0 +1,206 @@
+package runtimedrift
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+
+	gardener "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Drift describes a field of the Runtime resource which differs from the value KEB would set
+type Drift struct {
+	Field   string `json:"field"`
+	Desired any    `json:"desired"`
+	Actual  any    `json:"actual"`
+}
+
+// field is a part of the Runtime resource maintained by KEB, other fields are defaulted or changed by other components.
+// Only fields which KEB changes during the update are compared, so a detected drift can be corrected.
+type field struct {
+	name string
+	get  func(runtime *imv1.Runtime) any
+	set  func(desired, runtime *imv1.Runtime)
+}
+
+var fields = []field{
+	{
+		name: "metadata.labels[" + customresources.PlanIdLabel + "]",
+		get:  func(r *imv1.Runtime) any { return r.GetLabels()[customresources.PlanIdLabel] },
+		set: func(d, r *imv1.Runtime) {
+			setLabel(r, customresources.PlanIdLabel, d.GetLabels()[customresources.PlanIdLabel])
+		},
+	},
+	{
+		name: "metadata.labels[" + customresources.PlanNameLabel + "]",
+		get:  func(r *imv1.Runtime) any { return r.GetLabels()[customresources.PlanNameLabel] },
+		set: func(d, r *imv1.Runtime) {
+			setLabel(r, customresources.PlanNameLabel, d.GetLabels()[customresources.PlanNameLabel])
+		},
+	},
+	{
+		name: "spec.shoot.provider.workers[0].machine.type",
+		get:  func(r *imv1.Runtime) any { return kymaWorker(r).Machine.Type },
+		set:  func(d, r *imv1.Runtime) { kymaWorker(r).Machine.Type = kymaWorker(d).Machine.Type },
+	},
+	{
+		name: "spec.shoot.provider.workers[0].minimum",
+		get:  func(r *imv1.Runtime) any { return kymaWorker(r).Minimum },
+		set:  func(d, r *imv1.Runtime) { kymaWorker(r).Minimum = kymaWorker(d).Minimum },
+	},
+	{
+		name: "spec.shoot.provider.workers[0].maximum",
+		get:  func(r *imv1.Runtime) any { return kymaWorker(r).Maximum },
+		set:  func(d, r *imv1.Runtime) { kymaWorker(r).Maximum = kymaWorker(d).Maximum },
+	},
+	{
+		name: "spec.shoot.provider.workers[0].maxSurge",
+		get:  func(r *imv1.Runtime) any { return intValue(kymaWorker(r).MaxSurge) },
+		set:  func(d, r *imv1.Runtime) { kymaWorker(r).MaxSurge = kymaWorker(d).MaxSurge },
+	},
+	{
+		name: "spec.shoot.provider.workers[0].maxUnavailable",
+		get:  func(r *imv1.Runtime) any { return intValue(kymaWorker(r).MaxUnavailable) },
+		set:  func(d, r *imv1.Runtime) { kymaWorker(r).MaxUnavailable = kymaWorker(d).MaxUnavailable },
+	},
+	{
+		name: "spec.shoot.provider.additionalWorkers",
+		get:  func(r *imv1.Runtime) any { return additionalWorkersSummary(r) },
+		set:  setAdditionalWorkers,
+	},
+	{
+		name: "spec.shoot.kubernetes.kubeAPIServer.additionalOidcConfig",
+		get: func(r *imv1.Runtime) any {
+			if r.Spec.Shoot.Kubernetes.KubeAPIServer.AdditionalOidcConfig == nil {
+				return []imv1.OIDCConfig{}
+			}
+			return *r.Spec.Shoot.Kubernetes.KubeAPIServer.AdditionalOidcConfig
+		},
+		set: func(d, r *imv1.Runtime) {
+			r.Spec.Shoot.Kubernetes.KubeAPIServer.AdditionalOidcConfig = d.Spec.Shoot.Kubernetes.KubeAPIServer.AdditionalOidcConfig
+		},
+	},
+	{
+		name: "spec.security.administrators",
+		get:  func(r *imv1.Runtime) any { return r.Spec.Security.Administrators },
+		set:  func(d, r *imv1.Runtime) { r.Spec.Security.Administrators = d.Spec.Security.Administrators },
+	},
+	{
+		name: "spec.security.networking.filter.egress.enabled",
+		get:  func(r *imv1.Runtime) any { return r.Spec.Security.Networking.Filter.Egress.Enabled },
+		set: func(d, r *imv1.Runtime) {
+			r.Spec.Security.Networking.Filter.Egress.Enabled = d.Spec.Security.Networking.Filter.Egress.Enabled
+		},
+	},
+	{
+		name: "spec.security.networking.filter.ingress.enabled",
+		get: func(r *imv1.Runtime) any {
+			return r.Spec.Security.Networking.Filter.Ingress != nil && r.Spec.Security.Networking.Filter.Ingress.Enabled
+		},
+		set: func(d, r *imv1.Runtime) {
+			r.Spec.Security.Networking.Filter.Ingress = d.Spec.Security.Networking.Filter.Ingress
+		},
+	},
+}
+
+// compare returns fields of the current Runtime resource which differ from the desired one,
+// nil and empty slices and maps are treated as equal
+func compare(desired, current *imv1.Runtime) []Drift {
+	var drifts []Drift
+	for _, f := range fields {
+		desiredValue, actualValue := f.get(desired), f.get(current)
+		if !equality.Semantic.DeepEqual(desiredValue, actualValue) {
+			drifts = append(drifts, Drift{Field: f.name, Desired: desiredValue, Actual: actualValue})
+		}
+	}
+	return drifts
+}
+
+// apply sets all compared fields of the runtime to the desired values
+func apply(desired, runtime *imv1.Runtime) {
+	for _, f := range fields {
+		if !equality.Semantic.DeepEqual(f.get(desired), f.get(runtime)) {
+			f.set(desired, runtime)
+		}
+	}
+}
+
+func kymaWorker(runtime *imv1.Runtime) *gardener.Worker {
+	if len(runtime.Spec.Shoot.Provider.Workers) == 0 {
+		return &gardener.Worker{}
+	}
+	return &runtime.Spec.Shoot.Provider.Workers[0]
+}
+
+func intValue(value *intstr.IntOrString) int {
+	if value == nil {
+		return 0
+	}
+	return value.IntValue()
+}
+
+func setLabel(runtime *imv1.Runtime, key, value string) {
+	labels := runtime.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[key] = value
+	runtime.SetLabels(labels)
+}
+
+// additionalWorkerSummary contains fields of an additional worker node pool set from the provisioning parameters
+type additionalWorkerSummary struct {
+	Name        string `json:"name"`
+	MachineType string `json:"machineType"`
+	Minimum     int32  `json:"minimum"`
+	Maximum     int32  `json:"maximum"`
+	ZonesCount  int    `json:"zonesCount"`
+}
+
+func additionalWorkersSummary(runtime *imv1.Runtime) []additionalWorkerSummary {
+	summary := []additionalWorkerSummary{}
+	if runtime.Spec.Shoot.Provider.AdditionalWorkers == nil {
+		return summary
+	}
+	for _, worker := range *runtime.Spec.Shoot.Provider.AdditionalWorkers {
+		summary = append(summary, additionalWorkerSummary{
+			Name:        worker.Name,
+			MachineType: worker.Machine.Type,
+			Minimum:     worker.Minimum,
+			Maximum:     worker.Maximum,
+			ZonesCount:  len(worker.Zones),
+		})
+	}
+	return summary
+}
+
+// setAdditionalWorkers keeps fields of existing worker node pools which are not compared, for example the machine image
+func setAdditionalWorkers(desired, runtime *imv1.Runtime) {
+	current := make(map[string]gardener.Worker)
+	if runtime.Spec.Shoot.Provider.AdditionalWorkers != nil {
+		for _, worker := range *runtime.Spec.Shoot.Provider.AdditionalWorkers {
+			current[worker.Name] = worker
+		}
+	}
+
+	workers := make([]gardener.Worker, 0)
+	if desired.Spec.Shoot.Provider.AdditionalWorkers != nil {
+		for _, worker := range *desired.Spec.Shoot.Provider.AdditionalWorkers {
+			if existing, found := current[worker.Name]; found {
+				existing.Machine.Type = worker.Machine.Type
+				existing.Minimum = worker.Minimum
+				existing.Maximum = worker.Maximum
+				worker = existing
+			}
+			workers = append(workers, worker)
+		}
+	}
+	runtime.Spec.Shoot.Provider.AdditionalWorkers = &workers
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s (desired: %v, actual: %v)", d.Field, d.Desired, d.Actual)
+}
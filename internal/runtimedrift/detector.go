@@ -0,0 +1,157 @@
+package runtimedrift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNotComparable is returned when the Runtime resource of the instance is not expected to match the instance parameters,
+// for example when an operation is in progress or the instance was deprovisioned
+var ErrNotComparable = errors.New("runtime resource is not comparable")
+
+// RuntimeResourceBuilder builds the Runtime resource which KEB would create for the operation
+type RuntimeResourceBuilder interface {
+	RuntimeResource(operation internal.Operation, current *imv1.Runtime, log *slog.Logger) (*imv1.Runtime, error)
+}
+
+// Result is the outcome of the comparison of the Runtime resource with the one built from the instance parameters
+type Result struct {
+	Instance internal.Instance
+	Drifts   []Drift
+
+	// desired and current are kept to correct the drifts
+	desired *imv1.Runtime
+	current *imv1.Runtime
+}
+
+// Detector compares Runtime resources in KCP with the resources built from the instance parameters
+type Detector struct {
+	operations     storage.Operations
+	kcpClient      client.Client
+	builder        RuntimeResourceBuilder
+	valuesProvider broker.ValuesProvider
+}
+
+func NewDetector(operations storage.Operations, kcpClient client.Client, builder RuntimeResourceBuilder, valuesProvider broker.ValuesProvider) *Detector {
+	return &Detector{
+		operations:     operations,
+		kcpClient:      kcpClient,
+		builder:        builder,
+		valuesProvider: valuesProvider,
+	}
+}
+
+// instanceOperations keeps operations which decide if Runtime resources of instances can be compared, by instance IDs
+type instanceOperations struct {
+	notFinished map[string]internal.Operation
+	last        map[string]internal.Operation
+}
+
+// instanceOperations reads operations of all given instances at once
+func (d *Detector) instanceOperations(instanceIDs []string) (instanceOperations, error) {
+	notFinished, err := d.operations.ListNotFinishedOperations(instanceIDs)
+	if err != nil {
+		return instanceOperations{}, fmt.Errorf("while listing not finished operations: %w", err)
+	}
+	last, err := d.operations.GetLastOperations(instanceIDs)
+	if err != nil {
+		return instanceOperations{}, fmt.Errorf("while getting last operations: %w", err)
+	}
+	operations := instanceOperations{notFinished: make(map[string]internal.Operation), last: last}
+	for _, operation := range notFinished {
+		operations.notFinished[operation.InstanceID] = operation
+	}
+	return operations, nil
+}
+
+// Detect returns the fields of the Runtime resource of the instance which differ from the values KEB would set
+func (d *Detector) Detect(ctx context.Context, instance internal.Instance, log *slog.Logger) (Result, error) {
+	operations, err := d.instanceOperations([]string{instance.InstanceID})
+	if err != nil {
+		return Result{}, err
+	}
+	return d.detect(ctx, instance, operations, log)
+}
+
+func (d *Detector) detect(ctx context.Context, instance internal.Instance, operations instanceOperations, log *slog.Logger) (Result, error) {
+	if instance.RuntimeID == "" || broker.IsOwnClusterPlan(instance.ServicePlanID) {
+		return Result{}, fmt.Errorf("%w: the instance has no Runtime resource", ErrNotComparable)
+	}
+	// parameters of the instance are changed when the update is accepted, the Runtime resource when the update is processed
+	if operation, found := operations.notFinished[instance.InstanceID]; found {
+		return Result{}, fmt.Errorf("%w: the %s operation %s is in progress", ErrNotComparable, operation.Type, operation.ID)
+	}
+	lastOperation, found := operations.last[instance.InstanceID]
+	if !found {
+		return Result{}, fmt.Errorf("%w: the instance has no operations", ErrNotComparable)
+	}
+	if lastOperation.Type == internal.OperationTypeDeprovision {
+		return Result{}, fmt.Errorf("%w: the instance is deprovisioned or suspended", ErrNotComparable)
+	}
+
+	current := &imv1.Runtime{}
+	err := d.kcpClient.Get(ctx, client.ObjectKey{Name: instance.InstanceDetails.GetRuntimeResourceName(), Namespace: instance.InstanceDetails.GetRuntimeResourceNamespace()}, current)
+	if apierrors.IsNotFound(err) {
+		return Result{}, fmt.Errorf("%w: the Runtime resource does not exist", ErrNotComparable)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("while getting the Runtime resource: %w", err)
+	}
+
+	operation := internal.Operation{
+		InstanceID:             instance.InstanceID,
+		InstanceDetails:        instance.InstanceDetails,
+		ProvisioningParameters: instance.Parameters,
+	}
+	operation.RuntimeID = instance.RuntimeID
+	// values stored during the provisioning are used, so the drift is not reported for runtimes created with former defaults
+	if operation.ProviderValues == nil {
+		values, err := d.valuesProvider.ValuesForPlanAndParameters(instance.Parameters)
+		if err != nil {
+			return Result{}, fmt.Errorf("while calculating plan specific values: %w", err)
+		}
+		operation.ProviderValues = &values
+	}
+	if operation.ProvisioningParameters.Parameters.TargetSecret == nil {
+		operation.ProvisioningParameters.Parameters.TargetSecret = &current.Spec.Shoot.SecretBindingName
+	}
+
+	desired, err := d.builder.RuntimeResource(operation, current, log)
+	if err != nil {
+		return Result{}, fmt.Errorf("while building the Runtime resource: %w", err)
+	}
+	// the update does not set the default administrator for runtimes provisioned without the user ID
+	if operation.ProvisioningParameters.ErsContext.UserID == "" && len(operation.ProvisioningParameters.Parameters.RuntimeAdministrators) == 0 {
+		desired.Spec.Security.Administrators = []string{}
+	}
+
+	return Result{
+		Instance: instance,
+		Drifts:   compare(desired, current),
+		desired:  desired,
+		current:  current,
+	}, nil
+}
+
+// Correct sets the drifted fields of the Runtime resource to the values KEB would set
+func (d *Detector) Correct(ctx context.Context, result Result) error {
+	if len(result.Drifts) == 0 {
+		return nil
+	}
+	runtime := result.current.DeepCopy()
+	apply(result.desired, runtime)
+	if err := d.kcpClient.Update(ctx, runtime); err != nil {
+		return fmt.Errorf("while updating the Runtime resource: %w", err)
+	}
+	return nil
+}
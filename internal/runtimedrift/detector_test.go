@@ -0,0 +1,212 @@
+package runtimedrift
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+	"github.com/kyma-project/kyma-environment-broker/internal/customresources"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/process/provisioning"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/workers"
+
+	imv1 "github.com/kyma-project/infrastructure-manager/api/v1"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	instanceID  = "instance-id"
+	operationID = "operation-id"
+)
+
+func TestDetector_Detect(t *testing.T) {
+	t.Run("should not report drift for the Runtime resource created by KEB", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.Succeeded)
+		builder := fixBuilder(t)
+		kcpClient := fixClient(t, fixRuntimeResource(t, builder, instance))
+		detector := NewDetector(db.Operations(), kcpClient, builder, nil)
+
+		// when
+		result, err := detector.Detect(context.Background(), instance, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, result.Drifts)
+	})
+
+	t.Run("should report fields changed outside of KEB", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.Succeeded)
+		builder := fixBuilder(t)
+		runtime := fixRuntimeResource(t, builder, instance)
+		runtime.Spec.Shoot.Provider.Workers[0].Machine.Type = "m6i.2xlarge"
+		runtime.Spec.Shoot.Provider.Workers[0].Maximum = 40
+		runtime.Spec.Security.Administrators = []string{"intruder@test.com"}
+		// not maintained by KEB
+		runtime.Spec.Shoot.Kubernetes.Version = ptr.String("1.99")
+		kcpClient := fixClient(t, runtime)
+		detector := NewDetector(db.Operations(), kcpClient, builder, nil)
+
+		// when
+		result, err := detector.Detect(context.Background(), instance, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Drift{
+			{Field: "spec.shoot.provider.workers[0].machine.type", Desired: "m6i.large", Actual: "m6i.2xlarge"},
+			{Field: "spec.shoot.provider.workers[0].maximum", Desired: int32(20), Actual: int32(40)},
+			{Field: "spec.security.administrators", Desired: []string{"admin@test.com"}, Actual: []string{"intruder@test.com"}},
+		}, result.Drifts)
+	})
+
+	t.Run("should report the plan changed by the update", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.Succeeded)
+		builder := fixBuilder(t)
+		kcpClient := fixClient(t, fixRuntimeResource(t, builder, instance))
+		detector := NewDetector(db.Operations(), kcpClient, builder, nil)
+		instance.Parameters.PlanID = broker.BuildRuntimeAWSPlanID
+
+		// when
+		result, err := detector.Detect(context.Background(), instance, fixLogger())
+
+		// then
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Drift{
+			{Field: "metadata.labels[" + customresources.PlanIdLabel + "]", Desired: broker.BuildRuntimeAWSPlanID, Actual: broker.AWSPlanID},
+			{Field: "metadata.labels[" + customresources.PlanNameLabel + "]", Desired: broker.BuildRuntimeAWSPlanName, Actual: broker.AWSPlanName},
+		}, result.Drifts)
+	})
+
+	t.Run("should not compare when the update is not processed yet", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, internal.OperationStatePending)
+		builder := fixBuilder(t)
+		kcpClient := fixClient(t, fixRuntimeResource(t, builder, instance))
+		detector := NewDetector(db.Operations(), kcpClient, builder, nil)
+
+		// when
+		_, err := detector.Detect(context.Background(), instance, fixLogger())
+
+		// then
+		assert.ErrorIs(t, err, ErrNotComparable)
+	})
+
+	t.Run("should not compare when the Runtime resource does not exist", func(t *testing.T) {
+		// given
+		db, instance := fixStorage(t, domain.Succeeded)
+		detector := NewDetector(db.Operations(), fixClient(t), fixBuilder(t), nil)
+
+		// when
+		_, err := detector.Detect(context.Background(), instance, fixLogger())
+
+		// then
+		assert.ErrorIs(t, err, ErrNotComparable)
+	})
+}
+
+func TestDetector_Correct(t *testing.T) {
+	// given
+	db, instance := fixStorage(t, domain.Succeeded)
+	builder := fixBuilder(t)
+	runtime := fixRuntimeResource(t, builder, instance)
+	runtime.Spec.Shoot.Provider.Workers[0].Minimum = 1
+	runtime.Spec.Shoot.Provider.AdditionalWorkers = nil
+	runtime.Spec.Security.Networking.Filter.Egress.Enabled = false
+	runtime.Spec.Shoot.Kubernetes.Version = ptr.String("1.99")
+	kcpClient := fixClient(t, runtime)
+	detector := NewDetector(db.Operations(), kcpClient, builder, nil)
+
+	result, err := detector.Detect(context.Background(), instance, fixLogger())
+	require.NoError(t, err)
+	require.Len(t, result.Drifts, 3)
+
+	// when
+	err = detector.Correct(context.Background(), result)
+
+	// then
+	require.NoError(t, err)
+	corrected := &imv1.Runtime{}
+	require.NoError(t, kcpClient.Get(context.Background(), client.ObjectKeyFromObject(runtime), corrected))
+	assert.Equal(t, int32(3), corrected.Spec.Shoot.Provider.Workers[0].Minimum)
+	assert.True(t, corrected.Spec.Security.Networking.Filter.Egress.Enabled)
+	require.Len(t, *corrected.Spec.Shoot.Provider.AdditionalWorkers, 1)
+	assert.Equal(t, "worker-1", (*corrected.Spec.Shoot.Provider.AdditionalWorkers)[0].Name)
+	// fields not maintained by KEB are kept
+	assert.Equal(t, "1.99", *corrected.Spec.Shoot.Kubernetes.Version)
+
+	result, err = detector.Detect(context.Background(), instance, fixLogger())
+	require.NoError(t, err)
+	assert.Empty(t, result.Drifts)
+}
+
+func fixStorage(t *testing.T, lastOperationState domain.LastOperationState) (storage.BrokerStorage, internal.Instance) {
+	params := fixture.FixProvisioningParametersWithDTO(instanceID, broker.AWSPlanID, pkg.ProvisioningParametersDTO{
+		Name:                  "cluster-test",
+		Region:                ptr.String("eu-central-1"),
+		TargetSecret:          ptr.String("secret-binding"),
+		RuntimeAdministrators: []string{"admin@test.com"},
+		AdditionalWorkerNodePools: []pkg.AdditionalWorkerNodePool{
+			{Name: "worker-1", MachineType: "m6i.large", HAZones: true, AutoScalerMin: 3, AutoScalerMax: 20},
+		},
+	})
+	instance := fixture.FixInstanceWithProvisioningParameters(instanceID, params)
+	instance.ServicePlanID = broker.AWSPlanID
+	instance.InstanceDetails.ProviderValues = &internal.ProviderValues{
+		ProviderType:         "aws",
+		Region:               "eu-central-1",
+		Zones:                []string{"eu-central-1a", "eu-central-1b", "eu-central-1c"},
+		ZonesCount:           3,
+		DefaultMachineType:   "m6i.large",
+		DefaultAutoScalerMin: 3,
+		DefaultAutoScalerMax: 20,
+		VolumeSizeGb:         80,
+		DiskType:             "gp3",
+		Purpose:              "production",
+	}
+
+	operation := fixture.FixProvisioningOperationWithProvisioningParameters(operationID, instanceID, params)
+	operation.InstanceDetails = instance.InstanceDetails
+	operation.State = lastOperationState
+
+	db := storage.NewMemoryStorage()
+	require.NoError(t, db.Instances().Insert(instance))
+	require.NoError(t, db.Operations().InsertOperation(operation))
+	return db, instance
+}
+
+func fixBuilder(t *testing.T) *provisioning.CreateRuntimeResourceStep {
+	imConfig := broker.InfrastructureManager{MultiZoneCluster: true, DefaultGardenerShootPurpose: "production"}
+	providerSpec := fixture.NewProviderSpecWithZonesDiscovery(t, false)
+	oidc := pkg.OIDCConfigDTO{ClientID: "client-id", IssuerURL: "https://issuer.test", SigningAlgs: []string{"RS256"}}
+	return provisioning.NewCreateRuntimeResourceStep(storage.NewMemoryStorage(), nil, imConfig, oidc, workers.NewProvider(imConfig, providerSpec), providerSpec)
+}
+
+// fixRuntimeResource returns the Runtime resource as created during the provisioning
+func fixRuntimeResource(t *testing.T, builder *provisioning.CreateRuntimeResourceStep, instance internal.Instance) *imv1.Runtime {
+	operation := internal.Operation{InstanceID: instance.InstanceID, InstanceDetails: instance.InstanceDetails, ProvisioningParameters: instance.Parameters}
+	runtime, err := builder.RuntimeResource(operation, nil, fixLogger())
+	require.NoError(t, err)
+	return runtime
+}
+
+func fixClient(t *testing.T, objects ...client.Object) client.Client {
+	require.NoError(t, imv1.AddToScheme(scheme.Scheme))
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+}
+
+func fixLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
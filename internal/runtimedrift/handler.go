@@ -0,0 +1,87 @@
+package runtimedrift
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type DriftResponse struct {
+	InstanceID string  `json:"instanceID"`
+	RuntimeID  string  `json:"runtimeID"`
+	Drifted    bool    `json:"drifted"`
+	Drifts     []Drift `json:"drifts"`
+}
+
+// Handler compares the Runtime resource with the instance parameters on request, the result is not cached
+type Handler struct {
+	instances storage.Instances
+	detector  *Detector
+	log       *slog.Logger
+}
+
+func NewHandler(instances storage.Instances, detector *Detector, log *slog.Logger) *Handler {
+	return &Handler{
+		instances: instances,
+		detector:  detector,
+		log:       log.With("service", "RuntimeDriftEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	r.HandleFunc("GET /runtimes/{runtime_id}/drift", h.getDrift)
+}
+
+func (h *Handler) getDrift(w http.ResponseWriter, req *http.Request) {
+	runtimeID := req.PathValue("runtime_id")
+	log := h.log.With("runtimeID", runtimeID)
+
+	instances, err := h.instances.FindAllInstancesForRuntimes([]string{runtimeID})
+	switch {
+	case dberr.IsNotFound(err) || (err == nil && len(instances) == 0):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, fmt.Errorf("instance with runtime ID %s not found", runtimeID))
+		return
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to find the instance: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	// details of the instance are read together with the last operation
+	instance, err := h.instances.GetByID(instances[0].InstanceID)
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to get the instance: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := h.detector.Detect(req.Context(), *instance, log)
+	switch {
+	case errors.Is(err, ErrNotComparable):
+		httputil.WriteErrorResponse(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to compare the Runtime resource: %s", err))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := DriftResponse{
+		InstanceID: instance.InstanceID,
+		RuntimeID:  instance.RuntimeID,
+		Drifted:    len(result.Drifts) > 0,
+		Drifts:     result.Drifts,
+	}
+	if response.Drifts == nil {
+		response.Drifts = []Drift{}
+	}
+	httputil.WriteResponse(w, http.StatusOK, response)
+}
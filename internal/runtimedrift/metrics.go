@@ -0,0 +1,52 @@
+package runtimedrift
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/internal/broker"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	prometheusNamespace = "kcp"
+	prometheusSubsystem = "keb"
+)
+
+type Metrics struct {
+	driftedFields *prometheus.GaugeVec
+	corrections   prometheus.Counter
+}
+
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		driftedFields: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "runtime_drifted_fields",
+			Help:      "Number of fields of the Runtime resource which differ from the instance parameters, reported only for drifted runtimes",
+		}, []string{"instance_id", "runtime_id", "plan_name"}),
+		corrections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Subsystem: prometheusSubsystem,
+			Name:      "runtime_drift_corrections_total",
+			Help:      "Number of Runtime resources corrected by the runtime drift reconciler",
+		}),
+	}
+	registerer.MustRegister(m.driftedFields, m.corrections)
+	return m
+}
+
+// Update replaces drifts reported in the previous pass
+func (m *Metrics) Update(results []Result) {
+	m.driftedFields.Reset()
+	for _, result := range results {
+		if len(result.Drifts) == 0 {
+			continue
+		}
+		m.driftedFields.WithLabelValues(result.Instance.InstanceID, result.Instance.RuntimeID, broker.PlanNamesMapping[result.Instance.ServicePlanID]).
+			Set(float64(len(result.Drifts)))
+	}
+}
+
+func (m *Metrics) Corrected() {
+	m.corrections.Inc()
+}
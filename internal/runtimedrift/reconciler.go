@@ -0,0 +1,183 @@
+package runtimedrift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+const (
+	skipReconciliationLabel = "kyma-project.io/skip-reconciliation"
+	// reconcilePageSize is the number of instances read from the storage at once
+	reconcilePageSize = 100
+)
+
+type Config struct {
+	// Enables the periodic detection of Runtime resources which differ from the instance parameters, and the drift API
+	Enabled bool `envconfig:"default=false"`
+	// Only reports detected drifts when enabled, otherwise the drifted fields are set back to the values KEB would set
+	DryRun bool `envconfig:"default=true"`
+	// How often all Runtime resources are compared
+	Interval time.Duration `envconfig:"default=1h"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("(Enabled=%t; DryRun=%t; Interval=%s)", c.Enabled, c.DryRun, c.Interval)
+}
+
+// ReconcileStats summarizes one pass over all instances
+type ReconcileStats struct {
+	Compared  int
+	Drifted   int
+	Corrected int
+	Skipped   int
+	Failed    int
+}
+
+// Reconciler periodically compares Runtime resources of all instances with the resources built from the instance parameters.
+// Drifts are reported in metrics and logs, and corrected when the dry run is disabled.
+type Reconciler struct {
+	instances storage.Instances
+	detector  *Detector
+	metrics   *Metrics
+	cfg       Config
+	log       *slog.Logger
+}
+
+func NewReconciler(instances storage.Instances, detector *Detector, metrics *Metrics, cfg Config, log *slog.Logger) *Reconciler {
+	return &Reconciler{
+		instances: instances,
+		detector:  detector,
+		metrics:   metrics,
+		cfg:       cfg,
+		log:       log.With("component", "RuntimeDriftReconciler"),
+	}
+}
+
+// Run compares Runtime resources until the context is done
+func (r *Reconciler) Run(ctx context.Context) {
+	r.log.Info(fmt.Sprintf("starting the runtime drift reconciler (dry run: %t)", r.cfg.DryRun))
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := r.ReconcileAll(ctx)
+		if err != nil {
+			r.log.Error(fmt.Sprintf("unable to reconcile runtimes: %s", err))
+		} else {
+			r.log.Info(fmt.Sprintf("runtimes reconciled: compared %d, drifted %d, corrected %d, skipped %d, failed %d",
+				stats.Compared, stats.Drifted, stats.Corrected, stats.Skipped, stats.Failed))
+		}
+		select {
+		case <-ctx.Done():
+			r.log.Info("runtime drift reconciler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileAll compares Runtime resources of all instances with runtimes, instances are read page by page
+// together with their operations
+func (r *Reconciler) ReconcileAll(ctx context.Context) (ReconcileStats, error) {
+	stats := ReconcileStats{}
+	var results []Result
+	filter := dbmodel.InstanceFilter{PageSize: reconcilePageSize}
+	for {
+		instances, _, _, err := r.instances.List(filter)
+		if err != nil {
+			return stats, fmt.Errorf("while listing instances: %w", err)
+		}
+		if len(instances) == 0 {
+			break
+		}
+		pageResults, err := r.reconcilePage(ctx, instances, &stats)
+		if err != nil {
+			return stats, err
+		}
+		results = append(results, pageResults...)
+		if len(instances) < reconcilePageSize {
+			break
+		}
+		last := instances[len(instances)-1]
+		filter.After = &dbmodel.InstanceCursor{CreatedAt: last.CreatedAt, InstanceID: last.InstanceID}
+	}
+	r.metrics.Update(results)
+
+	return stats, nil
+}
+
+func (r *Reconciler) reconcilePage(ctx context.Context, instances []internal.Instance, stats *ReconcileStats) ([]Result, error) {
+	instanceIDs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		if instance.RuntimeID != "" {
+			instanceIDs = append(instanceIDs, instance.InstanceID)
+		}
+	}
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+	operations, err := r.detector.instanceOperations(instanceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, instance := range instances {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		if instance.RuntimeID == "" {
+			continue
+		}
+		log := r.log.With("instanceID", instance.InstanceID, "runtimeID", instance.RuntimeID)
+
+		result, err := r.detector.detect(ctx, instance, operations, log)
+		switch {
+		case errors.Is(err, ErrNotComparable):
+			log.Debug(fmt.Sprintf("skipping: %s", err))
+			stats.Skipped++
+			continue
+		case err != nil:
+			log.Error(fmt.Sprintf("unable to compare the Runtime resource: %s", err))
+			stats.Failed++
+			continue
+		}
+		stats.Compared++
+		results = append(results, result)
+		if len(result.Drifts) == 0 {
+			continue
+		}
+
+		stats.Drifted++
+		log.Warn(fmt.Sprintf("Runtime resource differs from the instance parameters: %v", result.Drifts))
+		if r.correct(ctx, result, log) {
+			stats.Corrected++
+		}
+	}
+	return results, nil
+}
+
+func (r *Reconciler) correct(ctx context.Context, result Result, log *slog.Logger) bool {
+	if result.current.GetLabels()[skipReconciliationLabel] == "true" {
+		log.Info(fmt.Sprintf("Runtime resource has the %s label, the drift is not corrected", skipReconciliationLabel))
+		return false
+	}
+	if r.cfg.DryRun {
+		log.Info(fmt.Sprintf("[dry-run] Runtime resource would be corrected: %d fields", len(result.Drifts)))
+		return false
+	}
+	if err := r.detector.Correct(ctx, result); err != nil {
+		log.Error(fmt.Sprintf("unable to correct the Runtime resource: %s", err))
+		return false
+	}
+	r.metrics.Corrected()
+	log.Info("Runtime resource corrected")
+	return true
+}
@@ -0,0 +1,45 @@
+package runtimedrift
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciler_ReconcileAll(t *testing.T) {
+	// given
+	db, instance := fixStorage(t, domain.Succeeded)
+	builder := fixBuilder(t)
+	kcpClient := fixClient(t, fixRuntimeResource(t, builder, instance))
+	detector := NewDetector(db.Operations(), kcpClient, builder, nil)
+
+	// more instances than fit on one page, their Runtime resources do not exist
+	createdAt := time.Now().Add(-time.Hour)
+	for i := 0; i < reconcilePageSize+10; i++ {
+		other := fixture.FixInstance(fmt.Sprintf("instance-%03d", i))
+		other.CreatedAt = createdAt.Add(time.Duration(i) * time.Second)
+		require.NoError(t, db.Instances().Insert(other))
+		require.NoError(t, db.Operations().InsertOperation(fixture.FixProvisioningOperation(fmt.Sprintf("operation-%03d", i), other.InstanceID)))
+	}
+	updating := fixture.FixOperation("update-in-progress", "instance-000", internal.OperationTypeUpdate)
+	updating.State = domain.InProgress
+	require.NoError(t, db.Operations().InsertOperation(updating))
+
+	reconciler := NewReconciler(db.Instances(), detector, NewMetrics(prometheus.NewRegistry()), Config{DryRun: true}, fixLogger())
+
+	// when
+	stats, err := reconciler.ReconcileAll(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileStats{Compared: 1, Skipped: reconcilePageSize + 10}, stats)
+}
@@ -19,6 +19,9 @@ type Config struct {
 	SSLRootCert string `envconfig:"optional"`
 
 	SecretKey string `envconfig:"optional"`
+	// EncryptionKeys is the keyring in the format id1=key1,id2=key2, keys other than the active one are used only for decryption
+	EncryptionKeys string `envconfig:"optional"`
+	ActiveKeyID    string `envconfig:"optional"`
 
 	MaxOpenConns    int           `envconfig:"default=8"`
 	MaxIdleConns    int           `envconfig:"default=2"`
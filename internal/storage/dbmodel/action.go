@@ -0,0 +1,13 @@
+package dbmodel
+
+import "time"
+
+type ActionFilter struct {
+	PageSize      int
+	Page          int
+	InstanceIDs   []string
+	Types         []string
+	Actors        []string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
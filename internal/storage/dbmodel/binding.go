@@ -14,6 +14,13 @@ type BindingDTO struct {
 	Kubeconfig        string
 	ExpirationSeconds int64
 	CreatedBy         string
+	Role              string
+	// Namespaces is a comma separated list of namespaces for namespaced roles
+	Namespaces string
+
+	State       string
+	Stage       string
+	Description string
 }
 
 type BindingStatsDTO struct {
@@ -0,0 +1,28 @@
+package dbmodel
+
+import "time"
+
+type CampaignDTO struct {
+	ID        string
+	State     string
+	Data      string
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type CampaignFilter struct {
+	PageSize int
+	Page     int
+	State    string
+}
+
+type CampaignInstanceDTO struct {
+	CampaignID  string
+	InstanceID  string
+	Wave        int
+	State       string
+	OperationID string
+	Message     string
+	UpdatedAt   time.Time
+}
@@ -16,6 +16,8 @@ const (
 	InstanceUpgrading        InstanceState = "upgrading"
 	InstanceUpdating         InstanceState = "updating"
 	InstanceDeprovisioned    InstanceState = "deprovisioned"
+	InstanceCanceling        InstanceState = "canceling"
+	InstanceCanceled         InstanceState = "canceled"
 	InstanceNotDeprovisioned InstanceState = "notDeprovisioned"
 )
 
@@ -37,6 +39,50 @@ type InstanceFilter struct {
 	DeletionAttempted            *bool
 	BindingExists                *bool
 	Suspended                    *bool
+	Providers                    []string
+	LicenseTypes                 []string
+	CommercialModels             []string
+	MachineTypes                 []string
+	KubernetesVersions           []string
+	AdditionalWorkerNodePools    *bool
+	LastErrorComponents          []string
+	LastErrorReasons             []string
+	CreatedAfter                 time.Time
+	CreatedBefore                time.Time
+	UpdatedAfter                 time.Time
+	UpdatedBefore                time.Time
+	// LabelSelector matches instances with labels meeting all requirements
+	LabelSelector []LabelRequirement
+	// Sort orders instances by the given fields, instances with equal fields are ordered by the creation time and ID
+	Sort []InstanceSort
+	// After selects the keyset pagination, only instances created after the cursor are returned and Page is ignored
+	After *InstanceCursor
+}
+
+// InstanceCursor points to the instance by its creation time and ID, instances are ordered by both columns
+type InstanceCursor struct {
+	CreatedAt  time.Time
+	InstanceID string
+}
+
+type InstanceSortField string
+
+// Values of InstanceSortField are names of columns of the instances table
+const (
+	InstanceSortCreatedAt       InstanceSortField = "created_at"
+	InstanceSortUpdatedAt       InstanceSortField = "updated_at"
+	InstanceSortInstanceID      InstanceSortField = "instance_id"
+	InstanceSortRuntimeID       InstanceSortField = "runtime_id"
+	InstanceSortGlobalAccountID InstanceSortField = "global_account_id"
+	InstanceSortSubAccountID    InstanceSortField = "sub_account_id"
+	InstanceSortRegion          InstanceSortField = "provider_region"
+	InstanceSortPlan            InstanceSortField = "service_plan_name"
+	InstanceSortProvider        InstanceSortField = "provider"
+)
+
+type InstanceSort struct {
+	Field      InstanceSortField
+	Descending bool
 }
 
 type InstanceDTO struct {
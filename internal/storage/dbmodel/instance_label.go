@@ -0,0 +1,40 @@
+package dbmodel
+
+import "slices"
+
+type InstanceLabelDTO struct {
+	InstanceID string
+	Key        string
+	Value      string
+}
+
+type LabelOperator string
+
+const (
+	LabelOperatorIn           LabelOperator = "in"
+	LabelOperatorNotIn        LabelOperator = "notin"
+	LabelOperatorExists       LabelOperator = "exists"
+	LabelOperatorDoesNotExist LabelOperator = "!"
+)
+
+// LabelRequirement is one requirement of a label selector, the notin and ! operators match instances without the label
+type LabelRequirement struct {
+	Key      string
+	Operator LabelOperator
+	Values   []string
+}
+
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	value, found := labels[r.Key]
+	switch r.Operator {
+	case LabelOperatorIn:
+		return found && slices.Contains(r.Values, value)
+	case LabelOperatorNotIn:
+		return !found || !slices.Contains(r.Values, value)
+	case LabelOperatorExists:
+		return found
+	case LabelOperatorDoesNotExist:
+		return !found
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package dbmodel
+
+import "time"
+
+type InstanceSnapshotDTO struct {
+	InstanceID    string
+	Version       int
+	OperationID   string
+	OperationType string
+	RuntimeID     string
+	Parameters    string
+	CreatedAt     time.Time
+}
@@ -0,0 +1,14 @@
+package dbmodel
+
+import "time"
+
+type OutboxEventDTO struct {
+	ID             int64
+	InstanceID     string
+	OperationID    string
+	Type           string
+	Payload        string
+	Attempts       int
+	DeliveredSinks string
+	CreatedAt      time.Time
+}
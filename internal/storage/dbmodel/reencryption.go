@@ -0,0 +1,23 @@
+package dbmodel
+
+import "database/sql"
+
+// EncryptedValueDTO is the encrypted column of a row identified by one or two key columns
+type EncryptedValueDTO struct {
+	Key1  string
+	Key2  string
+	Value sql.NullString
+}
+
+// ReencryptionBatch summarizes one batch of rows encrypted again with the active key
+type ReencryptionBatch struct {
+	// Last is the key of the last scanned row, the next batch starts after it
+	Last []string
+	// Scanned is the number of read rows, a batch smaller than the limit is the last one of the table
+	Scanned     int
+	Reencrypted int
+	// Conflicts counts rows changed concurrently, they are written with the active key by the change
+	Conflicts int
+	// Errors contains rows which could not be decrypted, for example, because their key is not configured
+	Errors []error
+}
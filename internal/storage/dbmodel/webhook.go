@@ -0,0 +1,40 @@
+package dbmodel
+
+import "time"
+
+type WebhookSubscriptionDTO struct {
+	ID              string
+	GlobalAccountID string
+	SubAccountID    string
+	URL             string
+	Secret          string
+	EventTypes      string
+	CreatedAt       time.Time
+}
+
+type WebhookDeliveryDTO struct {
+	ID             int64
+	SubscriptionID string
+	EventID        int64
+	EventType      string
+	Payload        string
+	State          string
+	Attempts       int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type WebhookSubscriptionFilter struct {
+	PageSize        int
+	Page            int
+	GlobalAccountID string
+	SubAccountID    string
+}
+
+type WebhookDeliveryFilter struct {
+	PageSize       int
+	Page           int
+	SubscriptionID string
+	State          string
+}
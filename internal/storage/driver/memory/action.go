@@ -1,15 +1,19 @@
 package memory
 
 import (
+	"slices"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 
 	"github.com/google/uuid"
 )
 
 type Action struct {
+	mu      sync.Mutex
 	actions []runtime.Action
 }
 
@@ -19,28 +23,60 @@ func NewAction() *Action {
 	}
 }
 
-func (a *Action) InsertAction(actionType runtime.ActionType, instanceID, message, oldValue, newValue string) error {
-	a.actions = append(a.actions, runtime.Action{
-		ID:         uuid.NewString(),
-		Type:       actionType,
-		InstanceID: instanceID,
-		Message:    message,
-		OldValue:   oldValue,
-		NewValue:   newValue,
-		CreatedAt:  time.Now(),
-	})
+func (a *Action) InsertAction(action runtime.Action) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if action.ID == "" {
+		action.ID = uuid.NewString()
+	}
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now()
+	}
+	a.actions = append(a.actions, action)
 	return nil
 }
 
 func (a *Action) ListActionsByInstanceID(instanceID string) ([]runtime.Action, error) {
+	actions, _, err := a.ListActions(dbmodel.ActionFilter{InstanceIDs: []string{instanceID}})
+	return actions, err
+}
+
+func (a *Action) ListActions(filter dbmodel.ActionFilter) ([]runtime.Action, int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// the latest actions go first, also when they are recorded at the same time
 	filtered := make([]runtime.Action, 0)
-	for _, action := range a.actions {
-		if action.InstanceID == instanceID {
+	for _, action := range slices.Backward(a.actions) {
+		if matchesActionFilter(action, filter) {
 			filtered = append(filtered, action)
 		}
 	}
-	sort.Slice(filtered, func(i, j int) bool {
+	sort.SliceStable(filtered, func(i, j int) bool {
 		return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
 	})
-	return filtered, nil
+
+	totalCount := len(filtered)
+	if filter.Page > 0 && filter.PageSize > 0 {
+		offset := min((filter.Page-1)*filter.PageSize, totalCount)
+		filtered = filtered[offset:min(offset+filter.PageSize, totalCount)]
+	}
+	return filtered, totalCount, nil
+}
+
+func matchesActionFilter(action runtime.Action, filter dbmodel.ActionFilter) bool {
+	switch {
+	case len(filter.InstanceIDs) > 0 && !slices.Contains(filter.InstanceIDs, action.InstanceID):
+		return false
+	case len(filter.Types) > 0 && !slices.Contains(filter.Types, string(action.Type)):
+		return false
+	case len(filter.Actors) > 0 && !slices.Contains(filter.Actors, action.Actor):
+		return false
+	case !filter.CreatedAfter.IsZero() && action.CreatedAt.Before(filter.CreatedAfter):
+		return false
+	case !filter.CreatedBefore.IsZero() && !action.CreatedAt.Before(filter.CreatedBefore):
+		return false
+	}
+	return true
 }
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActions(t *testing.T) {
+	// given
+	actions := NewAction()
+	now := time.Now()
+	require.NoError(t, actions.InsertAction(runtime.Action{Type: runtime.PlanUpdateActionType, InstanceID: "i-1", Actor: "admin@example.com", CreatedAt: now.Add(-2 * time.Hour)}))
+	require.NoError(t, actions.InsertAction(runtime.Action{Type: runtime.ExpirationActionType, InstanceID: "i-1", Actor: "expirator-job", CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, actions.InsertAction(runtime.Action{Type: runtime.SuspensionActionType, InstanceID: "i-2", Actor: "expirator-job", CreatedAt: now}))
+
+	t.Run("should list the latest actions first", func(t *testing.T) {
+		// when
+		list, totalCount, err := actions.ListActions(dbmodel.ActionFilter{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, totalCount)
+		require.Len(t, list, 3)
+		assert.Equal(t, runtime.SuspensionActionType, list[0].Type)
+		assert.Equal(t, runtime.PlanUpdateActionType, list[2].Type)
+		assert.NotEmpty(t, list[0].ID)
+	})
+
+	t.Run("should filter actions", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			filter   dbmodel.ActionFilter
+			expected []runtime.ActionType
+		}{
+			"by instance": {
+				filter:   dbmodel.ActionFilter{InstanceIDs: []string{"i-1"}},
+				expected: []runtime.ActionType{runtime.ExpirationActionType, runtime.PlanUpdateActionType},
+			},
+			"by type": {
+				filter:   dbmodel.ActionFilter{Types: []string{string(runtime.SuspensionActionType), string(runtime.PlanUpdateActionType)}},
+				expected: []runtime.ActionType{runtime.SuspensionActionType, runtime.PlanUpdateActionType},
+			},
+			"by actor": {
+				filter:   dbmodel.ActionFilter{Actors: []string{"expirator-job"}},
+				expected: []runtime.ActionType{runtime.SuspensionActionType, runtime.ExpirationActionType},
+			},
+			"by creation time": {
+				filter:   dbmodel.ActionFilter{CreatedAfter: now.Add(-90 * time.Minute), CreatedBefore: now},
+				expected: []runtime.ActionType{runtime.ExpirationActionType},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// when
+				list, totalCount, err := actions.ListActions(tc.filter)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, len(tc.expected), totalCount)
+				types := make([]runtime.ActionType, 0, len(list))
+				for _, action := range list {
+					types = append(types, action.Type)
+				}
+				assert.Equal(t, tc.expected, types)
+			})
+		}
+	})
+
+	t.Run("should return the requested page", func(t *testing.T) {
+		// when
+		list, totalCount, err := actions.ListActions(dbmodel.ActionFilter{Page: 2, PageSize: 2})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, totalCount)
+		require.Len(t, list, 1)
+		assert.Equal(t, runtime.PlanUpdateActionType, list[0].Type)
+	})
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+type campaignItem struct {
+	campaign       internal.Campaign
+	instances      []internal.CampaignInstance
+	owner          string
+	leaseExpiresAt time.Time
+}
+
+type Campaigns struct {
+	mu        sync.Mutex
+	campaigns map[string]*campaignItem
+}
+
+func NewCampaigns() *Campaigns {
+	return &Campaigns{
+		campaigns: make(map[string]*campaignItem),
+	}
+}
+
+func (c *Campaigns) Insert(campaign internal.Campaign, instances []internal.CampaignInstance) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.campaigns[campaign.ID]; found {
+		return dberr.AlreadyExists("campaign with id %s already exists", campaign.ID)
+	}
+	stored := make([]internal.CampaignInstance, len(instances))
+	copy(stored, instances)
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].Wave < stored[j].Wave
+	})
+	c.campaigns[campaign.ID] = &campaignItem{campaign: campaign, instances: stored}
+	return nil
+}
+
+func (c *Campaigns) Get(id string) (*internal.Campaign, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.campaigns[id]
+	if !found {
+		return nil, dberr.NotFound("Cannot find the campaign with id:'%s'", id)
+	}
+	campaign := item.campaign
+	return &campaign, nil
+}
+
+func (c *Campaigns) List(filter dbmodel.CampaignFilter) ([]internal.Campaign, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]internal.Campaign, 0, len(c.campaigns))
+	for _, item := range c.campaigns {
+		if filter.State != "" && string(item.campaign.State) != filter.State {
+			continue
+		}
+		result = append(result, item.campaign)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	page, totalCount := paginate(result, filter.Page, filter.PageSize)
+	return page, totalCount, nil
+}
+
+func (c *Campaigns) Update(campaign internal.Campaign) (*internal.Campaign, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.campaigns[campaign.ID]
+	if !found {
+		return nil, dberr.NotFound("Cannot find the campaign with id:'%s'", campaign.ID)
+	}
+	if item.campaign.Version != campaign.Version {
+		return nil, dberr.Conflict("unable to update campaign %s - conflict", campaign.ID)
+	}
+	campaign.UpdatedAt = time.Now()
+	campaign.Version++
+	item.campaign = campaign
+	return &campaign, nil
+}
+
+func (c *Campaigns) Claim(owner string, leaseDuration time.Duration) ([]internal.Campaign, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	claimed := make([]internal.Campaign, 0)
+	for _, item := range c.campaigns {
+		if item.campaign.State != campaigns.StatePending && item.campaign.State != campaigns.StateInProgress {
+			continue
+		}
+		if item.owner != "" && item.owner != owner && item.leaseExpiresAt.After(now) {
+			continue
+		}
+		item.owner = owner
+		item.leaseExpiresAt = now.Add(leaseDuration)
+		claimed = append(claimed, item.campaign)
+	}
+	sort.Slice(claimed, func(i, j int) bool {
+		return claimed[i].CreatedAt.Before(claimed[j].CreatedAt)
+	})
+	return claimed, nil
+}
+
+func (c *Campaigns) ListInstances(campaignID string, state campaigns.InstanceState) ([]internal.CampaignInstance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.campaigns[campaignID]
+	if !found {
+		return nil, dberr.NotFound("Cannot find the campaign with id:'%s'", campaignID)
+	}
+	instances := make([]internal.CampaignInstance, 0, len(item.instances))
+	for _, instance := range item.instances {
+		if state != "" && instance.State != state {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (c *Campaigns) UpdateInstance(instance internal.CampaignInstance) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.campaigns[instance.CampaignID]
+	if !found {
+		return dberr.NotFound("Cannot find the campaign with id:'%s'", instance.CampaignID)
+	}
+	for i := range item.instances {
+		if item.instances[i].InstanceID == instance.InstanceID {
+			item.instances[i] = instance
+			return nil
+		}
+	}
+	return dberr.NotFound("instance %s is not updated by campaign %s", instance.InstanceID, instance.CampaignID)
+}
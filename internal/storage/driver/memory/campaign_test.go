@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaigns(t *testing.T) {
+	t.Run("should claim only active campaigns not leased by another owner", func(t *testing.T) {
+		// given
+		storage := NewCampaigns()
+		require.NoError(t, storage.Insert(internal.Campaign{ID: "c-1", State: campaigns.StatePending}, nil))
+		require.NoError(t, storage.Insert(internal.Campaign{ID: "c-2", State: campaigns.StatePaused}, nil))
+
+		// when
+		claimed, err := storage.Claim("owner-1", time.Minute)
+		require.NoError(t, err)
+		claimedByOther, err := storage.Claim("owner-2", time.Minute)
+		require.NoError(t, err)
+		claimedAgain, err := storage.Claim("owner-1", time.Minute)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, claimed, 1)
+		assert.Equal(t, "c-1", claimed[0].ID)
+		assert.Empty(t, claimedByOther)
+		assert.Len(t, claimedAgain, 1)
+	})
+
+	t.Run("should reject the update of a changed campaign", func(t *testing.T) {
+		// given
+		storage := NewCampaigns()
+		require.NoError(t, storage.Insert(internal.Campaign{ID: "c-1", State: campaigns.StatePending}, nil))
+		campaign, err := storage.Get("c-1")
+		require.NoError(t, err)
+
+		// when
+		campaign.State = campaigns.StateInProgress
+		updated, err := storage.Update(*campaign)
+		require.NoError(t, err)
+		_, conflictErr := storage.Update(*campaign)
+
+		// then
+		assert.Equal(t, 1, updated.Version)
+		assert.True(t, dberr.IsConflict(conflictErr))
+	})
+
+	t.Run("should list instances in the wave order", func(t *testing.T) {
+		// given
+		storage := NewCampaigns()
+		require.NoError(t, storage.Insert(internal.Campaign{ID: "c-1"}, []internal.CampaignInstance{
+			{CampaignID: "c-1", InstanceID: "i-2", Wave: 1, State: campaigns.InstancePending},
+			{CampaignID: "c-1", InstanceID: "i-1", Wave: 0, State: campaigns.InstancePending},
+		}))
+
+		// when
+		require.NoError(t, storage.UpdateInstance(internal.CampaignInstance{CampaignID: "c-1", InstanceID: "i-1", State: campaigns.InstanceInProgress, OperationID: "op-1"}))
+		all, err := storage.ListInstances("c-1", "")
+		require.NoError(t, err)
+		pending, err := storage.ListInstances("c-1", campaigns.InstancePending)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, all, 2)
+		assert.Equal(t, "i-1", all[0].InstanceID)
+		assert.Equal(t, "op-1", all[0].OperationID)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "i-2", pending[0].InstanceID)
+		assert.True(t, dberr.IsNotFound(storage.UpdateInstance(internal.CampaignInstance{CampaignID: "c-1", InstanceID: "i-3"})))
+	})
+}
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/pagination"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/ptr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/predicate"
@@ -20,6 +23,7 @@ type instances struct {
 	instances               map[string]internal.Instance
 	operationsStorage       *operations
 	subaccountStatesStorage *SubaccountStates
+	labels                  *InstanceLabels
 }
 
 func NewInstance(operations *operations, subaccountStates *SubaccountStates) *instances {
@@ -27,9 +31,15 @@ func NewInstance(operations *operations, subaccountStates *SubaccountStates) *in
 		instances:               make(map[string]internal.Instance, 0),
 		operationsStorage:       operations,
 		subaccountStatesStorage: subaccountStates,
+		labels:                  NewInstanceLabels(),
 	}
 }
 
+// Labels returns labels of instances, labels are removed when the instance is deleted
+func (s *instances) Labels() *InstanceLabels {
+	return s.labels
+}
+
 func (s *instances) GetDistinctSubAccounts() ([]string, error) {
 	//iterate over instances and return distinct subaccounts
 	collectedSubAccounts := make(map[string]struct{})
@@ -177,6 +187,7 @@ func (s *instances) Delete(instanceID string) error {
 	defer s.mu.Unlock()
 
 	delete(s.instances, instanceID)
+	s.labels.delete(instanceID)
 	return nil
 }
 
@@ -217,13 +228,11 @@ func (s *instances) List(filter dbmodel.InstanceFilter) ([]internal.Instance, in
 	defer s.mu.Unlock()
 	var toReturn []internal.Instance
 
-	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
-
 	instances := s.filterInstances(filter)
-	sortInstancesByCreatedAt(instances)
+	sortInstances(instances, filter.Sort)
 
-	for i := offset; (filter.PageSize < 1 || i < offset+filter.PageSize) && i < len(instances); i++ {
-		toReturn = append(toReturn, s.instances[instances[i].InstanceID])
+	for _, instance := range pageOfInstances(instances, filter) {
+		toReturn = append(toReturn, s.instances[instance.InstanceID])
 	}
 
 	return toReturn,
@@ -237,15 +246,11 @@ func (s *instances) ListWithSubaccountState(filter dbmodel.InstanceFilter) ([]in
 	defer s.mu.Unlock()
 	var toReturn []internal.InstanceWithSubaccountState
 
-	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
-
 	instances := s.filterInstances(filter)
-	sort.Slice(instances, func(i, j int) bool {
-		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
-	})
+	sortInstances(instances, filter.Sort)
 
-	for i := offset; (filter.PageSize < 1 || i < offset+filter.PageSize) && i < len(instances); i++ {
-		instanceToReturn := s.instances[instances[i].InstanceID]
+	for _, instance := range pageOfInstances(instances, filter) {
+		instanceToReturn := s.instances[instance.InstanceID]
 		instanceWithSubaccountState := internal.InstanceWithSubaccountState{
 			Instance: instanceToReturn,
 		}
@@ -264,10 +269,79 @@ func (s *instances) ListWithSubaccountState(filter dbmodel.InstanceFilter) ([]in
 
 func sortInstancesByCreatedAt(instances []internal.Instance) {
 	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].CreatedAt.Equal(instances[j].CreatedAt) {
+			return instances[i].InstanceID < instances[j].InstanceID
+		}
 		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
 	})
 }
 
+// sortInstances orders instances by the given fields, instances with equal fields stay ordered by the creation time and ID
+func sortInstances(instances []internal.Instance, order []dbmodel.InstanceSort) {
+	sortInstancesByCreatedAt(instances)
+	if len(order) == 0 {
+		return
+	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		for _, o := range order {
+			c := compareInstances(instances[i], instances[j], o.Field)
+			if c == 0 {
+				continue
+			}
+			if o.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compareInstances(a, b internal.Instance, field dbmodel.InstanceSortField) int {
+	switch field {
+	case dbmodel.InstanceSortCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case dbmodel.InstanceSortUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case dbmodel.InstanceSortInstanceID:
+		return strings.Compare(a.InstanceID, b.InstanceID)
+	case dbmodel.InstanceSortRuntimeID:
+		return strings.Compare(a.RuntimeID, b.RuntimeID)
+	case dbmodel.InstanceSortGlobalAccountID:
+		return strings.Compare(a.GlobalAccountID, b.GlobalAccountID)
+	case dbmodel.InstanceSortSubAccountID:
+		return strings.Compare(a.SubAccountID, b.SubAccountID)
+	case dbmodel.InstanceSortRegion:
+		return strings.Compare(a.ProviderRegion, b.ProviderRegion)
+	case dbmodel.InstanceSortPlan:
+		return strings.Compare(a.ServicePlanName, b.ServicePlanName)
+	case dbmodel.InstanceSortProvider:
+		return strings.Compare(string(a.Provider), string(b.Provider))
+	}
+	return 0
+}
+
+// pageOfInstances returns the page of sorted instances selected by the cursor or the page number
+func pageOfInstances(instances []internal.Instance, filter dbmodel.InstanceFilter) []internal.Instance {
+	offset := pagination.ConvertPageAndPageSizeToOffset(filter.PageSize, filter.Page)
+	if filter.After != nil {
+		offset = sort.Search(len(instances), func(i int) bool {
+			if instances[i].CreatedAt.Equal(filter.After.CreatedAt) {
+				return instances[i].InstanceID > filter.After.InstanceID
+			}
+			return instances[i].CreatedAt.After(filter.After.CreatedAt)
+		})
+	}
+	if offset > len(instances) {
+		return nil
+	}
+	end := len(instances)
+	if filter.PageSize > 0 && offset+filter.PageSize < end {
+		end = offset + filter.PageSize
+	}
+	return instances[offset:end]
+}
+
 func (s *instances) filterInstances(filter dbmodel.InstanceFilter) []internal.Instance {
 	inst := make([]internal.Instance, 0, len(s.instances))
 	var ok bool
@@ -311,6 +385,36 @@ func (s *instances) filterInstances(filter dbmodel.InstanceFilter) []internal.In
 		if ok = s.matchInstanceState(v.InstanceID, filter.States); !ok {
 			continue
 		}
+		if ok = matchFilter(string(v.Provider), filter.Providers, equal); !ok {
+			continue
+		}
+		if ok = matchFilter(ptr.ToString(v.Parameters.ErsContext.LicenseType), filter.LicenseTypes, equal); !ok {
+			continue
+		}
+		if ok = matchFilter(ptr.ToString(v.Parameters.ErsContext.CommercialModel), filter.CommercialModels, equal); !ok {
+			continue
+		}
+		if ok = matchFilter(machineType(v), filter.MachineTypes, equal); !ok {
+			continue
+		}
+		if ok = matchFilter(v.InstanceDetails.KubernetesVersion, filter.KubernetesVersions, equal); !ok {
+			continue
+		}
+		if filter.AdditionalWorkerNodePools != nil && *filter.AdditionalWorkerNodePools != (len(v.Parameters.Parameters.AdditionalWorkerNodePools) > 0) {
+			continue
+		}
+		if ok = s.matchLastError(v.InstanceID, filter); !ok {
+			continue
+		}
+		if ok = matchTimeRange(v.CreatedAt, filter.CreatedAfter, filter.CreatedBefore); !ok {
+			continue
+		}
+		if ok = matchTimeRange(v.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore); !ok {
+			continue
+		}
+		if ok = s.matchLabelSelector(v.InstanceID, filter.LabelSelector); !ok {
+			continue
+		}
 
 		inst = append(inst, v)
 	}
@@ -318,6 +422,19 @@ func (s *instances) filterInstances(filter dbmodel.InstanceFilter) []internal.In
 	return inst
 }
 
+func (s *instances) matchLabelSelector(instanceID string, selector []dbmodel.LabelRequirement) bool {
+	if len(selector) == 0 {
+		return true
+	}
+	labels, _ := s.labels.Get(instanceID)
+	for _, requirement := range selector {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
 func matchFilter(value string, filters []string, match func(string, string) bool) bool {
 	if len(filters) == 0 {
 		return true
@@ -330,6 +447,42 @@ func matchFilter(value string, filters []string, match func(string, string) bool
 	return false
 }
 
+// machineType returns the machine type from parameters or the default machine type of the plan
+func machineType(instance internal.Instance) string {
+	if instance.Parameters.Parameters.MachineType != nil {
+		return *instance.Parameters.Parameters.MachineType
+	}
+	if instance.InstanceDetails.ProviderValues != nil {
+		return instance.InstanceDetails.ProviderValues.DefaultMachineType
+	}
+	return ""
+}
+
+func matchTimeRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
+
+func (s *instances) matchLastError(instanceID string, filter dbmodel.InstanceFilter) bool {
+	if len(filter.LastErrorComponents) == 0 && len(filter.LastErrorReasons) == 0 {
+		return true
+	}
+	op, err := s.operationsStorage.GetLastOperation(instanceID)
+	if err != nil {
+		return false
+	}
+	equal := func(a, b string) bool {
+		return a == b
+	}
+	return matchFilter(string(op.LastError.Component), filter.LastErrorComponents, equal) &&
+		matchFilter(string(op.LastError.Reason), filter.LastErrorReasons, equal)
+}
+
 func (s *instances) matchInstanceState(instanceID string, states []dbmodel.InstanceState) bool {
 	if len(states) == 0 {
 		return true
@@ -378,6 +531,14 @@ func (s *instances) matchInstanceState(instanceID string, states []dbmodel.Insta
 			if !(op.State == domain.Succeeded && op.Type == internal.OperationTypeDeprovision) {
 				return true
 			}
+		case dbmodel.InstanceCanceling:
+			if op.State == internal.OperationStateCanceling {
+				return true
+			}
+		case dbmodel.InstanceCanceled:
+			if op.State == internal.OperationStateCanceled {
+				return true
+			}
 		}
 	}
 
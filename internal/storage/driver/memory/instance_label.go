@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"maps"
+	"sync"
+)
+
+type InstanceLabels struct {
+	mu     sync.Mutex
+	labels map[string]map[string]string
+}
+
+func NewInstanceLabels() *InstanceLabels {
+	return &InstanceLabels{
+		labels: make(map[string]map[string]string),
+	}
+}
+
+func (s *InstanceLabels) Set(instanceID string, labels map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(labels) == 0 {
+		delete(s.labels, instanceID)
+		return nil
+	}
+	s.labels[instanceID] = maps.Clone(labels)
+	return nil
+}
+
+func (s *InstanceLabels) Get(instanceID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	labels := maps.Clone(s.labels[instanceID])
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return labels, nil
+}
+
+func (s *InstanceLabels) delete(instanceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.labels, instanceID)
+}
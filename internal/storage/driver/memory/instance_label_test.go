@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceLabels(t *testing.T) {
+	// given
+	instances := NewInstance(NewOperation(), NewSubaccountStates())
+	for _, id := range []string{"i-1", "i-2", "i-3"} {
+		require.NoError(t, instances.Insert(internal.Instance{InstanceID: id}))
+	}
+	labels := instances.Labels()
+	require.NoError(t, labels.Set("i-1", map[string]string{"team": "x", "env": "prod"}))
+	require.NoError(t, labels.Set("i-2", map[string]string{"team": "y", "env": "dev"}))
+
+	t.Run("should replace labels", func(t *testing.T) {
+		require.NoError(t, labels.Set("i-3", map[string]string{"team": "z"}))
+		require.NoError(t, labels.Set("i-3", map[string]string{"env": "dev"}))
+
+		got, err := labels.Get("i-3")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "dev"}, got)
+	})
+
+	t.Run("should filter instances by the label selector", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			selector []dbmodel.LabelRequirement
+			expected []string
+		}{
+			"equals": {
+				selector: []dbmodel.LabelRequirement{{Key: "team", Operator: dbmodel.LabelOperatorIn, Values: []string{"x"}}},
+				expected: []string{"i-1"},
+			},
+			"not equals matches instances without the label": {
+				selector: []dbmodel.LabelRequirement{{Key: "env", Operator: dbmodel.LabelOperatorNotIn, Values: []string{"prod"}}},
+				expected: []string{"i-2", "i-3"},
+			},
+			"exists and not exists": {
+				selector: []dbmodel.LabelRequirement{{Key: "env", Operator: dbmodel.LabelOperatorExists}, {Key: "team", Operator: dbmodel.LabelOperatorDoesNotExist}},
+				expected: []string{"i-3"},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				found := instances.filterInstances(dbmodel.InstanceFilter{LabelSelector: tc.selector})
+
+				ids := make([]string, 0, len(found))
+				for _, instance := range found {
+					ids = append(ids, instance.InstanceID)
+				}
+				assert.ElementsMatch(t, tc.expected, ids)
+			})
+		}
+	})
+
+	t.Run("should remove labels with the instance", func(t *testing.T) {
+		require.NoError(t, instances.Delete("i-1"))
+
+		got, err := labels.Get("i-1")
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
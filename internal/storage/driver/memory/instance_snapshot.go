@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type InstanceSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[string][]internal.InstanceSnapshot
+}
+
+func NewInstanceSnapshots() *InstanceSnapshots {
+	return &InstanceSnapshots{
+		snapshots: make(map[string][]internal.InstanceSnapshot),
+	}
+}
+
+func (s *InstanceSnapshots) Insert(snapshot internal.InstanceSnapshot) (*internal.InstanceSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, list := range s.snapshots {
+		for _, stored := range list {
+			if stored.OperationID == snapshot.OperationID {
+				return nil, dberr.AlreadyExists("snapshot of the operation %s already exists", snapshot.OperationID)
+			}
+		}
+	}
+	snapshot.Version = len(s.snapshots[snapshot.InstanceID]) + 1
+	s.snapshots[snapshot.InstanceID] = append(s.snapshots[snapshot.InstanceID], snapshot)
+	return &snapshot, nil
+}
+
+func (s *InstanceSnapshots) ListByInstanceID(instanceID string) ([]internal.InstanceSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]internal.InstanceSnapshot, len(s.snapshots[instanceID]))
+	copy(result, s.snapshots[instanceID])
+	return result, nil
+}
+
+func (s *InstanceSnapshots) GetAt(instanceID string, at time.Time) (*internal.InstanceSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *internal.InstanceSnapshot
+	for _, snapshot := range s.snapshots[instanceID] {
+		if snapshot.CreatedAt.After(at) {
+			continue
+		}
+		if found == nil || snapshot.Version > found.Version {
+			sn := snapshot
+			found = &sn
+		}
+	}
+	if found == nil {
+		return nil, dberr.NotFound("snapshot of the instance %s at %s not found", instanceID, at.Format(time.RFC3339))
+	}
+	return found, nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceSnapshots(t *testing.T) {
+	// given
+	snapshots := NewInstanceSnapshots()
+	now := time.Now()
+	first, err := snapshots.Insert(internal.InstanceSnapshot{InstanceID: "i-1", OperationID: "op-1", OperationType: internal.OperationTypeProvision, CreatedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	second, err := snapshots.Insert(internal.InstanceSnapshot{InstanceID: "i-1", OperationID: "op-2", OperationType: internal.OperationTypeUpdate, CreatedAt: now})
+	require.NoError(t, err)
+	_, err = snapshots.Insert(internal.InstanceSnapshot{InstanceID: "i-2", OperationID: "op-3", OperationType: internal.OperationTypeProvision, CreatedAt: now})
+	require.NoError(t, err)
+
+	t.Run("should assign versions per instance", func(t *testing.T) {
+		assert.Equal(t, 1, first.Version)
+		assert.Equal(t, 2, second.Version)
+
+		list, err := snapshots.ListByInstanceID("i-1")
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, "op-1", list[0].OperationID)
+		assert.Equal(t, "op-2", list[1].OperationID)
+	})
+
+	t.Run("should not store the snapshot of the operation twice", func(t *testing.T) {
+		_, err := snapshots.Insert(internal.InstanceSnapshot{InstanceID: "i-1", OperationID: "op-2", CreatedAt: now})
+
+		assert.True(t, dberr.IsAlreadyExists(err))
+	})
+
+	t.Run("should return the snapshot effective at the given time", func(t *testing.T) {
+		snapshot, err := snapshots.GetAt("i-1", now.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, "op-1", snapshot.OperationID)
+
+		snapshot, err = snapshots.GetAt("i-1", now)
+		require.NoError(t, err)
+		assert.Equal(t, "op-2", snapshot.OperationID)
+
+		_, err = snapshots.GetAt("i-1", now.Add(-2*time.Hour))
+		assert.True(t, dberr.IsNotFound(err))
+	})
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -20,6 +21,8 @@ type operations struct {
 	operations               map[string]internal.Operation
 	upgradeClusterOperations map[string]internal.UpgradeClusterOperation
 	updateOperations         map[string]internal.UpdatingOperation
+
+	outbox *Outbox
 }
 
 // NewOperation creates in-memory storage for OSB operations.
@@ -28,7 +31,25 @@ func NewOperation() *operations {
 		operations:               make(map[string]internal.Operation, 0),
 		upgradeClusterOperations: make(map[string]internal.UpgradeClusterOperation, 0),
 		updateOperations:         make(map[string]internal.UpdatingOperation, 0),
+		outbox:                   NewOutbox(),
+	}
+}
+
+// Outbox returns the outbox which gets events of finished operations
+func (s *operations) Outbox() *Outbox {
+	return s.outbox
+}
+
+// storeFinishedEvent stores the operation finished event in the outbox when the operation becomes finished with the update
+func (s *operations) storeFinishedEvent(oldOp, op internal.Operation) error {
+	if internal.IsOperationFinished(oldOp.State) || !internal.IsOperationFinished(op.State) {
+		return nil
+	}
+	event, err := internal.NewOperationFinishedOutboxEvent(op)
+	if err != nil {
+		return err
 	}
+	return s.outbox.Insert(event)
 }
 
 func (s *operations) DeleteByID(operationID string) error {
@@ -125,6 +146,9 @@ func (s *operations) UpdateProvisioningOperation(op internal.ProvisioningOperati
 	if oldOp.Version != op.Version {
 		return nil, dberr.Conflict("unable to update provisioning operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
+	if err := s.storeFinishedEvent(oldOp, op.Operation); err != nil {
+		return nil, err
+	}
 	op.Version = op.Version + 1
 	s.operations[op.ID] = op.Operation
 
@@ -142,6 +166,9 @@ func (s *operations) UpdateOperation(op internal.Operation) (*internal.Operation
 	if oldOp.Version != op.Version {
 		return nil, dberr.Conflict("unable to update operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
+	if err := s.storeFinishedEvent(oldOp, op); err != nil {
+		return nil, err
+	}
 	op.Version = op.Version + 1
 	s.operations[op.ID] = op
 
@@ -293,6 +320,9 @@ func (s *operations) UpdateDeprovisioningOperation(op internal.DeprovisioningOpe
 	if oldOp.Version != op.Version {
 		return nil, dberr.Conflict("unable to update deprovisioning operation with id %s (for instance id %s) - conflict", op.ID, op.InstanceID)
 	}
+	if err := s.storeFinishedEvent(oldOp, op.Operation); err != nil {
+		return nil, err
+	}
 	op.Version = op.Version + 1
 	s.operations[op.ID] = op.Operation
 
@@ -435,6 +465,41 @@ func (s *operations) GetLastOperation(instanceID string) (*internal.Operation, e
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.lastOperation(instanceID)
+}
+
+func (s *operations) GetLastOperations(instanceIDs []string) (map[string]internal.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations := make(map[string]internal.Operation)
+	for _, instanceID := range instanceIDs {
+		operation, err := s.lastOperation(instanceID)
+		if dberr.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		operations[instanceID] = *operation
+	}
+	return operations, nil
+}
+
+func (s *operations) ListNotFinishedOperations(instanceIDs []string) ([]internal.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations := make([]internal.Operation, 0)
+	for _, op := range s.operations {
+		if slices.Contains(instanceIDs, op.InstanceID) && isNotFinished(op.State) {
+			operations = append(operations, op)
+		}
+	}
+	return operations, nil
+}
+
+func (s *operations) lastOperation(instanceID string) (*internal.Operation, error) {
 	var rows []internal.Operation
 
 	for _, op := range s.operations {
@@ -502,13 +567,13 @@ func (s *operations) GetNotFinishedOperationsByType(opType internal.OperationTyp
 	switch opType {
 	case internal.OperationTypeProvision:
 		for _, op := range s.operations {
-			if op.State == domain.InProgress {
+			if isNotFinished(op.State) {
 				ops = append(ops, op)
 			}
 		}
 	case internal.OperationTypeDeprovision:
 		for _, op := range s.operations {
-			if op.State == domain.InProgress {
+			if isNotFinished(op.State) {
 				ops = append(ops, op)
 			}
 		}
@@ -517,6 +582,11 @@ func (s *operations) GetNotFinishedOperationsByType(opType internal.OperationTyp
 	return ops, nil
 }
 
+// isNotFinished reports whether the operation is still processed, canceling operations run their cancel steps
+func isNotFinished(state domain.LastOperationState) bool {
+	return state == domain.InProgress || state == internal.OperationStatePending || state == internal.OperationStateCanceling
+}
+
 func (s *operations) GetOperationsForIDs(opIdList []string) ([]internal.Operation, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type queueItem struct {
+	operationID    string
+	queueName      string
+	visibleAt      time.Time
+	owner          string
+	leaseExpiresAt time.Time
+	requeued       bool
+}
+
+type OperationQueue struct {
+	mu    sync.Mutex
+	items map[string]*queueItem
+}
+
+func NewOperationQueue() *OperationQueue {
+	return &OperationQueue{
+		items: make(map[string]*queueItem),
+	}
+}
+
+func (q *OperationQueue) Add(queueName, operationID string, delay time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	visibleAt := time.Now().Add(delay)
+	item, found := q.items[operationID]
+	if !found {
+		q.items[operationID] = &queueItem{operationID: operationID, queueName: queueName, visibleAt: visibleAt}
+		return nil
+	}
+	if visibleAt.Before(item.visibleAt) {
+		item.visibleAt = visibleAt
+	}
+	item.requeued = item.owner != ""
+	return nil
+}
+
+func (q *OperationQueue) Claim(queueName, owner string, leaseDuration time.Duration) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var ready []*queueItem
+	for _, item := range q.items {
+		if item.queueName != queueName || item.visibleAt.After(now) {
+			continue
+		}
+		if item.owner != "" && item.leaseExpiresAt.After(now) {
+			continue
+		}
+		ready = append(ready, item)
+	}
+	if len(ready) == 0 {
+		return "", dberr.NotFound("no operation ready to be processed in the queue %s", queueName)
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].visibleAt.Before(ready[j].visibleAt)
+	})
+
+	item := ready[0]
+	item.owner = owner
+	item.leaseExpiresAt = now.Add(leaseDuration)
+	item.requeued = false
+	return item.operationID, nil
+}
+
+func (q *OperationQueue) ExtendLease(operationID, owner string, leaseDuration time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.leased(operationID, owner)
+	if err != nil {
+		return err
+	}
+	item.leaseExpiresAt = time.Now().Add(leaseDuration)
+	return nil
+}
+
+func (q *OperationQueue) Release(operationID, owner string, delay time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.leased(operationID, owner)
+	if err != nil {
+		return err
+	}
+	q.release(item, delay)
+	return nil
+}
+
+func (q *OperationQueue) Remove(operationID, owner string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.leased(operationID, owner)
+	if err != nil {
+		return err
+	}
+	if item.requeued {
+		q.release(item, 0)
+		return nil
+	}
+	delete(q.items, operationID)
+	return nil
+}
+
+func (q *OperationQueue) Count(queueName string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := 0
+	for _, item := range q.items {
+		if item.queueName == queueName {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *OperationQueue) leased(operationID, owner string) (*queueItem, error) {
+	item, found := q.items[operationID]
+	if !found || item.owner != owner {
+		return nil, dberr.NotFound("operation %s is not leased by %s", operationID, owner)
+	}
+	return item, nil
+}
+
+func (q *OperationQueue) release(item *queueItem, delay time.Duration) {
+	if !item.requeued {
+		item.visibleAt = time.Now().Add(delay)
+	}
+	item.owner = ""
+	item.leaseExpiresAt = time.Time{}
+	item.requeued = false
+}
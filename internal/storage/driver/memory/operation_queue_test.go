@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationQueue(t *testing.T) {
+	t.Run("should claim operations exclusively", func(t *testing.T) {
+		// given
+		queue := NewOperationQueue()
+		require.NoError(t, queue.Add("provisioning", "op-1", 0))
+		require.NoError(t, queue.Add("provisioning", "op-2", time.Hour))
+		require.NoError(t, queue.Add("update", "op-3", 0))
+
+		// when
+		first, err := queue.Claim("provisioning", "owner-1", time.Minute)
+		require.NoError(t, err)
+		_, err = queue.Claim("provisioning", "owner-2", time.Minute)
+
+		// then
+		assert.Equal(t, "op-1", first)
+		assert.True(t, dberr.IsNotFound(err))
+	})
+
+	t.Run("should process again an operation added while it was claimed", func(t *testing.T) {
+		// given
+		queue := NewOperationQueue()
+		require.NoError(t, queue.Add("provisioning", "op-1", 0))
+		_, err := queue.Claim("provisioning", "owner-1", time.Minute)
+		require.NoError(t, err)
+
+		// when
+		require.NoError(t, queue.Add("provisioning", "op-1", 0))
+		require.NoError(t, queue.Remove("op-1", "owner-1"))
+
+		// then
+		id, err := queue.Claim("provisioning", "owner-2", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "op-1", id)
+		require.NoError(t, queue.Remove("op-1", "owner-2"))
+		count, err := queue.Count("provisioning")
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("should not allow to release an operation leased by other owner", func(t *testing.T) {
+		// given
+		queue := NewOperationQueue()
+		require.NoError(t, queue.Add("provisioning", "op-1", 0))
+		_, err := queue.Claim("provisioning", "owner-1", time.Minute)
+		require.NoError(t, err)
+
+		// when
+		err = queue.Release("op-1", "owner-2", 0)
+
+		// then
+		assert.True(t, dberr.IsNotFound(err))
+		assert.True(t, dberr.IsNotFound(queue.ExtendLease("op-1", "owner-2", time.Minute)))
+		assert.NoError(t, queue.ExtendLease("op-1", "owner-1", time.Minute))
+	})
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotFinishedOperations(t *testing.T) {
+	// given
+	operations := NewOperation()
+	for id, state := range map[string]domain.LastOperationState{
+		"in-progress": domain.InProgress,
+		"pending":     internal.OperationStatePending,
+		"canceling":   internal.OperationStateCanceling,
+		"canceled":    internal.OperationStateCanceled,
+		"succeeded":   domain.Succeeded,
+	} {
+		operation := fixture.FixDeprovisioningOperation(id, "inst-"+id)
+		operation.State = state
+		require.NoError(t, operations.InsertDeprovisioningOperation(operation))
+	}
+
+	// when
+	byType, err := operations.GetNotFinishedOperationsByType(internal.OperationTypeDeprovision)
+	require.NoError(t, err)
+	listed, err := operations.ListNotFinishedOperations([]string{"inst-in-progress", "inst-pending", "inst-canceling", "inst-canceled", "inst-succeeded"})
+	require.NoError(t, err)
+
+	// then
+	assert.ElementsMatch(t, []string{"in-progress", "pending", "canceling"}, operationIDs(byType))
+	assert.ElementsMatch(t, []string{"in-progress", "pending", "canceling"}, operationIDs(listed))
+}
+
+func operationIDs(operations []internal.Operation) []string {
+	ids := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		ids = append(ids, operation.ID)
+	}
+	return ids
+}
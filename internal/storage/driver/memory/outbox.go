@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+)
+
+type outboxItem struct {
+	event          internal.OutboxEvent
+	visibleAt      time.Time
+	owner          string
+	leaseExpiresAt time.Time
+}
+
+type Outbox struct {
+	mu     sync.Mutex
+	items  []*outboxItem
+	lastID int64
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{
+		items: make([]*outboxItem, 0),
+	}
+}
+
+func (o *Outbox) Insert(event internal.OutboxEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.lastID++
+	event.ID = o.lastID
+	o.items = append(o.items, &outboxItem{event: event, visibleAt: event.CreatedAt})
+	return nil
+}
+
+// Claim leases the oldest events of instances, items are kept in the insertion order
+func (o *Outbox) Claim(owner string, leaseDuration time.Duration, limit int) ([]internal.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	seen := make(map[string]struct{})
+	events := make([]internal.OutboxEvent, 0)
+	for _, item := range o.items {
+		if len(events) >= limit {
+			break
+		}
+		if _, found := seen[item.event.InstanceID]; found {
+			continue
+		}
+		seen[item.event.InstanceID] = struct{}{}
+		if item.visibleAt.After(now) || (item.owner != "" && item.leaseExpiresAt.After(now)) {
+			continue
+		}
+		item.owner = owner
+		item.leaseExpiresAt = now.Add(leaseDuration)
+		events = append(events, item.event)
+	}
+	return events, nil
+}
+
+func (o *Outbox) Release(id int64, owner string, delay time.Duration) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i, err := o.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	item := o.items[i]
+	item.owner = ""
+	item.leaseExpiresAt = time.Time{}
+	item.visibleAt = time.Now().Add(delay)
+	item.event.Attempts++
+	return nil
+}
+
+func (o *Outbox) MarkDelivered(id int64, owner string, sinks []string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i, err := o.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	o.items[i].event.DeliveredSinks = append([]string{}, sinks...)
+	return nil
+}
+
+func (o *Outbox) Remove(id int64, owner string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i, err := o.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	o.items = append(o.items[:i], o.items[i+1:]...)
+	return nil
+}
+
+func (o *Outbox) leased(id int64, owner string) (int, error) {
+	for i, item := range o.items {
+		if item.event.ID == id && item.owner == owner {
+			return i, nil
+		}
+	}
+	return 0, dberr.NotFound("outbox event %d is not leased by %s", id, owner)
+}
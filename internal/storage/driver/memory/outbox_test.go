@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Run("should claim only the oldest event of every instance", func(t *testing.T) {
+		// given
+		outbox := NewOutbox()
+		require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-1", OperationID: "op-1"}))
+		require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-1", OperationID: "op-2"}))
+		require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-2", OperationID: "op-3"}))
+
+		// when
+		events, err := outbox.Claim("owner-1", time.Minute, 10)
+		require.NoError(t, err)
+		claimedAgain, err := outbox.Claim("owner-2", time.Minute, 10)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, events, 2)
+		assert.Equal(t, "op-1", events[0].OperationID)
+		assert.Equal(t, "op-3", events[1].OperationID)
+		assert.Empty(t, claimedAgain)
+	})
+
+	t.Run("should claim the next event of the instance after the previous one is removed", func(t *testing.T) {
+		// given
+		outbox := NewOutbox()
+		require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-1", OperationID: "op-1"}))
+		require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-1", OperationID: "op-2"}))
+		events, err := outbox.Claim("owner-1", time.Minute, 10)
+		require.NoError(t, err)
+
+		// when
+		assert.True(t, dberr.IsNotFound(outbox.Remove(events[0].ID, "owner-2")))
+		require.NoError(t, outbox.Remove(events[0].ID, "owner-1"))
+		events, err = outbox.Claim("owner-1", time.Minute, 10)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, events, 1)
+		assert.Equal(t, "op-2", events[0].OperationID)
+	})
+
+	t.Run("should delay the released event and count attempts", func(t *testing.T) {
+		// given
+		outbox := NewOutbox()
+		require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-1", OperationID: "op-1"}))
+		events, err := outbox.Claim("owner-1", time.Minute, 10)
+		require.NoError(t, err)
+
+		// when
+		require.NoError(t, outbox.Release(events[0].ID, "owner-1", 0))
+		retried, err := outbox.Claim("owner-1", time.Minute, 10)
+		require.NoError(t, err)
+		require.NoError(t, outbox.Release(events[0].ID, "owner-1", time.Hour))
+		delayed, err := outbox.Claim("owner-1", time.Minute, 10)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, retried, 1)
+		assert.Equal(t, 1, retried[0].Attempts)
+		assert.Empty(t, delayed)
+	})
+}
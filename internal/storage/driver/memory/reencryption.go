@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+// Reencryption does nothing, the memory storage does not encrypt values
+type Reencryption struct{}
+
+func NewReencryption() *Reencryption {
+	return &Reencryption{}
+}
+
+func (r *Reencryption) Tables() []string {
+	return nil
+}
+
+func (r *Reencryption) ReencryptBatch(_ string, after []string, _ int) (dbmodel.ReencryptionBatch, error) {
+	return dbmodel.ReencryptionBatch{Last: after}, nil
+}
@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+type webhookDeliveryItem struct {
+	delivery       internal.WebhookDelivery
+	visibleAt      time.Time
+	owner          string
+	leaseExpiresAt time.Time
+}
+
+type Webhooks struct {
+	mu            sync.Mutex
+	subscriptions map[string]internal.WebhookSubscription
+	deliveries    []*webhookDeliveryItem
+	lastID        int64
+}
+
+func NewWebhooks() *Webhooks {
+	return &Webhooks{
+		subscriptions: make(map[string]internal.WebhookSubscription),
+		deliveries:    make([]*webhookDeliveryItem, 0),
+	}
+}
+
+func (w *Webhooks) InsertSubscription(subscription internal.WebhookSubscription) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, found := w.subscriptions[subscription.ID]; found {
+		return dberr.AlreadyExists("webhook subscription with id %s already exists", subscription.ID)
+	}
+	w.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (w *Webhooks) GetSubscription(id string) (*internal.WebhookSubscription, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subscription, found := w.subscriptions[id]
+	if !found {
+		return nil, dberr.NotFound("Cannot find the webhook subscription with id:'%s'", id)
+	}
+	return &subscription, nil
+}
+
+func (w *Webhooks) ListSubscriptions(filter dbmodel.WebhookSubscriptionFilter) ([]internal.WebhookSubscription, int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subscriptions := make([]internal.WebhookSubscription, 0)
+	for _, subscription := range w.subscriptions {
+		if filter.GlobalAccountID != "" && subscription.GlobalAccountID != filter.GlobalAccountID {
+			continue
+		}
+		if filter.SubAccountID != "" && subscription.SubAccountID != filter.SubAccountID {
+			continue
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	subscriptions, totalCount := paginate(subscriptions, filter.Page, filter.PageSize)
+	return subscriptions, totalCount, nil
+}
+
+func (w *Webhooks) DeleteSubscription(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, found := w.subscriptions[id]; !found {
+		return dberr.NotFound("webhook subscription %s does not exist", id)
+	}
+	delete(w.subscriptions, id)
+	deliveries := make([]*webhookDeliveryItem, 0, len(w.deliveries))
+	for _, item := range w.deliveries {
+		if item.delivery.SubscriptionID != id {
+			deliveries = append(deliveries, item)
+		}
+	}
+	w.deliveries = deliveries
+	return nil
+}
+
+func (w *Webhooks) InsertDelivery(delivery internal.WebhookDelivery) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, item := range w.deliveries {
+		if item.delivery.SubscriptionID == delivery.SubscriptionID && item.delivery.EventID == delivery.EventID {
+			return nil
+		}
+	}
+	w.lastID++
+	delivery.ID = w.lastID
+	delivery.State = internal.WebhookDeliveryPending
+	delivery.UpdatedAt = delivery.CreatedAt
+	w.deliveries = append(w.deliveries, &webhookDeliveryItem{delivery: delivery, visibleAt: delivery.CreatedAt})
+	return nil
+}
+
+func (w *Webhooks) ClaimDeliveries(owner string, leaseDuration time.Duration, limit int) ([]internal.WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	deliveries := make([]internal.WebhookDelivery, 0)
+	for _, item := range w.deliveries {
+		if len(deliveries) >= limit {
+			break
+		}
+		if item.delivery.State != internal.WebhookDeliveryPending || item.visibleAt.After(now) || (item.owner != "" && item.leaseExpiresAt.After(now)) {
+			continue
+		}
+		item.owner = owner
+		item.leaseExpiresAt = now.Add(leaseDuration)
+		deliveries = append(deliveries, item.delivery)
+	}
+	return deliveries, nil
+}
+
+func (w *Webhooks) RetryDelivery(id int64, owner string, delay time.Duration, lastError string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	item, err := w.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	item.release(lastError)
+	item.visibleAt = time.Now().Add(delay)
+	return nil
+}
+
+func (w *Webhooks) MarkDeliveryDead(id int64, owner string, lastError string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	item, err := w.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	item.release(lastError)
+	item.delivery.State = internal.WebhookDeliveryDead
+	return nil
+}
+
+func (w *Webhooks) RemoveDelivery(id int64, owner string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, item := range w.deliveries {
+		if item.delivery.ID == id && item.owner == owner {
+			w.deliveries = append(w.deliveries[:i], w.deliveries[i+1:]...)
+			return nil
+		}
+	}
+	return dberr.NotFound("webhook delivery %d is not leased by %s", id, owner)
+}
+
+func (w *Webhooks) ListDeliveries(filter dbmodel.WebhookDeliveryFilter) ([]internal.WebhookDelivery, int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	deliveries := make([]internal.WebhookDelivery, 0)
+	for _, item := range w.deliveries {
+		if item.delivery.SubscriptionID != filter.SubscriptionID {
+			continue
+		}
+		if filter.State != "" && string(item.delivery.State) != filter.State {
+			continue
+		}
+		deliveries = append(deliveries, item.delivery)
+	}
+	deliveries, totalCount := paginate(deliveries, filter.Page, filter.PageSize)
+	return deliveries, totalCount, nil
+}
+
+func (w *Webhooks) leased(id int64, owner string) (*webhookDeliveryItem, error) {
+	for _, item := range w.deliveries {
+		if item.delivery.ID == id && item.owner == owner {
+			return item, nil
+		}
+	}
+	return nil, dberr.NotFound("webhook delivery %d is not leased by %s", id, owner)
+}
+
+func (i *webhookDeliveryItem) release(lastError string) {
+	i.owner = ""
+	i.leaseExpiresAt = time.Time{}
+	i.delivery.Attempts++
+	i.delivery.LastError = lastError
+	i.delivery.UpdatedAt = time.Now()
+}
+
+// paginate returns the page of items together with the number of all items, all items are returned if the page is not set
+func paginate[T any](items []T, page, pageSize int) ([]T, int) {
+	totalCount := len(items)
+	if page > 0 && pageSize > 0 {
+		offset := min((page-1)*pageSize, totalCount)
+		items = items[offset:min(offset+pageSize, totalCount)]
+	}
+	return items, totalCount
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	t.Run("should store a notification once per subscription and event", func(t *testing.T) {
+		// given
+		webhooks := NewWebhooks()
+		require.NoError(t, webhooks.InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1"}))
+
+		// when
+		require.NoError(t, webhooks.InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1}))
+		require.NoError(t, webhooks.InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1}))
+		deliveries, _, err := webhooks.ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1"})
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, internal.WebhookDeliveryPending, deliveries[0].State)
+	})
+
+	t.Run("should not claim leased, delayed and dead notifications", func(t *testing.T) {
+		// given
+		webhooks := NewWebhooks()
+		require.NoError(t, webhooks.InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1"}))
+		for eventID := int64(1); eventID <= 3; eventID++ {
+			require.NoError(t, webhooks.InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: eventID}))
+		}
+		claimed, err := webhooks.ClaimDeliveries("owner-1", time.Minute, 2)
+		require.NoError(t, err)
+		require.Len(t, claimed, 2)
+
+		// when
+		assert.True(t, dberr.IsNotFound(webhooks.RetryDelivery(claimed[0].ID, "owner-2", 0, "timeout")))
+		require.NoError(t, webhooks.RetryDelivery(claimed[0].ID, "owner-1", time.Hour, "timeout"))
+		require.NoError(t, webhooks.MarkDeliveryDead(claimed[1].ID, "owner-1", "timeout"))
+		next, err := webhooks.ClaimDeliveries("owner-2", time.Minute, 10)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, next, 1)
+		assert.Equal(t, int64(3), next[0].EventID)
+		dead, _, err := webhooks.ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1", State: string(internal.WebhookDeliveryDead)})
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, 1, dead[0].Attempts)
+	})
+
+	t.Run("should remove notifications of the deleted subscription", func(t *testing.T) {
+		// given
+		webhooks := NewWebhooks()
+		require.NoError(t, webhooks.InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1"}))
+		require.NoError(t, webhooks.InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1}))
+
+		// when
+		require.NoError(t, webhooks.DeleteSubscription("sub-1"))
+
+		// then
+		deliveries, _, err := webhooks.ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1"})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+		assert.True(t, dberr.IsNotFound(webhooks.DeleteSubscription("sub-1")))
+	})
+}
@@ -2,6 +2,7 @@ package postsql
 
 import (
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
 )
 
@@ -15,10 +16,14 @@ func NewAction(sess postsql.Factory) *Action {
 	}
 }
 
-func (a *Action) InsertAction(actionType runtime.ActionType, instanceID, message, oldValue, newValue string) error {
-	return a.Factory.NewWriteSession().InsertAction(actionType, instanceID, message, oldValue, newValue)
+func (a *Action) InsertAction(action runtime.Action) error {
+	return a.Factory.NewWriteSession().InsertAction(action)
 }
 
 func (a *Action) ListActionsByInstanceID(instanceID string) ([]runtime.Action, error) {
 	return a.Factory.NewReadSession().ListActions(instanceID)
 }
+
+func (a *Action) ListActions(filter dbmodel.ActionFilter) ([]runtime.Action, int, error) {
+	return a.Factory.NewReadSession().ListActionsByFilter(filter)
+}
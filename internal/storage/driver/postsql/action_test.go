@@ -5,6 +5,7 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,9 +27,9 @@ func TestAction(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 
-	err = brokerStorage.Actions().InsertAction(runtime.PlanUpdateActionType, instanceID, "test-message-1", "old-value-1", "new-value-1")
+	err = brokerStorage.Actions().InsertAction(runtime.Action{Type: runtime.PlanUpdateActionType, InstanceID: instanceID, Message: "test-message-1", OldValue: "old-value-1", NewValue: "new-value-1"})
 	assert.NoError(t, err)
-	err = brokerStorage.Actions().InsertAction(runtime.SubaccountMovementActionType, instanceID, "test-message-2", "old-value-2", "new-value-2")
+	err = brokerStorage.Actions().InsertAction(runtime.Action{Type: runtime.SubaccountMovementActionType, InstanceID: instanceID, Actor: "admin@example.com", Message: "test-message-2", OldValue: "old-value-2", NewValue: "new-value-2"})
 	assert.NoError(t, err)
 
 	actions, err = brokerStorage.Actions().ListActionsByInstanceID(instanceID)
@@ -41,6 +42,7 @@ func TestAction(t *testing.T) {
 	assert.Equal(t, actions[0].Message, "test-message-2")
 	assert.Equal(t, actions[0].OldValue, "old-value-2")
 	assert.Equal(t, actions[0].NewValue, "new-value-2")
+	assert.Equal(t, actions[0].Actor, "admin@example.com")
 	assert.NotEmpty(t, actions[0].CreatedAt)
 
 	assert.NotEmpty(t, actions[1].ID)
@@ -60,4 +62,15 @@ func TestAction(t *testing.T) {
 	actions, err = brokerStorage.Actions().ListActionsByInstanceID(instanceID)
 	assert.NoError(t, err)
 	assert.Len(t, actions, 2)
+
+	filtered, totalCount, err := brokerStorage.Actions().ListActions(dbmodel.ActionFilter{
+		Types:    []string{string(runtime.SubaccountMovementActionType)},
+		Actors:   []string{"admin@example.com"},
+		Page:     1,
+		PageSize: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, totalCount)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "test-message-2", filtered[0].Message)
 }
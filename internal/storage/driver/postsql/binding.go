@@ -2,11 +2,13 @@ package postsql
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+	"github.com/pivotal-cf/brokerapi/v12/domain"
 )
 
 type Binding struct {
@@ -137,6 +139,11 @@ func (s *Binding) toBindingDTO(binding *internal.Binding) (dbmodel.BindingDTO, e
 		ExpirationSeconds: binding.ExpirationSeconds,
 		CreatedBy:         binding.CreatedBy,
 		ExpiresAt:         binding.ExpiresAt,
+		Role:              binding.Role,
+		Namespaces:        strings.Join(binding.Namespaces, ","),
+		State:             string(binding.State),
+		Stage:             binding.Stage,
+		Description:       binding.Description,
 	}, nil
 }
 
@@ -145,6 +152,10 @@ func (s *Binding) toBinding(dto dbmodel.BindingDTO) (internal.Binding, error) {
 	if err != nil {
 		return internal.Binding{}, fmt.Errorf("while decrypting kubeconfig: %w", err)
 	}
+	var namespaces []string
+	if dto.Namespaces != "" {
+		namespaces = strings.Split(dto.Namespaces, ",")
+	}
 
 	return internal.Binding{
 		Kubeconfig:        string(decrypted),
@@ -154,6 +165,11 @@ func (s *Binding) toBinding(dto dbmodel.BindingDTO) (internal.Binding, error) {
 		ExpirationSeconds: dto.ExpirationSeconds,
 		CreatedBy:         dto.CreatedBy,
 		ExpiresAt:         dto.ExpiresAt,
+		Role:              dto.Role,
+		Namespaces:        namespaces,
+		State:             domain.LastOperationState(dto.State),
+		Stage:             dto.Stage,
+		Description:       dto.Description,
 	}, nil
 }
 
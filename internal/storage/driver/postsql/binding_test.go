@@ -25,6 +25,8 @@ func TestBinding(t *testing.T) {
 		// given
 		testBindingId := "test"
 		fixedBinding := fixture.FixBinding(testBindingId)
+		fixedBinding.Role = "namespace-admin"
+		fixedBinding.Namespaces = []string{"ci", "staging"}
 
 		err = brokerStorage.Bindings().Insert(&fixedBinding)
 		assert.NoError(t, err)
@@ -44,6 +46,8 @@ func TestBinding(t *testing.T) {
 		assert.NotNil(t, createdBinding.Kubeconfig)
 		assert.Equal(t, fixedBinding.Kubeconfig, createdBinding.Kubeconfig)
 		assert.Equal(t, fixedBinding.CreatedBy, createdBinding.CreatedBy)
+		assert.Equal(t, fixedBinding.Role, createdBinding.Role)
+		assert.Equal(t, fixedBinding.Namespaces, createdBinding.Namespaces)
 
 		// when
 		err = brokerStorage.Bindings().Delete(testInstanceID, testBindingId)
@@ -0,0 +1,172 @@
+package postsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type Campaigns struct {
+	postsql.Factory
+}
+
+func NewCampaigns(sess postsql.Factory) *Campaigns {
+	return &Campaigns{
+		Factory: sess,
+	}
+}
+
+func (c *Campaigns) Insert(campaign internal.Campaign, instances []internal.CampaignInstance) error {
+	dto, err := toCampaignDTO(campaign)
+	if err != nil {
+		return err
+	}
+	instanceDTOs := make([]dbmodel.CampaignInstanceDTO, 0, len(instances))
+	for _, instance := range instances {
+		instanceDTOs = append(instanceDTOs, toCampaignInstanceDTO(instance))
+	}
+
+	session, dbErr := c.Factory.NewSessionWithinTransaction()
+	if dbErr != nil {
+		return dbErr
+	}
+	defer session.RollbackUnlessCommitted()
+
+	if dbErr := session.InsertCampaign(dto); dbErr != nil {
+		return dbErr
+	}
+	if dbErr := session.InsertCampaignInstances(instanceDTOs); dbErr != nil {
+		return dbErr
+	}
+	return session.Commit()
+}
+
+func (c *Campaigns) Get(id string) (*internal.Campaign, error) {
+	dto, dbErr := c.Factory.NewReadSession().GetCampaign(id)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+	campaign, err := toCampaign(dto)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (c *Campaigns) List(filter dbmodel.CampaignFilter) ([]internal.Campaign, int, error) {
+	dtos, totalCount, err := c.Factory.NewReadSession().ListCampaigns(filter)
+	if err != nil {
+		return nil, -1, dberr.Internal("while listing campaigns: %s", err)
+	}
+	list, err := toCampaigns(dtos)
+	if err != nil {
+		return nil, -1, err
+	}
+	return list, totalCount, nil
+}
+
+func (c *Campaigns) Update(campaign internal.Campaign) (*internal.Campaign, error) {
+	campaign.UpdatedAt = time.Now()
+	dto, err := toCampaignDTO(campaign)
+	if err != nil {
+		return nil, err
+	}
+	if dbErr := c.Factory.NewWriteSession().UpdateCampaign(dto); dbErr != nil {
+		if dberr.IsNotFound(dbErr) {
+			// the campaign exists but the version is different
+			return nil, dberr.Conflict("campaign update conflict, campaign ID: %s", campaign.ID)
+		}
+		return nil, dbErr
+	}
+	campaign.Version++
+	return &campaign, nil
+}
+
+func (c *Campaigns) Claim(owner string, leaseDuration time.Duration) ([]internal.Campaign, error) {
+	dtos, dbErr := c.Factory.NewWriteSession().ClaimCampaigns(owner, leaseDuration, []string{string(campaigns.StatePending), string(campaigns.StateInProgress)})
+	if dbErr != nil {
+		return nil, dbErr
+	}
+	return toCampaigns(dtos)
+}
+
+func (c *Campaigns) ListInstances(campaignID string, state campaigns.InstanceState) ([]internal.CampaignInstance, error) {
+	dtos, err := c.Factory.NewReadSession().ListCampaignInstances(campaignID, string(state))
+	if err != nil {
+		return nil, dberr.Internal("while listing instances of campaign %s: %s", campaignID, err)
+	}
+	instances := make([]internal.CampaignInstance, 0, len(dtos))
+	for _, dto := range dtos {
+		instances = append(instances, internal.CampaignInstance{
+			CampaignID:  dto.CampaignID,
+			InstanceID:  dto.InstanceID,
+			Wave:        dto.Wave,
+			State:       campaigns.InstanceState(dto.State),
+			OperationID: dto.OperationID,
+			Message:     dto.Message,
+			UpdatedAt:   dto.UpdatedAt,
+		})
+	}
+	return instances, nil
+}
+
+func (c *Campaigns) UpdateInstance(instance internal.CampaignInstance) error {
+	return c.Factory.NewWriteSession().UpdateCampaignInstance(toCampaignInstanceDTO(instance))
+}
+
+func toCampaignDTO(campaign internal.Campaign) (dbmodel.CampaignDTO, error) {
+	data, err := json.Marshal(campaign)
+	if err != nil {
+		return dbmodel.CampaignDTO{}, fmt.Errorf("while serializing campaign %s: %w", campaign.ID, err)
+	}
+	return dbmodel.CampaignDTO{
+		ID:        campaign.ID,
+		State:     string(campaign.State),
+		Data:      string(data),
+		Version:   campaign.Version,
+		CreatedAt: campaign.CreatedAt,
+		UpdatedAt: campaign.UpdatedAt,
+	}, nil
+}
+
+func toCampaign(dto dbmodel.CampaignDTO) (internal.Campaign, error) {
+	var campaign internal.Campaign
+	if err := json.Unmarshal([]byte(dto.Data), &campaign); err != nil {
+		return internal.Campaign{}, fmt.Errorf("while unmarshalling campaign %s: %w", dto.ID, err)
+	}
+	campaign.State = campaigns.State(dto.State)
+	campaign.Version = dto.Version
+	campaign.CreatedAt = dto.CreatedAt
+	campaign.UpdatedAt = dto.UpdatedAt
+	return campaign, nil
+}
+
+func toCampaigns(dtos []dbmodel.CampaignDTO) ([]internal.Campaign, error) {
+	result := make([]internal.Campaign, 0, len(dtos))
+	for _, dto := range dtos {
+		campaign, err := toCampaign(dto)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, campaign)
+	}
+	return result, nil
+}
+
+func toCampaignInstanceDTO(instance internal.CampaignInstance) dbmodel.CampaignInstanceDTO {
+	return dbmodel.CampaignInstanceDTO{
+		CampaignID:  instance.CampaignID,
+		InstanceID:  instance.InstanceID,
+		Wave:        instance.Wave,
+		State:       string(instance.State),
+		OperationID: instance.OperationID,
+		Message:     instance.Message,
+		UpdatedAt:   instance.UpdatedAt,
+	}
+}
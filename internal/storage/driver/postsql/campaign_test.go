@@ -0,0 +1,78 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaigns(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+	storage := brokerStorage.Campaigns()
+
+	// given
+	now := time.Now()
+	require.NoError(t, storage.Insert(internal.Campaign{ID: "c-1", State: campaigns.StatePending, Target: campaigns.Target{Plans: []string{"aws"}},
+		Strategy: campaigns.Strategy{CanarySize: 1}, Waves: 2, CreatedAt: now, UpdatedAt: now}, []internal.CampaignInstance{
+		{CampaignID: "c-1", InstanceID: "i-2", Wave: 1, State: campaigns.InstancePending, UpdatedAt: now},
+		{CampaignID: "c-1", InstanceID: "i-1", Wave: 0, State: campaigns.InstancePending, UpdatedAt: now},
+	}))
+	require.NoError(t, storage.Insert(internal.Campaign{ID: "c-2", State: campaigns.StatePaused, CreatedAt: now, UpdatedAt: now}, nil))
+
+	// when
+	claimed, err := storage.Claim("owner-1", time.Minute)
+	require.NoError(t, err)
+	claimedByOther, err := storage.Claim("owner-2", time.Minute)
+	require.NoError(t, err)
+
+	// then
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "c-1", claimed[0].ID)
+	assert.Equal(t, []string{"aws"}, claimed[0].Target.Plans)
+	assert.Empty(t, claimedByOther)
+
+	// when
+	claimed[0].State = campaigns.StateInProgress
+	updated, err := storage.Update(claimed[0])
+	require.NoError(t, err)
+	_, conflictErr := storage.Update(claimed[0])
+
+	// then
+	assert.True(t, dberr.IsConflict(conflictErr))
+	stored, err := storage.Get("c-1")
+	require.NoError(t, err)
+	assert.Equal(t, campaigns.StateInProgress, stored.State)
+	assert.Equal(t, updated.Version, stored.Version)
+
+	// when
+	require.NoError(t, storage.UpdateInstance(internal.CampaignInstance{CampaignID: "c-1", InstanceID: "i-1", State: campaigns.InstanceInProgress, OperationID: "op-1", UpdatedAt: now}))
+	instances, err := storage.ListInstances("c-1", "")
+	require.NoError(t, err)
+	pending, err := storage.ListInstances("c-1", campaigns.InstancePending)
+	require.NoError(t, err)
+
+	// then
+	require.Len(t, instances, 2)
+	assert.Equal(t, "i-1", instances[0].InstanceID)
+	assert.Equal(t, "op-1", instances[0].OperationID)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "i-2", pending[0].InstanceID)
+
+	list, totalCount, err := storage.List(dbmodel.CampaignFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, 2, totalCount)
+}
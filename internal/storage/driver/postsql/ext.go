@@ -5,6 +5,10 @@ import "github.com/kyma-project/kyma-environment-broker/internal"
 type Cipher interface {
 	Encrypt(text []byte) ([]byte, error)
 	Decrypt(text []byte) ([]byte, error)
+	// NeedsReencryption returns true if the text is not encrypted with the active key
+	NeedsReencryption(text []byte) bool
+	// IsEncrypted returns false if the text cannot be a ciphertext
+	IsEncrypted(text []byte) bool
 
 	// methods used to encrypt/decrypt SM credentials
 	EncryptSMCreds(pp *internal.ProvisioningParameters) error
@@ -0,0 +1,50 @@
+package postsql
+
+import (
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type InstanceLabels struct {
+	postsql.Factory
+}
+
+func NewInstanceLabels(sess postsql.Factory) *InstanceLabels {
+	return &InstanceLabels{
+		Factory: sess,
+	}
+}
+
+func (s *InstanceLabels) Set(instanceID string, labels map[string]string) error {
+	dtos := make([]dbmodel.InstanceLabelDTO, 0, len(labels))
+	for key, value := range labels {
+		dtos = append(dtos, dbmodel.InstanceLabelDTO{InstanceID: instanceID, Key: key, Value: value})
+	}
+
+	session, dbErr := s.Factory.NewSessionWithinTransaction()
+	if dbErr != nil {
+		return dbErr
+	}
+	defer session.RollbackUnlessCommitted()
+
+	if dbErr := session.DeleteInstanceLabels(instanceID); dbErr != nil {
+		return dbErr
+	}
+	if dbErr := session.InsertInstanceLabels(dtos); dbErr != nil {
+		return dbErr
+	}
+	return session.Commit()
+}
+
+func (s *InstanceLabels) Get(instanceID string) (map[string]string, error) {
+	dtos, err := s.Factory.NewReadSession().ListInstanceLabels(instanceID)
+	if err != nil {
+		return nil, dberr.Internal("while getting labels of the instance %s: %s", instanceID, err)
+	}
+	labels := make(map[string]string, len(dtos))
+	for _, dto := range dtos {
+		labels[dto.Key] = dto.Value
+	}
+	return labels, nil
+}
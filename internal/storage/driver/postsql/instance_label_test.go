@@ -0,0 +1,85 @@
+package postsql_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceLabels(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+	labels := brokerStorage.InstanceLabels()
+
+	// given
+	for _, id := range []string{"i-1", "i-2", "i-3"} {
+		err = brokerStorage.Instances().Insert(*fixInstance(instanceData{val: id}))
+		require.NoError(t, err)
+		err = brokerStorage.Operations().InsertOperation(fixture.FixProvisioningOperation("op-"+id, id))
+		require.NoError(t, err)
+		err = brokerStorage.Instances().UpdateInstanceLastOperation(id, "op-"+id)
+		require.NoError(t, err)
+	}
+	require.NoError(t, labels.Set("i-1", map[string]string{"team": "x", "env": "prod"}))
+	require.NoError(t, labels.Set("i-2", map[string]string{"team": "y", "env": "dev"}))
+	require.NoError(t, labels.Set("i-3", map[string]string{"team": "z"}))
+
+	// when
+	err = labels.Set("i-3", map[string]string{"env": "dev"})
+
+	// then
+	require.NoError(t, err)
+	got, err := labels.Get("i-3")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "dev"}, got)
+
+	for name, tc := range map[string]struct {
+		selector []dbmodel.LabelRequirement
+		expected []string
+	}{
+		"equals": {
+			selector: []dbmodel.LabelRequirement{{Key: "team", Operator: dbmodel.LabelOperatorIn, Values: []string{"x"}}},
+			expected: []string{"i-1"},
+		},
+		"not equals matches instances without the label": {
+			selector: []dbmodel.LabelRequirement{{Key: "team", Operator: dbmodel.LabelOperatorNotIn, Values: []string{"x"}}},
+			expected: []string{"i-2", "i-3"},
+		},
+		"exists and not exists": {
+			selector: []dbmodel.LabelRequirement{{Key: "env", Operator: dbmodel.LabelOperatorExists}, {Key: "team", Operator: dbmodel.LabelOperatorDoesNotExist}},
+			expected: []string{"i-3"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// when
+			out, _, totalCount, err := brokerStorage.Instances().List(dbmodel.InstanceFilter{LabelSelector: tc.selector})
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), totalCount)
+			var ids []string
+			for _, instance := range out {
+				ids = append(ids, instance.InstanceID)
+			}
+			assert.ElementsMatch(t, tc.expected, ids)
+		})
+	}
+
+	// when
+	err = brokerStorage.Instances().Delete("i-1")
+
+	// then
+	require.NoError(t, err)
+	got, err = labels.Get("i-1")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,105 @@
+package postsql
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type InstanceSnapshots struct {
+	postsql.Factory
+	cipher Cipher
+}
+
+func NewInstanceSnapshots(sess postsql.Factory, cipher Cipher) *InstanceSnapshots {
+	return &InstanceSnapshots{
+		Factory: sess,
+		cipher:  cipher,
+	}
+}
+
+func (s *InstanceSnapshots) Insert(snapshot internal.InstanceSnapshot) (*internal.InstanceSnapshot, error) {
+	dto, err := s.toDTO(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.Factory.NewWriteSession().InsertInstanceSnapshot(dto)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Version = version
+	return &snapshot, nil
+}
+
+func (s *InstanceSnapshots) ListByInstanceID(instanceID string) ([]internal.InstanceSnapshot, error) {
+	dtos, err := s.Factory.NewReadSession().ListInstanceSnapshots(instanceID)
+	if err != nil {
+		return nil, dberr.Internal("while listing snapshots of the instance %s: %s", instanceID, err)
+	}
+	snapshots := make([]internal.InstanceSnapshot, 0, len(dtos))
+	for _, dto := range dtos {
+		snapshot, err := s.toSnapshot(dto)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (s *InstanceSnapshots) GetAt(instanceID string, at time.Time) (*internal.InstanceSnapshot, error) {
+	dto, err := s.Factory.NewReadSession().GetInstanceSnapshotAt(instanceID, at)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, decryptErr := s.toSnapshot(dto)
+	if decryptErr != nil {
+		return nil, decryptErr
+	}
+	return &snapshot, nil
+}
+
+// toDTO encrypts the parameters as a whole, they contain OIDC configuration and administrators
+func (s *InstanceSnapshots) toDTO(snapshot internal.InstanceSnapshot) (dbmodel.InstanceSnapshotDTO, error) {
+	params, err := json.Marshal(snapshot.Parameters)
+	if err != nil {
+		return dbmodel.InstanceSnapshotDTO{}, fmt.Errorf("while marshalling snapshot parameters: %w", err)
+	}
+	encrypted, err := s.cipher.Encrypt(params)
+	if err != nil {
+		return dbmodel.InstanceSnapshotDTO{}, fmt.Errorf("while encrypting snapshot parameters: %w", err)
+	}
+	return dbmodel.InstanceSnapshotDTO{
+		InstanceID:    snapshot.InstanceID,
+		OperationID:   snapshot.OperationID,
+		OperationType: string(snapshot.OperationType),
+		RuntimeID:     snapshot.RuntimeID,
+		Parameters:    string(encrypted),
+		CreatedAt:     snapshot.CreatedAt,
+	}, nil
+}
+
+func (s *InstanceSnapshots) toSnapshot(dto dbmodel.InstanceSnapshotDTO) (internal.InstanceSnapshot, error) {
+	decrypted, err := s.cipher.Decrypt([]byte(dto.Parameters))
+	if err != nil {
+		return internal.InstanceSnapshot{}, fmt.Errorf("while decrypting snapshot parameters: %w", err)
+	}
+	var params internal.ProvisioningParameters
+	if err := json.Unmarshal(decrypted, &params); err != nil {
+		return internal.InstanceSnapshot{}, fmt.Errorf("while unmarshalling snapshot parameters: %w", err)
+	}
+	return internal.InstanceSnapshot{
+		InstanceID:    dto.InstanceID,
+		Version:       dto.Version,
+		OperationID:   dto.OperationID,
+		OperationType: internal.OperationType(dto.OperationType),
+		RuntimeID:     dto.RuntimeID,
+		Parameters:    params,
+		CreatedAt:     dto.CreatedAt,
+	}, nil
+}
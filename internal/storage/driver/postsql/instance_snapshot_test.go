@@ -0,0 +1,73 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceSnapshots(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+	storage := brokerStorage.InstanceSnapshots()
+
+	// given
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first, err := storage.Insert(internal.InstanceSnapshot{InstanceID: "i-1", OperationID: "op-1", OperationType: internal.OperationTypeProvision, RuntimeID: "r-1",
+		Parameters: internal.ProvisioningParameters{PlanID: "plan-1", Parameters: runtimeParameters("m5.xlarge")}, CreatedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	second, err := storage.Insert(internal.InstanceSnapshot{InstanceID: "i-1", OperationID: "op-2", OperationType: internal.OperationTypeUpdate, RuntimeID: "r-1",
+		Parameters: internal.ProvisioningParameters{PlanID: "plan-1", Parameters: runtimeParameters("m5.2xlarge")}, CreatedAt: now})
+	require.NoError(t, err)
+	_, err = storage.Insert(internal.InstanceSnapshot{InstanceID: "i-2", OperationID: "op-3", OperationType: internal.OperationTypeProvision, CreatedAt: now})
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, 2, second.Version)
+
+	// when
+	_, err = storage.Insert(internal.InstanceSnapshot{InstanceID: "i-1", OperationID: "op-2", OperationType: internal.OperationTypeUpdate, CreatedAt: now})
+
+	// then
+	assert.True(t, dberr.IsAlreadyExists(err))
+
+	// when
+	list, err := storage.ListByInstanceID("i-1")
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "op-1", list[0].OperationID)
+	assert.Equal(t, "m5.xlarge", *list[0].Parameters.Parameters.MachineType)
+	assert.Equal(t, "op-2", list[1].OperationID)
+
+	// when
+	atFirst, err := storage.GetAt("i-1", now.Add(-time.Minute))
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 1, atFirst.Version)
+	assert.Equal(t, internal.OperationTypeProvision, atFirst.OperationType)
+
+	// when
+	_, err = storage.GetAt("i-1", now.Add(-2*time.Hour))
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+}
+
+func runtimeParameters(machineType string) pkg.ProvisioningParametersDTO {
+	return pkg.ProvisioningParametersDTO{MachineType: &machineType}
+}
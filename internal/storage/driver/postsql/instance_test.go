@@ -8,6 +8,7 @@ import (
 	"time"
 
 	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	kebError "github.com/kyma-project/kyma-environment-broker/internal/error"
 	"github.com/kyma-project/kyma-environment-broker/internal/events"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
@@ -446,6 +447,52 @@ func TestInstance_UsingLastOperationID(t *testing.T) {
 		assert.Equal(t, fixInstances[2].InstanceID, out[0].InstanceID)
 	})
 
+	t.Run("Should list instances using the cursor", func(t *testing.T) {
+		storageCleanup, brokerStorage, err := storage.GetStorageForTest(cfg)
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		defer func() {
+			err := storageCleanup()
+			assert.NoError(t, err)
+		}()
+
+		// instances created at the same time are ordered by the ID
+		createdAt := time.Now().Truncate(time.Microsecond)
+		for i, id := range []string{"3", "1", "2"} {
+			instance := fixInstance(instanceData{val: id})
+			instance.CreatedAt = createdAt
+			if id == "3" {
+				instance.CreatedAt = createdAt.Add(time.Minute)
+			}
+			err = brokerStorage.Instances().Insert(*instance)
+			require.NoError(t, err)
+			operation := fixture.FixProvisioningOperation(fmt.Sprintf("op%d", i), id)
+			err = brokerStorage.Operations().InsertOperation(operation)
+			require.NoError(t, err)
+			err = brokerStorage.Instances().UpdateInstanceLastOperation(id, operation.ID)
+			require.NoError(t, err)
+		}
+
+		// when
+		out, count, totalCount, err := brokerStorage.Instances().List(dbmodel.InstanceFilter{PageSize: 2, After: &dbmodel.InstanceCursor{}})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Equal(t, 3, totalCount)
+		assert.Equal(t, "1", out[0].InstanceID)
+		assert.Equal(t, "2", out[1].InstanceID)
+
+		// when
+		withStates, count, totalCount, err := brokerStorage.Instances().ListWithSubaccountState(dbmodel.InstanceFilter{PageSize: 2, After: &dbmodel.InstanceCursor{CreatedAt: out[1].CreatedAt, InstanceID: out[1].InstanceID}})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, 3, totalCount)
+		assert.Equal(t, "3", withStates[0].InstanceID)
+	})
+
 	t.Run("Should list instances based on filters", func(t *testing.T) {
 		storageCleanup, brokerStorage, err := storage.GetStorageForTest(cfg)
 		require.NoError(t, err)
@@ -575,6 +622,83 @@ func TestInstance_UsingLastOperationID(t *testing.T) {
 
 	})
 
+	t.Run("Should list instances based on instance details filters and sort", func(t *testing.T) {
+		storageCleanup, brokerStorage, err := storage.GetStorageForTest(cfg)
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		defer func() {
+			err := storageCleanup()
+			assert.NoError(t, err)
+		}()
+
+		createdAt := time.Now().Truncate(time.Microsecond)
+		aws := *fixInstance(instanceData{val: "aws"})
+		aws.Provider = pkg.AWS
+		aws.CreatedAt = createdAt
+		aws.Parameters.ErsContext.CommercialModel = ptr.String("CONSUMPTION")
+		aws.Parameters.Parameters.MachineType = nil
+		aws.Parameters.Parameters.AdditionalWorkerNodePools = []pkg.AdditionalWorkerNodePool{{Name: "worker", MachineType: "m6i.large"}}
+		awsOperation := fixture.FixProvisioningOperation("op-aws", aws.InstanceID)
+		awsOperation.State = domain.Failed
+		awsOperation.ProviderValues = &internal.ProviderValues{DefaultMachineType: "m6i.large"}
+		awsOperation.KubernetesVersion = "1.33"
+		awsOperation.LastError = kebError.LastError{Component: kebError.InfrastructureManagerDependency, Reason: kebError.KEBTimeOutCode}
+
+		azure := *fixInstance(instanceData{val: "azure"})
+		azure.Provider = pkg.Azure
+		azure.CreatedAt = createdAt.Add(time.Hour)
+		azure.Parameters.ErsContext.LicenseType = ptr.String("CUSTOMER")
+		azureOperation := fixture.FixProvisioningOperation("op-azure", azure.InstanceID)
+		azureOperation.KubernetesVersion = "1.32"
+
+		for _, pair := range []struct {
+			instance  internal.Instance
+			operation internal.Operation
+		}{{aws, awsOperation}, {azure, azureOperation}} {
+			err = brokerStorage.Instances().Insert(pair.instance)
+			require.NoError(t, err)
+			err = brokerStorage.Operations().InsertOperation(pair.operation)
+			require.NoError(t, err)
+			err = brokerStorage.Instances().UpdateInstanceLastOperation(pair.instance.InstanceID, pair.operation.ID)
+			require.NoError(t, err)
+		}
+
+		for name, tc := range map[string]struct {
+			filter   dbmodel.InstanceFilter
+			expected []string
+		}{
+			"provider":          {dbmodel.InstanceFilter{Providers: []string{string(pkg.Azure)}}, []string{"azure"}},
+			"license type":      {dbmodel.InstanceFilter{LicenseTypes: []string{"CUSTOMER"}}, []string{"azure"}},
+			"commercial model":  {dbmodel.InstanceFilter{CommercialModels: []string{"CONSUMPTION"}}, []string{"aws"}},
+			"machine type":      {dbmodel.InstanceFilter{MachineTypes: []string{"m6i.large", "Standard_D8_v3"}}, []string{"aws", "azure"}},
+			"default machine":   {dbmodel.InstanceFilter{MachineTypes: []string{"m6i.large"}}, []string{"aws"}},
+			"kubernetes":        {dbmodel.InstanceFilter{KubernetesVersions: []string{"1.32"}}, []string{"azure"}},
+			"with pools":        {dbmodel.InstanceFilter{AdditionalWorkerNodePools: ptr.Bool(true)}, []string{"aws"}},
+			"without pools":     {dbmodel.InstanceFilter{AdditionalWorkerNodePools: ptr.Bool(false)}, []string{"azure"}},
+			"error component":   {dbmodel.InstanceFilter{LastErrorComponents: []string{string(kebError.InfrastructureManagerDependency)}}, []string{"aws"}},
+			"error reason":      {dbmodel.InstanceFilter{LastErrorReasons: []string{string(kebError.KEBInternalCode)}}, nil},
+			"created after":     {dbmodel.InstanceFilter{CreatedAfter: createdAt.Add(time.Minute)}, []string{"azure"}},
+			"created before":    {dbmodel.InstanceFilter{CreatedBefore: createdAt.Add(time.Hour)}, []string{"aws"}},
+			"sort by provider":  {dbmodel.InstanceFilter{Sort: []dbmodel.InstanceSort{{Field: dbmodel.InstanceSortProvider}}}, []string{"aws", "azure"}},
+			"sort by time desc": {dbmodel.InstanceFilter{Sort: []dbmodel.InstanceSort{{Field: dbmodel.InstanceSortCreatedAt, Descending: true}}}, []string{"azure", "aws"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// when
+				out, count, totalCount, err := brokerStorage.Instances().ListWithSubaccountState(tc.filter)
+
+				// then
+				require.NoError(t, err)
+				require.Equal(t, len(tc.expected), count)
+				require.Equal(t, len(tc.expected), totalCount)
+				var ids []string
+				for _, instance := range out {
+					ids = append(ids, instance.InstanceID)
+				}
+				assert.Equal(t, tc.expected, ids)
+			})
+		}
+	})
+
 	t.Run("Should list instances with proper subaccount state info", func(t *testing.T) {
 		storageCleanup, brokerStorage, err := storage.GetStorageForTest(cfg)
 		require.NoError(t, err)
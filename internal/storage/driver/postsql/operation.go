@@ -90,7 +90,7 @@ func (s *operations) UpdateOperation(op internal.Operation) (*internal.Operation
 		return nil, fmt.Errorf("while converting Operation to DTO: %w", err)
 	}
 
-	lastErr := s.update(dto)
+	lastErr := s.update(dto, op)
 	op.Version = op.Version + 1
 
 	return &op, lastErr
@@ -105,7 +105,7 @@ func (s *operations) UpdateProvisioningOperation(op internal.ProvisioningOperati
 		return nil, fmt.Errorf("while converting Operation to DTO: %w", err)
 	}
 
-	lastErr := s.update(dto)
+	lastErr := s.update(dto, op.Operation)
 	op.Version = op.Version + 1
 
 	return &op, lastErr
@@ -242,7 +242,7 @@ func (s *operations) UpdateDeprovisioningOperation(operation internal.Deprovisio
 		return nil, fmt.Errorf("while converting Operation to DTO: %w", err)
 	}
 
-	lastErr := s.update(dto)
+	lastErr := s.update(dto, operation.Operation)
 	operation.Version = operation.Version + 1
 	return &operation, lastErr
 }
@@ -434,6 +434,54 @@ func (s *operations) GetOperationsForIDs(operationIDList []string) ([]internal.O
 	return s.toOperations(operations)
 }
 
+func (s *operations) GetLastOperations(instanceIDs []string) (map[string]internal.Operation, error) {
+	if len(instanceIDs) == 0 {
+		return map[string]internal.Operation{}, nil
+	}
+	session := s.Factory.NewReadSession()
+	var dtos []dbmodel.OperationDTO
+	var lastErr dberr.Error
+	err := wait.PollUntilContextTimeout(context.Background(), defaultRetryInterval, defaultRetryTimeout, true, func(ctx context.Context) (bool, error) {
+		dtos, lastErr = session.GetLastOperations(instanceIDs)
+		if lastErr != nil {
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	operations, err := s.toOperations(dtos)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]internal.Operation, len(operations))
+	for _, operation := range operations {
+		result[operation.InstanceID] = operation
+	}
+	return result, nil
+}
+
+func (s *operations) ListNotFinishedOperations(instanceIDs []string) ([]internal.Operation, error) {
+	if len(instanceIDs) == 0 {
+		return []internal.Operation{}, nil
+	}
+	session := s.Factory.NewReadSession()
+	var dtos []dbmodel.OperationDTO
+	var lastErr dberr.Error
+	err := wait.PollUntilContextTimeout(context.Background(), defaultRetryInterval, defaultRetryTimeout, true, func(ctx context.Context) (bool, error) {
+		dtos, lastErr = session.ListNotFinishedOperations(instanceIDs)
+		if lastErr != nil {
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, lastErr
+	}
+	return s.toOperations(dtos)
+}
+
 func (s *operations) ListOperations(filter dbmodel.OperationFilter) ([]internal.Operation, int, int, error) {
 	session := s.Factory.NewReadSession()
 
@@ -1032,7 +1080,10 @@ func (s *operations) getByTypeAndInstanceID(id string, opType internal.Operation
 	return &operation, err
 }
 
-func (s *operations) update(operation dbmodel.OperationDTO) error {
+func (s *operations) update(operation dbmodel.OperationDTO, op internal.Operation) error {
+	if internal.IsOperationFinished(op.State) {
+		return s.updateFinished(operation, op)
+	}
 	session := s.Factory.NewWriteSession()
 
 	var lastErr error
@@ -1056,6 +1107,55 @@ func (s *operations) update(operation dbmodel.OperationDTO) error {
 	return lastErr
 }
 
+// updateFinished stores the operation together with the operation finished event in the outbox,
+// the event is stored only when the operation becomes finished with this update
+func (s *operations) updateFinished(operation dbmodel.OperationDTO, op internal.Operation) error {
+	event, err := internal.NewOperationFinishedOutboxEvent(op)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	_ = wait.PollUntilContextTimeout(context.Background(), defaultRetryInterval, defaultRetryTimeout, true, func(ctx context.Context) (bool, error) {
+		lastErr = s.updateFinishedInTransaction(operation, event)
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case dberr.IsNotFound(lastErr), dberr.IsConflict(lastErr):
+			return false, lastErr
+		default:
+			return false, nil
+		}
+	})
+	return lastErr
+}
+
+func (s *operations) updateFinishedInTransaction(operation dbmodel.OperationDTO, event internal.OutboxEvent) error {
+	session, dbErr := s.Factory.NewSessionWithinTransaction()
+	if dbErr != nil {
+		return dbErr
+	}
+	defer session.RollbackUnlessCommitted()
+
+	state, dbErr := session.GetOperationStateForUpdate(operation.ID)
+	if dbErr != nil {
+		return dbErr
+	}
+	if dbErr := session.UpdateOperation(operation); dbErr != nil {
+		if dberr.IsNotFound(dbErr) {
+			// the operation exists but the version is different
+			return dberr.Conflict("operation update conflict, operation ID: %s", operation.ID)
+		}
+		return dbErr
+	}
+	if !internal.IsOperationFinished(domain.LastOperationState(state)) {
+		if dbErr := session.InsertOutboxEvent(outboxEventToDTO(event)); dbErr != nil {
+			return dbErr
+		}
+	}
+	return session.Commit()
+}
+
 func (s *operations) listOperationsByInstanceIdAndType(instanceId string, operationType internal.OperationType) ([]dbmodel.OperationDTO, error) {
 	session := s.Factory.NewReadSession()
 	operations := []dbmodel.OperationDTO{}
@@ -0,0 +1,43 @@
+package postsql
+
+import (
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type OperationQueue struct {
+	postsql.Factory
+}
+
+func NewOperationQueue(sess postsql.Factory) *OperationQueue {
+	return &OperationQueue{
+		Factory: sess,
+	}
+}
+
+func (q *OperationQueue) Add(queueName, operationID string, delay time.Duration) error {
+	return q.Factory.NewWriteSession().InsertQueueItem(queueName, operationID, delay)
+}
+
+// Claim returns ID of the operation leased by the owner. The row is selected with SKIP LOCKED, so concurrent
+// replicas never claim the same operation. dberr.NotFound is returned when no operation is ready to be processed.
+func (q *OperationQueue) Claim(queueName, owner string, leaseDuration time.Duration) (string, error) {
+	return q.Factory.NewWriteSession().ClaimQueueItem(queueName, owner, leaseDuration)
+}
+
+func (q *OperationQueue) ExtendLease(operationID, owner string, leaseDuration time.Duration) error {
+	return q.Factory.NewWriteSession().ExtendQueueItemLease(operationID, owner, leaseDuration)
+}
+
+func (q *OperationQueue) Release(operationID, owner string, delay time.Duration) error {
+	return q.Factory.NewWriteSession().ReleaseQueueItem(operationID, owner, delay)
+}
+
+func (q *OperationQueue) Remove(operationID, owner string) error {
+	return q.Factory.NewWriteSession().DeleteQueueItem(operationID, owner)
+}
+
+func (q *OperationQueue) Count(queueName string) (int, error) {
+	return q.Factory.NewReadSession().CountQueueItems(queueName)
+}
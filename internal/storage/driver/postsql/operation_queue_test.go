@@ -0,0 +1,59 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationQueue(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+	queue := brokerStorage.OperationQueue()
+
+	// when
+	require.NoError(t, queue.Add("provisioning", "op-1", 0))
+	require.NoError(t, queue.Add("provisioning", "op-2", time.Hour))
+	require.NoError(t, queue.Add("provisioning", "op-1", 0))
+
+	// then
+	count, err := queue.Count("provisioning")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// when
+	id, err := queue.Claim("provisioning", "owner-1", time.Minute)
+	require.NoError(t, err)
+	_, err = queue.Claim("provisioning", "owner-2", time.Minute)
+
+	// then
+	assert.Equal(t, "op-1", id)
+	assert.True(t, dberr.IsNotFound(err))
+	assert.True(t, dberr.IsNotFound(queue.ExtendLease("op-1", "owner-2", time.Minute)))
+	assert.NoError(t, queue.ExtendLease("op-1", "owner-1", time.Minute))
+
+	// when the operation is added again while it is processed
+	require.NoError(t, queue.Add("provisioning", "op-1", 0))
+	require.NoError(t, queue.Remove("op-1", "owner-1"))
+
+	// then it is processed once more
+	id, err = queue.Claim("provisioning", "owner-2", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "op-1", id)
+
+	// when
+	require.NoError(t, queue.Release("op-1", "owner-2", time.Hour))
+	_, err = queue.Claim("provisioning", "owner-2", time.Minute)
+
+	// then
+	assert.True(t, dberr.IsNotFound(err))
+}
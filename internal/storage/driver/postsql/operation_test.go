@@ -203,6 +203,42 @@ func TestOperation(t *testing.T) {
 		assert.Equal(t, 2, len(opList))
 	})
 
+	t.Run("Canceling operations are not finished", func(t *testing.T) {
+		// given
+		storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+		require.NoError(t, err)
+		require.NotNil(t, brokerStorage)
+		defer func() {
+			err := storageCleanup()
+			assert.NoError(t, err)
+		}()
+
+		canceling := fixture.FixDeprovisioningOperation("canceling-id", "inst-id")
+		canceling.State = internal.OperationStateCanceling
+		canceled := fixture.FixDeprovisioningOperation("canceled-id", "other-inst-id")
+		canceled.State = internal.OperationStateCanceled
+
+		svc := brokerStorage.Operations()
+		require.NoError(t, svc.InsertDeprovisioningOperation(canceling))
+		require.NoError(t, svc.InsertDeprovisioningOperation(canceled))
+
+		// when
+		ops, err := svc.GetNotFinishedOperationsByType(internal.OperationTypeDeprovision)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, "canceling-id", ops[0].ID)
+
+		// when
+		ops, err = svc.ListNotFinishedOperations([]string{"inst-id", "other-inst-id"})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.Equal(t, "canceling-id", ops[0].ID)
+	})
+
 	t.Run("Upgrade Cluster", func(t *testing.T) {
 		storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
 		require.NoError(t, err)
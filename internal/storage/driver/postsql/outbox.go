@@ -0,0 +1,72 @@
+package postsql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type Outbox struct {
+	postsql.Factory
+}
+
+func NewOutbox(sess postsql.Factory) *Outbox {
+	return &Outbox{
+		Factory: sess,
+	}
+}
+
+func (o *Outbox) Insert(event internal.OutboxEvent) error {
+	return o.Factory.NewWriteSession().InsertOutboxEvent(outboxEventToDTO(event))
+}
+
+// Claim leases the oldest not delivered events of instances. The rows are selected with SKIP LOCKED, so concurrent
+// replicas never claim the same event, and the next event of an instance can be claimed only when the previous one is removed.
+func (o *Outbox) Claim(owner string, leaseDuration time.Duration, limit int) ([]internal.OutboxEvent, error) {
+	dtos, err := o.Factory.NewWriteSession().ClaimOutboxEvents(owner, leaseDuration, limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]internal.OutboxEvent, 0, len(dtos))
+	for _, dto := range dtos {
+		event := internal.OutboxEvent{
+			ID:          dto.ID,
+			InstanceID:  dto.InstanceID,
+			OperationID: dto.OperationID,
+			Type:        internal.OutboxEventType(dto.Type),
+			Payload:     []byte(dto.Payload),
+			Attempts:    dto.Attempts,
+			CreatedAt:   dto.CreatedAt,
+		}
+		if dto.DeliveredSinks != "" {
+			event.DeliveredSinks = strings.Split(dto.DeliveredSinks, ",")
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (o *Outbox) Release(id int64, owner string, delay time.Duration) error {
+	return o.Factory.NewWriteSession().ReleaseOutboxEvent(id, owner, delay)
+}
+
+func (o *Outbox) MarkDelivered(id int64, owner string, sinks []string) error {
+	return o.Factory.NewWriteSession().UpdateOutboxDeliveredSinks(id, owner, strings.Join(sinks, ","))
+}
+
+func (o *Outbox) Remove(id int64, owner string) error {
+	return o.Factory.NewWriteSession().DeleteOutboxEvent(id, owner)
+}
+
+func outboxEventToDTO(event internal.OutboxEvent) dbmodel.OutboxEventDTO {
+	return dbmodel.OutboxEventDTO{
+		InstanceID:  event.InstanceID,
+		OperationID: event.OperationID,
+		Type:        string(event.Type),
+		Payload:     string(event.Payload),
+		CreatedAt:   event.CreatedAt,
+	}
+}
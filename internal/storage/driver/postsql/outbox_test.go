@@ -0,0 +1,83 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/pivotal-cf/brokerapi/v12/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+	outbox := brokerStorage.Outbox()
+	operations := brokerStorage.Operations()
+
+	// given
+	operation := fixture.FixProvisioningOperation("op-1", "inst-1")
+	operation.State = domain.InProgress
+	require.NoError(t, operations.InsertOperation(operation))
+	require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-2", OperationID: "op-2", Type: internal.OperationFinishedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+
+	// when the operation is finished
+	operation.State = domain.Succeeded
+	updated, err := operations.UpdateOperation(operation)
+	require.NoError(t, err)
+	// and updated once again in the final state
+	_, err = operations.UpdateOperation(*updated)
+	require.NoError(t, err)
+
+	// then only one event for the operation is stored
+	events, err := outbox.Claim("owner-1", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "op-2", events[0].OperationID)
+	assert.Equal(t, "op-1", events[1].OperationID)
+	assert.Equal(t, "inst-1", events[1].InstanceID)
+
+	// when
+	claimedAgain, err := outbox.Claim("owner-2", time.Minute, 10)
+	require.NoError(t, err)
+
+	// then
+	assert.Empty(t, claimedAgain)
+	assert.True(t, dberr.IsNotFound(outbox.Remove(events[0].ID, "owner-2")))
+
+	// when
+	require.NoError(t, outbox.Remove(events[0].ID, "owner-1"))
+	require.NoError(t, outbox.Release(events[1].ID, "owner-1", time.Hour))
+	delayed, err := outbox.Claim("owner-2", time.Minute, 10)
+	require.NoError(t, err)
+
+	// then
+	assert.Empty(t, delayed)
+
+	// given
+	require.NoError(t, outbox.Insert(internal.OutboxEvent{InstanceID: "inst-3", OperationID: "op-3", Type: internal.OperationFinishedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+	claimed, err := outbox.Claim("owner-3", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Empty(t, claimed[0].DeliveredSinks)
+
+	// when
+	require.NoError(t, outbox.MarkDelivered(claimed[0].ID, "owner-3", []string{"subscribers", "webhook"}))
+	require.NoError(t, outbox.Release(claimed[0].ID, "owner-3", 0))
+	claimed, err = outbox.Claim("owner-3", time.Minute, 10)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, []string{"subscribers", "webhook"}, claimed[0].DeliveredSinks)
+	assert.True(t, dberr.IsNotFound(outbox.MarkDelivered(claimed[0].ID, "owner-2", nil)))
+}
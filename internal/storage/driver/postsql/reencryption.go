@@ -0,0 +1,140 @@
+package postsql
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+// encryptedColumn is a column which contains ciphertexts, directly or inside provisioning parameters
+type encryptedColumn struct {
+	keys      []string
+	column    string
+	reencrypt func(r *Reencryption, value string) (string, error)
+}
+
+var encryptedColumns = map[string]encryptedColumn{
+	postsql.InstancesTableName:            {keys: []string{"instance_id"}, column: "provisioning_parameters", reencrypt: (*Reencryption).reencryptParameters},
+	postsql.OperationTableName:            {keys: []string{"id"}, column: "provisioning_parameters", reencrypt: (*Reencryption).reencryptParameters},
+	postsql.BindingsTableName:             {keys: []string{"id", "instance_id"}, column: "kubeconfig", reencrypt: (*Reencryption).reencryptValue},
+	postsql.WebhookSubscriptionsTableName: {keys: []string{"id"}, column: "secret", reencrypt: (*Reencryption).reencryptValue},
+	postsql.InstanceSnapshotsTableName:    {keys: []string{"instance_id", "version"}, column: "parameters", reencrypt: (*Reencryption).reencryptValue},
+}
+
+type Reencryption struct {
+	postsql.Factory
+	cipher Cipher
+}
+
+func NewReencryption(sess postsql.Factory, cipher Cipher) *Reencryption {
+	return &Reencryption{
+		Factory: sess,
+		cipher:  cipher,
+	}
+}
+
+func (r *Reencryption) Tables() []string {
+	return []string{
+		postsql.InstancesTableName,
+		postsql.OperationTableName,
+		postsql.BindingsTableName,
+		postsql.WebhookSubscriptionsTableName,
+		postsql.InstanceSnapshotsTableName,
+	}
+}
+
+// ReencryptBatch writes values which are not encrypted with the active key again, a row is written only
+// if it was not changed since it was read, so concurrent writes are never overwritten
+func (r *Reencryption) ReencryptBatch(table string, after []string, limit int) (dbmodel.ReencryptionBatch, error) {
+	spec, found := encryptedColumns[table]
+	if !found {
+		return dbmodel.ReencryptionBatch{}, fmt.Errorf("table %s has no encrypted columns", table)
+	}
+	rows, err := r.Factory.NewReadSession().ListEncryptedValues(table, spec.keys, spec.column, after, limit)
+	if err != nil {
+		return dbmodel.ReencryptionBatch{}, dberr.Internal("while listing encrypted values of %s: %s", table, err)
+	}
+
+	result := dbmodel.ReencryptionBatch{Last: after, Scanned: len(rows)}
+	for _, row := range rows {
+		key := []string{row.Key1, row.Key2}[:len(spec.keys)]
+		result.Last = key
+		if !row.Value.Valid {
+			continue
+		}
+		reencrypted, err := spec.reencrypt(r, row.Value.String)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s %v: %w", table, key, err))
+			continue
+		}
+		if reencrypted == row.Value.String {
+			continue
+		}
+		err = r.Factory.NewWriteSession().UpdateEncryptedValue(table, spec.keys, spec.column, key, row.Value.String, reencrypted)
+		switch {
+		case dberr.IsConflict(err):
+			result.Conflicts++
+		case err != nil:
+			return result, err
+		default:
+			result.Reencrypted++
+		}
+	}
+	return result, nil
+}
+
+func (r *Reencryption) reencryptValue(value string) (string, error) {
+	if !r.cipher.NeedsReencryption([]byte(value)) {
+		return value, nil
+	}
+	decrypted, err := r.cipher.Decrypt([]byte(value))
+	if err != nil {
+		return "", fmt.Errorf("while decrypting: %w", err)
+	}
+	encrypted, err := r.cipher.Encrypt(decrypted)
+	if err != nil {
+		return "", fmt.Errorf("while encrypting: %w", err)
+	}
+	return string(encrypted), nil
+}
+
+// reencryptParameters encrypts again SM credentials and the kubeconfig, the parameters are written back only if one of them is stale
+func (r *Reencryption) reencryptParameters(value string) (string, error) {
+	var params internal.ProvisioningParameters
+	if err := json.Unmarshal([]byte(value), &params); err != nil {
+		return "", fmt.Errorf("while unmarshalling parameters: %w", err)
+	}
+	stale := r.cipher.NeedsReencryption([]byte(params.Parameters.Kubeconfig))
+	if creds := params.ErsContext.SMOperatorCredentials; creds != nil {
+		stale = stale || r.cipher.NeedsReencryption([]byte(creds.ClientID)) || r.cipher.NeedsReencryption([]byte(creds.ClientSecret))
+	}
+	if !stale {
+		return value, nil
+	}
+
+	if err := r.cipher.DecryptSMCreds(&params); err != nil {
+		return "", fmt.Errorf("while decrypting SM credentials: %w", err)
+	}
+	// the kubeconfig of own cluster instances can be stored in plain text, it is encrypted now,
+	// a value which can be a ciphertext must be decrypted, otherwise the row is left untouched
+	if r.cipher.IsEncrypted([]byte(params.Parameters.Kubeconfig)) {
+		if err := r.cipher.DecryptKubeconfig(&params); err != nil {
+			return "", err
+		}
+	}
+	if err := r.cipher.EncryptSMCreds(&params); err != nil {
+		return "", fmt.Errorf("while encrypting SM credentials: %w", err)
+	}
+	if err := r.cipher.EncryptKubeconfig(&params); err != nil {
+		return "", fmt.Errorf("while encrypting kubeconfig: %w", err)
+	}
+	reencrypted, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("while marshalling parameters: %w", err)
+	}
+	return string(reencrypted), nil
+}
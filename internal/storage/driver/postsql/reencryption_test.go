@@ -0,0 +1,69 @@
+package postsql_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/events"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReencryption(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+
+	// given values written with the secret key only
+	instance := fixture.FixInstance("instance-1")
+	instance.Parameters.ErsContext.SMOperatorCredentials = &internal.ServiceManagerOperatorCredentials{ClientID: "client-id", ClientSecret: "client-secret"}
+	require.NoError(t, brokerStorage.Instances().Insert(instance))
+	binding := fixture.FixBindingWithInstanceID("binding-1", instance.InstanceID)
+	require.NoError(t, brokerStorage.Bindings().Insert(&binding))
+
+	cfg := brokerStorageDatabaseTestConfig()
+	cfg.EncryptionKeys = "k1=0123456789abcdef0123456789abcdef"
+	cfg.ActiveKeyID = "k1"
+	cipher, err := storage.NewEncrypterFromConfig(cfg)
+	require.NoError(t, err)
+	rotatedStorage, conn, err := storage.NewFromConfig(cfg, events.Config{}, cipher)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// when
+	reencrypted := 0
+	for _, table := range rotatedStorage.Reencryption().Tables() {
+		batch, err := rotatedStorage.Reencryption().ReencryptBatch(table, nil, 100)
+		require.NoError(t, err)
+		assert.Empty(t, batch.Errors)
+		reencrypted += batch.Reencrypted
+	}
+
+	// then
+	assert.Equal(t, 2, reencrypted)
+	gotInstance, err := rotatedStorage.Instances().GetByID(instance.InstanceID)
+	require.NoError(t, err)
+	assert.Equal(t, "client-secret", gotInstance.Parameters.ErsContext.SMOperatorCredentials.ClientSecret)
+	gotBinding, err := rotatedStorage.Bindings().Get(instance.InstanceID, binding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, binding.Kubeconfig, gotBinding.Kubeconfig)
+
+	// the secret key alone cannot decrypt values written with the active key
+	_, err = brokerStorage.Instances().GetByID(instance.InstanceID)
+	assert.Error(t, err)
+
+	// when
+	batch, err := rotatedStorage.Reencryption().ReencryptBatch(rotatedStorage.Reencryption().Tables()[0], nil, 100)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 1, batch.Scanned)
+	assert.Zero(t, batch.Reencrypted)
+}
@@ -0,0 +1,166 @@
+package postsql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/postsql"
+)
+
+type Webhooks struct {
+	postsql.Factory
+	cipher Cipher
+}
+
+func NewWebhooks(sess postsql.Factory, cipher Cipher) *Webhooks {
+	return &Webhooks{
+		Factory: sess,
+		cipher:  cipher,
+	}
+}
+
+func (w *Webhooks) InsertSubscription(subscription internal.WebhookSubscription) error {
+	dto, err := w.toSubscriptionDTO(subscription)
+	if err != nil {
+		return err
+	}
+	return w.Factory.NewWriteSession().InsertWebhookSubscription(dto)
+}
+
+func (w *Webhooks) GetSubscription(id string) (*internal.WebhookSubscription, error) {
+	dto, err := w.Factory.NewReadSession().GetWebhookSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	subscription, decryptErr := w.toSubscription(dto)
+	if decryptErr != nil {
+		return nil, decryptErr
+	}
+	return &subscription, nil
+}
+
+func (w *Webhooks) ListSubscriptions(filter dbmodel.WebhookSubscriptionFilter) ([]internal.WebhookSubscription, int, error) {
+	dtos, totalCount, err := w.Factory.NewReadSession().ListWebhookSubscriptions(filter)
+	if err != nil {
+		return nil, -1, dberr.Internal("while listing webhook subscriptions: %s", err)
+	}
+	subscriptions := make([]internal.WebhookSubscription, 0, len(dtos))
+	for _, dto := range dtos {
+		subscription, err := w.toSubscription(dto)
+		if err != nil {
+			return nil, -1, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, totalCount, nil
+}
+
+func (w *Webhooks) DeleteSubscription(id string) error {
+	return w.Factory.NewWriteSession().DeleteWebhookSubscription(id)
+}
+
+func (w *Webhooks) InsertDelivery(delivery internal.WebhookDelivery) error {
+	return w.Factory.NewWriteSession().InsertWebhookDelivery(dbmodel.WebhookDeliveryDTO{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		Payload:        string(delivery.Payload),
+		State:          string(internal.WebhookDeliveryPending),
+		CreatedAt:      delivery.CreatedAt,
+	})
+}
+
+// ClaimDeliveries leases pending notifications which are due, the rows are selected with SKIP LOCKED,
+// so concurrent replicas never claim the same notification
+func (w *Webhooks) ClaimDeliveries(owner string, leaseDuration time.Duration, limit int) ([]internal.WebhookDelivery, error) {
+	dtos, err := w.Factory.NewWriteSession().ClaimWebhookDeliveries(owner, leaseDuration, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toDeliveries(dtos), nil
+}
+
+func (w *Webhooks) RetryDelivery(id int64, owner string, delay time.Duration, lastError string) error {
+	return w.Factory.NewWriteSession().RetryWebhookDelivery(id, owner, delay, lastError)
+}
+
+func (w *Webhooks) MarkDeliveryDead(id int64, owner string, lastError string) error {
+	return w.Factory.NewWriteSession().MarkWebhookDeliveryDead(id, owner, lastError)
+}
+
+func (w *Webhooks) RemoveDelivery(id int64, owner string) error {
+	return w.Factory.NewWriteSession().DeleteWebhookDelivery(id, owner)
+}
+
+func (w *Webhooks) ListDeliveries(filter dbmodel.WebhookDeliveryFilter) ([]internal.WebhookDelivery, int, error) {
+	dtos, totalCount, err := w.Factory.NewReadSession().ListWebhookDeliveries(filter)
+	if err != nil {
+		return nil, -1, dberr.Internal("while listing webhook deliveries of subscription %s: %s", filter.SubscriptionID, err)
+	}
+	return toDeliveries(dtos), totalCount, nil
+}
+
+func (w *Webhooks) toSubscriptionDTO(subscription internal.WebhookSubscription) (dbmodel.WebhookSubscriptionDTO, error) {
+	encrypted, err := w.cipher.Encrypt([]byte(subscription.Secret))
+	if err != nil {
+		return dbmodel.WebhookSubscriptionDTO{}, fmt.Errorf("while encrypting the webhook secret: %w", err)
+	}
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, t := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+	return dbmodel.WebhookSubscriptionDTO{
+		ID:              subscription.ID,
+		GlobalAccountID: subscription.GlobalAccountID,
+		SubAccountID:    subscription.SubAccountID,
+		URL:             subscription.URL,
+		Secret:          string(encrypted),
+		EventTypes:      strings.Join(eventTypes, ","),
+		CreatedAt:       subscription.CreatedAt,
+	}, nil
+}
+
+func (w *Webhooks) toSubscription(dto dbmodel.WebhookSubscriptionDTO) (internal.WebhookSubscription, error) {
+	decrypted, err := w.cipher.Decrypt([]byte(dto.Secret))
+	if err != nil {
+		return internal.WebhookSubscription{}, fmt.Errorf("while decrypting the webhook secret: %w", err)
+	}
+	var eventTypes []internal.OutboxEventType
+	for _, t := range strings.Split(dto.EventTypes, ",") {
+		if t != "" {
+			eventTypes = append(eventTypes, internal.OutboxEventType(t))
+		}
+	}
+	return internal.WebhookSubscription{
+		ID:              dto.ID,
+		GlobalAccountID: dto.GlobalAccountID,
+		SubAccountID:    dto.SubAccountID,
+		URL:             dto.URL,
+		Secret:          string(decrypted),
+		EventTypes:      eventTypes,
+		CreatedAt:       dto.CreatedAt,
+	}, nil
+}
+
+func toDeliveries(dtos []dbmodel.WebhookDeliveryDTO) []internal.WebhookDelivery {
+	deliveries := make([]internal.WebhookDelivery, 0, len(dtos))
+	for _, dto := range dtos {
+		deliveries = append(deliveries, internal.WebhookDelivery{
+			ID:             dto.ID,
+			SubscriptionID: dto.SubscriptionID,
+			EventID:        dto.EventID,
+			EventType:      internal.OutboxEventType(dto.EventType),
+			Payload:        []byte(dto.Payload),
+			State:          internal.WebhookDeliveryState(dto.State),
+			Attempts:       dto.Attempts,
+			LastError:      dto.LastError,
+			CreatedAt:      dto.CreatedAt,
+			UpdatedAt:      dto.UpdatedAt,
+		})
+	}
+	return deliveries
+}
@@ -0,0 +1,80 @@
+package postsql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooks(t *testing.T) {
+	storageCleanup, brokerStorage, err := GetStorageForDatabaseTests()
+	require.NoError(t, err)
+	require.NotNil(t, brokerStorage)
+	defer func() {
+		err := storageCleanup()
+		assert.NoError(t, err)
+	}()
+	webhooks := brokerStorage.Webhooks()
+
+	// given
+	require.NoError(t, webhooks.InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1", URL: "https://example.com", Secret: "secret",
+		EventTypes: []internal.OutboxEventType{internal.BindingCreatedOutboxEvent, internal.BindingDeletedOutboxEvent}, CreatedAt: time.Now()}))
+	require.NoError(t, webhooks.InsertSubscription(internal.WebhookSubscription{ID: "sub-2", GlobalAccountID: "ga-1", URL: "https://example.com", Secret: "secret", CreatedAt: time.Now()}))
+
+	// when
+	subscription, err := webhooks.GetSubscription("sub-1")
+	require.NoError(t, err)
+	listed, _, err := webhooks.ListSubscriptions(dbmodel.WebhookSubscriptionFilter{GlobalAccountID: "ga-1"})
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, "secret", subscription.Secret)
+	assert.Equal(t, []internal.OutboxEventType{internal.BindingCreatedOutboxEvent, internal.BindingDeletedOutboxEvent}, subscription.EventTypes)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "sub-2", listed[0].ID)
+
+	// when the same event is stored twice
+	for i := 0; i < 2; i++ {
+		require.NoError(t, webhooks.InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1, EventType: internal.BindingCreatedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+	}
+	require.NoError(t, webhooks.InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 2, EventType: internal.BindingDeletedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+	claimed, err := webhooks.ClaimDeliveries("owner-1", time.Minute, 10)
+	require.NoError(t, err)
+	claimedAgain, err := webhooks.ClaimDeliveries("owner-2", time.Minute, 10)
+	require.NoError(t, err)
+
+	// then
+	require.Len(t, claimed, 2)
+	assert.Empty(t, claimedAgain)
+
+	// when
+	assert.True(t, dberr.IsNotFound(webhooks.RemoveDelivery(claimed[0].ID, "owner-2")))
+	require.NoError(t, webhooks.RetryDelivery(claimed[0].ID, "owner-1", time.Hour, "timeout"))
+	require.NoError(t, webhooks.MarkDeliveryDead(claimed[1].ID, "owner-1", "connection refused"))
+	delayed, err := webhooks.ClaimDeliveries("owner-2", time.Minute, 10)
+	require.NoError(t, err)
+	dead, _, err := webhooks.ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1", State: string(internal.WebhookDeliveryDead)})
+	require.NoError(t, err)
+
+	// then
+	assert.Empty(t, delayed)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "connection refused", dead[0].LastError)
+	assert.Equal(t, 1, dead[0].Attempts)
+
+	// when
+	require.NoError(t, webhooks.DeleteSubscription("sub-1"))
+
+	// then
+	deliveries, _, err := webhooks.ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1"})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+	_, err = webhooks.GetSubscription("sub-1")
+	assert.True(t, dberr.IsNotFound(err))
+}
@@ -1,25 +1,150 @@
 package storage
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"regexp"
+	"strings"
 
 	"github.com/kyma-project/kyma-environment-broker/internal"
 )
 
+// keyIDSeparator separates the key ID from the ciphertext, it is not a part of the base64 alphabet,
+// so ciphertexts written before key IDs were introduced are recognized by its absence
+const keyIDSeparator = ':'
+
+var keyIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// NewEncrypter returns the encrypter which uses only the given key in the AES CFB mode and writes ciphertexts without key ID
 func NewEncrypter(secretKey string) *Encrypter {
 	return &Encrypter{key: []byte(secretKey)}
 }
 
+// NewEncrypterFromConfig returns the encrypter with the keyring from the configuration. When the active key ID is set,
+// new ciphertexts are encrypted with the active key in the AES GCM mode and prefixed with the key ID.
+// Ciphertexts without key ID are decrypted with the secret key in the AES CFB mode.
+func NewEncrypterFromConfig(cfg Config) (*Encrypter, error) {
+	keys, err := parseEncryptionKeys(cfg.EncryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ActiveKeyID != "" {
+		if _, found := keys[cfg.ActiveKeyID]; !found {
+			return nil, fmt.Errorf("active encryption key %q is not defined in the encryption keys", cfg.ActiveKeyID)
+		}
+	}
+	return &Encrypter{
+		key:         []byte(cfg.SecretKey),
+		keys:        keys,
+		activeKeyID: cfg.ActiveKeyID,
+	}, nil
+}
+
+// parseEncryptionKeys parses keys in the format id1=key1,id2=key2
+func parseEncryptionKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, key, found := strings.Cut(entry, "=")
+		if !found || !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid encryption key entry, expected <id>=<key> with the ID containing only letters, digits, '.', '_' and '-'")
+		}
+		if _, err := aes.NewCipher([]byte(key)); err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		if _, duplicated := keys[id]; duplicated {
+			return nil, fmt.Errorf("encryption key %q is defined twice", id)
+		}
+		keys[id] = []byte(key)
+	}
+	return keys, nil
+}
+
 type Encrypter struct {
-	key []byte
+	// key decrypts ciphertexts without key ID and encrypts new ones when there is no active key
+	key         []byte
+	keys        map[string][]byte
+	activeKeyID string
 }
 
 func (e *Encrypter) Encrypt(obj []byte) ([]byte, error) {
+	if e.activeKeyID == "" {
+		return e.encryptCFB(obj)
+	}
+	gcm, err := newGCM(e.keys[e.activeKeyID])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, obj, []byte(e.activeKeyID))
+	return []byte(e.activeKeyID + string(keyIDSeparator) + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func (e *Encrypter) Decrypt(obj []byte) ([]byte, error) {
+	keyID, ciphertext, found := bytes.Cut(obj, []byte{keyIDSeparator})
+	if !found {
+		return e.decryptCFB(obj)
+	}
+	key, known := e.keys[string(keyID)]
+	if !known {
+		return nil, fmt.Errorf("unknown encryption key %q", keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(ciphertext))
+	if err != nil {
+		return nil, fmt.Errorf("while decoding input object: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("cipher text is too short")
+	}
+	data, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], keyID)
+	if err != nil {
+		return nil, fmt.Errorf("while decrypting with the key %q: %w", keyID, err)
+	}
+	return data, nil
+}
+
+// NeedsReencryption returns true if the ciphertext is not encrypted with the active key,
+// without the active key new ciphertexts are written without key ID and existing ones are kept
+func (e *Encrypter) NeedsReencryption(obj []byte) bool {
+	if e.activeKeyID == "" || len(obj) == 0 {
+		return false
+	}
+	return !bytes.HasPrefix(obj, []byte(e.activeKeyID+string(keyIDSeparator)))
+}
+
+// IsEncrypted returns false only if the value cannot be a ciphertext, which is an empty value or a value which is
+// neither base64 encoded nor base64 encoded with a key ID prefix, for example a kubeconfig stored in plain text
+func (e *Encrypter) IsEncrypted(obj []byte) bool {
+	if len(obj) == 0 {
+		return false
+	}
+	keyID, ciphertext, found := bytes.Cut(obj, []byte{keyIDSeparator})
+	if found && !keyIDPattern.Match(keyID) {
+		return false
+	}
+	if !found {
+		ciphertext = obj
+	}
+	_, err := base64.StdEncoding.DecodeString(string(ciphertext))
+	return err == nil
+}
+
+func (e *Encrypter) encryptCFB(obj []byte) ([]byte, error) {
 	block, err := aes.NewCipher(e.key)
 	if err != nil {
 		return nil, err
@@ -36,7 +161,7 @@ func (e *Encrypter) Encrypt(obj []byte) ([]byte, error) {
 	return []byte(base64.StdEncoding.EncodeToString(bytes)), nil
 }
 
-func (e *Encrypter) Decrypt(obj []byte) ([]byte, error) {
+func (e *Encrypter) decryptCFB(obj []byte) ([]byte, error) {
 	obj, err := base64.StdEncoding.DecodeString(string(obj))
 	if err != nil {
 		return nil, fmt.Errorf("while decoding input object: %w", err)
@@ -59,6 +184,14 @@ func (e *Encrypter) Decrypt(obj []byte) ([]byte, error) {
 	return data, nil
 }
 
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
 func (e *Encrypter) EncryptSMCreds(provisioningParameters *internal.ProvisioningParameters) error {
 	if provisioningParameters.ErsContext.SMOperatorCredentials == nil {
 		return nil
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,3 +71,111 @@ func TestNewEncrypter(t *testing.T) {
 	})
 
 }
+
+func TestEncrypterWithKeyring(t *testing.T) {
+	legacyKey := rand.String(32)
+	oldKey := rand.String(32)
+	newKey := rand.String(32)
+	data := []byte(`{"clientSecret":"s3cr3t"}`)
+
+	legacy := NewEncrypter(legacyKey)
+	beforeRotation, err := NewEncrypterFromConfig(Config{SecretKey: legacyKey, EncryptionKeys: "k1=" + oldKey, ActiveKeyID: "k1"})
+	require.NoError(t, err)
+	afterRotation, err := NewEncrypterFromConfig(Config{SecretKey: legacyKey, EncryptionKeys: "k1=" + oldKey + ",k2=" + newKey, ActiveKeyID: "k2"})
+	require.NoError(t, err)
+
+	t.Run("should prefix ciphertexts with the active key ID", func(t *testing.T) {
+		// when
+		enc, err := afterRotation.Encrypt(data)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(enc), "k2:"))
+		assert.False(t, afterRotation.NeedsReencryption(enc))
+		dec, err := afterRotation.Decrypt(enc)
+		require.NoError(t, err)
+		assert.Equal(t, data, dec)
+	})
+
+	t.Run("should decrypt ciphertexts of previous keys", func(t *testing.T) {
+		// given
+		legacyEnc, err := legacy.Encrypt(data)
+		require.NoError(t, err)
+		oldEnc, err := beforeRotation.Encrypt(data)
+		require.NoError(t, err)
+
+		for _, enc := range [][]byte{legacyEnc, oldEnc} {
+			// when
+			dec, err := afterRotation.Decrypt(enc)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, data, dec)
+			assert.True(t, afterRotation.NeedsReencryption(enc))
+		}
+	})
+
+	t.Run("should reject unknown keys and modified ciphertexts", func(t *testing.T) {
+		// given
+		enc, err := afterRotation.Encrypt(data)
+		require.NoError(t, err)
+		modified := []byte(strings.Replace(string(enc), "k2:", "k1:", 1))
+
+		// when
+		_, unknownErr := beforeRotation.Decrypt(enc)
+		_, modifiedErr := afterRotation.Decrypt(modified)
+
+		// then
+		assert.ErrorContains(t, unknownErr, `unknown encryption key "k2"`)
+		assert.Error(t, modifiedErr)
+	})
+
+	t.Run("should keep writing legacy ciphertexts without the active key", func(t *testing.T) {
+		// given
+		e, err := NewEncrypterFromConfig(Config{SecretKey: legacyKey, EncryptionKeys: "k1=" + oldKey})
+		require.NoError(t, err)
+
+		// when
+		enc, err := e.Encrypt(data)
+
+		// then
+		require.NoError(t, err)
+		assert.NotContains(t, string(enc), ":")
+		assert.False(t, e.NeedsReencryption(enc))
+		dec, err := legacy.Decrypt(enc)
+		require.NoError(t, err)
+		assert.Equal(t, data, dec)
+	})
+
+	t.Run("should tell ciphertexts from plain text", func(t *testing.T) {
+		// given
+		legacyEnc, err := legacy.Encrypt(data)
+		require.NoError(t, err)
+		enc, err := afterRotation.Encrypt(data)
+		require.NoError(t, err)
+		unknownKeyEnc := []byte(strings.Replace(string(enc), "k2:", "k3:", 1))
+
+		// then
+		for _, ciphertext := range [][]byte{legacyEnc, enc, unknownKeyEnc} {
+			assert.True(t, afterRotation.IsEncrypted(ciphertext), string(ciphertext))
+		}
+		for _, plaintext := range []string{"", "apiVersion: v1\nkind: Config\n", `{"apiVersion":"v1"}`} {
+			assert.False(t, afterRotation.IsEncrypted([]byte(plaintext)), plaintext)
+		}
+	})
+
+	t.Run("should validate the configuration", func(t *testing.T) {
+		for name, cfg := range map[string]Config{
+			"unknown active key": {EncryptionKeys: "k1=" + oldKey, ActiveKeyID: "k3"},
+			"invalid key length": {EncryptionKeys: "k1=short", ActiveKeyID: "k1"},
+			"invalid key ID":     {EncryptionKeys: "k:1=" + oldKey},
+			"missing key":        {EncryptionKeys: "k1"},
+			"duplicated key":     {EncryptionKeys: "k1=" + oldKey + ",k1=" + newKey},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := NewEncrypterFromConfig(cfg)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"time"
 
+	"github.com/kyma-project/kyma-environment-broker/common/campaigns"
 	"github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
@@ -54,6 +55,10 @@ type Operations interface {
 	GetOperationStatsByPlan() (map[string]internal.OperationStats, error)
 	GetOperationStatsByPlanV2() ([]internal.OperationStatsV2, error)
 	GetOperationsForIDs(operationIDList []string) ([]internal.Operation, error)
+	// GetLastOperations returns the last operation of every given instance by the instance ID, as GetLastOperation does for one instance
+	GetLastOperations(instanceIDs []string) (map[string]internal.Operation, error)
+	// ListNotFinishedOperations returns operations of the given instances which are in progress, pending or canceling
+	ListNotFinishedOperations(instanceIDs []string) ([]internal.Operation, error)
 	ListOperations(filter dbmodel.OperationFilter) ([]internal.Operation, int, int, error)
 
 	InsertOperation(operation internal.Operation) error
@@ -119,6 +124,96 @@ type Bindings interface {
 }
 
 type Actions interface {
-	InsertAction(actionType runtime.ActionType, instanceID, message, oldValue, newValue string) error
+	InsertAction(action runtime.Action) error
 	ListActionsByInstanceID(instanceID string) ([]runtime.Action, error)
+	// ListActions returns a page of actions matching the filter, starting with the newest one, and the number of all matching actions
+	ListActions(filter dbmodel.ActionFilter) ([]runtime.Action, int, error)
+}
+
+// OperationQueue keeps operations waiting for processing. An operation is claimed by one owner at a time,
+// the claim (lease) expires if the owner does not extend it, which makes the operation available for other owners.
+type OperationQueue interface {
+	Add(queueName, operationID string, delay time.Duration) error
+	Claim(queueName, owner string, leaseDuration time.Duration) (string, error)
+	ExtendLease(operationID, owner string, leaseDuration time.Duration) error
+	Release(operationID, owner string, delay time.Duration) error
+	Remove(operationID, owner string) error
+	Count(queueName string) (int, error)
+}
+
+// Outbox keeps events stored together with the operation changes which caused them, the operation finished event
+// is stored by the operations storage in the same transaction as the operation. Only the oldest event of an instance
+// can be claimed, which keeps the delivery order per instance, also when many replicas deliver events.
+type Outbox interface {
+	Insert(event internal.OutboxEvent) error
+	Claim(owner string, leaseDuration time.Duration, limit int) ([]internal.OutboxEvent, error)
+	Release(id int64, owner string, delay time.Duration) error
+	// MarkDelivered stores names of sinks which accepted the event, only the remaining sinks get the event again
+	MarkDelivered(id int64, owner string, sinks []string) error
+	Remove(id int64, owner string) error
+}
+
+// Webhooks keeps webhook subscriptions and notifications to be delivered to subscribed endpoints. A notification which
+// could not be delivered after all attempts is kept in the dead state.
+type Webhooks interface {
+	InsertSubscription(subscription internal.WebhookSubscription) error
+	GetSubscription(id string) (*internal.WebhookSubscription, error)
+	// ListSubscriptions returns a page of subscriptions filtered by the given accounts together with the total count,
+	// an empty account matches any account, all subscriptions are returned if the page is not set
+	ListSubscriptions(filter dbmodel.WebhookSubscriptionFilter) ([]internal.WebhookSubscription, int, error)
+	DeleteSubscription(id string) error
+
+	// InsertDelivery stores the notification, a notification about the same event for the same subscription is stored once
+	InsertDelivery(delivery internal.WebhookDelivery) error
+	ClaimDeliveries(owner string, leaseDuration time.Duration, limit int) ([]internal.WebhookDelivery, error)
+	RetryDelivery(id int64, owner string, delay time.Duration, lastError string) error
+	MarkDeliveryDead(id int64, owner string, lastError string) error
+	RemoveDelivery(id int64, owner string) error
+	// ListDeliveries returns a page of notifications of the subscription together with the total count
+	ListDeliveries(filter dbmodel.WebhookDeliveryFilter) ([]internal.WebhookDelivery, int, error)
+}
+
+// InstanceSnapshots keeps versioned copies of instance parameters, snapshots are never updated
+type InstanceSnapshots interface {
+	// Insert stores the snapshot with the next version of the instance and returns the stored snapshot,
+	// the dberr.AlreadyExists error is returned when the snapshot of the operation is already stored
+	Insert(snapshot internal.InstanceSnapshot) (*internal.InstanceSnapshot, error)
+	// ListByInstanceID returns snapshots of the instance ordered by the version
+	ListByInstanceID(instanceID string) ([]internal.InstanceSnapshot, error)
+	// GetAt returns the last snapshot created at or before the given time
+	GetAt(instanceID string, at time.Time) (*internal.InstanceSnapshot, error)
+}
+
+// InstanceLabels keeps labels set by users on instances, labels are removed together with the instance
+type InstanceLabels interface {
+	// Set replaces all labels of the instance
+	Set(instanceID string, labels map[string]string) error
+	// Get returns labels of the instance, an empty map is returned if the instance has no labels
+	Get(instanceID string) (map[string]string, error)
+}
+
+// Reencryption encrypts stored secrets again with the active encryption key
+type Reencryption interface {
+	// Tables returns tables with encrypted columns
+	Tables() []string
+	// ReencryptBatch processes up to limit rows of the table ordered by the primary key, starting after the given key
+	ReencryptBatch(table string, after []string, limit int) (dbmodel.ReencryptionBatch, error)
+}
+
+// Campaigns keeps campaigns updating many instances and the state of the update of every instance. A campaign is
+// processed by one KEB replica at a time, the replica holds the lease of the campaign.
+type Campaigns interface {
+	// Insert stores the campaign together with its instances
+	Insert(campaign internal.Campaign, instances []internal.CampaignInstance) error
+	Get(id string) (*internal.Campaign, error)
+	// List returns the page of campaigns ordered by the creation time and the number of all campaigns matching the filter
+	List(filter dbmodel.CampaignFilter) ([]internal.Campaign, int, error)
+	// Update stores the campaign if its version did not change and returns the campaign with the incremented version
+	Update(campaign internal.Campaign) (*internal.Campaign, error)
+	// Claim leases the pending and in progress campaigns which are not leased by another owner
+	Claim(owner string, leaseDuration time.Duration) ([]internal.Campaign, error)
+
+	// ListInstances returns instances of the campaign ordered by the wave, an empty state matches any state
+	ListInstances(campaignID string, state campaigns.InstanceState) ([]internal.CampaignInstance, error)
+	UpdateInstance(instance internal.CampaignInstance) error
 }
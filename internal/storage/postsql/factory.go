@@ -35,6 +35,8 @@ type ReadSession interface {
 	GetOperationsByTypeAndInstanceID(inID string, opType internal.OperationType) ([]dbmodel.OperationDTO, dberr.Error)
 	GetOperationsByInstanceID(inID string) ([]dbmodel.OperationDTO, dberr.Error)
 	GetOperationsForIDs(opIdList []string) ([]dbmodel.OperationDTO, dberr.Error)
+	GetLastOperations(instanceIDs []string) ([]dbmodel.OperationDTO, dberr.Error)
+	ListNotFinishedOperations(instanceIDs []string) ([]dbmodel.OperationDTO, dberr.Error)
 	ListOperations(filter dbmodel.OperationFilter) ([]dbmodel.OperationDTO, int, int, error)
 	ListOperationsByType(operationType internal.OperationType) ([]dbmodel.OperationDTO, dberr.Error)
 	GetOperationStats() ([]dbmodel.OperationStatEntry, error)
@@ -62,6 +64,18 @@ type ReadSession interface {
 	ListExpiredBindings() ([]dbmodel.BindingDTO, error)
 	GetBindingsStatistics() (dbmodel.BindingStatsDTO, error)
 	ListActions(instanceID string) ([]runtime.Action, error)
+	ListActionsByFilter(filter dbmodel.ActionFilter) ([]runtime.Action, int, error)
+	CountQueueItems(queueName string) (int, error)
+	GetWebhookSubscription(id string) (dbmodel.WebhookSubscriptionDTO, dberr.Error)
+	ListWebhookSubscriptions(filter dbmodel.WebhookSubscriptionFilter) ([]dbmodel.WebhookSubscriptionDTO, int, error)
+	ListWebhookDeliveries(filter dbmodel.WebhookDeliveryFilter) ([]dbmodel.WebhookDeliveryDTO, int, error)
+	GetCampaign(id string) (dbmodel.CampaignDTO, dberr.Error)
+	ListCampaigns(filter dbmodel.CampaignFilter) ([]dbmodel.CampaignDTO, int, error)
+	ListCampaignInstances(campaignID, state string) ([]dbmodel.CampaignInstanceDTO, error)
+	ListInstanceSnapshots(instanceID string) ([]dbmodel.InstanceSnapshotDTO, error)
+	GetInstanceSnapshotAt(instanceID string, at time.Time) (dbmodel.InstanceSnapshotDTO, dberr.Error)
+	ListInstanceLabels(instanceID string) ([]dbmodel.InstanceLabelDTO, error)
+	ListEncryptedValues(table string, keys []string, column string, after []string, limit int) ([]dbmodel.EncryptedValueDTO, error)
 }
 
 //go:generate mockery --name=WriteSession
@@ -81,7 +95,34 @@ type WriteSession interface {
 	UpdateBinding(binding dbmodel.BindingDTO) dberr.Error
 	DeleteBinding(instanceID, bindingID string) dberr.Error
 	UpdateInstanceLastOperation(instanceID, operationID string) error
-	InsertAction(actionType runtime.ActionType, instanceID, message, oldValue, newValue string) dberr.Error
+	InsertAction(action runtime.Action) dberr.Error
+	InsertQueueItem(queueName, operationID string, delay time.Duration) dberr.Error
+	ClaimQueueItem(queueName, owner string, leaseDuration time.Duration) (string, dberr.Error)
+	ExtendQueueItemLease(operationID, owner string, leaseDuration time.Duration) dberr.Error
+	ReleaseQueueItem(operationID, owner string, delay time.Duration) dberr.Error
+	DeleteQueueItem(operationID, owner string) dberr.Error
+	GetOperationStateForUpdate(operationID string) (string, dberr.Error)
+	InsertOutboxEvent(event dbmodel.OutboxEventDTO) dberr.Error
+	ClaimOutboxEvents(owner string, leaseDuration time.Duration, limit int) ([]dbmodel.OutboxEventDTO, dberr.Error)
+	ReleaseOutboxEvent(id int64, owner string, delay time.Duration) dberr.Error
+	UpdateOutboxDeliveredSinks(id int64, owner string, sinks string) dberr.Error
+	DeleteOutboxEvent(id int64, owner string) dberr.Error
+	InsertWebhookSubscription(subscription dbmodel.WebhookSubscriptionDTO) dberr.Error
+	DeleteWebhookSubscription(id string) dberr.Error
+	InsertWebhookDelivery(delivery dbmodel.WebhookDeliveryDTO) dberr.Error
+	ClaimWebhookDeliveries(owner string, leaseDuration time.Duration, limit int) ([]dbmodel.WebhookDeliveryDTO, dberr.Error)
+	RetryWebhookDelivery(id int64, owner string, delay time.Duration, lastError string) dberr.Error
+	MarkWebhookDeliveryDead(id int64, owner string, lastError string) dberr.Error
+	DeleteWebhookDelivery(id int64, owner string) dberr.Error
+	InsertCampaign(campaign dbmodel.CampaignDTO) dberr.Error
+	InsertCampaignInstances(instances []dbmodel.CampaignInstanceDTO) dberr.Error
+	UpdateCampaign(campaign dbmodel.CampaignDTO) dberr.Error
+	ClaimCampaigns(owner string, leaseDuration time.Duration, states []string) ([]dbmodel.CampaignDTO, dberr.Error)
+	UpdateCampaignInstance(instance dbmodel.CampaignInstanceDTO) dberr.Error
+	InsertInstanceSnapshot(snapshot dbmodel.InstanceSnapshotDTO) (int, dberr.Error)
+	DeleteInstanceLabels(instanceID string) dberr.Error
+	InsertInstanceLabels(labels []dbmodel.InstanceLabelDTO) dberr.Error
+	UpdateEncryptedValue(table string, keys []string, column string, key []string, oldValue, newValue string) dberr.Error
 }
 
 type Transaction interface {
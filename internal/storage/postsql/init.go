@@ -12,14 +12,22 @@ import (
 )
 
 const (
-	schemaName                 = "public"
-	InstancesTableName         = "instances"
-	OperationTableName         = "operations"
-	SubaccountStatesTableName  = "subaccount_states"
-	CreatedAtField             = "created_at"
-	InstancesArchivedTableName = "instances_archived"
-	BindingsTableName          = "bindings"
-	ActionsTableName           = "actions"
+	schemaName                    = "public"
+	InstancesTableName            = "instances"
+	OperationTableName            = "operations"
+	SubaccountStatesTableName     = "subaccount_states"
+	CreatedAtField                = "created_at"
+	InstancesArchivedTableName    = "instances_archived"
+	BindingsTableName             = "bindings"
+	ActionsTableName              = "actions"
+	OperationQueueTableName       = "operation_queue"
+	OutboxTableName               = "outbox"
+	WebhookSubscriptionsTableName = "webhook_subscriptions"
+	WebhookDeliveriesTableName    = "webhook_deliveries"
+	CampaignsTableName            = "campaigns"
+	CampaignInstancesTableName    = "campaign_instances"
+	InstanceSnapshotsTableName    = "instance_snapshots"
+	InstanceLabelsTableName       = "instance_labels"
 )
 
 // InitializeDatabase opens database connection and initializes schema if it does not exist
@@ -296,7 +296,8 @@ func (r readSession) GetAllOperations() ([]dbmodel.OperationDTO, error) {
 func (r readSession) CountNotFinishedOperationsByInstanceID(instanceID string) (int, dberr.Error) {
 	stateInProgress := dbr.Eq("state", domain.InProgress)
 	statePending := dbr.Eq("state", internal.OperationStatePending)
-	stateCondition := dbr.Or(statePending, stateInProgress)
+	stateCanceling := dbr.Eq("state", internal.OperationStateCanceling)
+	stateCondition := dbr.Or(statePending, stateInProgress, stateCanceling)
 	instanceIDCondition := dbr.Eq("instance_id", instanceID)
 
 	var res struct {
@@ -317,7 +318,8 @@ func (r readSession) CountNotFinishedOperationsByInstanceID(instanceID string) (
 func (r readSession) GetNotFinishedOperationsByType(operationType internal.OperationType) ([]dbmodel.OperationDTO, dberr.Error) {
 	stateInProgress := dbr.Eq("state", domain.InProgress)
 	statePending := dbr.Eq("state", internal.OperationStatePending)
-	stateCondition := dbr.Or(statePending, stateInProgress)
+	stateCanceling := dbr.Eq("state", internal.OperationStateCanceling)
+	stateCondition := dbr.Or(statePending, stateInProgress, stateCanceling)
 	typeCondition := dbr.Eq("type", operationType)
 	var operations []dbmodel.OperationDTO
 
@@ -405,6 +407,37 @@ func (r readSession) GetOperationsForIDs(opIDlist []string) ([]dbmodel.Operation
 	return operations, nil
 }
 
+// GetLastOperations returns the last operation of every given instance, pending and canceled operations are skipped as in GetLastOperation
+func (r readSession) GetLastOperations(instanceIDs []string) ([]dbmodel.OperationDTO, dberr.Error) {
+	var operations []dbmodel.OperationDTO
+	_, err := r.session.
+		Select("DISTINCT ON (instance_id) *").
+		From(OperationTableName).
+		Where("instance_id IN ?", instanceIDs).
+		Where(dbr.Neq("state", []string{internal.OperationStatePending, internal.OperationStateCanceled})).
+		OrderBy("instance_id").
+		OrderDesc(CreatedAtField).
+		Load(&operations)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get last operations: %s", err)
+	}
+	return operations, nil
+}
+
+func (r readSession) ListNotFinishedOperations(instanceIDs []string) ([]dbmodel.OperationDTO, dberr.Error) {
+	var operations []dbmodel.OperationDTO
+	_, err := r.session.
+		Select("*").
+		From(OperationTableName).
+		Where("instance_id IN ?", instanceIDs).
+		Where("state IN ?", []string{string(domain.InProgress), internal.OperationStatePending, internal.OperationStateCanceling}).
+		Load(&operations)
+	if err != nil {
+		return nil, dberr.Internal("Failed to get not finished operations: %s", err)
+	}
+	return operations, nil
+}
+
 func (r readSession) ListOperationsByType(operationType internal.OperationType) ([]dbmodel.OperationDTO, dberr.Error) {
 	typeCondition := dbr.Eq("type", operationType)
 	var operations []dbmodel.OperationDTO
@@ -566,18 +599,14 @@ func (r readSession) ListInstances(filter dbmodel.InstanceFilter) ([]dbmodel.Ins
 	// select an instance with a last operation
 	stmt := r.session.Select("o.data", "o.state", "o.type", fmt.Sprintf("%s.*", InstancesTableName)).
 		From(InstancesTableName).
-		Join(dbr.I(OperationTableName).As("o"), fmt.Sprintf("%s.last_operation_id = o.id", InstancesTableName)).
-		OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField))
+		Join(dbr.I(OperationTableName).As("o"), fmt.Sprintf("%s.last_operation_id = o.id", InstancesTableName))
 
 	if len(filter.States) > 0 || filter.Suspended != nil {
 		stateFilters := buildInstanceStateFilters("o", filter)
 		stmt.Where(stateFilters)
 	}
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
-	}
+	addInstancePagination(stmt, filter)
 
 	addInstanceFilters(stmt, filter, "o")
 
@@ -607,18 +636,14 @@ func (r readSession) ListInstancesWithSubaccountStates(filter dbmodel.InstanceFi
 	stmt = r.session.Select("o1.data", "o1.state", "o1.type", fmt.Sprintf("%s.*", InstancesTableName), "ss.beta_enabled", "ss.used_for_production").
 		From(InstancesTableName).
 		Join(dbr.I(OperationTableName).As("o1"), fmt.Sprintf("%s.last_operation_id = o1.id", InstancesTableName)).
-		LeftJoin(dbr.I(SubaccountStatesTableName).As("ss"), fmt.Sprintf("%s.sub_account_id = ss.id", InstancesTableName)).
-		OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField))
+		LeftJoin(dbr.I(SubaccountStatesTableName).As("ss"), fmt.Sprintf("%s.sub_account_id = ss.id", InstancesTableName))
 
 	if len(filter.States) > 0 || filter.Suspended != nil {
 		stateFilters := buildInstanceStateFilters("o1", filter)
 		stmt.Where(stateFilters)
 	}
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
-	}
+	addInstancePagination(stmt, filter)
 
 	addInstanceFilters(stmt, filter, "o1")
 
@@ -753,6 +778,10 @@ func buildInstanceStateFilters(table string, filter dbmodel.InstanceFilter) dbr.
 				dbr.Neq(fmt.Sprintf("%s.type", table), internal.OperationTypeDeprovision),
 				dbr.Neq(fmt.Sprintf("%s.state", table), domain.Succeeded),
 			))
+		case dbmodel.InstanceCanceling:
+			exprs = append(exprs, dbr.Eq(fmt.Sprintf("%s.state", table), internal.OperationStateCanceling))
+		case dbmodel.InstanceCanceled:
+			exprs = append(exprs, dbr.Eq(fmt.Sprintf("%s.state", table), internal.OperationStateCanceled))
 		}
 	}
 	if filter.Suspended != nil && *filter.Suspended {
@@ -762,6 +791,28 @@ func buildInstanceStateFilters(table string, filter dbmodel.InstanceFilter) dbr.
 	return dbr.Or(exprs...)
 }
 
+// addInstancePagination orders instances by the creation time and ID, so pages are stable for instances created at the same time.
+// With a cursor, the page starts after the cursor instead of an offset, which keeps later pages fast and not shifted by new instances.
+// Fields of the filter sort are ordered first, the cursor must not be used together with them.
+func addInstancePagination(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
+	for _, sort := range filter.Sort {
+		stmt.OrderDir(fmt.Sprintf("%s.%s", InstancesTableName, sort.Field), !sort.Descending)
+	}
+	stmt.OrderBy(fmt.Sprintf("%s.%s", InstancesTableName, CreatedAtField)).
+		OrderBy(fmt.Sprintf("%s.instance_id", InstancesTableName))
+
+	if filter.After != nil {
+		stmt.Where(fmt.Sprintf("(%s.%s, %s.instance_id) > (?, ?)", InstancesTableName, CreatedAtField, InstancesTableName), filter.After.CreatedAt, filter.After.InstanceID)
+		if filter.PageSize > 0 {
+			stmt.Limit(uint64(filter.PageSize))
+		}
+		return
+	}
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+}
+
 func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter, table string) {
 	if len(filter.GlobalAccountIDs) > 0 {
 		stmt.Where("instances.global_account_id IN ?", filter.GlobalAccountIDs)
@@ -810,6 +861,70 @@ func addInstanceFilters(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter, tab
 	if filter.BindingExists != nil && *filter.BindingExists {
 		stmt.Where("exists (select instance_id from bindings where bindings.instance_id=instances.instance_id)")
 	}
+
+	if len(filter.Providers) > 0 {
+		stmt.Where("instances.provider IN ?", filter.Providers)
+	}
+	if len(filter.LicenseTypes) > 0 {
+		stmt.Where("instances.provisioning_parameters::JSONB->'ers_context'->>'license_type' IN ?", filter.LicenseTypes)
+	}
+	if len(filter.CommercialModels) > 0 {
+		stmt.Where("instances.provisioning_parameters::JSONB->'ers_context'->>'commercial_model' IN ?", filter.CommercialModels)
+	}
+	if len(filter.MachineTypes) > 0 {
+		// the default machine type of the plan is used if the machine type is not set in parameters
+		stmt.Where(fmt.Sprintf("COALESCE(instances.provisioning_parameters::JSONB->'parameters'->>'machineType', %s.data::JSONB->'providerValues'->>'DefaultMachineType') IN ?", table), filter.MachineTypes)
+	}
+	if len(filter.KubernetesVersions) > 0 {
+		stmt.Where(fmt.Sprintf("%s.data::JSONB->>'kubernetes_version' IN ?", table), filter.KubernetesVersions)
+	}
+	if filter.AdditionalWorkerNodePools != nil {
+		pools := "instances.provisioning_parameters::JSONB->'parameters'->'additionalWorkerNodePools'"
+		poolsCount := fmt.Sprintf("CASE WHEN jsonb_typeof(%s) = 'array' THEN jsonb_array_length(%s) ELSE 0 END", pools, pools)
+		if *filter.AdditionalWorkerNodePools {
+			stmt.Where(poolsCount + " > 0")
+		} else {
+			stmt.Where(poolsCount + " = 0")
+		}
+	}
+	if len(filter.LastErrorComponents) > 0 {
+		stmt.Where(fmt.Sprintf("%s.data::JSONB->'last_error'->>'component' IN ?", table), filter.LastErrorComponents)
+	}
+	if len(filter.LastErrorReasons) > 0 {
+		stmt.Where(fmt.Sprintf("%s.data::JSONB->'last_error'->>'reason' IN ?", table), filter.LastErrorReasons)
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		stmt.Where("instances.created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		stmt.Where("instances.created_at < ?", filter.CreatedBefore)
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		stmt.Where("instances.updated_at >= ?", filter.UpdatedAfter)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		stmt.Where("instances.updated_at < ?", filter.UpdatedBefore)
+	}
+	for _, requirement := range filter.LabelSelector {
+		addLabelRequirement(stmt, requirement)
+	}
+}
+
+// addLabelRequirement uses subqueries, so the notin and ! operators match instances without the label
+func addLabelRequirement(stmt *dbr.SelectStmt, requirement dbmodel.LabelRequirement) {
+	label := fmt.Sprintf("SELECT 1 FROM %s WHERE %s.instance_id = instances.instance_id AND %s.key = ?", InstanceLabelsTableName, InstanceLabelsTableName, InstanceLabelsTableName)
+	withValue := fmt.Sprintf("%s AND %s.value IN ?", label, InstanceLabelsTableName)
+	switch requirement.Operator {
+	case dbmodel.LabelOperatorIn:
+		stmt.Where(fmt.Sprintf("EXISTS (%s)", withValue), requirement.Key, requirement.Values)
+	case dbmodel.LabelOperatorNotIn:
+		stmt.Where(fmt.Sprintf("NOT EXISTS (%s)", withValue), requirement.Key, requirement.Values)
+	case dbmodel.LabelOperatorExists:
+		stmt.Where(fmt.Sprintf("EXISTS (%s)", label), requirement.Key)
+	case dbmodel.LabelOperatorDoesNotExist:
+		stmt.Where(fmt.Sprintf("NOT EXISTS (%s)", label), requirement.Key)
+	}
 }
 
 func addOperationFilters(stmt *dbr.SelectStmt, filter dbmodel.OperationFilter) {
@@ -965,6 +1080,272 @@ func (r readSession) ListActions(instanceID string) ([]runtime.Action, error) {
 	return actions, err
 }
 
+func (r readSession) ListActionsByFilter(filter dbmodel.ActionFilter) ([]runtime.Action, int, error) {
+	var actions []runtime.Action
+	stmt := r.session.Select("*").From(ActionsTableName)
+	addActionFilters(stmt, filter)
+	stmt.OrderDesc("created_at")
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+	if _, err := stmt.Load(&actions); err != nil {
+		return nil, -1, fmt.Errorf("while fetching actions: %w", err)
+	}
+
+	var res struct {
+		Total int
+	}
+	countStmt := r.session.Select("count(*) as total").From(ActionsTableName)
+	addActionFilters(countStmt, filter)
+	if err := countStmt.LoadOne(&res); err != nil {
+		return nil, -1, fmt.Errorf("while counting actions: %w", err)
+	}
+	return actions, res.Total, nil
+}
+
+func addActionFilters(stmt *dbr.SelectStmt, filter dbmodel.ActionFilter) {
+	if len(filter.InstanceIDs) > 0 {
+		stmt.Where("instance_id IN ?", filter.InstanceIDs)
+	}
+	if len(filter.Types) > 0 {
+		stmt.Where("type::text IN ?", filter.Types)
+	}
+	if len(filter.Actors) > 0 {
+		stmt.Where("actor IN ?", filter.Actors)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		stmt.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		stmt.Where("created_at < ?", filter.CreatedBefore)
+	}
+}
+
+func (r readSession) CountQueueItems(queueName string) (int, error) {
+	var res struct {
+		Total int
+	}
+	err := r.session.Select("count(*) as total").
+		From(OperationQueueTableName).
+		Where(dbr.Eq("queue_name", queueName)).
+		LoadOne(&res)
+
+	return res.Total, err
+}
+
+func (r readSession) GetWebhookSubscription(id string) (dbmodel.WebhookSubscriptionDTO, dberr.Error) {
+	var subscription dbmodel.WebhookSubscriptionDTO
+	err := r.session.
+		Select("*").
+		From(WebhookSubscriptionsTableName).
+		Where(dbr.Eq("id", id)).
+		LoadOne(&subscription)
+
+	if err != nil {
+		if errors.Is(err, dbr.ErrNotFound) {
+			return dbmodel.WebhookSubscriptionDTO{}, dberr.NotFound("Cannot find the webhook subscription with id:'%s'", id)
+		}
+		return dbmodel.WebhookSubscriptionDTO{}, dberr.Internal("Failed to get the webhook subscription: %s", err)
+	}
+	return subscription, nil
+}
+
+func (r readSession) ListWebhookSubscriptions(filter dbmodel.WebhookSubscriptionFilter) ([]dbmodel.WebhookSubscriptionDTO, int, error) {
+	var subscriptions []dbmodel.WebhookSubscriptionDTO
+	stmt := r.session.Select("*").From(WebhookSubscriptionsTableName)
+	addWebhookSubscriptionFilters(stmt, filter)
+	stmt.OrderBy("created_at")
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+	if _, err := stmt.Load(&subscriptions); err != nil {
+		return nil, -1, fmt.Errorf("while fetching webhook subscriptions: %w", err)
+	}
+
+	var res struct {
+		Total int
+	}
+	countStmt := r.session.Select("count(*) as total").From(WebhookSubscriptionsTableName)
+	addWebhookSubscriptionFilters(countStmt, filter)
+	if err := countStmt.LoadOne(&res); err != nil {
+		return nil, -1, fmt.Errorf("while counting webhook subscriptions: %w", err)
+	}
+	return subscriptions, res.Total, nil
+}
+
+func addWebhookSubscriptionFilters(stmt *dbr.SelectStmt, filter dbmodel.WebhookSubscriptionFilter) {
+	if filter.GlobalAccountID != "" {
+		stmt.Where(dbr.Eq("global_account_id", filter.GlobalAccountID))
+	}
+	if filter.SubAccountID != "" {
+		stmt.Where(dbr.Eq("sub_account_id", filter.SubAccountID))
+	}
+}
+
+func (r readSession) ListWebhookDeliveries(filter dbmodel.WebhookDeliveryFilter) ([]dbmodel.WebhookDeliveryDTO, int, error) {
+	var deliveries []dbmodel.WebhookDeliveryDTO
+	stmt := r.session.
+		Select("id", "subscription_id", "event_id", "event_type", "payload", "state", "attempts", "last_error", "created_at", "updated_at").
+		From(WebhookDeliveriesTableName)
+	addWebhookDeliveryFilters(stmt, filter)
+	stmt.OrderBy("id")
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+	if _, err := stmt.Load(&deliveries); err != nil {
+		return nil, -1, fmt.Errorf("while fetching webhook deliveries: %w", err)
+	}
+
+	var res struct {
+		Total int
+	}
+	countStmt := r.session.Select("count(*) as total").From(WebhookDeliveriesTableName)
+	addWebhookDeliveryFilters(countStmt, filter)
+	if err := countStmt.LoadOne(&res); err != nil {
+		return nil, -1, fmt.Errorf("while counting webhook deliveries: %w", err)
+	}
+	return deliveries, res.Total, nil
+}
+
+func addWebhookDeliveryFilters(stmt *dbr.SelectStmt, filter dbmodel.WebhookDeliveryFilter) {
+	stmt.Where(dbr.Eq("subscription_id", filter.SubscriptionID))
+	if filter.State != "" {
+		stmt.Where(dbr.Eq("state", filter.State))
+	}
+}
+
+func (r readSession) GetCampaign(id string) (dbmodel.CampaignDTO, dberr.Error) {
+	var campaign dbmodel.CampaignDTO
+	err := r.session.
+		Select("id", "state", "data", "version", "created_at", "updated_at").
+		From(CampaignsTableName).
+		Where(dbr.Eq("id", id)).
+		LoadOne(&campaign)
+
+	if err != nil {
+		if errors.Is(err, dbr.ErrNotFound) {
+			return dbmodel.CampaignDTO{}, dberr.NotFound("Cannot find the campaign with id:'%s'", id)
+		}
+		return dbmodel.CampaignDTO{}, dberr.Internal("Failed to get the campaign: %s", err)
+	}
+	return campaign, nil
+}
+
+func (r readSession) ListCampaigns(filter dbmodel.CampaignFilter) ([]dbmodel.CampaignDTO, int, error) {
+	var campaigns []dbmodel.CampaignDTO
+	stmt := r.session.
+		Select("id", "state", "data", "version", "created_at", "updated_at").
+		From(CampaignsTableName)
+	addCampaignFilters(stmt, filter)
+	stmt.OrderBy("created_at")
+	if filter.Page > 0 && filter.PageSize > 0 {
+		stmt = stmt.Paginate(uint64(filter.Page), uint64(filter.PageSize))
+	}
+	if _, err := stmt.Load(&campaigns); err != nil {
+		return nil, -1, fmt.Errorf("while fetching campaigns: %w", err)
+	}
+
+	var res struct {
+		Total int
+	}
+	countStmt := r.session.Select("count(*) as total").From(CampaignsTableName)
+	addCampaignFilters(countStmt, filter)
+	if err := countStmt.LoadOne(&res); err != nil {
+		return nil, -1, fmt.Errorf("while counting campaigns: %w", err)
+	}
+	return campaigns, res.Total, nil
+}
+
+func addCampaignFilters(stmt *dbr.SelectStmt, filter dbmodel.CampaignFilter) {
+	if filter.State != "" {
+		stmt.Where(dbr.Eq("state", filter.State))
+	}
+}
+
+func (r readSession) ListCampaignInstances(campaignID, state string) ([]dbmodel.CampaignInstanceDTO, error) {
+	var instances []dbmodel.CampaignInstanceDTO
+	stmt := r.session.
+		Select("campaign_id", "instance_id", "wave", "state", "operation_id", "message", "updated_at").
+		From(CampaignInstancesTableName).
+		Where(dbr.Eq("campaign_id", campaignID))
+	if state != "" {
+		stmt.Where(dbr.Eq("state", state))
+	}
+	stmt.OrderBy("wave").OrderBy("instance_id")
+	_, err := stmt.Load(&instances)
+	return instances, err
+}
+
+func (r readSession) ListInstanceSnapshots(instanceID string) ([]dbmodel.InstanceSnapshotDTO, error) {
+	var snapshots []dbmodel.InstanceSnapshotDTO
+	_, err := r.session.
+		Select("instance_id", "version", "operation_id", "operation_type", "runtime_id", "parameters", "created_at").
+		From(InstanceSnapshotsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		OrderBy("version").
+		Load(&snapshots)
+	return snapshots, err
+}
+
+func (r readSession) ListInstanceLabels(instanceID string) ([]dbmodel.InstanceLabelDTO, error) {
+	var labels []dbmodel.InstanceLabelDTO
+	_, err := r.session.
+		Select("instance_id", "key", "value").
+		From(InstanceLabelsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		OrderBy("key").
+		Load(&labels)
+	return labels, err
+}
+
+func (r readSession) GetInstanceSnapshotAt(instanceID string, at time.Time) (dbmodel.InstanceSnapshotDTO, dberr.Error) {
+	var snapshot dbmodel.InstanceSnapshotDTO
+	err := r.session.
+		Select("instance_id", "version", "operation_id", "operation_type", "runtime_id", "parameters", "created_at").
+		From(InstanceSnapshotsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		Where(dbr.Lte("created_at", at)).
+		OrderDesc("version").
+		Limit(1).
+		LoadOne(&snapshot)
+
+	if err != nil {
+		if errors.Is(err, dbr.ErrNotFound) {
+			return dbmodel.InstanceSnapshotDTO{}, dberr.NotFound("Cannot find the snapshot of the instance %s at %s", instanceID, at.Format(time.RFC3339))
+		}
+		return dbmodel.InstanceSnapshotDTO{}, dberr.Internal("Failed to get the snapshot of the instance %s: %s", instanceID, err)
+	}
+	return snapshot, nil
+}
+
+// ListEncryptedValues returns the column of rows ordered by one or two key columns, starting after the given key
+func (r readSession) ListEncryptedValues(table string, keys []string, column string, after []string, limit int) ([]dbmodel.EncryptedValueDTO, error) {
+	columns := make([]string, 0, 3)
+	for i, key := range keys {
+		columns = append(columns, fmt.Sprintf("%s::text AS key%d", key, i+1))
+	}
+	if len(keys) == 1 {
+		columns = append(columns, "'' AS key2")
+	}
+	columns = append(columns, fmt.Sprintf("%s::text AS value", column))
+
+	stmt := r.session.Select(columns...).From(table)
+	if len(after) == len(keys) {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+		values := make([]interface{}, 0, len(after))
+		for _, value := range after {
+			values = append(values, value)
+		}
+		stmt.Where(fmt.Sprintf("(%s) > (%s)", strings.Join(keys, ", "), placeholders), values...)
+	}
+	for _, key := range keys {
+		stmt.OrderBy(key)
+	}
+	var rows []dbmodel.EncryptedValueDTO
+	_, err := stmt.Limit(uint64(limit)).Load(&rows)
+	return rows, err
+}
+
 func addInstanceArchivedFilter(stmt *dbr.SelectStmt, filter dbmodel.InstanceFilter) {
 	if len(filter.InstanceIDs) > 0 {
 		stmt.Where("instance_id IN ?", filter.InstanceIDs)
@@ -1,10 +1,13 @@
 package postsql
 
 import (
+	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/kyma-project/kyma-environment-broker/common/events"
 	"github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
 
@@ -15,6 +18,9 @@ import (
 
 const (
 	UniqueViolationErrorCode = "23505"
+
+	// instanceSnapshotsVersionConstraint is the primary key of the instance_snapshots table
+	instanceSnapshotsVersionConstraint = "instance_snapshots_pkey"
 )
 
 type writeSession struct {
@@ -55,6 +61,11 @@ func (ws writeSession) InsertBinding(binding dbmodel.BindingDTO) dberr.Error {
 		Pair("kubeconfig", binding.Kubeconfig).
 		Pair("expiration_seconds", binding.ExpirationSeconds).
 		Pair("created_by", binding.CreatedBy).
+		Pair("role", binding.Role).
+		Pair("namespaces", binding.Namespaces).
+		Pair("state", binding.State).
+		Pair("stage", binding.Stage).
+		Pair("description", binding.Description).
 		Exec()
 
 	if err != nil {
@@ -73,6 +84,9 @@ func (ws writeSession) UpdateBinding(binding dbmodel.BindingDTO) dberr.Error {
 	_, err := ws.update(BindingsTableName).
 		Set("kubeconfig", binding.Kubeconfig).
 		Set("expires_at", binding.ExpiresAt).
+		Set("state", binding.State).
+		Set("stage", binding.Stage).
+		Set("description", binding.Description).
 		Where(dbr.Eq("id", binding.ID)).
 		Where(dbr.Eq("instance_id", binding.InstanceID)).
 		Exec()
@@ -331,15 +345,22 @@ func (ws writeSession) DeleteOperationByID(id string) dberr.Error {
 	return nil
 }
 
-func (ws writeSession) InsertAction(actionType runtime.ActionType, instanceID, message, oldValue, newValue string) dberr.Error {
+func (ws writeSession) InsertAction(action runtime.Action) dberr.Error {
+	if action.ID == "" {
+		action.ID = uuid.NewString()
+	}
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now()
+	}
 	_, err := ws.insertInto(ActionsTableName).
-		Pair("id", uuid.NewString()).
-		Pair("type", actionType).
-		Pair("instance_id", instanceID).
-		Pair("message", message).
-		Pair("old_value", oldValue).
-		Pair("new_value", newValue).
-		Pair("created_at", time.Now()).
+		Pair("id", action.ID).
+		Pair("type", action.Type).
+		Pair("instance_id", action.InstanceID).
+		Pair("actor", action.Actor).
+		Pair("message", action.Message).
+		Pair("old_value", action.OldValue).
+		Pair("new_value", action.NewValue).
+		Pair("created_at", action.CreatedAt).
 		Exec()
 	if err != nil {
 		return dberr.Internal("failed to insert action: %s", err)
@@ -347,6 +368,349 @@ func (ws writeSession) InsertAction(actionType runtime.ActionType, instanceID, m
 	return nil
 }
 
+func (ws writeSession) InsertQueueItem(queueName, operationID string, delay time.Duration) dberr.Error {
+	_, err := ws.updateBySql(fmt.Sprintf(`INSERT INTO %s (operation_id, queue_name, visible_at, created_at)
+VALUES (?, ?, now() + ? * interval '1 millisecond', now())
+ON CONFLICT (operation_id) DO UPDATE SET
+    visible_at = LEAST(%s.visible_at, EXCLUDED.visible_at),
+    requeued = %s.owner IS NOT NULL`, OperationQueueTableName, OperationQueueTableName, OperationQueueTableName),
+		operationID, queueName, delay.Milliseconds()).Exec()
+	if err != nil {
+		return dberr.Internal("failed to insert operation %s to the queue %s: %s", operationID, queueName, err)
+	}
+	return nil
+}
+
+func (ws writeSession) ClaimQueueItem(queueName, owner string, leaseDuration time.Duration) (string, dberr.Error) {
+	var operationID string
+	err := ws.selectBySql(fmt.Sprintf(`UPDATE %s SET owner = ?, lease_expires_at = now() + ? * interval '1 millisecond', requeued = false
+WHERE operation_id = (
+    SELECT operation_id FROM %s
+    WHERE queue_name = ? AND visible_at <= now() AND (owner IS NULL OR lease_expires_at < now())
+    ORDER BY visible_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING operation_id`, OperationQueueTableName, OperationQueueTableName),
+		owner, leaseDuration.Milliseconds(), queueName).LoadOne(&operationID)
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return "", dberr.NotFound("no operation ready to be processed in the queue %s", queueName)
+		}
+		return "", dberr.Internal("failed to claim an operation from the queue %s: %s", queueName, err)
+	}
+	return operationID, nil
+}
+
+func (ws writeSession) ExtendQueueItemLease(operationID, owner string, leaseDuration time.Duration) dberr.Error {
+	res, err := ws.updateBySql(fmt.Sprintf(`UPDATE %s SET lease_expires_at = now() + ? * interval '1 millisecond'
+WHERE operation_id = ? AND owner = ?`, OperationQueueTableName),
+		leaseDuration.Milliseconds(), operationID, owner).Exec()
+	if err != nil {
+		return dberr.Internal("failed to extend the lease of operation %s: %s", operationID, err)
+	}
+	return expectAffectedRows(res, "operation %s is not leased by %s", operationID, owner)
+}
+
+func (ws writeSession) ReleaseQueueItem(operationID, owner string, delay time.Duration) dberr.Error {
+	res, err := ws.updateBySql(fmt.Sprintf(`UPDATE %s SET owner = NULL, lease_expires_at = NULL, requeued = false,
+    visible_at = CASE WHEN requeued THEN visible_at ELSE now() + ? * interval '1 millisecond' END
+WHERE operation_id = ? AND owner = ?`, OperationQueueTableName),
+		delay.Milliseconds(), operationID, owner).Exec()
+	if err != nil {
+		return dberr.Internal("failed to release operation %s: %s", operationID, err)
+	}
+	return expectAffectedRows(res, "operation %s is not leased by %s", operationID, owner)
+}
+
+func (ws writeSession) DeleteQueueItem(operationID, owner string) dberr.Error {
+	res, err := ws.deleteFrom(OperationQueueTableName).
+		Where(dbr.Eq("operation_id", operationID)).
+		Where(dbr.Eq("owner", owner)).
+		Where(dbr.Eq("requeued", false)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to delete operation %s from the queue: %s", operationID, err)
+	}
+	if rAffected, err := res.RowsAffected(); err == nil && rAffected > 0 {
+		return nil
+	}
+	// the operation was added again while it was processed, it must be processed once more
+	return ws.ReleaseQueueItem(operationID, owner, 0)
+}
+
+// GetOperationStateForUpdate returns the operation state and locks the operation row until the transaction ends
+func (ws writeSession) GetOperationStateForUpdate(operationID string) (string, dberr.Error) {
+	var state string
+	err := ws.selectBySql(fmt.Sprintf("SELECT state FROM %s WHERE id = ? FOR UPDATE", OperationTableName), operationID).
+		LoadOne(&state)
+	if err != nil {
+		if err == dbr.ErrNotFound {
+			return "", dberr.NotFound("Cannot find Operation with ID:'%s'", operationID)
+		}
+		return "", dberr.Internal("Failed to get operation %s state: %s", operationID, err)
+	}
+	return state, nil
+}
+
+func (ws writeSession) InsertOutboxEvent(event dbmodel.OutboxEventDTO) dberr.Error {
+	_, err := ws.insertInto(OutboxTableName).
+		Pair("instance_id", event.InstanceID).
+		Pair("operation_id", event.OperationID).
+		Pair("type", event.Type).
+		Pair("payload", event.Payload).
+		Pair("visible_at", event.CreatedAt).
+		Pair("created_at", event.CreatedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to insert outbox event for operation %s: %s", event.OperationID, err)
+	}
+	return nil
+}
+
+// ClaimOutboxEvents leases the oldest events of instances, an event is never claimed while an older event of the same instance exists
+func (ws writeSession) ClaimOutboxEvents(owner string, leaseDuration time.Duration, limit int) ([]dbmodel.OutboxEventDTO, dberr.Error) {
+	var events []dbmodel.OutboxEventDTO
+	_, err := ws.selectBySql(fmt.Sprintf(`UPDATE %s SET owner = ?, lease_expires_at = now() + ? * interval '1 millisecond'
+WHERE id IN (
+    SELECT o.id FROM %s o
+    WHERE o.id = (SELECT min(h.id) FROM %s h WHERE h.instance_id = o.instance_id)
+        AND o.visible_at <= now() AND (o.owner IS NULL OR o.lease_expires_at < now())
+    ORDER BY o.id
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, instance_id, operation_id, type, payload, attempts, delivered_sinks, created_at`, OutboxTableName, OutboxTableName, OutboxTableName),
+		owner, leaseDuration.Milliseconds(), limit).Load(&events)
+	if err != nil {
+		return nil, dberr.Internal("failed to claim outbox events: %s", err)
+	}
+	return events, nil
+}
+
+func (ws writeSession) ReleaseOutboxEvent(id int64, owner string, delay time.Duration) dberr.Error {
+	res, err := ws.updateBySql(fmt.Sprintf(`UPDATE %s SET owner = NULL, lease_expires_at = NULL, attempts = attempts + 1,
+    visible_at = now() + ? * interval '1 millisecond'
+WHERE id = ? AND owner = ?`, OutboxTableName),
+		delay.Milliseconds(), id, owner).Exec()
+	if err != nil {
+		return dberr.Internal("failed to release outbox event %d: %s", id, err)
+	}
+	return expectAffectedRows(res, "outbox event %d is not leased by %s", id, owner)
+}
+
+func (ws writeSession) UpdateOutboxDeliveredSinks(id int64, owner string, sinks string) dberr.Error {
+	res, err := ws.update(OutboxTableName).
+		Set("delivered_sinks", sinks).
+		Where(dbr.Eq("id", id)).
+		Where(dbr.Eq("owner", owner)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to update delivered sinks of outbox event %d: %s", id, err)
+	}
+	return expectAffectedRows(res, "outbox event %d is not leased by %s", id, owner)
+}
+
+func (ws writeSession) DeleteOutboxEvent(id int64, owner string) dberr.Error {
+	res, err := ws.deleteFrom(OutboxTableName).
+		Where(dbr.Eq("id", id)).
+		Where(dbr.Eq("owner", owner)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to delete outbox event %d: %s", id, err)
+	}
+	return expectAffectedRows(res, "outbox event %d is not leased by %s", id, owner)
+}
+
+func (ws writeSession) InsertWebhookSubscription(subscription dbmodel.WebhookSubscriptionDTO) dberr.Error {
+	_, err := ws.insertInto(WebhookSubscriptionsTableName).
+		Pair("id", subscription.ID).
+		Pair("global_account_id", subscription.GlobalAccountID).
+		Pair("sub_account_id", subscription.SubAccountID).
+		Pair("url", subscription.URL).
+		Pair("secret", subscription.Secret).
+		Pair("event_types", subscription.EventTypes).
+		Pair("created_at", subscription.CreatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("webhook subscription with id %s already exists", subscription.ID)
+			}
+		}
+		return dberr.Internal("Failed to insert webhook subscription %s: %s", subscription.ID, err)
+	}
+	return nil
+}
+
+func (ws writeSession) DeleteWebhookSubscription(id string) dberr.Error {
+	res, err := ws.deleteFrom(WebhookSubscriptionsTableName).
+		Where(dbr.Eq("id", id)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete webhook subscription %s: %s", id, err)
+	}
+	return expectAffectedRows(res, "webhook subscription %s does not exist", id)
+}
+
+// InsertWebhookDelivery stores the notification, a notification about the same event for the same subscription is stored once
+func (ws writeSession) InsertWebhookDelivery(delivery dbmodel.WebhookDeliveryDTO) dberr.Error {
+	_, err := ws.updateBySql(fmt.Sprintf(`INSERT INTO %s (subscription_id, event_id, event_type, payload, state, visible_at, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`, WebhookDeliveriesTableName),
+		delivery.SubscriptionID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.State,
+		delivery.CreatedAt, delivery.CreatedAt, delivery.CreatedAt).Exec()
+	if err != nil {
+		return dberr.Internal("failed to insert webhook delivery of event %d for subscription %s: %s", delivery.EventID, delivery.SubscriptionID, err)
+	}
+	return nil
+}
+
+func (ws writeSession) ClaimWebhookDeliveries(owner string, leaseDuration time.Duration, limit int) ([]dbmodel.WebhookDeliveryDTO, dberr.Error) {
+	var deliveries []dbmodel.WebhookDeliveryDTO
+	_, err := ws.selectBySql(fmt.Sprintf(`UPDATE %s SET owner = ?, lease_expires_at = now() + ? * interval '1 millisecond'
+WHERE id IN (
+    SELECT id FROM %s
+    WHERE state = ? AND visible_at <= now() AND (owner IS NULL OR lease_expires_at < now())
+    ORDER BY id
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, subscription_id, event_id, event_type, payload, state, attempts, last_error, created_at, updated_at`, WebhookDeliveriesTableName, WebhookDeliveriesTableName),
+		owner, leaseDuration.Milliseconds(), internal.WebhookDeliveryPending, limit).Load(&deliveries)
+	if err != nil {
+		return nil, dberr.Internal("failed to claim webhook deliveries: %s", err)
+	}
+	return deliveries, nil
+}
+
+func (ws writeSession) RetryWebhookDelivery(id int64, owner string, delay time.Duration, lastError string) dberr.Error {
+	res, err := ws.updateBySql(fmt.Sprintf(`UPDATE %s SET owner = NULL, lease_expires_at = NULL, attempts = attempts + 1,
+    last_error = ?, updated_at = now(), visible_at = now() + ? * interval '1 millisecond'
+WHERE id = ? AND owner = ?`, WebhookDeliveriesTableName),
+		lastError, delay.Milliseconds(), id, owner).Exec()
+	if err != nil {
+		return dberr.Internal("failed to release webhook delivery %d: %s", id, err)
+	}
+	return expectAffectedRows(res, "webhook delivery %d is not leased by %s", id, owner)
+}
+
+func (ws writeSession) MarkWebhookDeliveryDead(id int64, owner string, lastError string) dberr.Error {
+	res, err := ws.updateBySql(fmt.Sprintf(`UPDATE %s SET owner = NULL, lease_expires_at = NULL, attempts = attempts + 1,
+    last_error = ?, updated_at = now(), state = ?
+WHERE id = ? AND owner = ?`, WebhookDeliveriesTableName),
+		lastError, internal.WebhookDeliveryDead, id, owner).Exec()
+	if err != nil {
+		return dberr.Internal("failed to mark webhook delivery %d as dead: %s", id, err)
+	}
+	return expectAffectedRows(res, "webhook delivery %d is not leased by %s", id, owner)
+}
+
+func (ws writeSession) DeleteWebhookDelivery(id int64, owner string) dberr.Error {
+	res, err := ws.deleteFrom(WebhookDeliveriesTableName).
+		Where(dbr.Eq("id", id)).
+		Where(dbr.Eq("owner", owner)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("failed to delete webhook delivery %d: %s", id, err)
+	}
+	return expectAffectedRows(res, "webhook delivery %d is not leased by %s", id, owner)
+}
+
+func (ws writeSession) InsertCampaign(campaign dbmodel.CampaignDTO) dberr.Error {
+	_, err := ws.insertInto(CampaignsTableName).
+		Pair("id", campaign.ID).
+		Pair("state", campaign.State).
+		Pair("data", campaign.Data).
+		Pair("version", campaign.Version).
+		Pair("created_at", campaign.CreatedAt).
+		Pair("updated_at", campaign.UpdatedAt).
+		Exec()
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				return dberr.AlreadyExists("campaign with id %s already exists", campaign.ID)
+			}
+		}
+		return dberr.Internal("Failed to insert campaign %s: %s", campaign.ID, err)
+	}
+	return nil
+}
+
+// InsertCampaignInstances stores instances in batches, a campaign can update thousands of instances
+func (ws writeSession) InsertCampaignInstances(instances []dbmodel.CampaignInstanceDTO) dberr.Error {
+	const batchSize = 500
+	for start := 0; start < len(instances); start += batchSize {
+		stmt := ws.insertInto(CampaignInstancesTableName).
+			Columns("campaign_id", "instance_id", "wave", "state", "operation_id", "message", "updated_at")
+		for _, instance := range instances[start:min(start+batchSize, len(instances))] {
+			stmt.Values(instance.CampaignID, instance.InstanceID, instance.Wave, instance.State, instance.OperationID, instance.Message, instance.UpdatedAt)
+		}
+		if _, err := stmt.Exec(); err != nil {
+			return dberr.Internal("Failed to insert campaign instances: %s", err)
+		}
+	}
+	return nil
+}
+
+func (ws writeSession) UpdateCampaign(campaign dbmodel.CampaignDTO) dberr.Error {
+	res, err := ws.update(CampaignsTableName).
+		Where(dbr.Eq("id", campaign.ID)).
+		Where(dbr.Eq("version", campaign.Version)).
+		Set("state", campaign.State).
+		Set("data", campaign.Data).
+		Set("version", campaign.Version+1).
+		Set("updated_at", campaign.UpdatedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update campaign %s: %s", campaign.ID, err)
+	}
+	return expectAffectedRows(res, "Cannot find the campaign with id:'%s' version: %d", campaign.ID, campaign.Version)
+}
+
+func (ws writeSession) ClaimCampaigns(owner string, leaseDuration time.Duration, states []string) ([]dbmodel.CampaignDTO, dberr.Error) {
+	var campaigns []dbmodel.CampaignDTO
+	_, err := ws.selectBySql(fmt.Sprintf(`UPDATE %s SET owner = ?, lease_expires_at = now() + ? * interval '1 millisecond'
+WHERE id IN (
+    SELECT id FROM %s
+    WHERE state IN ? AND (owner IS NULL OR owner = ? OR lease_expires_at < now())
+    ORDER BY created_at
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, state, data, version, created_at, updated_at`, CampaignsTableName, CampaignsTableName),
+		owner, leaseDuration.Milliseconds(), states, owner).Load(&campaigns)
+	if err != nil {
+		return nil, dberr.Internal("failed to claim campaigns: %s", err)
+	}
+	return campaigns, nil
+}
+
+func (ws writeSession) UpdateCampaignInstance(instance dbmodel.CampaignInstanceDTO) dberr.Error {
+	res, err := ws.update(CampaignInstancesTableName).
+		Where(dbr.Eq("campaign_id", instance.CampaignID)).
+		Where(dbr.Eq("instance_id", instance.InstanceID)).
+		Set("state", instance.State).
+		Set("operation_id", instance.OperationID).
+		Set("message", instance.Message).
+		Set("updated_at", instance.UpdatedAt).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update instance %s of campaign %s: %s", instance.InstanceID, instance.CampaignID, err)
+	}
+	return expectAffectedRows(res, "instance %s is not updated by campaign %s", instance.InstanceID, instance.CampaignID)
+}
+
+func expectAffectedRows(res sql.Result, format string, args ...interface{}) dberr.Error {
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return dberr.Internal("the DB driver does not support RowsAffected operation")
+	}
+	if rAffected == int64(0) {
+		return dberr.NotFound(format, args...)
+	}
+	return nil
+}
+
 func (ws writeSession) Commit() dberr.Error {
 	err := ws.transaction.Commit()
 	if err != nil {
@@ -360,6 +724,74 @@ func (ws writeSession) RollbackUnlessCommitted() {
 	ws.transaction.RollbackUnlessCommitted()
 }
 
+// InsertInstanceSnapshot stores the snapshot with the next version of the instance and returns the version
+func (ws writeSession) InsertInstanceSnapshot(snapshot dbmodel.InstanceSnapshotDTO) (int, dberr.Error) {
+	var version int
+	err := ws.selectBySql(fmt.Sprintf(`INSERT INTO %s (instance_id, version, operation_id, operation_type, runtime_id, parameters, created_at)
+SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ? FROM %s WHERE instance_id = ?
+RETURNING version`, InstanceSnapshotsTableName, InstanceSnapshotsTableName),
+		snapshot.InstanceID, snapshot.OperationID, snapshot.OperationType, snapshot.RuntimeID, snapshot.Parameters, snapshot.CreatedAt, snapshot.InstanceID).
+		LoadOne(&version)
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok {
+			if err.Code == UniqueViolationErrorCode {
+				// the version is computed from the stored snapshots, a concurrent insert can take it
+				if err.Constraint == instanceSnapshotsVersionConstraint {
+					return 0, dberr.Conflict("snapshot version of the instance %s is already taken", snapshot.InstanceID)
+				}
+				return 0, dberr.AlreadyExists("snapshot of the operation %s already exists", snapshot.OperationID)
+			}
+		}
+		return 0, dberr.Internal("Failed to insert snapshot of the instance %s: %s", snapshot.InstanceID, err)
+	}
+	return version, nil
+}
+
+func (ws writeSession) DeleteInstanceLabels(instanceID string) dberr.Error {
+	_, err := ws.deleteFrom(InstanceLabelsTableName).
+		Where(dbr.Eq("instance_id", instanceID)).
+		Exec()
+	if err != nil {
+		return dberr.Internal("Failed to delete labels of the instance %s: %s", instanceID, err)
+	}
+	return nil
+}
+
+func (ws writeSession) InsertInstanceLabels(labels []dbmodel.InstanceLabelDTO) dberr.Error {
+	if len(labels) == 0 {
+		return nil
+	}
+	stmt := ws.insertInto(InstanceLabelsTableName).
+		Columns("instance_id", "key", "value")
+	for _, label := range labels {
+		stmt.Values(label.InstanceID, label.Key, label.Value)
+	}
+	if _, err := stmt.Exec(); err != nil {
+		return dberr.Internal("Failed to insert labels of the instance %s: %s", labels[0].InstanceID, err)
+	}
+	return nil
+}
+
+// UpdateEncryptedValue sets the column only if it still contains the old value, otherwise the dberr.Conflict error is returned
+func (ws writeSession) UpdateEncryptedValue(table string, keys []string, column string, key []string, oldValue, newValue string) dberr.Error {
+	stmt := ws.update(table).Set(column, newValue)
+	for i, k := range keys {
+		stmt.Where(dbr.Eq(k, key[i]))
+	}
+	res, err := stmt.Where(fmt.Sprintf("%s::text = ?", column), oldValue).Exec()
+	if err != nil {
+		return dberr.Internal("Failed to update %s of %s %v: %s", column, table, key, err)
+	}
+	rAffected, err := res.RowsAffected()
+	if err != nil {
+		return dberr.Internal("Failed to get the number of updated rows: %s", err)
+	}
+	if rAffected == 0 {
+		return dberr.Conflict("%s of %s %v was changed", column, table, key)
+	}
+	return nil
+}
+
 func (ws writeSession) insertInto(table string) *dbr.InsertStmt {
 	if ws.transaction != nil {
 		return ws.transaction.InsertInto(table)
@@ -376,6 +808,22 @@ func (ws writeSession) deleteFrom(table string) *dbr.DeleteStmt {
 	return ws.session.DeleteFrom(table)
 }
 
+func (ws writeSession) updateBySql(query string, value ...interface{}) *dbr.UpdateStmt {
+	if ws.transaction != nil {
+		return ws.transaction.UpdateBySql(query, value...)
+	}
+
+	return ws.session.UpdateBySql(query, value...)
+}
+
+func (ws writeSession) selectBySql(query string, value ...interface{}) *dbr.SelectStmt {
+	if ws.transaction != nil {
+		return ws.transaction.SelectBySql(query, value...)
+	}
+
+	return ws.session.SelectBySql(query, value...)
+}
+
 func (ws writeSession) update(table string) *dbr.UpdateStmt {
 	if ws.transaction != nil {
 		return ws.transaction.Update(table)
@@ -24,6 +24,13 @@ type BrokerStorage interface {
 	InstancesArchived() InstancesArchived
 	Bindings() Bindings
 	Actions() Actions
+	OperationQueue() OperationQueue
+	Outbox() Outbox
+	Webhooks() Webhooks
+	Campaigns() Campaigns
+	InstanceSnapshots() InstanceSnapshots
+	Reencryption() Reencryption
+	InstanceLabels() InstanceLabels
 }
 
 const (
@@ -54,20 +61,35 @@ func NewFromConfig(cfg Config, evcfg events.Config, cipher postgres.Cipher) (Bro
 		instancesArchived: postgres.NewInstanceArchived(fact),
 		bindings:          postgres.NewBinding(fact, cipher),
 		actions:           postgres.NewAction(fact),
+		operationQueue:    postgres.NewOperationQueue(fact),
+		outbox:            postgres.NewOutbox(fact),
+		webhooks:          postgres.NewWebhooks(fact, cipher),
+		campaigns:         postgres.NewCampaigns(fact),
+		instanceSnapshots: postgres.NewInstanceSnapshots(fact, cipher),
+		reencryption:      postgres.NewReencryption(fact, cipher),
+		instanceLabels:    postgres.NewInstanceLabels(fact),
 	}, connection, nil
 }
 
 func NewMemoryStorage() BrokerStorage {
 	op := memory.NewOperation()
 	ss := memory.NewSubaccountStates()
+	instances := memory.NewInstance(op, ss)
 	return storage{
 		operation:         op,
 		subaccountStates:  ss,
-		instance:          memory.NewInstance(op, ss),
+		instance:          instances,
 		events:            events.New(events.Config{}, NewInMemoryEvents()),
 		instancesArchived: memory.NewInstanceArchivedInMemoryStorage(),
 		bindings:          memory.NewBinding(),
 		actions:           memory.NewAction(),
+		operationQueue:    memory.NewOperationQueue(),
+		outbox:            op.Outbox(),
+		webhooks:          memory.NewWebhooks(),
+		campaigns:         memory.NewCampaigns(),
+		instanceSnapshots: memory.NewInstanceSnapshots(),
+		reencryption:      memory.NewReencryption(),
+		instanceLabels:    instances.Labels(),
 	}
 }
 
@@ -127,6 +149,13 @@ type storage struct {
 	instancesArchived InstancesArchived
 	bindings          Bindings
 	actions           Actions
+	operationQueue    OperationQueue
+	outbox            Outbox
+	webhooks          Webhooks
+	campaigns         Campaigns
+	instanceSnapshots InstanceSnapshots
+	reencryption      Reencryption
+	instanceLabels    InstanceLabels
 }
 
 func (s storage) Instances() Instances {
@@ -164,3 +193,31 @@ func (s storage) Bindings() Bindings {
 func (s storage) Actions() Actions {
 	return s.actions
 }
+
+func (s storage) OperationQueue() OperationQueue {
+	return s.operationQueue
+}
+
+func (s storage) Outbox() Outbox {
+	return s.outbox
+}
+
+func (s storage) Webhooks() Webhooks {
+	return s.webhooks
+}
+
+func (s storage) Campaigns() Campaigns {
+	return s.campaigns
+}
+
+func (s storage) InstanceSnapshots() InstanceSnapshots {
+	return s.instanceSnapshots
+}
+
+func (s storage) Reencryption() Reencryption {
+	return s.reencryption
+}
+
+func (s storage) InstanceLabels() InstanceLabels {
+	return s.instanceLabels
+}
@@ -21,7 +21,11 @@ const (
 )
 
 func GetStorageForTest(config Config) (func() error, BrokerStorage, error) {
-	storage, connection, err := NewFromConfig(config, events.Config{}, NewEncrypter(config.SecretKey))
+	cipher, err := NewEncrypterFromConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("while creating encrypter: %w", err)
+	}
+	storage, connection, err := NewFromConfig(config, events.Config{}, cipher)
 	if err != nil {
 		return nil, nil, fmt.Errorf("while creating storage: %w", err)
 	}
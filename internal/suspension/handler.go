@@ -1,12 +1,15 @@
 package suspension
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 
 	"github.com/google/uuid"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/audit"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage"
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
@@ -16,6 +19,8 @@ import (
 
 type ContextUpdateHandler struct {
 	operations          storage.Operations
+	outbox              storage.Outbox
+	actions             storage.Actions
 	provisioningQueue   Adder
 	deprovisioningQueue Adder
 
@@ -26,9 +31,11 @@ type Adder interface {
 	Add(processId string)
 }
 
-func NewContextUpdateHandler(operations storage.Operations, provisioningQueue Adder, deprovisioningQueue Adder, l *slog.Logger) *ContextUpdateHandler {
+func NewContextUpdateHandler(operations storage.Operations, outbox storage.Outbox, actions storage.Actions, provisioningQueue Adder, deprovisioningQueue Adder, l *slog.Logger) *ContextUpdateHandler {
 	return &ContextUpdateHandler{
 		operations:          operations,
+		outbox:              outbox,
+		actions:             actions,
 		provisioningQueue:   provisioningQueue,
 		deprovisioningQueue: deprovisioningQueue,
 		log:                 l,
@@ -37,7 +44,7 @@ func NewContextUpdateHandler(operations storage.Operations, provisioningQueue Ad
 
 // Handle performs suspension/unsuspension for given instance.
 // Applies only when 'Active' parameter has changes and ServicePlanID is `Trial`
-func (h *ContextUpdateHandler) Handle(instance *internal.Instance, newCtx internal.ERSContext) (bool, error) {
+func (h *ContextUpdateHandler) Handle(ctx context.Context, instance *internal.Instance, newCtx internal.ERSContext) (bool, error) {
 	l := h.log.With(
 		"instanceID", instance.InstanceID,
 		"runtimeID", instance.RuntimeID,
@@ -49,10 +56,10 @@ func (h *ContextUpdateHandler) Handle(instance *internal.Instance, newCtx intern
 		return false, nil
 	}
 
-	return h.handleContextChange(newCtx, instance, l)
+	return h.handleContextChange(audit.ActorFromContext(ctx), newCtx, instance, l)
 }
 
-func (h *ContextUpdateHandler) handleContextChange(newCtx internal.ERSContext, instance *internal.Instance, l *slog.Logger) (bool, error) {
+func (h *ContextUpdateHandler) handleContextChange(actor string, newCtx internal.ERSContext, instance *internal.Instance, l *slog.Logger) (bool, error) {
 	isActivated := true
 	if instance.Parameters.ErsContext.Active != nil {
 		isActivated = *instance.Parameters.ErsContext.Active
@@ -76,7 +83,7 @@ func (h *ContextUpdateHandler) handleContextChange(newCtx internal.ERSContext, i
 			// instance is inactive and incoming context update is suspension - verify if KEB should retrigger the operation
 			if lastDeprovisioning.State == domain.Failed {
 				l.Info(fmt.Sprintf("triggering suspension again for instance id %s", instance.InstanceID))
-				return true, h.suspend(instance, l)
+				return true, h.suspend(actor, instance, l)
 			}
 			l.Info(fmt.Sprintf("last deprovisioning is not in Failed state - not triggering suspension for instance ID %s", instance.InstanceID))
 			return false, nil
@@ -96,13 +103,13 @@ func (h *ContextUpdateHandler) handleContextChange(newCtx internal.ERSContext, i
 			err := fmt.Errorf("Preceding suspension has failed, unable to reliably unsuspend")
 			return false, apiresponses.NewFailureResponse(err, http.StatusInternalServerError, "provisioning")
 		}
-		return true, h.unsuspend(instance, l)
+		return true, h.unsuspend(actor, instance, l)
 	} else {
-		return true, h.suspend(instance, l)
+		return true, h.suspend(actor, instance, l)
 	}
 }
 
-func (h *ContextUpdateHandler) suspend(instance *internal.Instance, log *slog.Logger) error {
+func (h *ContextUpdateHandler) suspend(actor string, instance *internal.Instance, log *slog.Logger) error {
 	lastDeprovisioning, err := h.operations.GetDeprovisioningOperationByInstanceID(instance.InstanceID)
 	// there was an error - fail
 	if err != nil && !dberr.IsNotFound(err) {
@@ -122,10 +129,17 @@ func (h *ContextUpdateHandler) suspend(instance *internal.Instance, log *slog.Lo
 		return err
 	}
 	h.deprovisioningQueue.Add(operation.ID)
+	h.storeEvent(internal.InstanceSuspensionStartedOutboxEvent, instance, operation.ID, log)
+	audit.Record(h.actions, pkg.Action{
+		Type:       pkg.SuspensionActionType,
+		InstanceID: instance.InstanceID,
+		Actor:      actor,
+		Message:    fmt.Sprintf("Suspension started with operation %s.", operation.ID),
+	}, log)
 	return nil
 }
 
-func (h *ContextUpdateHandler) unsuspend(instance *internal.Instance, log *slog.Logger) error {
+func (h *ContextUpdateHandler) unsuspend(actor string, instance *internal.Instance, log *slog.Logger) error {
 	if instance.IsExpired() {
 		log.Info("Expired instance cannot be unsuspended")
 		return nil
@@ -149,5 +163,23 @@ func (h *ContextUpdateHandler) unsuspend(instance *internal.Instance, log *slog.
 		return err
 	}
 	h.provisioningQueue.Add(operation.ID)
+	h.storeEvent(internal.InstanceUnsuspensionStartedOutboxEvent, instance, operation.ID, log)
+	audit.Record(h.actions, pkg.Action{
+		Type:       pkg.UnsuspensionActionType,
+		InstanceID: instance.InstanceID,
+		Actor:      actor,
+		Message:    fmt.Sprintf("Unsuspension started with operation %s.", operation.ID),
+	}, log)
 	return nil
 }
+
+// storeEvent notifies webhook subscribers, the operation is already started, so the failure is only logged
+func (h *ContextUpdateHandler) storeEvent(eventType internal.OutboxEventType, instance *internal.Instance, operationID string, log *slog.Logger) {
+	event, err := internal.NewInstanceOutboxEvent(eventType, *instance, operationID, "")
+	if err == nil {
+		err = h.outbox.Insert(event)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("unable to store %s event: %s", eventType, err))
+	}
+}
@@ -1,6 +1,7 @@
 package suspension
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"testing"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
 
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
 	"github.com/kyma-project/kyma-environment-broker/internal"
 	"github.com/kyma-project/kyma-environment-broker/internal/broker"
 	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
@@ -24,13 +26,13 @@ func TestSuspension(t *testing.T) {
 	deprovisioning := NewDummyQueue()
 	st := storage.NewMemoryStorage()
 
-	svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+	svc := NewContextUpdateHandler(st.Operations(), st.Outbox(), st.Actions(), provisioning, deprovisioning, fixLogger())
 	instance := fixInstance(fixActiveErsContext())
 	err := st.Instances().Insert(*instance)
 	require.NoError(t, err)
 
 	// when
-	changed, err := svc.Handle(instance, fixInactiveErsContext())
+	changed, err := svc.Handle(context.Background(), instance, fixInactiveErsContext())
 	require.NoError(t, err)
 	assert.True(t, changed, "handler to change active flag")
 
@@ -41,6 +43,17 @@ func TestSuspension(t *testing.T) {
 
 	assert.Equal(t, domain.LastOperationState("pending"), op.State)
 	assert.Equal(t, instance.InstanceID, op.InstanceID)
+
+	events, err := st.Outbox().Claim("owner", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, internal.InstanceSuspensionStartedOutboxEvent, events[0].Type)
+	assert.Equal(t, op.ID, events[0].OperationID)
+
+	actions, err := st.Actions().ListActionsByInstanceID(instance.InstanceID)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, pkg.SuspensionActionType, actions[0].Type)
 }
 
 func TestSuspension_Retrigger(t *testing.T) {
@@ -50,7 +63,7 @@ func TestSuspension_Retrigger(t *testing.T) {
 		deprovisioning := NewDummyQueue()
 		st := storage.NewMemoryStorage()
 
-		svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+		svc := NewContextUpdateHandler(st.Operations(), st.Outbox(), st.Actions(), provisioning, deprovisioning, fixLogger())
 		instance := fixInstance(fixInactiveErsContext())
 		err := st.Instances().Insert(*instance)
 		require.NoError(t, err)
@@ -69,7 +82,7 @@ func TestSuspension_Retrigger(t *testing.T) {
 		require.NoError(t, err)
 
 		// when
-		changed, err := svc.Handle(instance, fixInactiveErsContext())
+		changed, err := svc.Handle(context.Background(), instance, fixInactiveErsContext())
 		require.NoError(t, err)
 		assert.False(t, changed, "handler to not change active flag")
 
@@ -88,7 +101,7 @@ func TestSuspension_Retrigger(t *testing.T) {
 		deprovisioning := NewDummyQueue()
 		st := storage.NewMemoryStorage()
 
-		svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+		svc := NewContextUpdateHandler(st.Operations(), st.Outbox(), st.Actions(), provisioning, deprovisioning, fixLogger())
 		instance := fixInstance(fixInactiveErsContext())
 		err := st.Instances().Insert(*instance)
 		require.NoError(t, err)
@@ -107,7 +120,7 @@ func TestSuspension_Retrigger(t *testing.T) {
 		require.NoError(t, err)
 
 		// when
-		changed, err := svc.Handle(instance, fixInactiveErsContext())
+		changed, err := svc.Handle(context.Background(), instance, fixInactiveErsContext())
 		require.NoError(t, err)
 		assert.True(t, changed, "handler to change active flag")
 
@@ -137,7 +150,7 @@ func TestUnsuspension(t *testing.T) {
 	deprovisioning := NewDummyQueue()
 	st := storage.NewMemoryStorage()
 
-	svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+	svc := NewContextUpdateHandler(st.Operations(), st.Outbox(), st.Actions(), provisioning, deprovisioning, fixLogger())
 	instance := fixInstance(fixInactiveErsContext())
 	instance.InstanceDetails.ShootName = "c-012345"
 	instance.InstanceDetails.ShootDomain = "c-012345.sap.com"
@@ -151,7 +164,7 @@ func TestUnsuspension(t *testing.T) {
 	require.NoError(t, err)
 
 	// when
-	changed, err := svc.Handle(instance, fixActiveErsContext())
+	changed, err := svc.Handle(context.Background(), instance, fixActiveErsContext())
 	require.NoError(t, err)
 	assert.True(t, changed, "handler to change active flag")
 
@@ -165,6 +178,11 @@ func TestUnsuspension(t *testing.T) {
 	assert.Equal(t, instance.InstanceID, op.InstanceID)
 	assert.Equal(t, "c-012345", op.ShootName)
 	assert.Equal(t, "c-012345.sap.com", op.ShootDomain)
+
+	actions, err := st.Actions().ListActionsByInstanceID(instance.InstanceID)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, pkg.UnsuspensionActionType, actions[0].Type)
 }
 
 func TestUnsuspensionForDeprovisioningInstance(t *testing.T) {
@@ -173,7 +191,7 @@ func TestUnsuspensionForDeprovisioningInstance(t *testing.T) {
 	deprovisioning := NewDummyQueue()
 	st := storage.NewMemoryStorage()
 
-	svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+	svc := NewContextUpdateHandler(st.Operations(), st.Outbox(), st.Actions(), provisioning, deprovisioning, fixLogger())
 	instance := fixInstance(fixInactiveErsContext())
 	instance.InstanceDetails.ShootName = "c-012345"
 	instance.InstanceDetails.ShootDomain = "c-012345.sap.com"
@@ -186,7 +204,7 @@ func TestUnsuspensionForDeprovisioningInstance(t *testing.T) {
 	require.NoError(t, err)
 
 	// when
-	changed, err := svc.Handle(instance, fixActiveErsContext())
+	changed, err := svc.Handle(context.Background(), instance, fixActiveErsContext())
 	require.NoError(t, err)
 	assert.False(t, changed, "handler to not change active flag")
 
@@ -203,7 +221,7 @@ func TestUnsuspensionForExpiredInstance(t *testing.T) {
 	deprovisioning := NewDummyQueue()
 	st := storage.NewMemoryStorage()
 
-	svc := NewContextUpdateHandler(st.Operations(), provisioning, deprovisioning, fixLogger())
+	svc := NewContextUpdateHandler(st.Operations(), st.Outbox(), st.Actions(), provisioning, deprovisioning, fixLogger())
 	instance := fixInstance(fixInactiveErsContext())
 	instance.InstanceDetails.ShootName = "c-012345"
 	instance.InstanceDetails.ShootDomain = "c-012345.sap.com"
@@ -213,7 +231,7 @@ func TestUnsuspensionForExpiredInstance(t *testing.T) {
 	require.NoError(t, err)
 
 	// when
-	changed, err := svc.Handle(instance, fixActiveErsContext())
+	changed, err := svc.Handle(context.Background(), instance, fixActiveErsContext())
 	require.NoError(t, err)
 	assert.False(t, changed, "handler to not change active flag")
 
@@ -0,0 +1,252 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/webhooks"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+type Config struct {
+	// Enables webhook subscriptions, their API, and sending notifications
+	Enabled bool `envconfig:"default=false"`
+	// How often the deliverer checks the storage for notifications to send
+	PollInterval time.Duration `envconfig:"default=2s"`
+	// Maximum number of notifications claimed at once
+	BatchSize int `envconfig:"default=50"`
+	// Time after which a notification claimed by a KEB replica can be claimed by another replica
+	LeaseDuration time.Duration `envconfig:"default=1m"`
+	// Delay of the first retry, the delay is doubled after every failed attempt
+	InitialBackoff time.Duration `envconfig:"default=10s"`
+	// Maximum delay between attempts
+	MaxBackoff time.Duration `envconfig:"default=1h"`
+	// Number of attempts after which the notification is moved to the dead letters
+	MaxAttempts int `envconfig:"default=10"`
+	// Timeout of a single notification request
+	RequestTimeout time.Duration `envconfig:"default=10s"`
+	// Maximum number of subscriptions to which notifications are sent at the same time
+	Parallelism int `envconfig:"default=10"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("(Enabled=%t; PollInterval=%s; BatchSize=%d; LeaseDuration=%s; InitialBackoff=%s; MaxBackoff=%s; MaxAttempts=%d; RequestTimeout=%s; Parallelism=%d)",
+		c.Enabled, c.PollInterval, c.BatchSize, c.LeaseDuration, c.InitialBackoff, c.MaxBackoff, c.MaxAttempts, c.RequestTimeout, c.Parallelism)
+}
+
+// Backoff returns the delay of the next attempt after the given number of failed attempts
+func (c Config) Backoff(failedAttempts int) time.Duration {
+	delay := c.InitialBackoff
+	for i := 1; i < failedAttempts && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.MaxBackoff)
+}
+
+// Deliverer sends notifications stored by the Sink to subscribed endpoints. A notification is signed with the
+// subscription secret, retried with an exponential backoff and kept as a dead letter when all attempts failed.
+type Deliverer struct {
+	webhooks   storage.Webhooks
+	httpClient *http.Client
+	cfg        Config
+	owner      string
+	log        *slog.Logger
+}
+
+func NewDeliverer(webhooks storage.Webhooks, cfg Config, log *slog.Logger) *Deliverer {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "keb"
+	}
+	return &Deliverer{
+		webhooks:   webhooks,
+		httpClient: newHTTPClient(cfg, publicAddressesOnly),
+		cfg:        cfg,
+		owner:      fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+		log:        log.With("component", "WebhookDeliverer"),
+	}
+}
+
+// newHTTPClient creates the client used to send notifications, the control function checks every address the client connects to
+func newHTTPClient(cfg Config, control func(network, address string, conn syscall.RawConn) error) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would hide the address of the subscription endpoint from the control function
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   cfg.RequestTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}).DialContext
+	return &http.Client{
+		Timeout:   cfg.RequestTimeout,
+		Transport: transport,
+		// the subscription URL is validated when the subscription is created, a redirect could lead anywhere
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddressesOnly rejects connections to addresses which are not reachable from the internet, the host name of
+// the subscription URL is checked when the subscription is created, but it can be resolved to any address later
+func publicAddressesOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("while parsing the address %s: %w", address, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("connection to %s is not allowed, notifications are sent only to public addresses", address)
+	}
+	return nil
+}
+
+// Run sends notifications until the context is done
+func (d *Deliverer) Run(ctx context.Context) {
+	d.log.Info("starting the webhook deliverer")
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// do not wait when the batch was full, there could be more notifications ready
+		if d.Deliver(ctx) >= d.cfg.BatchSize && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			d.log.Info("webhook deliverer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliver sends one batch of notifications and returns the number of claimed notifications
+func (d *Deliverer) Deliver(ctx context.Context) int {
+	deliveries, err := d.webhooks.ClaimDeliveries(d.owner, d.cfg.LeaseDuration, d.cfg.BatchSize)
+	if err != nil {
+		d.log.Error(fmt.Sprintf("unable to claim webhook deliveries: %s", err))
+		return 0
+	}
+
+	// notifications of a subscription are sent in order, a slow endpoint delays only its own notifications
+	bySubscription := make(map[string][]internal.WebhookDelivery)
+	var subscriptionIDs []string
+	for _, delivery := range deliveries {
+		if _, found := bySubscription[delivery.SubscriptionID]; !found {
+			subscriptionIDs = append(subscriptionIDs, delivery.SubscriptionID)
+		}
+		bySubscription[delivery.SubscriptionID] = append(bySubscription[delivery.SubscriptionID], delivery)
+	}
+	group := errgroup.Group{}
+	group.SetLimit(max(d.cfg.Parallelism, 1))
+	for _, subscriptionID := range subscriptionIDs {
+		group.Go(func() error {
+			d.deliverToSubscription(ctx, bySubscription[subscriptionID])
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return len(deliveries)
+}
+
+// deliverToSubscription sends notifications of one subscription until the first failure, the remaining notifications
+// are retried with the failed one, so an unavailable endpoint does not hold the batch for a timeout of every notification
+func (d *Deliverer) deliverToSubscription(ctx context.Context, deliveries []internal.WebhookDelivery) {
+	for i, delivery := range deliveries {
+		err := d.deliver(ctx, delivery)
+		if err == nil {
+			continue
+		}
+		for _, remaining := range deliveries[i+1:] {
+			log := d.log.With("deliveryID", remaining.ID, "subscriptionID", remaining.SubscriptionID, "eventID", remaining.EventID, "eventType", remaining.EventType)
+			d.retry(remaining, fmt.Errorf("previous notification of the subscription failed: %w", err), log)
+		}
+		return
+	}
+}
+
+// deliver sends the notification and returns the error of a failed attempt
+func (d *Deliverer) deliver(ctx context.Context, delivery internal.WebhookDelivery) error {
+	log := d.log.With("deliveryID", delivery.ID, "subscriptionID", delivery.SubscriptionID, "eventID", delivery.EventID, "eventType", delivery.EventType)
+
+	subscription, err := d.webhooks.GetSubscription(delivery.SubscriptionID)
+	switch {
+	case dberr.IsNotFound(err):
+		log.Info("subscription was deleted, removing the notification")
+		if err := d.webhooks.RemoveDelivery(delivery.ID, d.owner); err != nil {
+			log.Error(fmt.Sprintf("unable to remove the notification: %s", err))
+		}
+		return nil
+	case err != nil:
+		log.Error(fmt.Sprintf("unable to get the subscription: %s", err))
+		d.retry(delivery, err, log)
+		return err
+	}
+
+	if err := d.send(ctx, *subscription, delivery); err != nil {
+		d.retry(delivery, err, log)
+		return err
+	}
+	log.Debug("notification delivered")
+	if err := d.webhooks.RemoveDelivery(delivery.ID, d.owner); err != nil {
+		log.Error(fmt.Sprintf("unable to remove the delivered notification: %s", err))
+	}
+	return nil
+}
+
+func (d *Deliverer) send(ctx context.Context, subscription internal.WebhookSubscription, delivery internal.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("while creating the request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(subscription.Secret, timestamp, delivery.Payload))
+	req.Header.Set(webhooks.EventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(webhooks.EventTypeHeader, string(delivery.EventType))
+	req.Header.Set(webhooks.DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("while calling %s: %w", subscription.URL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %d", subscription.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Deliverer) retry(delivery internal.WebhookDelivery, deliveryErr error, log *slog.Logger) {
+	failedAttempts := delivery.Attempts + 1
+	if failedAttempts >= d.cfg.MaxAttempts {
+		log.Error(fmt.Sprintf("notification moved to dead letters after %d attempts: %s", failedAttempts, deliveryErr))
+		if err := d.webhooks.MarkDeliveryDead(delivery.ID, d.owner, deliveryErr.Error()); err != nil {
+			log.Error(fmt.Sprintf("unable to mark the notification as dead: %s", err))
+		}
+		return
+	}
+	delay := d.cfg.Backoff(failedAttempts)
+	log.Warn(fmt.Sprintf("unable to deliver the notification, retrying in %s: %s", delay, deliveryErr))
+	if err := d.webhooks.RetryDelivery(delivery.ID, d.owner, delay, deliveryErr.Error()); err != nil {
+		log.Error(fmt.Sprintf("unable to release the notification: %s", err))
+	}
+}
@@ -0,0 +1,226 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/webhooks"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/fixture"
+	"github.com/kyma-project/kyma-environment-broker/internal/outbox"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliverer(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	// zero backoff makes a failed notification ready for the next attempt immediately
+	cfg := webhook.Config{BatchSize: 10, LeaseDuration: time.Minute, MaxAttempts: 2, RequestTimeout: time.Second}
+
+	t.Run("should send signed notifications to matching subscriptions", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		receiver := newReceiver(http.StatusOK)
+		defer receiver.Close()
+		require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-ga", GlobalAccountID: "ga-1", URL: receiver.URL, Secret: "secret-1"}))
+		require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-sa", SubAccountID: "sa-1", URL: receiver.URL, Secret: "secret-2",
+			EventTypes: []internal.OutboxEventType{internal.BindingCreatedOutboxEvent}}))
+		require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-other", GlobalAccountID: "ga-2", URL: receiver.URL, Secret: "secret-3"}))
+		instance := fixture.FixInstance("inst-1")
+		instance.GlobalAccountID = "ga-1"
+		instance.SubAccountID = "sa-1"
+		ev, err := internal.NewInstanceOutboxEvent(internal.InstanceExpiredOutboxEvent, instance, "op-1", "")
+		require.NoError(t, err)
+		ev.ID = 5
+
+		// when
+		require.NoError(t, webhook.NewSink(db.Webhooks()).Deliver(context.Background(), ev))
+		// the same event delivered again by the outbox is stored once
+		require.NoError(t, webhook.NewSink(db.Webhooks()).Deliver(context.Background(), ev))
+		delivered := webhook.NewDelivererForLocalReceivers(db.Webhooks(), cfg, log).Deliver(context.Background())
+
+		// then
+		assert.Equal(t, 1, delivered)
+		requests := receiver.Requests()
+		require.Len(t, requests, 1)
+		assert.Equal(t, "5", requests[0].header.Get(webhooks.EventIDHeader))
+		assert.Equal(t, string(internal.InstanceExpiredOutboxEvent), requests[0].header.Get(webhooks.EventTypeHeader))
+		timestamp, err := strconv.ParseInt(requests[0].header.Get(webhooks.TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.True(t, webhooks.Verify("secret-1", timestamp, requests[0].body, requests[0].header.Get(webhooks.SignatureHeader)))
+
+		var envelope outbox.Envelope
+		require.NoError(t, json.Unmarshal(requests[0].body, &envelope))
+		assert.Equal(t, "inst-1", envelope.InstanceID)
+		var payload internal.InstanceEvent
+		require.NoError(t, json.Unmarshal(envelope.Data, &payload))
+		assert.Equal(t, "op-1", payload.OperationID)
+
+		pending, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-ga"})
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("should keep the notification as dead letter after all attempts", func(t *testing.T) {
+		// given
+		db := storage.NewMemoryStorage()
+		receiver := newReceiver(http.StatusInternalServerError)
+		defer receiver.Close()
+		require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1", URL: receiver.URL, Secret: "secret"}))
+		require.NoError(t, db.Webhooks().InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1, EventType: internal.BindingDeletedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+		deliverer := webhook.NewDelivererForLocalReceivers(db.Webhooks(), cfg, log)
+
+		// when
+		first := deliverer.Deliver(context.Background())
+		deliveries, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1", State: string(internal.WebhookDeliveryPending)})
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, 1, first)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, 1, deliveries[0].Attempts)
+		assert.Contains(t, deliveries[0].LastError, "responded with status 500")
+
+		// when
+		deliverer.Deliver(context.Background())
+		dead, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1", State: string(internal.WebhookDeliveryDead)})
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, dead, 1)
+		assert.Equal(t, 2, dead[0].Attempts)
+		assert.Zero(t, deliverer.Deliver(context.Background()))
+		assert.Len(t, receiver.Requests(), 2)
+	})
+}
+
+func TestDeliverer_DoesNotFollowRedirects(t *testing.T) {
+	// given
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := webhook.Config{BatchSize: 10, LeaseDuration: time.Minute, MaxAttempts: 2, RequestTimeout: time.Second}
+	db := storage.NewMemoryStorage()
+	target := newReceiver(http.StatusOK)
+	defer target.Close()
+	redirecting := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirecting.Close()
+	require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1", URL: redirecting.URL, Secret: "secret"}))
+	require.NoError(t, db.Webhooks().InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1, EventType: internal.BindingDeletedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+
+	// when
+	webhook.NewDelivererForLocalReceivers(db.Webhooks(), cfg, log).Deliver(context.Background())
+
+	// then
+	assert.Empty(t, target.Requests())
+	deliveries, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1", State: string(internal.WebhookDeliveryPending)})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Contains(t, deliveries[0].LastError, "responded with status 302")
+}
+
+func TestDeliverer_RejectsPrivateAddresses(t *testing.T) {
+	// given
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := webhook.Config{BatchSize: 10, LeaseDuration: time.Minute, MaxAttempts: 2, RequestTimeout: time.Second}
+	db := storage.NewMemoryStorage()
+	target := newReceiver(http.StatusOK)
+	defer target.Close()
+	// the subscription was validated with a public host name which resolves now to the loopback address
+	require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-1", SubAccountID: "sa-1", URL: target.URL, Secret: "secret"}))
+	require.NoError(t, db.Webhooks().InsertDelivery(internal.WebhookDelivery{SubscriptionID: "sub-1", EventID: 1, EventType: internal.BindingDeletedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+
+	// when
+	webhook.NewDeliverer(db.Webhooks(), cfg, log).Deliver(context.Background())
+
+	// then
+	assert.Empty(t, target.Requests())
+	deliveries, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-1", State: string(internal.WebhookDeliveryPending)})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Contains(t, deliveries[0].LastError, "notifications are sent only to public addresses")
+}
+
+func TestDeliverer_FailingSubscriptionDoesNotHoldOthers(t *testing.T) {
+	// given
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg := webhook.Config{BatchSize: 10, LeaseDuration: time.Minute, MaxAttempts: 5, RequestTimeout: time.Second, Parallelism: 2}
+	db := storage.NewMemoryStorage()
+	failing := newReceiver(http.StatusServiceUnavailable)
+	defer failing.Close()
+	working := newReceiver(http.StatusOK)
+	defer working.Close()
+	require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-failing", SubAccountID: "sa-1", URL: failing.URL, Secret: "secret"}))
+	require.NoError(t, db.Webhooks().InsertSubscription(internal.WebhookSubscription{ID: "sub-working", SubAccountID: "sa-2", URL: working.URL, Secret: "secret"}))
+	for eventID := int64(1); eventID <= 3; eventID++ {
+		for _, subscriptionID := range []string{"sub-failing", "sub-working"} {
+			require.NoError(t, db.Webhooks().InsertDelivery(internal.WebhookDelivery{SubscriptionID: subscriptionID, EventID: eventID, EventType: internal.BindingDeletedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+		}
+	}
+
+	// when
+	delivered := webhook.NewDelivererForLocalReceivers(db.Webhooks(), cfg, log).Deliver(context.Background())
+
+	// then
+	assert.Equal(t, 6, delivered)
+	assert.Len(t, working.Requests(), 3)
+	// the failing endpoint is called once, the remaining notifications are retried with the failed one
+	assert.Len(t, failing.Requests(), 1)
+	deliveries, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: "sub-failing", State: string(internal.WebhookDeliveryPending)})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	for _, delivery := range deliveries {
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Contains(t, delivery.LastError, "responded with status 503")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := webhook.Config{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+	assert.Equal(t, 10*time.Second, cfg.Backoff(1))
+	assert.Equal(t, 20*time.Second, cfg.Backoff(2))
+	assert.Equal(t, 40*time.Second, cfg.Backoff(3))
+	assert.Equal(t, time.Minute, cfg.Backoff(4))
+	assert.Equal(t, time.Minute, cfg.Backoff(20))
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []receivedRequest
+}
+
+func newReceiver(status int) *receiver {
+	r := &receiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	return r
+}
+
+func (r *receiver) Requests() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest{}, r.requests...)
+}
@@ -0,0 +1,14 @@
+package webhook
+
+import (
+	"log/slog"
+
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+)
+
+// NewDelivererForLocalReceivers creates a deliverer which sends notifications to test servers listening on the loopback address
+func NewDelivererForLocalReceivers(webhooks storage.Webhooks, cfg Config, log *slog.Logger) *Deliverer {
+	d := NewDeliverer(webhooks, cfg, log)
+	d.httpClient = newHTTPClient(cfg, nil)
+	return d
+}
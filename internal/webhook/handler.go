@@ -0,0 +1,287 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/pagination"
+	pkg "github.com/kyma-project/kyma-environment-broker/common/runtime"
+	"github.com/kyma-project/kyma-environment-broker/common/webhooks"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dberr"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+
+	"github.com/google/uuid"
+)
+
+// SupportedEvents lists event types which can be subscribed
+var SupportedEvents = []internal.OutboxEventType{
+	internal.OperationFinishedOutboxEvent,
+	internal.InstanceSuspensionStartedOutboxEvent,
+	internal.InstanceUnsuspensionStartedOutboxEvent,
+	internal.InstanceExpiredOutboxEvent,
+	internal.BindingCreatedOutboxEvent,
+	internal.BindingDeletedOutboxEvent,
+}
+
+const secretLength = 32
+
+type router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+type Handler struct {
+	webhooks       storage.Webhooks
+	defaultMaxPage int
+	log            *slog.Logger
+}
+
+func NewHandler(webhooks storage.Webhooks, defaultMaxPage int, log *slog.Logger) *Handler {
+	return &Handler{
+		webhooks:       webhooks,
+		defaultMaxPage: defaultMaxPage,
+		log:            log.With("service", "WebhooksEndpoint"),
+	}
+}
+
+func (h *Handler) AttachRoutes(r router) {
+	r.HandleFunc("POST /webhooks/subscriptions", h.createSubscription)
+	r.HandleFunc("GET /webhooks/subscriptions", h.listSubscriptions)
+	r.HandleFunc("GET /webhooks/subscriptions/{subscription_id}", h.getSubscription)
+	r.HandleFunc("DELETE /webhooks/subscriptions/{subscription_id}", h.deleteSubscription)
+	r.HandleFunc("GET /webhooks/subscriptions/{subscription_id}/deliveries", h.listDeliveries)
+}
+
+func (h *Handler) createSubscription(w http.ResponseWriter, req *http.Request) {
+	var request webhooks.SubscriptionRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log.Warn(fmt.Sprintf("unable to decode request body: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while decoding request body: %w", err))
+		return
+	}
+	if err := validateSubscription(request); err != nil {
+		h.log.Warn(fmt.Sprintf("invalid subscription: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			h.log.Error(fmt.Sprintf("unable to generate secret: %s", err.Error()))
+			httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		secret = generated
+	}
+	subscription := internal.WebhookSubscription{
+		ID:              uuid.NewString(),
+		GlobalAccountID: request.GlobalAccountID,
+		SubAccountID:    request.SubAccountID,
+		URL:             request.URL,
+		Secret:          secret,
+		CreatedAt:       time.Now().UTC(),
+	}
+	for _, event := range request.Events {
+		subscription.EventTypes = append(subscription.EventTypes, internal.OutboxEventType(event))
+	}
+	if err := h.webhooks.InsertSubscription(subscription); err != nil {
+		h.log.Error(fmt.Sprintf("unable to store subscription: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info(fmt.Sprintf("webhook subscription %s created for globalAccountID=%q subAccountID=%q", subscription.ID, subscription.GlobalAccountID, subscription.SubAccountID))
+	dto := toSubscriptionDTO(subscription)
+	// the secret is returned only once, it cannot be read later
+	dto.Secret = subscription.Secret
+	httputil.WriteResponse(w, http.StatusCreated, dto)
+}
+
+func (h *Handler) listSubscriptions(w http.ResponseWriter, req *http.Request) {
+	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(req, h.defaultMaxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+	query := req.URL.Query()
+	subscriptions, totalCount, err := h.webhooks.ListSubscriptions(dbmodel.WebhookSubscriptionFilter{
+		Page:            page,
+		PageSize:        pageSize,
+		GlobalAccountID: query.Get(pkg.GlobalAccountIDParam),
+		SubAccountID:    query.Get(pkg.SubAccountIDParam),
+	})
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to list subscriptions: %s", err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := webhooks.SubscriptionsPage{Data: make([]webhooks.SubscriptionDTO, 0, len(subscriptions))}
+	for _, subscription := range subscriptions {
+		result.Data = append(result.Data, toSubscriptionDTO(subscription))
+	}
+	result.Count = len(result.Data)
+	result.TotalCount = totalCount
+	httputil.WriteResponse(w, http.StatusOK, result)
+}
+
+func (h *Handler) getSubscription(w http.ResponseWriter, req *http.Request) {
+	subscription, ok := h.subscription(w, req)
+	if !ok {
+		return
+	}
+	httputil.WriteResponse(w, http.StatusOK, toSubscriptionDTO(*subscription))
+}
+
+func (h *Handler) deleteSubscription(w http.ResponseWriter, req *http.Request) {
+	subscriptionID := req.PathValue("subscription_id")
+	err := h.webhooks.DeleteSubscription(subscriptionID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		return
+	case err != nil:
+		h.log.Error(fmt.Sprintf("unable to delete subscription %s: %s", subscriptionID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.log.Info(fmt.Sprintf("webhook subscription %s deleted", subscriptionID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listDeliveries returns notifications waiting for delivery or dead letters if the state parameter is "dead"
+func (h *Handler) listDeliveries(w http.ResponseWriter, req *http.Request) {
+	subscription, ok := h.subscription(w, req)
+	if !ok {
+		return
+	}
+	state := internal.WebhookDeliveryState(req.URL.Query().Get(pkg.StateParam))
+	if state != "" && state != internal.WebhookDeliveryPending && state != internal.WebhookDeliveryDead {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("unsupported state %s, use %s or %s", state, internal.WebhookDeliveryPending, internal.WebhookDeliveryDead))
+		return
+	}
+
+	pageSize, page, err := pagination.ExtractPaginationConfigFromRequest(req, h.defaultMaxPage)
+	if err != nil {
+		httputil.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("while getting query parameters: %w", err))
+		return
+	}
+
+	deliveries, totalCount, err := h.webhooks.ListDeliveries(dbmodel.WebhookDeliveryFilter{
+		Page:           page,
+		PageSize:       pageSize,
+		SubscriptionID: subscription.ID,
+		State:          string(state),
+	})
+	if err != nil {
+		h.log.Error(fmt.Sprintf("unable to list deliveries of subscription %s: %s", subscription.ID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := webhooks.DeliveriesPage{Data: make([]webhooks.DeliveryDTO, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		result.Data = append(result.Data, webhooks.DeliveryDTO{
+			ID:        delivery.ID,
+			EventID:   delivery.EventID,
+			EventType: string(delivery.EventType),
+			State:     string(delivery.State),
+			Attempts:  delivery.Attempts,
+			LastError: delivery.LastError,
+			CreatedAt: delivery.CreatedAt,
+			UpdatedAt: delivery.UpdatedAt,
+		})
+	}
+	result.Count = len(result.Data)
+	result.TotalCount = totalCount
+	httputil.WriteResponse(w, http.StatusOK, result)
+}
+
+func (h *Handler) subscription(w http.ResponseWriter, req *http.Request) (*internal.WebhookSubscription, bool) {
+	subscriptionID := req.PathValue("subscription_id")
+	subscription, err := h.webhooks.GetSubscription(subscriptionID)
+	switch {
+	case dberr.IsNotFound(err):
+		httputil.WriteErrorResponse(w, http.StatusNotFound, err)
+		return nil, false
+	case err != nil:
+		h.log.Error(fmt.Sprintf("unable to get subscription %s: %s", subscriptionID, err.Error()))
+		httputil.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return subscription, true
+}
+
+func validateSubscription(request webhooks.SubscriptionRequest) error {
+	if (request.GlobalAccountID == "") == (request.SubAccountID == "") {
+		return errors.New("exactly one of globalAccountId and subAccountId must be set")
+	}
+	u, err := url.Parse(request.URL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("url %q must be an absolute HTTPS URL", request.URL)
+	}
+	if !isPublicHost(u.Hostname()) {
+		return fmt.Errorf("url %q must not point to a loopback, link-local or private address", request.URL)
+	}
+	for _, event := range request.Events {
+		if !slices.Contains(SupportedEvents, internal.OutboxEventType(event)) {
+			return fmt.Errorf("event %s is not supported, supported events: %v", event, SupportedEvents)
+		}
+	}
+	return nil
+}
+
+// isPublicHost returns false for localhost and IP addresses which are not reachable from the internet
+func isPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return true
+	}
+	return isPublicIP(ip)
+}
+
+// isPublicIP returns false for loopback, private, link-local and unspecified addresses
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsUnspecified()
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("while generating the secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func toSubscriptionDTO(subscription internal.WebhookSubscription) webhooks.SubscriptionDTO {
+	dto := webhooks.SubscriptionDTO{
+		ID:              subscription.ID,
+		GlobalAccountID: subscription.GlobalAccountID,
+		SubAccountID:    subscription.SubAccountID,
+		URL:             subscription.URL,
+		CreatedAt:       subscription.CreatedAt,
+	}
+	for _, event := range subscription.EventTypes {
+		dto.Events = append(dto.Events, string(event))
+	}
+	return dto
+}
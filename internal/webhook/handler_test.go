@@ -0,0 +1,182 @@
+package webhook_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/common/webhooks"
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/httputil"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+	"github.com/kyma-project/kyma-environment-broker/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	router := httputil.NewRouter()
+	db := storage.NewMemoryStorage()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	webhook.NewHandler(db.Webhooks(), 100, logger).AttachRoutes(router)
+
+	var created webhooks.SubscriptionDTO
+
+	t.Run("should create subscription with generated secret", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodPost, "/webhooks/subscriptions", webhooks.SubscriptionRequest{
+			SubAccountID: "sa-1",
+			URL:          "https://example.com/hook",
+			Events:       []string{string(internal.BindingCreatedOutboxEvent)},
+		})
+
+		// then
+		require.Equal(t, http.StatusCreated, resp.Code)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.NotEmpty(t, created.ID)
+		assert.Len(t, created.Secret, 64)
+		assert.Equal(t, "sa-1", created.SubAccountID)
+		assert.Equal(t, []string{string(internal.BindingCreatedOutboxEvent)}, created.Events)
+
+		stored, err := db.Webhooks().GetSubscription(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.Secret, stored.Secret)
+	})
+
+	t.Run("should keep the provided secret", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodPost, "/webhooks/subscriptions", webhooks.SubscriptionRequest{
+			GlobalAccountID: "ga-1",
+			URL:             "https://example.com/hook",
+			Secret:          "my-secret",
+		})
+
+		// then
+		require.Equal(t, http.StatusCreated, resp.Code)
+		var dto webhooks.SubscriptionDTO
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&dto))
+		assert.Equal(t, "my-secret", dto.Secret)
+	})
+
+	t.Run("should reject invalid subscriptions", func(t *testing.T) {
+		for name, request := range map[string]webhooks.SubscriptionRequest{
+			"no account":         {URL: "https://example.com"},
+			"both accounts":      {GlobalAccountID: "ga-1", SubAccountID: "sa-1", URL: "https://example.com"},
+			"relative url":       {SubAccountID: "sa-1", URL: "/hook"},
+			"http url":           {SubAccountID: "sa-1", URL: "http://example.com/hook"},
+			"localhost":          {SubAccountID: "sa-1", URL: "https://localhost:8443/hook"},
+			"loopback address":   {SubAccountID: "sa-1", URL: "https://127.0.0.1/hook"},
+			"private address":    {SubAccountID: "sa-1", URL: "https://10.0.0.1/hook"},
+			"link-local address": {SubAccountID: "sa-1", URL: "https://169.254.169.254/latest/meta-data"},
+			"ipv6 loopback":      {SubAccountID: "sa-1", URL: "https://[::1]/hook"},
+			"unsupported event":  {SubAccountID: "sa-1", URL: "https://example.com", Events: []string{"instance_created"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// when
+				resp := call(router, http.MethodPost, "/webhooks/subscriptions", request)
+
+				// then
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			})
+		}
+	})
+
+	t.Run("should list subscriptions without secrets", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodGet, "/webhooks/subscriptions?subaccount=sa-1", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page webhooks.SubscriptionsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		require.Equal(t, 1, page.Count)
+		assert.Equal(t, created.ID, page.Data[0].ID)
+		assert.Empty(t, page.Data[0].Secret)
+	})
+
+	t.Run("should return the total count of subscriptions", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodGet, "/webhooks/subscriptions?page_size=1", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var page webhooks.SubscriptionsPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.Equal(t, 1, page.Count)
+		assert.Equal(t, 2, page.TotalCount)
+		assert.Equal(t, created.ID, page.Data[0].ID)
+	})
+
+	t.Run("should get subscription", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodGet, "/webhooks/subscriptions/"+created.ID, nil)
+		notFound := call(router, http.MethodGet, "/webhooks/subscriptions/not-existing", nil)
+
+		// then
+		require.Equal(t, http.StatusOK, resp.Code)
+		var dto webhooks.SubscriptionDTO
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&dto))
+		assert.Equal(t, "https://example.com/hook", dto.URL)
+		assert.Empty(t, dto.Secret)
+		assert.Equal(t, http.StatusNotFound, notFound.Code)
+	})
+
+	t.Run("should list dead letters", func(t *testing.T) {
+		// given
+		require.NoError(t, db.Webhooks().InsertDelivery(internal.WebhookDelivery{SubscriptionID: created.ID, EventID: 1, EventType: internal.BindingCreatedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+		require.NoError(t, db.Webhooks().InsertDelivery(internal.WebhookDelivery{SubscriptionID: created.ID, EventID: 2, EventType: internal.BindingCreatedOutboxEvent, Payload: []byte("{}"), CreatedAt: time.Now()}))
+		claimed, err := db.Webhooks().ClaimDeliveries("owner", time.Minute, 1)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		require.NoError(t, db.Webhooks().MarkDeliveryDead(claimed[0].ID, "owner", "connection refused"))
+
+		// when
+		all := call(router, http.MethodGet, "/webhooks/subscriptions/"+created.ID+"/deliveries", nil)
+		dead := call(router, http.MethodGet, "/webhooks/subscriptions/"+created.ID+"/deliveries?state=dead", nil)
+		invalid := call(router, http.MethodGet, "/webhooks/subscriptions/"+created.ID+"/deliveries?state=sent", nil)
+
+		// then
+		var allPage, deadPage webhooks.DeliveriesPage
+		require.Equal(t, http.StatusOK, all.Code)
+		require.NoError(t, json.NewDecoder(all.Body).Decode(&allPage))
+		assert.Equal(t, 2, allPage.Count)
+		require.Equal(t, http.StatusOK, dead.Code)
+		require.NoError(t, json.NewDecoder(dead.Body).Decode(&deadPage))
+		require.Equal(t, 1, deadPage.Count)
+		assert.Equal(t, "connection refused", deadPage.Data[0].LastError)
+		assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	})
+
+	t.Run("should delete subscription with its notifications", func(t *testing.T) {
+		// when
+		resp := call(router, http.MethodDelete, "/webhooks/subscriptions/"+created.ID, nil)
+		again := call(router, http.MethodDelete, "/webhooks/subscriptions/"+created.ID, nil)
+
+		// then
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, http.StatusNotFound, again.Code)
+		deliveries, _, err := db.Webhooks().ListDeliveries(dbmodel.WebhookDeliveryFilter{SubscriptionID: created.ID})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+}
+
+func call(router *httputil.Router, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
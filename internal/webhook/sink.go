@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/kyma-environment-broker/internal"
+	"github.com/kyma-project/kyma-environment-broker/internal/outbox"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage"
+	"github.com/kyma-project/kyma-environment-broker/internal/storage/dbmodel"
+)
+
+// Sink is the outbox sink which stores a notification for every subscription matching the event,
+// the notifications are sent by the Deliverer independently for every subscription
+type Sink struct {
+	webhooks storage.Webhooks
+}
+
+func NewSink(webhooks storage.Webhooks) *Sink {
+	return &Sink{webhooks: webhooks}
+}
+
+func (s *Sink) Name() string {
+	return "webhook-subscriptions"
+}
+
+func (s *Sink) Deliver(_ context.Context, ev internal.OutboxEvent) error {
+	// all event payloads contain the accounts of the instance
+	var accounts struct {
+		GlobalAccountID string `json:"globalAccountId"`
+		SubAccountID    string `json:"subAccountId"`
+	}
+	if err := json.Unmarshal(ev.Payload, &accounts); err != nil {
+		return fmt.Errorf("while unmarshalling the payload: %w", err)
+	}
+
+	subscriptions, err := s.matchingSubscriptions(ev.Type, accounts.GlobalAccountID, accounts.SubAccountID)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(outbox.NewEnvelope(ev))
+	if err != nil {
+		return fmt.Errorf("while marshalling the event: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		err := s.webhooks.InsertDelivery(internal.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        ev.ID,
+			EventType:      ev.Type,
+			Payload:        payload,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("while storing the notification for subscription %s: %w", subscription.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Sink) matchingSubscriptions(eventType internal.OutboxEventType, globalAccountID, subAccountID string) ([]internal.WebhookSubscription, error) {
+	var candidates []internal.WebhookSubscription
+	if globalAccountID != "" {
+		subscriptions, _, err := s.webhooks.ListSubscriptions(dbmodel.WebhookSubscriptionFilter{GlobalAccountID: globalAccountID})
+		if err != nil {
+			return nil, fmt.Errorf("while listing subscriptions of global account %s: %w", globalAccountID, err)
+		}
+		candidates = append(candidates, subscriptions...)
+	}
+	if subAccountID != "" {
+		subscriptions, _, err := s.webhooks.ListSubscriptions(dbmodel.WebhookSubscriptionFilter{SubAccountID: subAccountID})
+		if err != nil {
+			return nil, fmt.Errorf("while listing subscriptions of subaccount %s: %w", subAccountID, err)
+		}
+		candidates = append(candidates, subscriptions...)
+	}
+
+	seen := make(map[string]struct{})
+	var matching []internal.WebhookSubscription
+	for _, subscription := range candidates {
+		if _, found := seen[subscription.ID]; found {
+			continue
+		}
+		seen[subscription.ID] = struct{}{}
+		if subscription.Matches(eventType, globalAccountID, subAccountID) {
+			matching = append(matching, subscription)
+		}
+	}
+	return matching, nil
+}
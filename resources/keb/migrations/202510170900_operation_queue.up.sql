@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS operation_queue (
+    operation_id     varchar(255) NOT NULL PRIMARY KEY,
+    queue_name       varchar(64) NOT NULL,
+    visible_at       timestamp with time zone NOT NULL,
+    owner            varchar(255),
+    lease_expires_at timestamp with time zone,
+    requeued         boolean NOT NULL DEFAULT false,
+    created_at       timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS operation_queue_queue_name_visible_at ON operation_queue USING btree (queue_name, visible_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- values of an enum type cannot be removed without deleting the audit records which use them,
+-- so the down migrations keep the action types added by KEB, and the actions recorded with them.
+-- KEB of the previous version ignores the action types it does not know.
+-- The operation_retry action type is kept.
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id               bigserial PRIMARY KEY,
+    instance_id      varchar(255) NOT NULL,
+    operation_id     varchar(255) NOT NULL,
+    type             varchar(64) NOT NULL,
+    payload          text NOT NULL,
+    attempts         integer NOT NULL DEFAULT 0,
+    visible_at       timestamp with time zone NOT NULL,
+    owner            varchar(255),
+    lease_expires_at timestamp with time zone,
+    created_at       timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_instance_id_id ON outbox USING btree (instance_id, id);
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                varchar(255) PRIMARY KEY,
+    global_account_id varchar(255) NOT NULL DEFAULT '',
+    sub_account_id    varchar(255) NOT NULL DEFAULT '',
+    url               text NOT NULL,
+    secret            text NOT NULL,
+    event_types       text NOT NULL DEFAULT '',
+    created_at        timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_global_account_id ON webhook_subscriptions USING btree (global_account_id);
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_sub_account_id ON webhook_subscriptions USING btree (sub_account_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               bigserial PRIMARY KEY,
+    subscription_id  varchar(255) NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id         bigint NOT NULL,
+    event_type       varchar(64) NOT NULL,
+    payload          text NOT NULL,
+    state            varchar(32) NOT NULL,
+    attempts         integer NOT NULL DEFAULT 0,
+    last_error       text NOT NULL DEFAULT '',
+    visible_at       timestamp with time zone NOT NULL,
+    owner            varchar(255),
+    lease_expires_at timestamp with time zone,
+    created_at       timestamp with time zone NOT NULL,
+    updated_at       timestamp with time zone NOT NULL,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_state_visible_at ON webhook_deliveries USING btree (state, visible_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE campaign_instances;
+DROP TABLE campaigns;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id               varchar(255) PRIMARY KEY,
+    state            varchar(32) NOT NULL,
+    data             text NOT NULL,
+    version          integer NOT NULL DEFAULT 0,
+    owner            varchar(255),
+    lease_expires_at timestamp with time zone,
+    created_at       timestamp with time zone NOT NULL,
+    updated_at       timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS campaigns_state ON campaigns USING btree (state);
+
+CREATE TABLE IF NOT EXISTS campaign_instances (
+    campaign_id  varchar(255) NOT NULL REFERENCES campaigns (id) ON DELETE CASCADE,
+    instance_id  varchar(255) NOT NULL,
+    wave         integer NOT NULL,
+    state        varchar(32) NOT NULL,
+    operation_id varchar(255) NOT NULL DEFAULT '',
+    message      text NOT NULL DEFAULT '',
+    updated_at   timestamp with time zone NOT NULL,
+    PRIMARY KEY (campaign_id, instance_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_instances_campaign_id_wave ON campaign_instances USING btree (campaign_id, wave);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS actions_created_at;
+
+-- see 202510171000_operation_retry_action_type.down.sql for why action types are kept,
+-- the action types, the actor and the text values are kept
+
+COMMIT;
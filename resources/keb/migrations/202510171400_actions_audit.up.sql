@@ -0,0 +1,16 @@
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'operation_cancel';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'provisioning';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'deprovisioning';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'expiration';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'suspension';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'unsuspension';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'oidc_update';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'administrators_update';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'binding_creation';
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'binding_deletion';
+
+ALTER TABLE actions ADD COLUMN IF NOT EXISTS actor varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE actions ALTER COLUMN old_value TYPE text;
+ALTER TABLE actions ALTER COLUMN new_value TYPE text;
+
+CREATE INDEX IF NOT EXISTS actions_created_at ON actions USING btree (created_at);
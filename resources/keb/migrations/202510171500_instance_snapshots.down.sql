@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE instance_snapshots;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS instance_snapshots (
+    instance_id    varchar(255) NOT NULL,
+    version        integer NOT NULL,
+    operation_id   varchar(255) NOT NULL UNIQUE,
+    operation_type varchar(32) NOT NULL,
+    runtime_id     varchar(255) NOT NULL DEFAULT '',
+    parameters     text NOT NULL,
+    created_at     timestamp with time zone NOT NULL,
+    PRIMARY KEY (instance_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS instance_snapshots_instance_id_created_at ON instance_snapshots USING btree (instance_id, created_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE instance_labels;
+
+-- see 202510171000_operation_retry_action_type.down.sql for why action types are kept,
+-- the labels_update action type is kept
+
+COMMIT;
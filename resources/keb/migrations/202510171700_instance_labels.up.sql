@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS instance_labels (
+    instance_id varchar(255) NOT NULL REFERENCES instances (instance_id) ON DELETE CASCADE,
+    key         varchar(317) NOT NULL,
+    value       varchar(63) NOT NULL,
+    PRIMARY KEY (instance_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS instance_labels_key_value ON instance_labels USING btree (key, value);
+
+COMMIT;
+
+ALTER TYPE action_type ADD VALUE IF NOT EXISTS 'labels_update';
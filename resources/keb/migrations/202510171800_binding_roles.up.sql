@@ -0,0 +1,3 @@
+ALTER TABLE bindings
+    ADD COLUMN role VARCHAR(64) NOT NULL DEFAULT 'admin',
+    ADD COLUMN namespaces TEXT NOT NULL DEFAULT '';
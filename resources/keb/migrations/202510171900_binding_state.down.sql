@@ -0,0 +1,4 @@
+ALTER TABLE bindings
+    DROP COLUMN state,
+    DROP COLUMN stage,
+    DROP COLUMN description;
@@ -0,0 +1,4 @@
+ALTER TABLE bindings
+    ADD COLUMN state VARCHAR(32) NOT NULL DEFAULT 'succeeded',
+    ADD COLUMN stage VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN description TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- see 202510171000_operation_retry_action_type.down.sql for why action types are kept,
+-- the binding_renewal action type is kept
+
+COMMIT;
@@ -0,0 +1,2 @@
+ALTER TABLE outbox
+    ADD COLUMN delivered_sinks TEXT NOT NULL DEFAULT '';